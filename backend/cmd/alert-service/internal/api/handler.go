@@ -0,0 +1,423 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/edgelink/backend/cmd/alert-service/internal/integrations"
+	"github.com/edgelink/backend/cmd/alert-service/internal/notifier"
+	"github.com/edgelink/backend/cmd/alert-service/internal/rules"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	// healthCheckTimeout 单次健康检查的超时时间
+	healthCheckTimeout = 5 * time.Second
+	// integrationHealthTTL 集成健康检查结果的缓存时间，避免探针频繁调用第三方API
+	integrationHealthTTL = 30 * time.Second
+	// testNotificationTimeout 测试通知的超时时间（包含Webhook重试）
+	testNotificationTimeout = 45 * time.Second
+)
+
+// ServiceHandler 告警服务健康检查、集成状态与测试通知处理器
+type ServiceHandler struct {
+	db            *gorm.DB
+	redisClient   *redis.Client
+	integrations  *integrations.Manager
+	executor      *rules.Executor
+	emailNotifier *notifier.EmailNotifier
+	logger        *zap.Logger
+
+	healthMu      sync.Mutex
+	healthResults map[string]IntegrationHealth
+	healthChecked time.Time
+}
+
+// NewServiceHandler 创建服务处理器
+func NewServiceHandler(
+	db *gorm.DB,
+	redisClient *redis.Client,
+	integrationManager *integrations.Manager,
+	executor *rules.Executor,
+	emailNotifier *notifier.EmailNotifier,
+	logger *zap.Logger,
+) *ServiceHandler {
+	return &ServiceHandler{
+		db:            db,
+		redisClient:   redisClient,
+		integrations:  integrationManager,
+		executor:      executor,
+		emailNotifier: emailNotifier,
+		logger:        logger,
+	}
+}
+
+// IntegrationHealth 集成健康状态
+type IntegrationHealth struct {
+	Healthy bool   `json:"healthy"`
+	Error   string `json:"error,omitempty"`
+}
+
+// HealthResponse 健康检查响应
+type HealthResponse struct {
+	Status       string                       `json:"status"` // healthy/degraded/unhealthy
+	Checks       map[string]IntegrationHealth `json:"checks,omitempty"`
+	Integrations map[string]IntegrationHealth `json:"integrations"`
+	CheckedAt    time.Time                    `json:"checked_at"`
+}
+
+// Health 存活检查
+// 集成不可用只会使状态变为degraded，服务本身仍视为存活
+// @Summary      存活检查
+// @Tags         health
+// @Produce      json
+// @Success      200  {object}  HealthResponse
+// @Router       /health [get]
+func (h *ServiceHandler) Health(c *gin.Context) {
+	results := h.integrationHealth(c.Request.Context())
+
+	c.JSON(http.StatusOK, HealthResponse{
+		Status:       overallStatus(nil, results),
+		Integrations: results,
+		CheckedAt:    time.Now(),
+	})
+}
+
+// Ready 就绪检查
+// 数据库与Redis不可用时返回503，集成不可用时返回200并标记为degraded
+// @Summary      就绪检查
+// @Tags         health
+// @Produce      json
+// @Success      200  {object}  HealthResponse
+// @Failure      503  {object}  HealthResponse
+// @Router       /ready [get]
+func (h *ServiceHandler) Ready(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), healthCheckTimeout)
+	defer cancel()
+
+	checks := map[string]IntegrationHealth{
+		"database": toHealth(h.pingDatabase(ctx)),
+		"redis":    toHealth(h.redisClient.Ping(ctx).Err()),
+	}
+	results := h.integrationHealth(c.Request.Context())
+
+	status := overallStatus(checks, results)
+	code := http.StatusOK
+	if status == "unhealthy" {
+		code = http.StatusServiceUnavailable
+	}
+
+	c.JSON(code, HealthResponse{
+		Status:       status,
+		Checks:       checks,
+		Integrations: results,
+		CheckedAt:    time.Now(),
+	})
+}
+
+// IntegrationInfo 集成实例信息
+type IntegrationInfo struct {
+	Name    string                      `json:"name"`
+	Type    string                      `json:"type"`
+	Health  *IntegrationHealth          `json:"health,omitempty"`
+	Metrics *IntegrationMetricsResponse `json:"metrics"`
+}
+
+// IntegrationMetricsResponse 集成发送指标
+type IntegrationMetricsResponse struct {
+	TotalSent         int64      `json:"total_sent"`
+	SuccessCount      int64      `json:"success_count"`
+	FailureCount      int64      `json:"failure_count"`
+	LastSentAt        *time.Time `json:"last_sent_at,omitempty"`
+	AvgResponseTimeMs int64      `json:"avg_response_time_ms"`
+}
+
+// EmailMetricsResponse 邮件通知指标
+type EmailMetricsResponse struct {
+	TotalSent   int64      `json:"total_sent"`
+	TotalFailed int64      `json:"total_failed"`
+	TotalRetry  int64      `json:"total_retried"`
+	QueueLength int        `json:"queue_length"`
+	LastSentAt  *time.Time `json:"last_sent_at,omitempty"`
+	LastErrorAt *time.Time `json:"last_error_at,omitempty"`
+	LastError   string     `json:"last_error,omitempty"`
+}
+
+// IntegrationListResponse 集成列表响应
+type IntegrationListResponse struct {
+	Integrations []IntegrationInfo    `json:"integrations"`
+	Email        EmailMetricsResponse `json:"email"`
+	Total        int                  `json:"total"`
+}
+
+// ListIntegrations godoc
+// @Summary      获取集成状态与指标
+// @Description  列出已注册的集成实例及其发送指标和最近一次健康检查结果，同时返回邮件通知统计
+// @Tags         integrations
+// @Produce      json
+// @Success      200  {object}  IntegrationListResponse
+// @Router       /api/v1/integrations [get]
+func (h *ServiceHandler) ListIntegrations(c *gin.Context) {
+	health := h.integrationHealth(c.Request.Context())
+	metrics := h.integrations.GetMetrics()
+
+	names := h.integrations.Names()
+	sort.Strings(names)
+
+	items := make([]IntegrationInfo, 0, len(names))
+	for _, name := range names {
+		info := IntegrationInfo{
+			Name:    name,
+			Type:    h.integrations.TypeOf(name),
+			Metrics: toMetricsResponse(metrics[name]),
+		}
+		if result, ok := health[name]; ok {
+			info.Health = &result
+		}
+		items = append(items, info)
+	}
+
+	c.JSON(http.StatusOK, IntegrationListResponse{
+		Integrations: items,
+		Email:        h.emailMetrics(),
+		Total:        len(items),
+	})
+}
+
+// TestNotificationResult 测试通知结果
+type TestNotificationResult struct {
+	Channel    string `json:"channel"`
+	Success    bool   `json:"success"`
+	Error      string `json:"error,omitempty"`
+	Retryable  bool   `json:"retryable,omitempty"`
+	DurationMs int64  `json:"duration_ms"`
+}
+
+// TestIntegration godoc
+// @Summary      向集成实例发送测试通知
+// @Description  使用测试告警向指定集成实例发送一次通知，测试告警不写入数据库
+// @Tags         integrations
+// @Produce      json
+// @Param        name  path  string  true  "集成实例名称"
+// @Success      200  {object}  TestNotificationResult
+// @Failure      404  {object}  ErrorResponse
+// @Failure      502  {object}  TestNotificationResult
+// @Router       /api/v1/integrations/{name}/test [post]
+func (h *ServiceHandler) TestIntegration(c *gin.Context) {
+	name := c.Param("name")
+	if !h.integrations.Has(name) {
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:   "integration_not_found",
+			Message: "Integration instance not found",
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), testNotificationTimeout)
+	defer cancel()
+
+	startTime := time.Now()
+	err := h.integrations.SendAlertTo(ctx, name, notifier.NewTestAlert(name))
+	h.respondTest(c, name, err, time.Since(startTime))
+}
+
+// TestChannelRequest 渠道测试请求，config与规则动作的config相同
+type TestChannelRequest struct {
+	Config map[string]interface{} `json:"config" binding:"required"`
+}
+
+// TestChannel godoc
+// @Summary      向通知渠道发送测试通知
+// @Description  按规则动作的配置格式（email/webhook/slack/pagerduty/dingtalk/wechat/telegram/custom/integration）同步发送一次测试通知，不经过投递队列与速率限制
+// @Tags         integrations
+// @Accept       json
+// @Produce      json
+// @Param        channel  path  string              true  "渠道类型"
+// @Param        request  body  TestChannelRequest  true  "渠道配置"
+// @Success      200  {object}  TestNotificationResult
+// @Failure      400  {object}  ErrorResponse
+// @Failure      502  {object}  TestNotificationResult
+// @Router       /api/v1/channels/{channel}/test [post]
+func (h *ServiceHandler) TestChannel(c *gin.Context) {
+	var req TestChannelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	channel := c.Param("channel")
+	action := &rules.Action{
+		Type:    rules.ActionType(channel),
+		Enabled: true,
+		Config:  req.Config,
+	}
+	if !isTestableChannel(action.Type) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "unsupported_channel",
+			Message: "Unsupported notification channel: " + channel,
+		})
+		return
+	}
+	if err := rules.ValidateAction(action); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_config",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), testNotificationTimeout)
+	defer cancel()
+
+	result := h.executor.SendTest(ctx, action)
+	h.respondTest(c, channel, result.Error, result.Duration)
+}
+
+// respondTest 输出测试通知结果，发送失败返回502
+func (h *ServiceHandler) respondTest(c *gin.Context, channel string, err error, duration time.Duration) {
+	result := TestNotificationResult{
+		Channel:    channel,
+		Success:    err == nil,
+		DurationMs: duration.Milliseconds(),
+	}
+
+	if err != nil {
+		result.Error = err.Error()
+		var integrationErr *integrations.IntegrationError
+		if errors.As(err, &integrationErr) {
+			result.Retryable = integrationErr.Retryable
+		}
+
+		h.logger.Warn("Test notification failed",
+			zap.String("channel", channel),
+			zap.String("actor_id", c.GetHeader("X-Actor-ID")),
+			zap.Error(err),
+		)
+		c.JSON(http.StatusBadGateway, result)
+		return
+	}
+
+	h.logger.Info("Test notification sent",
+		zap.String("channel", channel),
+		zap.String("actor_id", c.GetHeader("X-Actor-ID")),
+	)
+	c.JSON(http.StatusOK, result)
+}
+
+// integrationHealth 返回集成健康检查结果，结果在integrationHealthTTL内复用
+func (h *ServiceHandler) integrationHealth(ctx context.Context) map[string]IntegrationHealth {
+	h.healthMu.Lock()
+	defer h.healthMu.Unlock()
+
+	if h.healthResults != nil && time.Since(h.healthChecked) < integrationHealthTTL {
+		return h.healthResults
+	}
+
+	checkCtx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+
+	results := make(map[string]IntegrationHealth)
+	for name, err := range h.integrations.HealthCheck(checkCtx) {
+		results[name] = toHealth(err)
+	}
+
+	h.healthResults = results
+	h.healthChecked = time.Now()
+	return results
+}
+
+// pingDatabase 检查数据库连接
+func (h *ServiceHandler) pingDatabase(ctx context.Context) error {
+	sqlDB, err := h.db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
+
+// emailMetrics 转换邮件通知统计
+func (h *ServiceHandler) emailMetrics() EmailMetricsResponse {
+	if h.emailNotifier == nil {
+		return EmailMetricsResponse{}
+	}
+
+	stats := h.emailNotifier.GetStats()
+	return EmailMetricsResponse{
+		TotalSent:   stats.TotalSent,
+		TotalFailed: stats.TotalFailed,
+		TotalRetry:  stats.TotalRetried,
+		QueueLength: stats.QueueLength,
+		LastSentAt:  optionalTime(stats.LastSentTime),
+		LastErrorAt: optionalTime(stats.LastErrorTime),
+		LastError:   stats.LastError,
+	}
+}
+
+// isTestableChannel 可通过TestChannel测试的动作类型
+func isTestableChannel(actionType rules.ActionType) bool {
+	switch actionType {
+	case rules.ActionTypeEmail, rules.ActionTypeWebhook, rules.ActionTypeSlack,
+		rules.ActionTypePagerDuty, rules.ActionTypeDingTalk, rules.ActionTypeWeChat,
+		rules.ActionTypeTelegram, rules.ActionTypeCustom, rules.ActionTypeIntegration:
+		return true
+	}
+	return false
+}
+
+// overallStatus 汇总健康状态：基础依赖失败为unhealthy，仅集成失败为degraded
+func overallStatus(checks, integrationResults map[string]IntegrationHealth) string {
+	for _, check := range checks {
+		if !check.Healthy {
+			return "unhealthy"
+		}
+	}
+	for _, result := range integrationResults {
+		if !result.Healthy {
+			return "degraded"
+		}
+	}
+	return "healthy"
+}
+
+func toHealth(err error) IntegrationHealth {
+	if err != nil {
+		return IntegrationHealth{Healthy: false, Error: err.Error()}
+	}
+	return IntegrationHealth{Healthy: true}
+}
+
+func toMetricsResponse(metrics *integrations.IntegrationMetrics) *IntegrationMetricsResponse {
+	if metrics == nil {
+		return &IntegrationMetricsResponse{}
+	}
+	return &IntegrationMetricsResponse{
+		TotalSent:         metrics.TotalSent,
+		SuccessCount:      metrics.SuccessCount,
+		FailureCount:      metrics.FailureCount,
+		LastSentAt:        optionalTime(metrics.LastSentTime),
+		AvgResponseTimeMs: metrics.AvgResponseTime.Milliseconds(),
+	}
+}
+
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+// ErrorResponse 错误响应
+type ErrorResponse struct {
+	Error   string `json:"error"`
+	Message string `json:"message"`
+}
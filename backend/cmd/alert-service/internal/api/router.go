@@ -0,0 +1,40 @@
+package api
+
+import (
+	"github.com/edgelink/backend/cmd/alert-service/internal/rules"
+	"github.com/edgelink/backend/internal/domain"
+	"github.com/edgelink/backend/internal/metrics"
+	"github.com/edgelink/backend/internal/middleware"
+	"github.com/edgelink/backend/internal/tracing"
+	"github.com/gin-gonic/gin"
+)
+
+// SetupRouter 配置告警服务路由
+func SetupRouter(
+	serviceHandler *ServiceHandler,
+	rulesHandler *rules.Handler,
+	adminAuth *middleware.AdminAuth,
+	m *metrics.Metrics,
+) *gin.Engine {
+	r := gin.Default()
+	r.Use(middleware.RequestID(), tracing.GinMiddleware(), m.GinMiddleware())
+
+	// 健康检查端点（供容器探针使用，无需认证）
+	r.GET("/health", serviceHandler.Health)
+	r.GET("/ready", serviceHandler.Ready)
+
+	// API v1路由组，需要管理员令牌
+	v1 := r.Group("/api/v1")
+	v1.Use(adminAuth.Middleware(domain.RoleAdmin))
+	{
+		// 规则管理（全局规则要求超级管理员）
+		rulesHandler.RegisterRoutes(v1, adminAuth.RequireRole(domain.RoleSuperAdmin))
+
+		// 集成状态与测试通知
+		v1.GET("/integrations", serviceHandler.ListIntegrations)
+		v1.POST("/integrations/:name/test", serviceHandler.TestIntegration)
+		v1.POST("/channels/:channel/test", serviceHandler.TestChannel)
+	}
+
+	return r
+}
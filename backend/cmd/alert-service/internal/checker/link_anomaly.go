@@ -0,0 +1,408 @@
+package checker
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/edgelink/backend/internal/domain"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	// linkSampleBatchSize 每次读取的样本数
+	linkSampleBatchSize = 5000
+	// linkSampleMaxBatches 单轮检查最多处理的批次，积压的样本留到下一轮
+	linkSampleMaxBatches = 20
+	// linkSampleLookback 服务启动后首轮检查回看的样本时长，更早的样本已反映在基线中
+	linkSampleLookback = time.Hour
+	// linkMetricsPruneBatch 每轮清理的过期样本上限
+	linkMetricsPruneBatch = 10000
+)
+
+// linkMetrics 参与基线检测的指标
+var linkMetrics = []domain.LinkMetricType{domain.LinkMetricLatency, domain.LinkMetricPacketLoss}
+
+// linkKey 设备对与指标
+type linkKey struct {
+	DeviceID     uuid.UUID
+	PeerDeviceID uuid.UUID
+	Metric       domain.LinkMetricType
+}
+
+// linkEvaluation 单条链路一轮样本的处理结果
+type linkEvaluation struct {
+	baseline  *domain.LinkBaseline
+	prevAt    *time.Time
+	lastValue float64
+	lastZ     float64
+	sustained bool
+	recovered bool
+}
+
+// CheckLinkAnomalies 用新上报的样本更新每个设备对的EWMA基线，延迟或丢包持续偏离基线时产生问题
+// 基线建立前（样本数不足）使用配置中的固定阈值
+func (tc *ThresholdChecker) CheckLinkAnomalies(ctx context.Context, thresholds *thresholdSet) []HealthIssue {
+	samples := tc.readNewSamples(ctx)
+	tc.pruneSamples(ctx)
+
+	if len(samples) == 0 {
+		return nil
+	}
+
+	// 按设备对分组，保持样本顺序
+	pairs := make(map[linkKey][]*domain.LinkMetric)
+	deviceSet := make(map[uuid.UUID]bool)
+	for _, sample := range samples {
+		for _, metric := range linkMetrics {
+			if _, ok := sample.Value(metric); !ok {
+				continue
+			}
+			key := linkKey{DeviceID: sample.DeviceID, PeerDeviceID: sample.PeerDeviceID, Metric: metric}
+			pairs[key] = append(pairs[key], sample)
+		}
+		deviceSet[sample.DeviceID] = true
+	}
+
+	deviceIDs := make([]uuid.UUID, 0, len(deviceSet))
+	for id := range deviceSet {
+		deviceIDs = append(deviceIDs, id)
+	}
+
+	devices, err := tc.deviceRepo.FindByIDs(ctx, deviceIDs)
+	if err != nil {
+		tc.logger.Error("Failed to load devices for link anomaly check", zap.Error(err))
+		return nil
+	}
+	deviceByID := make(map[uuid.UUID]*domain.Device, len(devices))
+	for i := range devices {
+		deviceByID[devices[i].ID] = &devices[i]
+	}
+
+	existing, err := tc.linkMetricRepo.FindBaselinesByDevices(ctx, deviceIDs)
+	if err != nil {
+		tc.logger.Error("Failed to load link baselines", zap.Error(err))
+		return nil
+	}
+	baselines := make(map[linkKey]*domain.LinkBaseline, len(existing))
+	for _, baseline := range existing {
+		baselines[linkKey{DeviceID: baseline.DeviceID, PeerDeviceID: baseline.PeerDeviceID, Metric: baseline.Metric}] = baseline
+	}
+
+	var issues []HealthIssue
+	recovered := make(map[linkKey]bool)
+	now := time.Now()
+
+	for key, pairSamples := range pairs {
+		device, ok := deviceByID[key.DeviceID]
+		if !ok {
+			continue
+		}
+
+		var orgID uuid.UUID
+		if device.VirtualNetwork != nil {
+			orgID = device.VirtualNetwork.OrganizationID
+			thresholds.networkOrgs[device.VirtualNetworkID] = orgID
+		} else {
+			orgID = tc.networkOrganization(ctx, thresholds, device.VirtualNetworkID)
+		}
+		limits := thresholds.resolve(device, orgID)
+
+		eval := tc.evaluateLink(key, baselines[key], pairSamples, &limits)
+		if eval == nil {
+			continue
+		}
+
+		saved, err := tc.linkMetricRepo.SaveBaseline(ctx, eval.baseline, eval.prevAt)
+		if err != nil {
+			tc.logger.Error("Failed to save link baseline",
+				zap.String("device_id", key.DeviceID.String()),
+				zap.String("peer_device_id", key.PeerDeviceID.String()),
+				zap.String("metric", string(key.Metric)),
+				zap.Error(err),
+			)
+			continue
+		}
+		if !saved {
+			// 其他副本已处理这批样本
+			continue
+		}
+
+		if eval.sustained {
+			issues = append(issues, newLinkIssue(device, key, eval, &limits, now))
+		}
+		if eval.recovered {
+			recovered[linkKey{DeviceID: key.DeviceID, Metric: key.Metric}] = true
+		}
+	}
+
+	tc.resolveRecovered(ctx, recovered)
+
+	return issues
+}
+
+// evaluateLink 依次处理样本，更新基线并判断是否持续异常，没有新样本时返回nil
+func (tc *ThresholdChecker) evaluateLink(key linkKey, baseline *domain.LinkBaseline, samples []*domain.LinkMetric, limits *limits) *linkEvaluation {
+	eval := &linkEvaluation{}
+	if baseline == nil {
+		baseline = &domain.LinkBaseline{
+			DeviceID:     key.DeviceID,
+			PeerDeviceID: key.PeerDeviceID,
+			Metric:       key.Metric,
+		}
+	} else if baseline.LastSampleAt != nil {
+		prev := *baseline.LastSampleAt
+		eval.prevAt = &prev
+	}
+	eval.baseline = baseline
+
+	wasAlerting := baseline.Alerting
+	processed := 0
+
+	for _, sample := range samples {
+		// 跳过基线已包含的样本（服务重启后回看窗口内的样本）
+		if baseline.LastSampleAt != nil && !sample.RecordedAt.After(*baseline.LastSampleAt) {
+			continue
+		}
+		value, _ := sample.Value(key.Metric)
+
+		anomalous, z := tc.isAnomalous(baseline, key.Metric, value, limits)
+		tc.updateBaseline(baseline, value, anomalous, limits)
+
+		if anomalous && limits.Enabled {
+			if baseline.AnomalousSince == nil {
+				since := sample.RecordedAt
+				baseline.AnomalousSince = &since
+			}
+		} else {
+			baseline.AnomalousSince = nil
+		}
+
+		v := value
+		at := sample.RecordedAt
+		baseline.LastValue = &v
+		baseline.LastSampleAt = &at
+		eval.lastValue = value
+		eval.lastZ = z
+		processed++
+	}
+
+	if processed == 0 {
+		return nil
+	}
+
+	eval.sustained = baseline.AnomalousSince != nil &&
+		baseline.LastSampleAt.Sub(*baseline.AnomalousSince) >= limits.Sustain
+	if eval.sustained {
+		baseline.Alerting = true
+	} else if baseline.AnomalousSince == nil {
+		baseline.Alerting = false
+	}
+	eval.recovered = wasAlerting && !baseline.Alerting
+
+	return eval
+}
+
+// isAnomalous 判断单个样本是否异常，返回偏离基线的标准差倍数
+// 只关注变差方向（延迟升高、丢包增加）
+func (tc *ThresholdChecker) isAnomalous(baseline *domain.LinkBaseline, metric domain.LinkMetricType, value float64, limits *limits) (bool, float64) {
+	if max := limits.absoluteMax(metric); max > 0 && value >= max {
+		return true, zScore(baseline, value)
+	}
+
+	if baseline.SampleCount < tc.cfg.Alert.AnomalyMinSamples {
+		// 基线尚未建立，使用固定阈值
+		return value >= tc.coldStartLimit(metric), 0
+	}
+
+	delta := value - baseline.Mean
+	if delta < limits.minDelta(metric) {
+		return false, zScore(baseline, value)
+	}
+	return delta >= limits.Sigma*baseline.StdDev(), zScore(baseline, value)
+}
+
+// coldStartLimit 基线建立前使用的固定阈值
+func (tc *ThresholdChecker) coldStartLimit(metric domain.LinkMetricType) float64 {
+	if metric == domain.LinkMetricPacketLoss {
+		if tc.cfg.Alert.PacketLossThreshold > 0 {
+			return tc.cfg.Alert.PacketLossThreshold
+		}
+		return math.Inf(1)
+	}
+	if tc.cfg.Alert.HighLatencyThreshold > 0 {
+		return float64(tc.cfg.Alert.HighLatencyThreshold)
+	}
+	return math.Inf(1)
+}
+
+// updateBaseline 以EWMA更新均值与方差
+// 样本数不足时使用累积平均以尽快收敛；异常样本按上界截断后参与更新，
+// 持续的水平变化（如线路切换）会被逐步吸收，而短时尖峰不会拉高基线
+func (tc *ThresholdChecker) updateBaseline(baseline *domain.LinkBaseline, value float64, anomalous bool, limits *limits) {
+	if baseline.SampleCount == 0 {
+		baseline.Mean = value
+		baseline.Variance = 0
+		baseline.SampleCount = 1
+		return
+	}
+
+	if anomalous && baseline.SampleCount >= tc.cfg.Alert.AnomalyMinSamples {
+		upper := baseline.Mean + math.Max(limits.Sigma*baseline.StdDev(), limits.minDelta(baseline.Metric))
+		value = math.Min(value, upper)
+	}
+
+	alpha := tc.cfg.Alert.AnomalyEWMAAlpha
+	if alpha <= 0 || alpha > 1 {
+		alpha = 0.05
+	}
+	if warmup := 1 / float64(baseline.SampleCount+1); warmup > alpha {
+		alpha = warmup
+	}
+
+	diff := value - baseline.Mean
+	increment := alpha * diff
+	baseline.Mean += increment
+	baseline.Variance = (1 - alpha) * (baseline.Variance + diff*increment)
+	baseline.SampleCount++
+}
+
+// resolveRecovered 设备某项指标的所有链路都恢复后自动解决对应告警
+func (tc *ThresholdChecker) resolveRecovered(ctx context.Context, recovered map[linkKey]bool) {
+	if tc.resolver == nil {
+		return
+	}
+
+	for key := range recovered {
+		count, err := tc.linkMetricRepo.CountAlertingBaselines(ctx, key.DeviceID, key.Metric)
+		if err != nil {
+			tc.logger.Error("Failed to count alerting links", zap.Error(err))
+			continue
+		}
+		if count > 0 {
+			continue
+		}
+
+		if err := tc.resolver.ResolveDeviceAlerts(ctx, key.DeviceID, linkAlertType(key.Metric)); err != nil {
+			tc.logger.Error("Failed to resolve recovered link alerts",
+				zap.String("device_id", key.DeviceID.String()),
+				zap.String("metric", string(key.Metric)),
+				zap.Error(err),
+			)
+		}
+	}
+}
+
+// readNewSamples 读取上一轮之后新上报的样本
+func (tc *ThresholdChecker) readNewSamples(ctx context.Context) []*domain.LinkMetric {
+	var since time.Time
+	if tc.sampleCursor == 0 {
+		since = time.Now().Add(-linkSampleLookback)
+	}
+
+	var samples []*domain.LinkMetric
+	for i := 0; i < linkSampleMaxBatches; i++ {
+		batch, err := tc.linkMetricRepo.ListAfter(ctx, tc.sampleCursor, since, linkSampleBatchSize)
+		if err != nil {
+			tc.logger.Error("Failed to read link metrics", zap.Error(err))
+			break
+		}
+		if len(batch) == 0 {
+			break
+		}
+
+		samples = append(samples, batch...)
+		tc.sampleCursor = batch[len(batch)-1].ID
+
+		if len(batch) < linkSampleBatchSize {
+			break
+		}
+	}
+
+	return samples
+}
+
+// pruneSamples 清理超过保留期的样本
+func (tc *ThresholdChecker) pruneSamples(ctx context.Context) {
+	retention := tc.cfg.Alert.LinkMetricsRetention
+	if retention <= 0 {
+		return
+	}
+
+	deleted, err := tc.linkMetricRepo.DeleteOlderThan(ctx, time.Now().Add(-retention), linkMetricsPruneBatch)
+	if err != nil {
+		tc.logger.Warn("Failed to prune link metrics", zap.Error(err))
+		return
+	}
+	if deleted > 0 {
+		tc.logger.Debug("Pruned expired link metrics", zap.Int64("deleted", deleted))
+	}
+}
+
+// newLinkIssue 构建链路异常问题
+func newLinkIssue(device *domain.Device, key linkKey, eval *linkEvaluation, limits *limits, now time.Time) HealthIssue {
+	baseline := eval.baseline
+	issueType := "high_latency"
+	severity := "medium"
+	if key.Metric == domain.LinkMetricPacketLoss {
+		issueType = "packet_loss"
+		if eval.lastValue >= 0.2 {
+			severity = "high"
+		}
+	} else if eval.lastZ >= 2*limits.Sigma {
+		severity = "high"
+	}
+
+	metadata := map[string]interface{}{
+		"device_name":        device.Name,
+		"virtual_network_id": device.VirtualNetworkID.String(),
+		"peer_device_id":     key.PeerDeviceID.String(),
+		"metric":             string(key.Metric),
+		"current_value":      roundTo(eval.lastValue, 4),
+		"baseline_mean":      roundTo(baseline.Mean, 4),
+		"baseline_stddev":    roundTo(baseline.StdDev(), 4),
+		"baseline_samples":   baseline.SampleCount,
+		"deviation_sigma":    roundTo(eval.lastZ, 2),
+		"sigma_threshold":    limits.Sigma,
+		"anomalous_since":    *baseline.AnomalousSince,
+		"sustain":            limits.Sustain.String(),
+	}
+	if key.Metric == domain.LinkMetricLatency {
+		metadata["avg_latency_ms"] = int(math.Round(eval.lastValue))
+	}
+	if len(limits.OverrideIDs) > 0 {
+		metadata["threshold_overrides"] = limits.OverrideIDs
+	}
+
+	return HealthIssue{
+		Type:       issueType,
+		DeviceID:   device.ID.String(),
+		Severity:   severity,
+		Message:    "Link metric deviates from its baseline for a sustained period",
+		Metadata:   metadata,
+		DetectedAt: now,
+	}
+}
+
+// linkAlertType 指标对应的告警类型
+func linkAlertType(metric domain.LinkMetricType) domain.AlertType {
+	if metric == domain.LinkMetricPacketLoss {
+		return domain.AlertTypePacketLoss
+	}
+	return domain.AlertTypeHighLatency
+}
+
+// zScore 样本偏离基线的标准差倍数，基线方差为0时返回0
+func zScore(baseline *domain.LinkBaseline, value float64) float64 {
+	std := baseline.StdDev()
+	if std == 0 {
+		return 0
+	}
+	return (value - baseline.Mean) / std
+}
+
+func roundTo(value float64, places int) float64 {
+	factor := math.Pow(10, float64(places))
+	return math.Round(value*factor) / factor
+}
@@ -0,0 +1,524 @@
+package checker
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/edgelink/backend/internal/domain"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	// clockSkewFreshness 只使用该时长内测得的时钟偏差，设备停止上报后不再告警
+	clockSkewFreshness = 15 * time.Minute
+	// signatureClockSkewLimit 设备签名时间戳的容忍范围，超过后设备请求会被拒绝
+	signatureClockSkewLimit = 5 * time.Minute
+	// statusEventRetention 设备状态变化记录保留时长
+	statusEventRetention = 7 * 24 * time.Hour
+	// statusEventPruneBatch 每轮清理的过期状态记录上限
+	statusEventPruneBatch = 10000
+)
+
+// CheckOperational 检查隧道抖动、中继回落、地址池使用率、注册密钥耗尽、设备时钟偏差和待审批设备
+// 每项检测成功执行后，未再出现的问题对应的告警会被自动解决
+func (tc *ThresholdChecker) CheckOperational(ctx context.Context, thresholds *thresholdSet) []HealthIssue {
+	var issues []HealthIssue
+	now := time.Now()
+
+	// 中继回落与地址池检查共用网络列表
+	networks, err := tc.vnRepo.FindAll(ctx)
+	if err != nil {
+		tc.logger.Error("Failed to load virtual networks", zap.Error(err))
+	}
+	byID := make(map[uuid.UUID]*domain.VirtualNetwork, len(networks))
+	for i := range networks {
+		byID[networks[i].ID] = &networks[i]
+		thresholds.networkOrgs[networks[i].ID] = networks[i].OrganizationID
+	}
+
+	detectors := []struct {
+		alertType domain.AlertType
+		run       func() ([]HealthIssue, bool)
+	}{
+		{domain.AlertTypeDeviceFlapping, func() ([]HealthIssue, bool) { return tc.checkFlapping(ctx, now) }},
+		{domain.AlertTypeRelayFallback, func() ([]HealthIssue, bool) { return tc.checkRelayFallback(ctx, byID, now) }},
+		{domain.AlertTypeIPPoolExhaustion, func() ([]HealthIssue, bool) { return tc.checkIPPools(ctx, networks, err == nil, now) }},
+		{domain.AlertTypeEnrollmentKeyExhaustion, func() ([]HealthIssue, bool) { return tc.checkEnrollmentKeys(ctx, now) }},
+		{domain.AlertTypeClockSkew, func() ([]HealthIssue, bool) { return tc.checkClockSkew(ctx, now) }},
+		{domain.AlertTypeDevicePendingApproval, func() ([]HealthIssue, bool) { return tc.checkPendingApprovals(ctx, now) }},
+		{domain.AlertTypePostureViolation, func() ([]HealthIssue, bool) { return tc.checkPostureViolations(ctx, now) }},
+	}
+
+	for _, detector := range detectors {
+		found, ok := detector.run()
+		issues = append(issues, found...)
+		if ok {
+			tc.resolveCleared(ctx, detector.alertType, found)
+		}
+	}
+
+	return issues
+}
+
+// checkFlapping 检查窗口内上下线次数达到阈值的设备
+func (tc *ThresholdChecker) checkFlapping(ctx context.Context, now time.Time) ([]HealthIssue, bool) {
+	window := tc.cfg.Alert.FlapWindow
+	threshold := tc.cfg.Alert.FlapThreshold
+	if window <= 0 || threshold <= 0 {
+		return nil, false
+	}
+
+	tc.pruneStatusEvents(ctx, now)
+
+	counts, err := tc.statusEventRepo.CountTransitionsSince(ctx, now.Add(-window), threshold)
+	if err != nil {
+		tc.logger.Error("Failed to count device status transitions", zap.Error(err))
+		return nil, false
+	}
+	if len(counts) == 0 {
+		return nil, true
+	}
+
+	ids := make([]uuid.UUID, 0, len(counts))
+	for _, count := range counts {
+		ids = append(ids, count.DeviceID)
+	}
+	devices, err := tc.deviceRepo.FindByIDs(ctx, ids)
+	if err != nil {
+		tc.logger.Error("Failed to load flapping devices", zap.Error(err))
+		return nil, false
+	}
+	deviceByID := make(map[uuid.UUID]*domain.Device, len(devices))
+	for i := range devices {
+		deviceByID[devices[i].ID] = &devices[i]
+	}
+
+	issues := make([]HealthIssue, 0, len(counts))
+	for _, count := range counts {
+		device, ok := deviceByID[count.DeviceID]
+		if !ok {
+			continue
+		}
+
+		severity := "medium"
+		if count.Transitions >= 2*threshold {
+			severity = "high"
+		}
+
+		issues = append(issues, HealthIssue{
+			Type:     "device_flapping",
+			DeviceID: device.ID.String(),
+			Severity: severity,
+			Message:  "Device tunnel is repeatedly going offline and online",
+			Metadata: map[string]interface{}{
+				"device_name":        device.Name,
+				"virtual_network_id": device.VirtualNetworkID.String(),
+				"transitions":        count.Transitions,
+				"flap_window":        window.String(),
+				"flap_threshold":     threshold,
+				"currently_online":   count.LastOnline,
+				"last_change_at":     count.LastChange.Format(time.RFC3339),
+			},
+			DetectedAt: now,
+		})
+	}
+
+	return issues, true
+}
+
+// checkRelayFallback 检查经TURN中继建立的会话比例过高的虚拟网络
+func (tc *ThresholdChecker) checkRelayFallback(ctx context.Context, networks map[uuid.UUID]*domain.VirtualNetwork, now time.Time) ([]HealthIssue, bool) {
+	window := tc.cfg.Alert.RelayFallbackWindow
+	threshold := tc.cfg.Alert.RelayFallbackThreshold
+	if window <= 0 || threshold <= 0 {
+		return nil, false
+	}
+
+	stats, err := tc.sessionRepo.GetRelayStatsByNetwork(ctx, now.Add(-window))
+	if err != nil {
+		tc.logger.Error("Failed to query relay statistics", zap.Error(err))
+		return nil, false
+	}
+
+	var issues []HealthIssue
+	for _, stat := range stats {
+		if stat.TotalSessions < int64(tc.cfg.Alert.RelayFallbackMinSessions) {
+			continue
+		}
+		rate := stat.RelayRate()
+		if rate < threshold {
+			continue
+		}
+
+		severity := "medium"
+		if rate >= 0.9 {
+			severity = "high"
+		}
+
+		metadata := map[string]interface{}{
+			"virtual_network_id": stat.VirtualNetworkID.String(),
+			"relay_sessions":     stat.RelaySessions,
+			"total_sessions":     stat.TotalSessions,
+			"relay_rate":         rate,
+			"relay_threshold":    threshold,
+			"window":             window.String(),
+		}
+		if vn, ok := networks[stat.VirtualNetworkID]; ok {
+			metadata["network_name"] = vn.Name
+			metadata["organization_id"] = vn.OrganizationID.String()
+		}
+
+		issues = append(issues, HealthIssue{
+			Type:       "relay_fallback",
+			Subject:    "network:" + stat.VirtualNetworkID.String(),
+			Severity:   severity,
+			Message:    "High share of sessions in the network fell back to TURN relay",
+			Metadata:   metadata,
+			DetectedAt: now,
+		})
+	}
+
+	return issues, true
+}
+
+// checkIPPools 检查地址池使用率超过阈值的虚拟网络
+func (tc *ThresholdChecker) checkIPPools(ctx context.Context, networks []domain.VirtualNetwork, loaded bool, now time.Time) ([]HealthIssue, bool) {
+	threshold := tc.cfg.Alert.IPPoolThreshold
+	if !loaded || threshold <= 0 {
+		return nil, false
+	}
+
+	counts, err := tc.deviceRepo.CountByVirtualNetworks(ctx)
+	if err != nil {
+		tc.logger.Error("Failed to count devices per network", zap.Error(err))
+		return nil, false
+	}
+
+	var issues []HealthIssue
+	for i := range networks {
+		vn := &networks[i]
+		capacity, err := vn.UsableAddresses()
+		if err != nil {
+			tc.logger.Warn("Invalid virtual network CIDR",
+				zap.String("virtual_network_id", vn.ID.String()),
+				zap.String("cidr", vn.CIDR),
+				zap.Error(err),
+			)
+			continue
+		}
+
+		allocated := counts[vn.ID]
+		utilization := 1.0
+		if capacity > 0 {
+			utilization = float64(allocated) / float64(capacity)
+		}
+		if utilization < threshold {
+			continue
+		}
+
+		free := capacity - allocated
+		if free < 0 {
+			free = 0
+		}
+		severity := "high"
+		if free == 0 || utilization >= 0.95 {
+			severity = "critical"
+		}
+
+		issues = append(issues, HealthIssue{
+			Type:     "ip_pool_exhaustion",
+			Subject:  "network:" + vn.ID.String(),
+			Severity: severity,
+			Message:  "Virtual network address pool is nearly exhausted",
+			Metadata: map[string]interface{}{
+				"virtual_network_id": vn.ID.String(),
+				"network_name":       vn.Name,
+				"organization_id":    vn.OrganizationID.String(),
+				"cidr":               vn.CIDR,
+				"allocated":          allocated,
+				"capacity":           capacity,
+				"free":               free,
+				"utilization":        utilization,
+				"threshold":          threshold,
+			},
+			DetectedAt: now,
+		})
+	}
+
+	return issues, true
+}
+
+// checkEnrollmentKeys 检查接近使用次数上限或即将过期的预共享密钥
+// 已耗尽或在告警窗口内刚过期的密钥以更高严重程度告警
+func (tc *ThresholdChecker) checkEnrollmentKeys(ctx context.Context, now time.Time) ([]HealthIssue, bool) {
+	usageThreshold := tc.cfg.Alert.PSKUsageThreshold
+	expiryWindow := tc.cfg.Alert.PSKExpiryWindow
+	if usageThreshold <= 0 && expiryWindow <= 0 {
+		return nil, false
+	}
+
+	keys, err := tc.pskRepo.FindWithLimits(ctx, now.Add(-expiryWindow))
+	if err != nil {
+		tc.logger.Error("Failed to query pre-shared keys", zap.Error(err))
+		return nil, false
+	}
+
+	var issues []HealthIssue
+	for i := range keys {
+		psk := &keys[i]
+		var reasons []string
+		severity := ""
+		raise := func(reason, level string) {
+			reasons = append(reasons, reason)
+			if severityRank(level) > severityRank(severity) {
+				severity = level
+			}
+		}
+
+		metadata := map[string]interface{}{
+			"psk_id":          psk.ID.String(),
+			"organization_id": psk.OrganizationID.String(),
+			"used_count":      psk.UsedCount,
+		}
+		if psk.Name != nil {
+			metadata["psk_name"] = *psk.Name
+		}
+
+		if psk.MaxUses != nil {
+			remaining := *psk.MaxUses - psk.UsedCount
+			metadata["max_uses"] = *psk.MaxUses
+			metadata["remaining_uses"] = remaining
+			switch {
+			case remaining <= 0:
+				raise("exhausted", "high")
+			case usageThreshold > 0 && *psk.MaxUses > 0 && float64(psk.UsedCount)/float64(*psk.MaxUses) >= usageThreshold:
+				level := "medium"
+				if remaining <= 1 {
+					level = "high"
+				}
+				raise("nearing_max_uses", level)
+			}
+		}
+
+		if psk.ExpiresAt != nil {
+			until := psk.ExpiresAt.Sub(now)
+			metadata["expires_at"] = psk.ExpiresAt.Format(time.RFC3339)
+			switch {
+			case until <= 0:
+				raise("expired", "high")
+			case expiryWindow > 0 && until <= expiryWindow:
+				level := "medium"
+				if until <= 24*time.Hour {
+					level = "high"
+				}
+				metadata["expires_in"] = until.Round(time.Minute).String()
+				raise("expiring", level)
+			}
+		}
+
+		if len(reasons) == 0 {
+			continue
+		}
+		metadata["reasons"] = reasons
+
+		issues = append(issues, HealthIssue{
+			Type:       "enrollment_key_exhaustion",
+			Subject:    "psk:" + psk.ID.String(),
+			Severity:   severity,
+			Message:    "Enrollment key is nearing its usage limit or expiry",
+			Metadata:   metadata,
+			DetectedAt: now,
+		})
+	}
+
+	return issues, true
+}
+
+// checkClockSkew 检查请求时间戳与服务器时间偏差过大的设备
+func (tc *ThresholdChecker) checkClockSkew(ctx context.Context, now time.Time) ([]HealthIssue, bool) {
+	threshold := tc.cfg.Alert.ClockSkewThreshold
+	if threshold <= 0 {
+		return nil, false
+	}
+
+	devices, err := tc.deviceRepo.FindClockSkewed(ctx, now.Add(-clockSkewFreshness), threshold.Milliseconds())
+	if err != nil {
+		tc.logger.Error("Failed to query clock skewed devices", zap.Error(err))
+		return nil, false
+	}
+
+	issues := make([]HealthIssue, 0, len(devices))
+	for i := range devices {
+		device := &devices[i]
+		if device.ClockSkewMs == nil {
+			continue
+		}
+
+		skew := time.Duration(*device.ClockSkewMs) * time.Millisecond
+		severity := "medium"
+		if time.Duration(math.Abs(float64(skew))) >= signatureClockSkewLimit {
+			severity = "high"
+		}
+
+		metadata := map[string]interface{}{
+			"device_name":        device.Name,
+			"virtual_network_id": device.VirtualNetworkID.String(),
+			"clock_skew_ms":      *device.ClockSkewMs,
+			"clock_skew":         skew.String(),
+			"skew_threshold":     threshold.String(),
+		}
+		if device.ClockSkewAt != nil {
+			metadata["measured_at"] = device.ClockSkewAt.Format(time.RFC3339)
+		}
+
+		issues = append(issues, HealthIssue{
+			Type:       "clock_skew",
+			DeviceID:   device.ID.String(),
+			Severity:   severity,
+			Message:    "Device clock differs significantly from server time",
+			Metadata:   metadata,
+			DetectedAt: now,
+		})
+	}
+
+	return issues, true
+}
+
+// checkPendingApprovals 为每台等待审批的设备产生一条告警，批准或拒绝后自动解决
+func (tc *ThresholdChecker) checkPendingApprovals(ctx context.Context, now time.Time) ([]HealthIssue, bool) {
+	devices, err := tc.deviceRepo.FindPendingApproval(ctx)
+	if err != nil {
+		tc.logger.Error("Failed to query devices pending approval", zap.Error(err))
+		return nil, false
+	}
+
+	issues := make([]HealthIssue, 0, len(devices))
+	for i := range devices {
+		device := &devices[i]
+
+		metadata := map[string]interface{}{
+			"device_name":        device.Name,
+			"virtual_network_id": device.VirtualNetworkID.String(),
+			"platform":           string(device.Platform),
+			"registered_at":      device.CreatedAt.Format(time.RFC3339),
+		}
+		if len(device.Tags) > 0 {
+			metadata["tags"] = []string(device.Tags)
+		}
+		if device.VirtualNetwork != nil {
+			metadata["network_name"] = device.VirtualNetwork.Name
+			metadata["organization_id"] = device.VirtualNetwork.OrganizationID.String()
+		}
+
+		issues = append(issues, HealthIssue{
+			Type:       "device_pending_approval",
+			DeviceID:   device.ID.String(),
+			Severity:   "medium",
+			Message:    "New device is waiting for administrator approval",
+			Metadata:   metadata,
+			DetectedAt: now,
+		})
+	}
+
+	return issues, true
+}
+
+// checkPostureViolations 为每台不满足安全状态策略的设备产生一条告警，恢复合规或策略调整后自动解决
+// 完全隔离为high，只限制对端为medium
+func (tc *ThresholdChecker) checkPostureViolations(ctx context.Context, now time.Time) ([]HealthIssue, bool) {
+	devices, err := tc.deviceRepo.FindPostureNonCompliant(ctx)
+	if err != nil {
+		tc.logger.Error("Failed to query posture non-compliant devices", zap.Error(err))
+		return nil, false
+	}
+
+	issues := make([]HealthIssue, 0, len(devices))
+	for i := range devices {
+		device := &devices[i]
+
+		severity := "high"
+		message := "Device does not meet posture policy and is quarantined"
+		if len(device.PostureAllowedPeerTags) > 0 {
+			severity = "medium"
+			message = "Device does not meet posture policy and is restricted to allowed peers"
+		}
+
+		metadata := map[string]interface{}{
+			"device_name":        device.Name,
+			"virtual_network_id": device.VirtualNetworkID.String(),
+			"platform":           string(device.Platform),
+			"violations":         []string(device.PostureViolations),
+		}
+		if len(device.PostureAllowedPeerTags) > 0 {
+			metadata["allowed_peer_tags"] = []string(device.PostureAllowedPeerTags)
+			metadata["allowed_peer_tag_sets"] = [][]string(device.PostureAllowedPeerTagSets)
+		}
+		if device.PostureCheckedAt != nil {
+			metadata["checked_at"] = device.PostureCheckedAt.Format(time.RFC3339)
+		}
+		if device.VirtualNetwork != nil {
+			metadata["network_name"] = device.VirtualNetwork.Name
+			metadata["organization_id"] = device.VirtualNetwork.OrganizationID.String()
+		}
+
+		issues = append(issues, HealthIssue{
+			Type:       "posture_violation",
+			DeviceID:   device.ID.String(),
+			Severity:   severity,
+			Message:    message,
+			Metadata:   metadata,
+			DetectedAt: now,
+		})
+	}
+
+	return issues, true
+}
+
+// resolveCleared 解决本轮检测中已不再出现的同类告警
+func (tc *ThresholdChecker) resolveCleared(ctx context.Context, alertType domain.AlertType, issues []HealthIssue) {
+	if tc.resolver == nil {
+		return
+	}
+
+	active := make(map[string]bool, len(issues))
+	for _, issue := range issues {
+		active[issue.subject()] = true
+	}
+
+	if err := tc.resolver.ResolveCleared(ctx, alertType, active); err != nil {
+		tc.logger.Error("Failed to resolve cleared alerts",
+			zap.String("alert_type", string(alertType)),
+			zap.Error(err),
+		)
+	}
+}
+
+// pruneStatusEvents 清理超过保留期的设备状态变化记录
+func (tc *ThresholdChecker) pruneStatusEvents(ctx context.Context, now time.Time) {
+	deleted, err := tc.statusEventRepo.DeleteOlderThan(ctx, now.Add(-statusEventRetention), statusEventPruneBatch)
+	if err != nil {
+		tc.logger.Warn("Failed to prune device status events", zap.Error(err))
+		return
+	}
+	if deleted > 0 {
+		tc.logger.Debug("Pruned expired device status events", zap.Int64("deleted", deleted))
+	}
+}
+
+// severityRank 严重程度排序，用于取多个原因中最高的级别
+func severityRank(severity string) int {
+	switch severity {
+	case "critical":
+		return 4
+	case "high":
+		return 3
+	case "medium":
+		return 2
+	case "low":
+		return 1
+	default:
+		return 0
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"time"
 
+	"github.com/edgelink/backend/internal/config"
+	"github.com/edgelink/backend/internal/domain"
 	"github.com/edgelink/backend/internal/repository"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
@@ -11,51 +13,100 @@ import (
 
 // HealthIssue 健康问题
 type HealthIssue struct {
-	Type        string                 // 问题类型: "device_offline", "high_latency", "connection_failed"
-	DeviceID    string                 // 设备ID
+	Type        string                 // 问题类型: "device_offline", "high_latency", "packet_loss", "device_flapping", "relay_fallback", "ip_pool_exhaustion", "enrollment_key_exhaustion", "clock_skew", "device_pending_approval", "posture_violation", "connection_failed"
+	DeviceID    string                 // 设备ID，非设备问题为空
+	Subject     string                 // 非设备问题的对象标识，如 "network:<id>"、"psk:<id>"
 	Severity    string                 // 严重程度: "critical", "high", "medium", "low"
 	Message     string                 // 问题描述
 	Metadata    map[string]interface{} // 附加元数据
 	DetectedAt  time.Time              // 检测时间
 }
 
+// subject 问题对象标识，同一对象的同类问题合并为一条告警
+func (i HealthIssue) subject() string {
+	if i.DeviceID != "" {
+		return i.DeviceID
+	}
+	return i.Subject
+}
+
+// AlertResolver 问题消失后自动解决告警（由告警生成器实现）
+type AlertResolver interface {
+	ResolveDeviceAlerts(ctx context.Context, deviceID uuid.UUID, alertType domain.AlertType) error
+	// ResolveCleared 解决对象不在active中的同类未解决告警
+	ResolveCleared(ctx context.Context, alertType domain.AlertType, active map[string]bool) error
+}
+
 // ThresholdChecker 阈值检查器
 type ThresholdChecker struct {
-	deviceRepo  repository.DeviceRepository
-	sessionRepo repository.SessionRepository
-	logger      *zap.Logger
+	deviceRepo      repository.DeviceRepository
+	vnRepo          repository.VirtualNetworkRepository
+	linkMetricRepo  repository.LinkMetricRepository
+	thresholdRepo   repository.AnomalyThresholdRepository
+	sessionRepo     repository.SessionRepository
+	pskRepo         repository.PreSharedKeyRepository
+	statusEventRepo repository.DeviceStatusEventRepository
+	cfg             *config.Config
+	resolver        AlertResolver
+	logger          *zap.Logger
+
+	// 已处理的链路指标样本位置
+	sampleCursor int64
 }
 
 // NewThresholdChecker 创建阈值检查器
 func NewThresholdChecker(
 	deviceRepo repository.DeviceRepository,
+	vnRepo repository.VirtualNetworkRepository,
+	linkMetricRepo repository.LinkMetricRepository,
+	thresholdRepo repository.AnomalyThresholdRepository,
 	sessionRepo repository.SessionRepository,
+	pskRepo repository.PreSharedKeyRepository,
+	statusEventRepo repository.DeviceStatusEventRepository,
+	cfg *config.Config,
 	logger *zap.Logger,
 ) *ThresholdChecker {
 	return &ThresholdChecker{
-		deviceRepo:  deviceRepo,
-		sessionRepo: sessionRepo,
-		logger:      logger,
+		deviceRepo:      deviceRepo,
+		vnRepo:          vnRepo,
+		linkMetricRepo:  linkMetricRepo,
+		thresholdRepo:   thresholdRepo,
+		sessionRepo:     sessionRepo,
+		pskRepo:         pskRepo,
+		statusEventRepo: statusEventRepo,
+		cfg:             cfg,
+		logger:          logger,
 	}
 }
 
+// SetResolver 设置问题消失时的告警解决器
+func (tc *ThresholdChecker) SetResolver(resolver AlertResolver) {
+	tc.resolver = resolver
+}
+
 // CheckAll 执行所有健康检查
 func (tc *ThresholdChecker) CheckAll(ctx context.Context) []HealthIssue {
 	var issues []HealthIssue
 
+	thresholds := tc.loadThresholds(ctx)
+
 	// 检查离线设备
-	offlineIssues := tc.CheckOfflineDevices(ctx)
+	offlineIssues := tc.CheckOfflineDevices(ctx, thresholds)
 	issues = append(issues, offlineIssues...)
 
-	// 检查高延迟
-	latencyIssues := tc.CheckHighLatency(ctx)
-	issues = append(issues, latencyIssues...)
+	// 检查链路延迟与丢包是否持续偏离基线
+	anomalyIssues := tc.CheckLinkAnomalies(ctx, thresholds)
+	issues = append(issues, anomalyIssues...)
+
+	// 检查隧道抖动、中继回落、地址池、注册密钥与时钟偏差
+	operationalIssues := tc.CheckOperational(ctx, thresholds)
+	issues = append(issues, operationalIssues...)
 
 	return issues
 }
 
-// CheckOfflineDevices 检查离线设备 (超过5分钟未上线)
-func (tc *ThresholdChecker) CheckOfflineDevices(ctx context.Context) []HealthIssue {
+// CheckOfflineDevices 检查离线设备 (超过离线阈值未上线，阈值可按网络/标签覆盖)
+func (tc *ThresholdChecker) CheckOfflineDevices(ctx context.Context, thresholds *thresholdSet) []HealthIssue {
 	var issues []HealthIssue
 
 	// 查询所有在线设备
@@ -67,16 +118,24 @@ func (tc *ThresholdChecker) CheckOfflineDevices(ctx context.Context) []HealthIss
 	}
 
 	now := time.Now()
-	offlineThreshold := 5 * time.Minute
 
-	for _, device := range devices {
+	for i := range devices {
+		device := &devices[i]
+
+		// 临时设备离线后由后台任务撤销，不告警
+		if device.Ephemeral {
+			continue
+		}
+
 		// 检查最后上线时间
 		if device.LastSeenAt != nil {
 			timeSinceLastSeen := now.Sub(*device.LastSeenAt)
+			limits := thresholds.resolve(device, tc.networkOrganization(ctx, thresholds, device.VirtualNetworkID))
+			offlineThreshold := limits.OfflineAfter
 
 			if timeSinceLastSeen > offlineThreshold {
 				severity := "high"
-				if timeSinceLastSeen > 30*time.Minute {
+				if timeSinceLastSeen > 6*offlineThreshold {
 					severity = "critical"
 				}
 
@@ -89,6 +148,7 @@ func (tc *ThresholdChecker) CheckOfflineDevices(ctx context.Context) []HealthIss
 						"device_name":       device.Name,
 						"last_seen_at":      *device.LastSeenAt,
 						"offline_duration":  timeSinceLastSeen.String(),
+						"offline_threshold": offlineThreshold.String(),
 						"virtual_network_id": device.VirtualNetworkID.String(),
 					},
 					DetectedAt: now,
@@ -100,44 +160,20 @@ func (tc *ThresholdChecker) CheckOfflineDevices(ctx context.Context) []HealthIss
 	return issues
 }
 
-// CheckHighLatency 检查高延迟会话 (平均延迟 > 500ms)
-func (tc *ThresholdChecker) CheckHighLatency(ctx context.Context) []HealthIssue {
-	var issues []HealthIssue
-
-	// 查询活跃会话
-	activeSessions, err := tc.sessionRepo.FindActiveSessions(ctx, 1000)
-	if err != nil {
-		tc.logger.Error("Failed to query active sessions", zap.Error(err))
-		return issues
+// networkOrganization 返回虚拟网络所属组织，结果在本轮检查内缓存
+func (tc *ThresholdChecker) networkOrganization(ctx context.Context, thresholds *thresholdSet, vnID uuid.UUID) uuid.UUID {
+	if orgID, ok := thresholds.networkOrgs[vnID]; ok {
+		return orgID
 	}
 
-	latencyThreshold := 500 // ms
-
-	for _, session := range activeSessions {
-		if session.AvgLatencyMs != nil && *session.AvgLatencyMs > latencyThreshold {
-			severity := "medium"
-			if *session.AvgLatencyMs > 1000 {
-				severity = "high"
-			}
-
-			issues = append(issues, HealthIssue{
-				Type:     "high_latency",
-				DeviceID: session.DeviceAID.String(),
-				Severity: severity,
-				Message:  "High latency detected in active session",
-				Metadata: map[string]interface{}{
-					"session_id":       session.ID.String(),
-					"device_a_id":      session.DeviceAID.String(),
-					"device_b_id":      session.DeviceBID.String(),
-					"avg_latency_ms":   *session.AvgLatencyMs,
-					"connection_type":  session.ConnectionType,
-				},
-				DetectedAt: time.Now(),
-			})
-		}
+	orgID := uuid.Nil
+	if vn, err := tc.vnRepo.FindByID(ctx, vnID); err == nil {
+		orgID = vn.OrganizationID
+	} else {
+		tc.logger.Warn("Failed to load virtual network", zap.String("virtual_network_id", vnID.String()), zap.Error(err))
 	}
-
-	return issues
+	thresholds.networkOrgs[vnID] = orgID
+	return orgID
 }
 
 // uuidNil 返回nil UUID (用于查询所有网络的设备)
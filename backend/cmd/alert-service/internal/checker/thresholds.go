@@ -0,0 +1,135 @@
+package checker
+
+import (
+	"context"
+	"time"
+
+	"github.com/edgelink/backend/internal/config"
+	"github.com/edgelink/backend/internal/domain"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// limits 某个设备生效的检测阈值
+type limits struct {
+	Enabled            bool
+	Sigma              float64
+	Sustain            time.Duration
+	MinLatencyDeltaMs  float64
+	MinPacketLossDelta float64
+	LatencyMaxMs       float64 // 0表示不设绝对上限
+	PacketLossMax      float64
+	OfflineAfter       time.Duration
+	OverrideIDs        []string // 生效的覆盖配置
+}
+
+// thresholdSet 本轮检查使用的阈值覆盖
+type thresholdSet struct {
+	defaults    limits
+	overrides   []*domain.AnomalyThreshold
+	networkOrgs map[uuid.UUID]uuid.UUID
+}
+
+// loadThresholds 加载全局默认阈值与数据库中的覆盖配置，加载失败时只使用默认值
+func (tc *ThresholdChecker) loadThresholds(ctx context.Context) *thresholdSet {
+	set := &thresholdSet{
+		defaults:    defaultLimits(&tc.cfg.Alert),
+		networkOrgs: make(map[uuid.UUID]uuid.UUID),
+	}
+
+	overrides, err := tc.thresholdRepo.List(ctx, nil)
+	if err != nil {
+		tc.logger.Error("Failed to load anomaly thresholds, using defaults", zap.Error(err))
+		return set
+	}
+	set.overrides = overrides
+	return set
+}
+
+// defaultLimits 从配置构建默认阈值
+func defaultLimits(cfg *config.AlertConfig) limits {
+	offlineAfter := cfg.DeviceOfflineThreshold
+	if offlineAfter <= 0 {
+		offlineAfter = 5 * time.Minute
+	}
+	sigma := cfg.AnomalySigma
+	if sigma <= 0 {
+		sigma = 3
+	}
+
+	return limits{
+		Enabled:            true,
+		Sigma:              sigma,
+		Sustain:            cfg.AnomalySustain,
+		MinLatencyDeltaMs:  float64(cfg.AnomalyMinLatencyDeltaMs),
+		MinPacketLossDelta: cfg.AnomalyMinPacketLossDelta,
+		OfflineAfter:       offlineAfter,
+	}
+}
+
+// resolve 计算设备生效的阈值：全局默认 < 虚拟网络覆盖 < 标签覆盖（多个标签匹配时priority小者优先）
+func (s *thresholdSet) resolve(device *domain.Device, orgID uuid.UUID) limits {
+	result := s.defaults
+
+	var networkOverride, tagOverride *domain.AnomalyThreshold
+	for _, override := range s.overrides {
+		if override.OrganizationID != orgID || !override.Matches(device) {
+			continue
+		}
+		if override.VirtualNetworkID != nil {
+			networkOverride = override
+		} else if tagOverride == nil || override.Priority < tagOverride.Priority {
+			tagOverride = override
+		}
+	}
+
+	for _, override := range []*domain.AnomalyThreshold{networkOverride, tagOverride} {
+		if override != nil {
+			result.apply(override)
+		}
+	}
+	return result
+}
+
+// apply 用覆盖配置中已设置的字段替换当前值
+func (l *limits) apply(override *domain.AnomalyThreshold) {
+	l.Enabled = override.Enabled
+	if override.Sigma != nil {
+		l.Sigma = *override.Sigma
+	}
+	if override.SustainSeconds != nil {
+		l.Sustain = time.Duration(*override.SustainSeconds) * time.Second
+	}
+	if override.MinLatencyDeltaMs != nil {
+		l.MinLatencyDeltaMs = float64(*override.MinLatencyDeltaMs)
+	}
+	if override.MinPacketLossDelta != nil {
+		l.MinPacketLossDelta = *override.MinPacketLossDelta
+	}
+	if override.LatencyMaxMs != nil {
+		l.LatencyMaxMs = float64(*override.LatencyMaxMs)
+	}
+	if override.PacketLossMax != nil {
+		l.PacketLossMax = *override.PacketLossMax
+	}
+	if override.OfflineAfterSecs != nil {
+		l.OfflineAfter = time.Duration(*override.OfflineAfterSecs) * time.Second
+	}
+	l.OverrideIDs = append(append([]string(nil), l.OverrideIDs...), override.ID.String())
+}
+
+// minDelta 指标允许忽略的最小偏离
+func (l *limits) minDelta(metric domain.LinkMetricType) float64 {
+	if metric == domain.LinkMetricPacketLoss {
+		return l.MinPacketLossDelta
+	}
+	return l.MinLatencyDeltaMs
+}
+
+// absoluteMax 指标的绝对上限，0表示未设置
+func (l *limits) absoluteMax(metric domain.LinkMetricType) float64 {
+	if metric == domain.LinkMetricPacketLoss {
+		return l.PacketLossMax
+	}
+	return l.LatencyMaxMs
+}
@@ -2,6 +2,7 @@ package config
 
 import (
 	"fmt"
+	"os"
 
 	"github.com/edgelink/backend/cmd/alert-service/internal/integrations"
 	"github.com/edgelink/backend/cmd/alert-service/internal/integrations/discord"
@@ -9,6 +10,7 @@ import (
 	"github.com/edgelink/backend/cmd/alert-service/internal/integrations/pagerduty"
 	"github.com/edgelink/backend/cmd/alert-service/internal/integrations/slack"
 	"github.com/edgelink/backend/cmd/alert-service/internal/integrations/teams"
+	"gopkg.in/yaml.v3"
 )
 
 // IntegrationsConfig 集成配置
@@ -18,6 +20,89 @@ type IntegrationsConfig struct {
 	Slack     *SlackConfig     `yaml:"slack" json:"slack"`
 	Discord   *DiscordConfig   `yaml:"discord" json:"discord"`
 	Teams     *TeamsConfig     `yaml:"teams" json:"teams"`
+
+	// Instances 额外的命名实例，规则动作通过实例名引用（平台级配置的实例名即平台名）
+	Instances map[string]*InstanceConfig `yaml:"instances" json:"instances"`
+}
+
+// InstanceConfig 命名集成实例配置，type决定其余字段按哪个平台解析
+type InstanceConfig struct {
+	Type      string           `yaml:"type" json:"type"`
+	PagerDuty *PagerDutyConfig `yaml:"-" json:"pagerduty,omitempty"`
+	Opsgenie  *OpsgenieConfig  `yaml:"-" json:"opsgenie,omitempty"`
+	Slack     *SlackConfig     `yaml:"-" json:"slack,omitempty"`
+	Discord   *DiscordConfig   `yaml:"-" json:"discord,omitempty"`
+	Teams     *TeamsConfig     `yaml:"-" json:"teams,omitempty"`
+}
+
+// UnmarshalYAML 根据type字段解析对应平台的配置
+func (c *InstanceConfig) UnmarshalYAML(node *yaml.Node) error {
+	var header struct {
+		Type string `yaml:"type"`
+	}
+	if err := node.Decode(&header); err != nil {
+		return err
+	}
+
+	c.Type = header.Type
+	switch header.Type {
+	case "pagerduty":
+		c.PagerDuty = &PagerDutyConfig{}
+		return node.Decode(c.PagerDuty)
+	case "opsgenie":
+		c.Opsgenie = &OpsgenieConfig{}
+		return node.Decode(c.Opsgenie)
+	case "slack":
+		c.Slack = &SlackConfig{}
+		return node.Decode(c.Slack)
+	case "discord":
+		c.Discord = &DiscordConfig{}
+		return node.Decode(c.Discord)
+	case "teams":
+		c.Teams = &TeamsConfig{}
+		return node.Decode(c.Teams)
+	case "":
+		return fmt.Errorf("integration instance type is required")
+	default:
+		return fmt.Errorf("unsupported integration type: %s", header.Type)
+	}
+}
+
+// IsEnabled 实例是否启用
+func (c *InstanceConfig) IsEnabled() bool {
+	switch {
+	case c.PagerDuty != nil:
+		return c.PagerDuty.Enabled
+	case c.Opsgenie != nil:
+		return c.Opsgenie.Enabled
+	case c.Slack != nil:
+		return c.Slack.Enabled
+	case c.Discord != nil:
+		return c.Discord.Enabled
+	case c.Teams != nil:
+		return c.Teams.Enabled
+	}
+	return false
+}
+
+// integrationsFile 集成配置文件的顶层结构
+type integrationsFile struct {
+	Integrations IntegrationsConfig `yaml:"integrations"`
+}
+
+// LoadIntegrationsConfig 从YAML文件加载集成配置，${VAR}形式的值从环境变量展开
+func LoadIntegrationsConfig(path string) (*IntegrationsConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read integrations config: %w", err)
+	}
+
+	var file integrationsFile
+	if err := yaml.Unmarshal([]byte(os.ExpandEnv(string(data))), &file); err != nil {
+		return nil, fmt.Errorf("failed to parse integrations config: %w", err)
+	}
+
+	return &file.Integrations, nil
 }
 
 // PagerDutyConfig PagerDuty配置
@@ -161,6 +246,7 @@ type TeamsConfig struct {
 	Enabled     bool                      `yaml:"enabled" json:"enabled"`
 	Priority    int                       `yaml:"priority" json:"priority"`
 	WebhookURL  string                    `yaml:"webhook_url" json:"webhook_url" env:"TEAMS_WEBHOOK_URL"`
+	ActionURL   string                    `yaml:"action_url" json:"action_url" env:"TEAMS_ACTION_URL"`
 	ColorMap    *teams.ColorMapping       `yaml:"color_map" json:"color_map"`
 	RetryConfig *integrations.RetryConfig `yaml:"retry_config" json:"retry_config"`
 }
@@ -171,6 +257,7 @@ func (c *TeamsConfig) ToTeamsConfig() *teams.Config {
 		WebhookURL: c.WebhookURL,
 		Enabled:    c.Enabled,
 		Priority:   c.Priority,
+		ActionURL:  c.ActionURL,
 	}
 
 	if c.ColorMap != nil {
@@ -226,6 +313,22 @@ func (c *IntegrationsConfig) Validate() error {
 		hasEnabled = true
 	}
 
+	for name, instance := range c.Instances {
+		if instance == nil {
+			return fmt.Errorf("integration instance %s is empty", name)
+		}
+		if c.hasTopLevel(name) {
+			return fmt.Errorf("integration instance %s conflicts with the top-level %s section", name, name)
+		}
+		if !instance.IsEnabled() {
+			continue
+		}
+		if err := instance.validate(); err != nil {
+			return fmt.Errorf("integration instance %s: %w", name, err)
+		}
+		hasEnabled = true
+	}
+
 	if !hasEnabled {
 		return fmt.Errorf("at least one integration must be enabled")
 	}
@@ -233,6 +336,40 @@ func (c *IntegrationsConfig) Validate() error {
 	return nil
 }
 
+// hasTopLevel 实例名是否与已配置的平台级集成重名
+func (c *IntegrationsConfig) hasTopLevel(name string) bool {
+	switch name {
+	case "pagerduty":
+		return c.PagerDuty != nil
+	case "opsgenie":
+		return c.Opsgenie != nil
+	case "slack":
+		return c.Slack != nil
+	case "discord":
+		return c.Discord != nil
+	case "teams":
+		return c.Teams != nil
+	}
+	return false
+}
+
+// validate 验证实例的必填项
+func (c *InstanceConfig) validate() error {
+	switch {
+	case c.PagerDuty != nil && c.PagerDuty.IntegrationKey == "":
+		return fmt.Errorf("pagerduty integration_key is required when enabled")
+	case c.Opsgenie != nil && c.Opsgenie.APIKey == "":
+		return fmt.Errorf("opsgenie api_key is required when enabled")
+	case c.Slack != nil && c.Slack.WebhookURL == "":
+		return fmt.Errorf("slack webhook_url is required when enabled")
+	case c.Discord != nil && c.Discord.WebhookURL == "":
+		return fmt.Errorf("discord webhook_url is required when enabled")
+	case c.Teams != nil && c.Teams.WebhookURL == "":
+		return fmt.Errorf("teams webhook_url is required when enabled")
+	}
+	return nil
+}
+
 // GetEnabledCount 获取启用的集成数量
 func (c *IntegrationsConfig) GetEnabledCount() int {
 	count := 0
@@ -252,6 +389,11 @@ func (c *IntegrationsConfig) GetEnabledCount() int {
 	if c.Teams != nil && c.Teams.Enabled {
 		count++
 	}
+	for _, instance := range c.Instances {
+		if instance != nil && instance.IsEnabled() {
+			count++
+		}
+	}
 
 	return count
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/edgelink/backend/cmd/alert-service/internal/checker"
@@ -14,11 +15,17 @@ import (
 	"go.uber.org/zap"
 )
 
+// ResolutionNotifier 告警自动解决后的通知接口（例如同步到PagerDuty等集成）
+type ResolutionNotifier interface {
+	AlertsResolved(ctx context.Context, alertIDs []uuid.UUID)
+}
+
 // AlertGenerator 告警生成器
 type AlertGenerator struct {
 	alertRepo  repository.AlertRepository
 	deviceRepo repository.DeviceRepository
 	dedupeManager *deduplication.Manager
+	resolutionNotifier ResolutionNotifier
 	logger     *zap.Logger
 }
 
@@ -27,22 +34,35 @@ func NewAlertGenerator(
 	alertRepo repository.AlertRepository,
 	deviceRepo repository.DeviceRepository,
 	dedupeManager *deduplication.Manager,
+	resolutionNotifier ResolutionNotifier,
 	logger *zap.Logger,
 ) *AlertGenerator {
 	return &AlertGenerator{
 		alertRepo:  alertRepo,
 		deviceRepo: deviceRepo,
 		dedupeManager: dedupeManager,
+		resolutionNotifier: resolutionNotifier,
 		logger:     logger,
 	}
 }
 
 // GenerateAlert 根据健康问题生成告警
 func (ag *AlertGenerator) GenerateAlert(ctx context.Context, issue checker.HealthIssue) (*domain.Alert, error) {
-	// 解析设备ID
-	deviceID, err := uuid.Parse(issue.DeviceID)
-	if err != nil {
-		return nil, fmt.Errorf("invalid device ID: %w", err)
+	// 解析设备ID（网络、注册密钥等非设备问题没有设备ID，以Subject标识对象）
+	var deviceID *uuid.UUID
+	if issue.DeviceID != "" {
+		id, err := uuid.Parse(issue.DeviceID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid device ID: %w", err)
+		}
+		deviceID = &id
+	}
+	subject := issue.DeviceID
+	if subject == "" {
+		subject = issue.Subject
+	}
+	if subject == "" {
+		return nil, fmt.Errorf("issue %s has neither device ID nor subject", issue.Type)
 	}
 
 	// 确定告警类型
@@ -53,7 +73,7 @@ func (ag *AlertGenerator) GenerateAlert(ctx context.Context, issue checker.Healt
 
 	// 构造去重键
 	dedupeKey := deduplication.AlertKey{
-		DeviceID:  issue.DeviceID,
+		DeviceID:  subject,
 		AlertType: string(alertType),
 	}
 
@@ -63,7 +83,7 @@ func (ag *AlertGenerator) GenerateAlert(ctx context.Context, issue checker.Healt
 		ag.logger.Warn("Failed to check silent period", zap.Error(err))
 	} else if inSilent {
 		ag.logger.Debug("Alert is in silent period, skipping",
-			zap.String("device_id", issue.DeviceID),
+			zap.String("subject", subject),
 			zap.String("alert_type", string(alertType)),
 		)
 		return nil, nil
@@ -95,7 +115,7 @@ func (ag *AlertGenerator) GenerateAlert(ctx context.Context, issue checker.Healt
 // createNewAlert 创建新告警
 func (ag *AlertGenerator) createNewAlert(
 	ctx context.Context,
-	deviceID uuid.UUID,
+	deviceID *uuid.UUID,
 	alertType domain.AlertType,
 	severity domain.Severity,
 	issue checker.HealthIssue,
@@ -113,14 +133,18 @@ func (ag *AlertGenerator) createNewAlert(
 	}
 
 	var metadata domain.JSONB
-	if err := json.Unmarshal(metadataJSON, &metadata); err != nil {
+	if err := json.Unmarshal(metadataJSON, &metadata); err != nil || metadata == nil {
 		metadata = domain.JSONB{}
 	}
+	if deviceID == nil {
+		// 非设备告警按subject自动解决
+		metadata["subject"] = dedupeKey.DeviceID
+	}
 
 	// 创建告警实体
 	alert := &domain.Alert{
 		ID:              uuid.New(),
-		DeviceID:        &deviceID,
+		DeviceID:        deviceID,
 		Severity:        severity,
 		Type:            alertType,
 		Title:           title,
@@ -147,7 +171,7 @@ func (ag *AlertGenerator) createNewAlert(
 		zap.String("alert_id", alert.ID.String()),
 		zap.String("type", string(alertType)),
 		zap.String("severity", string(severity)),
-		zap.String("device_id", deviceID.String()),
+		zap.String("subject", dedupeKey.DeviceID),
 	)
 
 	return alert, nil
@@ -218,7 +242,8 @@ func (ag *AlertGenerator) updateExistingAlert(
 // ResolveDeviceAlerts 自动解决设备告警（当设备恢复在线时）
 func (ag *AlertGenerator) ResolveDeviceAlerts(ctx context.Context, deviceID uuid.UUID, alertType domain.AlertType) error {
 	// 解决数据库中的告警
-	if err := ag.alertRepo.ResolveByDeviceAndType(ctx, deviceID, alertType); err != nil {
+	alertIDs, err := ag.alertRepo.ResolveByDeviceAndType(ctx, deviceID, alertType)
+	if err != nil {
 		return fmt.Errorf("failed to resolve alerts: %w", err)
 	}
 
@@ -237,14 +262,90 @@ func (ag *AlertGenerator) ResolveDeviceAlerts(ctx context.Context, deviceID uuid
 		ag.logger.Error("Failed to set silent period", zap.Error(err))
 	}
 
+	// 同步到已收到告警的集成（如自动解决PagerDuty事件）
+	if ag.resolutionNotifier != nil && len(alertIDs) > 0 {
+		ag.resolutionNotifier.AlertsResolved(ctx, alertIDs)
+	}
+
 	ag.logger.Info("Device alerts auto-resolved",
 		zap.String("device_id", deviceID.String()),
 		zap.String("alert_type", string(alertType)),
+		zap.Int("resolved", len(alertIDs)),
 	)
 
 	return nil
 }
 
+// ResolveCleared 自动解决问题已消失的同类告警
+// 设备告警按设备ID匹配，网络、注册密钥等告警按metadata中的subject匹配
+func (ag *AlertGenerator) ResolveCleared(ctx context.Context, alertType domain.AlertType, active map[string]bool) error {
+	alerts, err := ag.alertRepo.FindUnresolvedByTypes(ctx, nil, []domain.AlertType{alertType}, 0)
+	if err != nil {
+		return fmt.Errorf("failed to query unresolved alerts: %w", err)
+	}
+
+	clearedDevices := make(map[uuid.UUID]bool)
+	clearedSubjects := make(map[string]bool)
+	var resolvedIDs []uuid.UUID
+
+	for _, alert := range alerts {
+		if alert.DeviceID != nil {
+			if !active[alert.DeviceID.String()] {
+				clearedDevices[*alert.DeviceID] = true
+			}
+			continue
+		}
+
+		subject, _ := alert.Metadata["subject"].(string)
+		if subject == "" || active[subject] {
+			continue
+		}
+		if err := ag.alertRepo.Resolve(ctx, alert.ID); err != nil {
+			ag.logger.Error("Failed to resolve cleared alert",
+				zap.String("alert_id", alert.ID.String()),
+				zap.Error(err),
+			)
+			continue
+		}
+		resolvedIDs = append(resolvedIDs, alert.ID)
+		clearedSubjects[subject] = true
+	}
+
+	for deviceID := range clearedDevices {
+		if err := ag.ResolveDeviceAlerts(ctx, deviceID, alertType); err != nil {
+			ag.logger.Error("Failed to resolve cleared device alerts",
+				zap.String("device_id", deviceID.String()),
+				zap.Error(err),
+			)
+		}
+	}
+
+	for subject := range clearedSubjects {
+		dedupeKey := deduplication.AlertKey{
+			DeviceID:  subject,
+			AlertType: string(alertType),
+		}
+		if err := ag.dedupeManager.RemoveDedupeInfo(ctx, dedupeKey); err != nil {
+			ag.logger.Error("Failed to remove dedupe info", zap.Error(err))
+		}
+		if err := ag.dedupeManager.SetSilentPeriod(ctx, dedupeKey); err != nil {
+			ag.logger.Error("Failed to set silent period", zap.Error(err))
+		}
+	}
+
+	if len(resolvedIDs) > 0 {
+		if ag.resolutionNotifier != nil {
+			ag.resolutionNotifier.AlertsResolved(ctx, resolvedIDs)
+		}
+		ag.logger.Info("Cleared alerts auto-resolved",
+			zap.String("alert_type", string(alertType)),
+			zap.Int("resolved", len(resolvedIDs)),
+		)
+	}
+
+	return nil
+}
+
 // escalateSeverity 提升严重程度
 func (ag *AlertGenerator) escalateSeverity(current domain.Severity) domain.Severity {
 	switch current {
@@ -266,6 +367,22 @@ func (ag *AlertGenerator) mapIssueTypeToAlertType(issueType string) domain.Alert
 		return domain.AlertTypeDeviceOffline
 	case "high_latency":
 		return domain.AlertTypeHighLatency
+	case "packet_loss":
+		return domain.AlertTypePacketLoss
+	case "device_flapping":
+		return domain.AlertTypeDeviceFlapping
+	case "relay_fallback":
+		return domain.AlertTypeRelayFallback
+	case "ip_pool_exhaustion":
+		return domain.AlertTypeIPPoolExhaustion
+	case "enrollment_key_exhaustion":
+		return domain.AlertTypeEnrollmentKeyExhaustion
+	case "clock_skew":
+		return domain.AlertTypeClockSkew
+	case "device_pending_approval":
+		return domain.AlertTypeDevicePendingApproval
+	case "posture_violation":
+		return domain.AlertTypePostureViolation
 	case "connection_failed":
 		return domain.AlertTypeTunnelFailure
 	default:
@@ -314,6 +431,120 @@ func (ag *AlertGenerator) generateAlertContent(issue checker.HealthIssue) (strin
 		if latency, ok := issue.Metadata["avg_latency_ms"].(int); ok {
 			message += fmt.Sprintf(" 平均延迟: %dms", latency)
 		}
+		if baseline, ok := issue.Metadata["baseline_mean"].(float64); ok {
+			message += fmt.Sprintf(" 基线: %.0fms", baseline)
+			if sigma, ok := issue.Metadata["deviation_sigma"].(float64); ok && sigma > 0 {
+				message += fmt.Sprintf("（偏离%.1fσ）", sigma)
+			}
+		}
+		if name, ok := issue.Metadata["device_name"].(string); ok {
+			title = fmt.Sprintf("链路延迟异常: %s", name)
+		}
+
+	case "packet_loss":
+		deviceName := "Unknown"
+		if name, ok := issue.Metadata["device_name"].(string); ok {
+			deviceName = name
+		}
+
+		title = fmt.Sprintf("链路丢包异常: %s", deviceName)
+		message = fmt.Sprintf("设备 %s 到对端的丢包率持续高于基线,可能影响连接质量。", deviceName)
+
+		if loss, ok := issue.Metadata["current_value"].(float64); ok {
+			message += fmt.Sprintf(" 当前丢包率: %.1f%%", loss*100)
+		}
+		if baseline, ok := issue.Metadata["baseline_mean"].(float64); ok {
+			message += fmt.Sprintf(" 基线: %.1f%%", baseline*100)
+		}
+
+	case "device_flapping":
+		deviceName := metadataString(issue.Metadata, "device_name", "Unknown")
+
+		title = fmt.Sprintf("设备频繁上下线: %s", deviceName)
+		message = fmt.Sprintf("设备 %s 的隧道反复断开重连,请检查设备网络稳定性和上行链路。", deviceName)
+
+		if transitions, ok := issue.Metadata["transitions"].(int); ok {
+			message += fmt.Sprintf(" 最近%s内状态变化%d次", metadataString(issue.Metadata, "flap_window", ""), transitions)
+		}
+
+	case "relay_fallback":
+		networkName := metadataString(issue.Metadata, "network_name", metadataString(issue.Metadata, "virtual_network_id", "Unknown"))
+
+		title = fmt.Sprintf("中继回落比例过高: %s", networkName)
+		message = fmt.Sprintf("虚拟网络 %s 中大量会话无法建立P2P直连而回落到TURN中继,请检查NAT类型和防火墙策略。", networkName)
+
+		if rate, ok := issue.Metadata["relay_rate"].(float64); ok {
+			message += fmt.Sprintf(" 中继比例: %.0f%%", rate*100)
+		}
+		relay, okRelay := issue.Metadata["relay_sessions"].(int64)
+		total, okTotal := issue.Metadata["total_sessions"].(int64)
+		if okRelay && okTotal {
+			message += fmt.Sprintf("（%d/%d）", relay, total)
+		}
+
+	case "ip_pool_exhaustion":
+		networkName := metadataString(issue.Metadata, "network_name", "Unknown")
+
+		title = fmt.Sprintf("地址池即将耗尽: %s", networkName)
+		message = fmt.Sprintf("虚拟网络 %s 的可分配地址即将用尽,新设备可能无法注册。请扩大网段或清理无用设备。", networkName)
+
+		allocated, okAllocated := issue.Metadata["allocated"].(int)
+		capacity, okCapacity := issue.Metadata["capacity"].(int)
+		if okAllocated && okCapacity {
+			message += fmt.Sprintf(" 已分配: %d/%d", allocated, capacity)
+		}
+		if utilization, ok := issue.Metadata["utilization"].(float64); ok {
+			message += fmt.Sprintf("（%.0f%%）", utilization*100)
+		}
+
+	case "enrollment_key_exhaustion":
+		keyName := metadataString(issue.Metadata, "psk_name", metadataString(issue.Metadata, "psk_id", "Unknown"))
+
+		title = fmt.Sprintf("注册密钥即将失效: %s", keyName)
+		message = fmt.Sprintf("预共享密钥 %s 即将无法用于注册新设备,请及时轮换。", keyName)
+
+		if remaining, ok := issue.Metadata["remaining_uses"].(int); ok {
+			message += fmt.Sprintf(" 剩余次数: %d", remaining)
+		}
+		if expiresAt, ok := issue.Metadata["expires_at"].(string); ok {
+			message += fmt.Sprintf(" 过期时间: %s", expiresAt)
+		}
+
+	case "clock_skew":
+		deviceName := metadataString(issue.Metadata, "device_name", "Unknown")
+
+		title = fmt.Sprintf("设备时钟偏差: %s", deviceName)
+		message = fmt.Sprintf("设备 %s 的系统时间与服务器偏差过大,可能导致签名校验失败。请检查设备NTP配置。", deviceName)
+
+		if skew, ok := issue.Metadata["clock_skew"].(string); ok {
+			message += fmt.Sprintf(" 偏差: %s", skew)
+		}
+
+	case "device_pending_approval":
+		deviceName := metadataString(issue.Metadata, "device_name", "Unknown")
+		networkName := metadataString(issue.Metadata, "network_name", metadataString(issue.Metadata, "virtual_network_id", "Unknown"))
+
+		title = fmt.Sprintf("设备等待审批: %s", deviceName)
+		message = fmt.Sprintf("设备 %s 已注册到虚拟网络 %s,批准前不会加入任何对端列表。请在管理后台批准或拒绝。", deviceName, networkName)
+
+		if registeredAt, ok := issue.Metadata["registered_at"].(string); ok {
+			message += fmt.Sprintf(" 注册时间: %s", registeredAt)
+		}
+
+	case "posture_violation":
+		deviceName := metadataString(issue.Metadata, "device_name", "Unknown")
+		networkName := metadataString(issue.Metadata, "network_name", metadataString(issue.Metadata, "virtual_network_id", "Unknown"))
+		enforcement := "已从所有对端列表中隔离"
+		if tags, ok := issue.Metadata["allowed_peer_tags"].([]string); ok && len(tags) > 0 {
+			enforcement = fmt.Sprintf("只能与带有标签 %s 的设备互通", strings.Join(tags, ", "))
+		}
+		violations := "未知"
+		if v, ok := issue.Metadata["violations"].([]string); ok && len(v) > 0 {
+			violations = strings.Join(v, "; ")
+		}
+
+		title = fmt.Sprintf("设备安全状态不合规: %s", deviceName)
+		message = fmt.Sprintf("虚拟网络 %s 中的设备 %s 不满足安全状态策略,%s。不满足的要求: %s", networkName, deviceName, enforcement, violations)
 
 	case "connection_failed":
 		title = "连接失败"
@@ -326,3 +557,11 @@ func (ag *AlertGenerator) generateAlertContent(issue checker.HealthIssue) (strin
 
 	return title, message
 }
+
+// metadataString 读取字符串类型的元数据，缺失时返回默认值
+func metadataString(metadata map[string]interface{}, key, fallback string) string {
+	if value, ok := metadata[key].(string); ok && value != "" {
+		return value
+	}
+	return fallback
+}
@@ -24,18 +24,18 @@ type Config struct {
 	ColorMap    ColorMapping             // 颜色映射
 }
 
-// Enabled 实现IntegrationConfig接口
-func (c *Config) Enabled() bool {
+// IsEnabled 实现IntegrationConfig接口
+func (c *Config) IsEnabled() bool {
 	return c.Enabled
 }
 
-// Priority 实现IntegrationConfig接口
-func (c *Config) Priority() int {
+// GetPriority 实现IntegrationConfig接口
+func (c *Config) GetPriority() int {
 	return c.Priority
 }
 
-// RetryConfig 实现IntegrationConfig接口
-func (c *Config) RetryConfig() integrations.RetryConfig {
+// GetRetryConfig 实现IntegrationConfig接口
+func (c *Config) GetRetryConfig() integrations.RetryConfig {
 	return c.RetryConfig
 }
 
@@ -8,10 +8,10 @@ import (
 
 	"github.com/edgelink/backend/cmd/alert-service/internal/config"
 	"github.com/edgelink/backend/cmd/alert-service/internal/integrations"
+	integrationfactory "github.com/edgelink/backend/cmd/alert-service/internal/integrations/factory"
 	"github.com/edgelink/backend/internal/domain"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
-	"gopkg.in/yaml.v3"
 )
 
 // Example 展示如何使用集成管理器
@@ -21,13 +21,13 @@ func Example() {
 	defer logger.Sync()
 
 	// 加载配置
-	cfg, err := loadIntegrationsConfig("config/integrations.yaml")
+	cfg, err := config.LoadIntegrationsConfig("config/integrations.yaml")
 	if err != nil {
 		logger.Fatal("Failed to load config", zap.Error(err))
 	}
 
 	// 创建集成工厂
-	factory := integrations.NewFactory(logger)
+	factory := integrationfactory.NewFactory(logger)
 
 	// 创建集成管理器
 	manager, err := factory.CreateManager(cfg)
@@ -82,8 +82,13 @@ func ExampleWithManualRegistration() {
 		IconEmoji:  ":test_tube:",
 	}
 
-	slackIntegration := integrations.NewFactory(logger).CreateIntegration("slack", slackConfig)
-	manager.Register(slackIntegration, slackConfig.ToSlackConfig())
+	slackIntegrationConfig := slackConfig.ToSlackConfig()
+	slackIntegration, err := integrationfactory.NewFactory(logger).CreateIntegration("slack", slackIntegrationConfig)
+	if err != nil {
+		fmt.Printf("Failed to create slack integration: %v\n", err)
+		return
+	}
+	manager.Register(slackIntegration, slackIntegrationConfig)
 
 	// 发送测试告警
 	ctx := context.Background()
@@ -105,9 +110,10 @@ func ExampleErrorHandling() {
 		WebhookURL: "https://invalid-webhook-url.example.com/webhook",
 	}
 
-	factory := integrations.NewFactory(logger)
-	slackIntegration, _ := factory.CreateIntegration("slack", badConfig)
-	manager.Register(slackIntegration, badConfig.ToSlackConfig())
+	factory := integrationfactory.NewFactory(logger)
+	badIntegrationConfig := badConfig.ToSlackConfig()
+	slackIntegration, _ := factory.CreateIntegration("slack", badIntegrationConfig)
+	manager.Register(slackIntegration, badIntegrationConfig)
 
 	// 尝试发送告警（会失败并重试）
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
@@ -151,7 +157,7 @@ func ExamplePriorityRouting() {
 		},
 	}
 
-	factory := integrations.NewFactory(logger)
+	factory := integrationfactory.NewFactory(logger)
 	manager, _ := factory.CreateManager(cfg)
 
 	// 发送告警（会同时发送到所有平台，但日志会按优先级排序）
@@ -160,24 +166,6 @@ func ExamplePriorityRouting() {
 	manager.SendAlert(ctx, alert)
 }
 
-// loadIntegrationsConfig 加载集成配置
-func loadIntegrationsConfig(path string) (*config.IntegrationsConfig, error) {
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read config file: %w", err)
-	}
-
-	var cfg struct {
-		Integrations *config.IntegrationsConfig `yaml:"integrations"`
-	}
-
-	if err := yaml.Unmarshal(data, &cfg); err != nil {
-		return nil, fmt.Errorf("failed to parse config: %w", err)
-	}
-
-	return cfg.Integrations, nil
-}
-
 // createTestAlert 创建测试告警
 func createTestAlert() *domain.Alert {
 	deviceID := uuid.New()
@@ -209,7 +197,7 @@ func TestIntegration(integrationType string) {
 	defer logger.Sync()
 
 	ctx := context.Background()
-	factory := integrations.NewFactory(logger)
+	factory := integrationfactory.NewFactory(logger)
 
 	var integration integrations.Integration
 	var err error
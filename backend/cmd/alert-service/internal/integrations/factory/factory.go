@@ -1,10 +1,11 @@
-package integrations
+package factory
 
 import (
 	"context"
 	"fmt"
 
 	"github.com/edgelink/backend/cmd/alert-service/internal/config"
+	"github.com/edgelink/backend/cmd/alert-service/internal/integrations"
 	"github.com/edgelink/backend/cmd/alert-service/internal/integrations/discord"
 	"github.com/edgelink/backend/cmd/alert-service/internal/integrations/opsgenie"
 	"github.com/edgelink/backend/cmd/alert-service/internal/integrations/pagerduty"
@@ -26,12 +27,12 @@ func NewFactory(logger *zap.Logger) *Factory {
 }
 
 // CreateManager 根据配置创建并初始化集成管理器
-func (f *Factory) CreateManager(cfg *config.IntegrationsConfig) (*Manager, error) {
+func (f *Factory) CreateManager(cfg *config.IntegrationsConfig) (*integrations.Manager, error) {
 	if err := cfg.Validate(); err != nil {
 		return nil, fmt.Errorf("invalid integrations config: %w", err)
 	}
 
-	manager := NewManager(f.logger)
+	manager := integrations.NewManager(f.logger)
 
 	// 注册PagerDuty
 	if cfg.PagerDuty != nil && cfg.PagerDuty.Enabled {
@@ -78,6 +79,20 @@ func (f *Factory) CreateManager(cfg *config.IntegrationsConfig) (*Manager, error
 		}
 	}
 
+	// 注册命名实例
+	for name, instance := range cfg.Instances {
+		if !instance.IsEnabled() {
+			continue
+		}
+		integration, integrationConfig := f.createInstance(name, instance)
+		if integration == nil {
+			continue
+		}
+		if err := manager.RegisterAs(name, integration, integrationConfig); err != nil {
+			return nil, fmt.Errorf("failed to register %s: %w", name, err)
+		}
+	}
+
 	f.logger.Info("Integrations initialized",
 		zap.Int("total_enabled", cfg.GetEnabledCount()),
 	)
@@ -85,8 +100,32 @@ func (f *Factory) CreateManager(cfg *config.IntegrationsConfig) (*Manager, error
 	return manager, nil
 }
 
+// createInstance 根据实例类型创建集成
+func (f *Factory) createInstance(name string, instance *config.InstanceConfig) (integrations.Integration, integrations.IntegrationConfig) {
+	logger := f.logger.Named(name)
+
+	switch {
+	case instance.PagerDuty != nil:
+		cfg := instance.PagerDuty.ToPagerDutyConfig()
+		return pagerduty.NewIntegration(cfg, logger), cfg
+	case instance.Opsgenie != nil:
+		cfg := instance.Opsgenie.ToOpsgenieConfig()
+		return opsgenie.NewIntegration(cfg, logger), cfg
+	case instance.Slack != nil:
+		cfg := instance.Slack.ToSlackConfig()
+		return slack.NewIntegration(cfg, logger), cfg
+	case instance.Discord != nil:
+		cfg := instance.Discord.ToDiscordConfig()
+		return discord.NewIntegration(cfg, logger), cfg
+	case instance.Teams != nil:
+		cfg := instance.Teams.ToTeamsConfig()
+		return teams.NewIntegration(cfg, logger), cfg
+	}
+	return nil, nil
+}
+
 // HealthCheckAll 执行所有集成的健康检查
-func (f *Factory) HealthCheckAll(ctx context.Context, manager *Manager) map[string]error {
+func (f *Factory) HealthCheckAll(ctx context.Context, manager *integrations.Manager) map[string]error {
 	results := manager.HealthCheck(ctx)
 
 	// 记录健康检查结果
@@ -107,7 +146,7 @@ func (f *Factory) HealthCheckAll(ctx context.Context, manager *Manager) map[stri
 }
 
 // CreateIntegration 创建单个集成（用于动态添加）
-func (f *Factory) CreateIntegration(integrationType string, configData interface{}) (Integration, error) {
+func (f *Factory) CreateIntegration(integrationType string, configData interface{}) (integrations.Integration, error) {
 	switch integrationType {
 	case "pagerduty":
 		cfg, ok := configData.(*pagerduty.Config)
@@ -31,22 +31,22 @@ type Integration interface {
 
 // IntegrationConfig 集成配置接口
 type IntegrationConfig interface {
-	// Enabled 是否启用该集成
-	Enabled() bool
+	// IsEnabled 是否启用该集成
+	IsEnabled() bool
 
-	// Priority 优先级（数字越小优先级越高，用于备用通道）
-	Priority() int
+	// GetPriority 优先级（数字越小优先级越高，用于备用通道）
+	GetPriority() int
 
-	// RetryConfig 获取重试配置
-	RetryConfig() RetryConfig
+	// GetRetryConfig 获取重试配置
+	GetRetryConfig() RetryConfig
 }
 
 // RetryConfig 重试配置
 type RetryConfig struct {
-	MaxRetries    int           // 最大重试次数
-	InitialDelay  time.Duration // 初始延迟
-	MaxDelay      time.Duration // 最大延迟
-	BackoffFactor float64       // 退避因子（指数退避）
+	MaxRetries    int           `yaml:"max_retries" json:"max_retries"`       // 最大重试次数
+	InitialDelay  time.Duration `yaml:"initial_delay" json:"initial_delay"`   // 初始延迟
+	MaxDelay      time.Duration `yaml:"max_delay" json:"max_delay"`           // 最大延迟
+	BackoffFactor float64       `yaml:"backoff_factor" json:"backoff_factor"` // 退避因子（指数退避）
 }
 
 // DefaultRetryConfig 默认重试配置
@@ -61,10 +61,10 @@ func DefaultRetryConfig() RetryConfig {
 
 // AlertSeverityMapping 告警严重程度映射
 type AlertSeverityMapping struct {
-	Critical string
-	High     string
-	Medium   string
-	Low      string
+	Critical string `yaml:"critical" json:"critical"`
+	High     string `yaml:"high" json:"high"`
+	Medium   string `yaml:"medium" json:"medium"`
+	Low      string `yaml:"low" json:"low"`
 }
 
 // MapSeverity 映射EdgeLink严重程度到平台特定值
@@ -3,10 +3,13 @@ package integrations
 import (
 	"context"
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 
 	"github.com/edgelink/backend/internal/domain"
+	"github.com/edgelink/backend/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
 	"go.uber.org/zap"
 )
 
@@ -17,6 +20,8 @@ type Manager struct {
 	logger       *zap.Logger
 	metrics      map[string]*IntegrationMetrics
 	mu           sync.RWMutex
+	metricsMu    sync.Mutex // 指标单独加锁，发送过程中持有mu读锁时仍可更新
+	onSend       func(name string, err error, duration time.Duration)
 }
 
 // NewManager 创建集成管理器
@@ -29,12 +34,19 @@ func NewManager(logger *zap.Logger) *Manager {
 	}
 }
 
-// Register 注册集成
+// Register 注册集成（以平台名称作为实例名）
 func (m *Manager) Register(integration Integration, config IntegrationConfig) error {
+	return m.RegisterAs(integration.Name(), integration, config)
+}
+
+// RegisterAs 以指定实例名注册集成，同一平台可注册多个实例（如不同服务的PagerDuty）
+func (m *Manager) RegisterAs(name string, integration Integration, config IntegrationConfig) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	name := integration.Name()
+	if _, exists := m.integrations[name]; exists {
+		return fmt.Errorf("integration instance %s already registered", name)
+	}
 
 	// 验证配置
 	if err := integration.ValidateConfig(); err != nil {
@@ -43,17 +55,26 @@ func (m *Manager) Register(integration Integration, config IntegrationConfig) er
 
 	m.integrations[name] = integration
 	m.configs[name] = config
+
+	m.metricsMu.Lock()
 	m.metrics[name] = &IntegrationMetrics{}
+	m.metricsMu.Unlock()
 
 	m.logger.Info("Registered integration",
 		zap.String("integration", name),
-		zap.Bool("enabled", config.Enabled()),
-		zap.Int("priority", config.Priority()),
+		zap.String("type", integration.Name()),
+		zap.Bool("enabled", config.IsEnabled()),
+		zap.Int("priority", config.GetPriority()),
 	)
 
 	return nil
 }
 
+// OnSend 设置每次发送后的回调（用于导出Prometheus指标），需在发送前设置
+func (m *Manager) OnSend(fn func(name string, err error, duration time.Duration)) {
+	m.onSend = fn
+}
+
 // Unregister 注销集成
 func (m *Manager) Unregister(name string) {
 	m.mu.Lock()
@@ -61,7 +82,10 @@ func (m *Manager) Unregister(name string) {
 
 	delete(m.integrations, name)
 	delete(m.configs, name)
+
+	m.metricsMu.Lock()
 	delete(m.metrics, name)
+	m.metricsMu.Unlock()
 
 	m.logger.Info("Unregistered integration", zap.String("integration", name))
 }
@@ -83,25 +107,25 @@ func (m *Manager) SendAlert(ctx context.Context, alert *domain.Alert) error {
 
 	for _, item := range enabled {
 		wg.Add(1)
-		go func(integration Integration, config IntegrationConfig) {
+		go func(name string, integration Integration, config IntegrationConfig) {
 			defer wg.Done()
 
 			// 发送告警（带重试）
-			err := m.sendWithRetry(ctx, integration, alert, config.RetryConfig())
+			err := m.sendWithRetry(ctx, name, integration, alert, config.GetRetryConfig())
 			if err != nil {
 				m.logger.Error("Failed to send alert",
-					zap.String("integration", integration.Name()),
+					zap.String("integration", name),
 					zap.String("alert_id", alert.ID.String()),
 					zap.Error(err),
 				)
 				errChan <- err
 			} else {
 				m.logger.Info("Alert sent successfully",
-					zap.String("integration", integration.Name()),
+					zap.String("integration", name),
 					zap.String("alert_id", alert.ID.String()),
 				)
 			}
-		}(item.integration, item.config)
+		}(item.name, item.integration, item.config)
 	}
 
 	wg.Wait()
@@ -181,6 +205,115 @@ func (m *Manager) UpdateAlert(ctx context.Context, alertID string, status domain
 	return nil
 }
 
+// Has 检查实例是否已注册
+func (m *Manager) Has(name string) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	_, exists := m.integrations[name]
+	return exists
+}
+
+// Names 返回所有已注册的实例名
+func (m *Manager) Names() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	names := make([]string, 0, len(m.integrations))
+	for name := range m.integrations {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// TypeOf 返回实例的集成类型（Integration.Name），实例不存在时返回空字符串
+func (m *Manager) TypeOf(name string) string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	integration, exists := m.integrations[name]
+	if !exists {
+		return ""
+	}
+	return integration.Name()
+}
+
+// SendAlertTo 发送告警到指定实例（单次尝试，重试由调用方的投递队列负责）
+func (m *Manager) SendAlertTo(ctx context.Context, name string, alert *domain.Alert) error {
+	integration, err := m.getEnabled(name)
+	if err != nil {
+		return err
+	}
+
+	return m.send(ctx, name, integration, alert, 1)
+}
+
+// send 向单个实例发送一次告警，记录指标和追踪span
+func (m *Manager) send(ctx context.Context, name string, integration Integration, alert *domain.Alert, attempt int) error {
+	ctx, span := tracing.Start(ctx, "integration.send",
+		attribute.String("integration.name", name),
+		attribute.String("integration.type", integration.Name()),
+		attribute.String("alert.id", alert.ID.String()),
+		attribute.Int("attempt", attempt),
+	)
+
+	startTime := time.Now()
+	err := integration.SendAlert(ctx, alert)
+	m.updateMetrics(name, err, time.Since(startTime))
+
+	tracing.End(span, err)
+	return err
+}
+
+// ResolveAlertOn 在指定实例上解决告警
+func (m *Manager) ResolveAlertOn(ctx context.Context, name string, alertID string) (err error) {
+	integration, err := m.getEnabled(name)
+	if err != nil {
+		return err
+	}
+
+	ctx, span := tracing.Start(ctx, "integration.resolve",
+		attribute.String("integration.name", name),
+		attribute.String("alert.id", alertID),
+	)
+	defer func() { tracing.End(span, err) }()
+
+	return integration.ResolveAlert(ctx, alertID)
+}
+
+// UpdateAlertOn 在指定实例上更新告警状态
+func (m *Manager) UpdateAlertOn(ctx context.Context, name string, alertID string, status domain.AlertStatus) (err error) {
+	integration, err := m.getEnabled(name)
+	if err != nil {
+		return err
+	}
+
+	ctx, span := tracing.Start(ctx, "integration.update",
+		attribute.String("integration.name", name),
+		attribute.String("alert.id", alertID),
+		attribute.String("alert.status", string(status)),
+	)
+	defer func() { tracing.End(span, err) }()
+
+	return integration.UpdateAlert(ctx, alertID, status)
+}
+
+// getEnabled 获取已启用的实例
+func (m *Manager) getEnabled(name string) (Integration, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	integration, exists := m.integrations[name]
+	if !exists {
+		return nil, NewIntegrationError(name, "lookup", "", fmt.Errorf("integration instance %s not found", name), false)
+	}
+	if !m.configs[name].IsEnabled() {
+		return nil, NewIntegrationError(name, "lookup", "", fmt.Errorf("integration instance %s is disabled", name), false)
+	}
+	return integration, nil
+}
+
 // HealthCheck 检查所有集成的健康状态
 func (m *Manager) HealthCheck(ctx context.Context) map[string]error {
 	m.mu.RLock()
@@ -188,7 +321,7 @@ func (m *Manager) HealthCheck(ctx context.Context) map[string]error {
 
 	results := make(map[string]error)
 	for name, integration := range m.integrations {
-		if m.configs[name].Enabled() {
+		if m.configs[name].IsEnabled() {
 			results[name] = integration.HealthCheck(ctx)
 		}
 	}
@@ -198,8 +331,8 @@ func (m *Manager) HealthCheck(ctx context.Context) map[string]error {
 
 // GetMetrics 获取所有集成的指标
 func (m *Manager) GetMetrics() map[string]*IntegrationMetrics {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
+	m.metricsMu.Lock()
+	defer m.metricsMu.Unlock()
 
 	// 返回副本
 	metrics := make(map[string]*IntegrationMetrics, len(m.metrics))
@@ -212,7 +345,7 @@ func (m *Manager) GetMetrics() map[string]*IntegrationMetrics {
 }
 
 // sendWithRetry 带重试的发送
-func (m *Manager) sendWithRetry(ctx context.Context, integration Integration, alert *domain.Alert, retryConfig RetryConfig) error {
+func (m *Manager) sendWithRetry(ctx context.Context, name string, integration Integration, alert *domain.Alert, retryConfig RetryConfig) error {
 	var lastErr error
 	delay := retryConfig.InitialDelay
 
@@ -232,15 +365,8 @@ func (m *Manager) sendWithRetry(ctx context.Context, integration Integration, al
 			)
 		}
 
-		// 记录开始时间
-		startTime := time.Now()
-
-		// 发送告警
-		err := integration.SendAlert(ctx, alert)
-
-		// 更新指标
-		m.updateMetrics(integration.Name(), err, time.Since(startTime))
-
+		// 发送告警并更新指标
+		err := m.send(ctx, name, integration, alert, attempt+1)
 		if err == nil {
 			return nil
 		}
@@ -264,10 +390,17 @@ func (m *Manager) sendWithRetry(ctx context.Context, integration Integration, al
 
 // updateMetrics 更新集成指标
 func (m *Manager) updateMetrics(name string, err error, duration time.Duration) {
-	m.mu.Lock()
-	defer m.mu.Unlock()
+	if m.onSend != nil {
+		m.onSend(name, err, duration)
+	}
 
-	metric := m.metrics[name]
+	m.metricsMu.Lock()
+	defer m.metricsMu.Unlock()
+
+	metric, exists := m.metrics[name]
+	if !exists {
+		return
+	}
 	metric.TotalSent++
 	metric.LastSentTime = time.Now()
 
@@ -287,6 +420,7 @@ func (m *Manager) updateMetrics(name string, err error, duration time.Duration)
 
 // integrationItem 集成项（用于排序）
 type integrationItem struct {
+	name        string
 	integration Integration
 	config      IntegrationConfig
 	priority    int
@@ -298,11 +432,12 @@ func (m *Manager) getEnabledIntegrationsSorted() []integrationItem {
 
 	for name, integration := range m.integrations {
 		config := m.configs[name]
-		if config.Enabled() {
+		if config.IsEnabled() {
 			items = append(items, integrationItem{
+				name:        name,
 				integration: integration,
 				config:      config,
-				priority:    config.Priority(),
+				priority:    config.GetPriority(),
 			})
 		}
 	}
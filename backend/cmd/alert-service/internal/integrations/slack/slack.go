@@ -25,18 +25,18 @@ type Config struct {
 	ColorMap    ColorMapping             // 颜色映射
 }
 
-// Enabled 实现IntegrationConfig接口
-func (c *Config) Enabled() bool {
+// IsEnabled 实现IntegrationConfig接口
+func (c *Config) IsEnabled() bool {
 	return c.Enabled
 }
 
-// Priority 实现IntegrationConfig接口
-func (c *Config) Priority() int {
+// GetPriority 实现IntegrationConfig接口
+func (c *Config) GetPriority() int {
 	return c.Priority
 }
 
-// RetryConfig 实现IntegrationConfig接口
-func (c *Config) RetryConfig() integrations.RetryConfig {
+// GetRetryConfig 实现IntegrationConfig接口
+func (c *Config) GetRetryConfig() integrations.RetryConfig {
 	return c.RetryConfig
 }
 
@@ -302,17 +302,20 @@ func (i *Integration) buildMessage(alert *domain.Alert) Message {
 		Footer:     "EdgeLink Alert Service",
 		FooterIcon: "https://platform.slack-edge.com/img/default_application_icon.png",
 		Timestamp:  alert.CreatedAt.Unix(),
+		CallbackID: "edgelink_alert",
 	}
 
-	// 添加操作按钮（可选）
+	// 添加操作按钮（回调由网关 /api/v1/callbacks/slack 处理）
 	attachment.Actions = []Action{
 		{
+			Name:  "acknowledge",
 			Type:  "button",
 			Text:  "Acknowledge",
 			Style: "primary",
 			Value: fmt.Sprintf("ack:%s", alert.ID.String()),
 		},
 		{
+			Name:  "resolve",
 			Type:  "button",
 			Text:  "Resolve",
 			Style: "danger",
@@ -373,6 +376,7 @@ type Attachment struct {
 	FooterIcon string   `json:"footer_icon,omitempty"` // 页脚图标
 	Timestamp  int64    `json:"ts,omitempty"`          // Unix时间戳
 	Actions    []Action `json:"actions,omitempty"`     // 操作按钮
+	CallbackID string   `json:"callback_id,omitempty"` // 交互回调标识
 }
 
 // Field Slack字段
@@ -384,6 +388,7 @@ type Field struct {
 
 // Action Slack操作按钮
 type Action struct {
+	Name    string `json:"name,omitempty"`    // 名称（交互回调中返回）
 	Type    string `json:"type"`              // 类型：button
 	Text    string `json:"text"`              // 按钮文本
 	URL     string `json:"url,omitempty"`     // 链接URL
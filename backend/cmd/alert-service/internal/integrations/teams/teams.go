@@ -20,20 +20,21 @@ type Config struct {
 	Priority    int                      // 优先级
 	RetryConfig integrations.RetryConfig // 重试配置
 	ColorMap    ColorMapping             // 颜色映射
+	ActionURL   string                   // 可操作消息回调地址（EdgeLink网关 /api/v1/callbacks/teams），为空时不显示确认/解决按钮
 }
 
-// Enabled 实现IntegrationConfig接口
-func (c *Config) Enabled() bool {
+// IsEnabled 实现IntegrationConfig接口
+func (c *Config) IsEnabled() bool {
 	return c.Enabled
 }
 
-// Priority 实现IntegrationConfig接口
-func (c *Config) Priority() int {
+// GetPriority 实现IntegrationConfig接口
+func (c *Config) GetPriority() int {
 	return c.Priority
 }
 
-// RetryConfig 实现IntegrationConfig接口
-func (c *Config) RetryConfig() integrations.RetryConfig {
+// GetRetryConfig 实现IntegrationConfig接口
+func (c *Config) GetRetryConfig() integrations.RetryConfig {
 	return c.RetryConfig
 }
 
@@ -347,6 +348,13 @@ func (i *Integration) buildAdaptiveCard(alert *domain.Alert) Message {
 		},
 	}
 
+	if i.config.ActionURL != "" {
+		actions = append(actions,
+			i.httpAction("Acknowledge", "acknowledge", alert.ID.String()),
+			i.httpAction("Resolve", "resolve", alert.ID.String()),
+		)
+	}
+
 	card := Message{
 		Type:       "message",
 		Attachments: []Attachment{
@@ -372,6 +380,22 @@ func (i *Integration) buildAdaptiveCard(alert *domain.Alert) Message {
 	return card
 }
 
+// httpAction 构建回调到EdgeLink网关的Action.Http按钮，请求由Teams携带签名令牌发出
+func (i *Integration) httpAction(title, action, alertID string) CardAction {
+	body, _ := json.Marshal(map[string]string{
+		"action":   action,
+		"alert_id": alertID,
+	})
+
+	return CardAction{
+		Type:   "Action.Http",
+		Title:  title,
+		Method: "POST",
+		URL:    i.config.ActionURL,
+		Body:   string(body),
+	}
+}
+
 // Message Teams消息结构
 type Message struct {
 	Type        string       `json:"type"`                   // message
@@ -415,10 +439,12 @@ type Fact struct {
 
 // CardAction 卡片操作
 type CardAction struct {
-	Type  string `json:"type"`            // Action.OpenUrl, Action.Submit, etc.
-	Title string `json:"title"`           // 按钮标题
-	URL   string `json:"url,omitempty"`   // URL（用于OpenUrl）
-	Data  interface{} `json:"data,omitempty"` // 数据（用于Submit）
+	Type   string      `json:"type"`             // Action.OpenUrl, Action.Submit, Action.Http, etc.
+	Title  string      `json:"title"`            // 按钮标题
+	URL    string      `json:"url,omitempty"`    // URL（用于OpenUrl/Http）
+	Method string      `json:"method,omitempty"` // HTTP方法（用于Http）
+	Body   string      `json:"body,omitempty"`   // 请求体（用于Http）
+	Data   interface{} `json:"data,omitempty"`   // 数据（用于Submit）
 }
 
 // MSTeamsMetadata Teams特定元数据
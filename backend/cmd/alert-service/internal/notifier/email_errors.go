@@ -0,0 +1,126 @@
+package notifier
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/textproto"
+	"strconv"
+	"time"
+)
+
+// EmailSendError 邮件提供商返回的发送错误
+type EmailSendError struct {
+	Provider   string
+	StatusCode int           // HTTP状态码或SMTP响应码，网络错误时为0
+	Retryable  bool          // 稍后重试可能成功（限流、服务端错误、网络错误）
+	Rejected   bool          // 邮件内容或收件人被拒绝，换用其他提供商也无法投递
+	RetryAfter time.Duration // 提供商要求的重试等待时间
+	Message    string
+	Err        error
+}
+
+// Error 实现error接口
+func (e *EmailSendError) Error() string {
+	msg := e.Message
+	if msg == "" && e.Err != nil {
+		msg = e.Err.Error()
+	}
+	if e.StatusCode > 0 {
+		return fmt.Sprintf("%s: status %d: %s", e.Provider, e.StatusCode, msg)
+	}
+	return fmt.Sprintf("%s: %s", e.Provider, msg)
+}
+
+// Unwrap 返回底层错误
+func (e *EmailSendError) Unwrap() error {
+	return e.Err
+}
+
+// IsRetryableEmailError 判断发送错误是否值得重试，未分类的错误按可重试处理
+func IsRetryableEmailError(err error) bool {
+	var sendErr *EmailSendError
+	if errors.As(err, &sendErr) {
+		return sendErr.Retryable
+	}
+	return true
+}
+
+// emailRetryAfter 返回错误中提供商要求的重试等待时间
+func emailRetryAfter(err error) time.Duration {
+	var sendErr *EmailSendError
+	if errors.As(err, &sendErr) {
+		return sendErr.RetryAfter
+	}
+	return 0
+}
+
+// isEmailRejection 判断错误是否为邮件本身被拒绝（切换提供商无意义）
+func isEmailRejection(err error) bool {
+	var sendErr *EmailSendError
+	return errors.As(err, &sendErr) && sendErr.Rejected
+}
+
+// classifyHTTPError 按HTTP响应构造发送错误
+// 429与5xx可重试；400/413/422表示邮件被拒绝；401/403等鉴权或配置问题不重试但允许切换提供商
+func classifyHTTPError(provider string, resp *http.Response, message string) *EmailSendError {
+	sendErr := &EmailSendError{
+		Provider:   provider,
+		StatusCode: resp.StatusCode,
+		Message:    message,
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests, resp.StatusCode >= 500:
+		sendErr.Retryable = true
+		sendErr.RetryAfter = parseRetryAfter(resp.Header.Get("Retry-After"))
+	case resp.StatusCode == http.StatusBadRequest,
+		resp.StatusCode == http.StatusRequestEntityTooLarge,
+		resp.StatusCode == http.StatusUnprocessableEntity:
+		sendErr.Rejected = true
+	}
+
+	return sendErr
+}
+
+// networkError 请求未得到响应（连接失败、超时），可重试
+func networkError(provider string, err error) *EmailSendError {
+	return &EmailSendError{
+		Provider:  provider,
+		Retryable: true,
+		Err:       err,
+	}
+}
+
+// classifySMTPError 按SMTP响应码分类：4xx为临时错误，5xx为永久错误，无响应码的连接错误可重试
+func classifySMTPError(err error) *EmailSendError {
+	sendErr := &EmailSendError{
+		Provider:  "smtp",
+		Retryable: true,
+		Err:       err,
+	}
+
+	var protoErr *textproto.Error
+	if errors.As(err, &protoErr) {
+		sendErr.StatusCode = protoErr.Code
+		sendErr.Retryable = protoErr.Code < 500
+	}
+
+	return sendErr
+}
+
+// parseRetryAfter 解析Retry-After头（秒数或HTTP日期）
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		if d := time.Until(at); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
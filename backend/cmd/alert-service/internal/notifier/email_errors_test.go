@@ -0,0 +1,121 @@
+package notifier
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/textproto"
+	"testing"
+	"time"
+)
+
+func TestClassifyHTTPError(t *testing.T) {
+	tests := []struct {
+		status     int
+		retryAfter string
+		retryable  bool
+		rejected   bool
+		wait       time.Duration
+	}{
+		{status: http.StatusTooManyRequests, retryAfter: "30", retryable: true, wait: 30 * time.Second},
+		{status: http.StatusInternalServerError, retryable: true},
+		{status: http.StatusServiceUnavailable, retryAfter: "5", retryable: true, wait: 5 * time.Second},
+		{status: http.StatusBadRequest, rejected: true},
+		{status: http.StatusRequestEntityTooLarge, rejected: true},
+		{status: http.StatusUnprocessableEntity, rejected: true},
+		// 鉴权或配置问题：不重试，但允许切换到备用提供商
+		{status: http.StatusUnauthorized},
+		{status: http.StatusForbidden},
+		{status: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.status), func(t *testing.T) {
+			resp := &http.Response{StatusCode: tt.status, Header: http.Header{}}
+			if tt.retryAfter != "" {
+				resp.Header.Set("Retry-After", tt.retryAfter)
+			}
+
+			err := classifyHTTPError("test", resp, "message")
+			if err.Retryable != tt.retryable || err.Rejected != tt.rejected {
+				t.Errorf("retryable=%v rejected=%v, want retryable=%v rejected=%v",
+					err.Retryable, err.Rejected, tt.retryable, tt.rejected)
+			}
+			if err.RetryAfter != tt.wait {
+				t.Errorf("RetryAfter = %v, want %v", err.RetryAfter, tt.wait)
+			}
+			if err.StatusCode != tt.status {
+				t.Errorf("StatusCode = %d, want %d", err.StatusCode, tt.status)
+			}
+		})
+	}
+}
+
+func TestClassifySMTPError(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		code      int
+		retryable bool
+	}{
+		{name: "temporary", err: &textproto.Error{Code: 451, Msg: "try again later"}, code: 451, retryable: true},
+		{name: "permanent", err: &textproto.Error{Code: 550, Msg: "mailbox unavailable"}, code: 550},
+		{name: "wrapped", err: fmt.Errorf("send: %w", &textproto.Error{Code: 554, Msg: "rejected"}), code: 554},
+		{name: "connection", err: errors.New("dial tcp: connection refused"), retryable: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := classifySMTPError(tt.err)
+			if err.StatusCode != tt.code || err.Retryable != tt.retryable {
+				t.Errorf("code=%d retryable=%v, want code=%d retryable=%v", err.StatusCode, err.Retryable, tt.code, tt.retryable)
+			}
+		})
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	if got := parseRetryAfter(""); got != 0 {
+		t.Errorf("empty = %v, want 0", got)
+	}
+	if got := parseRetryAfter("120"); got != 2*time.Minute {
+		t.Errorf("seconds = %v, want 2m", got)
+	}
+	if got := parseRetryAfter("-1"); got != 0 {
+		t.Errorf("negative = %v, want 0", got)
+	}
+	if got := parseRetryAfter("soon"); got != 0 {
+		t.Errorf("invalid = %v, want 0", got)
+	}
+
+	future := time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)
+	if got := parseRetryAfter(future); got <= 58*time.Minute || got > time.Hour {
+		t.Errorf("http date = %v, want about 1h", got)
+	}
+	past := time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat)
+	if got := parseRetryAfter(past); got != 0 {
+		t.Errorf("past http date = %v, want 0", got)
+	}
+}
+
+func TestEmailErrorHelpers(t *testing.T) {
+	rejected := &EmailSendError{Provider: "ses", Rejected: true}
+	throttled := &EmailSendError{Provider: "sendgrid", Retryable: true, RetryAfter: 10 * time.Second}
+	wrapped := fmt.Errorf("deliver: %w", throttled)
+
+	if IsRetryableEmailError(rejected) {
+		t.Error("rejected error should not be retryable")
+	}
+	if !IsRetryableEmailError(wrapped) {
+		t.Error("wrapped throttling error should be retryable")
+	}
+	if !IsRetryableEmailError(errors.New("unclassified")) {
+		t.Error("unclassified errors should be retryable")
+	}
+	if !isEmailRejection(rejected) || isEmailRejection(wrapped) {
+		t.Error("isEmailRejection misclassified")
+	}
+	if got := emailRetryAfter(wrapped); got != 10*time.Second {
+		t.Errorf("emailRetryAfter = %v, want 10s", got)
+	}
+}
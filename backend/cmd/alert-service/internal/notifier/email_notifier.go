@@ -12,6 +12,8 @@ import (
 
 	"github.com/edgelink/backend/internal/config"
 	"github.com/edgelink/backend/internal/domain"
+	"github.com/edgelink/backend/internal/repository"
+	"github.com/google/uuid"
 	"go.uber.org/zap"
 	"gopkg.in/gomail.v2"
 )
@@ -38,6 +40,15 @@ type EmailTask struct {
 	Alert     *domain.Alert
 	Retries   int
 	CreatedAt time.Time
+	HistoryID *uuid.UUID // 对应的邮件历史记录
+}
+
+// AlertGroupDigest 告警分组摘要（多条告警合并为一封邮件）
+type AlertGroupDigest struct {
+	Title    string
+	Labels   map[string]string
+	Alerts   []*domain.Alert
+	FollowUp bool // 分组成员变化后的后续通知
 }
 
 // EmailNotifier 邮件通知器
@@ -45,6 +56,7 @@ type EmailNotifier struct {
 	config     *config.EmailConfig
 	provider   EmailProvider
 	templates  *template.Template
+	history    repository.EmailHistoryRepository
 	logger     *zap.Logger
 
 	// 邮件队列和工作池
@@ -130,31 +142,29 @@ func (rl *RateLimiter) Stop() {
 	close(rl.done)
 }
 
-// NewEmailNotifier 创建邮件通知器
-func NewEmailNotifier(cfg *config.Config, logger *zap.Logger) (*EmailNotifier, error) {
+// NewEmailNotifier 创建邮件通知器（historyRepo为nil时不记录发送历史）
+func NewEmailNotifier(cfg *config.Config, historyRepo repository.EmailHistoryRepository, logger *zap.Logger) (*EmailNotifier, error) {
 	emailCfg := &cfg.Email
 
 	// 根据配置选择邮件提供商
-	var provider EmailProvider
-	var err error
-
-	switch emailCfg.Provider {
-	case "smtp", "":
-		provider, err = NewSMTPProvider(emailCfg, logger)
-	case "sendgrid":
-		provider, err = NewSendGridProvider(emailCfg, logger)
-	case "mailgun":
-		provider, err = NewMailgunProvider(emailCfg, logger)
-	case "ses":
-		provider, err = NewSESProvider(emailCfg, logger)
-	default:
-		return nil, fmt.Errorf("unsupported email provider: %s", emailCfg.Provider)
-	}
-
+	provider, err := newEmailProvider(emailCfg.Provider, emailCfg, logger)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create email provider: %w", err)
 	}
 
+	// 配置了备用提供商时按顺序组成故障切换链
+	if len(emailCfg.FallbackProviders) > 0 {
+		chain := []EmailProvider{provider}
+		for _, name := range emailCfg.FallbackProviders {
+			fallback, err := newEmailProvider(name, emailCfg, logger)
+			if err != nil {
+				return nil, fmt.Errorf("failed to create fallback email provider %s: %w", name, err)
+			}
+			chain = append(chain, fallback)
+		}
+		provider = NewFailoverProvider(chain, logger)
+	}
+
 	// 加载邮件模板
 	templates, err := loadEmailTemplates(emailCfg.TemplateDir)
 	if err != nil {
@@ -170,6 +180,7 @@ func NewEmailNotifier(cfg *config.Config, logger *zap.Logger) (*EmailNotifier, e
 		config:      emailCfg,
 		provider:    provider,
 		templates:   templates,
+		history:     historyRepo,
 		logger:      logger,
 		queue:       make(chan *EmailTask, emailCfg.QueueSize),
 		ctx:         ctx,
@@ -217,23 +228,9 @@ func loadEmailTemplates(templateDir string) (*template.Template, error) {
 
 // SendAlert 发送告警邮件(异步入队)
 func (en *EmailNotifier) SendAlert(ctx context.Context, alert *domain.Alert, recipients []string) error {
-	if len(recipients) == 0 {
-		return fmt.Errorf("no recipients specified")
-	}
-
-	// 渲染邮件内容
-	subject := fmt.Sprintf("[EdgeLink Alert] %s", alert.Title)
-	htmlBody, err := en.renderEmailTemplate(alert)
+	message, err := en.buildAlertMessage(alert, recipients)
 	if err != nil {
-		return fmt.Errorf("failed to render email template: %w", err)
-	}
-
-	// 创建邮件消息
-	message := &EmailMessage{
-		To:       recipients,
-		Subject:  subject,
-		HTMLBody: htmlBody,
-		TextBody: en.extractTextFromHTML(htmlBody),
+		return err
 	}
 
 	// 创建邮件任务并入队
@@ -242,6 +239,7 @@ func (en *EmailNotifier) SendAlert(ctx context.Context, alert *domain.Alert, rec
 		Alert:     alert,
 		Retries:   0,
 		CreatedAt: time.Now(),
+		HistoryID: en.recordHistory(ctx, alert, message, "queued"),
 	}
 
 	select {
@@ -252,9 +250,151 @@ func (en *EmailNotifier) SendAlert(ctx context.Context, alert *domain.Alert, rec
 		)
 		return nil
 	case <-ctx.Done():
+		en.updateHistory(task.HistoryID, "failed", ctx.Err())
 		return ctx.Err()
 	default:
-		return fmt.Errorf("email queue is full")
+		err := fmt.Errorf("email queue is full")
+		en.updateHistory(task.HistoryID, "failed", err)
+		return err
+	}
+}
+
+// SendAlertNow 同步发送告警邮件，返回提供商的发送结果（供持久化投递队列使用，重试由调用方负责）
+func (en *EmailNotifier) SendAlertNow(ctx context.Context, alert *domain.Alert, recipients []string) error {
+	message, err := en.buildAlertMessage(alert, recipients)
+	if err != nil {
+		return err
+	}
+
+	return en.sendNow(ctx, alert, message)
+}
+
+// SendGroupNow 同步发送告警分组摘要邮件，邮件中列出分组的全部成员
+func (en *EmailNotifier) SendGroupNow(ctx context.Context, group *AlertGroupDigest, recipients []string) error {
+	if len(group.Alerts) == 0 {
+		return fmt.Errorf("alert group has no members")
+	}
+	if len(recipients) == 0 {
+		return fmt.Errorf("no recipients specified")
+	}
+
+	htmlBody, err := en.renderGroupTemplate(group)
+	if err != nil {
+		return fmt.Errorf("failed to render email template: %w", err)
+	}
+
+	message := &EmailMessage{
+		To:       recipients,
+		Subject:  fmt.Sprintf("[EdgeLink Alert] %s", group.Title),
+		HTMLBody: htmlBody,
+		TextBody: en.extractTextFromHTML(htmlBody),
+	}
+
+	return en.sendNow(ctx, group.Alerts[0], message)
+}
+
+// sendNow 同步发送邮件并记录统计与历史
+func (en *EmailNotifier) sendNow(ctx context.Context, alert *domain.Alert, message *EmailMessage) error {
+	if !en.rateLimiter.Allow() {
+		return fmt.Errorf("email rate limit reached")
+	}
+
+	historyID := en.recordHistory(ctx, alert, message, "queued")
+
+	sendCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	if err := en.provider.Send(sendCtx, message); err != nil {
+		en.mu.Lock()
+		en.stats.TotalFailed++
+		en.stats.LastErrorTime = time.Now()
+		en.stats.LastError = err.Error()
+		en.mu.Unlock()
+
+		en.updateHistory(historyID, "failed", err)
+		return err
+	}
+
+	en.mu.Lock()
+	en.stats.TotalSent++
+	en.stats.LastSentTime = time.Now()
+	en.mu.Unlock()
+
+	en.updateHistory(historyID, "sent", nil)
+	return nil
+}
+
+// buildAlertMessage 渲染告警邮件
+func (en *EmailNotifier) buildAlertMessage(alert *domain.Alert, recipients []string) (*EmailMessage, error) {
+	if len(recipients) == 0 {
+		return nil, fmt.Errorf("no recipients specified")
+	}
+
+	// 渲染邮件内容
+	subject := fmt.Sprintf("[EdgeLink Alert] %s", alert.Title)
+	htmlBody, err := en.renderEmailTemplate(alert)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render email template: %w", err)
+	}
+
+	return &EmailMessage{
+		To:       recipients,
+		Subject:  subject,
+		HTMLBody: htmlBody,
+		TextBody: en.extractTextFromHTML(htmlBody),
+	}, nil
+}
+
+// recordHistory 写入邮件发送历史，失败时仅记录日志
+func (en *EmailNotifier) recordHistory(ctx context.Context, alert *domain.Alert, message *EmailMessage, status string) *uuid.UUID {
+	if en.history == nil {
+		return nil
+	}
+
+	history := &repository.EmailHistory{
+		Provider:   en.provider.Name(),
+		Recipients: message.To,
+		Subject:    message.Subject,
+		Status:     status,
+		Attempts:   1,
+	}
+	// 测试告警不入库，不关联告警ID
+	if alert != nil && !IsTestAlert(alert) {
+		history.AlertID = &alert.ID
+	}
+
+	if err := en.history.Create(ctx, history); err != nil {
+		en.logger.Warn("Failed to record email history", zap.Error(err))
+		return nil
+	}
+	return &history.ID
+}
+
+// updateHistory 更新邮件发送历史状态
+func (en *EmailNotifier) updateHistory(id *uuid.UUID, status string, sendErr error) {
+	if en.history == nil || id == nil {
+		return
+	}
+
+	var sentAt *time.Time
+	var lastError *string
+	if status == "sent" {
+		now := time.Now()
+		sentAt = &now
+	}
+	if sendErr != nil {
+		msg := sendErr.Error()
+		lastError = &msg
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := en.history.UpdateStatus(ctx, *id, status, sentAt, lastError); err != nil {
+		en.logger.Warn("Failed to update email history",
+			zap.String("history_id", id.String()),
+			zap.Error(err),
+		)
 	}
 }
 
@@ -326,6 +466,8 @@ func (en *EmailNotifier) handleSendSuccess(task *EmailTask) {
 	en.stats.TotalSent++
 	en.stats.LastSentTime = time.Now()
 	en.mu.Unlock()
+
+	en.updateHistory(task.HistoryID, "sent", nil)
 }
 
 // handleSendError 处理发送错误
@@ -342,30 +484,47 @@ func (en *EmailNotifier) handleSendError(task *EmailTask, err error) {
 	en.stats.LastError = err.Error()
 	en.mu.Unlock()
 
-	// 重试逻辑
-	if task.Retries < en.config.MaxRetries {
+	// 重试逻辑（提供商明确返回永久错误时不再重试）
+	if task.Retries < en.config.MaxRetries && IsRetryableEmailError(err) {
 		task.Retries++
 
 		en.mu.Lock()
 		en.stats.TotalRetried++
 		en.mu.Unlock()
 
+		en.updateHistory(task.HistoryID, "retrying", err)
+		if en.history != nil && task.HistoryID != nil {
+			if incErr := en.history.IncrementAttempts(context.Background(), *task.HistoryID); incErr != nil {
+				en.logger.Warn("Failed to increment email history attempts", zap.Error(incErr))
+			}
+		}
+
+		// 提供商要求的等待时间（如429的Retry-After）长于配置时以提供商为准
+		delay := en.config.RetryDelay
+		if retryAfter := emailRetryAfter(err); retryAfter > delay {
+			delay = retryAfter
+		}
+
 		en.logger.Info("Retrying email send",
 			zap.String("alert_id", task.Alert.ID.String()),
 			zap.Int("retry_attempt", task.Retries),
-			zap.Duration("delay", en.config.RetryDelay),
+			zap.Duration("delay", delay),
 		)
 
 		// 延迟后重新入队
-		time.Sleep(en.config.RetryDelay)
+		time.Sleep(delay)
 		select {
 		case en.queue <- task:
 		default:
 			en.logger.Error("Failed to re-queue email for retry",
 				zap.String("alert_id", task.Alert.ID.String()),
 			)
+			en.updateHistory(task.HistoryID, "failed", err)
 		}
+		return
 	}
+
+	en.updateHistory(task.HistoryID, "failed", err)
 }
 
 // renderEmailTemplate 渲染邮件模板
@@ -393,6 +552,53 @@ func (en *EmailNotifier) renderEmailTemplate(alert *domain.Alert) (string, error
 	return en.renderInlineTemplate(data)
 }
 
+// renderGroupTemplate 渲染告警分组摘要邮件
+func (en *EmailNotifier) renderGroupTemplate(group *AlertGroupDigest) (string, error) {
+	// 标题颜色取分组中最高的严重程度
+	highest := group.Alerts[0].Severity
+	items := make([]map[string]interface{}, 0, len(group.Alerts))
+	for _, alert := range group.Alerts {
+		if severityRank(alert.Severity) < severityRank(highest) {
+			highest = alert.Severity
+		}
+
+		item := map[string]interface{}{
+			"Title":         alert.Title,
+			"Message":       alert.Message,
+			"Severity":      alert.Severity,
+			"SeverityColor": en.getSeverityColor(alert.Severity),
+			"AlertType":     alert.Type,
+			"Status":        alert.Status,
+			"CreatedAt":     alert.CreatedAt.Format("2006-01-02 15:04:05"),
+			"DeviceID":      "",
+			"DeviceName":    "",
+		}
+		if alert.DeviceID != nil {
+			item["DeviceID"] = alert.DeviceID.String()
+		}
+		if alert.Device != nil {
+			item["DeviceName"] = alert.Device.Name
+		}
+		items = append(items, item)
+	}
+
+	data := map[string]interface{}{
+		"Title":         group.Title,
+		"Labels":        group.Labels,
+		"Count":         len(group.Alerts),
+		"FollowUp":      group.FollowUp,
+		"Severity":      highest,
+		"SeverityColor": en.getSeverityColor(highest),
+		"Alerts":        items,
+	}
+
+	if en.templates != nil && en.templates.Lookup("alert_group.html") != nil {
+		return en.renderTemplateFile("alert_group.html", data)
+	}
+
+	return en.renderInlineGroupTemplate(data)
+}
+
 // renderTemplateFile 使用外部模板文件渲染
 func (en *EmailNotifier) renderTemplateFile(name string, data interface{}) (string, error) {
 	var buf []byte
@@ -542,6 +748,64 @@ func (en *EmailNotifier) renderInlineTemplate(data map[string]interface{}) (stri
 	return string(writer.buf), nil
 }
 
+// renderInlineGroupTemplate 使用内置模板渲染告警分组摘要
+func (en *EmailNotifier) renderInlineGroupTemplate(data map[string]interface{}) (string, error) {
+	tmplStr := `
+<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <style>
+        body { font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Arial, sans-serif; line-height: 1.6; color: #333; margin: 0; padding: 20px; background-color: #f5f5f5; }
+        .container { max-width: 680px; margin: 0 auto; background-color: white; border-radius: 8px; overflow: hidden; box-shadow: 0 2px 8px rgba(0,0,0,0.1); }
+        .header { background-color: {{.SeverityColor}}; color: white; padding: 20px; }
+        .header h2 { margin: 0 0 10px 0; font-size: 22px; }
+        .content { padding: 30px; }
+        .labels span { display: inline-block; padding: 3px 8px; margin: 0 6px 6px 0; background-color: #eee; border-radius: 4px; font-size: 13px; }
+        .item { background-color: #f9f9f9; padding: 12px 15px; margin: 10px 0; border-radius: 4px; }
+        .item p { margin: 4px 0; color: #666; font-size: 14px; }
+        .footer { margin-top: 30px; padding-top: 20px; border-top: 2px solid #e9e9e9; font-size: 13px; color: #999; text-align: center; }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <div class="header">
+            <h2>{{.Title}}</h2>
+            <span>{{if .FollowUp}}分组成员已变化，当前{{else}}共{{end}} {{.Count}} 条告警</span>
+        </div>
+        <div class="content">
+            <div class="labels">{{range $key, $value := .Labels}}<span>{{$key}}={{$value}}</span>{{end}}</div>
+            {{range .Alerts}}
+            <div class="item" style="border-left: 4px solid {{.SeverityColor}};">
+                <strong>{{.Title}}</strong>
+                <p>{{.Message}}</p>
+                <p>严重程度: {{.Severity}} | 类型: {{.AlertType}} | 状态: {{.Status}} | 时间: {{.CreatedAt}}</p>
+                {{if .DeviceName}}<p>设备: {{.DeviceName}}</p>{{else if .DeviceID}}<p>设备ID: {{.DeviceID}}</p>{{end}}
+            </div>
+            {{end}}
+        </div>
+        <div class="footer">
+            <p>此邮件由EdgeLink告警系统自动发送,请勿直接回复</p>
+        </div>
+    </div>
+</body>
+</html>
+`
+
+	tmpl, err := template.New("alert_group_inline").Parse(tmplStr)
+	if err != nil {
+		return "", err
+	}
+
+	writer := &bytesWriter{}
+	if err := tmpl.Execute(writer, data); err != nil {
+		return "", err
+	}
+
+	return string(writer.buf), nil
+}
+
 // getSeverityColor 获取严重程度对应的颜色
 func (en *EmailNotifier) getSeverityColor(severity domain.Severity) string {
 	switch severity {
@@ -558,6 +822,22 @@ func (en *EmailNotifier) getSeverityColor(severity domain.Severity) string {
 	}
 }
 
+// severityRank 严重程度排序，数字越小越严重
+func severityRank(severity domain.Severity) int {
+	switch severity {
+	case domain.SeverityCritical:
+		return 0
+	case domain.SeverityHigh:
+		return 1
+	case domain.SeverityMedium:
+		return 2
+	case domain.SeverityLow:
+		return 3
+	default:
+		return 4
+	}
+}
+
 // extractTextFromHTML 从HTML提取纯文本(简单实现)
 func (en *EmailNotifier) extractTextFromHTML(html string) string {
 	// TODO: 实现更完善的HTML到文本转换
@@ -685,7 +965,7 @@ func (p *SMTPProvider) Send(ctx context.Context, msg *EmailMessage) error {
 
 	// 发送邮件
 	if err := p.dialer.DialAndSend(m); err != nil {
-		return fmt.Errorf("failed to send email via SMTP: %w", err)
+		return classifySMTPError(fmt.Errorf("failed to send email via SMTP: %w", err))
 	}
 
 	return nil
@@ -695,72 +975,3 @@ func (p *SMTPProvider) Send(ctx context.Context, msg *EmailMessage) error {
 func (p *SMTPProvider) Name() string {
 	return "smtp"
 }
-
-// SendGridProvider SendGrid邮件提供商(占位实现)
-type SendGridProvider struct {
-	config *config.EmailConfig
-	logger *zap.Logger
-}
-
-func NewSendGridProvider(cfg *config.EmailConfig, logger *zap.Logger) (*SendGridProvider, error) {
-	if cfg.SendGrid.APIKey == "" {
-		return nil, fmt.Errorf("SendGrid API key not configured")
-	}
-	return &SendGridProvider{config: cfg, logger: logger}, nil
-}
-
-func (p *SendGridProvider) Send(ctx context.Context, msg *EmailMessage) error {
-	// TODO: 实现SendGrid API集成
-	p.logger.Warn("SendGrid provider not fully implemented yet")
-	return fmt.Errorf("SendGrid provider not implemented")
-}
-
-func (p *SendGridProvider) Name() string {
-	return "sendgrid"
-}
-
-// MailgunProvider Mailgun邮件提供商(占位实现)
-type MailgunProvider struct {
-	config *config.EmailConfig
-	logger *zap.Logger
-}
-
-func NewMailgunProvider(cfg *config.EmailConfig, logger *zap.Logger) (*MailgunProvider, error) {
-	if cfg.Mailgun.APIKey == "" {
-		return nil, fmt.Errorf("Mailgun API key not configured")
-	}
-	return &MailgunProvider{config: cfg, logger: logger}, nil
-}
-
-func (p *MailgunProvider) Send(ctx context.Context, msg *EmailMessage) error {
-	// TODO: 实现Mailgun API集成
-	p.logger.Warn("Mailgun provider not fully implemented yet")
-	return fmt.Errorf("Mailgun provider not implemented")
-}
-
-func (p *MailgunProvider) Name() string {
-	return "mailgun"
-}
-
-// SESProvider Amazon SES邮件提供商(占位实现)
-type SESProvider struct {
-	config *config.EmailConfig
-	logger *zap.Logger
-}
-
-func NewSESProvider(cfg *config.EmailConfig, logger *zap.Logger) (*SESProvider, error) {
-	if cfg.SES.AccessKeyID == "" || cfg.SES.SecretAccessKey == "" {
-		return nil, fmt.Errorf("AWS credentials not configured")
-	}
-	return &SESProvider{config: cfg, logger: logger}, nil
-}
-
-func (p *SESProvider) Send(ctx context.Context, msg *EmailMessage) error {
-	// TODO: 实现Amazon SES SDK集成
-	p.logger.Warn("SES provider not fully implemented yet")
-	return fmt.Errorf("SES provider not implemented")
-}
-
-func (p *SESProvider) Name() string {
-	return "ses"
-}
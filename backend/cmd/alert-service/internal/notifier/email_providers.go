@@ -0,0 +1,523 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/mail"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/edgelink/backend/internal/config"
+	"go.uber.org/zap"
+)
+
+const (
+	// emailAPITimeout 邮件API请求超时
+	emailAPITimeout = 30 * time.Second
+	// maxErrorBodySize 读取错误响应体的上限
+	maxErrorBodySize = 64 * 1024
+
+	defaultSendGridBaseURL  = "https://api.sendgrid.com"
+	defaultMailgunBaseURL   = "https://api.mailgun.net"
+	defaultMailgunEUBaseURL = "https://api.eu.mailgun.net"
+)
+
+// newEmailProvider 按名称创建邮件提供商
+func newEmailProvider(name string, cfg *config.EmailConfig, logger *zap.Logger) (EmailProvider, error) {
+	switch name {
+	case "smtp", "":
+		return NewSMTPProvider(cfg, logger)
+	case "sendgrid":
+		return NewSendGridProvider(cfg, logger)
+	case "mailgun":
+		return NewMailgunProvider(cfg, logger)
+	case "ses":
+		return NewSESProvider(cfg, logger)
+	default:
+		return nil, fmt.Errorf("unsupported email provider: %s", name)
+	}
+}
+
+// formatFromAddress 格式化发件人地址
+func formatFromAddress(cfg *config.EmailConfig) string {
+	if cfg.FromName == "" {
+		return cfg.FromAddress
+	}
+	return (&mail.Address{Name: cfg.FromName, Address: cfg.FromAddress}).String()
+}
+
+// readErrorBody 读取错误响应体
+func readErrorBody(resp *http.Response) []byte {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+	return body
+}
+
+// SendGridProvider SendGrid邮件提供商（v3 Mail Send API）
+type SendGridProvider struct {
+	config  *config.EmailConfig
+	baseURL string
+	client  *http.Client
+	logger  *zap.Logger
+}
+
+// NewSendGridProvider 创建SendGrid提供商
+func NewSendGridProvider(cfg *config.EmailConfig, logger *zap.Logger) (*SendGridProvider, error) {
+	if cfg.SendGrid.APIKey == "" {
+		return nil, fmt.Errorf("SendGrid API key not configured")
+	}
+
+	baseURL := cfg.SendGrid.BaseURL
+	if baseURL == "" {
+		baseURL = defaultSendGridBaseURL
+	}
+
+	return &SendGridProvider{
+		config:  cfg,
+		baseURL: strings.TrimRight(baseURL, "/"),
+		client:  &http.Client{Timeout: emailAPITimeout},
+		logger:  logger,
+	}, nil
+}
+
+type sendGridAddress struct {
+	Email string `json:"email"`
+	Name  string `json:"name,omitempty"`
+}
+
+type sendGridContent struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+type sendGridRequest struct {
+	Personalizations []struct {
+		To []sendGridAddress `json:"to"`
+	} `json:"personalizations"`
+	From         sendGridAddress        `json:"from"`
+	ReplyTo      *sendGridAddress       `json:"reply_to,omitempty"`
+	Subject      string                 `json:"subject"`
+	Content      []sendGridContent      `json:"content"`
+	Headers      map[string]string      `json:"headers,omitempty"`
+	MailSettings map[string]interface{} `json:"mail_settings,omitempty"`
+}
+
+// Send 发送邮件
+func (p *SendGridProvider) Send(ctx context.Context, msg *EmailMessage) error {
+	payload := sendGridRequest{
+		From:    sendGridAddress{Email: p.config.FromAddress, Name: p.config.FromName},
+		Subject: msg.Subject,
+		Headers: msg.Headers,
+	}
+
+	payload.Personalizations = make([]struct {
+		To []sendGridAddress `json:"to"`
+	}, 1)
+	for _, to := range msg.To {
+		payload.Personalizations[0].To = append(payload.Personalizations[0].To, sendGridAddress{Email: to})
+	}
+
+	if p.config.ReplyTo != "" {
+		payload.ReplyTo = &sendGridAddress{Email: p.config.ReplyTo}
+	}
+
+	// SendGrid要求text/plain位于text/html之前
+	if msg.TextBody != "" {
+		payload.Content = append(payload.Content, sendGridContent{Type: "text/plain", Value: msg.TextBody})
+	}
+	if msg.HTMLBody != "" {
+		payload.Content = append(payload.Content, sendGridContent{Type: "text/html", Value: msg.HTMLBody})
+	}
+
+	if p.config.SendGrid.SandboxMode {
+		payload.MailSettings = map[string]interface{}{
+			"sandbox_mode": map[string]bool{"enable": true},
+		}
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return &EmailSendError{Provider: p.Name(), Err: fmt.Errorf("failed to encode request: %w", err)}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL+"/v3/mail/send", bytes.NewReader(body))
+	if err != nil {
+		return &EmailSendError{Provider: p.Name(), Err: err}
+	}
+	req.Header.Set("Authorization", "Bearer "+p.config.SendGrid.APIKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return networkError(p.Name(), err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+
+	return classifyHTTPError(p.Name(), resp, sendGridErrorMessage(readErrorBody(resp)))
+}
+
+// sendGridErrorMessage 提取SendGrid错误响应中的消息
+func sendGridErrorMessage(body []byte) string {
+	var parsed struct {
+		Errors []struct {
+			Message string  `json:"message"`
+			Field   *string `json:"field"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal(body, &parsed); err != nil || len(parsed.Errors) == 0 {
+		return strings.TrimSpace(string(body))
+	}
+
+	messages := make([]string, 0, len(parsed.Errors))
+	for _, e := range parsed.Errors {
+		if e.Field != nil && *e.Field != "" {
+			messages = append(messages, fmt.Sprintf("%s: %s", *e.Field, e.Message))
+		} else {
+			messages = append(messages, e.Message)
+		}
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Name 提供商名称
+func (p *SendGridProvider) Name() string {
+	return "sendgrid"
+}
+
+// MailgunProvider Mailgun邮件提供商（Messages API）
+type MailgunProvider struct {
+	config  *config.EmailConfig
+	baseURL string
+	client  *http.Client
+	logger  *zap.Logger
+}
+
+// NewMailgunProvider 创建Mailgun提供商，未指定BaseURL时按Region选择美国或欧盟区域
+func NewMailgunProvider(cfg *config.EmailConfig, logger *zap.Logger) (*MailgunProvider, error) {
+	if cfg.Mailgun.APIKey == "" {
+		return nil, fmt.Errorf("Mailgun API key not configured")
+	}
+	if cfg.Mailgun.Domain == "" {
+		return nil, fmt.Errorf("Mailgun domain not configured")
+	}
+
+	baseURL := cfg.Mailgun.BaseURL
+	if baseURL == "" {
+		switch strings.ToLower(cfg.Mailgun.Region) {
+		case "eu":
+			baseURL = defaultMailgunEUBaseURL
+		case "us", "":
+			baseURL = defaultMailgunBaseURL
+		default:
+			return nil, fmt.Errorf("unsupported Mailgun region: %s", cfg.Mailgun.Region)
+		}
+	}
+
+	return &MailgunProvider{
+		config:  cfg,
+		baseURL: strings.TrimRight(baseURL, "/"),
+		client:  &http.Client{Timeout: emailAPITimeout},
+		logger:  logger,
+	}, nil
+}
+
+// Send 发送邮件
+func (p *MailgunProvider) Send(ctx context.Context, msg *EmailMessage) error {
+	form := url.Values{}
+	form.Set("from", formatFromAddress(p.config))
+	for _, to := range msg.To {
+		form.Add("to", to)
+	}
+	form.Set("subject", msg.Subject)
+	if msg.TextBody != "" {
+		form.Set("text", msg.TextBody)
+	}
+	if msg.HTMLBody != "" {
+		form.Set("html", msg.HTMLBody)
+	}
+	if p.config.ReplyTo != "" {
+		form.Set("h:Reply-To", p.config.ReplyTo)
+	}
+	for k, v := range msg.Headers {
+		form.Set("h:"+k, v)
+	}
+
+	endpoint := fmt.Sprintf("%s/v3/%s/messages", p.baseURL, url.PathEscape(p.config.Mailgun.Domain))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return &EmailSendError{Provider: p.Name(), Err: err}
+	}
+	req.SetBasicAuth("api", p.config.Mailgun.APIKey)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return networkError(p.Name(), err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+
+	body := readErrorBody(resp)
+	message := strings.TrimSpace(string(body))
+	var parsed struct {
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(body, &parsed); err == nil && parsed.Message != "" {
+		message = parsed.Message
+	}
+
+	return classifyHTTPError(p.Name(), resp, message)
+}
+
+// Name 提供商名称
+func (p *MailgunProvider) Name() string {
+	return "mailgun"
+}
+
+// SESProvider Amazon SES邮件提供商（SES v2 SendEmail API，SigV4签名）
+type SESProvider struct {
+	config   *config.EmailConfig
+	endpoint string
+	signer   *sigV4Signer
+	client   *http.Client
+	logger   *zap.Logger
+}
+
+// NewSESProvider 创建SES提供商
+func NewSESProvider(cfg *config.EmailConfig, logger *zap.Logger) (*SESProvider, error) {
+	if cfg.SES.AccessKeyID == "" || cfg.SES.SecretAccessKey == "" {
+		return nil, fmt.Errorf("AWS credentials not configured")
+	}
+	if cfg.SES.Region == "" {
+		return nil, fmt.Errorf("AWS SES region not configured")
+	}
+
+	endpoint := cfg.SES.Endpoint
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://email.%s.amazonaws.com", cfg.SES.Region)
+	}
+
+	return &SESProvider{
+		config:   cfg,
+		endpoint: strings.TrimRight(endpoint, "/"),
+		signer: &sigV4Signer{
+			accessKeyID:     cfg.SES.AccessKeyID,
+			secretAccessKey: cfg.SES.SecretAccessKey,
+			sessionToken:    cfg.SES.SessionToken,
+			region:          cfg.SES.Region,
+			service:         "ses",
+		},
+		client: &http.Client{Timeout: emailAPITimeout},
+		logger: logger,
+	}, nil
+}
+
+type sesContent struct {
+	Data    string `json:"Data"`
+	Charset string `json:"Charset"`
+}
+
+type sesHeader struct {
+	Name  string `json:"Name"`
+	Value string `json:"Value"`
+}
+
+type sesSendEmailRequest struct {
+	FromEmailAddress string `json:"FromEmailAddress"`
+	Destination      struct {
+		ToAddresses []string `json:"ToAddresses"`
+	} `json:"Destination"`
+	ReplyToAddresses []string `json:"ReplyToAddresses,omitempty"`
+	Content          struct {
+		Simple struct {
+			Subject sesContent `json:"Subject"`
+			Body    struct {
+				Html *sesContent `json:"Html,omitempty"`
+				Text *sesContent `json:"Text,omitempty"`
+			} `json:"Body"`
+			Headers []sesHeader `json:"Headers,omitempty"`
+		} `json:"Simple"`
+	} `json:"Content"`
+	ConfigurationSetName string `json:"ConfigurationSetName,omitempty"`
+}
+
+// Send 发送邮件
+func (p *SESProvider) Send(ctx context.Context, msg *EmailMessage) error {
+	var payload sesSendEmailRequest
+	payload.FromEmailAddress = formatFromAddress(p.config)
+	payload.Destination.ToAddresses = msg.To
+	if p.config.ReplyTo != "" {
+		payload.ReplyToAddresses = []string{p.config.ReplyTo}
+	}
+	payload.ConfigurationSetName = p.config.SES.ConfigSet
+
+	simple := &payload.Content.Simple
+	simple.Subject = sesContent{Data: msg.Subject, Charset: "UTF-8"}
+	if msg.HTMLBody != "" {
+		simple.Body.Html = &sesContent{Data: msg.HTMLBody, Charset: "UTF-8"}
+	}
+	if msg.TextBody != "" {
+		simple.Body.Text = &sesContent{Data: msg.TextBody, Charset: "UTF-8"}
+	}
+	for k, v := range msg.Headers {
+		simple.Headers = append(simple.Headers, sesHeader{Name: k, Value: v})
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return &EmailSendError{Provider: p.Name(), Err: fmt.Errorf("failed to encode request: %w", err)}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.endpoint+"/v2/email/outbound-emails", bytes.NewReader(body))
+	if err != nil {
+		return &EmailSendError{Provider: p.Name(), Err: err}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	p.signer.Sign(req, body, time.Now())
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return networkError(p.Name(), err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+
+	return classifySESError(resp, readErrorBody(resp))
+}
+
+// classifySESError 按SES错误类型细化分类
+func classifySESError(resp *http.Response, body []byte) *EmailSendError {
+	var parsed struct {
+		Type    string `json:"__type"`
+		Message string `json:"message"`
+	}
+	_ = json.Unmarshal(body, &parsed)
+
+	errorType := resp.Header.Get("X-Amzn-ErrorType")
+	if errorType == "" {
+		errorType = parsed.Type
+	}
+	// 形如 "MessageRejected:http://internal.amazon.com/..." 或 "com.amazonaws...#MessageRejected"
+	if i := strings.Index(errorType, ":"); i >= 0 {
+		errorType = errorType[:i]
+	}
+	if i := strings.LastIndex(errorType, "#"); i >= 0 {
+		errorType = errorType[i+1:]
+	}
+
+	message := parsed.Message
+	if message == "" {
+		message = strings.TrimSpace(string(body))
+	}
+	if errorType != "" {
+		message = errorType + ": " + message
+	}
+
+	sendErr := classifyHTTPError("ses", resp, message)
+	switch errorType {
+	case "MessageRejected", "BadRequestException":
+		sendErr.Retryable = false
+		sendErr.Rejected = true
+	case "MailFromDomainNotVerifiedException", "AccountSuspendedException", "SendingPausedException",
+		"LimitExceededException", "NotFoundException":
+		// 账号或配置问题，重试无效但备用提供商可能正常
+		sendErr.Retryable = false
+		sendErr.Rejected = false
+	case "TooManyRequestsException", "ThrottlingException":
+		sendErr.Retryable = true
+		sendErr.Rejected = false
+	}
+
+	return sendErr
+}
+
+// Name 提供商名称
+func (p *SESProvider) Name() string {
+	return "ses"
+}
+
+// FailoverProvider 按顺序尝试多个提供商，前一个失败时切换到下一个
+// 邮件本身被拒绝时不再切换，避免同一封无效邮件在每个提供商上重复失败
+type FailoverProvider struct {
+	providers []EmailProvider
+	logger    *zap.Logger
+}
+
+// NewFailoverProvider 创建带备用提供商的邮件提供商
+func NewFailoverProvider(providers []EmailProvider, logger *zap.Logger) *FailoverProvider {
+	return &FailoverProvider{
+		providers: providers,
+		logger:    logger,
+	}
+}
+
+// Send 发送邮件，所有提供商均失败时返回合并后的错误
+func (p *FailoverProvider) Send(ctx context.Context, msg *EmailMessage) error {
+	errs := make([]error, 0, len(p.providers))
+	retryable := false
+	var retryAfter time.Duration
+
+	for i, provider := range p.providers {
+		err := provider.Send(ctx, msg)
+		if err == nil {
+			if i > 0 {
+				p.logger.Info("Email delivered via fallback provider",
+					zap.String("provider", provider.Name()),
+					zap.Strings("recipients", msg.To),
+				)
+			}
+			return nil
+		}
+
+		if isEmailRejection(err) {
+			return err
+		}
+
+		errs = append(errs, err)
+		if IsRetryableEmailError(err) {
+			retryable = true
+			if d := emailRetryAfter(err); d > retryAfter {
+				retryAfter = d
+			}
+		}
+
+		if i < len(p.providers)-1 {
+			p.logger.Warn("Email provider failed, failing over",
+				zap.String("provider", provider.Name()),
+				zap.String("next_provider", p.providers[i+1].Name()),
+				zap.Error(err),
+			)
+		}
+
+		if ctx.Err() != nil {
+			break
+		}
+	}
+
+	return &EmailSendError{
+		Provider:   "failover",
+		Retryable:  retryable,
+		RetryAfter: retryAfter,
+		Err:        errors.Join(errs...),
+	}
+}
+
+// Name 提供商名称（主提供商）
+func (p *FailoverProvider) Name() string {
+	return p.providers[0].Name()
+}
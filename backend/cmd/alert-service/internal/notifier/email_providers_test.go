@@ -0,0 +1,503 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/edgelink/backend/internal/config"
+	"go.uber.org/zap"
+)
+
+func testEmailMessage() *EmailMessage {
+	return &EmailMessage{
+		To:       []string{"ops@example.com", "oncall@example.com"},
+		Subject:  "[critical] device offline",
+		HTMLBody: "<p>device offline</p>",
+		TextBody: "device offline",
+		Headers:  map[string]string{"X-EdgeLink-Alert-ID": "a1"},
+	}
+}
+
+func testEmailConfig() *config.EmailConfig {
+	return &config.EmailConfig{
+		FromAddress: "alerts@example.com",
+		FromName:    "EdgeLink",
+		ReplyTo:     "noreply@example.com",
+	}
+}
+
+// respond 返回按给定状态码、响应头和响应体应答的处理函数
+func respond(status int, headers map[string]string, body string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		for k, v := range headers {
+			w.Header().Set(k, v)
+		}
+		w.WriteHeader(status)
+		io.WriteString(w, body)
+	}
+}
+
+func TestSendGridProviderSend(t *testing.T) {
+	var got struct {
+		path, auth string
+		body       sendGridRequest
+	}
+
+	tests := []struct {
+		name      string
+		handler   http.HandlerFunc
+		wantErr   bool
+		retryable bool
+		rejected  bool
+		wait      time.Duration
+		message   string
+	}{
+		{name: "accepted", handler: respond(http.StatusAccepted, nil, "")},
+		{
+			name:      "rate limited",
+			handler:   respond(http.StatusTooManyRequests, map[string]string{"Retry-After": "7"}, `{"errors":[{"message":"too many requests"}]}`),
+			wantErr:   true,
+			retryable: true,
+			wait:      7 * time.Second,
+		},
+		{
+			name:     "invalid recipient",
+			handler:  respond(http.StatusBadRequest, nil, `{"errors":[{"message":"Invalid email","field":"personalizations.0.to"}]}`),
+			wantErr:  true,
+			rejected: true,
+			message:  "personalizations.0.to: Invalid email",
+		},
+		{
+			name:    "bad api key",
+			handler: respond(http.StatusUnauthorized, nil, `{"errors":[{"message":"authorization required"}]}`),
+			wantErr: true,
+			message: "authorization required",
+		},
+		{name: "server error", handler: respond(http.StatusBadGateway, nil, "upstream"), wantErr: true, retryable: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got.path = r.URL.Path
+				got.auth = r.Header.Get("Authorization")
+				if err := json.NewDecoder(r.Body).Decode(&got.body); err != nil {
+					t.Errorf("decode request: %v", err)
+				}
+				tt.handler(w, r)
+			}))
+			defer server.Close()
+
+			cfg := testEmailConfig()
+			cfg.SendGrid = config.SendGridConfig{APIKey: "SG.key", BaseURL: server.URL + "/"}
+			provider, err := NewSendGridProvider(cfg, zap.NewNop())
+			if err != nil {
+				t.Fatalf("NewSendGridProvider: %v", err)
+			}
+
+			err = provider.Send(context.Background(), testEmailMessage())
+			checkEmailSendError(t, err, tt.wantErr, tt.retryable, tt.rejected, tt.wait, tt.message)
+
+			if got.path != "/v3/mail/send" {
+				t.Errorf("path = %q, want /v3/mail/send", got.path)
+			}
+			if got.auth != "Bearer SG.key" {
+				t.Errorf("Authorization = %q", got.auth)
+			}
+			if len(got.body.Personalizations) != 1 || len(got.body.Personalizations[0].To) != 2 {
+				t.Errorf("personalizations = %+v", got.body.Personalizations)
+			}
+			// SendGrid要求text/plain位于text/html之前
+			if len(got.body.Content) != 2 || got.body.Content[0].Type != "text/plain" || got.body.Content[1].Type != "text/html" {
+				t.Errorf("content order = %+v", got.body.Content)
+			}
+			if got.body.ReplyTo == nil || got.body.ReplyTo.Email != "noreply@example.com" {
+				t.Errorf("reply_to = %+v", got.body.ReplyTo)
+			}
+		})
+	}
+}
+
+func TestNewMailgunProviderBaseURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     config.MailgunConfig
+		want    string
+		wantErr bool
+	}{
+		{name: "default region", cfg: config.MailgunConfig{}, want: defaultMailgunBaseURL},
+		{name: "us", cfg: config.MailgunConfig{Region: "us"}, want: "https://api.mailgun.net"},
+		{name: "eu", cfg: config.MailgunConfig{Region: "EU"}, want: "https://api.eu.mailgun.net"},
+		{name: "explicit base url wins", cfg: config.MailgunConfig{Region: "eu", BaseURL: "https://mg.internal/"}, want: "https://mg.internal"},
+		{name: "unknown region", cfg: config.MailgunConfig{Region: "ap"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testEmailConfig()
+			cfg.Mailgun = tt.cfg
+			cfg.Mailgun.APIKey = "key"
+			cfg.Mailgun.Domain = "mg.example.com"
+
+			provider, err := NewMailgunProvider(cfg, zap.NewNop())
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewMailgunProvider: %v", err)
+			}
+			if provider.baseURL != tt.want {
+				t.Errorf("baseURL = %q, want %q", provider.baseURL, tt.want)
+			}
+		})
+	}
+}
+
+func TestMailgunProviderSend(t *testing.T) {
+	var path, user, pass string
+	var form url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		user, pass, _ = r.BasicAuth()
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("ParseForm: %v", err)
+		}
+		form = r.PostForm
+		respond(http.StatusOK, nil, `{"id":"<1@mg.example.com>","message":"Queued. Thank you."}`)(w, r)
+	}))
+	defer server.Close()
+
+	cfg := testEmailConfig()
+	cfg.Mailgun = config.MailgunConfig{APIKey: "key", Domain: "mg.example.com", Region: "eu", BaseURL: server.URL}
+	provider, err := NewMailgunProvider(cfg, zap.NewNop())
+	if err != nil {
+		t.Fatalf("NewMailgunProvider: %v", err)
+	}
+
+	if err := provider.Send(context.Background(), testEmailMessage()); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	if path != "/v3/mg.example.com/messages" {
+		t.Errorf("path = %q", path)
+	}
+	if user != "api" || pass != "key" {
+		t.Errorf("basic auth = %q:%q", user, pass)
+	}
+	if got := form["to"]; len(got) != 2 {
+		t.Errorf("to = %v", got)
+	}
+	if got := form.Get("from"); got != `"EdgeLink" <alerts@example.com>` {
+		t.Errorf("from = %q", got)
+	}
+	if form.Get("h:Reply-To") != "noreply@example.com" || form.Get("h:X-EdgeLink-Alert-ID") != "a1" {
+		t.Errorf("headers = %v", form)
+	}
+}
+
+func TestMailgunProviderSendError(t *testing.T) {
+	server := httptest.NewServer(respond(http.StatusBadRequest, nil, `{"message":"'to' parameter is not a valid address"}`))
+	defer server.Close()
+
+	cfg := testEmailConfig()
+	cfg.Mailgun = config.MailgunConfig{APIKey: "key", Domain: "mg.example.com", BaseURL: server.URL}
+	provider, err := NewMailgunProvider(cfg, zap.NewNop())
+	if err != nil {
+		t.Fatalf("NewMailgunProvider: %v", err)
+	}
+
+	err = provider.Send(context.Background(), testEmailMessage())
+	checkEmailSendError(t, err, true, false, true, 0, "'to' parameter is not a valid address")
+}
+
+func TestSESProviderSend(t *testing.T) {
+	var path, auth, token string
+	var body sesSendEmailRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		auth = r.Header.Get("Authorization")
+		token = r.Header.Get("X-Amz-Security-Token")
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		respond(http.StatusOK, nil, `{"MessageId":"0100"}`)(w, r)
+	}))
+	defer server.Close()
+
+	cfg := testEmailConfig()
+	cfg.SES = config.SESConfig{
+		Region:          "eu-west-1",
+		AccessKeyID:     "AKIDEXAMPLE",
+		SecretAccessKey: "secret",
+		SessionToken:    "token",
+		ConfigSet:       "alerts",
+		Endpoint:        server.URL,
+	}
+	provider, err := NewSESProvider(cfg, zap.NewNop())
+	if err != nil {
+		t.Fatalf("NewSESProvider: %v", err)
+	}
+
+	if err := provider.Send(context.Background(), testEmailMessage()); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	if path != "/v2/email/outbound-emails" {
+		t.Errorf("path = %q", path)
+	}
+	date := time.Now().UTC().Format(sigV4DateFormat)
+	if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/"+date+"/eu-west-1/ses/aws4_request, ") ||
+		!strings.Contains(auth, "SignedHeaders=content-type;host;x-amz-date;x-amz-security-token, ") {
+		t.Errorf("Authorization = %q", auth)
+	}
+	if token != "token" {
+		t.Errorf("X-Amz-Security-Token = %q", token)
+	}
+	if body.FromEmailAddress != `"EdgeLink" <alerts@example.com>` || len(body.Destination.ToAddresses) != 2 {
+		t.Errorf("request = %+v", body)
+	}
+	if body.ConfigurationSetName != "alerts" || body.Content.Simple.Body.Html == nil || body.Content.Simple.Body.Text == nil {
+		t.Errorf("request = %+v", body)
+	}
+}
+
+func TestNewSESProviderDefaultEndpoint(t *testing.T) {
+	cfg := testEmailConfig()
+	cfg.SES = config.SESConfig{Region: "eu-central-1", AccessKeyID: "id", SecretAccessKey: "secret"}
+	provider, err := NewSESProvider(cfg, zap.NewNop())
+	if err != nil {
+		t.Fatalf("NewSESProvider: %v", err)
+	}
+	if provider.endpoint != "https://email.eu-central-1.amazonaws.com" {
+		t.Errorf("endpoint = %q", provider.endpoint)
+	}
+}
+
+func TestClassifySESError(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		errorType  string
+		body       string
+		retryAfter string
+		retryable  bool
+		rejected   bool
+		message    string
+	}{
+		{
+			name:      "message rejected header",
+			status:    http.StatusBadRequest,
+			errorType: "MessageRejected:http://internal.amazon.com/coral/com.amazonaws.sesv2/",
+			body:      `{"message":"Email address is not verified."}`,
+			rejected:  true,
+			message:   "MessageRejected: Email address is not verified.",
+		},
+		{
+			name:      "throttling in body",
+			status:    http.StatusBadRequest,
+			body:      `{"__type":"com.amazonaws.sesv2#TooManyRequestsException","message":"Rate exceeded"}`,
+			retryable: true,
+			message:   "TooManyRequestsException: Rate exceeded",
+		},
+		{
+			name:      "account suspended",
+			status:    http.StatusBadRequest,
+			errorType: "AccountSuspendedException",
+			body:      `{"message":"Account suspended"}`,
+		},
+		{
+			name:      "unverified mail from domain",
+			status:    http.StatusBadRequest,
+			errorType: "MailFromDomainNotVerifiedException",
+		},
+		{
+			name:       "service unavailable",
+			status:     http.StatusServiceUnavailable,
+			body:       "Service Unavailable",
+			retryAfter: "3",
+			retryable:  true,
+			message:    "Service Unavailable",
+		},
+		{
+			name:     "bad request without type",
+			status:   http.StatusBadRequest,
+			body:     `{"message":"Missing required header"}`,
+			rejected: true,
+			message:  "Missing required header",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{StatusCode: tt.status, Header: http.Header{}}
+			if tt.errorType != "" {
+				resp.Header.Set("X-Amzn-ErrorType", tt.errorType)
+			}
+			if tt.retryAfter != "" {
+				resp.Header.Set("Retry-After", tt.retryAfter)
+			}
+
+			err := classifySESError(resp, []byte(tt.body))
+			if err.Retryable != tt.retryable || err.Rejected != tt.rejected {
+				t.Errorf("retryable=%v rejected=%v, want retryable=%v rejected=%v",
+					err.Retryable, err.Rejected, tt.retryable, tt.rejected)
+			}
+			if tt.message != "" && err.Message != tt.message {
+				t.Errorf("message = %q, want %q", err.Message, tt.message)
+			}
+		})
+	}
+}
+
+// stubEmailProvider 记录调用并返回固定结果的提供商
+type stubEmailProvider struct {
+	name  string
+	err   error
+	calls *[]string
+}
+
+func (p *stubEmailProvider) Send(ctx context.Context, msg *EmailMessage) error {
+	*p.calls = append(*p.calls, p.name)
+	return p.err
+}
+
+func (p *stubEmailProvider) Name() string {
+	return p.name
+}
+
+func TestFailoverProviderSend(t *testing.T) {
+	throttled := &EmailSendError{Provider: "a", Retryable: true, RetryAfter: 20 * time.Second}
+	unavailable := &EmailSendError{Provider: "b", Retryable: true, RetryAfter: 5 * time.Second}
+	unauthorized := &EmailSendError{Provider: "b", StatusCode: http.StatusUnauthorized}
+	rejected := &EmailSendError{Provider: "a", Rejected: true}
+
+	tests := []struct {
+		name      string
+		errs      []error
+		wantCalls string
+		wantErr   bool
+		retryable bool
+		wait      time.Duration
+		same      error // 期望原样返回的错误
+	}{
+		{name: "primary succeeds", errs: []error{nil, nil}, wantCalls: "a"},
+		{name: "fails over in order", errs: []error{throttled, nil, nil}, wantCalls: "a,b"},
+		{name: "uses third provider", errs: []error{throttled, unauthorized, nil}, wantCalls: "a,b,c"},
+		{name: "rejection stops failover", errs: []error{rejected, nil}, wantCalls: "a", wantErr: true, same: rejected},
+		{
+			name:      "all fail, longest retry-after",
+			errs:      []error{throttled, unavailable},
+			wantCalls: "a,b",
+			wantErr:   true,
+			retryable: true,
+			wait:      20 * time.Second,
+		},
+		{
+			name:      "retryable if any provider is",
+			errs:      []error{unauthorized, unavailable},
+			wantCalls: "a,b",
+			wantErr:   true,
+			retryable: true,
+			wait:      5 * time.Second,
+		},
+		{name: "permanent failures", errs: []error{unauthorized, unauthorized}, wantCalls: "a,b", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls []string
+			providers := make([]EmailProvider, len(tt.errs))
+			for i, err := range tt.errs {
+				providers[i] = &stubEmailProvider{name: string(rune('a' + i)), err: err, calls: &calls}
+			}
+
+			err := NewFailoverProvider(providers, zap.NewNop()).Send(context.Background(), testEmailMessage())
+
+			if got := strings.Join(calls, ","); got != tt.wantCalls {
+				t.Errorf("calls = %q, want %q", got, tt.wantCalls)
+			}
+			if tt.same != nil {
+				if err != tt.same {
+					t.Errorf("err = %v, want %v", err, tt.same)
+				}
+				return
+			}
+			checkEmailSendError(t, err, tt.wantErr, tt.retryable, false, tt.wait, "")
+		})
+	}
+}
+
+func TestFailoverProviderHTTP(t *testing.T) {
+	var order []string
+	sendgrid := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		order = append(order, "sendgrid")
+		respond(http.StatusServiceUnavailable, nil, "maintenance")(w, r)
+	}))
+	defer sendgrid.Close()
+	mailgun := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		order = append(order, "mailgun")
+		respond(http.StatusOK, nil, `{"message":"Queued"}`)(w, r)
+	}))
+	defer mailgun.Close()
+
+	cfg := testEmailConfig()
+	cfg.SendGrid = config.SendGridConfig{APIKey: "SG.key", BaseURL: sendgrid.URL}
+	cfg.Mailgun = config.MailgunConfig{APIKey: "key", Domain: "mg.example.com", BaseURL: mailgun.URL}
+
+	primary, err := newEmailProvider("sendgrid", cfg, zap.NewNop())
+	if err != nil {
+		t.Fatalf("sendgrid: %v", err)
+	}
+	fallback, err := newEmailProvider("mailgun", cfg, zap.NewNop())
+	if err != nil {
+		t.Fatalf("mailgun: %v", err)
+	}
+
+	provider := NewFailoverProvider([]EmailProvider{primary, fallback}, zap.NewNop())
+	if err := provider.Send(context.Background(), testEmailMessage()); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if got := strings.Join(order, ","); got != "sendgrid,mailgun" {
+		t.Errorf("order = %q, want sendgrid,mailgun", got)
+	}
+	if provider.Name() != "sendgrid" {
+		t.Errorf("Name = %q, want sendgrid", provider.Name())
+	}
+}
+
+// checkEmailSendError 校验错误是否为期望分类的EmailSendError
+func checkEmailSendError(t *testing.T, err error, wantErr, retryable, rejected bool, wait time.Duration, message string) {
+	t.Helper()
+
+	if !wantErr {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return
+	}
+
+	var sendErr *EmailSendError
+	if !errors.As(err, &sendErr) {
+		t.Fatalf("err = %v (%T), want *EmailSendError", err, err)
+	}
+	if sendErr.Retryable != retryable || sendErr.Rejected != rejected {
+		t.Errorf("retryable=%v rejected=%v, want retryable=%v rejected=%v", sendErr.Retryable, sendErr.Rejected, retryable, rejected)
+	}
+	if sendErr.RetryAfter != wait {
+		t.Errorf("RetryAfter = %v, want %v", sendErr.RetryAfter, wait)
+	}
+	if message != "" && sendErr.Message != message {
+		t.Errorf("message = %q, want %q", sendErr.Message, message)
+	}
+}
@@ -0,0 +1,135 @@
+package notifier
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// AWS Signature Version 4 test suite (aws4_testsuite) credentials and request time
+const (
+	sigV4TestAccessKeyID     = "AKIDEXAMPLE"
+	sigV4TestSecretAccessKey = "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"
+)
+
+var sigV4TestTime = time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC)
+
+func TestSigV4SignerTestSuite(t *testing.T) {
+	tests := []struct {
+		name          string
+		method        string
+		url           string
+		headers       map[string]string
+		body          string
+		signedHeaders string
+		signature     string
+	}{
+		{
+			name:          "get-vanilla",
+			method:        http.MethodGet,
+			url:           "https://example.amazonaws.com/",
+			signedHeaders: "host;x-amz-date",
+			signature:     "5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31",
+		},
+		{
+			name:          "get-vanilla-query-order-key-case",
+			method:        http.MethodGet,
+			url:           "https://example.amazonaws.com/?Param2=value2&Param1=value1",
+			signedHeaders: "host;x-amz-date",
+			signature:     "b97d918cfa904a5beff61c982a1b6f458b799221646efd99d3219ec94cdf2500",
+		},
+		{
+			name:          "post-vanilla",
+			method:        http.MethodPost,
+			url:           "https://example.amazonaws.com/",
+			signedHeaders: "host;x-amz-date",
+			signature:     "5da7c1a2acd57cee7505fc6676e4e544621c30862966e37dddb68e92efbe5d6b",
+		},
+		{
+			name:          "post-x-www-form-urlencoded",
+			method:        http.MethodPost,
+			url:           "https://example.amazonaws.com/",
+			headers:       map[string]string{"Content-Type": "application/x-www-form-urlencoded"},
+			body:          "Param1=value1",
+			signedHeaders: "content-type;host;x-amz-date",
+			signature:     "ff11897932ad3f4e8b18135d722051e5ac45fc38421b1da7b9d196a0fe09473a",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(tt.method, tt.url, strings.NewReader(tt.body))
+			if err != nil {
+				t.Fatalf("NewRequest: %v", err)
+			}
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+
+			signer := &sigV4Signer{
+				accessKeyID:     sigV4TestAccessKeyID,
+				secretAccessKey: sigV4TestSecretAccessKey,
+				region:          "us-east-1",
+				service:         "service",
+			}
+			signer.Sign(req, []byte(tt.body), sigV4TestTime)
+
+			want := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, " +
+				"SignedHeaders=" + tt.signedHeaders + ", Signature=" + tt.signature
+			if got := req.Header.Get("Authorization"); got != want {
+				t.Errorf("Authorization =\n  %s\nwant\n  %s", got, want)
+			}
+			if got := req.Header.Get("X-Amz-Date"); got != "20150830T123600Z" {
+				t.Errorf("X-Amz-Date = %q, want 20150830T123600Z", got)
+			}
+		})
+	}
+}
+
+func TestSigV4SignerSessionToken(t *testing.T) {
+	req, err := http.NewRequest(http.MethodPost, "https://email.us-east-1.amazonaws.com/v2/email/outbound-emails", nil)
+	if err != nil {
+		t.Fatalf("NewRequest: %v", err)
+	}
+
+	signer := &sigV4Signer{
+		accessKeyID:     sigV4TestAccessKeyID,
+		secretAccessKey: sigV4TestSecretAccessKey,
+		sessionToken:    "session-token",
+		region:          "us-east-1",
+		service:         "ses",
+	}
+	signer.Sign(req, nil, sigV4TestTime)
+
+	if got := req.Header.Get("X-Amz-Security-Token"); got != "session-token" {
+		t.Errorf("X-Amz-Security-Token = %q, want session-token", got)
+	}
+	// 会话令牌必须参与签名，否则AWS拒绝请求
+	if auth := req.Header.Get("Authorization"); !strings.Contains(auth, "SignedHeaders=host;x-amz-date;x-amz-security-token,") {
+		t.Errorf("Authorization does not sign the session token: %s", auth)
+	}
+}
+
+func TestCanonicalQueryString(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want string
+	}{
+		{raw: "", want: ""},
+		{raw: "b=2&a=1", want: "a=1&b=2"},
+		{raw: "a=2&a=1", want: "a=1&a=2"},
+		{raw: "q=hello+world", want: "q=hello%20world"},
+		{raw: "k=%2F%7E", want: "k=%2F~"},
+	}
+
+	for _, tt := range tests {
+		req, err := http.NewRequest(http.MethodGet, "https://example.com/?"+tt.raw, nil)
+		if err != nil {
+			t.Fatalf("NewRequest: %v", err)
+		}
+		if got := canonicalQueryString(req.URL.Query()); got != tt.want {
+			t.Errorf("canonicalQueryString(%q) = %q, want %q", tt.raw, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package notifier
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/edgelink/backend/internal/domain"
+	"github.com/google/uuid"
+)
+
+// TestAlertType 测试通知使用的告警类型，测试告警不写入数据库
+const TestAlertType domain.AlertType = "test_notification"
+
+// NewTestAlert 构建用于验证通知渠道配置的测试告警
+func NewTestAlert(channel string) *domain.Alert {
+	now := time.Now()
+	return &domain.Alert{
+		ID:       uuid.New(),
+		Severity: domain.SeverityLow,
+		Type:     TestAlertType,
+		Title:    fmt.Sprintf("EdgeLink test notification (%s)", channel),
+		Message:  "This is a test notification sent from the EdgeLink alert service. No action is required.",
+		Metadata: domain.JSONB{
+			"test":    true,
+			"channel": channel,
+		},
+		Status:          domain.AlertStatusActive,
+		OccurrenceCount: 1,
+		FirstSeenAt:     now,
+		LastSeenAt:      now,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+}
+
+// IsTestAlert 是否为测试告警
+func IsTestAlert(alert *domain.Alert) bool {
+	return alert != nil && alert.Type == TestAlertType
+}
@@ -3,115 +3,641 @@ package notifier
 import (
 	"bytes"
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"math/rand"
 	"net/http"
+	"net/url"
+	"strconv"
 	"time"
 
 	"github.com/edgelink/backend/internal/domain"
+	"github.com/edgelink/backend/internal/repository"
+	"github.com/google/uuid"
 	"go.uber.org/zap"
+	"gorm.io/gorm"
 )
 
-// WebhookPayload Webhook负载
+const (
+	// WebhookPayloadV1 扁平负载（默认，兼容已有接收端）
+	WebhookPayloadV1 = "v1"
+	// WebhookPayloadV2 结构化负载，包含事件ID、事件类型与完整告警状态
+	WebhookPayloadV2 = "v2"
+
+	// WebhookFormatJSON 直接发送负载
+	WebhookFormatJSON = "json"
+	// WebhookFormatCloudEvents 以CloudEvents 1.0结构化模式封装负载
+	WebhookFormatCloudEvents = "cloudevents"
+
+	// Webhook请求头
+	WebhookHeaderEventID       = "X-EdgeLink-Event-ID"
+	WebhookHeaderEventType     = "X-EdgeLink-Event-Type"
+	WebhookHeaderSchemaVersion = "X-EdgeLink-Schema-Version"
+	WebhookHeaderTimestamp     = "X-EdgeLink-Timestamp"
+	WebhookHeaderSignature     = "X-EdgeLink-Signature"
+
+	// cloudEventsSource CloudEvents source属性
+	cloudEventsSource = "/edgelink/alert-service"
+	// cloudEventsTypePrefix CloudEvents type前缀
+	cloudEventsTypePrefix = "com.edgelink."
+
+	// 端点自动停用的默认阈值：连续失败次数与持续失败时长同时满足
+	defaultDisableAfterFailures = 10
+	defaultDisableAfter         = time.Hour
+
+	// webhookErrorBodySize 错误信息中保留的响应体长度
+	webhookErrorBodySize = 512
+)
+
+// WebhookTarget Webhook端点配置（来自规则动作）
+type WebhookTarget struct {
+	Name                 string            // 端点名称，为空时使用URL主机名
+	URL                  string            // 端点地址
+	Secret               string            // 签名密钥，为空时不签名
+	Headers              map[string]string // 自定义请求头
+	PayloadVersion       string            // v1（默认）或 v2
+	Format               string            // json（默认）或 cloudevents
+	MaxRetries           int               // 单次投递内的重试次数，<0时使用默认值
+	EventID              string            // 事件ID，为空时随机生成；外部重试时传入固定值，接收端可据此去重
+	AutoDisable          bool              // 持续失败时自动停用
+	DisableAfterFailures int               // 停用所需的连续失败次数
+	DisableAfter         time.Duration     // 停用所需的持续失败时长
+}
+
+// eventID 本次发送使用的事件ID
+func (t *WebhookTarget) eventID() string {
+	if t.EventID != "" {
+		return t.EventID
+	}
+	return uuid.New().String()
+}
+
+// displayName 端点显示名称
+func (t *WebhookTarget) displayName() string {
+	if t.Name != "" {
+		return t.Name
+	}
+	if u, err := url.Parse(t.URL); err == nil && u.Host != "" {
+		return u.Host
+	}
+	return t.URL
+}
+
+// payloadVersion 负载版本
+func (t *WebhookTarget) payloadVersion() string {
+	if t.PayloadVersion == "" {
+		return WebhookPayloadV1
+	}
+	return t.PayloadVersion
+}
+
+// WebhookPayload Webhook负载（v1）
 type WebhookPayload struct {
-	AlertID      string                 `json:"alert_id"`
-	Title        string                 `json:"title"`
-	Message      string                 `json:"message"`
-	Severity     string                 `json:"severity"`
-	AlertType    string                 `json:"alert_type"`
-	DeviceID     *string                `json:"device_id,omitempty"`
-	Metadata     map[string]interface{} `json:"metadata,omitempty"`
-	CreatedAt    string                 `json:"created_at"`
-	Timestamp    string                 `json:"timestamp"`
+	AlertID   string                 `json:"alert_id"`
+	Title     string                 `json:"title"`
+	Message   string                 `json:"message"`
+	Severity  string                 `json:"severity"`
+	AlertType string                 `json:"alert_type"`
+	DeviceID  *string                `json:"device_id,omitempty"`
+	Metadata  map[string]interface{} `json:"metadata,omitempty"`
+	CreatedAt string                 `json:"created_at"`
+	Timestamp string                 `json:"timestamp"`
+}
+
+// WebhookEventV2 Webhook负载（v2）
+type WebhookEventV2 struct {
+	SchemaVersion string           `json:"schema_version"`
+	EventID       string           `json:"event_id"`
+	EventType     string           `json:"event_type"`
+	OccurredAt    string           `json:"occurred_at"`
+	Alert         *WebhookAlertV2  `json:"alert,omitempty"`  // 单条告警事件
+	Alerts        []WebhookAlertV2 `json:"alerts,omitempty"` // 分组摘要事件
+	Count         int              `json:"count,omitempty"`
+}
+
+// WebhookAlertV2 v2负载中的告警
+type WebhookAlertV2 struct {
+	ID              string                 `json:"id"`
+	Type            string                 `json:"type"`
+	Severity        string                 `json:"severity"`
+	Status          string                 `json:"status"`
+	Title           string                 `json:"title"`
+	Message         string                 `json:"message"`
+	DeviceID        *string                `json:"device_id,omitempty"`
+	Metadata        map[string]interface{} `json:"metadata,omitempty"`
+	OccurrenceCount int                    `json:"occurrence_count"`
+	FirstSeenAt     string                 `json:"first_seen_at"`
+	LastSeenAt      string                 `json:"last_seen_at"`
+	CreatedAt       string                 `json:"created_at"`
+	AcknowledgedAt  *string                `json:"acknowledged_at,omitempty"`
+	ResolvedAt      *string                `json:"resolved_at,omitempty"`
+}
+
+// CloudEvent CloudEvents 1.0结构化模式事件
+type CloudEvent struct {
+	SpecVersion     string      `json:"specversion"`
+	ID              string      `json:"id"`
+	Source          string      `json:"source"`
+	Type            string      `json:"type"`
+	Subject         string      `json:"subject,omitempty"`
+	Time            string      `json:"time"`
+	DataContentType string      `json:"datacontenttype"`
+	DataSchema      string      `json:"dataschema,omitempty"`
+	Data            interface{} `json:"data"`
+}
+
+// webhookEvent 待投递的事件
+type webhookEvent struct {
+	ID            string
+	Type          string
+	SchemaVersion string
+	ContentType   string
+	Body          []byte
+}
+
+// WebhookSendError Webhook投递错误
+type WebhookSendError struct {
+	Endpoint   string
+	StatusCode int           // HTTP状态码，网络错误时为0
+	Retryable  bool          // 稍后重试可能成功（限流、服务端错误、网络错误）
+	Disabled   bool          // 端点已被停用
+	RetryAfter time.Duration // 接收端要求的重试等待时间
+	Message    string
+	Err        error
+}
+
+// Error 实现error接口
+func (e *WebhookSendError) Error() string {
+	msg := e.Message
+	if msg == "" && e.Err != nil {
+		msg = e.Err.Error()
+	}
+	if e.StatusCode > 0 {
+		return fmt.Sprintf("webhook %s: status %d: %s", e.Endpoint, e.StatusCode, msg)
+	}
+	return fmt.Sprintf("webhook %s: %s", e.Endpoint, msg)
+}
+
+// Unwrap 返回底层错误
+func (e *WebhookSendError) Unwrap() error {
+	return e.Err
+}
+
+// IsRetryableWebhookError 判断投递错误是否值得稍后重试，未分类的错误按可重试处理
+func IsRetryableWebhookError(err error) bool {
+	var sendErr *WebhookSendError
+	if errors.As(err, &sendErr) {
+		return sendErr.Retryable
+	}
+	return true
 }
 
 // WebhookNotifier Webhook通知器
+// 请求使用端点密钥签名，失败时指数退避（带抖动）重试，持续失败的端点自动停用并产生告警
 type WebhookNotifier struct {
-	httpClient *http.Client
-	logger     *zap.Logger
-	maxRetries int
-	retryDelay time.Duration
+	httpClient    *http.Client
+	endpointRepo  repository.WebhookEndpointRepository
+	alertRepo     repository.AlertRepository
+	onDisabled    func(ctx context.Context, alert *domain.Alert) error
+	logger        *zap.Logger
+	maxRetries    int
+	retryDelay    time.Duration
+	maxRetryDelay time.Duration
 }
 
 // NewWebhookNotifier 创建Webhook通知器
-func NewWebhookNotifier(logger *zap.Logger) *WebhookNotifier {
+func NewWebhookNotifier(
+	endpointRepo repository.WebhookEndpointRepository,
+	alertRepo repository.AlertRepository,
+	logger *zap.Logger,
+) *WebhookNotifier {
 	return &WebhookNotifier{
 		httpClient: &http.Client{
 			Timeout: 10 * time.Second,
 		},
-		logger:     logger,
-		maxRetries: 3,
-		retryDelay: 2 * time.Second,
+		endpointRepo:  endpointRepo,
+		alertRepo:     alertRepo,
+		logger:        logger,
+		maxRetries:    3,
+		retryDelay:    2 * time.Second,
+		maxRetryDelay: time.Minute,
 	}
 }
 
+// OnEndpointDisabled 设置端点停用告警的处理函数（例如交给规则引擎通知管理员）
+func (wn *WebhookNotifier) OnEndpointDisabled(handler func(ctx context.Context, alert *domain.Alert) error) {
+	wn.onDisabled = handler
+}
+
 // SendAlert 发送告警到Webhook
-func (wn *WebhookNotifier) SendAlert(ctx context.Context, alert *domain.Alert, webhookURL string) error {
-	if webhookURL == "" {
+func (wn *WebhookNotifier) SendAlert(ctx context.Context, alert *domain.Alert, target *WebhookTarget) error {
+	if target == nil || target.URL == "" {
 		return fmt.Errorf("webhook URL is empty")
 	}
 
-	// 构建Webhook负载
-	payload := wn.buildPayload(alert)
+	eventID := target.eventID()
+	eventType := alertEventType(alert)
+	now := time.Now()
 
-	// 序列化为JSON
-	jsonData, err := json.Marshal(payload)
+	var data interface{}
+	if target.payloadVersion() == WebhookPayloadV2 {
+		v2 := wn.buildPayloadV2(alert)
+		data = WebhookEventV2{
+			SchemaVersion: "2",
+			EventID:       eventID,
+			EventType:     eventType,
+			OccurredAt:    now.Format(time.RFC3339),
+			Alert:         &v2,
+		}
+	} else {
+		data = wn.buildPayload(alert)
+	}
+
+	event, err := wn.buildEvent(target, eventID, eventType, alert.ID.String(), now, data)
 	if err != nil {
-		return fmt.Errorf("failed to marshal payload: %w", err)
+		return err
+	}
+
+	err = wn.deliver(ctx, target, event)
+	if err == nil {
+		wn.logger.Info("Webhook sent successfully",
+			zap.String("alert_id", alert.ID.String()),
+			zap.String("endpoint", target.displayName()),
+			zap.String("event_id", eventID),
+		)
+	}
+	return err
+}
+
+// SendBatch 批量发送告警到Webhook
+func (wn *WebhookNotifier) SendBatch(ctx context.Context, alerts []*domain.Alert, target *WebhookTarget) error {
+	if target == nil || target.URL == "" {
+		return fmt.Errorf("webhook URL is empty")
+	}
+
+	eventID := target.eventID()
+	eventType := "alert.group"
+	now := time.Now()
+
+	var data interface{}
+	if target.payloadVersion() == WebhookPayloadV2 {
+		items := make([]WebhookAlertV2, len(alerts))
+		for i, alert := range alerts {
+			items[i] = wn.buildPayloadV2(alert)
+		}
+		data = WebhookEventV2{
+			SchemaVersion: "2",
+			EventID:       eventID,
+			EventType:     eventType,
+			OccurredAt:    now.Format(time.RFC3339),
+			Alerts:        items,
+			Count:         len(items),
+		}
+	} else {
+		payloads := make([]WebhookPayload, len(alerts))
+		for i, alert := range alerts {
+			payloads[i] = wn.buildPayload(alert)
+		}
+		data = map[string]interface{}{
+			"alerts": payloads,
+			"count":  len(alerts),
+		}
+	}
+
+	event, err := wn.buildEvent(target, eventID, eventType, "", now, data)
+	if err != nil {
+		return err
+	}
+
+	if err := wn.deliver(ctx, target, event); err != nil {
+		return err
+	}
+
+	wn.logger.Info("Batch webhook sent successfully",
+		zap.Int("alert_count", len(alerts)),
+		zap.String("endpoint", target.displayName()),
+		zap.String("event_id", eventID),
+	)
+	return nil
+}
+
+// buildEvent 按端点格式序列化事件
+func (wn *WebhookNotifier) buildEvent(target *WebhookTarget, eventID, eventType, subject string, now time.Time, data interface{}) (*webhookEvent, error) {
+	version := target.payloadVersion()
+	event := &webhookEvent{
+		ID:            eventID,
+		Type:          eventType,
+		SchemaVersion: version,
+		ContentType:   "application/json",
+	}
+
+	var body interface{} = data
+	if target.Format == WebhookFormatCloudEvents {
+		event.ContentType = "application/cloudevents+json"
+		body = CloudEvent{
+			SpecVersion:     "1.0",
+			ID:              eventID,
+			Source:          cloudEventsSource,
+			Type:            cloudEventsTypePrefix + eventType,
+			Subject:         subject,
+			Time:            now.UTC().Format(time.RFC3339Nano),
+			DataContentType: "application/json",
+			DataSchema:      "urn:edgelink:webhook:" + version,
+			Data:            data,
+		}
+	}
+
+	jsonData, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal payload: %w", err)
+	}
+	event.Body = jsonData
+	return event, nil
+}
+
+// deliver 检查端点状态、带重试投递并记录结果
+func (wn *WebhookNotifier) deliver(ctx context.Context, target *WebhookTarget, event *webhookEvent) error {
+	endpoint := &domain.WebhookEndpoint{
+		EndpointKey: webhookEndpointKey(target.URL),
+		Name:        target.displayName(),
+		URL:         redactURL(target.URL),
+	}
+
+	if wn.endpointRepo != nil {
+		state, err := wn.endpointRepo.FindByKey(ctx, endpoint.EndpointKey)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			wn.logger.Warn("Failed to load webhook endpoint state",
+				zap.String("endpoint", endpoint.Name),
+				zap.Error(err),
+			)
+		}
+		if state != nil && state.IsDisabled() {
+			return &WebhookSendError{
+				Endpoint: endpoint.Name,
+				Disabled: true,
+				Message:  "endpoint is disabled after persistent failures",
+			}
+		}
+	}
+
+	err := wn.sendWithRetry(ctx, target, event)
+
+	// 调用方取消（服务停止）不计入端点健康状态
+	if ctx.Err() == nil {
+		wn.recordResult(ctx, target, endpoint, err)
+	}
+	return err
+}
+
+// sendWithRetry 发送请求，可重试的错误按指数退避（带抖动）重试
+func (wn *WebhookNotifier) sendWithRetry(ctx context.Context, target *WebhookTarget, event *webhookEvent) error {
+	maxRetries := wn.maxRetries
+	if target.MaxRetries >= 0 {
+		maxRetries = target.MaxRetries
 	}
 
-	// 带重试的发送
 	var lastErr error
-	for attempt := 0; attempt <= wn.maxRetries; attempt++ {
+	for attempt := 0; attempt <= maxRetries; attempt++ {
 		if attempt > 0 {
-			// 重试前等待
+			delay := wn.backoff(attempt, lastErr)
 			select {
 			case <-ctx.Done():
 				return ctx.Err()
-			case <-time.After(wn.retryDelay * time.Duration(attempt)):
+			case <-time.After(delay):
 			}
 
 			wn.logger.Info("Retrying webhook send",
 				zap.Int("attempt", attempt),
-				zap.String("alert_id", alert.ID.String()),
+				zap.String("endpoint", target.displayName()),
+				zap.String("event_id", event.ID),
+				zap.Duration("delay", delay),
 			)
 		}
 
-		// 发送HTTP POST请求
-		req, err := http.NewRequestWithContext(ctx, "POST", webhookURL, bytes.NewBuffer(jsonData))
-		if err != nil {
-			lastErr = fmt.Errorf("failed to create request: %w", err)
-			continue
+		lastErr = wn.post(ctx, target, event)
+		if lastErr == nil {
+			return nil
+		}
+		if !IsRetryableWebhookError(lastErr) {
+			return lastErr
 		}
+	}
+
+	return lastErr
+}
+
+// post 发送一次签名请求
+func (wn *WebhookNotifier) post(ctx context.Context, target *WebhookTarget, event *webhookEvent) error {
+	name := target.displayName()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target.URL, bytes.NewReader(event.Body))
+	if err != nil {
+		return &WebhookSendError{Endpoint: name, Message: "failed to create request", Err: err}
+	}
+
+	// 自定义请求头先设置，保留头由下方覆盖
+	for key, value := range target.Headers {
+		req.Header.Set(key, value)
+	}
+	req.Header.Set("Content-Type", event.ContentType)
+	req.Header.Set("User-Agent", "EdgeLink-AlertService/1.0")
+	req.Header.Set(WebhookHeaderEventID, event.ID)
+	req.Header.Set(WebhookHeaderEventType, event.Type)
+	req.Header.Set(WebhookHeaderSchemaVersion, event.SchemaVersion)
+
+	// 每次尝试重新签名，退避等待不会使时间戳超出接收端的重放窗口
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set(WebhookHeaderTimestamp, timestamp)
+	if target.Secret != "" {
+		req.Header.Set(WebhookHeaderSignature, SignWebhook(target.Secret, timestamp, event.Body))
+	}
+
+	resp, err := wn.httpClient.Do(req)
+	if err != nil {
+		return &WebhookSendError{Endpoint: name, Retryable: true, Err: err}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, webhookErrorBodySize))
+	sendErr := &WebhookSendError{
+		Endpoint:   name,
+		StatusCode: resp.StatusCode,
+		Message:    string(body),
+	}
+	switch {
+	case resp.StatusCode == http.StatusRequestTimeout,
+		resp.StatusCode == http.StatusTooEarly,
+		resp.StatusCode == http.StatusTooManyRequests,
+		resp.StatusCode >= 500:
+		sendErr.Retryable = true
+		sendErr.RetryAfter = parseRetryAfter(resp.Header.Get("Retry-After"))
+	}
+	return sendErr
+}
 
-		req.Header.Set("Content-Type", "application/json")
-		req.Header.Set("User-Agent", "EdgeLink-AlertService/1.0")
+// backoff 计算第attempt次重试前的等待时间
+// 指数增长并取上半区间的随机值（equal jitter），避免大量投递同时重试；接收端要求的Retry-After优先
+func (wn *WebhookNotifier) backoff(attempt int, lastErr error) time.Duration {
+	delay := wn.retryDelay << (attempt - 1)
+	if delay <= 0 || delay > wn.maxRetryDelay {
+		delay = wn.maxRetryDelay
+	}
+	half := delay / 2
+	delay = half + time.Duration(rand.Int63n(int64(half)+1))
 
-		resp, err := wn.httpClient.Do(req)
-		if err != nil {
-			lastErr = fmt.Errorf("failed to send request: %w", err)
-			continue
+	var sendErr *WebhookSendError
+	if errors.As(lastErr, &sendErr) && sendErr.RetryAfter > delay {
+		delay = sendErr.RetryAfter
+		if delay > wn.maxRetryDelay {
+			delay = wn.maxRetryDelay
 		}
+	}
+	return delay
+}
 
-		// 检查响应状态
-		if resp.StatusCode >= 200 && resp.StatusCode < 300 {
-			resp.Body.Close()
-			wn.logger.Info("Webhook sent successfully",
-				zap.String("alert_id", alert.ID.String()),
-				zap.String("url", webhookURL),
-				zap.Int("status_code", resp.StatusCode),
+// recordResult 更新端点健康状态，持续失败达到阈值时停用端点
+func (wn *WebhookNotifier) recordResult(ctx context.Context, target *WebhookTarget, endpoint *domain.WebhookEndpoint, sendErr error) {
+	if wn.endpointRepo == nil {
+		return
+	}
+
+	now := time.Now()
+	if sendErr == nil {
+		if err := wn.endpointRepo.RecordSuccess(ctx, endpoint, now); err != nil {
+			wn.logger.Warn("Failed to record webhook success",
+				zap.String("endpoint", endpoint.Name),
+				zap.Error(err),
 			)
-			return nil
 		}
+		return
+	}
 
-		resp.Body.Close()
-		lastErr = fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	statusCode := 0
+	var webhookErr *WebhookSendError
+	if errors.As(sendErr, &webhookErr) {
+		statusCode = webhookErr.StatusCode
 	}
 
-	return fmt.Errorf("webhook send failed after %d attempts: %w", wn.maxRetries+1, lastErr)
+	state, err := wn.endpointRepo.RecordFailure(ctx, endpoint, sendErr.Error(), statusCode, now)
+	if err != nil {
+		wn.logger.Warn("Failed to record webhook failure",
+			zap.String("endpoint", endpoint.Name),
+			zap.Error(err),
+		)
+		return
+	}
+
+	if !target.AutoDisable {
+		return
+	}
+
+	threshold := target.DisableAfterFailures
+	if threshold <= 0 {
+		threshold = defaultDisableAfterFailures
+	}
+	window := target.DisableAfter
+	if window <= 0 {
+		window = defaultDisableAfter
+	}
+
+	if state.ConsecutiveFailures < threshold || state.FailingSince == nil || now.Sub(*state.FailingSince) < window {
+		return
+	}
+
+	wn.disableEndpoint(ctx, state)
 }
 
-// buildPayload 构建Webhook负载
+// disableEndpoint 停用端点并产生告警
+func (wn *WebhookNotifier) disableEndpoint(ctx context.Context, state *domain.WebhookEndpoint) {
+	now := time.Now()
+	disabled, err := wn.endpointRepo.Disable(ctx, state.ID, now)
+	if err != nil {
+		wn.logger.Error("Failed to disable webhook endpoint",
+			zap.String("endpoint", state.Name),
+			zap.Error(err),
+		)
+		return
+	}
+	if !disabled {
+		// 其他实例已停用
+		return
+	}
+
+	wn.logger.Error("Webhook endpoint disabled after persistent failures",
+		zap.String("endpoint", state.Name),
+		zap.String("endpoint_id", state.ID.String()),
+		zap.Int("consecutive_failures", state.ConsecutiveFailures),
+	)
+
+	if wn.alertRepo == nil {
+		return
+	}
+
+	lastError := ""
+	if state.LastError != nil {
+		lastError = *state.LastError
+	}
+	metadata := domain.JSONB{
+		"endpoint_id":          state.ID.String(),
+		"endpoint_name":        state.Name,
+		"endpoint_url":         state.URL,
+		"consecutive_failures": state.ConsecutiveFailures,
+		"last_error":           lastError,
+	}
+	if state.FailingSince != nil {
+		metadata["failing_since"] = state.FailingSince.Format(time.RFC3339)
+	}
+
+	alert := &domain.Alert{
+		ID:       uuid.New(),
+		Severity: domain.SeverityHigh,
+		Type:     domain.AlertTypeWebhookDisabled,
+		Title:    fmt.Sprintf("Webhook endpoint disabled: %s", state.Name),
+		Message: fmt.Sprintf("Webhook endpoint %s (%s) failed %d consecutive deliveries and has been disabled. Last error: %s",
+			state.Name, state.URL, state.ConsecutiveFailures, lastError),
+		Status:          domain.AlertStatusActive,
+		Metadata:        metadata,
+		OccurrenceCount: 1,
+		FirstSeenAt:     now,
+		LastSeenAt:      now,
+		CreatedAt:       now,
+	}
+
+	if err := wn.alertRepo.Create(ctx, alert); err != nil {
+		wn.logger.Error("Failed to create webhook disabled alert",
+			zap.String("endpoint", state.Name),
+			zap.Error(err),
+		)
+		return
+	}
+
+	if err := wn.endpointRepo.SetDisabledAlert(ctx, state.ID, alert.ID); err != nil {
+		wn.logger.Warn("Failed to link alert to disabled webhook endpoint",
+			zap.String("endpoint_id", state.ID.String()),
+			zap.Error(err),
+		)
+	}
+
+	if wn.onDisabled != nil {
+		if err := wn.onDisabled(ctx, alert); err != nil {
+			wn.logger.Error("Failed to schedule webhook disabled alert",
+				zap.String("alert_id", alert.ID.String()),
+				zap.Error(err),
+			)
+		}
+	}
+}
+
+// buildPayload 构建Webhook负载（v1）
 func (wn *WebhookNotifier) buildPayload(alert *domain.Alert) WebhookPayload {
 	payload := WebhookPayload{
 		AlertID:   alert.ID.String(),
@@ -140,50 +666,76 @@ func (wn *WebhookNotifier) buildPayload(alert *domain.Alert) WebhookPayload {
 	return payload
 }
 
-// SendBatch 批量发送告警到Webhook
-func (wn *WebhookNotifier) SendBatch(ctx context.Context, alerts []*domain.Alert, webhookURL string) error {
-	if webhookURL == "" {
-		return fmt.Errorf("webhook URL is empty")
+// buildPayloadV2 构建v2负载中的告警
+func (wn *WebhookNotifier) buildPayloadV2(alert *domain.Alert) WebhookAlertV2 {
+	item := WebhookAlertV2{
+		ID:              alert.ID.String(),
+		Type:            string(alert.Type),
+		Severity:        string(alert.Severity),
+		Status:          string(alert.Status),
+		Title:           alert.Title,
+		Message:         alert.Message,
+		OccurrenceCount: alert.OccurrenceCount,
+		FirstSeenAt:     alert.FirstSeenAt.Format(time.RFC3339),
+		LastSeenAt:      alert.LastSeenAt.Format(time.RFC3339),
+		CreatedAt:       alert.CreatedAt.Format(time.RFC3339),
 	}
 
-	// 构建批量负载
-	payloads := make([]WebhookPayload, len(alerts))
-	for i, alert := range alerts {
-		payloads[i] = wn.buildPayload(alert)
+	if alert.DeviceID != nil {
+		deviceID := alert.DeviceID.String()
+		item.DeviceID = &deviceID
 	}
-
-	// 序列化为JSON
-	jsonData, err := json.Marshal(map[string]interface{}{
-		"alerts": payloads,
-		"count":  len(alerts),
-	})
-	if err != nil {
-		return fmt.Errorf("failed to marshal batch payload: %w", err)
+	if len(alert.Metadata) > 0 {
+		item.Metadata = map[string]interface{}(alert.Metadata)
 	}
-
-	// 发送HTTP POST请求
-	req, err := http.NewRequestWithContext(ctx, "POST", webhookURL, bytes.NewBuffer(jsonData))
-	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+	if alert.AcknowledgedAt != nil {
+		at := alert.AcknowledgedAt.Format(time.RFC3339)
+		item.AcknowledgedAt = &at
+	}
+	if alert.ResolvedAt != nil {
+		at := alert.ResolvedAt.Format(time.RFC3339)
+		item.ResolvedAt = &at
 	}
 
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("User-Agent", "EdgeLink-AlertService/1.0")
+	return item
+}
 
-	resp, err := wn.httpClient.Do(req)
-	if err != nil {
-		return fmt.Errorf("failed to send request: %w", err)
-	}
-	defer resp.Body.Close()
+// SignWebhook 计算Webhook签名：v1=HEX(HMAC-SHA256(secret, "{timestamp}.{body}"))
+// 接收端应校验时间戳在允许窗口内（建议5分钟）以防重放
+func SignWebhook(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "v1=" + hex.EncodeToString(mac.Sum(nil))
+}
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+// alertEventType 按告警状态确定事件类型
+func alertEventType(alert *domain.Alert) string {
+	switch alert.Status {
+	case domain.AlertStatusAcknowledged:
+		return "alert.acknowledged"
+	case domain.AlertStatusResolved:
+		return "alert.resolved"
+	default:
+		return "alert.triggered"
 	}
+}
 
-	wn.logger.Info("Batch webhook sent successfully",
-		zap.Int("alert_count", len(alerts)),
-		zap.String("url", webhookURL),
-	)
+// webhookEndpointKey 端点键（完整URL的SHA-256，URL中的令牌不落库）
+func webhookEndpointKey(rawURL string) string {
+	sum := sha256.Sum256([]byte(rawURL))
+	return hex.EncodeToString(sum[:])
+}
 
-	return nil
+// redactURL 去除URL中的凭据与查询参数
+func redactURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	u.User = nil
+	u.RawQuery = ""
+	u.Fragment = ""
+	return u.String()
 }
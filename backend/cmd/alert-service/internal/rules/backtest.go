@@ -0,0 +1,561 @@
+package rules
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/edgelink/backend/internal/domain"
+	"github.com/edgelink/backend/internal/repository"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	// backtestPageSize 每次从数据库读取的告警数量
+	backtestPageSize = 500
+	// defaultBacktestMaxAlerts 默认最多回放的告警数量
+	defaultBacktestMaxAlerts = 10000
+	// maxEscalationRepeats 模拟升级重复通知的安全上限
+	maxEscalationRepeats = 1000
+)
+
+// 回测中规则匹配的结果
+const (
+	BacktestOutcomeFired       = "fired"
+	BacktestOutcomeRateLimited = "rate_limited"
+	BacktestOutcomeSilenced    = "silenced"
+	BacktestOutcomeGrouped     = "grouped" // 并入已有分组的待发通知，不单独发送
+)
+
+// BacktestOptions 回测参数
+type BacktestOptions struct {
+	Since          time.Time
+	Until          time.Time
+	OrganizationID *uuid.UUID // 为空时回放所有组织的告警
+	MaxAlerts      int
+}
+
+// BacktestReport 回测报告
+type BacktestReport struct {
+	Since                  time.Time             `json:"since"`
+	Until                  time.Time             `json:"until"`
+	RuleCount              int                   `json:"rule_count"`
+	AlertsEvaluated        int                   `json:"alerts_evaluated"`
+	AlertsMatched          int                   `json:"alerts_matched"`
+	AlertsSilenced         int                   `json:"alerts_silenced"`
+	NotificationsFired     int                   `json:"notifications_fired"`
+	NotificationsThrottled int                   `json:"notifications_throttled"`
+	AlertsGrouped          int                   `json:"alerts_grouped"`
+	EscalationsFired       int                   `json:"escalations_fired"`
+	Truncated              bool                  `json:"truncated"`
+	Rules                  []RuleBacktestSummary `json:"rules"`
+	Alerts                 []AlertBacktestResult `json:"alerts"`
+}
+
+// RuleBacktestSummary 单个规则的回测汇总
+type RuleBacktestSummary struct {
+	RuleID           string             `json:"rule_id"`
+	Name             string             `json:"name"`
+	Matched          int                `json:"matched"`
+	Fired            int                `json:"fired"`
+	RateLimited      int                `json:"rate_limited"`
+	Grouped          int                `json:"grouped"`
+	Silenced         int                `json:"silenced"`
+	Actions          map[ActionType]int `json:"actions"`
+	EscalationsFired int                `json:"escalations_fired"`
+}
+
+// AlertBacktestResult 单个告警的回测结果（仅包含命中规则或被静默的告警）
+type AlertBacktestResult struct {
+	AlertID    uuid.UUID          `json:"alert_id"`
+	DeviceID   *uuid.UUID         `json:"device_id,omitempty"`
+	Type       domain.AlertType   `json:"type"`
+	Severity   domain.Severity    `json:"severity"`
+	Title      string             `json:"title"`
+	OccurredAt time.Time          `json:"occurred_at"`
+	SilencedBy *uuid.UUID         `json:"silenced_by,omitempty"`
+	Matches    []RuleMatchOutcome `json:"matches"`
+}
+
+// RuleMatchOutcome 告警命中规则后的模拟执行结果
+type RuleMatchOutcome struct {
+	RuleID     string             `json:"rule_id"`
+	Outcome    string             `json:"outcome"`
+	Actions    []PlannedAction    `json:"actions,omitempty"`
+	Escalation *EscalationOutcome `json:"escalation,omitempty"`
+}
+
+// PlannedAction 将会执行的通知动作
+type PlannedAction struct {
+	Type   ActionType `json:"type"`
+	Target string     `json:"target,omitempty"`
+}
+
+// EscalationOutcome 告警升级的模拟结果
+type EscalationOutcome struct {
+	Triggered     bool       `json:"triggered"`
+	FirstAt       *time.Time `json:"first_at,omitempty"`
+	Notifications int        `json:"notifications"`
+	LevelsReached int        `json:"levels_reached,omitempty"` // 升级策略到达的最高级别（从1开始）
+	StoppedBy     string     `json:"stopped_by,omitempty"`     // acknowledged / resolved / max_repeat / exhausted / policy_not_found / window_end
+}
+
+// Backtester 规则回测器（回放历史告警，不发送任何通知）
+type Backtester struct {
+	parser      *Parser
+	matcher     *Matcher
+	alertRepo   repository.AlertRepository
+	deviceRepo  repository.DeviceRepository
+	silenceRepo repository.SilenceRepository
+	policyRepo  repository.EscalationPolicyRepository
+	logger      *zap.Logger
+}
+
+// NewBacktester 创建规则回测器
+func NewBacktester(
+	alertRepo repository.AlertRepository,
+	deviceRepo repository.DeviceRepository,
+	silenceRepo repository.SilenceRepository,
+	policyRepo repository.EscalationPolicyRepository,
+	logger *zap.Logger,
+) *Backtester {
+	return &Backtester{
+		parser:      NewParser(),
+		matcher:     NewMatcher(),
+		alertRepo:   alertRepo,
+		deviceRepo:  deviceRepo,
+		silenceRepo: silenceRepo,
+		policyRepo:  policyRepo,
+		logger:      logger,
+	}
+}
+
+// RunYAML 解析YAML候选规则集并回测
+func (b *Backtester) RunYAML(ctx context.Context, data []byte, opts BacktestOptions) (*BacktestReport, error) {
+	ruleSet, err := b.parser.ParseBytes(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRule, err)
+	}
+	return b.Run(ctx, ruleSet, opts)
+}
+
+// Run 使用候选规则集回放时间窗口内的历史告警
+func (b *Backtester) Run(ctx context.Context, ruleSet *RuleSet, opts BacktestOptions) (*BacktestReport, error) {
+	if !opts.Until.After(opts.Since) {
+		return nil, fmt.Errorf("backtest window is empty")
+	}
+	if opts.MaxAlerts <= 0 {
+		opts.MaxAlerts = defaultBacktestMaxAlerts
+	}
+
+	alerts, truncated, err := b.loadAlerts(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var silences []*domain.Silence
+	if b.silenceRepo != nil {
+		silences, err = b.silenceRepo.FindOverlapping(ctx, opts.Since, opts.Until)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load silences: %w", err)
+		}
+	}
+
+	report := &BacktestReport{
+		Since:     opts.Since,
+		Until:     opts.Until,
+		RuleCount: len(ruleSet.Rules),
+		Truncated: truncated,
+		Rules:     make([]RuleBacktestSummary, 0, len(ruleSet.Rules)),
+		Alerts:    make([]AlertBacktestResult, 0),
+	}
+
+	summaries := make(map[string]*RuleBacktestSummary, len(ruleSet.Rules))
+	for _, rule := range ruleSet.Rules {
+		report.Rules = append(report.Rules, RuleBacktestSummary{
+			RuleID:  rule.ID,
+			Name:    rule.Name,
+			Actions: make(map[ActionType]int),
+		})
+	}
+	for i := range report.Rules {
+		summaries[report.Rules[i].RuleID] = &report.Rules[i]
+	}
+
+	devices := make(map[uuid.UUID]*domain.Device)
+	rateLimiters := make(map[string]*RateLimitTracker)
+	policies := make(map[string]*domain.EscalationPolicy)
+	groupFlushes := make(map[string]time.Time)
+
+	for _, alert := range alerts {
+		device := b.lookupDevice(ctx, devices, alert.DeviceID)
+
+		// 按组织过滤
+		if opts.OrganizationID != nil {
+			if orgID, ok := alertOrganization(alert, device); !ok || orgID != *opts.OrganizationID {
+				continue
+			}
+		}
+		report.AlertsEvaluated++
+
+		occurredAt := alert.CreatedAt
+		matched := b.matcher.MatchMultiple(ruleSet.Rules, &MatchContext{
+			Alert:     alert,
+			Device:    device,
+			Timestamp: occurredAt,
+			Metadata:  make(map[string]interface{}),
+		})
+
+		silence := findSilenceAt(silences, alert, device, occurredAt)
+		if len(matched) == 0 && silence == nil {
+			continue
+		}
+
+		result := AlertBacktestResult{
+			AlertID:    alert.ID,
+			DeviceID:   alert.DeviceID,
+			Type:       alert.Type,
+			Severity:   alert.Severity,
+			Title:      alert.Title,
+			OccurredAt: occurredAt,
+			Matches:    make([]RuleMatchOutcome, 0, len(matched)),
+		}
+		if silence != nil {
+			result.SilencedBy = &silence.ID
+			report.AlertsSilenced++
+		}
+		if len(matched) > 0 {
+			report.AlertsMatched++
+		}
+
+		for i := range matched {
+			rule := &matched[i]
+			summary := summaries[rule.ID]
+			summary.Matched++
+
+			outcome := RuleMatchOutcome{RuleID: rule.ID}
+			switch {
+			case silence != nil:
+				// 引擎在静默命中时不执行任何动作，也不消耗限流配额
+				outcome.Outcome = BacktestOutcomeSilenced
+				summary.Silenced++
+
+			case rule.Grouping != nil && joinsGroupAt(groupFlushes, rule, alert, device, occurredAt):
+				// 分组窗口内的告警合并到同一条摘要通知中
+				outcome.Outcome = BacktestOutcomeGrouped
+				summary.Grouped++
+				report.AlertsGrouped++
+
+			case rule.RateLimit != nil && !allowAt(rateLimiters, rule, alert, occurredAt):
+				outcome.Outcome = BacktestOutcomeRateLimited
+				summary.RateLimited++
+				report.NotificationsThrottled += countEnabledActions(rule.Actions)
+
+			default:
+				outcome.Outcome = BacktestOutcomeFired
+				summary.Fired++
+				outcome.Actions = plannedActions(rule.Actions)
+				for _, action := range outcome.Actions {
+					summary.Actions[action.Type]++
+				}
+				report.NotificationsFired += len(outcome.Actions)
+			}
+
+			// 分组不影响升级，每条告警各自登记
+			if (outcome.Outcome == BacktestOutcomeFired || outcome.Outcome == BacktestOutcomeGrouped) &&
+				rule.Escalation != nil && rule.Escalation.Enabled {
+				policy, err := b.escalationPolicy(ctx, rule.Escalation, policies)
+				if err != nil {
+					return nil, err
+				}
+				outcome.Escalation = simulateEscalation(rule.Escalation, policy, alert, occurredAt, opts.Until)
+				if outcome.Escalation.Triggered {
+					summary.EscalationsFired += outcome.Escalation.Notifications
+					report.EscalationsFired += outcome.Escalation.Notifications
+				}
+			}
+
+			result.Matches = append(result.Matches, outcome)
+		}
+
+		report.Alerts = append(report.Alerts, result)
+	}
+
+	return report, nil
+}
+
+// loadAlerts 分页读取时间窗口内的告警，并按发生时间正序排列
+func (b *Backtester) loadAlerts(ctx context.Context, opts BacktestOptions) ([]*domain.Alert, bool, error) {
+	since, until := opts.Since, opts.Until
+	filters := &repository.AlertFilters{
+		StartTime: &since,
+		EndTime:   &until,
+		Limit:     backtestPageSize,
+	}
+
+	alerts := make([]*domain.Alert, 0)
+	truncated := false
+	for {
+		page, total, err := b.alertRepo.FindByFilters(ctx, filters)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to load alerts: %w", err)
+		}
+		alerts = append(alerts, page...)
+
+		if len(alerts) >= opts.MaxAlerts {
+			truncated = total > int64(opts.MaxAlerts)
+			alerts = alerts[:opts.MaxAlerts]
+			break
+		}
+		if len(page) < backtestPageSize {
+			break
+		}
+		filters.Offset += backtestPageSize
+	}
+
+	sort.SliceStable(alerts, func(i, j int) bool {
+		return alerts[i].CreatedAt.Before(alerts[j].CreatedAt)
+	})
+
+	if truncated {
+		b.logger.Warn("Backtest alert window truncated",
+			zap.Int("max_alerts", opts.MaxAlerts),
+		)
+	}
+	return alerts, truncated, nil
+}
+
+// lookupDevice 查找告警对应的设备（带缓存，包含虚拟网络信息）
+func (b *Backtester) lookupDevice(ctx context.Context, cache map[uuid.UUID]*domain.Device, deviceID *uuid.UUID) *domain.Device {
+	if deviceID == nil {
+		return nil
+	}
+	if device, ok := cache[*deviceID]; ok {
+		return device
+	}
+
+	device, err := b.deviceRepo.FindByID(ctx, *deviceID)
+	if err != nil {
+		device = nil
+	}
+	cache[*deviceID] = device
+	return device
+}
+
+// findSilenceAt 查找在指定时间命中告警的静默
+func findSilenceAt(silences []*domain.Silence, alert *domain.Alert, device *domain.Device, at time.Time) *domain.Silence {
+	orgID, ok := alertOrganization(alert, device)
+	if !ok {
+		return nil
+	}
+	for _, silence := range silences {
+		if silence.OrganizationID != orgID {
+			continue
+		}
+		if silence.State(at) == domain.SilenceStateActive && silence.Matches(alert, device) {
+			return silence
+		}
+	}
+	return nil
+}
+
+// allowAt 使用回放时间检查速率限制（与引擎使用相同的限流键）
+func allowAt(trackers map[string]*RateLimitTracker, rule *Rule, alert *domain.Alert, at time.Time) bool {
+	key := rateLimitKey(rule, alert)
+	tracker, exists := trackers[key]
+	if !exists {
+		tracker = &RateLimitTracker{
+			maxCount: rule.RateLimit.MaxNotifications,
+			window:   rule.RateLimit.Window,
+			tokens:   make([]time.Time, 0),
+		}
+		trackers[key] = tracker
+	}
+	return tracker.AllowAt(at)
+}
+
+// joinsGroupAt 判断告警是否并入分组中尚未发送的通知
+// 简化模型：不考虑成员恢复，分组的下一次通知时间为首条告警后group_wait，之后每次变化后group_interval
+func joinsGroupAt(flushes map[string]time.Time, rule *Rule, alert *domain.Alert, device *domain.Device, at time.Time) bool {
+	_, groupKey := groupLabels(rule.Grouping, alert, device)
+	key := rule.ID + "/" + groupKey
+
+	next, exists := flushes[key]
+	if exists && at.Before(next) {
+		return true
+	}
+
+	interval := rule.Grouping.GroupInterval
+	switch {
+	case !exists:
+		flushes[key] = at.Add(rule.Grouping.GroupWait)
+	case interval > 0:
+		// 通知在告警之后的第一个group_interval边界发出
+		flushes[key] = next.Add(interval * (at.Sub(next)/interval + 1))
+	default:
+		flushes[key] = at
+	}
+	return false
+}
+
+// escalationPolicy 加载规则引用的升级策略（按ID缓存，策略不存在时返回nil）
+func (b *Backtester) escalationPolicy(ctx context.Context, escalation *Escalation, cache map[string]*domain.EscalationPolicy) (*domain.EscalationPolicy, error) {
+	if escalation.PolicyID == "" || b.policyRepo == nil {
+		return nil, nil
+	}
+	if policy, ok := cache[escalation.PolicyID]; ok {
+		return policy, nil
+	}
+
+	var policy *domain.EscalationPolicy
+	if policyID, err := uuid.Parse(escalation.PolicyID); err == nil {
+		policy, err = b.policyRepo.FindByID(ctx, policyID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("failed to load escalation policy: %w", err)
+		}
+	}
+	cache[escalation.PolicyID] = policy
+	return policy, nil
+}
+
+// simulateEscalation 根据告警的确认/解决时间推算升级通知
+func simulateEscalation(escalation *Escalation, policy *domain.EscalationPolicy, alert *domain.Alert, triggeredAt, until time.Time) *EscalationOutcome {
+	stop, stoppedBy := until, "window_end"
+	if alert.AcknowledgedAt != nil && alert.AcknowledgedAt.Before(stop) {
+		stop, stoppedBy = *alert.AcknowledgedAt, "acknowledged"
+	}
+	if alert.ResolvedAt != nil && alert.ResolvedAt.Before(stop) {
+		stop, stoppedBy = *alert.ResolvedAt, "resolved"
+	}
+
+	outcome := &EscalationOutcome{StoppedBy: stoppedBy}
+
+	firstAt := triggeredAt.Add(escalation.WaitDuration)
+	if !firstAt.Before(stop) {
+		return outcome
+	}
+
+	if escalation.PolicyID != "" {
+		if policy == nil || len(policy.Levels) == 0 {
+			outcome.StoppedBy = escalationStopPolicyMissing
+			return outcome
+		}
+		return simulatePolicyEscalation(outcome, policy, firstAt, stop)
+	}
+
+	outcome.Triggered = true
+	outcome.FirstAt = &firstAt
+	outcome.Notifications = 1
+
+	if escalation.RepeatInterval <= 0 {
+		return outcome
+	}
+
+	for next := firstAt.Add(escalation.RepeatInterval); next.Before(stop); next = next.Add(escalation.RepeatInterval) {
+		if escalation.MaxRepeat > 0 && outcome.Notifications >= escalation.MaxRepeat {
+			outcome.StoppedBy = "max_repeat"
+			break
+		}
+		if outcome.Notifications >= maxEscalationRepeats {
+			break
+		}
+		outcome.Notifications++
+	}
+	return outcome
+}
+
+// simulatePolicyEscalation 按升级策略的级别延迟推算通知次数（与引擎推进规则一致）
+func simulatePolicyEscalation(outcome *EscalationOutcome, policy *domain.EscalationPolicy, firstAt, stop time.Time) *EscalationOutcome {
+	outcome.Triggered = true
+	outcome.FirstAt = &firstAt
+
+	level, cycle := 0, 0
+	for at := firstAt; at.Before(stop); {
+		outcome.Notifications++
+		if level+1 > outcome.LevelsReached {
+			outcome.LevelsReached = level + 1
+		}
+		if outcome.Notifications >= maxEscalationRepeats {
+			return outcome
+		}
+
+		at = at.Add(time.Duration(policy.Levels[level].DelayMinutes) * time.Minute)
+		level++
+		if level >= len(policy.Levels) {
+			level = 0
+			cycle++
+			if cycle > policy.RepeatCount {
+				outcome.StoppedBy = escalationStopExhausted
+				return outcome
+			}
+		}
+	}
+	return outcome
+}
+
+// plannedActions 列出规则中将会执行的动作
+func plannedActions(actions []Action) []PlannedAction {
+	planned := make([]PlannedAction, 0, len(actions))
+	for i := range actions {
+		if !actions[i].Enabled {
+			continue
+		}
+		planned = append(planned, PlannedAction{
+			Type:   actions[i].Type,
+			Target: DescribeActionTarget(&actions[i]),
+		})
+	}
+	return planned
+}
+
+// countEnabledActions 统计启用的动作数量
+func countEnabledActions(actions []Action) int {
+	count := 0
+	for i := range actions {
+		if actions[i].Enabled {
+			count++
+		}
+	}
+	return count
+}
+
+// DescribeActionTarget 描述动作的通知目标（URL仅保留主机名，避免泄露令牌）
+func DescribeActionTarget(action *Action) string {
+	switch action.Type {
+	case ActionTypeEmail:
+		if recipients, ok := action.Config["recipients"].([]interface{}); ok {
+			targets := make([]string, 0, len(recipients))
+			for _, r := range recipients {
+				targets = append(targets, fmt.Sprint(r))
+			}
+			return strings.Join(targets, ",")
+		}
+	case ActionTypeWebhook, ActionTypeCustom:
+		return urlHost(action.Config["url"])
+	case ActionTypeSlack, ActionTypeDingTalk, ActionTypeWeChat:
+		return urlHost(action.Config["webhook_url"])
+	case ActionTypeIntegration:
+		name, _ := action.Config["name"].(string)
+		return name
+	}
+	return ""
+}
+
+// urlHost 提取URL的主机名
+func urlHost(value interface{}) string {
+	raw, ok := value.(string)
+	if !ok {
+		return ""
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return ""
+	}
+	return u.Host
+}
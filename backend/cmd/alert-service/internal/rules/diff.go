@@ -0,0 +1,75 @@
+package rules
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// FieldChange 规则定义中单个字段的变化
+type FieldChange struct {
+	Path string      `json:"path"`           // 字段路径，如 actions[0].config.url
+	From interface{} `json:"from,omitempty"` // 旧值（新增字段时为空）
+	To   interface{} `json:"to,omitempty"`   // 新值（删除字段时为空）
+}
+
+// DiffDefinitions 比较两个规则定义，返回按路径排序的字段变化列表
+func DiffDefinitions(from, to map[string]interface{}) []FieldChange {
+	changes := make([]FieldChange, 0)
+	diffValues("", from, to, &changes)
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Path < changes[j].Path
+	})
+	return changes
+}
+
+// diffValues 递归比较两个JSON值
+func diffValues(path string, from, to interface{}, changes *[]FieldChange) {
+	fromMap, fromIsMap := from.(map[string]interface{})
+	toMap, toIsMap := to.(map[string]interface{})
+	if fromIsMap && toIsMap {
+		keys := make(map[string]struct{}, len(fromMap)+len(toMap))
+		for k := range fromMap {
+			keys[k] = struct{}{}
+		}
+		for k := range toMap {
+			keys[k] = struct{}{}
+		}
+		for k := range keys {
+			diffValues(joinPath(path, k), fromMap[k], toMap[k], changes)
+		}
+		return
+	}
+
+	fromSlice, fromIsSlice := from.([]interface{})
+	toSlice, toIsSlice := to.([]interface{})
+	if fromIsSlice && toIsSlice {
+		n := len(fromSlice)
+		if len(toSlice) > n {
+			n = len(toSlice)
+		}
+		for i := 0; i < n; i++ {
+			var a, b interface{}
+			if i < len(fromSlice) {
+				a = fromSlice[i]
+			}
+			if i < len(toSlice) {
+				b = toSlice[i]
+			}
+			diffValues(fmt.Sprintf("%s[%d]", path, i), a, b, changes)
+		}
+		return
+	}
+
+	if !reflect.DeepEqual(from, to) {
+		*changes = append(*changes, FieldChange{Path: path, From: from, To: to})
+	}
+}
+
+// joinPath 拼接字段路径
+func joinPath(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
@@ -6,24 +6,39 @@ import (
 	"sync"
 	"time"
 
-	"github.com/edgelink/backend/cmd/alert-service/internal/notifier"
 	"github.com/edgelink/backend/internal/domain"
 	"github.com/edgelink/backend/internal/repository"
+	"github.com/edgelink/backend/internal/tracing"
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
 	"go.uber.org/zap"
 )
 
+// activeSilencesTTL 生效静默的缓存时间，避免每条告警都查询数据库
+// 新建或提前过期的静默最多延迟这么久生效
+const activeSilencesTTL = 10 * time.Second
+
 // Engine 规则引擎
 type Engine struct {
 	rules          []Rule
+	orgRules       map[uuid.UUID][]Rule // 数据库中存储的组织级规则
+	inhibitRules   []InhibitRule        // 规则文件中的抑制规则
 	rulesMutex     sync.RWMutex
 	parser         *Parser
 	matcher        *Matcher
 	executor       *Executor
+	dispatcher     ActionDispatcher
 	rateLimiters   map[string]*RateLimitTracker
-	escalations    map[uuid.UUID]*EscalationState
-	escalationLock sync.RWMutex
+	rateLimitMutex sync.Mutex // 保护rateLimiters：告警处理、分组刷新和抑制释放并发访问
+	escalator      *Escalator
 	deviceRepo     repository.DeviceRepository
+	alertRepo      repository.AlertRepository
+	silenceRepo    repository.SilenceRepository
+	silenceMutex   sync.Mutex
+	silences       []*domain.Silence // 最近一次加载的生效静默
+	silencesLoaded time.Time
+	groupRepo      repository.AlertGroupRepository
+	topology       *TopologyResolver
 	logger         *zap.Logger
 }
 
@@ -36,19 +51,30 @@ type EngineConfig struct {
 
 // NewEngine 创建规则引擎
 func NewEngine(
-	emailNotifier *notifier.EmailNotifier,
-	webhookNotifier *notifier.WebhookNotifier,
+	executor *Executor,
 	deviceRepo repository.DeviceRepository,
+	alertRepo repository.AlertRepository,
+	silenceRepo repository.SilenceRepository,
+	groupRepo repository.AlertGroupRepository,
+	topology *TopologyResolver,
+	escalator *Escalator,
+	dispatcher ActionDispatcher,
 	logger *zap.Logger,
 ) *Engine {
 	return &Engine{
 		rules:        make([]Rule, 0),
+		orgRules:     make(map[uuid.UUID][]Rule),
 		parser:       NewParser(),
 		matcher:      NewMatcher(),
-		executor:     NewExecutor(emailNotifier, webhookNotifier, logger),
+		executor:     executor,
+		dispatcher:   dispatcher,
 		rateLimiters: make(map[string]*RateLimitTracker),
-		escalations:  make(map[uuid.UUID]*EscalationState),
+		escalator:    escalator,
 		deviceRepo:   deviceRepo,
+		alertRepo:    alertRepo,
+		silenceRepo:  silenceRepo,
+		groupRepo:    groupRepo,
+		topology:     topology,
 		logger:       logger,
 	}
 }
@@ -62,10 +88,12 @@ func (e *Engine) LoadRules(filepath string) error {
 
 	e.rulesMutex.Lock()
 	e.rules = ruleSet.Rules
+	e.inhibitRules = ruleSet.InhibitRules
 	e.rulesMutex.Unlock()
 
 	e.logger.Info("Rules loaded successfully",
 		zap.Int("count", len(ruleSet.Rules)),
+		zap.Int("inhibit_rules", len(ruleSet.InhibitRules)),
 		zap.String("version", ruleSet.Version),
 	)
 
@@ -81,10 +109,12 @@ func (e *Engine) LoadRulesFromBytes(data []byte) error {
 
 	e.rulesMutex.Lock()
 	e.rules = ruleSet.Rules
+	e.inhibitRules = ruleSet.InhibitRules
 	e.rulesMutex.Unlock()
 
 	e.logger.Info("Rules loaded from bytes",
 		zap.Int("count", len(ruleSet.Rules)),
+		zap.Int("inhibit_rules", len(ruleSet.InhibitRules)),
 	)
 
 	return nil
@@ -120,6 +150,13 @@ func (e *Engine) StartAutoReload(ctx context.Context, config EngineConfig) {
 
 // Process 处理告警
 func (e *Engine) Process(ctx context.Context, alert *domain.Alert) error {
+	ctx, span := tracing.Start(ctx, "rules.process",
+		attribute.String("alert.id", alert.ID.String()),
+		attribute.String("alert.type", string(alert.Type)),
+		attribute.String("alert.severity", string(alert.Severity)),
+	)
+	defer span.End()
+
 	// 获取设备信息（如果有）
 	var device *domain.Device
 	if alert.DeviceID != nil {
@@ -129,6 +166,41 @@ func (e *Engine) Process(ctx context.Context, alert *domain.Alert) error {
 		}
 	}
 
+	// 静默检查先于任何动作执行
+	if silence := e.findSilence(ctx, alert, device); silence != nil {
+		e.logger.Info("Alert silenced, skipping notification",
+			zap.String("alert_id", alert.ID.String()),
+			zap.String("silence_id", silence.ID.String()),
+		)
+		e.markSilenced(ctx, alert, &silence.ID)
+		span.SetAttributes(attribute.String("alert.silenced_by", silence.ID.String()))
+		return nil
+	}
+	if alert.SilencedBy != nil {
+		// 静默已过期或被删除，清除告警上的静默标记
+		e.markSilenced(ctx, alert, nil)
+	}
+
+	// 根因告警活跃时抑制依赖它的告警，只记录根因引用不发送通知
+	if source := e.findInhibitor(ctx, alert, device); source != nil {
+		rootID := source.ID
+		if source.InhibitedBy != nil {
+			rootID = *source.InhibitedBy
+		}
+		e.logger.Info("Alert inhibited, skipping notification",
+			zap.String("alert_id", alert.ID.String()),
+			zap.String("source_alert_id", source.ID.String()),
+			zap.String("root_cause_alert_id", rootID.String()),
+		)
+		e.markInhibited(ctx, alert, &rootID)
+		span.SetAttributes(attribute.String("alert.inhibited_by", rootID.String()))
+		return nil
+	}
+	if alert.InhibitedBy != nil {
+		// 根因已恢复，清除告警上的抑制标记
+		e.markInhibited(ctx, alert, nil)
+	}
+
 	// 构建匹配上下文
 	matchCtx := &MatchContext{
 		Alert:     alert,
@@ -137,11 +209,20 @@ func (e *Engine) Process(ctx context.Context, alert *domain.Alert) error {
 		Metadata:  make(map[string]interface{}),
 	}
 
-	// 匹配规则
+	// 匹配规则（全局规则 + 告警所属组织的规则）
 	e.rulesMutex.RLock()
-	matchedRules := e.matcher.MatchMultiple(e.rules, matchCtx)
+	candidates := e.rules
+	if orgID, ok := alertOrganization(alert, device); ok {
+		if orgRules := e.orgRules[orgID]; len(orgRules) > 0 {
+			candidates = make([]Rule, 0, len(e.rules)+len(orgRules))
+			candidates = append(candidates, e.rules...)
+			candidates = append(candidates, orgRules...)
+		}
+	}
+	matchedRules := e.matcher.MatchMultiple(candidates, matchCtx)
 	e.rulesMutex.RUnlock()
 
+	span.SetAttributes(attribute.Int("rules.matched", len(matchedRules)))
 	if len(matchedRules) == 0 {
 		e.logger.Debug("No rules matched for alert",
 			zap.String("alert_id", alert.ID.String()),
@@ -164,14 +245,110 @@ func (e *Engine) Process(ctx context.Context, alert *domain.Alert) error {
 		}
 	}
 
-	// 处理告警升级
-	e.processEscalations(ctx, alert, device)
-
 	return nil
 }
 
+// alertOrganization 确定告警所属组织：设备告警取设备所在网络的组织，
+// 网络、注册密钥等非设备告警取metadata中的organization_id
+func alertOrganization(alert *domain.Alert, device *domain.Device) (uuid.UUID, bool) {
+	if device != nil && device.VirtualNetwork != nil {
+		return device.VirtualNetwork.OrganizationID, true
+	}
+	if orgStr, ok := alert.Metadata["organization_id"].(string); ok {
+		if orgID, err := uuid.Parse(orgStr); err == nil {
+			return orgID, true
+		}
+	}
+	return uuid.Nil, false
+}
+
+// findSilence 查找命中告警的生效静默
+// 静默按组织隔离，无法确定组织的告警不会被静默；网络、注册密钥等非设备告警按metadata中的组织匹配
+func (e *Engine) findSilence(ctx context.Context, alert *domain.Alert, device *domain.Device) *domain.Silence {
+	if e.silenceRepo == nil {
+		return nil
+	}
+	orgID, ok := alertOrganization(alert, device)
+	if !ok {
+		return nil
+	}
+
+	now := time.Now()
+	silences, err := e.activeSilences(ctx, now)
+	if err != nil {
+		// 静默查询失败时宁可多发通知，也不吞掉告警
+		e.logger.Error("Failed to load active silences", zap.Error(err))
+		return nil
+	}
+
+	// 缓存期间到期的静默不再生效
+	active := make([]*domain.Silence, 0, len(silences))
+	for _, silence := range silences {
+		if silence.State(now) == domain.SilenceStateActive {
+			active = append(active, silence)
+		}
+	}
+	return domain.FindMatchingSilence(active, orgID, alert, device)
+}
+
+// activeSilences 返回生效的静默，结果在activeSilencesTTL内复用
+// 静默从数据库加载时已编译正则匹配器，缓存的静默只读，可在多条告警间共享
+func (e *Engine) activeSilences(ctx context.Context, now time.Time) ([]*domain.Silence, error) {
+	e.silenceMutex.Lock()
+	defer e.silenceMutex.Unlock()
+
+	if e.silences != nil && now.Sub(e.silencesLoaded) < activeSilencesTTL {
+		return e.silences, nil
+	}
+
+	silences, err := e.silenceRepo.FindActive(ctx, now)
+	if err != nil {
+		return nil, err
+	}
+	if silences == nil {
+		silences = []*domain.Silence{}
+	}
+
+	e.silences = silences
+	e.silencesLoaded = now
+	return silences, nil
+}
+
+// markSilenced 更新告警的静默标记
+func (e *Engine) markSilenced(ctx context.Context, alert *domain.Alert, silenceID *uuid.UUID) {
+	alert.SilencedBy = silenceID
+	if e.alertRepo == nil {
+		return
+	}
+	if err := e.alertRepo.MarkSilenced(ctx, alert.ID, silenceID); err != nil {
+		e.logger.Error("Failed to update alert silence state",
+			zap.String("alert_id", alert.ID.String()),
+			zap.Error(err),
+		)
+	}
+}
+
 // executeRule 执行单个规则
 func (e *Engine) executeRule(ctx context.Context, rule *Rule, alert *domain.Alert, device *domain.Device) error {
+	ctx, span := tracing.Start(ctx, "rules.execute", attribute.String("rule.id", rule.ID))
+	defer span.End()
+
+	// 配置了分组的规则先合并告警，由RunGroupFlushes统一发送摘要通知（速率限制在发送时检查）
+	if rule.Grouping != nil && e.groupRepo != nil {
+		err := e.addToGroup(ctx, rule, alert, device)
+		if err == nil {
+			e.startEscalation(ctx, rule, alert)
+			return nil
+		}
+
+		// 分组写入失败时退回单条通知，避免丢失告警
+		e.logger.Error("Failed to group alert, notifying individually",
+			zap.String("rule_id", rule.ID),
+			zap.String("alert_id", alert.ID.String()),
+			zap.Error(err),
+		)
+	}
+
 	// 检查速率限制
 	if rule.RateLimit != nil {
 		if !e.checkRateLimit(rule, alert) {
@@ -197,28 +374,49 @@ func (e *Engine) executeRule(ctx context.Context, rule *Rule, alert *domain.Aler
 			continue
 		}
 
-		result := e.executor.ExecuteWithRetry(ctx, action, execCtx)
-		if !result.Success {
+		if err := e.dispatch(ctx, action, execCtx); err != nil {
 			e.logger.Error("Action execution failed after retries",
 				zap.String("rule_id", rule.ID),
 				zap.String("action_type", string(action.Type)),
-				zap.Error(result.Error),
+				zap.Error(err),
 			)
 		}
 	}
 
-	// 设置升级状态
-	if rule.Escalation != nil && rule.Escalation.Enabled {
-		e.setEscalationState(alert.ID, rule.ID)
+	e.startEscalation(ctx, rule, alert)
+	return nil
+}
+
+// startEscalation 登记告警升级（由RunEscalations按持久化进度执行）
+func (e *Engine) startEscalation(ctx context.Context, rule *Rule, alert *domain.Alert) {
+	if rule.Escalation == nil || !rule.Escalation.Enabled || e.escalator == nil {
+		return
 	}
 
-	return nil
+	if err := e.escalator.Start(ctx, alert, rule); err != nil {
+		e.logger.Error("Failed to schedule escalation",
+			zap.String("rule_id", rule.ID),
+			zap.String("alert_id", alert.ID.String()),
+			zap.Error(err),
+		)
+	}
+}
+
+// dispatch 执行动作：配置了分发器时交给分发器异步投递，否则同步带重试执行
+func (e *Engine) dispatch(ctx context.Context, action *Action, execCtx *ExecutionContext) error {
+	if e.dispatcher != nil {
+		return e.dispatcher.Dispatch(ctx, action, execCtx)
+	}
+
+	result := e.executor.ExecuteWithRetry(ctx, action, execCtx)
+	return result.Error
 }
 
 // checkRateLimit 检查速率限制
 func (e *Engine) checkRateLimit(rule *Rule, alert *domain.Alert) bool {
 	key := e.getRateLimitKey(rule, alert)
 
+	e.rateLimitMutex.Lock()
 	tracker, exists := e.rateLimiters[key]
 	if !exists {
 		tracker = &RateLimitTracker{
@@ -228,12 +426,18 @@ func (e *Engine) checkRateLimit(rule *Rule, alert *domain.Alert) bool {
 		}
 		e.rateLimiters[key] = tracker
 	}
+	e.rateLimitMutex.Unlock()
 
 	return tracker.Allow()
 }
 
 // getRateLimitKey 获取速率限制键
 func (e *Engine) getRateLimitKey(rule *Rule, alert *domain.Alert) string {
+	return rateLimitKey(rule, alert)
+}
+
+// rateLimitKey 根据规则的速率限制范围计算限流键
+func rateLimitKey(rule *Rule, alert *domain.Alert) string {
 	switch rule.RateLimit.Scope {
 	case "global":
 		return "global"
@@ -249,122 +453,12 @@ func (e *Engine) getRateLimitKey(rule *Rule, alert *domain.Alert) string {
 	}
 }
 
-// setEscalationState 设置升级状态
-func (e *Engine) setEscalationState(alertID uuid.UUID, ruleID string) {
-	e.escalationLock.Lock()
-	defer e.escalationLock.Unlock()
-
-	if _, exists := e.escalations[alertID]; !exists {
-		e.escalations[alertID] = &EscalationState{
-			AlertID:        alertID,
-			RuleID:         ruleID,
-			FirstTriggered: time.Now(),
-			LastNotified:   time.Now(),
-			RepeatCount:    0,
-			Acknowledged:   false,
-		}
-	}
-}
-
-// processEscalations 处理告警升级
-func (e *Engine) processEscalations(ctx context.Context, alert *domain.Alert, device *domain.Device) {
-	e.escalationLock.RLock()
-	state, exists := e.escalations[alert.ID]
-	e.escalationLock.RUnlock()
-
-	if !exists {
-		return
-	}
-
-	// 如果告警已确认，不再升级
-	if alert.Status == domain.AlertStatusAcknowledged || alert.Status == domain.AlertStatusResolved {
-		e.escalationLock.Lock()
-		delete(e.escalations, alert.ID)
-		e.escalationLock.Unlock()
-		return
-	}
-
-	// 找到对应的规则
-	e.rulesMutex.RLock()
-	var rule *Rule
-	for i := range e.rules {
-		if e.rules[i].ID == state.RuleID {
-			rule = &e.rules[i]
-			break
-		}
-	}
-	e.rulesMutex.RUnlock()
-
-	if rule == nil || rule.Escalation == nil || !rule.Escalation.Enabled {
-		return
-	}
-
-	escalation := rule.Escalation
-	now := time.Now()
-
-	// 检查是否需要升级
-	if now.Sub(state.FirstTriggered) < escalation.WaitDuration {
-		return // 还未到升级时间
-	}
-
-	// 检查是否需要重复通知
-	if escalation.RepeatInterval > 0 {
-		if now.Sub(state.LastNotified) < escalation.RepeatInterval {
-			return // 还未到重复通知时间
-		}
-
-		// 检查重复次数限制
-		if escalation.MaxRepeat > 0 && state.RepeatCount >= escalation.MaxRepeat {
-			return // 已达到最大重复次数
-		}
-	}
-
-	// 执行升级动作
-	execCtx := &ExecutionContext{
-		Alert:     alert,
-		Device:    device,
-		Rule:      rule,
-		Timestamp: now,
-	}
-
-	for i := range escalation.EscalateTo {
-		action := &escalation.EscalateTo[i]
-		result := e.executor.ExecuteWithRetry(ctx, action, execCtx)
-		if !result.Success {
-			e.logger.Error("Escalation action failed",
-				zap.String("rule_id", rule.ID),
-				zap.String("action_type", string(action.Type)),
-				zap.Error(result.Error),
-			)
-		}
-	}
-
-	// 更新升级状态
-	e.escalationLock.Lock()
-	state.LastNotified = now
-	state.RepeatCount++
-	e.escalationLock.Unlock()
-
-	e.logger.Info("Alert escalated",
-		zap.String("alert_id", alert.ID.String()),
-		zap.String("rule_id", rule.ID),
-		zap.Int("repeat_count", state.RepeatCount),
-	)
-}
-
 // TestRule 测试规则匹配
 func (e *Engine) TestRule(ruleID string, alert *domain.Alert, device *domain.Device) (bool, error) {
 	e.rulesMutex.RLock()
 	defer e.rulesMutex.RUnlock()
 
-	var rule *Rule
-	for i := range e.rules {
-		if e.rules[i].ID == ruleID {
-			rule = &e.rules[i]
-			break
-		}
-	}
-
+	rule := e.findRuleLocked(ruleID)
 	if rule == nil {
 		return false, fmt.Errorf("rule not found: %s", ruleID)
 	}
@@ -394,14 +488,67 @@ func (e *Engine) GetRule(ruleID string) (*Rule, error) {
 	e.rulesMutex.RLock()
 	defer e.rulesMutex.RUnlock()
 
+	if rule := e.findRuleLocked(ruleID); rule != nil {
+		copied := *rule
+		return &copied, nil
+	}
+
+	return nil, fmt.Errorf("rule not found: %s", ruleID)
+}
+
+// SetOrganizationRules 替换组织的已存储规则（规则ID需已按组织命名空间化）
+func (e *Engine) SetOrganizationRules(orgID uuid.UUID, rules []Rule) {
+	e.rulesMutex.Lock()
+	if len(rules) == 0 {
+		delete(e.orgRules, orgID)
+	} else {
+		e.orgRules[orgID] = rules
+	}
+	e.rulesMutex.Unlock()
+
+	e.logger.Info("Organization rules loaded",
+		zap.String("organization_id", orgID.String()),
+		zap.Int("count", len(rules)),
+	)
+}
+
+// organizationIDs 返回已加载存储规则的组织
+func (e *Engine) organizationIDs() []uuid.UUID {
+	e.rulesMutex.RLock()
+	defer e.rulesMutex.RUnlock()
+
+	ids := make([]uuid.UUID, 0, len(e.orgRules))
+	for orgID := range e.orgRules {
+		ids = append(ids, orgID)
+	}
+	return ids
+}
+
+// GetOrganizationRules 获取组织当前生效的已存储规则
+func (e *Engine) GetOrganizationRules(orgID uuid.UUID) []Rule {
+	e.rulesMutex.RLock()
+	defer e.rulesMutex.RUnlock()
+
+	rules := make([]Rule, len(e.orgRules[orgID]))
+	copy(rules, e.orgRules[orgID])
+	return rules
+}
+
+// findRuleLocked 在全局规则和组织规则中查找规则（调用方需持有rulesMutex）
+func (e *Engine) findRuleLocked(ruleID string) *Rule {
 	for i := range e.rules {
 		if e.rules[i].ID == ruleID {
-			rule := e.rules[i]
-			return &rule, nil
+			return &e.rules[i]
 		}
 	}
-
-	return nil, fmt.Errorf("rule not found: %s", ruleID)
+	for _, orgRules := range e.orgRules {
+		for i := range orgRules {
+			if orgRules[i].ID == ruleID {
+				return &orgRules[i]
+			}
+		}
+	}
+	return nil
 }
 
 // RateLimitTracker 速率限制跟踪器
@@ -414,10 +561,14 @@ type RateLimitTracker struct {
 
 // Allow 检查是否允许
 func (t *RateLimitTracker) Allow() bool {
+	return t.AllowAt(time.Now())
+}
+
+// AllowAt 以指定时间为当前时间检查是否允许（用于回放历史告警）
+func (t *RateLimitTracker) AllowAt(now time.Time) bool {
 	t.mutex.Lock()
 	defer t.mutex.Unlock()
 
-	now := time.Now()
 	cutoff := now.Add(-t.window)
 
 	// 清理过期的token
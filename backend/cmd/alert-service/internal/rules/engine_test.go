@@ -0,0 +1,164 @@
+package rules
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/edgelink/backend/internal/domain"
+	"github.com/edgelink/backend/internal/repository"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// noopDispatcher 丢弃所有动作的分发器
+type noopDispatcher struct{}
+
+func (noopDispatcher) Dispatch(ctx context.Context, action *Action, execCtx *ExecutionContext) error {
+	return nil
+}
+
+// missingDeviceRepo 查不到任何设备的设备仓库
+type missingDeviceRepo struct {
+	repository.DeviceRepository
+}
+
+func (missingDeviceRepo) FindByID(ctx context.Context, id uuid.UUID) (*domain.Device, error) {
+	return nil, errors.New("not found")
+}
+
+// memberAlertRepo 按ID返回活跃告警的告警仓库，每个告警属于不同设备
+type memberAlertRepo struct {
+	repository.AlertRepository
+}
+
+func (memberAlertRepo) FindByIDs(ctx context.Context, ids []uuid.UUID) ([]*domain.Alert, error) {
+	alerts := make([]*domain.Alert, 0, len(ids))
+	for _, id := range ids {
+		alerts = append(alerts, newRateLimitedAlert(id))
+	}
+	return alerts, nil
+}
+
+// savingGroupRepo 只接受保存的分组仓库
+type savingGroupRepo struct {
+	repository.AlertGroupRepository
+}
+
+func (savingGroupRepo) Save(ctx context.Context, group *domain.AlertGroup, removed domain.AlertIDList) error {
+	return nil
+}
+
+func newRateLimitedAlert(id uuid.UUID) *domain.Alert {
+	deviceID := uuid.New()
+	return &domain.Alert{
+		ID:       id,
+		DeviceID: &deviceID,
+		Type:     domain.AlertTypeDeviceOffline,
+		Severity: domain.SeverityCritical,
+		Status:   domain.AlertStatusActive,
+	}
+}
+
+// TestRateLimitConcurrentProcessAndFlush 告警处理与分组刷新并发创建按设备的限流器（需配合-race运行）
+func TestRateLimitConcurrentProcessAndFlush(t *testing.T) {
+	rateLimit := &RateLimit{MaxNotifications: 1, Window: time.Minute, Scope: "per_device"}
+	actions := []Action{{Type: ActionTypeWebhook, Enabled: true}}
+
+	engine := NewEngine(nil, missingDeviceRepo{}, memberAlertRepo{}, nil, savingGroupRepo{}, nil, nil, noopDispatcher{}, zap.NewNop())
+	engine.rules = []Rule{{
+		ID:        "direct",
+		Enabled:   true,
+		Actions:   actions,
+		RateLimit: rateLimit,
+	}}
+	// 分组规则放在组织规则中，不会被无组织的告警匹配
+	engine.orgRules[uuid.New()] = []Rule{{
+		ID:        "grouped",
+		Enabled:   true,
+		Actions:   actions,
+		RateLimit: rateLimit,
+		Grouping:  &Grouping{GroupInterval: time.Minute},
+	}}
+
+	const iterations = 200
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < iterations; i++ {
+			if err := engine.Process(ctx, newRateLimitedAlert(uuid.New())); err != nil {
+				t.Errorf("Process: %v", err)
+			}
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < iterations; i++ {
+			group := &domain.AlertGroup{ID: uuid.New(), RuleID: "grouped", AlertIDs: domain.AlertIDList{uuid.New()}}
+			if err := engine.flushGroup(ctx, group); err != nil {
+				t.Errorf("flushGroup: %v", err)
+			}
+		}
+	}()
+	wg.Wait()
+
+	engine.rateLimitMutex.Lock()
+	defer engine.rateLimitMutex.Unlock()
+	if got := len(engine.rateLimiters); got != 2*iterations {
+		t.Errorf("rate limiters = %d, want %d", got, 2*iterations)
+	}
+}
+
+// countingSilenceRepo 记录查询次数的静默仓库
+type countingSilenceRepo struct {
+	repository.SilenceRepository
+	silences []*domain.Silence
+	calls    int
+}
+
+func (r *countingSilenceRepo) FindActive(ctx context.Context, now time.Time) ([]*domain.Silence, error) {
+	r.calls++
+	return r.silences, nil
+}
+
+// TestFindSilenceCachesActiveSilences 生效静默在缓存期内只加载一次，缓存期间到期的静默不再命中
+func TestFindSilenceCachesActiveSilences(t *testing.T) {
+	orgID := uuid.New()
+	now := time.Now()
+	silence := &domain.Silence{
+		ID:             uuid.New(),
+		OrganizationID: orgID,
+		Matchers:       domain.SilenceMatchers{{Name: domain.SilenceLabelType, Value: "device_.*", Operator: domain.MatchRegex}},
+		StartsAt:       now.Add(-time.Hour),
+		EndsAt:         now.Add(time.Hour),
+		Comment:        "maintenance",
+	}
+	if err := silence.Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	repo := &countingSilenceRepo{silences: []*domain.Silence{silence}}
+
+	engine := NewEngine(nil, missingDeviceRepo{}, memberAlertRepo{}, repo, savingGroupRepo{}, nil, nil, noopDispatcher{}, zap.NewNop())
+	alert := newRateLimitedAlert(uuid.New())
+	alert.Metadata = domain.JSONB{"organization_id": orgID.String()}
+	alert.DeviceID = nil
+
+	for i := 0; i < 3; i++ {
+		if got := engine.findSilence(context.Background(), alert, nil); got != silence {
+			t.Fatalf("findSilence #%d = %v, want silence %s", i, got, silence.ID)
+		}
+	}
+	if repo.calls != 1 {
+		t.Errorf("FindActive calls = %d, want 1", repo.calls)
+	}
+
+	silence.EndsAt = now.Add(-time.Second)
+	if got := engine.findSilence(context.Background(), alert, nil); got != nil {
+		t.Errorf("findSilence after expiry = %s, want nil", got.ID)
+	}
+}
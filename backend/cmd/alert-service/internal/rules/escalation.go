@@ -0,0 +1,359 @@
+package rules
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/edgelink/backend/internal/domain"
+	"github.com/edgelink/backend/internal/repository"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	// escalationPollInterval 升级轮询间隔
+	escalationPollInterval = 15 * time.Second
+	// escalationBatchSize 每次认领的升级数量
+	escalationBatchSize = 50
+	// escalationLease 认领后的锁定时长，实例崩溃后由其他实例接管
+	escalationLease = 2 * time.Minute
+	// escalationSilenceRecheck 告警被静默时推迟升级的间隔
+	escalationSilenceRecheck = time.Minute
+)
+
+// 升级结束原因
+const (
+	escalationStopAcknowledged  = "acknowledged"
+	escalationStopResolved      = "resolved"
+	escalationStopRuleRemoved   = "rule_removed"
+	escalationStopPolicyMissing = "policy_not_found"
+	escalationStopExhausted     = "exhausted"
+)
+
+// Escalator 告警升级器：持久化升级进度并解析升级策略的通知对象
+type Escalator struct {
+	escalationRepo repository.AlertEscalationRepository
+	policyRepo     repository.EscalationPolicyRepository
+	scheduleRepo   repository.OnCallScheduleRepository
+	adminUserRepo  repository.AdminUserRepository
+	logger         *zap.Logger
+}
+
+// NewEscalator 创建告警升级器
+func NewEscalator(
+	escalationRepo repository.AlertEscalationRepository,
+	policyRepo repository.EscalationPolicyRepository,
+	scheduleRepo repository.OnCallScheduleRepository,
+	adminUserRepo repository.AdminUserRepository,
+	logger *zap.Logger,
+) *Escalator {
+	return &Escalator{
+		escalationRepo: escalationRepo,
+		policyRepo:     policyRepo,
+		scheduleRepo:   scheduleRepo,
+		adminUserRepo:  adminUserRepo,
+		logger:         logger,
+	}
+}
+
+// Start 为告警登记规则的升级（同一告警和规则只登记一次）
+func (s *Escalator) Start(ctx context.Context, alert *domain.Alert, rule *Rule) error {
+	now := time.Now()
+	escalation := &domain.AlertEscalation{
+		ID:        uuid.New(),
+		AlertID:   alert.ID,
+		RuleID:    rule.ID,
+		Status:    domain.EscalationStatusActive,
+		NextRunAt: now.Add(rule.Escalation.WaitDuration),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if rule.Escalation.PolicyID != "" {
+		policyID, err := uuid.Parse(rule.Escalation.PolicyID)
+		if err != nil {
+			return fmt.Errorf("invalid escalation policy_id: %w", err)
+		}
+		escalation.PolicyID = &policyID
+	}
+
+	created, err := s.escalationRepo.Start(ctx, escalation)
+	if err != nil {
+		return err
+	}
+	if created {
+		s.logger.Info("Escalation scheduled",
+			zap.String("alert_id", alert.ID.String()),
+			zap.String("rule_id", rule.ID),
+			zap.Time("next_run_at", escalation.NextRunAt),
+		)
+	}
+	return nil
+}
+
+// Recipients 解析升级级别在指定时刻的通知邮箱（排班表取当前值班人员，仅限策略所属组织的活跃管理员）
+func (s *Escalator) Recipients(ctx context.Context, policy *domain.EscalationPolicy, level domain.EscalationLevel, at time.Time) ([]string, error) {
+	userIDs := make([]uuid.UUID, 0, len(level.Targets))
+	for _, target := range level.Targets {
+		switch target.Type {
+		case domain.EscalationTargetUser:
+			userIDs = append(userIDs, target.ID)
+		case domain.EscalationTargetSchedule:
+			schedule, err := s.scheduleRepo.FindByID(ctx, target.ID)
+			if err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					s.logger.Warn("Escalation schedule not found",
+						zap.String("policy_id", policy.ID.String()),
+						zap.String("schedule_id", target.ID.String()),
+					)
+					continue
+				}
+				return nil, err
+			}
+			if schedule.OrganizationID != policy.OrganizationID {
+				continue
+			}
+			if shift, ok := schedule.OnCallAt(at); ok {
+				userIDs = append(userIDs, shift.UserID)
+			}
+		}
+	}
+
+	seen := make(map[uuid.UUID]bool)
+	recipients := make([]string, 0, len(userIDs))
+	for _, id := range userIDs {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		user, err := s.adminUserRepo.FindByID(ctx, id)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				continue
+			}
+			return nil, err
+		}
+		if user.IsActive && user.OrganizationID == policy.OrganizationID {
+			recipients = append(recipients, user.Email)
+		}
+	}
+	return recipients, nil
+}
+
+// RunEscalations 周期性处理到期的告警升级，直到ctx取消
+func (e *Engine) RunEscalations(ctx context.Context) {
+	if e.escalator == nil {
+		return
+	}
+
+	ticker := time.NewTicker(escalationPollInterval)
+	defer ticker.Stop()
+
+	e.logger.Info("Escalation processor started", zap.Duration("interval", escalationPollInterval))
+
+	for {
+		select {
+		case <-ctx.Done():
+			e.logger.Info("Escalation processor shutting down")
+			return
+		case <-ticker.C:
+			e.processDueEscalations(ctx)
+		}
+	}
+}
+
+// processDueEscalations 认领并处理一批到期的升级
+func (e *Engine) processDueEscalations(ctx context.Context) {
+	escalations, err := e.escalator.escalationRepo.ClaimDue(ctx, time.Now(), escalationBatchSize, escalationLease)
+	if err != nil {
+		e.logger.Error("Failed to claim due escalations", zap.Error(err))
+		return
+	}
+
+	for _, escalation := range escalations {
+		if err := e.processEscalation(ctx, escalation); err != nil {
+			e.logger.Error("Failed to process escalation",
+				zap.String("escalation_id", escalation.ID.String()),
+				zap.String("alert_id", escalation.AlertID.String()),
+				zap.Error(err),
+			)
+		}
+	}
+}
+
+// processEscalation 处理单个升级：告警未确认时通知当前级别并推进进度
+// 出错时不保存，锁过期后重新认领
+func (e *Engine) processEscalation(ctx context.Context, escalation *domain.AlertEscalation) error {
+	alert, err := e.alertRepo.FindByID(ctx, escalation.AlertID)
+	if err != nil {
+		return fmt.Errorf("failed to load alert: %w", err)
+	}
+
+	// 告警已确认或解决，不再升级
+	switch alert.Status {
+	case domain.AlertStatusAcknowledged:
+		escalation.Stop(domain.EscalationStatusStopped, escalationStopAcknowledged)
+		return e.escalator.escalationRepo.Save(ctx, escalation)
+	case domain.AlertStatusResolved:
+		escalation.Stop(domain.EscalationStatusStopped, escalationStopResolved)
+		return e.escalator.escalationRepo.Save(ctx, escalation)
+	}
+
+	e.rulesMutex.RLock()
+	var rule *Rule
+	if found := e.findRuleLocked(escalation.RuleID); found != nil {
+		copied := *found
+		rule = &copied
+	}
+	e.rulesMutex.RUnlock()
+
+	if rule == nil || !rule.Enabled || rule.Escalation == nil || !rule.Escalation.Enabled {
+		escalation.Stop(domain.EscalationStatusStopped, escalationStopRuleRemoved)
+		return e.escalator.escalationRepo.Save(ctx, escalation)
+	}
+
+	var device *domain.Device
+	if alert.DeviceID != nil {
+		if dev, err := e.deviceRepo.FindByID(ctx, *alert.DeviceID); err == nil {
+			device = dev
+		}
+	}
+
+	now := time.Now()
+
+	// 静默期间暂停升级，静默结束后继续
+	if e.findSilence(ctx, alert, device) != nil {
+		escalation.NextRunAt = now.Add(escalationSilenceRecheck)
+		return e.escalator.escalationRepo.Save(ctx, escalation)
+	}
+
+	execCtx := &ExecutionContext{
+		Alert:      alert,
+		Device:     device,
+		Rule:       rule,
+		Timestamp:  now,
+		Escalation: true,
+	}
+
+	if rule.Escalation.PolicyID != "" {
+		err = e.escalatePolicy(ctx, escalation, rule, execCtx, now)
+	} else {
+		e.escalateActions(ctx, escalation, rule, execCtx, now)
+	}
+	if err != nil {
+		return err
+	}
+
+	return e.escalator.escalationRepo.Save(ctx, escalation)
+}
+
+// escalatePolicy 按升级策略通知当前级别，并推进到下一级别或下一轮
+func (e *Engine) escalatePolicy(ctx context.Context, escalation *domain.AlertEscalation, rule *Rule, execCtx *ExecutionContext, now time.Time) error {
+	policyID, err := uuid.Parse(rule.Escalation.PolicyID)
+	if err != nil {
+		escalation.Stop(domain.EscalationStatusStopped, escalationStopPolicyMissing)
+		return nil
+	}
+
+	policy, err := e.escalator.policyRepo.FindByID(ctx, policyID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			escalation.Stop(domain.EscalationStatusStopped, escalationStopPolicyMissing)
+			return nil
+		}
+		return fmt.Errorf("failed to load escalation policy: %w", err)
+	}
+
+	// 策略被编辑后级别可能减少
+	if escalation.Level >= len(policy.Levels) {
+		escalation.Level = len(policy.Levels) - 1
+	}
+	level := policy.Levels[escalation.Level]
+
+	recipients, err := e.escalator.Recipients(ctx, policy, level, now)
+	if err != nil {
+		return fmt.Errorf("failed to resolve escalation recipients: %w", err)
+	}
+
+	if len(recipients) > 0 {
+		list := make([]interface{}, 0, len(recipients))
+		for _, r := range recipients {
+			list = append(list, r)
+		}
+		action := &Action{
+			Type:    ActionTypeEmail,
+			Enabled: true,
+			Config:  map[string]interface{}{"recipients": list},
+		}
+		if err := e.dispatch(ctx, action, execCtx); err != nil {
+			e.logger.Error("Escalation action failed",
+				zap.String("rule_id", rule.ID),
+				zap.String("policy_id", policy.ID.String()),
+				zap.Int("level", escalation.Level),
+				zap.Error(err),
+			)
+		}
+	} else {
+		e.logger.Warn("Escalation level has nobody to notify",
+			zap.String("alert_id", escalation.AlertID.String()),
+			zap.String("policy_id", policy.ID.String()),
+			zap.Int("level", escalation.Level),
+		)
+	}
+
+	e.logger.Info("Alert escalated",
+		zap.String("alert_id", escalation.AlertID.String()),
+		zap.String("rule_id", rule.ID),
+		zap.String("policy_id", policy.ID.String()),
+		zap.Int("level", escalation.Level),
+		zap.Int("cycle", escalation.Cycle),
+		zap.Int("recipients", len(recipients)),
+	)
+
+	escalation.NotifyCount++
+	escalation.LastNotifiedAt = &now
+	escalation.NextRunAt = now.Add(time.Duration(level.DelayMinutes) * time.Minute)
+	escalation.Level++
+	if escalation.Level >= len(policy.Levels) {
+		escalation.Level = 0
+		escalation.Cycle++
+		if escalation.Cycle > policy.RepeatCount {
+			escalation.Stop(domain.EscalationStatusCompleted, escalationStopExhausted)
+		}
+	}
+	return nil
+}
+
+// escalateActions 执行规则内联的升级动作，并按重复间隔安排下一次通知
+func (e *Engine) escalateActions(ctx context.Context, escalation *domain.AlertEscalation, rule *Rule, execCtx *ExecutionContext, now time.Time) {
+	for i := range rule.Escalation.EscalateTo {
+		action := &rule.Escalation.EscalateTo[i]
+		if err := e.dispatch(ctx, action, execCtx); err != nil {
+			e.logger.Error("Escalation action failed",
+				zap.String("rule_id", rule.ID),
+				zap.String("action_type", string(action.Type)),
+				zap.Error(err),
+			)
+		}
+	}
+
+	escalation.NotifyCount++
+	escalation.LastNotifiedAt = &now
+
+	e.logger.Info("Alert escalated",
+		zap.String("alert_id", escalation.AlertID.String()),
+		zap.String("rule_id", rule.ID),
+		zap.Int("repeat_count", escalation.NotifyCount),
+	)
+
+	interval := rule.Escalation.RepeatInterval
+	maxRepeat := rule.Escalation.MaxRepeat
+	if interval <= 0 || (maxRepeat > 0 && escalation.NotifyCount >= maxRepeat) {
+		escalation.Stop(domain.EscalationStatusCompleted, escalationStopExhausted)
+		return
+	}
+	escalation.NextRunAt = now.Add(interval)
+}
@@ -7,17 +7,30 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"os"
+	"sort"
+	"strings"
 	"time"
 
+	"github.com/edgelink/backend/cmd/alert-service/internal/integrations"
 	"github.com/edgelink/backend/cmd/alert-service/internal/notifier"
 	"github.com/edgelink/backend/internal/domain"
 	"go.uber.org/zap"
 )
 
+// ActionDispatcher 动作分发器（例如持久化投递队列），设置后规则引擎不再同步执行动作
+type ActionDispatcher interface {
+	Dispatch(ctx context.Context, action *Action, execCtx *ExecutionContext) error
+}
+
+// maxGroupItems 聊天类渠道摘要中逐条列出的告警上限，超出部分只显示数量
+const maxGroupItems = 20
+
 // Executor 动作执行器
 type Executor struct {
 	emailNotifier   *notifier.EmailNotifier
 	webhookNotifier *notifier.WebhookNotifier
+	integrations    *integrations.Manager
 	httpClient      *http.Client
 	logger          *zap.Logger
 }
@@ -26,11 +39,13 @@ type Executor struct {
 func NewExecutor(
 	emailNotifier *notifier.EmailNotifier,
 	webhookNotifier *notifier.WebhookNotifier,
+	integrationManager *integrations.Manager,
 	logger *zap.Logger,
 ) *Executor {
 	return &Executor{
 		emailNotifier:   emailNotifier,
 		webhookNotifier: webhookNotifier,
+		integrations:    integrationManager,
 		httpClient: &http.Client{
 			Timeout: 30 * time.Second,
 		},
@@ -56,6 +71,26 @@ func (e *Executor) Execute(ctx context.Context, action *Action, execCtx *Executi
 		return result
 	}
 
+	// 分组摘要通知使用多条目格式
+	if execCtx.Group != nil && len(execCtx.Group.Alerts) > 0 {
+		err := e.executeGroup(ctx, action, execCtx)
+		result.Success = (err == nil)
+		result.Error = err
+		result.Duration = time.Since(startTime)
+		result.Metadata["group_id"] = execCtx.Group.ID.String()
+		result.Metadata["group_size"] = len(execCtx.Group.Alerts)
+
+		if err != nil {
+			e.logger.Error("Group action execution failed",
+				zap.String("rule_id", execCtx.Rule.ID),
+				zap.String("action_type", string(action.Type)),
+				zap.String("group_id", execCtx.Group.ID.String()),
+				zap.Error(err),
+			)
+		}
+		return result
+	}
+
 	// 执行对应类型的动作
 	var err error
 	switch action.Type {
@@ -75,6 +110,8 @@ func (e *Executor) Execute(ctx context.Context, action *Action, execCtx *Executi
 		err = e.executeTelegram(ctx, action, execCtx)
 	case ActionTypeCustom:
 		err = e.executeCustom(ctx, action, execCtx)
+	case ActionTypeIntegration:
+		err = e.executeIntegration(ctx, action, execCtx)
 	default:
 		err = fmt.Errorf("unsupported action type: %s", action.Type)
 	}
@@ -100,6 +137,22 @@ func (e *Executor) Execute(ctx context.Context, action *Action, execCtx *Executi
 	return result
 }
 
+// testRuleID 测试通知执行结果中的规则ID
+const testRuleID = "test-notification"
+
+// SendTest 以测试告警同步执行动作，不经过投递队列、速率限制与重试
+func (e *Executor) SendTest(ctx context.Context, action *Action) *ExecutionResult {
+	testAction := *action
+	testAction.Enabled = true
+
+	execCtx := &ExecutionContext{
+		Alert:     notifier.NewTestAlert(string(action.Type)),
+		Rule:      &Rule{ID: testRuleID, Name: "Test notification"},
+		Timestamp: time.Now(),
+	}
+	return e.Execute(ctx, &testAction, execCtx)
+}
+
 // executeEmail 执行邮件通知
 func (e *Executor) executeEmail(ctx context.Context, action *Action, execCtx *ExecutionContext) error {
 	recipients, ok := action.Config["recipients"].([]interface{})
@@ -119,17 +172,107 @@ func (e *Executor) executeEmail(ctx context.Context, action *Action, execCtx *Ex
 		return fmt.Errorf("no valid recipients")
 	}
 
-	return e.emailNotifier.SendAlert(ctx, execCtx.Alert, recipientList)
+	err := e.emailNotifier.SendAlertNow(ctx, execCtx.Alert, recipientList)
+	return emailDeliveryError(execCtx.Alert, err)
+}
+
+// emailDeliveryError 将邮件提供商的错误分类传递给发件箱，永久错误直接进入死信
+func emailDeliveryError(alert *domain.Alert, err error) error {
+	if err == nil {
+		return nil
+	}
+	return integrations.NewIntegrationError("email", "send", alert.ID.String(), err, notifier.IsRetryableEmailError(err))
 }
 
 // executeWebhook 执行Webhook通知
 func (e *Executor) executeWebhook(ctx context.Context, action *Action, execCtx *ExecutionContext) error {
-	url, ok := action.Config["url"].(string)
-	if !ok {
-		return fmt.Errorf("invalid webhook url config")
+	target, err := webhookTarget(action, execCtx)
+	if err != nil {
+		return err
+	}
+
+	err = e.webhookNotifier.SendAlert(ctx, execCtx.Alert, target)
+	return webhookDeliveryError(execCtx.Alert, err)
+}
+
+// webhookDeliveryError 将Webhook错误分类传递给发件箱，4xx与已停用端点直接进入死信
+func webhookDeliveryError(alert *domain.Alert, err error) error {
+	if err == nil {
+		return nil
+	}
+	return integrations.NewIntegrationError("webhook", "send", alert.ID.String(), err, notifier.IsRetryableWebhookError(err))
+}
+
+// webhookTarget 从动作配置构建Webhook端点
+// 密钥优先从secret_env指定的环境变量读取，避免明文写入规则；请求头的值支持${VAR}引用环境变量
+// 经发件箱投递时每次只发送一次（max_retries不生效），事件ID取投递ID，发件箱重试时接收端可按事件ID去重
+func webhookTarget(action *Action, execCtx *ExecutionContext) (*notifier.WebhookTarget, error) {
+	rawURL, ok := action.Config["url"].(string)
+	if !ok || rawURL == "" {
+		return nil, fmt.Errorf("invalid webhook url config")
+	}
+
+	target := &notifier.WebhookTarget{
+		URL:         rawURL,
+		MaxRetries:  -1,
+		AutoDisable: true,
+	}
+	target.Name, _ = action.Config["name"].(string)
+	target.PayloadVersion, _ = action.Config["payload_version"].(string)
+	target.Format, _ = action.Config["format"].(string)
+
+	if envName, _ := action.Config["secret_env"].(string); envName != "" {
+		target.Secret = os.Getenv(envName)
+		if target.Secret == "" {
+			return nil, fmt.Errorf("webhook secret environment variable %s is not set", envName)
+		}
+	} else {
+		target.Secret, _ = action.Config["secret"].(string)
+	}
+
+	if headers, ok := action.Config["headers"].(map[string]interface{}); ok {
+		target.Headers = make(map[string]string, len(headers))
+		for key, value := range headers {
+			target.Headers[key] = os.ExpandEnv(fmt.Sprint(value))
+		}
+	}
+
+	if v, ok := configInt(action.Config["max_retries"]); ok {
+		target.MaxRetries = v
+	}
+	if v, ok := action.Config["auto_disable"].(bool); ok {
+		target.AutoDisable = v
+	}
+	if v, ok := configInt(action.Config["disable_after_failures"]); ok {
+		target.DisableAfterFailures = v
 	}
+	if raw, ok := action.Config["disable_after"].(string); ok && raw != "" {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid webhook disable_after: %w", err)
+		}
+		target.DisableAfter = d
+	}
+
+	if execCtx != nil && execCtx.DeliveryID != nil {
+		target.MaxRetries = 0
+		target.EventID = execCtx.DeliveryID.String()
+	}
+
+	return target, nil
+}
 
-	return e.webhookNotifier.SendAlert(ctx, execCtx.Alert, url)
+// configInt 读取整数配置（YAML解析为int，JSON解析为float64）
+func configInt(value interface{}) (int, bool) {
+	switch v := value.(type) {
+	case int:
+		return v, true
+	case int64:
+		return int(v), true
+	case float64:
+		return int(v), true
+	}
+	return 0, false
 }
 
 // executeSlack 执行Slack通知
@@ -348,6 +491,294 @@ func (e *Executor) executeCustom(ctx context.Context, action *Action, execCtx *E
 	return e.sendHTTPJSON(ctx, url, body)
 }
 
+// executeIntegration 通过集成管理器发送到命名实例
+func (e *Executor) executeIntegration(ctx context.Context, action *Action, execCtx *ExecutionContext) error {
+	name, ok := action.Config["name"].(string)
+	if !ok || name == "" {
+		return fmt.Errorf("invalid integration name config")
+	}
+
+	if e.integrations == nil {
+		return integrations.NewIntegrationError(name, "lookup", execCtx.Alert.ID.String(), fmt.Errorf("integrations are not configured"), false)
+	}
+
+	return e.integrations.SendAlertTo(ctx, name, execCtx.Alert)
+}
+
+// executeGroup 执行分组摘要通知
+func (e *Executor) executeGroup(ctx context.Context, action *Action, execCtx *ExecutionContext) error {
+	switch action.Type {
+	case ActionTypeEmail:
+		return e.executeEmailGroup(ctx, action, execCtx)
+	case ActionTypeWebhook:
+		target, err := webhookTarget(action, execCtx)
+		if err != nil {
+			return err
+		}
+		return webhookDeliveryError(execCtx.Alert, e.webhookNotifier.SendBatch(ctx, execCtx.Group.Alerts, target))
+	case ActionTypeSlack:
+		return e.executeSlackGroup(ctx, action, execCtx)
+	case ActionTypePagerDuty:
+		return e.executePagerDutyGroup(ctx, action, execCtx)
+	case ActionTypeDingTalk, ActionTypeWeChat, ActionTypeTelegram:
+		return e.executeTextGroup(ctx, action, execCtx)
+	case ActionTypeCustom:
+		return e.executeCustomGroup(ctx, action, execCtx)
+	case ActionTypeIntegration:
+		return e.executeIntegrationGroup(ctx, action, execCtx)
+	default:
+		return fmt.Errorf("unsupported action type: %s", action.Type)
+	}
+}
+
+// executeEmailGroup 发送分组摘要邮件
+func (e *Executor) executeEmailGroup(ctx context.Context, action *Action, execCtx *ExecutionContext) error {
+	recipients, ok := action.Config["recipients"].([]interface{})
+	if !ok {
+		return fmt.Errorf("invalid recipients config")
+	}
+
+	recipientList := make([]string, 0, len(recipients))
+	for _, r := range recipients {
+		if email, ok := r.(string); ok {
+			recipientList = append(recipientList, email)
+		}
+	}
+
+	if len(recipientList) == 0 {
+		return fmt.Errorf("no valid recipients")
+	}
+
+	group := execCtx.Group
+	err := e.emailNotifier.SendGroupNow(ctx, &notifier.AlertGroupDigest{
+		Title:    group.Title(),
+		Labels:   group.Labels,
+		Alerts:   group.Alerts,
+		FollowUp: group.FollowUp,
+	}, recipientList)
+	return emailDeliveryError(execCtx.Alert, err)
+}
+
+// executeSlackGroup 发送Slack分组摘要，每条告警一个附件
+func (e *Executor) executeSlackGroup(ctx context.Context, action *Action, execCtx *ExecutionContext) error {
+	webhookURL, ok := action.Config["webhook_url"].(string)
+	if !ok {
+		return fmt.Errorf("invalid slack webhook_url config")
+	}
+
+	channel, _ := action.Config["channel"].(string)
+	username, _ := action.Config["username"].(string)
+	if username == "" {
+		username = "EdgeLink Alerts"
+	}
+
+	group := execCtx.Group
+	alerts := group.Alerts
+	if len(alerts) > maxGroupItems {
+		alerts = alerts[:maxGroupItems]
+	}
+
+	attachments := make([]map[string]interface{}, 0, len(alerts)+1)
+	for _, alert := range alerts {
+		attachments = append(attachments, map[string]interface{}{
+			"color": e.getSeverityColor(alert.Severity),
+			"title": alert.Title,
+			"text":  alert.Message,
+			"fields": []map[string]interface{}{
+				{"title": "Severity", "value": string(alert.Severity), "short": true},
+				{"title": "Type", "value": string(alert.Type), "short": true},
+				{"title": "Device", "value": groupAlertDevice(alert), "short": true},
+				{"title": "Time", "value": alert.CreatedAt.Format(time.RFC3339), "short": true},
+			},
+		})
+	}
+	if omitted := len(group.Alerts) - len(alerts); omitted > 0 {
+		attachments = append(attachments, map[string]interface{}{
+			"color": "comment",
+			"text":  fmt.Sprintf("... and %d more", omitted),
+		})
+	}
+
+	message := map[string]interface{}{
+		"username":    username,
+		"text":        fmt.Sprintf("*%s*\n%s", group.Title(), formatGroupLabels(group.Labels)),
+		"attachments": attachments,
+	}
+
+	if channel != "" {
+		message["channel"] = channel
+	}
+
+	return e.sendHTTPJSON(ctx, webhookURL, message)
+}
+
+// executePagerDutyGroup 以分组为单位触发一个PagerDuty事件
+func (e *Executor) executePagerDutyGroup(ctx context.Context, action *Action, execCtx *ExecutionContext) error {
+	serviceKey, ok := action.Config["service_key"].(string)
+	if !ok {
+		return fmt.Errorf("invalid pagerduty service_key config")
+	}
+
+	group := execCtx.Group
+	members := make([]map[string]interface{}, 0, len(group.Alerts))
+	for _, alert := range group.Alerts {
+		members = append(members, map[string]interface{}{
+			"alert_id": alert.ID.String(),
+			"title":    alert.Title,
+			"severity": string(alert.Severity),
+			"type":     string(alert.Type),
+			"device":   groupAlertDevice(alert),
+		})
+	}
+
+	event := map[string]interface{}{
+		"routing_key":  serviceKey,
+		"event_action": "trigger",
+		"payload": map[string]interface{}{
+			"summary":   group.Title(),
+			"severity":  e.mapPagerDutySeverity(group.HighestSeverity()),
+			"source":    "EdgeLink",
+			"timestamp": execCtx.Timestamp.Format(time.RFC3339),
+			"custom_details": map[string]interface{}{
+				"group":  group.Labels,
+				"count":  len(group.Alerts),
+				"alerts": members,
+			},
+		},
+		"dedup_key": group.ID.String(),
+	}
+
+	return e.sendHTTPJSON(ctx, "https://events.pagerduty.com/v2/enqueue", event)
+}
+
+// executeTextGroup 发送钉钉/企业微信/Telegram的文本分组摘要
+func (e *Executor) executeTextGroup(ctx context.Context, action *Action, execCtx *ExecutionContext) error {
+	group := execCtx.Group
+	alerts := group.Alerts
+	if len(alerts) > maxGroupItems {
+		alerts = alerts[:maxGroupItems]
+	}
+
+	lines := make([]string, 0, len(alerts)+1)
+	for _, alert := range alerts {
+		lines = append(lines, fmt.Sprintf("- [%s] %s (%s, %s)",
+			alert.Severity, alert.Title, groupAlertDevice(alert), alert.CreatedAt.Format("2006-01-02 15:04:05")))
+	}
+	if omitted := len(group.Alerts) - len(alerts); omitted > 0 {
+		lines = append(lines, fmt.Sprintf("- ... and %d more", omitted))
+	}
+	body := strings.Join(lines, "\n")
+	labels := formatGroupLabels(group.Labels)
+
+	switch action.Type {
+	case ActionTypeDingTalk:
+		webhookURL, ok := action.Config["webhook_url"].(string)
+		if !ok {
+			return fmt.Errorf("invalid dingtalk webhook_url config")
+		}
+		return e.sendHTTPJSON(ctx, webhookURL, map[string]interface{}{
+			"msgtype": "markdown",
+			"markdown": map[string]interface{}{
+				"title": group.Title(),
+				"text":  fmt.Sprintf("## %s\n\n%s\n\n%s", group.Title(), labels, body),
+			},
+		})
+	case ActionTypeWeChat:
+		webhookURL, ok := action.Config["webhook_url"].(string)
+		if !ok {
+			return fmt.Errorf("invalid wechat webhook_url config")
+		}
+		return e.sendHTTPJSON(ctx, webhookURL, map[string]interface{}{
+			"msgtype": "markdown",
+			"markdown": map[string]interface{}{
+				"content": fmt.Sprintf("## %s\n>%s\n%s", group.Title(), labels, body),
+			},
+		})
+	default:
+		botToken, ok := action.Config["bot_token"].(string)
+		if !ok {
+			return fmt.Errorf("invalid telegram bot_token config")
+		}
+		chatID, ok := action.Config["chat_id"]
+		if !ok {
+			return fmt.Errorf("invalid telegram chat_id config")
+		}
+		url := fmt.Sprintf("https://api.telegram.org/bot%s/sendMessage", botToken)
+		return e.sendHTTPJSON(ctx, url, map[string]interface{}{
+			"chat_id": chatID,
+			"text":    fmt.Sprintf("%s\n%s\n\n%s", group.Title(), labels, body),
+		})
+	}
+}
+
+// executeCustomGroup 执行自定义动作的分组摘要
+func (e *Executor) executeCustomGroup(ctx context.Context, action *Action, execCtx *ExecutionContext) error {
+	url, ok := action.Config["url"].(string)
+	if !ok {
+		return fmt.Errorf("invalid custom url config")
+	}
+
+	body := map[string]interface{}{
+		"alerts":    execCtx.Group.Alerts,
+		"group":     execCtx.Group.Labels,
+		"group_id":  execCtx.Group.ID.String(),
+		"follow_up": execCtx.Group.FollowUp,
+		"rule":      execCtx.Rule.ID,
+	}
+
+	// 允许自定义请求体模板
+	if customBody, ok := action.Config["body"].(map[string]interface{}); ok {
+		body = customBody
+	}
+
+	return e.sendHTTPJSON(ctx, url, body)
+}
+
+// executeIntegrationGroup 将分组成员逐条发送到命名实例
+// 集成按告警ID维护外部事件状态（用于确认/恢复同步），因此不合并为单个事件
+func (e *Executor) executeIntegrationGroup(ctx context.Context, action *Action, execCtx *ExecutionContext) error {
+	var firstErr error
+	for _, alert := range execCtx.Group.Alerts {
+		memberCtx := *execCtx
+		memberCtx.Alert = alert
+		memberCtx.Group = nil
+		if err := e.executeIntegration(ctx, action, &memberCtx); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// groupAlertDevice 摘要中展示的告警设备
+func groupAlertDevice(alert *domain.Alert) string {
+	if alert.Device != nil && alert.Device.Name != "" {
+		return alert.Device.Name
+	}
+	if alert.DeviceID != nil {
+		return alert.DeviceID.String()
+	}
+	return "-"
+}
+
+// formatGroupLabels 格式化分组标签，按键排序
+func formatGroupLabels(labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, key := range keys {
+		value := labels[key]
+		if value == "" {
+			value = "-"
+		}
+		parts = append(parts, key+"="+value)
+	}
+	return strings.Join(parts, ", ")
+}
+
 // sendHTTPJSON 发送HTTP JSON请求
 func (e *Executor) sendHTTPJSON(ctx context.Context, url string, payload interface{}) error {
 	jsonData, err := json.Marshal(payload)
@@ -0,0 +1,288 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/edgelink/backend/internal/domain"
+	"go.uber.org/zap"
+)
+
+const (
+	// groupFlushPollInterval 分组刷新轮询间隔
+	groupFlushPollInterval = 5 * time.Second
+	// groupFlushBatchSize 每次认领的分组数量
+	groupFlushBatchSize = 50
+	// groupFlushLease 认领后的锁定时长，实例崩溃后由其他实例接管
+	groupFlushLease = 2 * time.Minute
+)
+
+// groupLabels 按规则的group_by计算告警所属分组的标签和分组键
+func groupLabels(grouping *Grouping, alert *domain.Alert, device *domain.Device) (map[string]string, string) {
+	labels := make(map[string]string, len(grouping.GroupBy))
+	parts := make([]string, 0, len(grouping.GroupBy))
+
+	for _, key := range grouping.GroupBy {
+		value := groupLabelValue(key, alert, device)
+		labels[key] = value
+		parts = append(parts, key+"="+value)
+	}
+
+	return labels, strings.Join(parts, ",")
+}
+
+// groupLabelValue 计算单个分组键的取值，无法确定时为空字符串（归入同一分组）
+func groupLabelValue(key string, alert *domain.Alert, device *domain.Device) string {
+	switch {
+	case key == GroupKeyVirtualNetwork:
+		if device != nil {
+			return device.VirtualNetworkID.String()
+		}
+	case key == GroupKeyAlertType:
+		return string(alert.Type)
+	case key == GroupKeySeverity:
+		return string(alert.Severity)
+	case key == GroupKeyDevice:
+		if alert.DeviceID != nil {
+			return alert.DeviceID.String()
+		}
+	case key == GroupKeyTags:
+		if device != nil && len(device.Tags) > 0 {
+			tags := append([]string(nil), device.Tags...)
+			sort.Strings(tags)
+			return strings.Join(tags, "|")
+		}
+	case strings.HasPrefix(key, GroupKeyTagPrefix):
+		prefix := strings.TrimPrefix(key, GroupKeyTagPrefix)
+		if device != nil {
+			matched := make([]string, 0, 1)
+			for _, tag := range device.Tags {
+				if strings.HasPrefix(tag, prefix) {
+					matched = append(matched, tag)
+				}
+			}
+			sort.Strings(matched)
+			return strings.Join(matched, "|")
+		}
+	case strings.HasPrefix(key, GroupKeyMetadataPrefix):
+		field := strings.TrimPrefix(key, GroupKeyMetadataPrefix)
+		if value, ok := alert.Metadata[field]; ok && value != nil {
+			return fmt.Sprint(value)
+		}
+	}
+	return ""
+}
+
+// addToGroup 将告警加入规则的分组，由RunGroupFlushes在group_wait后合并通知
+func (e *Engine) addToGroup(ctx context.Context, rule *Rule, alert *domain.Alert, device *domain.Device) error {
+	labels, key := groupLabels(rule.Grouping, alert, device)
+
+	group := &domain.AlertGroup{
+		RuleID:      rule.ID,
+		GroupKey:    key,
+		Labels:      labels,
+		NextFlushAt: time.Now().Add(rule.Grouping.GroupWait),
+	}
+
+	if err := e.groupRepo.AddAlert(ctx, group, alert.ID); err != nil {
+		return fmt.Errorf("failed to add alert to group: %w", err)
+	}
+
+	e.logger.Debug("Alert added to group",
+		zap.String("rule_id", rule.ID),
+		zap.String("alert_id", alert.ID.String()),
+		zap.String("group_key", key),
+	)
+	return nil
+}
+
+// RunGroupFlushes 周期性刷新到期的告警分组，直到ctx取消
+func (e *Engine) RunGroupFlushes(ctx context.Context) {
+	if e.groupRepo == nil {
+		return
+	}
+
+	ticker := time.NewTicker(groupFlushPollInterval)
+	defer ticker.Stop()
+
+	e.logger.Info("Alert group processor started", zap.Duration("interval", groupFlushPollInterval))
+
+	for {
+		select {
+		case <-ctx.Done():
+			e.logger.Info("Alert group processor shutting down")
+			return
+		case <-ticker.C:
+			e.flushDueGroups(ctx)
+		}
+	}
+}
+
+// flushDueGroups 认领并刷新一批到期的分组
+func (e *Engine) flushDueGroups(ctx context.Context) {
+	groups, err := e.groupRepo.ClaimDue(ctx, time.Now(), groupFlushBatchSize, groupFlushLease)
+	if err != nil {
+		e.logger.Error("Failed to claim due alert groups", zap.Error(err))
+		return
+	}
+
+	for _, group := range groups {
+		if err := e.flushGroup(ctx, group); err != nil {
+			e.logger.Error("Failed to flush alert group",
+				zap.String("group_id", group.ID.String()),
+				zap.String("rule_id", group.RuleID),
+				zap.Error(err),
+			)
+		}
+	}
+}
+
+// flushGroup 刷新单个分组：移除已恢复的成员，成员相对上次通知有变化时发送摘要通知
+// 出错时不保存，锁过期后重新认领
+func (e *Engine) flushGroup(ctx context.Context, group *domain.AlertGroup) error {
+	e.rulesMutex.RLock()
+	var rule *Rule
+	if found := e.findRuleLocked(group.RuleID); found != nil {
+		copied := *found
+		rule = &copied
+	}
+	e.rulesMutex.RUnlock()
+
+	// 规则已删除或不再分组，直接关闭分组
+	if rule == nil || !rule.Enabled || rule.Grouping == nil {
+		return e.groupRepo.Save(ctx, group, group.AlertIDs)
+	}
+
+	alerts, err := e.alertRepo.FindByIDs(ctx, group.AlertIDs)
+	if err != nil {
+		return fmt.Errorf("failed to load group members: %w", err)
+	}
+
+	members := make([]*domain.Alert, 0, len(alerts))
+	memberIDs := make(domain.AlertIDList, 0, len(alerts))
+	for _, alert := range alerts {
+		if alert.Status == domain.AlertStatusResolved {
+			continue
+		}
+		members = append(members, alert)
+		memberIDs = append(memberIDs, alert.ID)
+	}
+
+	// 已恢复或已删除的告警移出分组
+	removed := make(domain.AlertIDList, 0)
+	for _, id := range group.AlertIDs {
+		if !memberIDs.Contains(id) {
+			removed = append(removed, id)
+		}
+	}
+
+	now := time.Now()
+	group.NextFlushAt = now.Add(rule.Grouping.GroupInterval)
+
+	if len(members) > 0 && group.Changed(memberIDs) {
+		if rule.RateLimit != nil && !e.checkRateLimit(rule, members[0]) {
+			// 未发送的变化保留到下一个group_interval
+			e.logger.Info("Rate limit exceeded, deferring group notification",
+				zap.String("rule_id", rule.ID),
+				zap.String("group_id", group.ID.String()),
+			)
+			return e.groupRepo.Save(ctx, group, removed)
+		}
+
+		group.NotifiedAlertIDs = memberIDs
+		group.NotifyCount++
+		group.LastNotifiedAt = &now
+
+		// 先保存通知进度：发件箱投递时按分组记录的成员快照还原摘要内容
+		if err := e.groupRepo.Save(ctx, group, removed); err != nil {
+			return err
+		}
+		e.notifyGroup(ctx, rule, group, members)
+		return nil
+	}
+
+	return e.groupRepo.Save(ctx, group, removed)
+}
+
+// notifyGroup 执行规则动作发送分组摘要通知
+func (e *Engine) notifyGroup(ctx context.Context, rule *Rule, group *domain.AlertGroup, members []*domain.Alert) {
+	execCtx := &ExecutionContext{
+		Alert:     members[0],
+		Device:    members[0].Device,
+		Rule:      rule,
+		Timestamp: time.Now(),
+		Group: &GroupNotification{
+			ID:       group.ID,
+			Key:      group.GroupKey,
+			Labels:   group.Labels,
+			Alerts:   members,
+			FollowUp: group.NotifyCount > 1,
+		},
+	}
+
+	e.logger.Info("Sending alert group notification",
+		zap.String("rule_id", rule.ID),
+		zap.String("group_id", group.ID.String()),
+		zap.Int("members", len(members)),
+		zap.Bool("follow_up", execCtx.Group.FollowUp),
+	)
+
+	for i := range rule.Actions {
+		action := &rule.Actions[i]
+		if !action.Enabled {
+			continue
+		}
+
+		if err := e.dispatch(ctx, action, execCtx); err != nil {
+			e.logger.Error("Group action execution failed after retries",
+				zap.String("rule_id", rule.ID),
+				zap.String("group_id", group.ID.String()),
+				zap.String("action_type", string(action.Type)),
+				zap.Error(err),
+			)
+		}
+	}
+}
+
+// Title 摘要通知标题
+func (g *GroupNotification) Title() string {
+	if len(g.Alerts) == 1 {
+		return g.Alerts[0].Title
+	}
+
+	types := make([]string, 0, 1)
+	seen := make(map[domain.AlertType]bool)
+	for _, alert := range g.Alerts {
+		if !seen[alert.Type] {
+			seen[alert.Type] = true
+			types = append(types, string(alert.Type))
+		}
+	}
+
+	title := fmt.Sprintf("%d alerts: %s", len(g.Alerts), strings.Join(types, ", "))
+	if g.FollowUp {
+		title += " (updated)"
+	}
+	return title
+}
+
+// HighestSeverity 分组成员中最高的严重程度
+func (g *GroupNotification) HighestSeverity() domain.Severity {
+	order := map[domain.Severity]int{
+		domain.SeverityCritical: 0,
+		domain.SeverityHigh:     1,
+		domain.SeverityMedium:   2,
+		domain.SeverityLow:      3,
+	}
+
+	highest := g.Alerts[0].Severity
+	for _, alert := range g.Alerts[1:] {
+		if rank, ok := order[alert.Severity]; ok && rank < order[highest] {
+			highest = alert.Severity
+		}
+	}
+	return highest
+}
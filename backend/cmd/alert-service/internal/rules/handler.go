@@ -1,34 +1,64 @@
 package rules
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
+	"time"
 
+	"github.com/edgelink/backend/internal/domain"
+	"github.com/edgelink/backend/internal/middleware"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
 
 // Handler 规则引擎HTTP处理器
 type Handler struct {
-	engine *Engine
-	logger *zap.Logger
+	engine     *Engine
+	store      *RuleStore
+	backtester *Backtester
+	logger     *zap.Logger
 }
 
 // NewHandler 创建规则处理器
-func NewHandler(engine *Engine, logger *zap.Logger) *Handler {
+func NewHandler(engine *Engine, store *RuleStore, backtester *Backtester, logger *zap.Logger) *Handler {
 	return &Handler{
-		engine: engine,
-		logger: logger,
+		engine:     engine,
+		store:      store,
+		backtester: backtester,
+		logger:     logger,
 	}
 }
 
 // RegisterRoutes 注册路由
-func (h *Handler) RegisterRoutes(router *gin.RouterGroup) {
+// 全局规则来自规则文件，对所有组织生效，只允许superAdmin中间件放行的请求访问；回测按令牌限定组织
+func (h *Handler) RegisterRoutes(router *gin.RouterGroup, superAdmin gin.HandlerFunc) {
 	rules := router.Group("/rules")
 	{
-		rules.GET("", h.ListRules)
-		rules.GET("/:rule_id", h.GetRule)
-		rules.POST("/reload", h.ReloadRules)
-		rules.POST("/test", h.TestRule)
+		rules.GET("", superAdmin, h.ListRules)
+		rules.GET("/:rule_id", superAdmin, h.GetRule)
+		rules.POST("/reload", superAdmin, h.ReloadRules)
+		rules.POST("/test", superAdmin, h.TestRule)
+		rules.POST("/backtest", h.BacktestRules)
+	}
+
+	// 组织级规则管理（存储于数据库）
+	orgRules := router.Group("/organizations/:organization_id/rules")
+	{
+		orgRules.GET("", h.ListStoredRules)
+		orgRules.POST("", h.CreateStoredRule)
+		orgRules.POST("/import", h.ImportRules)
+		orgRules.GET("/export", h.ExportRules)
+		orgRules.GET("/:rule_id", h.GetStoredRule)
+		orgRules.PUT("/:rule_id", h.UpdateStoredRule)
+		orgRules.DELETE("/:rule_id", h.DeleteStoredRule)
+		orgRules.POST("/:rule_id/enable", h.EnableStoredRule)
+		orgRules.POST("/:rule_id/disable", h.DisableStoredRule)
+		orgRules.GET("/:rule_id/versions", h.ListRuleVersions)
+		orgRules.GET("/:rule_id/versions/:version", h.GetRuleVersion)
+		orgRules.GET("/:rule_id/diff", h.DiffRuleVersions)
+		orgRules.POST("/:rule_id/rollback", h.RollbackRule)
 	}
 }
 
@@ -178,6 +208,494 @@ func (h *Handler) TestRule(c *gin.Context) {
 	})
 }
 
+// BacktestRequest 规则回测请求
+type BacktestRequest struct {
+	Rules          string `json:"rules" binding:"required"`  // YAML格式的候选规则集
+	Days           int    `json:"days,omitempty"`            // 回放最近N天的告警，默认7天
+	OrganizationID string `json:"organization_id,omitempty"` // 只对超级管理员生效，其他角色固定回放令牌所属组织
+	MaxAlerts      int    `json:"max_alerts,omitempty"`
+}
+
+// maxBacktestDays 回测允许的最大天数
+const maxBacktestDays = 90
+
+// BacktestRules 使用候选规则集回放历史告警
+// @Summary 回测候选通知规则
+// @Description 回放最近N天的历史告警，展示命中的规则、将会执行的动作以及速率限制、升级和静默的影响，不发送任何通知
+// @Tags rules
+// @Accept json
+// @Produce json
+// @Param request body BacktestRequest true "回测请求"
+// @Success 200 {object} BacktestReport
+// @Failure 400 {object} ErrorResponse
+// @Router /api/v1/rules/backtest [post]
+func (h *Handler) BacktestRules(c *gin.Context) {
+	var req BacktestRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	if req.Days == 0 {
+		req.Days = 7
+	}
+	if req.Days < 0 || req.Days > maxBacktestDays {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "days must be between 1 and 90"})
+		return
+	}
+
+	until := time.Now()
+	opts := BacktestOptions{
+		Since:     until.AddDate(0, 0, -req.Days),
+		Until:     until,
+		MaxAlerts: req.MaxAlerts,
+	}
+
+	// 非超级管理员只能回放自己组织的告警，忽略请求中的organization_id
+	opts.OrganizationID = middleware.ScopedOrganizationID(c)
+	if opts.OrganizationID == nil && req.OrganizationID != "" {
+		orgID, err := uuid.Parse(req.OrganizationID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "organization_id must be a valid UUID"})
+			return
+		}
+		opts.OrganizationID = &orgID
+	}
+
+	report, err := h.backtester.RunYAML(c.Request.Context(), []byte(req.Rules), opts)
+	if err != nil {
+		h.respondStoreError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// StoredRuleListResponse 组织规则列表响应
+type StoredRuleListResponse struct {
+	Rules []*domain.NotificationRule `json:"rules"`
+	Total int                        `json:"total"`
+}
+
+// ListStoredRules 列出组织的规则
+// @Summary 获取组织通知规则列表
+// @Tags rules
+// @Accept json
+// @Produce json
+// @Param organization_id path string true "组织ID"
+// @Success 200 {object} StoredRuleListResponse
+// @Failure 400 {object} ErrorResponse
+// @Router /api/v1/organizations/{organization_id}/rules [get]
+func (h *Handler) ListStoredRules(c *gin.Context) {
+	orgID, ok := parseOrganizationID(c)
+	if !ok {
+		return
+	}
+
+	records, err := h.store.List(c.Request.Context(), orgID)
+	if err != nil {
+		h.respondStoreError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, StoredRuleListResponse{
+		Rules: records,
+		Total: len(records),
+	})
+}
+
+// GetStoredRule 获取组织规则详情
+// @Summary 获取组织通知规则详情
+// @Tags rules
+// @Accept json
+// @Produce json
+// @Param organization_id path string true "组织ID"
+// @Param rule_id path string true "规则ID"
+// @Success 200 {object} domain.NotificationRule
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/organizations/{organization_id}/rules/{rule_id} [get]
+func (h *Handler) GetStoredRule(c *gin.Context) {
+	orgID, ok := parseOrganizationID(c)
+	if !ok {
+		return
+	}
+
+	record, err := h.store.Get(c.Request.Context(), orgID, c.Param("rule_id"))
+	if err != nil {
+		h.respondStoreError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, record)
+}
+
+// SaveRuleRequest 创建/更新规则请求
+type SaveRuleRequest struct {
+	Rule    Rule   `json:"rule" binding:"required"`
+	Enabled *bool  `json:"enabled,omitempty"` // 仅创建时使用，默认启用
+	Comment string `json:"comment,omitempty"`
+}
+
+// CreateStoredRule 创建组织规则
+// @Summary 创建组织通知规则
+// @Tags rules
+// @Accept json
+// @Produce json
+// @Param organization_id path string true "组织ID"
+// @Param request body SaveRuleRequest true "规则定义"
+// @Success 201 {object} domain.NotificationRule
+// @Failure 400 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /api/v1/organizations/{organization_id}/rules [post]
+func (h *Handler) CreateStoredRule(c *gin.Context) {
+	orgID, ok := parseOrganizationID(c)
+	if !ok {
+		return
+	}
+
+	var req SaveRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	enabled := true
+	if req.Enabled != nil {
+		enabled = *req.Enabled
+	}
+
+	record, err := h.store.Create(c.Request.Context(), orgID, req.Rule, enabled, actorID(c), req.Comment)
+	if err != nil {
+		h.respondStoreError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, record)
+}
+
+// UpdateStoredRule 更新组织规则
+// @Summary 更新组织通知规则
+// @Tags rules
+// @Accept json
+// @Produce json
+// @Param organization_id path string true "组织ID"
+// @Param rule_id path string true "规则ID"
+// @Param request body SaveRuleRequest true "规则定义"
+// @Success 200 {object} domain.NotificationRule
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/organizations/{organization_id}/rules/{rule_id} [put]
+func (h *Handler) UpdateStoredRule(c *gin.Context) {
+	orgID, ok := parseOrganizationID(c)
+	if !ok {
+		return
+	}
+
+	var req SaveRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	record, err := h.store.Update(c.Request.Context(), orgID, c.Param("rule_id"), req.Rule, actorID(c), req.Comment)
+	if err != nil {
+		h.respondStoreError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, record)
+}
+
+// DeleteStoredRule 删除组织规则
+// @Summary 删除组织通知规则
+// @Tags rules
+// @Accept json
+// @Produce json
+// @Param organization_id path string true "组织ID"
+// @Param rule_id path string true "规则ID"
+// @Success 204
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/organizations/{organization_id}/rules/{rule_id} [delete]
+func (h *Handler) DeleteStoredRule(c *gin.Context) {
+	orgID, ok := parseOrganizationID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.store.Delete(c.Request.Context(), orgID, c.Param("rule_id"), actorID(c)); err != nil {
+		h.respondStoreError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// EnableStoredRule 启用组织规则
+// @Summary 启用组织通知规则
+// @Tags rules
+// @Produce json
+// @Param organization_id path string true "组织ID"
+// @Param rule_id path string true "规则ID"
+// @Success 200 {object} domain.NotificationRule
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/organizations/{organization_id}/rules/{rule_id}/enable [post]
+func (h *Handler) EnableStoredRule(c *gin.Context) {
+	h.setRuleEnabled(c, true)
+}
+
+// DisableStoredRule 禁用组织规则
+// @Summary 禁用组织通知规则
+// @Tags rules
+// @Produce json
+// @Param organization_id path string true "组织ID"
+// @Param rule_id path string true "规则ID"
+// @Success 200 {object} domain.NotificationRule
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/organizations/{organization_id}/rules/{rule_id}/disable [post]
+func (h *Handler) DisableStoredRule(c *gin.Context) {
+	h.setRuleEnabled(c, false)
+}
+
+// setRuleEnabled 切换规则启用状态
+func (h *Handler) setRuleEnabled(c *gin.Context, enabled bool) {
+	orgID, ok := parseOrganizationID(c)
+	if !ok {
+		return
+	}
+
+	record, err := h.store.SetEnabled(c.Request.Context(), orgID, c.Param("rule_id"), enabled, actorID(c))
+	if err != nil {
+		h.respondStoreError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, record)
+}
+
+// RuleVersionListResponse 规则版本列表响应
+type RuleVersionListResponse struct {
+	Versions []*domain.NotificationRuleVersion `json:"versions"`
+	Total    int                               `json:"total"`
+}
+
+// ListRuleVersions 列出规则版本历史
+// @Summary 获取规则版本历史
+// @Tags rules
+// @Produce json
+// @Param organization_id path string true "组织ID"
+// @Param rule_id path string true "规则ID"
+// @Success 200 {object} RuleVersionListResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/organizations/{organization_id}/rules/{rule_id}/versions [get]
+func (h *Handler) ListRuleVersions(c *gin.Context) {
+	orgID, ok := parseOrganizationID(c)
+	if !ok {
+		return
+	}
+
+	versions, err := h.store.Versions(c.Request.Context(), orgID, c.Param("rule_id"))
+	if err != nil {
+		h.respondStoreError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, RuleVersionListResponse{
+		Versions: versions,
+		Total:    len(versions),
+	})
+}
+
+// GetRuleVersion 获取规则的指定版本
+// @Summary 获取规则版本详情
+// @Tags rules
+// @Produce json
+// @Param organization_id path string true "组织ID"
+// @Param rule_id path string true "规则ID"
+// @Param version path int true "版本号"
+// @Success 200 {object} domain.NotificationRuleVersion
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/organizations/{organization_id}/rules/{rule_id}/versions/{version} [get]
+func (h *Handler) GetRuleVersion(c *gin.Context) {
+	orgID, ok := parseOrganizationID(c)
+	if !ok {
+		return
+	}
+
+	version, err := strconv.Atoi(c.Param("version"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "version must be an integer"})
+		return
+	}
+
+	v, err := h.store.Version(c.Request.Context(), orgID, c.Param("rule_id"), version)
+	if err != nil {
+		h.respondStoreError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, v)
+}
+
+// DiffRuleVersions 比较规则的两个版本
+// @Summary 比较规则版本差异
+// @Tags rules
+// @Produce json
+// @Param organization_id path string true "组织ID"
+// @Param rule_id path string true "规则ID"
+// @Param from query int true "起始版本"
+// @Param to query int true "目标版本"
+// @Success 200 {object} RuleDiff
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/organizations/{organization_id}/rules/{rule_id}/diff [get]
+func (h *Handler) DiffRuleVersions(c *gin.Context) {
+	orgID, ok := parseOrganizationID(c)
+	if !ok {
+		return
+	}
+
+	from, errFrom := strconv.Atoi(c.Query("from"))
+	to, errTo := strconv.Atoi(c.Query("to"))
+	if errFrom != nil || errTo != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "from and to must be integer versions"})
+		return
+	}
+
+	diff, err := h.store.Diff(c.Request.Context(), orgID, c.Param("rule_id"), from, to)
+	if err != nil {
+		h.respondStoreError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, diff)
+}
+
+// RollbackRuleRequest 回滚规则请求
+type RollbackRuleRequest struct {
+	Version int    `json:"version" binding:"required,min=1"`
+	Comment string `json:"comment,omitempty"`
+}
+
+// RollbackRule 回滚规则到指定版本
+// @Summary 回滚规则版本
+// @Tags rules
+// @Accept json
+// @Produce json
+// @Param organization_id path string true "组织ID"
+// @Param rule_id path string true "规则ID"
+// @Param request body RollbackRuleRequest true "回滚请求"
+// @Success 200 {object} domain.NotificationRule
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/organizations/{organization_id}/rules/{rule_id}/rollback [post]
+func (h *Handler) RollbackRule(c *gin.Context) {
+	orgID, ok := parseOrganizationID(c)
+	if !ok {
+		return
+	}
+
+	var req RollbackRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	record, err := h.store.Rollback(c.Request.Context(), orgID, c.Param("rule_id"), req.Version, actorID(c), req.Comment)
+	if err != nil {
+		h.respondStoreError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, record)
+}
+
+// ImportRules 从YAML导入组织规则
+// @Summary 导入YAML规则集
+// @Tags rules
+// @Accept application/x-yaml
+// @Produce json
+// @Param organization_id path string true "组织ID"
+// @Success 200 {object} ImportResult
+// @Failure 400 {object} ErrorResponse
+// @Router /api/v1/organizations/{organization_id}/rules/import [post]
+func (h *Handler) ImportRules(c *gin.Context) {
+	orgID, ok := parseOrganizationID(c)
+	if !ok {
+		return
+	}
+
+	data, err := c.GetRawData()
+	if err != nil || len(data) == 0 {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "request body must contain a YAML rule set"})
+		return
+	}
+
+	result, err := h.store.Import(c.Request.Context(), orgID, data, actorID(c))
+	if err != nil {
+		h.respondStoreError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// ExportRules 导出组织规则为YAML
+// @Summary 导出YAML规则集
+// @Tags rules
+// @Produce application/x-yaml
+// @Param organization_id path string true "组织ID"
+// @Success 200 {string} string "YAML规则集"
+// @Router /api/v1/organizations/{organization_id}/rules/export [get]
+func (h *Handler) ExportRules(c *gin.Context) {
+	orgID, ok := parseOrganizationID(c)
+	if !ok {
+		return
+	}
+
+	data, err := h.store.Export(c.Request.Context(), orgID)
+	if err != nil {
+		h.respondStoreError(c, err)
+		return
+	}
+
+	c.Header("Content-Disposition", "attachment; filename=alert-rules.yaml")
+	c.Data(http.StatusOK, "application/x-yaml", data)
+}
+
+// respondStoreError 将规则存储错误映射为HTTP响应
+func (h *Handler) respondStoreError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, ErrRuleNotFound), errors.Is(err, ErrVersionNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: err.Error()})
+	case errors.Is(err, ErrRuleExists):
+		c.JSON(http.StatusConflict, ErrorResponse{Error: err.Error()})
+	case errors.Is(err, ErrInvalidRule):
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	default:
+		h.logger.Error("Rule store operation failed", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Rule store operation failed"})
+	}
+}
+
+// parseOrganizationID 解析路径中的组织ID，失败时写入错误响应
+func parseOrganizationID(c *gin.Context) (uuid.UUID, bool) {
+	orgID, err := uuid.Parse(c.Param("organization_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "organization_id must be a valid UUID"})
+		return uuid.Nil, false
+	}
+	return orgID, true
+}
+
+// actorID 从X-Actor-ID请求头提取操作者ID
+func actorID(c *gin.Context) *uuid.UUID {
+	id, err := uuid.Parse(c.GetHeader("X-Actor-ID"))
+	if err != nil {
+		return nil
+	}
+	return &id
+}
+
 // ErrorResponse 错误响应
 type ErrorResponse struct {
 	Error string `json:"error"`
@@ -0,0 +1,243 @@
+package rules
+
+import (
+	"context"
+	"time"
+
+	"github.com/edgelink/backend/internal/domain"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	// inhibitSourceScanLimit 每条抑制规则在告警所属组织内加载的候选源告警上限
+	inhibitSourceScanLimit = 500
+	// inhibitReleasePollInterval 检查根因已恢复的被抑制告警的间隔
+	inhibitReleasePollInterval = 30 * time.Second
+	// inhibitReleaseBatchSize 每次释放的被抑制告警数量
+	inhibitReleaseBatchSize = 100
+)
+
+// findInhibitor 查找抑制该告警的活跃源告警
+// 抑制按组织隔离，无法确定组织的告警不会被抑制
+func (e *Engine) findInhibitor(ctx context.Context, alert *domain.Alert, device *domain.Device) *domain.Alert {
+	if e.alertRepo == nil || device == nil || device.VirtualNetwork == nil {
+		return nil
+	}
+
+	e.rulesMutex.RLock()
+	inhibitRules := e.inhibitRules
+	e.rulesMutex.RUnlock()
+
+	if len(inhibitRules) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	targetCtx := &MatchContext{
+		Alert:     alert,
+		Device:    device,
+		Timestamp: now,
+		Metadata:  make(map[string]interface{}),
+	}
+
+	// 拓扑依赖按需计算，同一告警只解析一次
+	var providers map[uuid.UUID]DependencyKind
+	providersLoaded := false
+
+	orgID := device.VirtualNetwork.OrganizationID
+	for i := range inhibitRules {
+		inhibit := &inhibitRules[i]
+		if !inhibit.Enabled || !e.matcher.matchConditions(&inhibit.Target, targetCtx) {
+			continue
+		}
+
+		if inhibit.RequireDependency && !providersLoaded {
+			providersLoaded = true
+			if e.topology != nil {
+				resolved, err := e.topology.Providers(ctx, device)
+				if err != nil {
+					// 拓扑查询失败时宁可多发通知，也不吞掉告警
+					e.logger.Error("Failed to resolve device dependencies",
+						zap.String("device_id", device.ID.String()),
+						zap.Error(err),
+					)
+				}
+				providers = resolved
+			}
+		}
+		if inhibit.RequireDependency && len(providers) == 0 {
+			continue
+		}
+
+		sources, err := e.alertRepo.FindUnresolvedByTypes(ctx, &orgID, inhibit.Source.AlertTypes, inhibitSourceScanLimit)
+		if err != nil {
+			e.logger.Error("Failed to load inhibition source alerts",
+				zap.String("inhibit_rule_id", inhibit.ID),
+				zap.Error(err),
+			)
+			continue
+		}
+
+		for _, source := range sources {
+			if e.inhibits(inhibit, source, alert, device, orgID, providers, now) {
+				e.logger.Debug("Inhibit rule matched",
+					zap.String("inhibit_rule_id", inhibit.ID),
+					zap.String("source_alert_id", source.ID.String()),
+					zap.String("alert_id", alert.ID.String()),
+				)
+				return source
+			}
+		}
+	}
+
+	return nil
+}
+
+// inhibits 判断源告警是否按抑制规则抑制目标告警
+func (e *Engine) inhibits(
+	inhibit *InhibitRule,
+	source, alert *domain.Alert,
+	device *domain.Device,
+	orgID uuid.UUID,
+	providers map[uuid.UUID]DependencyKind,
+	now time.Time,
+) bool {
+	if source.ID == alert.ID {
+		return false
+	}
+	// 源告警自身被目标告警抑制时不反向抑制，避免循环
+	if source.InhibitedBy != nil && *source.InhibitedBy == alert.ID {
+		return false
+	}
+	if source.Device == nil || source.Device.VirtualNetwork == nil ||
+		source.Device.VirtualNetwork.OrganizationID != orgID {
+		return false
+	}
+
+	sourceCtx := &MatchContext{
+		Alert:     source,
+		Device:    source.Device,
+		Timestamp: now,
+		Metadata:  make(map[string]interface{}),
+	}
+	if !e.matcher.matchConditions(&inhibit.Source, sourceCtx) {
+		return false
+	}
+
+	for _, key := range inhibit.Equal {
+		if groupLabelValue(key, source, source.Device) != groupLabelValue(key, alert, device) {
+			return false
+		}
+	}
+
+	if inhibit.RequireDependency {
+		kind, ok := providers[source.Device.ID]
+		if !ok {
+			return false
+		}
+		if len(inhibit.DependencyKinds) > 0 && !containsDependencyKind(inhibit.DependencyKinds, kind) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// markInhibited 更新告警的抑制标记
+func (e *Engine) markInhibited(ctx context.Context, alert *domain.Alert, sourceID *uuid.UUID) {
+	alert.InhibitedBy = sourceID
+	if e.alertRepo == nil {
+		return
+	}
+	if err := e.alertRepo.MarkInhibited(ctx, alert.ID, sourceID); err != nil {
+		e.logger.Error("Failed to update alert inhibition state",
+			zap.String("alert_id", alert.ID.String()),
+			zap.Error(err),
+		)
+	}
+}
+
+// RunInhibitionReleases 周期性释放根因已恢复的被抑制告警并重新处理，直到ctx取消
+func (e *Engine) RunInhibitionReleases(ctx context.Context) {
+	if e.alertRepo == nil {
+		return
+	}
+
+	ticker := time.NewTicker(inhibitReleasePollInterval)
+	defer ticker.Stop()
+
+	e.logger.Info("Alert inhibition release processor started", zap.Duration("interval", inhibitReleasePollInterval))
+
+	for {
+		select {
+		case <-ctx.Done():
+			e.logger.Info("Alert inhibition release processor shutting down")
+			return
+		case <-ticker.C:
+			e.releaseInhibited(ctx)
+		}
+	}
+}
+
+// releaseInhibited 清除根因已恢复的抑制标记，告警重新经过规则匹配（可能被其他源告警再次抑制）
+func (e *Engine) releaseInhibited(ctx context.Context) {
+	alerts, err := e.alertRepo.FindReleasableInhibited(ctx, inhibitReleaseBatchSize)
+	if err != nil {
+		e.logger.Error("Failed to load releasable inhibited alerts", zap.Error(err))
+		return
+	}
+
+	for _, alert := range alerts {
+		if ctx.Err() != nil {
+			return
+		}
+
+		sourceID := *alert.InhibitedBy
+		cleared, err := e.alertRepo.ClearInhibition(ctx, alert.ID, sourceID)
+		if err != nil {
+			e.logger.Error("Failed to clear alert inhibition",
+				zap.String("alert_id", alert.ID.String()),
+				zap.Error(err),
+			)
+			continue
+		}
+		// 已被其他实例释放
+		if !cleared {
+			continue
+		}
+
+		alert.InhibitedBy = nil
+		e.logger.Info("Root cause resolved, releasing inhibited alert",
+			zap.String("alert_id", alert.ID.String()),
+			zap.String("source_alert_id", sourceID.String()),
+		)
+
+		if err := e.Process(ctx, alert); err != nil {
+			e.logger.Error("Failed to process released alert",
+				zap.String("alert_id", alert.ID.String()),
+				zap.Error(err),
+			)
+		}
+	}
+}
+
+// containsDependencyKind 判断依赖类型列表是否包含指定类型
+func containsDependencyKind(kinds []DependencyKind, kind DependencyKind) bool {
+	for _, k := range kinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// GetInhibitRules 获取抑制规则
+func (e *Engine) GetInhibitRules() []InhibitRule {
+	e.rulesMutex.RLock()
+	defer e.rulesMutex.RUnlock()
+
+	inhibitRules := make([]InhibitRule, len(e.inhibitRules))
+	copy(inhibitRules, e.inhibitRules)
+	return inhibitRules
+}
@@ -3,8 +3,11 @@ package rules
 import (
 	"fmt"
 	"os"
+	"strings"
 	"time"
 
+	"github.com/edgelink/backend/cmd/alert-service/internal/notifier"
+	"github.com/google/uuid"
 	"gopkg.in/yaml.v3"
 )
 
@@ -26,6 +29,8 @@ func NewParser() *Parser {
 			&ConditionsValidator{},
 			&ActionsValidator{},
 			&TimeRangeValidator{},
+			&EscalationValidator{},
+			&GroupingValidator{},
 		},
 	}
 }
@@ -88,6 +93,24 @@ func (p *Parser) ValidateRuleSet(ruleSet *RuleSet) error {
 		}
 	}
 
+	// 验证抑制规则
+	inhibitIDs := make(map[string]bool)
+	for i := range ruleSet.InhibitRules {
+		inhibit := &ruleSet.InhibitRules[i]
+		if inhibit.ID == "" {
+			return fmt.Errorf("inhibit rule at index %d has empty ID", i)
+		}
+
+		if inhibitIDs[inhibit.ID] {
+			return fmt.Errorf("duplicate inhibit rule ID: %s", inhibit.ID)
+		}
+		inhibitIDs[inhibit.ID] = true
+
+		if err := validateInhibitRule(inhibit); err != nil {
+			return fmt.Errorf("inhibit rule '%s' validation failed: %w", inhibit.ID, err)
+		}
+	}
+
 	return nil
 }
 
@@ -137,6 +160,23 @@ func (p *Parser) initializeDefaults(ruleSet *RuleSet) {
 		if rule.RateLimit != nil && rule.RateLimit.Scope == "" {
 			rule.RateLimit.Scope = "per_rule"
 		}
+
+		// 初始化分组默认值
+		if rule.Grouping != nil {
+			if rule.Grouping.GroupWait == 0 {
+				rule.Grouping.GroupWait = 30 * time.Second
+			}
+			if rule.Grouping.GroupInterval == 0 {
+				rule.Grouping.GroupInterval = 5 * time.Minute
+			}
+		}
+	}
+
+	// 抑制规则默认启用
+	for i := range ruleSet.InhibitRules {
+		if !ruleSet.InhibitRules[i].Enabled {
+			ruleSet.InhibitRules[i].Enabled = true
+		}
 	}
 }
 
@@ -224,6 +264,66 @@ func (v *ActionsValidator) Validate(rule *Rule) error {
 	return nil
 }
 
+// webhookReservedHeaders 由通知器设置、不允许自定义覆盖的请求头
+var webhookReservedHeaders = map[string]bool{
+	"content-type":              true,
+	"x-edgelink-event-id":       true,
+	"x-edgelink-event-type":     true,
+	"x-edgelink-schema-version": true,
+	"x-edgelink-timestamp":      true,
+	"x-edgelink-signature":      true,
+}
+
+// validateWebhookConfig 校验Webhook签名、负载格式、自定义请求头与停用阈值
+func validateWebhookConfig(config map[string]interface{}) error {
+	if version, ok := config["payload_version"]; ok {
+		if version != notifier.WebhookPayloadV1 && version != notifier.WebhookPayloadV2 {
+			return fmt.Errorf("webhook payload_version must be 'v1' or 'v2'")
+		}
+	}
+	if format, ok := config["format"]; ok {
+		if format != notifier.WebhookFormatJSON && format != notifier.WebhookFormatCloudEvents {
+			return fmt.Errorf("webhook format must be 'json' or 'cloudevents'")
+		}
+	}
+	if _, hasSecret := config["secret"]; hasSecret {
+		if _, hasEnv := config["secret_env"]; hasEnv {
+			return fmt.Errorf("webhook action accepts either 'secret' or 'secret_env', not both")
+		}
+	}
+
+	if raw, ok := config["headers"]; ok {
+		headers, ok := raw.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("webhook headers must be a map")
+		}
+		for key := range headers {
+			if webhookReservedHeaders[strings.ToLower(key)] {
+				return fmt.Errorf("webhook header %s is reserved", key)
+			}
+		}
+	}
+
+	if raw, ok := config["disable_after"]; ok {
+		value, ok := raw.(string)
+		if !ok {
+			return fmt.Errorf("webhook disable_after must be a duration string")
+		}
+		if _, err := time.ParseDuration(value); err != nil {
+			return fmt.Errorf("invalid webhook disable_after: %w", err)
+		}
+	}
+	for _, key := range []string{"max_retries", "disable_after_failures"} {
+		if raw, ok := config[key]; ok {
+			if v, ok := configInt(raw); !ok || v < 0 {
+				return fmt.Errorf("webhook %s must be a non-negative integer", key)
+			}
+		}
+	}
+
+	return nil
+}
+
 func (v *ActionsValidator) validateActionConfig(action *Action) error {
 	switch action.Type {
 	case ActionTypeEmail:
@@ -235,6 +335,9 @@ func (v *ActionsValidator) validateActionConfig(action *Action) error {
 		if _, ok := action.Config["url"]; !ok {
 			return fmt.Errorf("webhook action requires 'url' config")
 		}
+		if err := validateWebhookConfig(action.Config); err != nil {
+			return err
+		}
 
 	case ActionTypeSlack:
 		if _, ok := action.Config["webhook_url"]; !ok {
@@ -255,6 +358,138 @@ func (v *ActionsValidator) validateActionConfig(action *Action) error {
 		if _, ok := action.Config["webhook_url"]; !ok {
 			return fmt.Errorf("wechat action requires 'webhook_url' config")
 		}
+
+	case ActionTypeIntegration:
+		if name, _ := action.Config["name"].(string); name == "" {
+			return fmt.Errorf("integration action requires 'name' config")
+		}
+	}
+
+	return nil
+}
+
+// ValidateAction 校验单个动作的配置（供测试通知接口复用）
+func ValidateAction(action *Action) error {
+	if action.Type == "" {
+		return fmt.Errorf("type is required")
+	}
+	return (&ActionsValidator{}).validateActionConfig(action)
+}
+
+// EscalationValidator 升级配置验证器
+type EscalationValidator struct{}
+
+func (v *EscalationValidator) Validate(rule *Rule) error {
+	escalation := rule.Escalation
+	if escalation == nil || !escalation.Enabled {
+		return nil
+	}
+
+	if escalation.WaitDuration < 0 || escalation.RepeatInterval < 0 {
+		return fmt.Errorf("escalation durations must be non-negative")
+	}
+
+	if escalation.PolicyID != "" {
+		if _, err := uuid.Parse(escalation.PolicyID); err != nil {
+			return fmt.Errorf("escalation policy_id must be a valid UUID")
+		}
+		if len(escalation.EscalateTo) > 0 {
+			return fmt.Errorf("escalation cannot set both policy_id and escalate_to")
+		}
+		return nil
+	}
+
+	if len(escalation.EscalateTo) == 0 {
+		return fmt.Errorf("escalation requires policy_id or escalate_to")
+	}
+
+	actionsValidator := &ActionsValidator{}
+	for i := range escalation.EscalateTo {
+		if err := actionsValidator.validateActionConfig(&escalation.EscalateTo[i]); err != nil {
+			return fmt.Errorf("escalate_to[%d]: %w", i, err)
+		}
+	}
+
+	return nil
+}
+
+// GroupingValidator 分组配置验证器
+type GroupingValidator struct{}
+
+func (v *GroupingValidator) Validate(rule *Rule) error {
+	grouping := rule.Grouping
+	if grouping == nil {
+		return nil
+	}
+
+	if len(grouping.GroupBy) == 0 {
+		return fmt.Errorf("grouping requires at least one group_by key")
+	}
+
+	if grouping.GroupWait < 0 || grouping.GroupInterval < 0 {
+		return fmt.Errorf("grouping durations must be non-negative")
+	}
+
+	seen := make(map[string]bool)
+	for _, key := range grouping.GroupBy {
+		if seen[key] {
+			return fmt.Errorf("duplicate group_by key: %s", key)
+		}
+		seen[key] = true
+
+		if !validGroupKey(key) {
+			return fmt.Errorf("invalid group_by key: %s", key)
+		}
+	}
+
+	return nil
+}
+
+// validGroupKey 判断分组键是否合法
+func validGroupKey(key string) bool {
+	switch {
+	case key == GroupKeyVirtualNetwork, key == GroupKeyAlertType, key == GroupKeySeverity,
+		key == GroupKeyDevice, key == GroupKeyTags:
+		return true
+	case strings.HasPrefix(key, GroupKeyTagPrefix) && len(key) > len(GroupKeyTagPrefix):
+		return true
+	case strings.HasPrefix(key, GroupKeyMetadataPrefix) && len(key) > len(GroupKeyMetadataPrefix):
+		return true
+	}
+	return false
+}
+
+// validateInhibitRule 验证抑制规则
+func validateInhibitRule(inhibit *InhibitRule) error {
+	conditions := &ConditionsValidator{}
+	if err := conditions.validateConditions(&inhibit.Source); err != nil {
+		return fmt.Errorf("invalid source conditions: %w", err)
+	}
+	if err := conditions.validateConditions(&inhibit.Target); err != nil {
+		return fmt.Errorf("invalid target conditions: %w", err)
+	}
+
+	// 源条件必须限定告警类型，否则每次匹配都需扫描全部未恢复告警
+	if len(inhibit.Source.AlertTypes) == 0 {
+		return fmt.Errorf("source conditions must specify alert_types")
+	}
+
+	for _, key := range inhibit.Equal {
+		if !validGroupKey(key) {
+			return fmt.Errorf("invalid equal key: %s", key)
+		}
+	}
+
+	for _, kind := range inhibit.DependencyKinds {
+		switch kind {
+		case DependencyExitNode, DependencySubnetRouter, DependencyRelay:
+		default:
+			return fmt.Errorf("invalid dependency kind: %s", kind)
+		}
+	}
+
+	if len(inhibit.DependencyKinds) > 0 && !inhibit.RequireDependency {
+		return fmt.Errorf("dependency_kinds requires require_dependency to be true")
 	}
 
 	return nil
@@ -0,0 +1,46 @@
+package rules
+
+import (
+	"context"
+	"time"
+
+	"github.com/edgelink/backend/internal/service"
+	"go.uber.org/zap"
+)
+
+// silenceReconcileInterval 检查静默开始和到期的间隔
+const silenceReconcileInterval = time.Minute
+
+// RunSilenceReconciliation 周期性为开始或到期的静默更新已有告警的静默标记，直到ctx取消
+// 创建、修改和手动结束静默时网关会立即更新；这里补上定时开始和自然到期的静默，多个副本同时执行结果相同
+func (e *Engine) RunSilenceReconciliation(ctx context.Context) {
+	if e.silenceRepo == nil || e.alertRepo == nil {
+		return
+	}
+
+	silences := service.NewSilenceService(e.silenceRepo, e.alertRepo, e.logger)
+	ticker := time.NewTicker(silenceReconcileInterval)
+	defer ticker.Stop()
+
+	e.logger.Info("Silence reconciliation started", zap.Duration("interval", silenceReconcileInterval))
+
+	last := time.Now()
+	for {
+		select {
+		case <-ctx.Done():
+			e.logger.Info("Silence reconciliation shutting down")
+			return
+		case <-ticker.C:
+			now := time.Now()
+			changed, err := silences.ReconcileTransitions(ctx, last, now)
+			if err != nil {
+				e.logger.Error("Failed to reconcile silenced alerts", zap.Error(err))
+				continue
+			}
+			last = now
+			if changed > 0 {
+				e.logger.Info("Alert silence state updated", zap.Int("alerts", changed))
+			}
+		}
+	}
+}
@@ -0,0 +1,707 @@
+package rules
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/edgelink/backend/internal/domain"
+	"github.com/edgelink/backend/internal/repository"
+	"github.com/edgelink/backend/internal/tracing"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+	"gorm.io/gorm"
+)
+
+// RuleChangesChannel 规则变更通知的Redis频道
+const RuleChangesChannel = "edgelink:rules"
+
+// exportRuleSetVersion 导出YAML时使用的规则集版本
+const exportRuleSetVersion = "1.0"
+
+// 规则变更订阅中断后重新订阅的退避区间
+const (
+	watchRetryMinBackoff = time.Second
+	watchRetryMaxBackoff = 30 * time.Second
+)
+
+var (
+	// ErrRuleNotFound 规则不存在
+	ErrRuleNotFound = errors.New("rule not found")
+	// ErrRuleExists 规则标识已被占用
+	ErrRuleExists = errors.New("rule already exists")
+	// ErrInvalidRule 规则校验失败
+	ErrInvalidRule = errors.New("invalid rule")
+	// ErrVersionNotFound 规则版本不存在
+	ErrVersionNotFound = errors.New("rule version not found")
+)
+
+// RuleChangeEvent 规则变更事件（通过Redis广播给所有告警服务副本）
+type RuleChangeEvent struct {
+	OrganizationID uuid.UUID             `json:"organization_id"`
+	RuleIDs        []string              `json:"rule_ids"`
+	ChangeType     domain.RuleChangeType `json:"change_type"`
+	Timestamp      time.Time             `json:"timestamp"`
+	TraceContext   map[string]string     `json:"trace_context,omitempty"`
+}
+
+// RuleDiff 两个规则版本之间的差异
+type RuleDiff struct {
+	RuleID      string        `json:"rule_id"`
+	FromVersion int           `json:"from_version"`
+	ToVersion   int           `json:"to_version"`
+	Changes     []FieldChange `json:"changes"`
+}
+
+// ImportResult YAML导入结果
+type ImportResult struct {
+	Created   []string `json:"created"`
+	Updated   []string `json:"updated"`
+	Unchanged []string `json:"unchanged"`
+}
+
+// RuleStore 数据库规则存储（负责校验、版本管理和跨副本同步）
+type RuleStore struct {
+	repo        repository.NotificationRuleRepository
+	parser      *Parser
+	engine      *Engine
+	redisClient *redis.Client
+	logger      *zap.Logger
+}
+
+// NewRuleStore 创建规则存储
+// engine为nil时（规则引擎未启用）仍可管理规则，但不会在本地生效
+func NewRuleStore(
+	repo repository.NotificationRuleRepository,
+	engine *Engine,
+	redisClient *redis.Client,
+	logger *zap.Logger,
+) *RuleStore {
+	return &RuleStore{
+		repo:        repo,
+		parser:      NewParser(),
+		engine:      engine,
+		redisClient: redisClient,
+		logger:      logger,
+	}
+}
+
+// List 列出组织的所有规则
+func (s *RuleStore) List(ctx context.Context, orgID uuid.UUID) ([]*domain.NotificationRule, error) {
+	return s.repo.FindByOrganization(ctx, orgID)
+}
+
+// Get 获取组织的单个规则
+func (s *RuleStore) Get(ctx context.Context, orgID uuid.UUID, ruleID string) (*domain.NotificationRule, error) {
+	record, err := s.findRecord(ctx, orgID, ruleID)
+	if err != nil {
+		return nil, err
+	}
+	if record.IsDeleted() {
+		return nil, ErrRuleNotFound
+	}
+	return record, nil
+}
+
+// Create 创建规则
+func (s *RuleStore) Create(ctx context.Context, orgID uuid.UUID, rule Rule, enabled bool, actorID *uuid.UUID, comment string) (*domain.NotificationRule, error) {
+	if err := s.prepareRule(&rule); err != nil {
+		return nil, err
+	}
+	rule.Enabled = enabled
+
+	record, err := s.createOrRevive(ctx, orgID, &rule, domain.RuleChangeCreate, actorID, comment)
+	if err != nil {
+		return nil, err
+	}
+
+	s.applyChange(ctx, orgID, []string{record.RuleKey}, domain.RuleChangeCreate)
+	return record, nil
+}
+
+// Update 更新规则定义（启用状态保持不变）
+func (s *RuleStore) Update(ctx context.Context, orgID uuid.UUID, ruleID string, rule Rule, actorID *uuid.UUID, comment string) (*domain.NotificationRule, error) {
+	if rule.ID == "" {
+		rule.ID = ruleID
+	}
+	if rule.ID != ruleID {
+		return nil, fmt.Errorf("%w: rule id cannot be changed", ErrInvalidRule)
+	}
+	if err := s.prepareRule(&rule); err != nil {
+		return nil, err
+	}
+
+	record, err := s.Get(ctx, orgID, ruleID)
+	if err != nil {
+		return nil, err
+	}
+	rule.Enabled = record.Enabled
+
+	if err := s.saveDefinition(ctx, record, &rule, domain.RuleChangeUpdate, actorID, comment); err != nil {
+		return nil, err
+	}
+
+	s.applyChange(ctx, orgID, []string{ruleID}, domain.RuleChangeUpdate)
+	return record, nil
+}
+
+// SetEnabled 启用或禁用规则
+func (s *RuleStore) SetEnabled(ctx context.Context, orgID uuid.UUID, ruleID string, enabled bool, actorID *uuid.UUID) (*domain.NotificationRule, error) {
+	record, err := s.Get(ctx, orgID, ruleID)
+	if err != nil {
+		return nil, err
+	}
+	if record.Enabled == enabled {
+		return record, nil
+	}
+
+	rule, err := definitionToRule(record.Definition)
+	if err != nil {
+		return nil, err
+	}
+	rule.Enabled = enabled
+
+	changeType := domain.RuleChangeDisable
+	if enabled {
+		changeType = domain.RuleChangeEnable
+	}
+
+	if err := s.saveDefinition(ctx, record, &rule, changeType, actorID, ""); err != nil {
+		return nil, err
+	}
+
+	s.applyChange(ctx, orgID, []string{ruleID}, changeType)
+	return record, nil
+}
+
+// Delete 删除规则（保留版本历史，可通过回滚恢复）
+func (s *RuleStore) Delete(ctx context.Context, orgID uuid.UUID, ruleID string, actorID *uuid.UUID) error {
+	record, err := s.Get(ctx, orgID, ruleID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	record.DeletedAt = &now
+	record.UpdatedBy = actorID
+	record.UpdatedAt = now
+
+	version := &domain.NotificationRuleVersion{
+		ChangeType: domain.RuleChangeDelete,
+		Enabled:    record.Enabled,
+		Definition: record.Definition,
+		ChangedBy:  actorID,
+		CreatedAt:  now,
+	}
+	if err := s.repo.SaveWithVersion(ctx, record, version); err != nil {
+		return fmt.Errorf("failed to delete rule: %w", err)
+	}
+
+	s.applyChange(ctx, orgID, []string{ruleID}, domain.RuleChangeDelete)
+	return nil
+}
+
+// Versions 列出规则的版本历史
+func (s *RuleStore) Versions(ctx context.Context, orgID uuid.UUID, ruleID string) ([]*domain.NotificationRuleVersion, error) {
+	record, err := s.findRecord(ctx, orgID, ruleID)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.FindVersions(ctx, record.ID)
+}
+
+// Version 获取规则的指定版本
+func (s *RuleStore) Version(ctx context.Context, orgID uuid.UUID, ruleID string, version int) (*domain.NotificationRuleVersion, error) {
+	record, err := s.findRecord(ctx, orgID, ruleID)
+	if err != nil {
+		return nil, err
+	}
+	return s.findVersion(ctx, record.ID, version)
+}
+
+// Diff 比较规则的两个版本
+func (s *RuleStore) Diff(ctx context.Context, orgID uuid.UUID, ruleID string, fromVersion, toVersion int) (*RuleDiff, error) {
+	record, err := s.findRecord(ctx, orgID, ruleID)
+	if err != nil {
+		return nil, err
+	}
+
+	from, err := s.findVersion(ctx, record.ID, fromVersion)
+	if err != nil {
+		return nil, err
+	}
+	to, err := s.findVersion(ctx, record.ID, toVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RuleDiff{
+		RuleID:      ruleID,
+		FromVersion: fromVersion,
+		ToVersion:   toVersion,
+		Changes:     DiffDefinitions(from.Definition, to.Definition),
+	}, nil
+}
+
+// Rollback 将规则回滚到指定版本（生成新版本，已删除的规则会被恢复）
+func (s *RuleStore) Rollback(ctx context.Context, orgID uuid.UUID, ruleID string, version int, actorID *uuid.UUID, comment string) (*domain.NotificationRule, error) {
+	record, err := s.findRecord(ctx, orgID, ruleID)
+	if err != nil {
+		return nil, err
+	}
+
+	target, err := s.findVersion(ctx, record.ID, version)
+	if err != nil {
+		return nil, err
+	}
+	if target.ChangeType == domain.RuleChangeDelete {
+		return nil, fmt.Errorf("%w: cannot roll back to a delete version", ErrInvalidRule)
+	}
+
+	rule, err := definitionToRule(target.Definition)
+	if err != nil {
+		return nil, err
+	}
+	rule.Enabled = target.Enabled
+
+	// 校验器可能在历史版本之后收紧，回滚前重新校验
+	if err := s.prepareRule(&rule); err != nil {
+		return nil, err
+	}
+	rule.Enabled = target.Enabled
+
+	if comment == "" {
+		comment = fmt.Sprintf("rollback to version %d", version)
+	}
+
+	record.DeletedAt = nil
+	if err := s.saveDefinition(ctx, record, &rule, domain.RuleChangeRollback, actorID, comment); err != nil {
+		return nil, err
+	}
+
+	s.applyChange(ctx, orgID, []string{ruleID}, domain.RuleChangeRollback)
+	return record, nil
+}
+
+// Import 从YAML导入规则集（新规则创建，已有规则仅在定义变化时生成新版本）
+func (s *RuleStore) Import(ctx context.Context, orgID uuid.UUID, data []byte, actorID *uuid.UUID) (*ImportResult, error) {
+	ruleSet, err := s.parser.ParseBytes(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRule, err)
+	}
+
+	result := &ImportResult{
+		Created:   make([]string, 0),
+		Updated:   make([]string, 0),
+		Unchanged: make([]string, 0),
+	}
+	changed := make([]string, 0, len(ruleSet.Rules))
+
+	for i := range ruleSet.Rules {
+		rule := ruleSet.Rules[i]
+
+		record, err := s.findRecord(ctx, orgID, rule.ID)
+		if err != nil && !errors.Is(err, ErrRuleNotFound) {
+			return result, err
+		}
+
+		if record == nil || record.IsDeleted() {
+			if _, err := s.createOrRevive(ctx, orgID, &rule, domain.RuleChangeImport, actorID, "imported from YAML"); err != nil {
+				return result, fmt.Errorf("failed to import rule '%s': %w", rule.ID, err)
+			}
+			result.Created = append(result.Created, rule.ID)
+			changed = append(changed, rule.ID)
+			continue
+		}
+
+		// 导入不改变已有规则的启用状态
+		rule.Enabled = record.Enabled
+		definition, err := ruleToDefinition(rule)
+		if err != nil {
+			return result, err
+		}
+		if reflect.DeepEqual(definition, record.Definition) {
+			result.Unchanged = append(result.Unchanged, rule.ID)
+			continue
+		}
+
+		if err := s.saveDefinition(ctx, record, &rule, domain.RuleChangeImport, actorID, "imported from YAML"); err != nil {
+			return result, fmt.Errorf("failed to import rule '%s': %w", rule.ID, err)
+		}
+		result.Updated = append(result.Updated, rule.ID)
+		changed = append(changed, rule.ID)
+	}
+
+	if len(changed) > 0 {
+		s.applyChange(ctx, orgID, changed, domain.RuleChangeImport)
+	}
+	return result, nil
+}
+
+// Export 导出组织的规则为YAML（格式与alert-rules.yaml一致）
+func (s *RuleStore) Export(ctx context.Context, orgID uuid.UUID) ([]byte, error) {
+	records, err := s.repo.FindByOrganization(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	ruleSet := RuleSet{
+		Version: exportRuleSetVersion,
+		Rules:   make([]Rule, 0, len(records)),
+	}
+	for _, record := range records {
+		rule, err := definitionToRule(record.Definition)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode rule '%s': %w", record.RuleKey, err)
+		}
+		rule.ID = record.RuleKey
+		rule.Enabled = record.Enabled
+		rule.CreatedAt = record.CreatedAt
+		rule.UpdatedAt = record.UpdatedAt
+		ruleSet.Rules = append(ruleSet.Rules, rule)
+	}
+
+	return yaml.Marshal(&ruleSet)
+}
+
+// LoadAll 将所有组织的启用规则加载到本地引擎
+func (s *RuleStore) LoadAll(ctx context.Context) error {
+	if s.engine == nil {
+		return nil
+	}
+
+	records, err := s.repo.FindAllEnabled(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load stored rules: %w", err)
+	}
+
+	byOrg := make(map[uuid.UUID][]*domain.NotificationRule)
+	for _, record := range records {
+		byOrg[record.OrganizationID] = append(byOrg[record.OrganizationID], record)
+	}
+
+	for orgID, orgRecords := range byOrg {
+		s.engine.SetOrganizationRules(orgID, s.toEngineRules(orgRecords))
+	}
+	// 已不再有启用规则的组织（重新订阅后全量加载时可能出现）
+	for _, orgID := range s.engine.organizationIDs() {
+		if _, ok := byOrg[orgID]; !ok {
+			s.engine.SetOrganizationRules(orgID, nil)
+		}
+	}
+	return nil
+}
+
+// Watch 订阅规则变更频道，其他副本的修改会立即在本地生效
+// 订阅失败或中断后按指数退避重新订阅，并重新加载全部规则以补上中断期间错过的变更，直到ctx取消
+func (s *RuleStore) Watch(ctx context.Context) error {
+	backoff := watchRetryMinBackoff
+	resync := false
+	for {
+		subscribed, err := s.watchOnce(ctx, resync)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if subscribed {
+			backoff = watchRetryMinBackoff
+		}
+		resync = true
+
+		s.logger.Warn("Rule change subscription interrupted, resubscribing",
+			zap.Duration("backoff", backoff),
+			zap.Error(err),
+		)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		if backoff *= 2; backoff > watchRetryMaxBackoff {
+			backoff = watchRetryMaxBackoff
+		}
+	}
+}
+
+// watchOnce 订阅一次规则变更频道并处理消息直到订阅中断，返回是否曾订阅成功
+func (s *RuleStore) watchOnce(ctx context.Context, resync bool) (bool, error) {
+	pubsub := s.redisClient.Subscribe(ctx, RuleChangesChannel)
+	defer pubsub.Close()
+
+	// 等待订阅确认
+	if _, err := pubsub.Receive(ctx); err != nil {
+		return false, fmt.Errorf("failed to subscribe to rule changes: %w", err)
+	}
+
+	s.logger.Info("Watching rule changes", zap.String("channel", RuleChangesChannel))
+
+	// 重新订阅后全量加载，中断期间发布的变更不会补发
+	if resync {
+		if err := s.LoadAll(ctx); err != nil {
+			s.logger.Error("Failed to reload stored rules after resubscribing", zap.Error(err))
+		}
+	}
+
+	ch := pubsub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return true, ctx.Err()
+
+		case msg, ok := <-ch:
+			if !ok {
+				return true, errors.New("rule change subscription closed")
+			}
+
+			var event RuleChangeEvent
+			if err := json.Unmarshal([]byte(msg.Payload), &event); err != nil {
+				s.logger.Error("Failed to unmarshal rule change event",
+					zap.Error(err),
+					zap.String("payload", msg.Payload),
+				)
+				continue
+			}
+
+			recvCtx, span := tracing.StartReceive(ctx, RuleChangesChannel, event.TraceContext)
+			err := s.reloadOrganization(recvCtx, event.OrganizationID)
+			if err != nil {
+				s.logger.Error("Failed to reload organization rules",
+					zap.String("organization_id", event.OrganizationID.String()),
+					zap.Error(err),
+				)
+			}
+			tracing.End(span, err)
+		}
+	}
+}
+
+// createOrRevive 创建新规则，若同标识规则已删除则在原记录上恢复
+func (s *RuleStore) createOrRevive(ctx context.Context, orgID uuid.UUID, rule *Rule, changeType domain.RuleChangeType, actorID *uuid.UUID, comment string) (*domain.NotificationRule, error) {
+	existing, err := s.findRecord(ctx, orgID, rule.ID)
+	if err != nil && !errors.Is(err, ErrRuleNotFound) {
+		return nil, err
+	}
+	if existing != nil {
+		if !existing.IsDeleted() {
+			return nil, ErrRuleExists
+		}
+		existing.DeletedAt = nil
+		if err := s.saveDefinition(ctx, existing, rule, changeType, actorID, comment); err != nil {
+			return nil, err
+		}
+		return existing, nil
+	}
+
+	definition, err := ruleToDefinition(*rule)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	record := &domain.NotificationRule{
+		ID:             uuid.New(),
+		OrganizationID: orgID,
+		RuleKey:        rule.ID,
+		Name:           rule.Name,
+		Enabled:        rule.Enabled,
+		Priority:       rule.Priority,
+		Definition:     definition,
+		CreatedBy:      actorID,
+		UpdatedBy:      actorID,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	version := &domain.NotificationRuleVersion{
+		ChangeType: changeType,
+		Enabled:    rule.Enabled,
+		Definition: definition,
+		ChangedBy:  actorID,
+		Comment:    comment,
+		CreatedAt:  now,
+	}
+
+	if err := s.repo.Create(ctx, record, version); err != nil {
+		return nil, fmt.Errorf("failed to create rule: %w", err)
+	}
+	return record, nil
+}
+
+// saveDefinition 写入新的规则定义并追加版本
+func (s *RuleStore) saveDefinition(ctx context.Context, record *domain.NotificationRule, rule *Rule, changeType domain.RuleChangeType, actorID *uuid.UUID, comment string) error {
+	definition, err := ruleToDefinition(*rule)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	record.Name = rule.Name
+	record.Enabled = rule.Enabled
+	record.Priority = rule.Priority
+	record.Definition = definition
+	record.UpdatedBy = actorID
+	record.UpdatedAt = now
+
+	version := &domain.NotificationRuleVersion{
+		ChangeType: changeType,
+		Enabled:    rule.Enabled,
+		Definition: definition,
+		ChangedBy:  actorID,
+		Comment:    comment,
+		CreatedAt:  now,
+	}
+
+	if err := s.repo.SaveWithVersion(ctx, record, version); err != nil {
+		return fmt.Errorf("failed to save rule: %w", err)
+	}
+	return nil
+}
+
+// prepareRule 使用解析器的校验器验证规则并填充默认值
+func (s *RuleStore) prepareRule(rule *Rule) error {
+	ruleSet := &RuleSet{
+		Version: exportRuleSetVersion,
+		Rules:   []Rule{*rule},
+	}
+	if err := s.parser.ValidateRuleSet(ruleSet); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidRule, err)
+	}
+
+	s.parser.initializeDefaults(ruleSet)
+	*rule = ruleSet.Rules[0]
+	return nil
+}
+
+// applyChange 在本地引擎生效并通知其他副本
+func (s *RuleStore) applyChange(ctx context.Context, orgID uuid.UUID, ruleIDs []string, changeType domain.RuleChangeType) {
+	if err := s.reloadOrganization(ctx, orgID); err != nil {
+		s.logger.Error("Failed to reload organization rules",
+			zap.String("organization_id", orgID.String()),
+			zap.Error(err),
+		)
+	}
+
+	if s.redisClient == nil {
+		return
+	}
+
+	ctx, span := tracing.StartPublish(ctx, RuleChangesChannel)
+
+	data, err := json.Marshal(&RuleChangeEvent{
+		OrganizationID: orgID,
+		RuleIDs:        ruleIDs,
+		ChangeType:     changeType,
+		Timestamp:      time.Now(),
+		TraceContext:   tracing.Inject(ctx),
+	})
+	if err == nil {
+		err = s.redisClient.Publish(ctx, RuleChangesChannel, data).Err()
+	}
+	tracing.End(span, err)
+
+	if err != nil {
+		// 其他副本将在下次重启或下一次变更时同步
+		s.logger.Error("Failed to publish rule change",
+			zap.String("organization_id", orgID.String()),
+			zap.Error(err),
+		)
+	}
+}
+
+// reloadOrganization 从数据库重新加载组织的启用规则到本地引擎
+func (s *RuleStore) reloadOrganization(ctx context.Context, orgID uuid.UUID) error {
+	if s.engine == nil {
+		return nil
+	}
+
+	records, err := s.repo.FindEnabledByOrganization(ctx, orgID)
+	if err != nil {
+		return err
+	}
+
+	s.engine.SetOrganizationRules(orgID, s.toEngineRules(records))
+	return nil
+}
+
+// toEngineRules 将数据库记录转换为引擎规则（规则ID按组织命名空间化，避免跨组织冲突）
+func (s *RuleStore) toEngineRules(records []*domain.NotificationRule) []Rule {
+	rules := make([]Rule, 0, len(records))
+	for _, record := range records {
+		rule, err := definitionToRule(record.Definition)
+		if err != nil {
+			s.logger.Error("Skipping undecodable stored rule",
+				zap.String("organization_id", record.OrganizationID.String()),
+				zap.String("rule_id", record.RuleKey),
+				zap.Error(err),
+			)
+			continue
+		}
+		rule.ID = OrganizationRuleID(record.OrganizationID, record.RuleKey)
+		rule.Enabled = record.Enabled
+		rule.CreatedAt = record.CreatedAt
+		rule.UpdatedAt = record.UpdatedAt
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+// findRecord 查找规则记录（包含已删除的规则）
+func (s *RuleStore) findRecord(ctx context.Context, orgID uuid.UUID, ruleID string) (*domain.NotificationRule, error) {
+	record, err := s.repo.FindByKey(ctx, orgID, ruleID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRuleNotFound
+		}
+		return nil, err
+	}
+	return record, nil
+}
+
+// findVersion 查找规则版本
+func (s *RuleStore) findVersion(ctx context.Context, recordID uuid.UUID, version int) (*domain.NotificationRuleVersion, error) {
+	v, err := s.repo.FindVersion(ctx, recordID, version)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrVersionNotFound
+		}
+		return nil, err
+	}
+	return v, nil
+}
+
+// OrganizationRuleID 组织规则在引擎中的ID
+func OrganizationRuleID(orgID uuid.UUID, ruleID string) string {
+	return orgID.String() + "/" + ruleID
+}
+
+// ruleToDefinition 将规则序列化为JSONB定义（时间戳由记录本身维护，不计入定义）
+func ruleToDefinition(rule Rule) (domain.JSONB, error) {
+	data, err := json.Marshal(rule)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode rule: %w", err)
+	}
+
+	var definition domain.JSONB
+	if err := json.Unmarshal(data, &definition); err != nil {
+		return nil, fmt.Errorf("failed to encode rule: %w", err)
+	}
+	delete(definition, "created_at")
+	delete(definition, "updated_at")
+	return definition, nil
+}
+
+// definitionToRule 将JSONB定义反序列化为规则
+func definitionToRule(definition domain.JSONB) (Rule, error) {
+	var rule Rule
+	data, err := json.Marshal(definition)
+	if err != nil {
+		return rule, fmt.Errorf("failed to decode rule: %w", err)
+	}
+	if err := json.Unmarshal(data, &rule); err != nil {
+		return rule, fmt.Errorf("failed to decode rule: %w", err)
+	}
+	return rule, nil
+}
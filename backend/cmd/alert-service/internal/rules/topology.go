@@ -0,0 +1,137 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	"github.com/edgelink/backend/internal/domain"
+	"github.com/edgelink/backend/internal/repository"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// DependencyKind 设备依赖类型
+type DependencyKind string
+
+const (
+	DependencyExitNode     DependencyKind = "exit_node"     // 对等配置的AllowedIPs包含默认路由
+	DependencySubnetRouter DependencyKind = "subnet_router" // 对等配置的AllowedIPs包含对端/32以外的网段
+	DependencyRelay        DependencyKind = "relay"         // 设备当前经TURN中继连接，依赖同网络的中继设备
+)
+
+// RelayRoleTag 标记中继设备的标签
+const RelayRoleTag = "role=relay"
+
+// topologySessionScanLimit 判断中继依赖时扫描的最近会话数量
+const topologySessionScanLimit = 50
+
+// TopologyResolver 根据拓扑数据推导设备之间的依赖关系
+type TopologyResolver struct {
+	peerConfigRepo repository.PeerConfigurationRepository
+	sessionRepo    repository.SessionRepository
+	deviceRepo     repository.DeviceRepository
+	logger         *zap.Logger
+}
+
+// NewTopologyResolver 创建拓扑依赖解析器
+func NewTopologyResolver(
+	peerConfigRepo repository.PeerConfigurationRepository,
+	sessionRepo repository.SessionRepository,
+	deviceRepo repository.DeviceRepository,
+	logger *zap.Logger,
+) *TopologyResolver {
+	return &TopologyResolver{
+		peerConfigRepo: peerConfigRepo,
+		sessionRepo:    sessionRepo,
+		deviceRepo:     deviceRepo,
+		logger:         logger,
+	}
+}
+
+// Providers 返回设备所依赖的上游设备及依赖类型
+func (t *TopologyResolver) Providers(ctx context.Context, device *domain.Device) (map[uuid.UUID]DependencyKind, error) {
+	providers := make(map[uuid.UUID]DependencyKind)
+
+	// 出口节点与子网路由：对等配置中路由了对端自身地址以外的网段
+	configs, err := t.peerConfigRepo.FindByDeviceID(ctx, device.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load peer configurations: %w", err)
+	}
+	for _, config := range configs {
+		if kind, ok := routedDependency(config); ok {
+			providers[config.PeerDeviceID] = kind
+		}
+	}
+
+	// 中继：设备存在活跃的TURN中继会话时依赖同网络中的中继设备
+	relayed, err := t.usesRelay(ctx, device.ID)
+	if err != nil {
+		return nil, err
+	}
+	if relayed {
+		peers, err := t.deviceRepo.FindByVirtualNetwork(ctx, device.VirtualNetworkID, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load network devices: %w", err)
+		}
+		for _, peer := range peers {
+			if !device.CanPeerWith(&peer) || !hasTag(peer.Tags, RelayRoleTag) {
+				continue
+			}
+			if _, exists := providers[peer.ID]; !exists {
+				providers[peer.ID] = DependencyRelay
+			}
+		}
+	}
+
+	return providers, nil
+}
+
+// usesRelay 判断设备当前是否存在经TURN中继的活跃会话
+func (t *TopologyResolver) usesRelay(ctx context.Context, deviceID uuid.UUID) (bool, error) {
+	sessions, err := t.sessionRepo.FindByDeviceID(ctx, deviceID, topologySessionScanLimit)
+	if err != nil {
+		return false, fmt.Errorf("failed to load sessions: %w", err)
+	}
+	for _, session := range sessions {
+		if session.IsActive() && session.ConnectionType == domain.ConnectionTypeTURNRelay {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// routedDependency 根据对等配置的AllowedIPs判断是否经对端路由流量
+func routedDependency(config *domain.PeerConfiguration) (DependencyKind, bool) {
+	peerIP := net.ParseIP(config.PeerVirtualIP)
+	kind := DependencyKind("")
+
+	for _, cidr := range config.AllowedIPs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			continue
+		}
+
+		ones, bits := network.Mask.Size()
+		if ones == 0 {
+			return DependencyExitNode, true
+		}
+		// 对端自身的主机路由不构成依赖
+		if ones == bits && peerIP != nil && network.IP.Equal(peerIP) {
+			continue
+		}
+		kind = DependencySubnetRouter
+	}
+
+	return kind, kind != ""
+}
+
+// hasTag 判断标签列表是否包含指定标签
+func hasTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
@@ -18,6 +18,7 @@ type Rule struct {
 	Actions     []Action     `yaml:"actions" json:"actions"`
 	RateLimit   *RateLimit   `yaml:"rate_limit,omitempty" json:"rate_limit,omitempty"`
 	Escalation  *Escalation  `yaml:"escalation,omitempty" json:"escalation,omitempty"`
+	Grouping    *Grouping    `yaml:"grouping,omitempty" json:"grouping,omitempty"`
 	Silence     *SilenceRule `yaml:"silence,omitempty" json:"silence,omitempty"`
 	Metadata    map[string]interface{} `yaml:"metadata,omitempty" json:"metadata,omitempty"`
 	CreatedAt   time.Time    `yaml:"created_at" json:"created_at"`
@@ -58,14 +59,15 @@ type Action struct {
 type ActionType string
 
 const (
-	ActionTypeEmail      ActionType = "email"
-	ActionTypeWebhook    ActionType = "webhook"
-	ActionTypeSlack      ActionType = "slack"
-	ActionTypePagerDuty  ActionType = "pagerduty"
-	ActionTypeDingTalk   ActionType = "dingtalk"
-	ActionTypeWeChat     ActionType = "wechat"
-	ActionTypeTelegram   ActionType = "telegram"
-	ActionTypeCustom     ActionType = "custom"
+	ActionTypeEmail       ActionType = "email"
+	ActionTypeWebhook     ActionType = "webhook"
+	ActionTypeSlack       ActionType = "slack"
+	ActionTypePagerDuty   ActionType = "pagerduty"
+	ActionTypeDingTalk    ActionType = "dingtalk"
+	ActionTypeWeChat      ActionType = "wechat"
+	ActionTypeTelegram    ActionType = "telegram"
+	ActionTypeCustom      ActionType = "custom"
+	ActionTypeIntegration ActionType = "integration" // 引用integrations配置中的命名实例
 )
 
 // RetryPolicy 重试策略
@@ -83,14 +85,35 @@ type RateLimit struct {
 }
 
 // Escalation 告警升级
+// 设置PolicyID时按升级策略逐级通知值班人员，否则执行EscalateTo中的动作
 type Escalation struct {
 	Enabled         bool          `yaml:"enabled" json:"enabled"`
 	WaitDuration    time.Duration `yaml:"wait_duration" json:"wait_duration"` // 未确认等待时长
-	EscalateTo      []Action      `yaml:"escalate_to" json:"escalate_to"` // 升级后的通知动作
+	PolicyID        string        `yaml:"policy_id,omitempty" json:"policy_id,omitempty"` // 升级策略ID
+	EscalateTo      []Action      `yaml:"escalate_to,omitempty" json:"escalate_to,omitempty"` // 升级后的通知动作
 	RepeatInterval  time.Duration `yaml:"repeat_interval,omitempty" json:"repeat_interval,omitempty"` // 重复通知间隔
 	MaxRepeat       int           `yaml:"max_repeat,omitempty" json:"max_repeat,omitempty"` // 最大重复次数
 }
 
+// Grouping 告警分组
+// 同一规则下group_by取值相同的告警在GroupWait窗口内合并为一条通知，之后每隔GroupInterval仅在成员变化时发送后续通知
+type Grouping struct {
+	GroupBy       []string      `yaml:"group_by" json:"group_by"`             // 分组键，见GroupKeyVirtualNetwork等
+	GroupWait     time.Duration `yaml:"group_wait" json:"group_wait"`         // 首次通知前的等待时长
+	GroupInterval time.Duration `yaml:"group_interval" json:"group_interval"` // 后续通知的最小间隔
+}
+
+// 分组键
+const (
+	GroupKeyVirtualNetwork = "virtual_network"
+	GroupKeyAlertType      = "alert_type"
+	GroupKeySeverity       = "severity"
+	GroupKeyDevice         = "device"
+	GroupKeyTags           = "tags"      // 设备的全部标签
+	GroupKeyTagPrefix      = "tag:"      // tag:<前缀>，取设备上以该前缀开头的标签，如tag:site=
+	GroupKeyMetadataPrefix = "metadata." // metadata.<字段>，取告警元数据中的字段
+)
+
 // SilenceRule 静默规则
 type SilenceRule struct {
 	Enabled    bool       `yaml:"enabled" json:"enabled"`
@@ -98,10 +121,24 @@ type SilenceRule struct {
 	Comment    string     `yaml:"comment,omitempty" json:"comment,omitempty"`
 }
 
+// InhibitRule 抑制规则
+// 源告警处于活跃状态时，匹配目标条件的告警被标记为抑制并引用根因告警，不再单独通知
+type InhibitRule struct {
+	ID                string           `yaml:"id" json:"id"`
+	Name              string           `yaml:"name" json:"name"`
+	Enabled           bool             `yaml:"enabled" json:"enabled"`
+	Source            Conditions       `yaml:"source" json:"source"`                                             // 根因告警条件
+	Target            Conditions       `yaml:"target" json:"target"`                                             // 被抑制告警条件
+	Equal             []string         `yaml:"equal,omitempty" json:"equal,omitempty"`                           // 源与目标取值必须相同的键，与group_by键相同
+	RequireDependency bool             `yaml:"require_dependency,omitempty" json:"require_dependency,omitempty"` // 要求目标设备在拓扑上依赖源设备
+	DependencyKinds   []DependencyKind `yaml:"dependency_kinds,omitempty" json:"dependency_kinds,omitempty"`     // 限定依赖类型，为空时不限
+}
+
 // RuleSet 规则集合
 type RuleSet struct {
-	Version string  `yaml:"version" json:"version"`
-	Rules   []Rule  `yaml:"rules" json:"rules"`
+	Version      string        `yaml:"version" json:"version"`
+	Rules        []Rule        `yaml:"rules" json:"rules"`
+	InhibitRules []InhibitRule `yaml:"inhibit_rules,omitempty" json:"inhibit_rules,omitempty"`
 }
 
 // MatchContext 匹配上下文
@@ -119,6 +156,18 @@ type ExecutionContext struct {
 	Rule         *Rule
 	Timestamp    time.Time
 	PreviousTries int // 之前的尝试次数
+	Escalation    bool // 是否为升级通知
+	Group         *GroupNotification // 分组摘要通知，此时Alert为分组中的第一条告警
+	DeliveryID    *uuid.UUID // 经发件箱投递时的投递ID，此时重试由发件箱负责
+}
+
+// GroupNotification 分组摘要通知内容
+type GroupNotification struct {
+	ID       uuid.UUID
+	Key      string
+	Labels   map[string]string
+	Alerts   []*domain.Alert
+	FollowUp bool // 是否为成员变化后的后续通知
 }
 
 // ExecutionResult 执行结果
@@ -138,13 +187,3 @@ type RateLimitKey struct {
 	DeviceID *uuid.UUID
 	Scope    string
 }
-
-// EscalationState 升级状态
-type EscalationState struct {
-	AlertID        uuid.UUID
-	RuleID         string
-	FirstTriggered time.Time
-	LastNotified   time.Time
-	RepeatCount    int
-	Acknowledged   bool
-}
@@ -0,0 +1,366 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/edgelink/backend/cmd/alert-service/internal/integrations"
+	"github.com/edgelink/backend/cmd/alert-service/internal/notifier"
+	"github.com/edgelink/backend/cmd/alert-service/internal/rules"
+	"github.com/edgelink/backend/internal/domain"
+	"github.com/edgelink/backend/internal/metrics"
+	"github.com/edgelink/backend/internal/repository"
+	"github.com/edgelink/backend/internal/tracing"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+)
+
+const (
+	// deliveryPollInterval 发件箱轮询间隔
+	deliveryPollInterval = 2 * time.Second
+	// deliveryLease 投递锁定时长，实例崩溃后超时的投递会被其他实例重新认领
+	deliveryLease = 2 * time.Minute
+	// deliveryAttemptTimeout 单次投递尝试的超时，须小于deliveryLease，避免发送期间锁过期被其他实例重复认领
+	deliveryAttemptTimeout = time.Minute
+	// defaultMaxAttempts 未配置重试策略时的最大尝试次数
+	defaultMaxAttempts = 4
+	// defaultRetryDelay 未配置重试策略时的首次重试间隔
+	defaultRetryDelay = 5 * time.Second
+	// maxRetryDelay 退避上限
+	maxRetryDelay = time.Hour
+)
+
+// DeliveryQueue 基于PostgreSQL发件箱的持久化通知投递队列
+// 规则引擎匹配后只写入投递记录，由后台循环负责发送、重试和死信处理，服务重启不会丢失通知
+type DeliveryQueue struct {
+	repo       repository.NotificationDeliveryRepository
+	alertRepo  repository.AlertRepository
+	deviceRepo repository.DeviceRepository
+	groupRepo  repository.AlertGroupRepository
+	executor   *rules.Executor
+	metrics    *metrics.Metrics
+	logger     *zap.Logger
+}
+
+// NewDeliveryQueue 创建持久化投递队列
+func NewDeliveryQueue(
+	repo repository.NotificationDeliveryRepository,
+	alertRepo repository.AlertRepository,
+	deviceRepo repository.DeviceRepository,
+	groupRepo repository.AlertGroupRepository,
+	executor *rules.Executor,
+	m *metrics.Metrics,
+	logger *zap.Logger,
+) *DeliveryQueue {
+	return &DeliveryQueue{
+		repo:       repo,
+		alertRepo:  alertRepo,
+		deviceRepo: deviceRepo,
+		groupRepo:  groupRepo,
+		executor:   executor,
+		metrics:    m,
+		logger:     logger,
+	}
+}
+
+// Dispatch 将动作写入发件箱（实现rules.ActionDispatcher）
+func (q *DeliveryQueue) Dispatch(ctx context.Context, action *rules.Action, execCtx *rules.ExecutionContext) error {
+	data, err := json.Marshal(action)
+	if err != nil {
+		return fmt.Errorf("failed to encode action: %w", err)
+	}
+
+	var payload domain.JSONB
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return fmt.Errorf("failed to encode action: %w", err)
+	}
+
+	maxAttempts := defaultMaxAttempts
+	if action.RetryPolicy != nil {
+		maxAttempts = action.RetryPolicy.MaxRetries + 1
+	}
+
+	delivery := &domain.NotificationDelivery{
+		AlertID:       execCtx.Alert.ID,
+		RuleID:        execCtx.Rule.ID,
+		Channel:       string(action.Type),
+		Target:        rules.DescribeActionTarget(action),
+		Escalation:    execCtx.Escalation,
+		Action:        payload,
+		Status:        domain.DeliveryStatusPending,
+		MaxAttempts:   maxAttempts,
+		NextAttemptAt: time.Now(),
+		TraceContext:  encodeTraceContext(tracing.Inject(ctx)),
+	}
+	if execCtx.Group != nil {
+		delivery.GroupID = &execCtx.Group.ID
+	}
+
+	if err := q.repo.Enqueue(ctx, delivery); err != nil {
+		return fmt.Errorf("failed to enqueue notification: %w", err)
+	}
+
+	q.logger.Debug("Notification enqueued",
+		zap.String("delivery_id", delivery.ID.String()),
+		zap.String("alert_id", delivery.AlertID.String()),
+		zap.String("channel", delivery.Channel),
+	)
+
+	return nil
+}
+
+// Start 启动投递循环，直到ctx取消
+func (q *DeliveryQueue) Start(ctx context.Context) {
+	q.logger.Info("Notification delivery queue started")
+
+	ticker := time.NewTicker(deliveryPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			q.logger.Info("Notification delivery queue shutting down")
+			return
+		case <-ticker.C:
+			q.poll(ctx)
+		}
+	}
+}
+
+// poll 逐条认领并处理到期的投递，直到没有积压
+// 每次只认领一条：批量认领共用一个锁期限，前面的慢目标会让后面的投递在发送前锁已过期，被其他实例重复发送
+func (q *DeliveryQueue) poll(ctx context.Context) {
+	for ctx.Err() == nil {
+		deliveries, err := q.repo.ClaimDue(ctx, time.Now(), 1, deliveryLease)
+		if err != nil {
+			q.logger.Error("Failed to claim notification deliveries", zap.Error(err))
+			return
+		}
+		if len(deliveries) == 0 {
+			return
+		}
+
+		q.process(ctx, deliveries[0])
+	}
+}
+
+// process 执行一次投递尝试并记录结果
+func (q *DeliveryQueue) process(ctx context.Context, delivery *domain.NotificationDelivery) {
+	// 每次尝试都挂在入队时的追踪下，重试与首次发送可在同一条追踪中查看
+	ctx, span := tracing.Start(tracing.Extract(ctx, decodeTraceContext(delivery.TraceContext)), "notification.deliver",
+		attribute.String("delivery.id", delivery.ID.String()),
+		attribute.String("alert.id", delivery.AlertID.String()),
+		attribute.String("notification.channel", delivery.Channel),
+		attribute.Int("attempt", delivery.Attempts+1),
+	)
+
+	start := time.Now()
+	action, err := decodeAction(delivery.Action)
+	if err == nil {
+		execCtx, cancel := context.WithTimeout(ctx, deliveryAttemptTimeout)
+		err = q.execute(execCtx, delivery, action)
+		cancel()
+	}
+	latency := time.Since(start)
+	tracing.End(span, err)
+
+	now := time.Now()
+	delivery.Attempts++
+	attempt := &domain.NotificationAttempt{
+		DeliveryID: delivery.ID,
+		AlertID:    delivery.AlertID,
+		Channel:    delivery.Channel,
+		Attempt:    delivery.Attempts,
+		Success:    err == nil,
+		LatencyMs:  latency.Milliseconds(),
+	}
+
+	if err == nil {
+		delivery.Status = domain.DeliveryStatusSent
+		delivery.SentAt = &now
+		delivery.LastError = nil
+	} else {
+		errMsg := err.Error()
+		attempt.Error = &errMsg
+		delivery.LastError = &errMsg
+
+		// 集成明确返回不可重试的错误（如配置错误、4xx）时直接进入死信
+		var integrationErr *integrations.IntegrationError
+		permanent := errors.As(err, &integrationErr) && !integrationErr.Retryable
+
+		if permanent || delivery.Attempts >= delivery.MaxAttempts {
+			delivery.Status = domain.DeliveryStatusDead
+			delivery.DeadAt = &now
+		} else {
+			delivery.Status = domain.DeliveryStatusRetrying
+			delivery.NextAttemptAt = now.Add(retryDelay(action, delivery.Attempts, err))
+		}
+	}
+
+	if recordErr := q.repo.RecordAttempt(ctx, delivery, attempt); recordErr != nil {
+		q.logger.Error("Failed to record notification attempt",
+			zap.String("delivery_id", delivery.ID.String()),
+			zap.Error(recordErr),
+		)
+		return
+	}
+
+	q.metrics.RecordNotificationDelivery(delivery.Channel, string(delivery.Status), latency)
+
+	switch delivery.Status {
+	case domain.DeliveryStatusSent:
+		q.logger.Info("Notification delivered",
+			zap.String("delivery_id", delivery.ID.String()),
+			zap.String("alert_id", delivery.AlertID.String()),
+			zap.String("channel", delivery.Channel),
+			zap.Int("attempt", delivery.Attempts),
+			zap.Duration("latency", latency),
+		)
+	case domain.DeliveryStatusDead:
+		q.logger.Error("Notification moved to dead-letter list",
+			zap.String("delivery_id", delivery.ID.String()),
+			zap.String("alert_id", delivery.AlertID.String()),
+			zap.String("channel", delivery.Channel),
+			zap.Int("attempts", delivery.Attempts),
+			zap.Error(err),
+		)
+	default:
+		q.logger.Warn("Notification delivery failed, will retry",
+			zap.String("delivery_id", delivery.ID.String()),
+			zap.String("channel", delivery.Channel),
+			zap.Int("attempt", delivery.Attempts),
+			zap.Time("next_attempt_at", delivery.NextAttemptAt),
+			zap.Error(err),
+		)
+	}
+}
+
+// execute 加载最新的告警与设备并执行动作
+func (q *DeliveryQueue) execute(ctx context.Context, delivery *domain.NotificationDelivery, action *rules.Action) error {
+	alert, err := q.alertRepo.FindByID(ctx, delivery.AlertID)
+	if err != nil {
+		return fmt.Errorf("failed to load alert: %w", err)
+	}
+
+	execCtx := &rules.ExecutionContext{
+		Alert:         alert,
+		Rule:          &rules.Rule{ID: delivery.RuleID},
+		Timestamp:     time.Now(),
+		PreviousTries: delivery.Attempts,
+		Escalation:    delivery.Escalation,
+		DeliveryID:    &delivery.ID,
+	}
+
+	if alert.DeviceID != nil {
+		if device, err := q.deviceRepo.FindByID(ctx, *alert.DeviceID); err == nil {
+			execCtx.Device = device
+		}
+	}
+
+	if delivery.GroupID != nil {
+		group, err := q.loadGroup(ctx, *delivery.GroupID)
+		if err != nil {
+			return err
+		}
+		execCtx.Group = group
+	}
+
+	result := q.executor.Execute(ctx, action, execCtx)
+	return result.Error
+}
+
+// loadGroup 按分组最近一次通知时的成员还原摘要内容
+func (q *DeliveryQueue) loadGroup(ctx context.Context, groupID uuid.UUID) (*rules.GroupNotification, error) {
+	if q.groupRepo == nil {
+		return nil, fmt.Errorf("alert groups are not configured")
+	}
+
+	group, err := q.groupRepo.FindByID(ctx, groupID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load alert group: %w", err)
+	}
+
+	alerts, err := q.alertRepo.FindByIDs(ctx, group.NotifiedAlertIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load alert group members: %w", err)
+	}
+
+	return &rules.GroupNotification{
+		ID:       group.ID,
+		Key:      group.GroupKey,
+		Labels:   group.Labels,
+		Alerts:   alerts,
+		FollowUp: group.NotifyCount > 1,
+	}, nil
+}
+
+// decodeAction 从发件箱记录还原动作定义
+func decodeAction(payload domain.JSONB) (*rules.Action, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode action: %w", err)
+	}
+
+	var action rules.Action
+	if err := json.Unmarshal(data, &action); err != nil {
+		return nil, fmt.Errorf("failed to decode action: %w", err)
+	}
+	return &action, nil
+}
+
+// encodeTraceContext 将追踪上下文转换为JSONB列
+func encodeTraceContext(carrier map[string]string) domain.JSONB {
+	if len(carrier) == 0 {
+		return nil
+	}
+	payload := make(domain.JSONB, len(carrier))
+	for k, v := range carrier {
+		payload[k] = v
+	}
+	return payload
+}
+
+// decodeTraceContext 从JSONB列还原追踪上下文
+func decodeTraceContext(payload domain.JSONB) map[string]string {
+	if len(payload) == 0 {
+		return nil
+	}
+	carrier := make(map[string]string, len(payload))
+	for k, v := range payload {
+		if s, ok := v.(string); ok {
+			carrier[k] = s
+		}
+	}
+	return carrier
+}
+
+// retryDelay 按动作的重试策略计算第attempts次失败后的退避时间，Webhook接收端要求的Retry-After更长时优先
+func retryDelay(action *rules.Action, attempts int, err error) time.Duration {
+	delay := defaultRetryDelay
+	backoff := 2.0
+	if action != nil && action.RetryPolicy != nil {
+		if action.RetryPolicy.RetryDelay > 0 {
+			delay = action.RetryPolicy.RetryDelay
+		}
+		if action.RetryPolicy.BackoffRate >= 1 {
+			backoff = action.RetryPolicy.BackoffRate
+		}
+	}
+
+	next := float64(delay) * math.Pow(backoff, float64(attempts-1))
+
+	var sendErr *notifier.WebhookSendError
+	if errors.As(err, &sendErr) && float64(sendErr.RetryAfter) > next {
+		next = float64(sendErr.RetryAfter)
+	}
+
+	if next > float64(maxRetryDelay) {
+		return maxRetryDelay
+	}
+	return time.Duration(next)
+}
@@ -0,0 +1,202 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/edgelink/backend/cmd/alert-service/internal/integrations"
+	"github.com/edgelink/backend/cmd/alert-service/internal/rules"
+	"github.com/edgelink/backend/internal/domain"
+	"github.com/edgelink/backend/internal/repository"
+	"github.com/edgelink/backend/internal/tracing"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+const (
+	// AlertEventsChannel API网关发布告警事件的Redis频道
+	AlertEventsChannel = "edgelink:events"
+	// alertUpdatedEvent 告警状态变更事件类型
+	alertUpdatedEvent = "alert_updated"
+	// integrationSyncTimeout 单个实例同步状态的超时时间
+	integrationSyncTimeout = 15 * time.Second
+	// integrationSyncClaimPrefix 事件同步认领键前缀，每个副本都会收到同一事件，只有认领成功的副本执行同步
+	integrationSyncClaimPrefix = "edgelink:integration_sync:"
+	// integrationSyncClaimTTL 认领键保留时长，远大于Redis消息在各副本间的投递延迟即可
+	integrationSyncClaimTTL = time.Hour
+)
+
+// alertEventMessage API网关广播的事件（与websocket.BroadcastMessage一致）
+type alertEventMessage struct {
+	EventType    string            `json:"event_type"`
+	Data         json.RawMessage   `json:"data"`
+	TraceContext map[string]string `json:"trace_context,omitempty"`
+}
+
+// alertUpdatedData alert_updated事件的data部分
+type alertUpdatedData struct {
+	EventID uuid.UUID          `json:"event_id"`
+	AlertID uuid.UUID          `json:"alert_id"`
+	Action  string             `json:"action"`
+	Status  domain.AlertStatus `json:"status"`
+	Source  string             `json:"source,omitempty"` // 操作来源平台（slack、pagerduty等），为空表示EdgeLink内部
+}
+
+// IntegrationSync 将告警的确认/解决/重新打开同步到已收到该告警的集成实例
+// 目标实例来自投递记录，只有成功投递过的实例才会收到状态变更（例如PagerDuty自动解决事件）
+type IntegrationSync struct {
+	manager      *integrations.Manager
+	deliveryRepo repository.NotificationDeliveryRepository
+	redisClient  *redis.Client
+	logger       *zap.Logger
+}
+
+// NewIntegrationSync 创建集成状态同步器
+func NewIntegrationSync(
+	manager *integrations.Manager,
+	deliveryRepo repository.NotificationDeliveryRepository,
+	redisClient *redis.Client,
+	logger *zap.Logger,
+) *IntegrationSync {
+	return &IntegrationSync{
+		manager:      manager,
+		deliveryRepo: deliveryRepo,
+		redisClient:  redisClient,
+		logger:       logger,
+	}
+}
+
+// AlertsResolved 同步自动解决的告警（实现generator.ResolutionNotifier）
+func (s *IntegrationSync) AlertsResolved(ctx context.Context, alertIDs []uuid.UUID) {
+	for _, alertID := range alertIDs {
+		s.SyncStatus(ctx, uuid.Nil, alertID, domain.AlertStatusResolved, "")
+	}
+}
+
+// SyncStatus 将告警状态同步到所有已投递的集成实例
+// source为变更来源平台时跳过该类型的实例，避免把平台上的操作再回写给平台本身
+// eventID非空时按(事件, 实例)认领，多个副本订阅同一事件也只有一个副本发送；自动解决只在解决告警的副本上调用，eventID为空
+func (s *IntegrationSync) SyncStatus(ctx context.Context, eventID uuid.UUID, alertID uuid.UUID, status domain.AlertStatus, source string) {
+	targets, err := s.deliveryRepo.FindDeliveredTargets(ctx, alertID, string(rules.ActionTypeIntegration))
+	if err != nil {
+		s.logger.Error("Failed to load integration targets",
+			zap.String("alert_id", alertID.String()),
+			zap.Error(err),
+		)
+		return
+	}
+
+	for _, name := range targets {
+		if !s.manager.Has(name) {
+			continue
+		}
+		if source != "" && s.manager.TypeOf(name) == source {
+			continue
+		}
+		if eventID != uuid.Nil && !s.claim(ctx, eventID, name) {
+			continue
+		}
+
+		syncCtx, cancel := context.WithTimeout(ctx, integrationSyncTimeout)
+		if status == domain.AlertStatusResolved {
+			err = s.manager.ResolveAlertOn(syncCtx, name, alertID.String())
+		} else {
+			err = s.manager.UpdateAlertOn(syncCtx, name, alertID.String(), status)
+		}
+		cancel()
+
+		if err != nil {
+			s.logger.Warn("Failed to sync alert status to integration",
+				zap.String("integration", name),
+				zap.String("alert_id", alertID.String()),
+				zap.String("status", string(status)),
+				zap.Error(err),
+			)
+			continue
+		}
+
+		s.logger.Info("Alert status synced to integration",
+			zap.String("integration", name),
+			zap.String("alert_id", alertID.String()),
+			zap.String("status", string(status)),
+		)
+	}
+}
+
+// Watch 订阅API网关的告警事件，同步管理员的确认/解决/重新打开操作，直到ctx取消
+func (s *IntegrationSync) Watch(ctx context.Context) error {
+	pubsub := s.redisClient.Subscribe(ctx, AlertEventsChannel)
+	defer pubsub.Close()
+
+	if _, err := pubsub.Receive(ctx); err != nil {
+		return fmt.Errorf("failed to subscribe to alert events: %w", err)
+	}
+
+	s.logger.Info("Integration status sync started", zap.String("channel", AlertEventsChannel))
+
+	ch := pubsub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case msg, ok := <-ch:
+			if !ok {
+				return nil
+			}
+			s.handleEvent(ctx, msg.Payload)
+		}
+	}
+}
+
+// handleEvent 处理单条告警事件
+func (s *IntegrationSync) handleEvent(ctx context.Context, payload string) {
+	var msg alertEventMessage
+	if err := json.Unmarshal([]byte(payload), &msg); err != nil {
+		s.logger.Warn("Failed to decode alert event", zap.Error(err))
+		return
+	}
+
+	if msg.EventType != alertUpdatedEvent {
+		return
+	}
+
+	// 延续网关上管理员操作的追踪
+	ctx, span := tracing.StartReceive(ctx, AlertEventsChannel, msg.TraceContext)
+	defer span.End()
+
+	var data alertUpdatedData
+	if err := json.Unmarshal(msg.Data, &data); err != nil {
+		s.logger.Warn("Failed to decode alert_updated event", zap.Error(err))
+		return
+	}
+
+	// 仅状态变更需要同步，指派和评论不影响第三方平台
+	switch data.Status {
+	case domain.AlertStatusAcknowledged, domain.AlertStatusResolved, domain.AlertStatusActive:
+	default:
+		return
+	}
+	if data.Action == "assign" || data.Action == "comment" {
+		return
+	}
+
+	s.SyncStatus(ctx, data.EventID, data.AlertID, data.Status, data.Source)
+}
+
+// claim 认领事件在指定实例上的同步，Redis不可用时放弃同步（宁可漏同步也不重复发送状态变更）
+func (s *IntegrationSync) claim(ctx context.Context, eventID uuid.UUID, name string) bool {
+	key := integrationSyncClaimPrefix + eventID.String() + ":" + name
+	claimed, err := s.redisClient.SetNX(ctx, key, "1", integrationSyncClaimTTL).Result()
+	if err != nil {
+		s.logger.Warn("Failed to claim integration sync",
+			zap.String("event_id", eventID.String()),
+			zap.String("integration", name),
+			zap.Error(err),
+		)
+		return false
+	}
+	return claimed
+}
@@ -25,6 +25,7 @@ type NotificationScheduler struct {
 	emailNotifier   *notifier.EmailNotifier
 	webhookNotifier *notifier.WebhookNotifier
 	ruleEngine      *rules.Engine
+	deliveryQueue   *DeliveryQueue
 	deviceRepo      repository.DeviceRepository
 	logger          *zap.Logger
 
@@ -51,13 +52,21 @@ type SchedulerConfig struct {
 func NewNotificationScheduler(
 	emailNotifier *notifier.EmailNotifier,
 	webhookNotifier *notifier.WebhookNotifier,
+	executor *rules.Executor,
 	deviceRepo repository.DeviceRepository,
+	alertRepo repository.AlertRepository,
+	silenceRepo repository.SilenceRepository,
+	groupRepo repository.AlertGroupRepository,
+	topology *rules.TopologyResolver,
+	escalator *rules.Escalator,
+	deliveryQueue *DeliveryQueue,
 	logger *zap.Logger,
 	config SchedulerConfig,
 ) *NotificationScheduler {
 	scheduler := &NotificationScheduler{
 		emailNotifier:   emailNotifier,
 		webhookNotifier: webhookNotifier,
+		deliveryQueue:   deliveryQueue,
 		deviceRepo:      deviceRepo,
 		logger:          logger,
 		queue:           make([]*NotificationTask, 0),
@@ -69,9 +78,14 @@ func NewNotificationScheduler(
 	// 初始化规则引擎
 	if config.EnableRuleEngine && config.RulesFile != "" {
 		scheduler.ruleEngine = rules.NewEngine(
-			emailNotifier,
-			webhookNotifier,
+			executor,
 			deviceRepo,
+			alertRepo,
+			silenceRepo,
+			groupRepo,
+			topology,
+			escalator,
+			deliveryQueue,
 			logger,
 		)
 
@@ -160,6 +174,19 @@ func (ns *NotificationScheduler) Start(ctx context.Context) {
 		go ns.ruleEngine.StartAutoReload(ctx, engineConfig)
 	}
 
+	// 处理持久化的告警升级与告警分组
+	if ns.ruleEngine != nil {
+		go ns.ruleEngine.RunEscalations(ctx)
+		go ns.ruleEngine.RunGroupFlushes(ctx)
+		go ns.ruleEngine.RunInhibitionReleases(ctx)
+		go ns.ruleEngine.RunSilenceReconciliation(ctx)
+	}
+
+	// 启动持久化投递队列（规则引擎匹配的通知经由发件箱发送）
+	if ns.deliveryQueue != nil {
+		go ns.deliveryQueue.Start(ctx)
+	}
+
 	// 启动worker池（用于传统调度方式）
 	workerCount := 5
 	for i := 0; i < workerCount; i++ {
@@ -223,7 +250,7 @@ func (ns *NotificationScheduler) processTask(ctx context.Context, task *Notifica
 	// 发送Webhook通知
 	webhookURL := "" // TODO: 从配置读取Webhook URL
 	if webhookURL != "" {
-		if err := ns.webhookNotifier.SendAlert(ctx, alert, webhookURL); err != nil {
+		if err := ns.webhookNotifier.SendAlert(ctx, alert, &notifier.WebhookTarget{URL: webhookURL, MaxRetries: -1}); err != nil {
 			ns.logger.Error("Failed to send webhook notification",
 				zap.Error(err),
 				zap.String("alert_id", alert.ID.String()),
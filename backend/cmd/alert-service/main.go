@@ -2,32 +2,50 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
+	"github.com/edgelink/backend/cmd/alert-service/internal/api"
 	"github.com/edgelink/backend/cmd/alert-service/internal/checker"
+	integrationsconfig "github.com/edgelink/backend/cmd/alert-service/internal/config"
 	"github.com/edgelink/backend/cmd/alert-service/internal/deduplication"
 	"github.com/edgelink/backend/cmd/alert-service/internal/generator"
+	"github.com/edgelink/backend/cmd/alert-service/internal/integrations"
+	"github.com/edgelink/backend/cmd/alert-service/internal/integrations/factory"
 	"github.com/edgelink/backend/cmd/alert-service/internal/notifier"
+	"github.com/edgelink/backend/cmd/alert-service/internal/rules"
 	"github.com/edgelink/backend/cmd/alert-service/internal/scheduler"
 	"github.com/edgelink/backend/internal/config"
 	"github.com/edgelink/backend/internal/database"
 	"github.com/edgelink/backend/internal/logger"
+	"github.com/edgelink/backend/internal/metrics"
+	"github.com/edgelink/backend/internal/middleware"
 	"github.com/edgelink/backend/internal/repository"
+	"github.com/edgelink/backend/internal/tracing"
+	"github.com/gin-gonic/gin"
 	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel/attribute"
 	"go.uber.org/fx"
 	"go.uber.org/zap"
 )
 
 func main() {
+	// 子命令
+	if len(os.Args) > 1 && os.Args[1] == "backtest" {
+		os.Exit(runBacktestCommand(os.Args[2:]))
+	}
+
 	app := fx.New(
 		// 配置模块
 		fx.Provide(
 			config.LoadConfig,
 			logger.NewLogger,
+			metrics.New,
 		),
 
 		// 数据库模块
@@ -41,6 +59,22 @@ func main() {
 			repository.NewDeviceRepository,
 			repository.NewAlertRepository,
 			repository.NewSessionRepository,
+			repository.NewSilenceRepository,
+			repository.NewNotificationRuleRepository,
+			repository.NewNotificationDeliveryRepository,
+			repository.NewEmailHistoryRepository,
+			repository.NewAdminUserRepository,
+			repository.NewOnCallScheduleRepository,
+			repository.NewEscalationPolicyRepository,
+			repository.NewAlertEscalationRepository,
+			repository.NewAlertGroupRepository,
+			repository.NewPeerConfigurationRepository,
+			repository.NewWebhookEndpointRepository,
+			repository.NewVirtualNetworkRepository,
+			repository.NewLinkMetricRepository,
+			repository.NewAnomalyThresholdRepository,
+			repository.NewPreSharedKeyRepository,
+			repository.NewDeviceStatusEventRepository,
 		),
 
 		// 告警服务组件
@@ -49,15 +83,43 @@ func main() {
 			NewSchedulerConfig,
 			checker.NewThresholdChecker,
 			generator.NewAlertGenerator,
-			func(cfg *config.Config, logger *zap.Logger) (*notifier.EmailNotifier, error) {
-				return notifier.NewEmailNotifier(cfg, logger)
+			func(cfg *config.Config, historyRepo repository.EmailHistoryRepository, logger *zap.Logger) (*notifier.EmailNotifier, error) {
+				return notifier.NewEmailNotifier(cfg, historyRepo, logger)
 			},
 			notifier.NewWebhookNotifier,
+			NewIntegrationManager,
+			rules.NewExecutor,
+			rules.NewEscalator,
+			rules.NewTopologyResolver,
+			scheduler.NewDeliveryQueue,
+			scheduler.NewIntegrationSync,
+			func(sync *scheduler.IntegrationSync) generator.ResolutionNotifier {
+				return sync
+			},
 			scheduler.NewNotificationScheduler,
+			NewRuleStore,
+			rules.NewBacktester,
 		),
 
+		// HTTP接口
+		fx.Provide(
+			middleware.NewAdminAuth,
+			NewRulesHandler,
+			api.NewServiceHandler,
+			api.SetupRouter,
+		),
+
+		// 分布式追踪（最先注册，停止时最后刷新span）
+		fx.Invoke(tracing.Setup("alert-service")),
+
 		// 启动告警服务
 		fx.Invoke(runAlertService),
+
+		// HTTP服务器
+		fx.Invoke(runHTTPServer),
+
+		// 指标服务器
+		fx.Invoke(metrics.RunServer),
 	)
 
 	app.Run()
@@ -93,6 +155,89 @@ func NewSchedulerConfig(cfg *config.Config) scheduler.SchedulerConfig {
 	}
 }
 
+// NewIntegrationManager 根据集成配置文件创建集成管理器（文件不存在或未启用任何集成时返回空管理器）
+func NewIntegrationManager(cfg *config.Config, logger *zap.Logger) (*integrations.Manager, error) {
+	integrationsCfg, err := integrationsconfig.LoadIntegrationsConfig(cfg.Alert.IntegrationsFile)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			logger.Info("Integrations config not found, integrations disabled",
+				zap.String("file", cfg.Alert.IntegrationsFile),
+			)
+			return integrations.NewManager(logger), nil
+		}
+		return nil, err
+	}
+
+	if integrationsCfg.GetEnabledCount() == 0 {
+		logger.Info("No integrations enabled")
+		return integrations.NewManager(logger), nil
+	}
+
+	return factory.NewFactory(logger).CreateManager(integrationsCfg)
+}
+
+// NewRuleStore 创建数据库规则存储（规则变更同步到调度器的规则引擎）
+func NewRuleStore(
+	repo repository.NotificationRuleRepository,
+	notificationScheduler *scheduler.NotificationScheduler,
+	redisClient *redis.Client,
+	logger *zap.Logger,
+) *rules.RuleStore {
+	return rules.NewRuleStore(repo, notificationScheduler.GetRuleEngine(), redisClient, logger)
+}
+
+// NewRulesHandler 创建规则管理HTTP处理器
+func NewRulesHandler(
+	notificationScheduler *scheduler.NotificationScheduler,
+	ruleStore *rules.RuleStore,
+	backtester *rules.Backtester,
+	logger *zap.Logger,
+) *rules.Handler {
+	return rules.NewHandler(notificationScheduler.GetRuleEngine(), ruleStore, backtester, logger)
+}
+
+// runHTTPServer 启动告警服务HTTP服务器
+func runHTTPServer(
+	lifecycle fx.Lifecycle,
+	log *zap.Logger,
+	cfg *config.Config,
+	router *gin.Engine,
+) {
+	server := &http.Server{
+		Addr:        fmt.Sprintf(":%d", cfg.Server.Port),
+		Handler:     router,
+		ReadTimeout: 15 * time.Second,
+		// 测试通知会同步等待Webhook重试
+		WriteTimeout: 60 * time.Second,
+		IdleTimeout:  60 * time.Second,
+	}
+
+	lifecycle.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			log.Info("Starting Alert Service HTTP server",
+				zap.Int("port", cfg.Server.Port),
+			)
+
+			go func() {
+				if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+					log.Fatal("Failed to start HTTP server", zap.Error(err))
+				}
+			}()
+
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			shutdownCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+			defer cancel()
+
+			if err := server.Shutdown(shutdownCtx); err != nil {
+				log.Error("Failed to gracefully shutdown HTTP server", zap.Error(err))
+				return err
+			}
+			return nil
+		},
+	})
+}
 
 // runAlertService 运行告警服务
 func runAlertService(
@@ -103,15 +248,48 @@ func runAlertService(
 	thresholdChecker *checker.ThresholdChecker,
 	alertGenerator *generator.AlertGenerator,
 	notificationScheduler *scheduler.NotificationScheduler,
+	ruleStore *rules.RuleStore,
+	integrationSync *scheduler.IntegrationSync,
+	webhookNotifier *notifier.WebhookNotifier,
+	integrationManager *integrations.Manager,
+	m *metrics.Metrics,
 ) {
 	ctx, cancel := context.WithCancel(context.Background())
 
+	// 按集成实例导出发送结果
+	integrationManager.OnSend(m.RecordIntegrationSend)
+
+	// Webhook端点被自动停用时产生的告警同样经规则引擎通知管理员
+	webhookNotifier.OnEndpointDisabled(notificationScheduler.Schedule)
+
+	// 链路恢复到基线、抖动或地址池等问题消失后自动解决告警
+	thresholdChecker.SetResolver(alertGenerator)
+
 	lifecycle.Append(fx.Hook{
 		OnStart: func(context.Context) error {
 			log.Info("Starting Alert Service")
 
 			// 启动告警检查循环
-			go runCheckLoop(ctx, log, cfg, thresholdChecker, alertGenerator, notificationScheduler)
+			go runCheckLoop(ctx, log, cfg, m, thresholdChecker, alertGenerator, notificationScheduler)
+
+			// 加载数据库中的组织规则
+			if err := ruleStore.LoadAll(ctx); err != nil {
+				log.Error("Failed to load stored rules", zap.Error(err))
+			}
+
+			// 订阅其他副本的规则变更
+			go func() {
+				if err := ruleStore.Watch(ctx); err != nil && err != context.Canceled {
+					log.Error("Rule change watcher stopped", zap.Error(err))
+				}
+			}()
+
+			// 同步管理员在网关上的确认/解决操作到第三方集成
+			go func() {
+				if err := integrationSync.Watch(ctx); err != nil && err != context.Canceled {
+					log.Error("Integration status sync stopped", zap.Error(err))
+				}
+			}()
 
 			// 启动通知调度器
 			go notificationScheduler.Start(ctx)
@@ -141,6 +319,7 @@ func runCheckLoop(
 	ctx context.Context,
 	log *zap.Logger,
 	cfg *config.Config,
+	m *metrics.Metrics,
 	thresholdChecker *checker.ThresholdChecker,
 	alertGenerator *generator.AlertGenerator,
 	notificationScheduler *scheduler.NotificationScheduler,
@@ -162,13 +341,20 @@ func runCheckLoop(
 			return
 
 		case <-ticker.C:
+			start := time.Now()
+			var taskErr error
+
+			// 每轮检查作为一条根追踪，告警生成、规则匹配和通知入队都挂在其下
+			checkCtx, span := tracing.Start(ctx, "alert.check")
+
 			// 执行健康检查
-			issues := thresholdChecker.CheckAll(ctx)
+			issues := thresholdChecker.CheckAll(checkCtx)
 
 			// 为每个问题生成告警
 			for _, issue := range issues {
-				alert, err := alertGenerator.GenerateAlert(ctx, issue)
+				alert, err := alertGenerator.GenerateAlert(checkCtx, issue)
 				if err != nil {
+					taskErr = err
 					log.Error("Failed to generate alert",
 						zap.Error(err),
 						zap.String("issue_type", issue.Type),
@@ -182,7 +368,8 @@ func runCheckLoop(
 				}
 
 				// 调度通知
-				if err := notificationScheduler.Schedule(ctx, alert); err != nil {
+				if err := notificationScheduler.Schedule(checkCtx, alert); err != nil {
+					taskErr = err
 					log.Error("Failed to schedule notification",
 						zap.Error(err),
 						zap.String("alert_id", alert.ID.String()),
@@ -195,6 +382,12 @@ func runCheckLoop(
 					zap.Int("issues_found", len(issues)),
 				)
 			}
+
+			span.SetAttributes(attribute.Int("alert.issues", len(issues)))
+			tracing.End(span, taskErr)
+
+			// 任一告警生成或调度失败即记为失败
+			m.RecordTask("alert_check", time.Since(start), taskErr)
 		}
 	}
 }
@@ -5,6 +5,7 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/edgelink/backend/internal/audit"
 	"github.com/edgelink/backend/internal/domain"
 	"github.com/edgelink/backend/internal/repository"
 	"github.com/gin-gonic/gin"
@@ -46,6 +47,7 @@ func NewAdminHandler(
 // @Param        virtual_network_id  query    string  false  "虚拟网络ID"
 // @Param        online             query    string  false  "在线状态过滤 (true/false)"
 // @Param        platform           query    string  false  "平台过滤"
+// @Param        approval_status    query    string  false  "审批状态过滤 (pending/approved/rejected)"
 // @Param        limit              query    int     false  "返回数量限制"
 // @Param        offset             query    int     false  "偏移量"
 // @Success      200  {object}  DeviceListResponse
@@ -58,6 +60,7 @@ func (h *AdminHandler) GetDevices(c *gin.Context) {
 		VirtualNetworkID *uuid.UUID
 		Online           *bool
 		Platform         *domain.Platform
+		ApprovalStatus   *domain.DeviceApprovalStatus
 		Limit            int
 		Offset           int
 	}
@@ -87,6 +90,12 @@ func (h *AdminHandler) GetDevices(c *gin.Context) {
 		filters.Platform = &platform
 	}
 
+	// 审批状态过滤
+	if statusStr := c.Query("approval_status"); statusStr != "" {
+		status := domain.DeviceApprovalStatus(statusStr)
+		filters.ApprovalStatus = &status
+	}
+
 	// 分页参数
 	filters.Limit, _ = strconv.Atoi(c.DefaultQuery("limit", "50"))
 	filters.Offset, _ = strconv.Atoi(c.DefaultQuery("offset", "0"))
@@ -128,6 +137,17 @@ func (h *AdminHandler) GetDevices(c *gin.Context) {
 		devices = filtered
 	}
 
+	// 应用审批状态过滤
+	if filters.ApprovalStatus != nil {
+		filtered := make([]*domain.Device, 0)
+		for _, d := range devices {
+			if d.ApprovalStatus == *filters.ApprovalStatus {
+				filtered = append(filtered, d)
+			}
+		}
+		devices = filtered
+	}
+
 	// 应用分页
 	total := len(devices)
 	if filters.Offset < len(devices) {
@@ -226,14 +246,15 @@ func (h *AdminHandler) CreateVirtualNetwork(c *gin.Context) {
 
 	// 创建虚拟网络
 	network := &domain.VirtualNetwork{
-		ID:             uuid.New(),
-		OrganizationID: organizationID,
-		Name:           req.Name,
-		CIDR:           req.CIDR,
-		GatewayIP:      req.GatewayIP,
-		DNSServers:     req.DNSServers,
-		CreatedAt:      time.Now(),
-		UpdatedAt:      time.Now(),
+		ID:              uuid.New(),
+		OrganizationID:  organizationID,
+		Name:            req.Name,
+		CIDR:            req.CIDR,
+		GatewayIP:       req.GatewayIP,
+		DNSServers:      req.DNSServers,
+		RequireApproval: req.RequireApproval,
+		CreatedAt:       time.Now(),
+		UpdatedAt:       time.Now(),
 	}
 
 	if err := h.virtualNetworkRepo.Create(c.Request.Context(), network); err != nil {
@@ -279,6 +300,10 @@ func (h *AdminHandler) DeleteDevice(c *gin.Context) {
 		})
 		return
 	}
+	if device.VirtualNetwork != nil {
+		c.Set(audit.ContextOrganizationID, device.VirtualNetwork.OrganizationID)
+	}
+	c.Set(audit.ContextBeforeState, device.AuditState())
 
 	// 删除设备
 	if err := h.deviceRepo.Delete(c.Request.Context(), device.ID); err != nil {
@@ -294,54 +319,34 @@ func (h *AdminHandler) DeleteDevice(c *gin.Context) {
 	})
 }
 
-// GetAlerts godoc
-// @Summary      获取告警列表
-// @Description  获取告警列表，支持过滤和分页
+// GetAuditLogs godoc
+// @Summary      获取审计日志
+// @Description  获取审计日志列表，支持过滤和分页
 // @Tags         admin
 // @Accept       json
 // @Produce      json
-// @Param        device_id   query    string  false  "设备ID"
-// @Param        severity    query    string  false  "严重程度 (critical/high/medium/low)"
-// @Param        status      query    string  false  "状态 (active/acknowledged/resolved)"
-// @Param        type        query    string  false  "告警类型"
-// @Param        limit       query    int     false  "返回数量限制"
-// @Param        offset      query    int     false  "偏移量"
-// @Success      200  {object}  AlertListResponse
+// @Param        organization_id  query    string  false  "组织ID"
+// @Param        actor_id         query    string  false  "操作者ID"
+// @Param        action           query    string  false  "操作类型"
+// @Param        resource_type    query    string  false  "资源类型"
+// @Param        resource_id      query    string  false  "资源ID"
+// @Param        start_time       query    string  false  "开始时间 (RFC3339)"
+// @Param        end_time         query    string  false  "结束时间 (RFC3339)"
+// @Param        limit            query    int     false  "返回数量限制"
+// @Param        offset           query    int     false  "偏移量"
+// @Success      200  {object}  AuditLogListResponse
 // @Failure      400  {object}  ErrorResponse
 // @Failure      500  {object}  ErrorResponse
-// @Router       /api/v1/admin/alerts [get]
-func (h *AdminHandler) GetAlerts(c *gin.Context) {
-	filters := &repository.AlertFilters{
+// @Router       /api/v1/admin/audit-logs [get]
+func (h *AdminHandler) GetAuditLogs(c *gin.Context) {
+	filters := &repository.AuditLogFilters{
 		Limit:  50,
 		Offset: 0,
 	}
 
 	// 解析过滤参数
-	if deviceIDStr := c.Query("device_id"); deviceIDStr != "" {
-		deviceID, err := uuid.Parse(deviceIDStr)
-		if err != nil {
-			c.JSON(http.StatusBadRequest, ErrorResponse{
-				Error:   "invalid_device_id",
-				Message: "device_id must be a valid UUID",
-			})
-			return
-		}
-		filters.DeviceID = &deviceID
-	}
-
-	if severityStr := c.Query("severity"); severityStr != "" {
-		severity := domain.Severity(severityStr)
-		filters.Severity = &severity
-	}
-
-	if statusStr := c.Query("status"); statusStr != "" {
-		status := domain.AlertStatus(statusStr)
-		filters.Status = &status
-	}
-
-	if typeStr := c.Query("type"); typeStr != "" {
-		alertType := domain.AlertType(typeStr)
-		filters.AlertType = &alertType
+	if !parseAuditLogFilters(c, filters) {
+		return
 	}
 
 	if limitStr := c.Query("limit"); limitStr != "" {
@@ -352,8 +357,8 @@ func (h *AdminHandler) GetAlerts(c *gin.Context) {
 		filters.Offset, _ = strconv.Atoi(offsetStr)
 	}
 
-	// 查询告警
-	alerts, total, err := h.alertRepo.FindByFilters(c.Request.Context(), filters)
+	// 查询审计日志
+	logs, total, err := h.auditLogRepo.FindByFilters(c.Request.Context(), filters)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, ErrorResponse{
 			Error:   "query_failed",
@@ -362,97 +367,16 @@ func (h *AdminHandler) GetAlerts(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, AlertListResponse{
-		Alerts: alerts,
+	c.JSON(http.StatusOK, AuditLogListResponse{
+		Logs:   logs,
 		Total:  int(total),
 		Limit:  filters.Limit,
 		Offset: filters.Offset,
 	})
 }
 
-// AcknowledgeAlert godoc
-// @Summary      确认告警
-// @Description  标记告警为已确认状态
-// @Tags         admin
-// @Accept       json
-// @Produce      json
-// @Param        alert_id  path  string  true  "告警ID"
-// @Param        request   body  AcknowledgeAlertRequest  true  "确认请求"
-// @Success      200  {object}  SuccessResponse
-// @Failure      400  {object}  ErrorResponse
-// @Failure      404  {object}  ErrorResponse
-// @Failure      500  {object}  ErrorResponse
-// @Router       /api/v1/admin/alerts/{alert_id}/acknowledge [post]
-func (h *AdminHandler) AcknowledgeAlert(c *gin.Context) {
-	alertIDStr := c.Param("alert_id")
-	alertID, err := uuid.Parse(alertIDStr)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{
-			Error:   "invalid_alert_id",
-			Message: "alert_id must be a valid UUID",
-		})
-		return
-	}
-
-	var req AcknowledgeAlertRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{
-			Error:   "invalid_request",
-			Message: err.Error(),
-		})
-		return
-	}
-
-	// 解析确认者ID
-	acknowledgedBy, err := uuid.Parse(req.AcknowledgedBy)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{
-			Error:   "invalid_acknowledged_by",
-			Message: "acknowledged_by must be a valid UUID",
-		})
-		return
-	}
-
-	// 确认告警
-	if err := h.alertRepo.Acknowledge(c.Request.Context(), alertID, acknowledgedBy); err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{
-			Error:   "acknowledgement_failed",
-			Message: err.Error(),
-		})
-		return
-	}
-
-	c.JSON(http.StatusOK, SuccessResponse{
-		Message: "alert acknowledged successfully",
-	})
-}
-
-// GetAuditLogs godoc
-// @Summary      获取审计日志
-// @Description  获取审计日志列表，支持过滤和分页
-// @Tags         admin
-// @Accept       json
-// @Produce      json
-// @Param        organization_id  query    string  false  "组织ID"
-// @Param        actor_id         query    string  false  "操作者ID"
-// @Param        action           query    string  false  "操作类型"
-// @Param        resource_type    query    string  false  "资源类型"
-// @Param        resource_id      query    string  false  "资源ID"
-// @Param        start_time       query    string  false  "开始时间 (RFC3339)"
-// @Param        end_time         query    string  false  "结束时间 (RFC3339)"
-// @Param        limit            query    int     false  "返回数量限制"
-// @Param        offset           query    int     false  "偏移量"
-// @Success      200  {object}  AuditLogListResponse
-// @Failure      400  {object}  ErrorResponse
-// @Failure      500  {object}  ErrorResponse
-// @Router       /api/v1/admin/audit-logs [get]
-func (h *AdminHandler) GetAuditLogs(c *gin.Context) {
-	filters := &repository.AuditLogFilters{
-		Limit:  50,
-		Offset: 0,
-	}
-
-	// 解析过滤参数
+// parseAuditLogFilters 解析审计日志过滤参数，参数无效时写入400响应并返回false
+func parseAuditLogFilters(c *gin.Context, filters *repository.AuditLogFilters) bool {
 	if orgIDStr := c.Query("organization_id"); orgIDStr != "" {
 		orgID, err := uuid.Parse(orgIDStr)
 		if err != nil {
@@ -460,7 +384,7 @@ func (h *AdminHandler) GetAuditLogs(c *gin.Context) {
 				Error:   "invalid_organization_id",
 				Message: "organization_id must be a valid UUID",
 			})
-			return
+			return false
 		}
 		filters.OrganizationID = &orgID
 	}
@@ -472,7 +396,7 @@ func (h *AdminHandler) GetAuditLogs(c *gin.Context) {
 				Error:   "invalid_actor_id",
 				Message: "actor_id must be a valid UUID",
 			})
-			return
+			return false
 		}
 		filters.ActorID = &actorID
 	}
@@ -493,7 +417,7 @@ func (h *AdminHandler) GetAuditLogs(c *gin.Context) {
 				Error:   "invalid_resource_id",
 				Message: "resource_id must be a valid UUID",
 			})
-			return
+			return false
 		}
 		filters.ResourceID = &resourceID
 	}
@@ -505,7 +429,7 @@ func (h *AdminHandler) GetAuditLogs(c *gin.Context) {
 				Error:   "invalid_start_time",
 				Message: "start_time must be in RFC3339 format",
 			})
-			return
+			return false
 		}
 		filters.StartTime = &startTime
 	}
@@ -517,35 +441,12 @@ func (h *AdminHandler) GetAuditLogs(c *gin.Context) {
 				Error:   "invalid_end_time",
 				Message: "end_time must be in RFC3339 format",
 			})
-			return
+			return false
 		}
 		filters.EndTime = &endTime
 	}
 
-	if limitStr := c.Query("limit"); limitStr != "" {
-		filters.Limit, _ = strconv.Atoi(limitStr)
-	}
-
-	if offsetStr := c.Query("offset"); offsetStr != "" {
-		filters.Offset, _ = strconv.Atoi(offsetStr)
-	}
-
-	// 查询审计日志
-	logs, total, err := h.auditLogRepo.FindByFilters(c.Request.Context(), filters)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{
-			Error:   "query_failed",
-			Message: err.Error(),
-		})
-		return
-	}
-
-	c.JSON(http.StatusOK, AuditLogListResponse{
-		Logs:   logs,
-		Total:  int(total),
-		Limit:  filters.Limit,
-		Offset: filters.Offset,
-	})
+	return true
 }
 
 // GetDeviceById godoc
@@ -587,9 +488,10 @@ func (h *AdminHandler) GetDeviceById(c *gin.Context) {
 	}
 
 	// 获取设备的告警
+	activeStatus := domain.AlertStatusActive
 	alertFilters := &repository.AlertFilters{
 		DeviceID: &deviceID,
-		Status:   &domain.AlertStatusActive,
+		Status:   &activeStatus,
 		Limit:    10,
 		Offset:   0,
 	}
@@ -664,17 +566,17 @@ func (h *AdminHandler) GetDevicePeers(c *gin.Context) {
 			PeerName:         peerDevice.Name,
 			PeerIP:           peerDevice.VirtualIP,
 			Status:           status,
-			Latency:          session.LatencyMs,
+			Latency:          session.AvgLatencyMs,
 			LastHandshake:    session.LastHandshakeAt,
-			ConnectionType:   session.ConnectionType,
-			BytesSent:        session.BytesSentA,
-			BytesReceived:    session.BytesReceivedA,
+			ConnectionType:   string(session.ConnectionType),
+			BytesSent:        session.BytesSent,
+			BytesReceived:    session.BytesReceived,
 		}
 
-		// 如果当前设备是B，交换发送/接收字节
+		// 会话字节数以设备A视角记录，当前设备是B时交换发送/接收字节
 		if session.DeviceBID == deviceID {
-			peer.BytesSent = session.BytesSentB
-			peer.BytesReceived = session.BytesReceivedB
+			peer.BytesSent = session.BytesReceived
+			peer.BytesReceived = session.BytesSent
 		}
 
 		peers = append(peers, peer)
@@ -766,15 +668,15 @@ func (h *AdminHandler) GetDeviceMetrics(c *gin.Context) {
 	for _, session := range sessions {
 		metric := &DeviceMetricsPoint{
 			Timestamp:     session.StartedAt,
-			Latency:       session.LatencyMs,
-			BytesSent:     session.BytesSentA,
-			BytesReceived: session.BytesReceivedA,
+			Latency:       session.AvgLatencyMs,
+			BytesSent:     session.BytesSent,
+			BytesReceived: session.BytesReceived,
 		}
 
-		// 如果当前设备是B，交换发送/接收字节
+		// 会话字节数以设备A视角记录，当前设备是B时交换发送/接收字节
 		if session.DeviceBID == deviceID {
-			metric.BytesSent = session.BytesSentB
-			metric.BytesReceived = session.BytesReceivedB
+			metric.BytesSent = session.BytesReceived
+			metric.BytesReceived = session.BytesSent
 		}
 
 		metrics = append(metrics, metric)
@@ -1110,8 +1012,7 @@ func (h *AdminHandler) GetTopologyDevices(c *gin.Context) {
 	var err error
 
 	if orgIDStr := c.Query("organization_id"); orgIDStr != "" {
-		orgID, err := uuid.Parse(orgIDStr)
-		if err != nil {
+		if _, err := uuid.Parse(orgIDStr); err != nil {
 			c.JSON(http.StatusBadRequest, ErrorResponse{
 				Error:   "invalid_organization_id",
 				Message: "organization_id must be a valid UUID",
@@ -1120,13 +1021,15 @@ func (h *AdminHandler) GetTopologyDevices(c *gin.Context) {
 		}
 		// 这里需要在Repository中添加FindByOrganization方法
 		// 暂时使用现有方法
-		devicesSlice, err := h.deviceRepo.FindByVirtualNetwork(c.Request.Context(), uuid.Nil, nil)
+		var devicesSlice []domain.Device
+		devicesSlice, err = h.deviceRepo.FindByVirtualNetwork(c.Request.Context(), uuid.Nil, nil)
 		devices = make([]*domain.Device, len(devicesSlice))
 		for i := range devicesSlice {
 			devices[i] = &devicesSlice[i]
 		}
 	} else {
-		devicesSlice, err := h.deviceRepo.FindByVirtualNetwork(c.Request.Context(), uuid.Nil, nil)
+		var devicesSlice []domain.Device
+		devicesSlice, err = h.deviceRepo.FindByVirtualNetwork(c.Request.Context(), uuid.Nil, nil)
 		devices = make([]*domain.Device, len(devicesSlice))
 		for i := range devicesSlice {
 			devices[i] = &devicesSlice[i]
@@ -1192,11 +1095,9 @@ func (h *AdminHandler) GetTopologyPeers(c *gin.Context) {
 			DeviceAID:      session.DeviceAID,
 			DeviceBID:      session.DeviceBID,
 			ConnectionType: string(session.ConnectionType),
-			Latency:        session.LatencyMs,
+			Latency:        session.AvgLatencyMs,
 			LastHandshake:  session.LastHandshakeAt,
 			StartedAt:      session.StartedAt,
-			EndpointA:      session.EndpointA,
-			EndpointB:      session.EndpointB,
 		}
 		peers = append(peers, peer)
 	}
@@ -1222,22 +1123,12 @@ type VirtualNetworkListResponse struct {
 }
 
 type CreateVirtualNetworkRequest struct {
-	OrganizationID string   `json:"organization_id" binding:"required"`
-	Name           string   `json:"name" binding:"required"`
-	CIDR           string   `json:"cidr" binding:"required"`
-	GatewayIP      string   `json:"gateway_ip" binding:"required"`
-	DNSServers     []string `json:"dns_servers"`
-}
-
-type AlertListResponse struct {
-	Alerts []*domain.Alert `json:"alerts"`
-	Total  int             `json:"total"`
-	Limit  int             `json:"limit"`
-	Offset int             `json:"offset"`
-}
-
-type AcknowledgeAlertRequest struct {
-	AcknowledgedBy string `json:"acknowledged_by" binding:"required"`
+	OrganizationID  string   `json:"organization_id" binding:"required"`
+	Name            string   `json:"name" binding:"required"`
+	CIDR            string   `json:"cidr" binding:"required"`
+	GatewayIP       string   `json:"gateway_ip" binding:"required"`
+	DNSServers      []string `json:"dns_servers"`
+	RequireApproval bool     `json:"require_approval"` // 新设备需经管理员审批才加入网络
 }
 
 type AuditLogListResponse struct {
@@ -0,0 +1,834 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/edgelink/backend/cmd/api-gateway/internal/websocket"
+	"github.com/edgelink/backend/internal/domain"
+	"github.com/edgelink/backend/internal/repository"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// 告警变更动作（随alert_updated事件下发，供告警服务同步第三方集成）
+const (
+	AlertActionAcknowledge = "acknowledge"
+	AlertActionResolve     = "resolve"
+	AlertActionReopen      = "reopen"
+	AlertActionAssign      = "assign"
+	AlertActionComment     = "comment"
+)
+
+// AlertHandler 告警生命周期处理器
+type AlertHandler struct {
+	alertRepo          repository.AlertRepository
+	alertCommentRepo   repository.AlertCommentRepository
+	adminUserRepo      repository.AdminUserRepository
+	virtualNetworkRepo repository.VirtualNetworkRepository
+	broadcaster        *websocket.Broadcaster
+	logger             *zap.Logger
+}
+
+// NewAlertHandler 创建AlertHandler实例
+func NewAlertHandler(
+	alertRepo repository.AlertRepository,
+	alertCommentRepo repository.AlertCommentRepository,
+	adminUserRepo repository.AdminUserRepository,
+	virtualNetworkRepo repository.VirtualNetworkRepository,
+	broadcaster *websocket.Broadcaster,
+	logger *zap.Logger,
+) *AlertHandler {
+	return &AlertHandler{
+		alertRepo:          alertRepo,
+		alertCommentRepo:   alertCommentRepo,
+		adminUserRepo:      adminUserRepo,
+		virtualNetworkRepo: virtualNetworkRepo,
+		broadcaster:        broadcaster,
+		logger:             logger,
+	}
+}
+
+// GetAlerts godoc
+// @Summary      获取告警列表
+// @Description  获取告警列表，支持过滤和分页
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        device_id   query    string  false  "设备ID"
+// @Param        severity    query    string  false  "严重程度 (critical/high/medium/low)"
+// @Param        status      query    string  false  "状态 (active/acknowledged/resolved)"
+// @Param        type        query    string  false  "告警类型"
+// @Param        assigned_to query    string  false  "指派的管理员ID"
+// @Param        silenced    query    bool    false  "是否被静默"
+// @Param        inhibited   query    bool    false  "是否被根因告警抑制"
+// @Param        inhibited_by query   string  false  "根因告警ID（列出被其抑制的告警）"
+// @Param        limit       query    int     false  "返回数量限制"
+// @Param        offset      query    int     false  "偏移量"
+// @Success      200  {object}  AlertListResponse
+// @Failure      400  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/v1/admin/alerts [get]
+func (h *AlertHandler) GetAlerts(c *gin.Context) {
+	filters := &repository.AlertFilters{
+		Limit:  50,
+		Offset: 0,
+	}
+
+	// 解析过滤参数
+	if deviceIDStr := c.Query("device_id"); deviceIDStr != "" {
+		deviceID, err := uuid.Parse(deviceIDStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "invalid_device_id",
+				Message: "device_id must be a valid UUID",
+			})
+			return
+		}
+		filters.DeviceID = &deviceID
+	}
+
+	if severityStr := c.Query("severity"); severityStr != "" {
+		severity := domain.Severity(severityStr)
+		filters.Severity = &severity
+	}
+
+	if statusStr := c.Query("status"); statusStr != "" {
+		status := domain.AlertStatus(statusStr)
+		filters.Status = &status
+	}
+
+	if typeStr := c.Query("type"); typeStr != "" {
+		alertType := domain.AlertType(typeStr)
+		filters.AlertType = &alertType
+	}
+
+	if assignedToStr := c.Query("assigned_to"); assignedToStr != "" {
+		assignedTo, err := uuid.Parse(assignedToStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "invalid_assigned_to",
+				Message: "assigned_to must be a valid UUID",
+			})
+			return
+		}
+		filters.AssignedTo = &assignedTo
+	}
+
+	if silencedStr := c.Query("silenced"); silencedStr != "" {
+		silenced, err := strconv.ParseBool(silencedStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "invalid_silenced",
+				Message: "silenced must be a boolean",
+			})
+			return
+		}
+		filters.Silenced = &silenced
+	}
+
+	if inhibitedStr := c.Query("inhibited"); inhibitedStr != "" {
+		inhibited, err := strconv.ParseBool(inhibitedStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "invalid_inhibited",
+				Message: "inhibited must be a boolean",
+			})
+			return
+		}
+		filters.Inhibited = &inhibited
+	}
+
+	if inhibitedByStr := c.Query("inhibited_by"); inhibitedByStr != "" {
+		inhibitedBy, err := uuid.Parse(inhibitedByStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "invalid_inhibited_by",
+				Message: "inhibited_by must be a valid UUID",
+			})
+			return
+		}
+		filters.InhibitedBy = &inhibitedBy
+	}
+
+	if limitStr := c.Query("limit"); limitStr != "" {
+		filters.Limit, _ = strconv.Atoi(limitStr)
+	}
+
+	if offsetStr := c.Query("offset"); offsetStr != "" {
+		filters.Offset, _ = strconv.Atoi(offsetStr)
+	}
+
+	// 查询告警
+	alerts, total, err := h.alertRepo.FindByFilters(c.Request.Context(), filters)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "query_failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, AlertListResponse{
+		Alerts: alerts,
+		Total:  int(total),
+		Limit:  filters.Limit,
+		Offset: filters.Offset,
+	})
+}
+
+// AcknowledgeAlert godoc
+// @Summary      确认告警
+// @Description  标记告警为已确认状态
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        alert_id  path  string  true  "告警ID"
+// @Param        request   body  AcknowledgeAlertRequest  true  "确认请求"
+// @Success      200  {object}  SuccessResponse
+// @Failure      400  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/v1/admin/alerts/{alert_id}/acknowledge [post]
+func (h *AlertHandler) AcknowledgeAlert(c *gin.Context) {
+	alertIDStr := c.Param("alert_id")
+	alertID, err := uuid.Parse(alertIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_alert_id",
+			Message: "alert_id must be a valid UUID",
+		})
+		return
+	}
+
+	var req AcknowledgeAlertRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	// 解析确认者ID
+	acknowledgedBy, err := uuid.Parse(req.AcknowledgedBy)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_acknowledged_by",
+			Message: "acknowledged_by must be a valid UUID",
+		})
+		return
+	}
+
+	alert, ok := h.findAlert(c, alertID)
+	if !ok {
+		return
+	}
+
+	// 确认告警
+	if err := h.alertRepo.Acknowledge(c.Request.Context(), alert.ID, acknowledgedBy); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "acknowledgement_failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	h.publishAlertUpdate(c.Request.Context(), alert.ID, AlertActionAcknowledge, &acknowledgedBy)
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Message: "alert acknowledged successfully",
+	})
+}
+
+// ResolveAlert godoc
+// @Summary      解决告警
+// @Description  标记告警为已解决状态
+// @Tags         admin
+// @Produce      json
+// @Param        alert_id  path  string  true  "告警ID"
+// @Success      200  {object}  SuccessResponse
+// @Failure      400  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse
+// @Failure      409  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/v1/admin/alerts/{alert_id}/resolve [post]
+func (h *AlertHandler) ResolveAlert(c *gin.Context) {
+	alertID, ok := parseAlertID(c)
+	if !ok {
+		return
+	}
+
+	alert, ok := h.findAlert(c, alertID)
+	if !ok {
+		return
+	}
+
+	if alert.Status == domain.AlertStatusResolved {
+		c.JSON(http.StatusConflict, ErrorResponse{
+			Error:   "alert_already_resolved",
+			Message: "alert is already resolved",
+		})
+		return
+	}
+
+	if err := h.alertRepo.Resolve(c.Request.Context(), alert.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "resolve_failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	h.publishAlertUpdate(c.Request.Context(), alert.ID, AlertActionResolve, actorIDFromHeader(c))
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Message: "alert resolved successfully",
+	})
+}
+
+// ReopenAlert godoc
+// @Summary      重新打开告警
+// @Description  将已确认或已解决的告警恢复为活跃状态
+// @Tags         admin
+// @Produce      json
+// @Param        alert_id  path  string  true  "告警ID"
+// @Success      200  {object}  SuccessResponse
+// @Failure      400  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse
+// @Failure      409  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/v1/admin/alerts/{alert_id}/reopen [post]
+func (h *AlertHandler) ReopenAlert(c *gin.Context) {
+	alertID, ok := parseAlertID(c)
+	if !ok {
+		return
+	}
+
+	alert, ok := h.findAlert(c, alertID)
+	if !ok {
+		return
+	}
+
+	if alert.Status == domain.AlertStatusActive {
+		c.JSON(http.StatusConflict, ErrorResponse{
+			Error:   "alert_already_active",
+			Message: "alert is already active",
+		})
+		return
+	}
+
+	if err := h.alertRepo.Reopen(c.Request.Context(), alert.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "reopen_failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	h.publishAlertUpdate(c.Request.Context(), alert.ID, AlertActionReopen, actorIDFromHeader(c))
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Message: "alert reopened successfully",
+	})
+}
+
+// AssignAlert godoc
+// @Summary      指派告警
+// @Description  将告警指派给管理员，assignee_id为空时取消指派
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        alert_id  path  string  true  "告警ID"
+// @Param        request   body  AssignAlertRequest  true  "指派请求"
+// @Success      200  {object}  SuccessResponse
+// @Failure      400  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/v1/admin/alerts/{alert_id}/assign [post]
+func (h *AlertHandler) AssignAlert(c *gin.Context) {
+	alertID, ok := parseAlertID(c)
+	if !ok {
+		return
+	}
+
+	var req AssignAlertRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	var assignee *uuid.UUID
+	if req.AssigneeID != "" {
+		assigneeID, err := uuid.Parse(req.AssigneeID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "invalid_assignee_id",
+				Message: "assignee_id must be a valid UUID",
+			})
+			return
+		}
+
+		// 只能指派给有效的管理员
+		user, err := h.adminUserRepo.FindByID(c.Request.Context(), assigneeID)
+		if err != nil || !user.IsActive {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "invalid_assignee",
+				Message: "assignee must be an active admin user",
+			})
+			return
+		}
+		assignee = &assigneeID
+	}
+
+	alert, ok := h.findAlert(c, alertID)
+	if !ok {
+		return
+	}
+
+	if err := h.alertRepo.Assign(c.Request.Context(), alert.ID, assignee); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "assign_failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	h.publishAlertUpdate(c.Request.Context(), alert.ID, AlertActionAssign, actorIDFromHeader(c))
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Message: "alert assignment updated successfully",
+	})
+}
+
+// GetAlertComments godoc
+// @Summary      获取告警评论
+// @Description  按时间顺序获取告警的所有评论
+// @Tags         admin
+// @Produce      json
+// @Param        alert_id  path  string  true  "告警ID"
+// @Success      200  {object}  AlertCommentListResponse
+// @Failure      400  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/v1/admin/alerts/{alert_id}/comments [get]
+func (h *AlertHandler) GetAlertComments(c *gin.Context) {
+	alertID, ok := parseAlertID(c)
+	if !ok {
+		return
+	}
+
+	if _, ok := h.findAlert(c, alertID); !ok {
+		return
+	}
+
+	comments, err := h.alertCommentRepo.FindByAlertID(c.Request.Context(), alertID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "query_failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, AlertCommentListResponse{
+		Comments: comments,
+		Total:    len(comments),
+	})
+}
+
+// AddAlertComment godoc
+// @Summary      添加告警评论
+// @Description  为告警添加一条带时间戳的评论
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        alert_id  path  string  true  "告警ID"
+// @Param        request   body  AddAlertCommentRequest  true  "评论内容"
+// @Success      201  {object}  domain.AlertComment
+// @Failure      400  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/v1/admin/alerts/{alert_id}/comments [post]
+func (h *AlertHandler) AddAlertComment(c *gin.Context) {
+	alertID, ok := parseAlertID(c)
+	if !ok {
+		return
+	}
+
+	var req AddAlertCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	authorID, err := uuid.Parse(req.AuthorID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_author_id",
+			Message: "author_id must be a valid UUID",
+		})
+		return
+	}
+
+	body := strings.TrimSpace(req.Body)
+	if body == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "empty_comment",
+			Message: "comment body must not be empty",
+		})
+		return
+	}
+
+	if _, ok := h.findAlert(c, alertID); !ok {
+		return
+	}
+
+	comment := &domain.AlertComment{
+		ID:        uuid.New(),
+		AlertID:   alertID,
+		AuthorID:  authorID,
+		Body:      body,
+		CreatedAt: time.Now(),
+	}
+
+	if err := h.alertCommentRepo.Create(c.Request.Context(), comment); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "comment_failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	h.publishAlertUpdate(c.Request.Context(), alertID, AlertActionComment, &authorID)
+
+	c.JSON(http.StatusCreated, comment)
+}
+
+// BulkAcknowledgeAlerts godoc
+// @Summary      批量确认告警
+// @Description  确认所有符合过滤条件的活跃告警
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        request  body  BulkAcknowledgeAlertsRequest  true  "批量确认请求"
+// @Success      200  {object}  BulkAlertOperationResponse
+// @Failure      400  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/v1/admin/alerts/bulk/acknowledge [post]
+func (h *AlertHandler) BulkAcknowledgeAlerts(c *gin.Context) {
+	var req BulkAcknowledgeAlertsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	acknowledgedBy, err := uuid.Parse(req.AcknowledgedBy)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_acknowledged_by",
+			Message: "acknowledged_by must be a valid UUID",
+		})
+		return
+	}
+
+	filters, ok := req.Filter.toAlertFilters(c)
+	if !ok {
+		return
+	}
+
+	ids, err := h.alertRepo.AcknowledgeByFilters(c.Request.Context(), filters, acknowledgedBy)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "acknowledgement_failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	for _, id := range ids {
+		h.publishAlertUpdate(c.Request.Context(), id, AlertActionAcknowledge, &acknowledgedBy)
+	}
+
+	c.JSON(http.StatusOK, BulkAlertOperationResponse{
+		AlertIDs: ids,
+		Affected: len(ids),
+	})
+}
+
+// BulkResolveAlerts godoc
+// @Summary      批量解决告警
+// @Description  解决所有符合过滤条件的未解决告警
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        request  body  BulkResolveAlertsRequest  true  "批量解决请求"
+// @Success      200  {object}  BulkAlertOperationResponse
+// @Failure      400  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/v1/admin/alerts/bulk/resolve [post]
+func (h *AlertHandler) BulkResolveAlerts(c *gin.Context) {
+	var req BulkResolveAlertsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	filters, ok := req.Filter.toAlertFilters(c)
+	if !ok {
+		return
+	}
+
+	ids, err := h.alertRepo.ResolveByFilters(c.Request.Context(), filters)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "resolve_failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	actorID := actorIDFromHeader(c)
+	for _, id := range ids {
+		h.publishAlertUpdate(c.Request.Context(), id, AlertActionResolve, actorID)
+	}
+
+	c.JSON(http.StatusOK, BulkAlertOperationResponse{
+		AlertIDs: ids,
+		Affected: len(ids),
+	})
+}
+
+// findAlert 查找告警，失败时写入错误响应
+func (h *AlertHandler) findAlert(c *gin.Context, alertID uuid.UUID) (*domain.Alert, bool) {
+	alert, err := h.alertRepo.FindByID(c.Request.Context(), alertID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Error:   "alert_not_found",
+				Message: "alert not found",
+			})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "query_failed",
+			Message: err.Error(),
+		})
+		return nil, false
+	}
+	return alert, true
+}
+
+// publishAlertUpdate 广播告警更新事件
+// 网关只负责发布事件，不直接调用集成：告警服务的scheduler.IntegrationSync订阅同一频道，
+// 对已投递过该告警的集成实例调用UpdateAlert/ResolveAlert，使PagerDuty/Opsgenie等保持同步
+func (h *AlertHandler) publishAlertUpdate(ctx context.Context, alertID uuid.UUID, action string, actorID *uuid.UUID) {
+	h.publishAlertUpdateFrom(ctx, alertID, action, actorID, "")
+}
+
+// publishAlertUpdateFrom 广播来自指定平台的告警更新事件，告警服务不会再把状态同步回该平台
+func (h *AlertHandler) publishAlertUpdateFrom(ctx context.Context, alertID uuid.UUID, action string, actorID *uuid.UUID, source string) {
+	// 重新读取告警以携带变更后的状态
+	alert, err := h.alertRepo.FindByID(ctx, alertID)
+	if err != nil {
+		h.logger.Warn("Failed to load alert for update event",
+			zap.String("alert_id", alertID.String()),
+			zap.Error(err),
+		)
+		return
+	}
+
+	event := AlertUpdateEvent{
+		EventID:   uuid.New(),
+		AlertID:   alert.ID,
+		Action:    action,
+		Status:    alert.Status,
+		ActorID:   actorID,
+		Source:    source,
+		Alert:     alert,
+		Timestamp: time.Now(),
+	}
+
+	if err := h.broadcaster.PublishAlertUpdated(ctx, h.alertOrgID(ctx, alert), event); err != nil {
+		h.logger.Error("Failed to publish alert update",
+			zap.String("alert_id", alertID.String()),
+			zap.String("action", action),
+			zap.Error(err),
+		)
+	}
+}
+
+// alertOrgID 通过告警关联设备所在的虚拟网络解析组织ID
+func (h *AlertHandler) alertOrgID(ctx context.Context, alert *domain.Alert) string {
+	if alert.Device == nil {
+		return ""
+	}
+
+	vn, err := h.virtualNetworkRepo.FindByID(ctx, alert.Device.VirtualNetworkID)
+	if err != nil {
+		return ""
+	}
+	return vn.OrganizationID.String()
+}
+
+// parseAlertID 解析路径中的告警ID，失败时写入错误响应
+func parseAlertID(c *gin.Context) (uuid.UUID, bool) {
+	alertID, err := uuid.Parse(c.Param("alert_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_alert_id",
+			Message: "alert_id must be a valid UUID",
+		})
+		return uuid.Nil, false
+	}
+	return alertID, true
+}
+
+// actorIDFromHeader 从X-Actor-ID请求头提取操作者ID（与审计中间件一致）
+func actorIDFromHeader(c *gin.Context) *uuid.UUID {
+	actorID, err := uuid.Parse(c.GetHeader("X-Actor-ID"))
+	if err != nil {
+		return nil
+	}
+	return &actorID
+}
+
+// toAlertFilters 转换为仓储过滤条件，要求至少指定一个条件以避免误操作全部告警
+func (f *AlertFilterRequest) toAlertFilters(c *gin.Context) (*repository.AlertFilters, bool) {
+	filters := &repository.AlertFilters{
+		StartTime: f.StartTime,
+		EndTime:   f.EndTime,
+	}
+
+	if f.DeviceID != "" {
+		deviceID, err := uuid.Parse(f.DeviceID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "invalid_device_id",
+				Message: "device_id must be a valid UUID",
+			})
+			return nil, false
+		}
+		filters.DeviceID = &deviceID
+	}
+
+	if f.AssignedTo != "" {
+		assignedTo, err := uuid.Parse(f.AssignedTo)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "invalid_assigned_to",
+				Message: "assigned_to must be a valid UUID",
+			})
+			return nil, false
+		}
+		filters.AssignedTo = &assignedTo
+	}
+
+	if f.Severity != "" {
+		severity := domain.Severity(f.Severity)
+		filters.Severity = &severity
+	}
+
+	if f.Type != "" {
+		alertType := domain.AlertType(f.Type)
+		filters.AlertType = &alertType
+	}
+
+	if f.Status != "" {
+		status := domain.AlertStatus(f.Status)
+		filters.Status = &status
+	}
+
+	if filters.DeviceID == nil && filters.AssignedTo == nil && filters.Severity == nil &&
+		filters.AlertType == nil && filters.Status == nil && filters.StartTime == nil && filters.EndTime == nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "empty_filter",
+			Message: "at least one filter field is required",
+		})
+		return nil, false
+	}
+
+	return filters, true
+}
+
+// 请求/响应类型定义
+
+type AlertListResponse struct {
+	Alerts []*domain.Alert `json:"alerts"`
+	Total  int             `json:"total"`
+	Limit  int             `json:"limit"`
+	Offset int             `json:"offset"`
+}
+
+type AcknowledgeAlertRequest struct {
+	AcknowledgedBy string `json:"acknowledged_by" binding:"required"`
+}
+
+type AssignAlertRequest struct {
+	AssigneeID string `json:"assignee_id"`
+}
+
+type AddAlertCommentRequest struct {
+	AuthorID string `json:"author_id" binding:"required"`
+	Body     string `json:"body" binding:"required"`
+}
+
+type AlertCommentListResponse struct {
+	Comments []*domain.AlertComment `json:"comments"`
+	Total    int                    `json:"total"`
+}
+
+// 批量操作过滤条件
+type AlertFilterRequest struct {
+	DeviceID   string     `json:"device_id"`
+	Severity   string     `json:"severity"`
+	Type       string     `json:"type"`
+	Status     string     `json:"status"`
+	AssignedTo string     `json:"assigned_to"`
+	StartTime  *time.Time `json:"start_time"`
+	EndTime    *time.Time `json:"end_time"`
+}
+
+type BulkAcknowledgeAlertsRequest struct {
+	AcknowledgedBy string             `json:"acknowledged_by" binding:"required"`
+	Filter         AlertFilterRequest `json:"filter"`
+}
+
+type BulkResolveAlertsRequest struct {
+	Filter AlertFilterRequest `json:"filter"`
+}
+
+type BulkAlertOperationResponse struct {
+	AlertIDs []uuid.UUID `json:"alert_ids"`
+	Affected int         `json:"affected"`
+}
+
+// 告警更新事件（alert_updated事件的data部分）
+type AlertUpdateEvent struct {
+	EventID   uuid.UUID          `json:"event_id"` // 告警服务的各副本据此保证每个事件只同步一次
+	AlertID   uuid.UUID          `json:"alert_id"`
+	Action    string             `json:"action"`
+	Status    domain.AlertStatus `json:"status"`
+	ActorID   *uuid.UUID         `json:"actor_id,omitempty"`
+	Source    string             `json:"source,omitempty"` // 经第三方平台回调变更时的平台名称
+	Alert     *domain.Alert      `json:"alert"`
+	Timestamp time.Time          `json:"timestamp"`
+}
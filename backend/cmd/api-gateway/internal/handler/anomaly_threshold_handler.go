@@ -0,0 +1,451 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/edgelink/backend/internal/domain"
+	"github.com/edgelink/backend/internal/repository"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// AnomalyThresholdHandler 链路异常检测阈值覆盖处理器
+type AnomalyThresholdHandler struct {
+	thresholdRepo  repository.AnomalyThresholdRepository
+	vnRepo         repository.VirtualNetworkRepository
+	deviceRepo     repository.DeviceRepository
+	linkMetricRepo repository.LinkMetricRepository
+	logger         *zap.Logger
+}
+
+// NewAnomalyThresholdHandler 创建AnomalyThresholdHandler实例
+func NewAnomalyThresholdHandler(
+	thresholdRepo repository.AnomalyThresholdRepository,
+	vnRepo repository.VirtualNetworkRepository,
+	deviceRepo repository.DeviceRepository,
+	linkMetricRepo repository.LinkMetricRepository,
+	logger *zap.Logger,
+) *AnomalyThresholdHandler {
+	return &AnomalyThresholdHandler{
+		thresholdRepo:  thresholdRepo,
+		vnRepo:         vnRepo,
+		deviceRepo:     deviceRepo,
+		linkMetricRepo: linkMetricRepo,
+		logger:         logger,
+	}
+}
+
+// AnomalyThresholdRequest 创建/更新阈值覆盖请求
+// 更新时作用范围（组织、网络、标签）不可修改，阈值字段整体替换
+type AnomalyThresholdRequest struct {
+	OrganizationID     string   `json:"organization_id"`
+	VirtualNetworkID   *string  `json:"virtual_network_id"`
+	Tag                *string  `json:"tag"`
+	Priority           *int     `json:"priority"`
+	Enabled            *bool    `json:"enabled"`
+	Sigma              *float64 `json:"sigma"`
+	SustainSeconds     *int     `json:"sustain_seconds"`
+	MinLatencyDeltaMs  *int     `json:"min_latency_delta_ms"`
+	MinPacketLossDelta *float64 `json:"min_packet_loss_delta"`
+	LatencyMaxMs       *int     `json:"latency_max_ms"`
+	PacketLossMax      *float64 `json:"packet_loss_max"`
+	OfflineAfterSecs   *int     `json:"offline_after_seconds"`
+	Comment            string   `json:"comment"`
+}
+
+// AnomalyThresholdListResponse 阈值覆盖列表响应
+type AnomalyThresholdListResponse struct {
+	Thresholds []*domain.AnomalyThreshold `json:"thresholds"`
+	Total      int                        `json:"total"`
+}
+
+// LinkBaselineResponse 设备链路基线响应
+type LinkBaselineResponse struct {
+	DeviceID  uuid.UUID            `json:"device_id"`
+	Baselines []LinkBaselineItem   `json:"baselines"`
+	Samples   []*domain.LinkMetric `json:"samples,omitempty"`
+}
+
+// LinkBaselineItem 单条链路基线
+type LinkBaselineItem struct {
+	PeerDeviceID   uuid.UUID  `json:"peer_device_id"`
+	Metric         string     `json:"metric"`
+	Mean           float64    `json:"mean"`
+	StdDev         float64    `json:"stddev"`
+	SampleCount    int        `json:"sample_count"`
+	LastValue      *float64   `json:"last_value,omitempty"`
+	LastSampleAt   *time.Time `json:"last_sample_at,omitempty"`
+	AnomalousSince *time.Time `json:"anomalous_since,omitempty"`
+	Alerting       bool       `json:"alerting"`
+}
+
+// GetAnomalyThresholds godoc
+// @Summary      获取异常检测阈值覆盖列表
+// @Description  按组织列出网络/标签级别的延迟与丢包阈值覆盖
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        organization_id  query  string  false  "组织ID"
+// @Success      200  {object}  AnomalyThresholdListResponse
+// @Failure      400  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/v1/admin/anomaly-thresholds [get]
+func (h *AnomalyThresholdHandler) GetAnomalyThresholds(c *gin.Context) {
+	var orgID *uuid.UUID
+	if orgIDStr := c.Query("organization_id"); orgIDStr != "" {
+		id, err := uuid.Parse(orgIDStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "invalid_organization_id",
+				Message: "organization_id must be a valid UUID",
+			})
+			return
+		}
+		orgID = &id
+	}
+
+	thresholds, err := h.thresholdRepo.List(c.Request.Context(), orgID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "query_failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, AnomalyThresholdListResponse{
+		Thresholds: thresholds,
+		Total:      len(thresholds),
+	})
+}
+
+// GetAnomalyThreshold godoc
+// @Summary      获取阈值覆盖详情
+// @Tags         admin
+// @Produce      json
+// @Param        threshold_id  path  string  true  "阈值覆盖ID"
+// @Success      200  {object}  domain.AnomalyThreshold
+// @Failure      400  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse
+// @Router       /api/v1/admin/anomaly-thresholds/{threshold_id} [get]
+func (h *AnomalyThresholdHandler) GetAnomalyThreshold(c *gin.Context) {
+	threshold, ok := h.findThreshold(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, threshold)
+}
+
+// CreateAnomalyThreshold godoc
+// @Summary      创建阈值覆盖
+// @Description  为虚拟网络或设备标签（二选一）覆盖基线偏离倍数、持续时长、绝对上限和离线判定时间
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        request  body  AnomalyThresholdRequest  true  "阈值覆盖"
+// @Success      201  {object}  domain.AnomalyThreshold
+// @Failure      400  {object}  ErrorResponse
+// @Failure      409  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/v1/admin/anomaly-thresholds [post]
+func (h *AnomalyThresholdHandler) CreateAnomalyThreshold(c *gin.Context) {
+	var req AnomalyThresholdRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	orgID, err := uuid.Parse(req.OrganizationID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_organization_id",
+			Message: "organization_id must be a valid UUID",
+		})
+		return
+	}
+
+	now := time.Now()
+	threshold := &domain.AnomalyThreshold{
+		ID:             uuid.New(),
+		OrganizationID: orgID,
+		Tag:            req.Tag,
+		Priority:       100,
+		Enabled:        true,
+		CreatedBy:      actorIDFromHeader(c),
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+
+	if req.VirtualNetworkID != nil {
+		vnID, err := uuid.Parse(*req.VirtualNetworkID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "invalid_virtual_network_id",
+				Message: "virtual_network_id must be a valid UUID",
+			})
+			return
+		}
+
+		vn, err := h.vnRepo.FindByID(c.Request.Context(), vnID)
+		if err != nil || vn.OrganizationID != orgID {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "invalid_virtual_network_id",
+				Message: "virtual network not found in organization",
+			})
+			return
+		}
+		threshold.VirtualNetworkID = &vnID
+	}
+
+	req.applyTo(threshold)
+
+	if err := threshold.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_threshold",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if err := h.thresholdRepo.Create(c.Request.Context(), threshold); err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) || strings.Contains(err.Error(), "duplicate key") {
+			c.JSON(http.StatusConflict, ErrorResponse{
+				Error:   "threshold_exists",
+				Message: "a threshold override already exists for this scope",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "create_failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	h.logger.Info("Anomaly threshold created",
+		zap.String("threshold_id", threshold.ID.String()),
+		zap.String("organization_id", orgID.String()),
+	)
+
+	c.JSON(http.StatusCreated, threshold)
+}
+
+// UpdateAnomalyThreshold godoc
+// @Summary      更新阈值覆盖
+// @Description  整体替换阈值字段，作用范围不可修改
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        threshold_id  path  string                   true  "阈值覆盖ID"
+// @Param        request       body  AnomalyThresholdRequest  true  "阈值覆盖"
+// @Success      200  {object}  domain.AnomalyThreshold
+// @Failure      400  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/v1/admin/anomaly-thresholds/{threshold_id} [put]
+func (h *AnomalyThresholdHandler) UpdateAnomalyThreshold(c *gin.Context) {
+	var req AnomalyThresholdRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	threshold, ok := h.findThreshold(c)
+	if !ok {
+		return
+	}
+
+	req.applyTo(threshold)
+	threshold.UpdatedAt = time.Now()
+
+	if err := threshold.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_threshold",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if err := h.thresholdRepo.Update(c.Request.Context(), threshold); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "update_failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	h.logger.Info("Anomaly threshold updated", zap.String("threshold_id", threshold.ID.String()))
+
+	c.JSON(http.StatusOK, threshold)
+}
+
+// DeleteAnomalyThreshold godoc
+// @Summary      删除阈值覆盖
+// @Tags         admin
+// @Produce      json
+// @Param        threshold_id  path  string  true  "阈值覆盖ID"
+// @Success      204
+// @Failure      400  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/v1/admin/anomaly-thresholds/{threshold_id} [delete]
+func (h *AnomalyThresholdHandler) DeleteAnomalyThreshold(c *gin.Context) {
+	threshold, ok := h.findThreshold(c)
+	if !ok {
+		return
+	}
+
+	if err := h.thresholdRepo.Delete(c.Request.Context(), threshold.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "delete_failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	h.logger.Info("Anomaly threshold deleted", zap.String("threshold_id", threshold.ID.String()))
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetDeviceLinkBaselines godoc
+// @Summary      获取设备链路基线
+// @Description  返回设备到各对等端的延迟/丢包基线，include_samples=true时附带最近一小时的原始样本
+// @Tags         admin
+// @Produce      json
+// @Param        device_id        path   string  true   "设备ID"
+// @Param        include_samples  query  bool    false  "是否返回原始样本"
+// @Param        limit            query  int     false  "样本数量限制"
+// @Success      200  {object}  LinkBaselineResponse
+// @Failure      400  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/v1/admin/devices/{device_id}/link-baselines [get]
+func (h *AnomalyThresholdHandler) GetDeviceLinkBaselines(c *gin.Context) {
+	deviceID, err := uuid.Parse(c.Param("device_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_device_id",
+			Message: "device_id must be a valid UUID",
+		})
+		return
+	}
+
+	if _, err := h.deviceRepo.FindByID(c.Request.Context(), deviceID); err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:   "device_not_found",
+			Message: "Device not found",
+		})
+		return
+	}
+
+	baselines, err := h.linkMetricRepo.FindBaselinesByDevices(c.Request.Context(), []uuid.UUID{deviceID})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "query_failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	resp := LinkBaselineResponse{
+		DeviceID:  deviceID,
+		Baselines: make([]LinkBaselineItem, 0, len(baselines)),
+	}
+	for _, b := range baselines {
+		resp.Baselines = append(resp.Baselines, LinkBaselineItem{
+			PeerDeviceID:   b.PeerDeviceID,
+			Metric:         string(b.Metric),
+			Mean:           b.Mean,
+			StdDev:         b.StdDev(),
+			SampleCount:    b.SampleCount,
+			LastValue:      b.LastValue,
+			LastSampleAt:   b.LastSampleAt,
+			AnomalousSince: b.AnomalousSince,
+			Alerting:       b.Alerting,
+		})
+	}
+
+	if c.Query("include_samples") == "true" {
+		limit := 500
+		if limitStr := c.Query("limit"); limitStr != "" {
+			if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 5000 {
+				limit = l
+			}
+		}
+
+		now := time.Now()
+		samples, err := h.linkMetricRepo.ListByDevice(c.Request.Context(), deviceID, now.Add(-time.Hour), now, limit)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, ErrorResponse{
+				Error:   "query_failed",
+				Message: err.Error(),
+			})
+			return
+		}
+		resp.Samples = samples
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// findThreshold 解析路径参数并加载阈值覆盖，失败时已写入响应
+func (h *AnomalyThresholdHandler) findThreshold(c *gin.Context) (*domain.AnomalyThreshold, bool) {
+	thresholdID, err := uuid.Parse(c.Param("threshold_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_threshold_id",
+			Message: "threshold_id must be a valid UUID",
+		})
+		return nil, false
+	}
+
+	threshold, err := h.thresholdRepo.FindByID(c.Request.Context(), thresholdID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Error:   "threshold_not_found",
+				Message: "Anomaly threshold not found",
+			})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "query_failed",
+			Message: err.Error(),
+		})
+		return nil, false
+	}
+
+	return threshold, true
+}
+
+// applyTo 将请求中的阈值字段写入覆盖记录
+func (req *AnomalyThresholdRequest) applyTo(threshold *domain.AnomalyThreshold) {
+	if req.Priority != nil {
+		threshold.Priority = *req.Priority
+	}
+	if req.Enabled != nil {
+		threshold.Enabled = *req.Enabled
+	}
+	threshold.Sigma = req.Sigma
+	threshold.SustainSeconds = req.SustainSeconds
+	threshold.MinLatencyDeltaMs = req.MinLatencyDeltaMs
+	threshold.MinPacketLossDelta = req.MinPacketLossDelta
+	threshold.LatencyMaxMs = req.LatencyMaxMs
+	threshold.PacketLossMax = req.PacketLossMax
+	threshold.OfflineAfterSecs = req.OfflineAfterSecs
+	threshold.Comment = req.Comment
+}
@@ -0,0 +1,317 @@
+package handler
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/edgelink/backend/internal/audit"
+	"github.com/edgelink/backend/internal/domain"
+	"github.com/edgelink/backend/internal/repository"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// auditExportBatchSize 导出时每批读取的记录数
+const auditExportBatchSize = 1000
+
+// AuditHandler 审计日志哈希链校验与导出处理器
+type AuditHandler struct {
+	verifier       *audit.ChainVerifier
+	auditLogRepo   repository.AuditLogRepository
+	checkpointRepo repository.AuditCheckpointRepository
+	logger         *zap.Logger
+}
+
+// NewAuditHandler 创建AuditHandler实例
+func NewAuditHandler(
+	verifier *audit.ChainVerifier,
+	auditLogRepo repository.AuditLogRepository,
+	checkpointRepo repository.AuditCheckpointRepository,
+	logger *zap.Logger,
+) *AuditHandler {
+	return &AuditHandler{
+		verifier:       verifier,
+		auditLogRepo:   auditLogRepo,
+		checkpointRepo: checkpointRepo,
+		logger:         logger,
+	}
+}
+
+// AuditVerifyResponse 哈希链校验响应
+type AuditVerifyResponse struct {
+	Valid   bool                        `json:"valid"`
+	Results []*audit.VerificationResult `json:"results"`
+}
+
+// AuditCheckpointListResponse 检查点列表响应
+type AuditCheckpointListResponse struct {
+	Checkpoints []*domain.AuditCheckpoint `json:"checkpoints"`
+	Total       int                       `json:"total"`
+}
+
+// VerifyAuditChain godoc
+// @Summary      校验审计日志哈希链
+// @Description  从链首逐条重算哈希并比对签名检查点，返回每个组织第一个断裂的位置；未指定组织时校验全部组织
+// @Tags         admin
+// @Produce      json
+// @Param        organization_id  query  string  false  "组织ID"
+// @Success      200  {object}  AuditVerifyResponse
+// @Failure      400  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/v1/admin/audit-logs/verify [get]
+func (h *AuditHandler) VerifyAuditChain(c *gin.Context) {
+	var results []*audit.VerificationResult
+	if orgIDStr := c.Query("organization_id"); orgIDStr != "" {
+		orgID, err := uuid.Parse(orgIDStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "invalid_organization_id",
+				Message: "organization_id must be a valid UUID",
+			})
+			return
+		}
+
+		result, err := h.verifier.Verify(c.Request.Context(), orgID)
+		if err != nil {
+			h.logger.Error("Failed to verify audit chain", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, ErrorResponse{
+				Error:   "verification_failed",
+				Message: err.Error(),
+			})
+			return
+		}
+		results = []*audit.VerificationResult{result}
+	} else {
+		var err error
+		results, err = h.verifier.VerifyAll(c.Request.Context())
+		if err != nil {
+			h.logger.Error("Failed to verify audit chains", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, ErrorResponse{
+				Error:   "verification_failed",
+				Message: err.Error(),
+			})
+			return
+		}
+	}
+
+	valid := true
+	for _, result := range results {
+		valid = valid && result.Valid
+	}
+
+	c.JSON(http.StatusOK, AuditVerifyResponse{
+		Valid:   valid,
+		Results: results,
+	})
+}
+
+// GetAuditCheckpoints godoc
+// @Summary      获取审计日志签名检查点
+// @Description  按序号升序列出组织哈希链的Ed25519签名检查点，可用于离线校验
+// @Tags         admin
+// @Produce      json
+// @Param        organization_id  query  string  true  "组织ID"
+// @Success      200  {object}  AuditCheckpointListResponse
+// @Failure      400  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/v1/admin/audit-logs/checkpoints [get]
+func (h *AuditHandler) GetAuditCheckpoints(c *gin.Context) {
+	orgID, err := uuid.Parse(c.Query("organization_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_organization_id",
+			Message: "organization_id is required and must be a valid UUID",
+		})
+		return
+	}
+
+	checkpoints, err := h.checkpointRepo.FindByOrganization(c.Request.Context(), orgID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "query_failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, AuditCheckpointListResponse{
+		Checkpoints: checkpoints,
+		Total:       len(checkpoints),
+	})
+}
+
+// auditCSVHeader CSV导出列
+var auditCSVHeader = []string{
+	"id", "organization_id", "sequence", "created_at", "actor_id", "action",
+	"resource_type", "resource_id", "ip_address", "user_agent",
+	"before_state", "after_state", "prev_hash", "hash",
+}
+
+// ExportAuditLogs godoc
+// @Summary      导出审计日志
+// @Description  以CSV或NDJSON格式流式导出符合条件的全部审计日志（按创建时间升序），用于调查取证；过滤参数与审计日志列表相同，不分页
+// @Tags         admin
+// @Produce      text/csv
+// @Produce      application/x-ndjson
+// @Param        format           query    string  false  "导出格式：csv（默认）或 ndjson"
+// @Param        organization_id  query    string  false  "组织ID"
+// @Param        actor_id         query    string  false  "操作者ID"
+// @Param        action           query    string  false  "操作类型"
+// @Param        resource_type    query    string  false  "资源类型"
+// @Param        resource_id      query    string  false  "资源ID"
+// @Param        start_time       query    string  false  "开始时间 (RFC3339)"
+// @Param        end_time         query    string  false  "结束时间 (RFC3339)"
+// @Success      200  {file}    file
+// @Failure      400  {object}  ErrorResponse
+// @Router       /api/v1/admin/audit-logs/export [get]
+func (h *AuditHandler) ExportAuditLogs(c *gin.Context) {
+	format := c.DefaultQuery("format", "csv")
+	if format != "csv" && format != "ndjson" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_format",
+			Message: "format must be csv or ndjson",
+		})
+		return
+	}
+
+	filters := &repository.AuditLogFilters{}
+	if !parseAuditLogFilters(c, filters) {
+		return
+	}
+
+	// 导出可能远超服务器写超时，取消本次响应的写截止时间
+	if err := http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{}); err != nil {
+		h.logger.Warn("Failed to clear write deadline for audit export", zap.Error(err))
+	}
+
+	filename := fmt.Sprintf("audit-logs-%s.%s", time.Now().UTC().Format("20060102T150405Z"), format)
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.Header("Cache-Control", "no-store")
+
+	var (
+		writeBatch func([]*domain.AuditLog) error
+		flush      func() error
+	)
+	switch format {
+	case "ndjson":
+		c.Header("Content-Type", "application/x-ndjson")
+		encoder := json.NewEncoder(c.Writer)
+		writeBatch = func(logs []*domain.AuditLog) error {
+			for _, log := range logs {
+				if err := encoder.Encode(log); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		flush = func() error { return nil }
+	default:
+		c.Header("Content-Type", "text/csv; charset=utf-8")
+		writer := csv.NewWriter(c.Writer)
+		writeBatch = func(logs []*domain.AuditLog) error {
+			for _, log := range logs {
+				record, err := auditCSVRecord(log)
+				if err != nil {
+					return err
+				}
+				if err := writer.Write(record); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		flush = func() error {
+			writer.Flush()
+			return writer.Error()
+		}
+		if err := writer.Write(auditCSVHeader); err != nil {
+			return
+		}
+	}
+
+	c.Status(http.StatusOK)
+	var exported int
+	err := h.auditLogRepo.ExportByFilters(c.Request.Context(), filters, auditExportBatchSize, func(logs []*domain.AuditLog) error {
+		if err := writeBatch(logs); err != nil {
+			return err
+		}
+		if err := flush(); err != nil {
+			return err
+		}
+		c.Writer.Flush()
+		exported += len(logs)
+		return nil
+	})
+	if err == nil {
+		err = flush()
+	}
+	if err != nil {
+		// 响应头已发送，无法再返回错误状态码，客户端会看到截断的文件
+		h.logger.Error("Audit log export aborted",
+			zap.Int("exported", exported),
+			zap.Error(err),
+		)
+		c.Abort()
+		return
+	}
+
+	h.logger.Info("Audit logs exported",
+		zap.String("format", format),
+		zap.Int("records", exported),
+	)
+}
+
+// auditCSVRecord 将审计日志转换为一行CSV，前后状态以JSON字符串保存
+func auditCSVRecord(log *domain.AuditLog) ([]string, error) {
+	optional := func(value *string) string {
+		if value == nil {
+			return ""
+		}
+		return *value
+	}
+	state := func(value *domain.JSONB) (string, error) {
+		if value == nil {
+			return "", nil
+		}
+		data, err := json.Marshal(value)
+		return string(data), err
+	}
+
+	var actorID, sequence string
+	if log.ActorID != nil {
+		actorID = log.ActorID.String()
+	}
+	if log.Sequence != nil {
+		sequence = strconv.FormatInt(*log.Sequence, 10)
+	}
+	beforeState, err := state(log.BeforeState)
+	if err != nil {
+		return nil, err
+	}
+	afterState, err := state(log.AfterState)
+	if err != nil {
+		return nil, err
+	}
+
+	return []string{
+		log.ID.String(),
+		log.OrganizationID.String(),
+		sequence,
+		log.CreatedAt.UTC().Format(time.RFC3339Nano),
+		actorID,
+		log.Action,
+		string(log.ResourceType),
+		log.ResourceID.String(),
+		optional(log.IPAddress),
+		optional(log.UserAgent),
+		beforeState,
+		afterState,
+		optional(log.PrevHash),
+		optional(log.Hash),
+	}, nil
+}
@@ -0,0 +1,756 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/edgelink/backend/internal/config"
+	"github.com/edgelink/backend/internal/domain"
+	"github.com/edgelink/backend/internal/repository"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	// maxCallbackBodySize 回调请求体大小上限
+	maxCallbackBodySize = 1 << 20
+	// slackCallbackID Slack告警消息的callback_id
+	slackCallbackID = "edgelink_alert"
+)
+
+var (
+	errUnmappedUser      = errors.New("external user is not linked to an EdgeLink account")
+	errCallbackForbidden = errors.New("user is not allowed to change this alert")
+	errCallbackNoAlert   = errors.New("alert not found")
+)
+
+// CallbackHandler 外部平台回调处理器
+// 处理Slack按钮、PagerDuty/Opsgenie Webhook与Teams可操作消息，在EdgeLink中确认/解决告警
+type CallbackHandler struct {
+	alertHandler  *AlertHandler
+	alertRepo     repository.AlertRepository
+	groupRepo     repository.AlertGroupRepository
+	adminUserRepo repository.AdminUserRepository
+	identityRepo  repository.ExternalIdentityRepository
+	auditLogRepo  repository.AuditLogRepository
+	cfg           config.CallbackConfig
+	teamsVerifier *teamsTokenVerifier
+	logger        *zap.Logger
+}
+
+// NewCallbackHandler 创建回调处理器
+func NewCallbackHandler(
+	cfg *config.Config,
+	alertHandler *AlertHandler,
+	alertRepo repository.AlertRepository,
+	groupRepo repository.AlertGroupRepository,
+	adminUserRepo repository.AdminUserRepository,
+	identityRepo repository.ExternalIdentityRepository,
+	auditLogRepo repository.AuditLogRepository,
+	logger *zap.Logger,
+) *CallbackHandler {
+	return &CallbackHandler{
+		alertHandler:  alertHandler,
+		alertRepo:     alertRepo,
+		groupRepo:     groupRepo,
+		adminUserRepo: adminUserRepo,
+		identityRepo:  identityRepo,
+		auditLogRepo:  auditLogRepo,
+		cfg:           cfg.Callbacks,
+		teamsVerifier: newTeamsTokenVerifier(cfg.Callbacks.TeamsIssuer, cfg.Callbacks.TeamsAudience, cfg.Callbacks.TeamsJWKSURL),
+		logger:        logger,
+	}
+}
+
+// callbackAction 回调解析出的告警操作
+type callbackAction struct {
+	provider   domain.ExternalProvider
+	externalID string // 平台用户ID
+	email      string // 平台用户邮箱（未建立映射时按邮箱匹配管理员）
+	action     string // acknowledge 或 resolve
+	targetID   uuid.UUID
+}
+
+// SlackCallback godoc
+// @Summary      Slack交互回调
+// @Description  处理告警消息中的确认/解决按钮，要求Slack签名校验通过
+// @Tags         callbacks
+// @Accept       x-www-form-urlencoded
+// @Produce      json
+// @Success      200  {object}  map[string]interface{}
+// @Failure      401  {object}  ErrorResponse
+// @Router       /api/v1/callbacks/slack [post]
+func (h *CallbackHandler) SlackCallback(c *gin.Context) {
+	body, ok := h.readBody(c)
+	if !ok {
+		return
+	}
+
+	if err := verifySlackSignature(h.cfg.SlackSigningSecret,
+		c.GetHeader("X-Slack-Request-Timestamp"), c.GetHeader("X-Slack-Signature"),
+		body, time.Now(), h.cfg.MaxClockSkew); err != nil {
+		h.rejectSignature(c, domain.ExternalProviderSlack, err)
+		return
+	}
+
+	if err := c.Request.ParseForm(); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid_request", Message: err.Error()})
+		return
+	}
+
+	var payload struct {
+		Type       string `json:"type"`
+		CallbackID string `json:"callback_id"`
+		User       struct {
+			ID       string `json:"id"`
+			Name     string `json:"name"`
+			Username string `json:"username"`
+		} `json:"user"`
+		Actions []struct {
+			Name     string `json:"name"`
+			ActionID string `json:"action_id"`
+			Value    string `json:"value"`
+		} `json:"actions"`
+	}
+	if err := json.Unmarshal([]byte(c.Request.PostForm.Get("payload")), &payload); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid_payload", Message: err.Error()})
+		return
+	}
+
+	if payload.Type != "interactive_message" && payload.Type != "block_actions" {
+		c.JSON(http.StatusOK, slackReply("Unsupported interaction"))
+		return
+	}
+
+	for _, a := range payload.Actions {
+		action, target, ok := parseActionValue(a.Value)
+		if !ok {
+			continue
+		}
+
+		result, err := h.apply(c, callbackAction{
+			provider:   domain.ExternalProviderSlack,
+			externalID: payload.User.ID,
+			action:     action,
+			targetID:   target,
+		})
+		c.JSON(http.StatusOK, slackReply(callbackMessage(result, err)))
+		return
+	}
+
+	c.JSON(http.StatusOK, slackReply("No alert action in request"))
+}
+
+// PagerDutyCallback godoc
+// @Summary      PagerDuty Webhook回调
+// @Description  PagerDuty事件被确认或解决时同步到EdgeLink告警（V3 Webhook，要求签名校验通过）
+// @Tags         callbacks
+// @Accept       json
+// @Produce      json
+// @Success      200  {object}  map[string]interface{}
+// @Failure      401  {object}  ErrorResponse
+// @Router       /api/v1/callbacks/pagerduty [post]
+func (h *CallbackHandler) PagerDutyCallback(c *gin.Context) {
+	body, ok := h.readBody(c)
+	if !ok {
+		return
+	}
+
+	if err := verifyPagerDutySignature(h.cfg.PagerDutyWebhookSecrets, c.GetHeader("X-PagerDuty-Signature"), body); err != nil {
+		h.rejectSignature(c, domain.ExternalProviderPagerDuty, err)
+		return
+	}
+
+	var payload struct {
+		Event struct {
+			EventType string `json:"event_type"`
+			Agent     *struct {
+				ID      string `json:"id"`
+				Type    string `json:"type"`
+				Summary string `json:"summary"`
+			} `json:"agent"`
+			Data struct {
+				IncidentKey string `json:"incident_key"`
+			} `json:"data"`
+		} `json:"event"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid_payload", Message: err.Error()})
+		return
+	}
+
+	var action string
+	switch payload.Event.EventType {
+	case "incident.acknowledged":
+		action = AlertActionAcknowledge
+	case "incident.resolved":
+		action = AlertActionResolve
+	default:
+		c.JSON(http.StatusOK, gin.H{"status": "ignored", "reason": "unsupported event type"})
+		return
+	}
+
+	// 自动解决（由集成或超时触发）没有用户操作者，不回写EdgeLink
+	agent := payload.Event.Agent
+	if agent == nil || agent.Type != "user_reference" {
+		c.JSON(http.StatusOK, gin.H{"status": "ignored", "reason": "event was not performed by a user"})
+		return
+	}
+
+	target, err := uuid.Parse(payload.Event.Data.IncidentKey)
+	if err != nil {
+		c.JSON(http.StatusOK, gin.H{"status": "ignored", "reason": "incident was not created by EdgeLink"})
+		return
+	}
+
+	result, err := h.apply(c, callbackAction{
+		provider:   domain.ExternalProviderPagerDuty,
+		externalID: agent.ID,
+		action:     action,
+		targetID:   target,
+	})
+	h.respondWebhook(c, result, err)
+}
+
+// OpsgenieCallback godoc
+// @Summary      Opsgenie Webhook回调
+// @Description  Opsgenie告警被确认或关闭时同步到EdgeLink告警，要求X-EdgeLink-Token令牌校验通过
+// @Tags         callbacks
+// @Accept       json
+// @Produce      json
+// @Success      200  {object}  map[string]interface{}
+// @Failure      401  {object}  ErrorResponse
+// @Router       /api/v1/callbacks/opsgenie [post]
+func (h *CallbackHandler) OpsgenieCallback(c *gin.Context) {
+	body, ok := h.readBody(c)
+	if !ok {
+		return
+	}
+
+	if err := verifySharedToken(h.cfg.OpsgenieWebhookToken, c.GetHeader("X-EdgeLink-Token")); err != nil {
+		h.rejectSignature(c, domain.ExternalProviderOpsgenie, err)
+		return
+	}
+
+	var payload struct {
+		Action string `json:"action"`
+		Alert  struct {
+			Alias    string `json:"alias"`
+			UserID   string `json:"userId"`
+			Username string `json:"username"`
+		} `json:"alert"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid_payload", Message: err.Error()})
+		return
+	}
+
+	var action string
+	switch payload.Action {
+	case "Acknowledge":
+		action = AlertActionAcknowledge
+	case "Close":
+		action = AlertActionResolve
+	default:
+		c.JSON(http.StatusOK, gin.H{"status": "ignored", "reason": "unsupported action"})
+		return
+	}
+
+	// System表示由Opsgenie自身或集成触发的操作
+	if payload.Alert.Username == "" || payload.Alert.Username == "System" {
+		c.JSON(http.StatusOK, gin.H{"status": "ignored", "reason": "action was not performed by a user"})
+		return
+	}
+
+	target, err := uuid.Parse(payload.Alert.Alias)
+	if err != nil {
+		c.JSON(http.StatusOK, gin.H{"status": "ignored", "reason": "alert was not created by EdgeLink"})
+		return
+	}
+
+	result, err := h.apply(c, callbackAction{
+		provider:   domain.ExternalProviderOpsgenie,
+		externalID: payload.Alert.UserID,
+		email:      payload.Alert.Username,
+		action:     action,
+		targetID:   target,
+	})
+	h.respondWebhook(c, result, err)
+}
+
+// TeamsCallback godoc
+// @Summary      Teams可操作消息回调
+// @Description  处理Teams告警卡片中的确认/解决按钮，要求Bearer令牌校验通过
+// @Tags         callbacks
+// @Accept       json
+// @Produce      json
+// @Success      200
+// @Failure      401  {object}  ErrorResponse
+// @Router       /api/v1/callbacks/teams [post]
+func (h *CallbackHandler) TeamsCallback(c *gin.Context) {
+	body, ok := h.readBody(c)
+	if !ok {
+		return
+	}
+
+	claims, err := h.teamsVerifier.Verify(c.Request.Context(), c.GetHeader("Authorization"))
+	if err != nil {
+		h.rejectSignature(c, domain.ExternalProviderTeams, err)
+		return
+	}
+
+	var payload struct {
+		Action  string `json:"action"`
+		AlertID string `json:"alert_id"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid_payload", Message: err.Error()})
+		return
+	}
+
+	target, err := uuid.Parse(payload.AlertID)
+	if err != nil || (payload.Action != AlertActionAcknowledge && payload.Action != AlertActionResolve) {
+		c.Header("CARD-ACTION-STATUS", "Invalid alert action")
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.apply(c, callbackAction{
+		provider:   domain.ExternalProviderTeams,
+		externalID: claims.ActorID(),
+		email:      claims.ActorEmail(),
+		action:     payload.Action,
+		targetID:   target,
+	})
+
+	c.Header("CARD-ACTION-STATUS", callbackMessage(result, err))
+	switch {
+	case errors.Is(err, errUnmappedUser), errors.Is(err, errCallbackForbidden):
+		c.Status(http.StatusForbidden)
+	case errors.Is(err, errCallbackNoAlert):
+		c.Status(http.StatusNotFound)
+	case err != nil:
+		c.Status(http.StatusInternalServerError)
+	default:
+		c.Status(http.StatusOK)
+	}
+}
+
+// ListExternalIdentities godoc
+// @Summary      获取外部平台用户映射
+// @Description  获取管理员在Slack/PagerDuty/Opsgenie/Teams中的用户映射
+// @Tags         admin
+// @Produce      json
+// @Param        admin_user_id  query  string  true  "管理员ID"
+// @Success      200  {array}   domain.ExternalIdentity
+// @Failure      400  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/v1/admin/external-identities [get]
+func (h *CallbackHandler) ListExternalIdentities(c *gin.Context) {
+	adminUserID, err := uuid.Parse(c.Query("admin_user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_admin_user_id",
+			Message: "admin_user_id must be a valid UUID",
+		})
+		return
+	}
+
+	identities, err := h.identityRepo.FindByAdminUserID(c.Request.Context(), adminUserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "query_failed", Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, identities)
+}
+
+// CreateExternalIdentity godoc
+// @Summary      创建外部平台用户映射
+// @Description  将外部平台用户ID关联到管理员，回调时据此识别操作者
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        request  body  CreateExternalIdentityRequest  true  "映射"
+// @Success      201  {object}  domain.ExternalIdentity
+// @Failure      400  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/v1/admin/external-identities [post]
+func (h *CallbackHandler) CreateExternalIdentity(c *gin.Context) {
+	var req CreateExternalIdentityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid_request", Message: err.Error()})
+		return
+	}
+
+	adminUserID, err := uuid.Parse(req.AdminUserID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_admin_user_id",
+			Message: "admin_user_id must be a valid UUID",
+		})
+		return
+	}
+
+	provider := domain.ExternalProvider(req.Provider)
+	if !provider.IsValid() {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_provider",
+			Message: "provider must be one of slack, pagerduty, opsgenie, teams",
+		})
+		return
+	}
+
+	if _, err := h.adminUserRepo.FindByID(c.Request.Context(), adminUserID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "admin_user_not_found", Message: "admin user not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "query_failed", Message: err.Error()})
+		return
+	}
+
+	identity := &domain.ExternalIdentity{
+		AdminUserID: adminUserID,
+		Provider:    provider,
+		ExternalID:  strings.TrimSpace(req.ExternalID),
+		DisplayName: req.DisplayName,
+	}
+	if err := h.identityRepo.Create(c.Request.Context(), identity); err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) || strings.Contains(err.Error(), "duplicate key") {
+			c.JSON(http.StatusConflict, ErrorResponse{
+				Error:   "identity_exists",
+				Message: "external user is already linked to an admin user",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "create_failed", Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, identity)
+}
+
+// DeleteExternalIdentity godoc
+// @Summary      删除外部平台用户映射
+// @Tags         admin
+// @Produce      json
+// @Param        identity_id  path  string  true  "映射ID"
+// @Success      200  {object}  SuccessResponse
+// @Failure      400  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/v1/admin/external-identities/{identity_id} [delete]
+func (h *CallbackHandler) DeleteExternalIdentity(c *gin.Context) {
+	identityID, err := uuid.Parse(c.Param("identity_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_identity_id",
+			Message: "identity_id must be a valid UUID",
+		})
+		return
+	}
+
+	if _, err := h.identityRepo.FindByID(c.Request.Context(), identityID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "identity_not_found", Message: "external identity not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "query_failed", Message: err.Error()})
+		return
+	}
+
+	if err := h.identityRepo.Delete(c.Request.Context(), identityID); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "delete_failed", Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{Message: "external identity deleted successfully"})
+}
+
+// apply 识别操作者并对告警（或分组内全部告警）执行操作，返回实际变更的告警数
+func (h *CallbackHandler) apply(c *gin.Context, req callbackAction) (int, error) {
+	ctx := c.Request.Context()
+
+	user, err := h.resolveActor(ctx, req)
+	if err != nil {
+		h.logger.Info("Rejected alert callback",
+			zap.String("provider", string(req.provider)),
+			zap.String("external_user_id", req.externalID),
+			zap.String("target_id", req.targetID.String()),
+			zap.Error(err),
+		)
+		return 0, err
+	}
+
+	alerts, err := h.targetAlerts(ctx, req.targetID)
+	if err != nil {
+		return 0, err
+	}
+
+	// 先校验全部告警的组织归属，避免部分执行
+	for _, alert := range alerts {
+		if orgID := h.alertHandler.alertOrgID(ctx, alert); orgID != "" && orgID != user.OrganizationID.String() {
+			return 0, errCallbackForbidden
+		}
+	}
+
+	changed := 0
+	for _, alert := range alerts {
+		ok, err := h.applyToAlert(c, req, user, alert)
+		if err != nil {
+			return changed, err
+		}
+		if ok {
+			changed++
+		}
+	}
+	return changed, nil
+}
+
+// applyToAlert 对单个告警执行操作并记录审计日志，状态未变化时返回false
+func (h *CallbackHandler) applyToAlert(c *gin.Context, req callbackAction, user *domain.AdminUser, alert *domain.Alert) (bool, error) {
+	ctx := c.Request.Context()
+	previous := alert.Status
+
+	switch req.action {
+	case AlertActionAcknowledge:
+		if alert.Status != domain.AlertStatusActive {
+			return false, nil
+		}
+		if err := h.alertRepo.Acknowledge(ctx, alert.ID, user.ID); err != nil {
+			return false, err
+		}
+	case AlertActionResolve:
+		if alert.Status == domain.AlertStatusResolved {
+			return false, nil
+		}
+		if err := h.alertRepo.Resolve(ctx, alert.ID); err != nil {
+			return false, err
+		}
+	default:
+		return false, fmt.Errorf("unsupported action: %s", req.action)
+	}
+
+	ipAddress := c.ClientIP()
+	userAgent := c.Request.UserAgent()
+	auditLog := &domain.AuditLog{
+		ID:             uuid.New(),
+		OrganizationID: user.OrganizationID,
+		ActorID:        &user.ID,
+		Action:         req.action,
+		ResourceType:   domain.ResourceTypeAlert,
+		ResourceID:     alert.ID,
+		BeforeState:    &domain.JSONB{"status": previous},
+		AfterState: &domain.JSONB{
+			"status":           alertStatusAfter(req.action),
+			"source":           string(req.provider),
+			"external_user_id": req.externalID,
+		},
+		IPAddress: &ipAddress,
+		UserAgent: &userAgent,
+		CreatedAt: time.Now(),
+	}
+	if err := h.auditLogRepo.Create(ctx, auditLog); err != nil {
+		h.logger.Error("Failed to create audit log for alert callback",
+			zap.String("alert_id", alert.ID.String()),
+			zap.Error(err),
+		)
+	}
+
+	h.alertHandler.publishAlertUpdateFrom(ctx, alert.ID, req.action, &user.ID, string(req.provider))
+
+	h.logger.Info("Alert updated from external callback",
+		zap.String("alert_id", alert.ID.String()),
+		zap.String("action", req.action),
+		zap.String("provider", string(req.provider)),
+		zap.String("actor_id", user.ID.String()),
+	)
+	return true, nil
+}
+
+// resolveActor 通过外部用户映射（或邮箱）找到管理员并校验权限
+func (h *CallbackHandler) resolveActor(ctx context.Context, req callbackAction) (*domain.AdminUser, error) {
+	var user *domain.AdminUser
+
+	if req.externalID != "" {
+		identity, err := h.identityRepo.FindByExternalID(ctx, req.provider, req.externalID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		if identity != nil {
+			user = identity.AdminUser
+		}
+	}
+
+	if user == nil && req.email != "" {
+		found, err := h.adminUserRepo.FindByEmail(ctx, req.email)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		user = found
+	}
+
+	if user == nil {
+		return nil, errUnmappedUser
+	}
+	if !user.IsActive || !user.HasPermission(domain.RoleNetworkOperator) {
+		return nil, errCallbackForbidden
+	}
+	return user, nil
+}
+
+// targetAlerts 查找回调指向的告警；分组通知使用分组ID作为去重键，此时返回分组内全部告警
+func (h *CallbackHandler) targetAlerts(ctx context.Context, targetID uuid.UUID) ([]*domain.Alert, error) {
+	alert, err := h.alertRepo.FindByID(ctx, targetID)
+	if err == nil {
+		return []*domain.Alert{alert}, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	group, err := h.groupRepo.FindByID(ctx, targetID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errCallbackNoAlert
+		}
+		return nil, err
+	}
+
+	alerts := make([]*domain.Alert, 0, len(group.AlertIDs))
+	for _, id := range group.AlertIDs {
+		member, err := h.alertRepo.FindByID(ctx, id)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				continue
+			}
+			return nil, err
+		}
+		alerts = append(alerts, member)
+	}
+	if len(alerts) == 0 {
+		return nil, errCallbackNoAlert
+	}
+	return alerts, nil
+}
+
+// readBody 读取原始请求体（签名基于原始字节计算），并允许后续再次解析
+func (h *CallbackHandler) readBody(c *gin.Context) ([]byte, bool) {
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxCallbackBodySize))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid_request", Message: err.Error()})
+		return nil, false
+	}
+	c.Request.Body = io.NopCloser(strings.NewReader(string(body)))
+	return body, true
+}
+
+// rejectSignature 签名校验失败
+func (h *CallbackHandler) rejectSignature(c *gin.Context, provider domain.ExternalProvider, err error) {
+	h.logger.Warn("Rejected unauthenticated callback",
+		zap.String("provider", string(provider)),
+		zap.String("client_ip", c.ClientIP()),
+		zap.Error(err),
+	)
+
+	if errors.Is(err, errCallbackNotConfigured) {
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:   "callback_not_configured",
+			Message: fmt.Sprintf("%s callbacks are not configured", provider),
+		})
+		return
+	}
+	c.JSON(http.StatusUnauthorized, ErrorResponse{
+		Error:   "invalid_signature",
+		Message: "request signature verification failed",
+	})
+}
+
+// respondWebhook 返回Webhook处理结果
+// 身份或权限问题返回200，避免平台反复重试无法成功的请求
+func (h *CallbackHandler) respondWebhook(c *gin.Context, changed int, err error) {
+	switch {
+	case err == nil:
+		c.JSON(http.StatusOK, gin.H{"status": "ok", "updated": changed})
+	case errors.Is(err, errUnmappedUser), errors.Is(err, errCallbackForbidden), errors.Is(err, errCallbackNoAlert):
+		c.JSON(http.StatusOK, gin.H{"status": "ignored", "reason": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "callback_failed", Message: err.Error()})
+	}
+}
+
+// parseActionValue 解析按钮值 "ack:<id>" / "resolve:<id>"
+func parseActionValue(value string) (string, uuid.UUID, bool) {
+	kind, rawID, found := strings.Cut(value, ":")
+	if !found {
+		return "", uuid.Nil, false
+	}
+
+	id, err := uuid.Parse(rawID)
+	if err != nil {
+		return "", uuid.Nil, false
+	}
+
+	switch kind {
+	case "ack", AlertActionAcknowledge:
+		return AlertActionAcknowledge, id, true
+	case AlertActionResolve:
+		return AlertActionResolve, id, true
+	}
+	return "", uuid.Nil, false
+}
+
+// alertStatusAfter 操作完成后的告警状态
+func alertStatusAfter(action string) domain.AlertStatus {
+	if action == AlertActionResolve {
+		return domain.AlertStatusResolved
+	}
+	return domain.AlertStatusAcknowledged
+}
+
+// callbackMessage 返回给操作者的提示文本
+func callbackMessage(changed int, err error) string {
+	switch {
+	case errors.Is(err, errUnmappedUser):
+		return "Your account is not linked to an EdgeLink user"
+	case errors.Is(err, errCallbackForbidden):
+		return "You are not allowed to change this alert"
+	case errors.Is(err, errCallbackNoAlert):
+		return "Alert not found"
+	case err != nil:
+		return "Failed to update alert"
+	case changed == 0:
+		return "Alert was already in the requested state"
+	case changed == 1:
+		return "Alert updated"
+	default:
+		return fmt.Sprintf("%d alerts updated", changed)
+	}
+}
+
+// slackReply 仅对操作者可见的Slack回复
+func slackReply(text string) gin.H {
+	return gin.H{
+		"response_type":    "ephemeral",
+		"replace_original": false,
+		"text":             text,
+	}
+}
+
+// CreateExternalIdentityRequest 创建外部平台用户映射请求
+type CreateExternalIdentityRequest struct {
+	AdminUserID string `json:"admin_user_id" binding:"required"`
+	Provider    string `json:"provider" binding:"required"`
+	ExternalID  string `json:"external_id" binding:"required"`
+	DisplayName string `json:"display_name"`
+}
@@ -0,0 +1,255 @@
+package handler
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	errCallbackNotConfigured = errors.New("callback is not configured")
+	errInvalidSignature      = errors.New("invalid signature")
+)
+
+// verifySlackSignature 校验Slack请求签名：v0=HMAC-SHA256(secret, "v0:{timestamp}:{body}")
+// 时间戳超出允许偏差的请求视为重放
+func verifySlackSignature(secret, timestamp, signature string, body []byte, now time.Time, maxSkew time.Duration) error {
+	if secret == "" {
+		return errCallbackNotConfigured
+	}
+
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid timestamp: %w", err)
+	}
+	if skew := now.Sub(time.Unix(ts, 0)); skew > maxSkew || skew < -maxSkew {
+		return fmt.Errorf("timestamp outside allowed window")
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("v0:" + timestamp + ":"))
+	mac.Write(body)
+	expected := "v0=" + hex.EncodeToString(mac.Sum(nil))
+
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return errInvalidSignature
+	}
+	return nil
+}
+
+// verifyPagerDutySignature 校验PagerDuty v3 Webhook签名
+// 请求头形如 "v1=<hex>,v1=<hex>"，密钥轮换期间可能携带多个签名，任一匹配即可
+func verifyPagerDutySignature(secrets []string, header string, body []byte) error {
+	if len(secrets) == 0 {
+		return errCallbackNotConfigured
+	}
+
+	for _, secret := range secrets {
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write(body)
+		expected := "v1=" + hex.EncodeToString(mac.Sum(nil))
+
+		for _, signature := range strings.Split(header, ",") {
+			if hmac.Equal([]byte(expected), []byte(strings.TrimSpace(signature))) {
+				return nil
+			}
+		}
+	}
+	return errInvalidSignature
+}
+
+// verifySharedToken 以常量时间比较共享令牌（Opsgenie Webhook不签名，使用自定义请求头携带令牌）
+func verifySharedToken(expected, got string) error {
+	if expected == "" {
+		return errCallbackNotConfigured
+	}
+	if subtle.ConstantTimeCompare([]byte(expected), []byte(got)) != 1 {
+		return errInvalidSignature
+	}
+	return nil
+}
+
+const (
+	// jwksCacheTTL 签名公钥缓存时长
+	jwksCacheTTL = 24 * time.Hour
+	// jwksMinRefreshInterval 遇到未知kid时重新拉取公钥的最小间隔
+	jwksMinRefreshInterval = 5 * time.Minute
+)
+
+// teamsActionClaims 可操作消息令牌的声明
+type teamsActionClaims struct {
+	jwt.RegisteredClaims
+	Sender string `json:"sender,omitempty"` // 发送卡片的邮箱
+	OID    string `json:"oid,omitempty"`    // 执行操作的用户对象ID
+	UPN    string `json:"upn,omitempty"`    // 执行操作的用户主体名称（邮箱）
+	Email  string `json:"email,omitempty"`
+	TID    string `json:"tid,omitempty"`
+}
+
+// ActorEmail 执行操作的用户邮箱
+func (c *teamsActionClaims) ActorEmail() string {
+	if c.Email != "" {
+		return c.Email
+	}
+	return c.UPN
+}
+
+// ActorID 执行操作的用户标识
+func (c *teamsActionClaims) ActorID() string {
+	if c.OID != "" {
+		return c.OID
+	}
+	return c.Subject
+}
+
+// teamsTokenVerifier 校验Teams/Outlook可操作消息请求携带的Bearer令牌（RS256，公钥来自JWKS）
+type teamsTokenVerifier struct {
+	issuer   string
+	audience string
+	jwksURL  string
+	client   *http.Client
+
+	mu        sync.RWMutex
+	keys      map[string]*rsa.PublicKey
+	fetchedAt time.Time
+}
+
+// newTeamsTokenVerifier 创建可操作消息令牌校验器
+func newTeamsTokenVerifier(issuer, audience, jwksURL string) *teamsTokenVerifier {
+	return &teamsTokenVerifier{
+		issuer:   issuer,
+		audience: audience,
+		jwksURL:  jwksURL,
+		client:   &http.Client{Timeout: 10 * time.Second},
+		keys:     make(map[string]*rsa.PublicKey),
+	}
+}
+
+// Verify 校验Authorization请求头并返回令牌声明
+func (v *teamsTokenVerifier) Verify(ctx context.Context, authorization string) (*teamsActionClaims, error) {
+	if v.audience == "" || v.jwksURL == "" {
+		return nil, errCallbackNotConfigured
+	}
+
+	raw, ok := strings.CutPrefix(authorization, "Bearer ")
+	if !ok || raw == "" {
+		return nil, fmt.Errorf("missing bearer token")
+	}
+
+	claims := &teamsActionClaims{}
+	_, err := jwt.ParseWithClaims(raw, claims,
+		func(token *jwt.Token) (interface{}, error) {
+			kid, _ := token.Header["kid"].(string)
+			return v.key(ctx, kid)
+		},
+		jwt.WithValidMethods([]string{"RS256"}),
+		jwt.WithIssuer(v.issuer),
+		jwt.WithAudience(v.audience),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errInvalidSignature, err)
+	}
+	return claims, nil
+}
+
+// key 按kid获取公钥，缓存未命中时刷新JWKS
+func (v *teamsTokenVerifier) key(ctx context.Context, kid string) (*rsa.PublicKey, error) {
+	v.mu.RLock()
+	key, ok := v.keys[kid]
+	stale := time.Since(v.fetchedAt) > jwksCacheTTL
+	canRefresh := time.Since(v.fetchedAt) > jwksMinRefreshInterval
+	v.mu.RUnlock()
+
+	if ok && !stale {
+		return key, nil
+	}
+	if !ok && !stale && !canRefresh {
+		return nil, fmt.Errorf("unknown signing key: %s", kid)
+	}
+
+	if err := v.refresh(ctx); err != nil {
+		if ok {
+			// 拉取失败时继续使用已缓存的公钥
+			return key, nil
+		}
+		return nil, err
+	}
+
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	if key, ok := v.keys[kid]; ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unknown signing key: %s", kid)
+}
+
+// refresh 拉取JWKS并替换公钥缓存
+func (v *teamsTokenVerifier) refresh(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, v.jwksURL, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to fetch signing keys: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to fetch signing keys: status %d", resp.StatusCode)
+	}
+
+	var jwks struct {
+		Keys []struct {
+			Kty string `json:"kty"`
+			Kid string `json:"kid"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+		} `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&jwks); err != nil {
+		return fmt.Errorf("failed to decode signing keys: %w", err)
+	}
+
+	keys := make(map[string]*rsa.PublicKey, len(jwks.Keys))
+	for _, k := range jwks.Keys {
+		if k.Kty != "RSA" {
+			continue
+		}
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			continue
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			continue
+		}
+		keys[k.Kid] = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+	}
+
+	v.mu.Lock()
+	v.keys = keys
+	v.fetchedAt = time.Now()
+	v.mu.Unlock()
+	return nil
+}
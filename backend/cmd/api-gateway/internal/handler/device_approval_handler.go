@@ -0,0 +1,464 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/edgelink/backend/cmd/api-gateway/internal/websocket"
+	"github.com/edgelink/backend/internal/audit"
+	"github.com/edgelink/backend/internal/domain"
+	"github.com/edgelink/backend/internal/repository"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// 设备审批事件动作
+const (
+	DeviceApprovalActionRequested = "requested" // 新设备注册后等待审批
+	DeviceApprovalActionApproved  = "approved"
+	DeviceApprovalActionRejected  = "rejected"
+)
+
+// DeviceApprovalHandler 设备审批处理器
+// 批准、拒绝和规则变更经审计中间件记录（动作分别为approve、reject、create/update/delete）
+type DeviceApprovalHandler struct {
+	deviceRepo  repository.DeviceRepository
+	vnRepo      repository.VirtualNetworkRepository
+	ruleRepo    repository.DeviceApprovalRuleRepository
+	pskRepo     repository.PreSharedKeyRepository
+	broadcaster *websocket.Broadcaster
+	logger      *zap.Logger
+}
+
+// NewDeviceApprovalHandler 创建DeviceApprovalHandler实例
+func NewDeviceApprovalHandler(
+	deviceRepo repository.DeviceRepository,
+	vnRepo repository.VirtualNetworkRepository,
+	ruleRepo repository.DeviceApprovalRuleRepository,
+	pskRepo repository.PreSharedKeyRepository,
+	broadcaster *websocket.Broadcaster,
+	logger *zap.Logger,
+) *DeviceApprovalHandler {
+	return &DeviceApprovalHandler{
+		deviceRepo:  deviceRepo,
+		vnRepo:      vnRepo,
+		ruleRepo:    ruleRepo,
+		pskRepo:     pskRepo,
+		broadcaster: broadcaster,
+		logger:      logger,
+	}
+}
+
+// DeviceApprovalRequest 批准/拒绝设备请求
+type DeviceApprovalRequest struct {
+	Note string `json:"note"`
+}
+
+// ApprovalSettingRequest 虚拟网络审批开关请求
+type ApprovalSettingRequest struct {
+	RequireApproval *bool `json:"require_approval" binding:"required"`
+}
+
+// DeviceApprovalRuleRequest 创建自动审批规则请求，pre_shared_key_id和tag至少填一个，都填时需同时匹配
+type DeviceApprovalRuleRequest struct {
+	PreSharedKeyID *string `json:"pre_shared_key_id"`
+	Tag            *string `json:"tag"`
+	Description    string  `json:"description"`
+}
+
+// DeviceApprovalRuleListResponse 自动审批规则列表响应
+type DeviceApprovalRuleListResponse struct {
+	Rules []*domain.DeviceApprovalRule `json:"rules"`
+	Total int                          `json:"total"`
+}
+
+// DeviceApprovalEvent 通过WebSocket广播的设备审批事件
+type DeviceApprovalEvent struct {
+	DeviceID         uuid.UUID                   `json:"device_id"`
+	VirtualNetworkID uuid.UUID                   `json:"virtual_network_id"`
+	Name             string                      `json:"name"`
+	Platform         string                      `json:"platform,omitempty"`
+	Tags             []string                    `json:"tags,omitempty"`
+	Action           string                      `json:"action"`
+	Status           domain.DeviceApprovalStatus `json:"status"`
+	ActorID          *uuid.UUID                  `json:"actor_id,omitempty"`
+	Note             string                      `json:"note,omitempty"`
+	Timestamp        time.Time                   `json:"timestamp"`
+}
+
+// ApproveDevice godoc
+// @Summary      批准设备
+// @Description  批准待审批或已拒绝的设备，设备随后出现在同网络其他设备的对端列表中
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        device_id  path  string                 true   "设备ID"
+// @Param        request    body  DeviceApprovalRequest  false  "审批备注"
+// @Success      200  {object}  domain.Device
+// @Failure      400  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse
+// @Failure      409  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/v1/admin/devices/{device_id}/approve [post]
+func (h *DeviceApprovalHandler) ApproveDevice(c *gin.Context) {
+	h.decide(c, []domain.DeviceApprovalStatus{domain.DeviceApprovalPending, domain.DeviceApprovalRejected},
+		domain.DeviceApprovalApproved, DeviceApprovalActionApproved)
+}
+
+// RejectDevice godoc
+// @Summary      拒绝设备
+// @Description  拒绝待审批的设备，设备无法获取配置或上报指标；已批准的设备请使用删除接口撤销
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        device_id  path  string                 true   "设备ID"
+// @Param        request    body  DeviceApprovalRequest  false  "拒绝原因"
+// @Success      200  {object}  domain.Device
+// @Failure      400  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse
+// @Failure      409  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/v1/admin/devices/{device_id}/reject [post]
+func (h *DeviceApprovalHandler) RejectDevice(c *gin.Context) {
+	h.decide(c, []domain.DeviceApprovalStatus{domain.DeviceApprovalPending},
+		domain.DeviceApprovalRejected, DeviceApprovalActionRejected)
+}
+
+// decide 将设备从from中的状态转为to，并广播审批事件
+func (h *DeviceApprovalHandler) decide(c *gin.Context, from []domain.DeviceApprovalStatus, to domain.DeviceApprovalStatus, action string) {
+	var req DeviceApprovalRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "invalid_request",
+				Message: err.Error(),
+			})
+			return
+		}
+	}
+
+	deviceID, err := uuid.Parse(c.Param("device_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_device_id",
+			Message: "device_id must be a valid UUID",
+		})
+		return
+	}
+
+	ctx := c.Request.Context()
+	device, err := h.deviceRepo.FindByID(ctx, deviceID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:   "device_not_found",
+			Message: "device not found",
+		})
+		return
+	}
+	if device.VirtualNetwork != nil {
+		c.Set(audit.ContextOrganizationID, device.VirtualNetwork.OrganizationID)
+	}
+
+	actorID := actorIDFromHeader(c)
+	updated, err := h.deviceRepo.SetApprovalStatus(ctx, deviceID, from, to, actorID, req.Note, time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "update_failed",
+			Message: err.Error(),
+		})
+		return
+	}
+	if !updated {
+		c.JSON(http.StatusConflict, ErrorResponse{
+			Error:   "invalid_approval_status",
+			Message: "device approval status is " + string(device.ApprovalStatus),
+		})
+		return
+	}
+
+	// 重新读取设备以返回变更后的状态
+	if refreshed, err := h.deviceRepo.FindByID(ctx, deviceID); err == nil {
+		device = refreshed
+	}
+
+	h.publish(c, device, action, actorID, req.Note)
+
+	h.logger.Info("Device approval decided",
+		zap.String("device_id", deviceID.String()),
+		zap.String("status", string(to)),
+	)
+
+	c.JSON(http.StatusOK, device)
+}
+
+// publish 广播设备审批事件，失败只记录日志
+func (h *DeviceApprovalHandler) publish(c *gin.Context, device *domain.Device, action string, actorID *uuid.UUID, note string) {
+	publishDeviceApproval(c.Request.Context(), h.broadcaster, h.logger, device, action, actorID, note)
+}
+
+// UpdateApprovalSetting godoc
+// @Summary      设置虚拟网络是否要求设备审批
+// @Description  开启后新注册的设备需经管理员批准或命中自动审批规则才加入网络，不影响已有设备
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        network_id  path  string                  true  "虚拟网络ID"
+// @Param        request     body  ApprovalSettingRequest  true  "审批设置"
+// @Success      200  {object}  domain.VirtualNetwork
+// @Failure      400  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/v1/admin/virtual-networks/{network_id}/approval [put]
+func (h *DeviceApprovalHandler) UpdateApprovalSetting(c *gin.Context) {
+	var req ApprovalSettingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	vn, ok := h.findVirtualNetwork(c)
+	if !ok {
+		return
+	}
+
+	vn.RequireApproval = *req.RequireApproval
+	vn.UpdatedAt = time.Now()
+	if err := h.vnRepo.Update(c.Request.Context(), vn); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "update_failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	h.logger.Info("Virtual network approval setting updated",
+		zap.String("network_id", vn.ID.String()),
+		zap.Bool("require_approval", vn.RequireApproval),
+	)
+
+	c.JSON(http.StatusOK, vn)
+}
+
+// GetApprovalRules godoc
+// @Summary      获取虚拟网络的自动审批规则
+// @Tags         admin
+// @Produce      json
+// @Param        network_id  path  string  true  "虚拟网络ID"
+// @Success      200  {object}  DeviceApprovalRuleListResponse
+// @Failure      400  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/v1/admin/virtual-networks/{network_id}/approval-rules [get]
+func (h *DeviceApprovalHandler) GetApprovalRules(c *gin.Context) {
+	vn, ok := h.findVirtualNetwork(c)
+	if !ok {
+		return
+	}
+
+	rules, err := h.ruleRepo.FindByVirtualNetwork(c.Request.Context(), vn.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "query_failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, DeviceApprovalRuleListResponse{
+		Rules: rules,
+		Total: len(rules),
+	})
+}
+
+// CreateApprovalRule godoc
+// @Summary      创建自动审批规则
+// @Description  注册使用指定预共享密钥和/或带有指定标签的设备直接批准，预共享密钥须属于同一组织
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        network_id  path  string                     true  "虚拟网络ID"
+// @Param        request     body  DeviceApprovalRuleRequest  true  "规则"
+// @Success      201  {object}  domain.DeviceApprovalRule
+// @Failure      400  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/v1/admin/virtual-networks/{network_id}/approval-rules [post]
+func (h *DeviceApprovalHandler) CreateApprovalRule(c *gin.Context) {
+	var req DeviceApprovalRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	vn, ok := h.findVirtualNetwork(c)
+	if !ok {
+		return
+	}
+
+	rule := &domain.DeviceApprovalRule{
+		ID:               uuid.New(),
+		VirtualNetworkID: vn.ID,
+		Tag:              req.Tag,
+		Description:      req.Description,
+		CreatedBy:        actorIDFromHeader(c),
+		CreatedAt:        time.Now(),
+	}
+
+	if req.PreSharedKeyID != nil {
+		pskID, err := uuid.Parse(*req.PreSharedKeyID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "invalid_pre_shared_key_id",
+				Message: "pre_shared_key_id must be a valid UUID",
+			})
+			return
+		}
+
+		psk, err := h.pskRepo.FindByID(c.Request.Context(), pskID)
+		if err != nil || psk.OrganizationID != vn.OrganizationID {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "invalid_pre_shared_key_id",
+				Message: "pre-shared key not found in organization",
+			})
+			return
+		}
+		rule.PreSharedKeyID = &pskID
+	}
+
+	if err := rule.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_rule",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if err := h.ruleRepo.Create(c.Request.Context(), rule); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "create_failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	h.logger.Info("Device approval rule created",
+		zap.String("rule_id", rule.ID.String()),
+		zap.String("network_id", vn.ID.String()),
+	)
+
+	c.JSON(http.StatusCreated, rule)
+}
+
+// DeleteApprovalRule godoc
+// @Summary      删除自动审批规则
+// @Description  只影响之后的注册，已按该规则批准的设备保持批准状态
+// @Tags         admin
+// @Produce      json
+// @Param        network_id  path  string  true  "虚拟网络ID"
+// @Param        rule_id     path  string  true  "规则ID"
+// @Success      200  {object}  SuccessResponse
+// @Failure      400  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/v1/admin/virtual-networks/{network_id}/approval-rules/{rule_id} [delete]
+func (h *DeviceApprovalHandler) DeleteApprovalRule(c *gin.Context) {
+	vn, ok := h.findVirtualNetwork(c)
+	if !ok {
+		return
+	}
+
+	ruleID, err := uuid.Parse(c.Param("rule_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_rule_id",
+			Message: "rule_id must be a valid UUID",
+		})
+		return
+	}
+
+	rule, err := h.ruleRepo.FindByID(c.Request.Context(), ruleID)
+	if err != nil || rule.VirtualNetworkID != vn.ID {
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:   "rule_not_found",
+			Message: "approval rule not found",
+		})
+		return
+	}
+
+	if err := h.ruleRepo.Delete(c.Request.Context(), ruleID); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "delete_failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	h.logger.Info("Device approval rule deleted",
+		zap.String("rule_id", ruleID.String()),
+		zap.String("network_id", vn.ID.String()),
+	)
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Message: "approval rule deleted",
+	})
+}
+
+// findVirtualNetwork 解析路径中的network_id并查找虚拟网络，失败时已写入响应
+func (h *DeviceApprovalHandler) findVirtualNetwork(c *gin.Context) (*domain.VirtualNetwork, bool) {
+	vnID, err := uuid.Parse(c.Param("network_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_network_id",
+			Message: "network_id must be a valid UUID",
+		})
+		return nil, false
+	}
+
+	vn, err := h.vnRepo.FindByID(c.Request.Context(), vnID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:   "network_not_found",
+			Message: "virtual network not found",
+		})
+		return nil, false
+	}
+
+	c.Set(audit.ContextOrganizationID, vn.OrganizationID)
+	return vn, true
+}
+
+// publishDeviceApproval 广播设备审批事件，失败只记录日志
+func publishDeviceApproval(ctx context.Context, broadcaster *websocket.Broadcaster, logger *zap.Logger, device *domain.Device, action string, actorID *uuid.UUID, note string) {
+	orgID := ""
+	if device.VirtualNetwork != nil {
+		orgID = device.VirtualNetwork.OrganizationID.String()
+	}
+
+	event := DeviceApprovalEvent{
+		DeviceID:         device.ID,
+		VirtualNetworkID: device.VirtualNetworkID,
+		Name:             device.Name,
+		Platform:         string(device.Platform),
+		Tags:             device.Tags,
+		Action:           action,
+		Status:           device.ApprovalStatus,
+		ActorID:          actorID,
+		Note:             note,
+		Timestamp:        time.Now(),
+	}
+	if err := broadcaster.PublishDeviceApproval(ctx, device.ID.String(), orgID, event); err != nil {
+		logger.Error("Failed to publish device approval event",
+			zap.String("device_id", device.ID.String()),
+			zap.String("action", action),
+			zap.Error(err),
+		)
+	}
+}
@@ -3,13 +3,18 @@ package handler
 import (
 	"fmt"
 	"net/http"
+	"strconv"
 	"time"
 
+	"github.com/edgelink/backend/cmd/api-gateway/internal/websocket"
 	"github.com/edgelink/backend/internal/service"
 	"github.com/edgelink/backend/internal/auth"
 	"github.com/edgelink/backend/internal/crypto"
+	"github.com/edgelink/backend/internal/domain"
+	"github.com/edgelink/backend/internal/metrics"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"go.uber.org/zap"
 )
 
 // DeviceHandler 设备相关HTTP处理器
@@ -17,6 +22,9 @@ type DeviceHandler struct {
 	deviceService   *service.DeviceService
 	topologyService *service.TopologyService
 	pskAuth         *auth.PSKAuthenticator
+	metrics         *metrics.Metrics
+	broadcaster     *websocket.Broadcaster
+	logger          *zap.Logger
 }
 
 // NewDeviceHandler 创建设备处理器实例
@@ -24,17 +32,23 @@ func NewDeviceHandler(
 	deviceService *service.DeviceService,
 	topologyService *service.TopologyService,
 	pskAuth *auth.PSKAuthenticator,
+	m *metrics.Metrics,
+	broadcaster *websocket.Broadcaster,
+	logger *zap.Logger,
 ) *DeviceHandler {
 	return &DeviceHandler{
 		deviceService:   deviceService,
 		topologyService: topologyService,
 		pskAuth:         pskAuth,
+		metrics:         m,
+		broadcaster:     broadcaster,
+		logger:          logger,
 	}
 }
 
 // RegisterDevice godoc
 // @Summary      注册新设备
-// @Description  使用预共享密钥注册新设备到虚拟网络
+// @Description  使用预共享密钥注册新设备到虚拟网络；要求审批的网络中未命中自动审批规则的设备返回approval_status=pending
 // @Tags         devices
 // @Accept       json
 // @Produce      json
@@ -72,6 +86,7 @@ func (h *DeviceHandler) RegisterDevice(c *gin.Context) {
 	// 4. 调用服务层注册设备
 	resp, err := h.deviceService.RegisterDevice(c.Request.Context(), &req)
 	if err != nil {
+		h.metrics.RecordDeviceRegistration(platformLabel(req.Platform), "failed")
 		c.JSON(http.StatusInternalServerError, ErrorResponse{
 			Error:   "registration_failed",
 			Message: err.Error(),
@@ -79,21 +94,68 @@ func (h *DeviceHandler) RegisterDevice(c *gin.Context) {
 		return
 	}
 
-	// 5. 返回成功响应
+	// 5. 等待审批的设备通知管理员
+	if resp.ApprovalStatus == domain.DeviceApprovalPending {
+		event := DeviceApprovalEvent{
+			DeviceID:         resp.DeviceID,
+			VirtualNetworkID: resp.VirtualNetworkID,
+			Name:             req.DeviceName,
+			Platform:         req.Platform,
+			Tags:             req.Tags,
+			Action:           DeviceApprovalActionRequested,
+			Status:           resp.ApprovalStatus,
+			Timestamp:        time.Now(),
+		}
+		if err := h.broadcaster.PublishDeviceApproval(c.Request.Context(), resp.DeviceID.String(), resp.OrganizationID.String(), event); err != nil {
+			h.logger.Error("Failed to publish device approval request",
+				zap.String("device_id", resp.DeviceID.String()),
+				zap.Error(err),
+			)
+		}
+	}
+
+	// 6. 注册时即不满足安全状态策略的设备通知管理端
+	if resp.PostureStatus == domain.DevicePostureNonCompliant {
+		device := &domain.Device{
+			ID:                        resp.DeviceID,
+			VirtualNetworkID:          resp.VirtualNetworkID,
+			Name:                      req.DeviceName,
+			PostureStatus:             resp.PostureStatus,
+			PostureViolations:         resp.PostureViolations,
+			PostureAllowedPeerTags:    resp.PostureAllowedPeerTags,
+			PostureAllowedPeerTagSets: resp.PostureAllowedTagSets,
+		}
+		publishDevicePosture(c.Request.Context(), h.broadcaster, h.logger, device, resp.OrganizationID)
+	}
+
+	// 7. 返回成功响应
+	h.metrics.RecordDeviceRegistration(platformLabel(req.Platform), "success")
 	c.JSON(http.StatusCreated, resp)
 }
 
+// platformLabel 将客户端上报的平台转换为指标标签，未知值统一归为unknown以限制标签数量
+func platformLabel(platform string) string {
+	switch domain.Platform(platform) {
+	case domain.PlatformDesktopLinux, domain.PlatformDesktopWindows, domain.PlatformDesktopMacOS,
+		domain.PlatformMobileIOS, domain.PlatformMobileAndroid, domain.PlatformIoT, domain.PlatformContainer:
+		return platform
+	}
+	return "unknown"
+}
+
 // GetDeviceConfig godoc
 // @Summary      获取设备配置
-// @Description  获取设备的WireGuard配置（包含对等设备列表）
+// @Description  获取设备的WireGuard配置（包含对等设备列表）；待审批设备只返回自身信息，已拒绝设备返回403
 // @Tags         devices
 // @Accept       json
 // @Produce      json
 // @Param        device_id  path  string  true  "设备ID"
 // @Param        Authorization  header  string  true  "Bearer {device_signature}"
+// @Param        X-Device-Timestamp  header  string  false  "签名时间戳（Unix秒或RFC3339），用于检测设备时钟偏差"
 // @Success      200  {object}  DeviceConfigResponse
 // @Failure      400  {object}  ErrorResponse
 // @Failure      401  {object}  ErrorResponse
+// @Failure      403  {object}  ErrorResponse
 // @Failure      404  {object}  ErrorResponse
 // @Router       /api/v1/device/{device_id}/config [get]
 func (h *DeviceHandler) GetDeviceConfig(c *gin.Context) {
@@ -124,8 +186,13 @@ func (h *DeviceHandler) GetDeviceConfig(c *gin.Context) {
 		})
 		return
 	}
+	if !h.checkNotRejected(c, device) {
+		return
+	}
+
+	h.recordClockSkew(c, deviceID)
 
-	// 4. 获取对等配置
+	// 4. 获取对等配置（待审批设备为空）
 	peers, err := h.topologyService.GetPeerConfigurations(c.Request.Context(), deviceID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, ErrorResponse{
@@ -137,12 +204,15 @@ func (h *DeviceHandler) GetDeviceConfig(c *gin.Context) {
 
 	// 5. 构建响应
 	resp := DeviceConfigResponse{
-		DeviceID:         device.ID,
-		VirtualIP:        device.VirtualIP,
-		VirtualNetworkID: device.VirtualNetworkID,
-		Platform:         string(device.Platform),
-		Peers:            peers,
-		UpdatedAt:        device.UpdatedAt,
+		DeviceID:          device.ID,
+		VirtualIP:         device.VirtualIP,
+		VirtualNetworkID:  device.VirtualNetworkID,
+		Platform:          string(device.Platform),
+		ApprovalStatus:    device.ApprovalStatus,
+		PostureStatus:     device.PostureStatus,
+		PostureViolations: device.PostureViolations,
+		Peers:             peers,
+		UpdatedAt:         device.UpdatedAt,
 	}
 
 	c.JSON(http.StatusOK, resp)
@@ -156,10 +226,12 @@ func (h *DeviceHandler) GetDeviceConfig(c *gin.Context) {
 // @Produce      json
 // @Param        device_id  path  string  true  "设备ID"
 // @Param        Authorization  header  string  true  "Bearer {device_signature}"
+// @Param        X-Device-Timestamp  header  string  false  "签名时间戳（Unix秒或RFC3339），用于检测设备时钟偏差"
 // @Param        metrics  body  DeviceMetricsRequest  true  "设备指标"
 // @Success      200  {object}  SuccessResponse
 // @Failure      400  {object}  ErrorResponse
 // @Failure      401  {object}  ErrorResponse
+// @Failure      403  {object}  ErrorResponse
 // @Router       /api/v1/device/{device_id}/metrics [post]
 func (h *DeviceHandler) SubmitDeviceMetrics(c *gin.Context) {
 	// 1. 解析设备ID
@@ -175,6 +247,17 @@ func (h *DeviceHandler) SubmitDeviceMetrics(c *gin.Context) {
 
 	// 2. 验证设备身份
 	// TODO: 实现设备签名验证（同GetDeviceConfig）
+	device, err := h.deviceService.GetDeviceConfig(c.Request.Context(), deviceID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:   "device_not_found",
+			Message: err.Error(),
+		})
+		return
+	}
+	if !h.checkNotRejected(c, device) {
+		return
+	}
 
 	// 3. 解析指标数据
 	var metrics DeviceMetricsRequest
@@ -195,36 +278,91 @@ func (h *DeviceHandler) SubmitDeviceMetrics(c *gin.Context) {
 		return
 	}
 
-	// 5. 存储指标数据
-	// TODO: 实现完整的指标存储逻辑
-	// 当前为简化实现，实际生产需要：
-	// 1. 将指标数据写入时序数据库（如Prometheus/InfluxDB）
-	// 2. 触发监控告警规则
-	// 3. 更新设备最后活跃时间
+	h.recordClockSkew(c, deviceID)
+
+	// 5. 保存安全状态并重新评估，隔离/限制状态变化时通知管理端
+	if metrics.Posture != nil {
+		updated, changed, err := h.deviceService.ReportPosture(c.Request.Context(), deviceID, metrics.Posture, time.Now())
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, ErrorResponse{
+				Error:   "failed_to_update_posture",
+				Message: err.Error(),
+			})
+			return
+		}
+		if changed && updated.VirtualNetwork != nil {
+			publishDevicePosture(c.Request.Context(), h.broadcaster, h.logger, updated, updated.VirtualNetwork.OrganizationID)
+		}
+	}
+
+	// 6. 存储链路指标样本（告警服务据此计算每个设备对的延迟/丢包基线）
+	if _, err := h.deviceService.RecordLinkMetrics(c.Request.Context(), deviceID, metrics.LatencyMs, metrics.PacketLoss, time.Now()); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "failed_to_store_metrics",
+			Message: err.Error(),
+		})
+		return
+	}
 
 	c.JSON(http.StatusOK, SuccessResponse{
 		Message: "metrics submitted successfully",
 	})
 }
 
+// checkNotRejected 已被拒绝的设备不能获取配置或上报指标，返回false时已写入响应
+func (h *DeviceHandler) checkNotRejected(c *gin.Context, device *domain.Device) bool {
+	if device.ApprovalStatus != domain.DeviceApprovalRejected {
+		return true
+	}
+	c.JSON(http.StatusForbidden, ErrorResponse{
+		Error:   "device_rejected",
+		Message: "device registration was rejected by an administrator",
+	})
+	return false
+}
+
+// recordClockSkew 根据X-Device-Timestamp请求头（签名时间戳，Unix秒或RFC3339）记录设备时钟偏差
+// 时钟偏差仅用于告警，缺失或无法解析时忽略，记录失败也不影响请求
+func (h *DeviceHandler) recordClockSkew(c *gin.Context, deviceID uuid.UUID) {
+	header := c.GetHeader("X-Device-Timestamp")
+	if header == "" {
+		return
+	}
+
+	var deviceTime time.Time
+	if unix, err := strconv.ParseInt(header, 10, 64); err == nil {
+		deviceTime = time.Unix(unix, 0)
+	} else if parsed, err := time.Parse(time.RFC3339, header); err == nil {
+		deviceTime = parsed
+	} else {
+		return
+	}
+
+	_ = h.deviceService.RecordClockSkew(c.Request.Context(), deviceID, deviceTime, time.Now())
+}
+
 // DeviceConfigResponse 设备配置响应
 type DeviceConfigResponse struct {
-	DeviceID         uuid.UUID                      `json:"device_id"`
-	VirtualIP        string                         `json:"virtual_ip"`
-	VirtualNetworkID uuid.UUID                      `json:"virtual_network_id"`
-	Platform         string                         `json:"platform"`
-	Peers            []crypto.WireGuardPeerConfig  `json:"peers"`
-	UpdatedAt        time.Time                      `json:"updated_at"`
+	DeviceID          uuid.UUID                    `json:"device_id"`
+	VirtualIP         string                       `json:"virtual_ip"`
+	VirtualNetworkID  uuid.UUID                    `json:"virtual_network_id"`
+	Platform          string                       `json:"platform"`
+	ApprovalStatus    domain.DeviceApprovalStatus  `json:"approval_status"`              // pending时peers为空，批准后重新拉取
+	PostureStatus     domain.DevicePostureStatus   `json:"posture_status"`               // non_compliant时按策略被隔离或限制对端
+	PostureViolations []string                     `json:"posture_violations,omitempty"` // 恢复合规后重新拉取配置
+	Peers             []crypto.WireGuardPeerConfig `json:"peers"`
+	UpdatedAt         time.Time                    `json:"updated_at"`
 }
 
 // DeviceMetricsRequest 设备指标请求
 type DeviceMetricsRequest struct {
-	Online          bool              `json:"online"`
-	BytesSent       int64             `json:"bytes_sent"`
-	BytesReceived   int64             `json:"bytes_received"`
-	LatencyMs       map[string]int    `json:"latency_ms"` // peerID -> latency
-	PacketLoss      map[string]float64 `json:"packet_loss"` // peerID -> loss rate
-	PublicEndpoint  string            `json:"public_endpoint,omitempty"`
+	Online         bool                  `json:"online"`
+	BytesSent      int64                 `json:"bytes_sent"`
+	BytesReceived  int64                 `json:"bytes_received"`
+	LatencyMs      map[string]int        `json:"latency_ms"`  // peerID -> latency
+	PacketLoss     map[string]float64    `json:"packet_loss"` // peerID -> loss rate (0-1)
+	PublicEndpoint string                `json:"public_endpoint,omitempty"`
+	Posture        *domain.DevicePosture `json:"posture,omitempty"` // 安全状态，客户端可按较低频率随指标上报
 }
 
 // ErrorResponse 错误响应
@@ -0,0 +1,832 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/edgelink/backend/cmd/api-gateway/internal/websocket"
+	"github.com/edgelink/backend/internal/audit"
+	"github.com/edgelink/backend/internal/domain"
+	"github.com/edgelink/backend/internal/repository"
+	"github.com/edgelink/backend/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+)
+
+// 设备变更事件动作
+const (
+	DeviceUpdateActionUpdated = "updated" // 名称或标签变更
+	DeviceUpdateActionMoved   = "moved"   // 迁移到其他虚拟网络
+)
+
+// device_removed事件的原因
+const (
+	DeviceRemovedReasonMoved   = "moved"
+	DeviceRemovedReasonDeleted = "deleted"
+)
+
+// maxBulkDevices 单次批量操作最多影响的设备数，超过时要求缩小过滤范围
+const maxBulkDevices = 1000
+
+// DeviceManagementHandler 设备管理处理器（修改名称和标签、迁移网络、批量操作）
+// 变更经审计中间件记录，处理器通过audit.ContextBeforeState/ContextAfterState写入变更前后的设备快照
+type DeviceManagementHandler struct {
+	deviceService   *service.DeviceService
+	topologyService *service.TopologyService
+	deviceRepo      repository.DeviceRepository
+	vnRepo          repository.VirtualNetworkRepository
+	broadcaster     *websocket.Broadcaster
+	logger          *zap.Logger
+}
+
+// NewDeviceManagementHandler 创建DeviceManagementHandler实例
+func NewDeviceManagementHandler(
+	deviceService *service.DeviceService,
+	topologyService *service.TopologyService,
+	deviceRepo repository.DeviceRepository,
+	vnRepo repository.VirtualNetworkRepository,
+	broadcaster *websocket.Broadcaster,
+	logger *zap.Logger,
+) *DeviceManagementHandler {
+	return &DeviceManagementHandler{
+		deviceService:   deviceService,
+		topologyService: topologyService,
+		deviceRepo:      deviceRepo,
+		vnRepo:          vnRepo,
+		broadcaster:     broadcaster,
+		logger:          logger,
+	}
+}
+
+// UpdateDeviceRequest 修改设备请求，省略的字段保持不变；tags整体替换
+type UpdateDeviceRequest struct {
+	Name *string   `json:"name"`
+	Tags *[]string `json:"tags"`
+}
+
+// MoveDeviceRequest 迁移设备请求
+type MoveDeviceRequest struct {
+	VirtualNetworkID string `json:"virtual_network_id" binding:"required"`
+}
+
+// DeviceFilterRequest 批量操作过滤条件，各条件同时满足，至少指定一个
+type DeviceFilterRequest struct {
+	DeviceIDs        []string `json:"device_ids"`
+	VirtualNetworkID string   `json:"virtual_network_id"`
+	Tag              string   `json:"tag"`
+	Platform         string   `json:"platform"`
+	Online           *bool    `json:"online"`
+	ApprovalStatus   string   `json:"approval_status"`
+}
+
+// BulkTagDevicesRequest 批量增删标签请求
+type BulkTagDevicesRequest struct {
+	Filter     DeviceFilterRequest `json:"filter"`
+	AddTags    []string            `json:"add_tags"`
+	RemoveTags []string            `json:"remove_tags"`
+	DryRun     bool                `json:"dry_run"` // 只返回受影响的设备，不做变更
+}
+
+// BulkApproveDevicesRequest 批量批准请求，只作用于待审批和已拒绝的设备
+type BulkApproveDevicesRequest struct {
+	Filter DeviceFilterRequest `json:"filter"`
+	Note   string              `json:"note"`
+	DryRun bool                `json:"dry_run"`
+}
+
+// BulkDeleteDevicesRequest 批量删除请求
+type BulkDeleteDevicesRequest struct {
+	Filter DeviceFilterRequest `json:"filter"`
+	DryRun bool                `json:"dry_run"`
+}
+
+// BulkDeviceResult 批量操作中单台设备的结果（dry-run时为预期结果）
+type BulkDeviceResult struct {
+	DeviceID         uuid.UUID                   `json:"device_id"`
+	Name             string                      `json:"name"`
+	VirtualNetworkID uuid.UUID                   `json:"virtual_network_id"`
+	VirtualIP        string                      `json:"virtual_ip"`
+	Tags             []string                    `json:"tags"`
+	ApprovalStatus   domain.DeviceApprovalStatus `json:"approval_status"`
+}
+
+// BulkDeviceOperationResponse 批量操作响应
+type BulkDeviceOperationResponse struct {
+	DryRun   bool               `json:"dry_run"`
+	Matched  int                `json:"matched"`  // 符合过滤条件的设备数
+	Affected int                `json:"affected"` // 实际（dry-run时为将要）变更的设备数
+	Failed   int                `json:"failed"`   // 变更失败的设备数，详见网关日志
+	Devices  []BulkDeviceResult `json:"devices"`
+}
+
+// DeviceUpdatedEvent 通过WebSocket广播的设备变更事件
+type DeviceUpdatedEvent struct {
+	DeviceID                 uuid.UUID  `json:"device_id"`
+	VirtualNetworkID         uuid.UUID  `json:"virtual_network_id"`
+	PreviousVirtualNetworkID *uuid.UUID `json:"previous_virtual_network_id,omitempty"` // 仅迁移时
+	Name                     string     `json:"name"`
+	Tags                     []string   `json:"tags"`
+	VirtualIP                string     `json:"virtual_ip"`
+	PreviousVirtualIP        string     `json:"previous_virtual_ip,omitempty"` // 仅迁移时
+	Action                   string     `json:"action"`
+	ActorID                  *uuid.UUID `json:"actor_id,omitempty"`
+	Timestamp                time.Time  `json:"timestamp"`
+}
+
+// DeviceRemovedEvent 通过WebSocket广播的设备移出网络事件（与后台任务发布的device_removed一致）
+type DeviceRemovedEvent struct {
+	DeviceID         uuid.UUID `json:"device_id"`
+	VirtualNetworkID uuid.UUID `json:"virtual_network_id"`
+	VirtualIP        string    `json:"virtual_ip"`
+	PublicKey        string    `json:"public_key"`
+	Reason           string    `json:"reason"`
+}
+
+// UpdateDevice godoc
+// @Summary      修改设备名称和标签
+// @Description  标签变更后按网络的安全状态策略重新评估，并广播device_updated事件
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        device_id  path  string               true  "设备ID"
+// @Param        request    body  UpdateDeviceRequest  true  "修改内容"
+// @Success      200  {object}  domain.Device
+// @Failure      400  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/v1/admin/devices/{device_id} [patch]
+func (h *DeviceManagementHandler) UpdateDevice(c *gin.Context) {
+	var req UpdateDeviceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+	if req.Name == nil && req.Tags == nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "empty_update",
+			Message: "at least one of name or tags is required",
+		})
+		return
+	}
+
+	var name string
+	if req.Name != nil {
+		name = strings.TrimSpace(*req.Name)
+		if name == "" || len(name) > 255 {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "invalid_name",
+				Message: "name must be 1-255 characters",
+			})
+			return
+		}
+	}
+	var tags pq.StringArray
+	if req.Tags != nil {
+		var err error
+		if tags, err = domain.NormalizeDeviceTags(*req.Tags); err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "invalid_tags",
+				Message: err.Error(),
+			})
+			return
+		}
+	}
+
+	device, ok := h.findDevice(c)
+	if !ok {
+		return
+	}
+	c.Set(audit.ContextBeforeState, device.AuditState())
+
+	if req.Name != nil {
+		device.Name = name
+	}
+	if req.Tags != nil {
+		device.Tags = tags
+	}
+
+	ctx := c.Request.Context()
+	postureChanged, err := h.deviceService.UpdateDevice(ctx, device)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "update_failed",
+			Message: err.Error(),
+		})
+		return
+	}
+	c.Set(audit.ContextAfterState, device.AuditState())
+
+	h.publishUpdated(ctx, device, DeviceUpdateActionUpdated, nil, "", actorIDFromHeader(c))
+	if postureChanged {
+		publishDevicePosture(ctx, h.broadcaster, h.logger, device, device.VirtualNetwork.OrganizationID)
+	}
+
+	c.JSON(http.StatusOK, device)
+}
+
+// MoveDevice godoc
+// @Summary      将设备迁移到其他虚拟网络
+// @Description  在目标网络中重新分配虚拟IP，旧网络广播device_removed、新网络广播device_updated，双方重新拉取配置。只能在同一组织内迁移
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        device_id  path  string             true  "设备ID"
+// @Param        request    body  MoveDeviceRequest  true  "目标虚拟网络"
+// @Success      200  {object}  domain.Device
+// @Failure      400  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse
+// @Failure      409  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/v1/admin/devices/{device_id}/move [post]
+func (h *DeviceManagementHandler) MoveDevice(c *gin.Context) {
+	var req MoveDeviceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+	targetID, err := uuid.Parse(req.VirtualNetworkID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_virtual_network_id",
+			Message: "virtual_network_id must be a valid UUID",
+		})
+		return
+	}
+
+	device, ok := h.findDevice(c)
+	if !ok {
+		return
+	}
+	if device.VirtualNetworkID == targetID {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "same_network",
+			Message: "device is already in this virtual network",
+		})
+		return
+	}
+
+	ctx := c.Request.Context()
+	target, err := h.vnRepo.FindByID(ctx, targetID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:   "network_not_found",
+			Message: "virtual network not found",
+		})
+		return
+	}
+	if target.OrganizationID != device.VirtualNetwork.OrganizationID {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "cross_organization_move",
+			Message: "device can only be moved within its organization",
+		})
+		return
+	}
+	c.Set(audit.ContextBeforeState, device.AuditState())
+
+	virtualIP, err := h.topologyService.AllocateVirtualIP(ctx, target.ID)
+	if err != nil {
+		c.JSON(http.StatusConflict, ErrorResponse{
+			Error:   "ip_allocation_failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	previous := *device
+	moved, err := h.deviceService.MoveDevice(ctx, device, target, virtualIP)
+	if err != nil || !moved {
+		h.topologyService.ReleaseVirtualIP(ctx, target.ID, virtualIP)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, ErrorResponse{
+				Error:   "move_failed",
+				Message: err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusConflict, ErrorResponse{
+			Error:   "device_changed",
+			Message: "device was moved or deleted by another request",
+		})
+		return
+	}
+	h.topologyService.ReleaseVirtualIP(ctx, previous.VirtualNetworkID, previous.VirtualIP)
+	c.Set(audit.ContextAfterState, device.AuditState())
+
+	// 旧网络的设备移除该peer，新网络的设备和迁移的设备本身重新拉取配置
+	h.publishRemoved(ctx, &previous, DeviceRemovedReasonMoved)
+	h.publishUpdated(ctx, device, DeviceUpdateActionMoved, &previous.VirtualNetworkID, previous.VirtualIP, actorIDFromHeader(c))
+	if device.PostureStatus != previous.PostureStatus ||
+		!device.PostureAllowedPeerTagSets.Equal(previous.PostureAllowedPeerTagSets) {
+		publishDevicePosture(ctx, h.broadcaster, h.logger, device, target.OrganizationID)
+	}
+	for _, vnID := range []uuid.UUID{previous.VirtualNetworkID, target.ID} {
+		if err := h.topologyService.RefreshVirtualNetworkTopology(ctx, vnID); err != nil {
+			h.logger.Warn("Failed to refresh virtual network topology",
+				zap.String("network_id", vnID.String()),
+				zap.Error(err),
+			)
+		}
+	}
+
+	h.logger.Info("Device moved",
+		zap.String("device_id", device.ID.String()),
+		zap.String("from_network_id", previous.VirtualNetworkID.String()),
+		zap.String("to_network_id", target.ID.String()),
+		zap.String("virtual_ip", device.VirtualIP),
+	)
+
+	c.JSON(http.StatusOK, device)
+}
+
+// BulkTagDevices godoc
+// @Summary      批量增删设备标签
+// @Description  为符合过滤条件的设备添加和移除标签，标签未变化的设备不计入affected；dry_run只返回预期结果
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        request  body  BulkTagDevicesRequest  true  "批量标签请求"
+// @Success      200  {object}  BulkDeviceOperationResponse
+// @Failure      400  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/v1/admin/devices/bulk/tag [post]
+func (h *DeviceManagementHandler) BulkTagDevices(c *gin.Context) {
+	var req BulkTagDevicesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+	if len(req.AddTags) == 0 && len(req.RemoveTags) == 0 {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "empty_update",
+			Message: "at least one of add_tags or remove_tags is required",
+		})
+		return
+	}
+	addTags, err := domain.NormalizeDeviceTags(req.AddTags)
+	if err == nil {
+		_, err = domain.NormalizeDeviceTags(req.RemoveTags)
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_tags",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	devices, ok := h.findBulkTargets(c, &req.Filter)
+	if !ok {
+		return
+	}
+
+	// 计算每台设备的新标签，未变化的设备跳过
+	type tagChange struct {
+		device *domain.Device
+		tags   pq.StringArray
+	}
+	changes := make([]tagChange, 0, len(devices))
+	for i := range devices {
+		device := &devices[i]
+		tags, err := retag(device.Tags, addTags, req.RemoveTags)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "invalid_tags",
+				Message: device.ID.String() + ": " + err.Error(),
+			})
+			return
+		}
+		if strings.Join(tags, "\n") != strings.Join(device.Tags, "\n") {
+			changes = append(changes, tagChange{device: device, tags: tags})
+		}
+	}
+
+	resp := BulkDeviceOperationResponse{
+		DryRun:  req.DryRun,
+		Matched: len(devices),
+		Devices: make([]BulkDeviceResult, 0, len(changes)),
+	}
+	if req.DryRun {
+		c.Set(audit.ContextSkipAudit, true)
+		for _, change := range changes {
+			result := bulkDeviceResult(change.device)
+			result.Tags = change.tags
+			resp.Devices = append(resp.Devices, result)
+		}
+		resp.Affected = len(resp.Devices)
+		c.JSON(http.StatusOK, resp)
+		return
+	}
+
+	ctx := c.Request.Context()
+	actorID := actorIDFromHeader(c)
+	before := make([]domain.JSONB, 0, len(changes))
+	after := make([]domain.JSONB, 0, len(changes))
+	for _, change := range changes {
+		device := change.device
+		state := device.AuditState()
+
+		device.Tags = change.tags
+		postureChanged, err := h.deviceService.UpdateDevice(ctx, device)
+		if err != nil {
+			h.logger.Error("Failed to update device tags",
+				zap.String("device_id", device.ID.String()),
+				zap.Error(err),
+			)
+			resp.Failed++
+			continue
+		}
+
+		before = append(before, state)
+		after = append(after, device.AuditState())
+		resp.Devices = append(resp.Devices, bulkDeviceResult(device))
+
+		h.publishUpdated(ctx, device, DeviceUpdateActionUpdated, nil, "", actorID)
+		if postureChanged && device.VirtualNetwork != nil {
+			publishDevicePosture(ctx, h.broadcaster, h.logger, device, device.VirtualNetwork.OrganizationID)
+		}
+	}
+	resp.Affected = len(resp.Devices)
+	h.setBulkAuditState(c, devices, before, after)
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// BulkApproveDevices godoc
+// @Summary      批量批准设备
+// @Description  批准符合过滤条件的待审批和已拒绝设备，已批准的设备不计入affected；dry_run只返回将被批准的设备
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        request  body  BulkApproveDevicesRequest  true  "批量批准请求"
+// @Success      200  {object}  BulkDeviceOperationResponse
+// @Failure      400  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/v1/admin/devices/bulk/approve [post]
+func (h *DeviceManagementHandler) BulkApproveDevices(c *gin.Context) {
+	var req BulkApproveDevicesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	devices, ok := h.findBulkTargets(c, &req.Filter)
+	if !ok {
+		return
+	}
+
+	from := []domain.DeviceApprovalStatus{domain.DeviceApprovalPending, domain.DeviceApprovalRejected}
+	candidates := make([]*domain.Device, 0, len(devices))
+	for i := range devices {
+		if !devices[i].IsApproved() {
+			candidates = append(candidates, &devices[i])
+		}
+	}
+
+	resp := BulkDeviceOperationResponse{
+		DryRun:  req.DryRun,
+		Matched: len(devices),
+		Devices: make([]BulkDeviceResult, 0, len(candidates)),
+	}
+	if req.DryRun {
+		c.Set(audit.ContextSkipAudit, true)
+		for _, device := range candidates {
+			result := bulkDeviceResult(device)
+			result.ApprovalStatus = domain.DeviceApprovalApproved
+			resp.Devices = append(resp.Devices, result)
+		}
+		resp.Affected = len(resp.Devices)
+		c.JSON(http.StatusOK, resp)
+		return
+	}
+
+	ctx := c.Request.Context()
+	actorID := actorIDFromHeader(c)
+	now := time.Now()
+	before := make([]domain.JSONB, 0, len(candidates))
+	after := make([]domain.JSONB, 0, len(candidates))
+	for _, device := range candidates {
+		state := device.AuditState()
+
+		// 条件更新：查询之后已被批准或删除的设备跳过
+		updated, err := h.deviceRepo.SetApprovalStatus(ctx, device.ID, from, domain.DeviceApprovalApproved, actorID, req.Note, now)
+		if err != nil {
+			h.logger.Error("Failed to approve device",
+				zap.String("device_id", device.ID.String()),
+				zap.Error(err),
+			)
+			resp.Failed++
+			continue
+		}
+		if !updated {
+			continue
+		}
+
+		device.ApprovalStatus = domain.DeviceApprovalApproved
+		device.ApprovalDecidedAt = &now
+		device.ApprovalDecidedBy = actorID
+		device.ApprovalNote = req.Note
+
+		before = append(before, state)
+		after = append(after, device.AuditState())
+		resp.Devices = append(resp.Devices, bulkDeviceResult(device))
+
+		publishDeviceApproval(ctx, h.broadcaster, h.logger, device, DeviceApprovalActionApproved, actorID, req.Note)
+	}
+	resp.Affected = len(resp.Devices)
+	h.setBulkAuditState(c, devices, before, after)
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// BulkDeleteDevices godoc
+// @Summary      批量删除设备
+// @Description  删除符合过滤条件的设备并释放虚拟IP，同网络的设备收到device_removed事件；dry_run只返回将被删除的设备
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        request  body  BulkDeleteDevicesRequest  true  "批量删除请求"
+// @Success      200  {object}  BulkDeviceOperationResponse
+// @Failure      400  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/v1/admin/devices/bulk/delete [post]
+func (h *DeviceManagementHandler) BulkDeleteDevices(c *gin.Context) {
+	var req BulkDeleteDevicesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	devices, ok := h.findBulkTargets(c, &req.Filter)
+	if !ok {
+		return
+	}
+
+	resp := BulkDeviceOperationResponse{
+		DryRun:  req.DryRun,
+		Matched: len(devices),
+		Devices: make([]BulkDeviceResult, 0, len(devices)),
+	}
+	if req.DryRun {
+		c.Set(audit.ContextSkipAudit, true)
+		for i := range devices {
+			resp.Devices = append(resp.Devices, bulkDeviceResult(&devices[i]))
+		}
+		resp.Affected = len(resp.Devices)
+		c.JSON(http.StatusOK, resp)
+		return
+	}
+
+	ctx := c.Request.Context()
+	before := make([]domain.JSONB, 0, len(devices))
+	for i := range devices {
+		device := &devices[i]
+		if err := h.deviceRepo.Delete(ctx, device.ID); err != nil {
+			h.logger.Error("Failed to delete device",
+				zap.String("device_id", device.ID.String()),
+				zap.Error(err),
+			)
+			resp.Failed++
+			continue
+		}
+		h.topologyService.ReleaseVirtualIP(ctx, device.VirtualNetworkID, device.VirtualIP)
+
+		before = append(before, device.AuditState())
+		resp.Devices = append(resp.Devices, bulkDeviceResult(device))
+
+		h.publishRemoved(ctx, device, DeviceRemovedReasonDeleted)
+	}
+	resp.Affected = len(resp.Devices)
+	h.setBulkAuditState(c, devices, before, []domain.JSONB{})
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// findDevice 解析路径中的device_id并查找设备（含虚拟网络），失败时已写入响应
+func (h *DeviceManagementHandler) findDevice(c *gin.Context) (*domain.Device, bool) {
+	deviceID, err := uuid.Parse(c.Param("device_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_device_id",
+			Message: "device_id must be a valid UUID",
+		})
+		return nil, false
+	}
+
+	device, err := h.deviceRepo.FindByID(c.Request.Context(), deviceID)
+	if err != nil || device.VirtualNetwork == nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:   "device_not_found",
+			Message: "device not found",
+		})
+		return nil, false
+	}
+
+	c.Set(audit.ContextOrganizationID, device.VirtualNetwork.OrganizationID)
+	return device, true
+}
+
+// findBulkTargets 按过滤条件查询批量操作的目标设备，失败或超过上限时已写入响应
+func (h *DeviceManagementHandler) findBulkTargets(c *gin.Context, f *DeviceFilterRequest) ([]domain.Device, bool) {
+	filters, ok := f.toDeviceFilters(c)
+	if !ok {
+		return nil, false
+	}
+
+	devices, err := h.deviceRepo.FindByFilters(c.Request.Context(), filters, maxBulkDevices+1)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "query_failed",
+			Message: err.Error(),
+		})
+		return nil, false
+	}
+	if len(devices) > maxBulkDevices {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "too_many_devices",
+			Message: "filter matches more than 1000 devices, narrow it down",
+		})
+		return nil, false
+	}
+
+	return devices, true
+}
+
+// setBulkAuditState 写入批量操作的审计快照；目标设备属于同一组织时记录该组织
+func (h *DeviceManagementHandler) setBulkAuditState(c *gin.Context, devices []domain.Device, before, after []domain.JSONB) {
+	c.Set(audit.ContextBeforeState, domain.JSONB{"devices": before})
+	c.Set(audit.ContextAfterState, domain.JSONB{"devices": after})
+
+	var orgID uuid.UUID
+	for i := range devices {
+		if devices[i].VirtualNetwork == nil {
+			return
+		}
+		id := devices[i].VirtualNetwork.OrganizationID
+		if orgID != uuid.Nil && orgID != id {
+			return
+		}
+		orgID = id
+	}
+	if orgID != uuid.Nil {
+		c.Set(audit.ContextOrganizationID, orgID)
+	}
+}
+
+// publishUpdated 广播device_updated事件，失败只记录日志
+func (h *DeviceManagementHandler) publishUpdated(ctx context.Context, device *domain.Device, action string, previousVNID *uuid.UUID, previousIP string, actorID *uuid.UUID) {
+	orgID := ""
+	if device.VirtualNetwork != nil {
+		orgID = device.VirtualNetwork.OrganizationID.String()
+	}
+
+	event := DeviceUpdatedEvent{
+		DeviceID:                 device.ID,
+		VirtualNetworkID:         device.VirtualNetworkID,
+		PreviousVirtualNetworkID: previousVNID,
+		Name:                     device.Name,
+		Tags:                     device.Tags,
+		VirtualIP:                device.VirtualIP,
+		PreviousVirtualIP:        previousIP,
+		Action:                   action,
+		ActorID:                  actorID,
+		Timestamp:                time.Now(),
+	}
+	if err := h.broadcaster.PublishDeviceUpdated(ctx, device.ID.String(), orgID, event); err != nil {
+		h.logger.Error("Failed to publish device updated event",
+			zap.String("device_id", device.ID.String()),
+			zap.String("action", action),
+			zap.Error(err),
+		)
+	}
+}
+
+// publishRemoved 广播device_removed事件，通知原网络的设备移除该peer，失败只记录日志
+func (h *DeviceManagementHandler) publishRemoved(ctx context.Context, device *domain.Device, reason string) {
+	orgID := ""
+	if device.VirtualNetwork != nil {
+		orgID = device.VirtualNetwork.OrganizationID.String()
+	}
+
+	event := DeviceRemovedEvent{
+		DeviceID:         device.ID,
+		VirtualNetworkID: device.VirtualNetworkID,
+		VirtualIP:        device.VirtualIP,
+		PublicKey:        device.PublicKey,
+		Reason:           reason,
+	}
+	if err := h.broadcaster.PublishDeviceRemoved(ctx, device.ID.String(), orgID, event); err != nil {
+		h.logger.Error("Failed to publish device removed event",
+			zap.String("device_id", device.ID.String()),
+			zap.String("reason", reason),
+			zap.Error(err),
+		)
+	}
+}
+
+// toDeviceFilters 转换为仓储过滤条件，要求至少指定一个条件以避免误操作全部设备
+func (f *DeviceFilterRequest) toDeviceFilters(c *gin.Context) (*repository.DeviceFilters, bool) {
+	filters := &repository.DeviceFilters{
+		Online: f.Online,
+	}
+
+	for _, idStr := range f.DeviceIDs {
+		id, err := uuid.Parse(idStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "invalid_device_id",
+				Message: "device_ids must contain valid UUIDs",
+			})
+			return nil, false
+		}
+		filters.DeviceIDs = append(filters.DeviceIDs, id)
+	}
+
+	if f.VirtualNetworkID != "" {
+		vnID, err := uuid.Parse(f.VirtualNetworkID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "invalid_virtual_network_id",
+				Message: "virtual_network_id must be a valid UUID",
+			})
+			return nil, false
+		}
+		filters.VirtualNetworkID = &vnID
+	}
+
+	if tag := strings.TrimSpace(f.Tag); tag != "" {
+		filters.Tag = &tag
+	}
+
+	if f.Platform != "" {
+		platform := domain.Platform(f.Platform)
+		filters.Platform = &platform
+	}
+
+	if f.ApprovalStatus != "" {
+		status := domain.DeviceApprovalStatus(f.ApprovalStatus)
+		filters.ApprovalStatus = &status
+	}
+
+	if len(filters.DeviceIDs) == 0 && filters.VirtualNetworkID == nil && filters.Tag == nil &&
+		filters.Platform == nil && filters.Online == nil && filters.ApprovalStatus == nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "empty_filter",
+			Message: "at least one filter field is required",
+		})
+		return nil, false
+	}
+
+	return filters, true
+}
+
+// retag 从现有标签中移除remove并追加add，返回规范化后的标签
+func retag(current pq.StringArray, add []string, remove []string) (pq.StringArray, error) {
+	removed := make(map[string]bool, len(remove))
+	for _, tag := range remove {
+		removed[strings.TrimSpace(tag)] = true
+	}
+
+	tags := make([]string, 0, len(current)+len(add))
+	for _, tag := range current {
+		if !removed[tag] {
+			tags = append(tags, tag)
+		}
+	}
+	tags = append(tags, add...)
+	return domain.NormalizeDeviceTags(tags)
+}
+
+// bulkDeviceResult 批量操作结果中的设备摘要
+func bulkDeviceResult(device *domain.Device) BulkDeviceResult {
+	tags := []string(device.Tags)
+	if tags == nil {
+		tags = []string{}
+	}
+	return BulkDeviceResult{
+		DeviceID:         device.ID,
+		Name:             device.Name,
+		VirtualNetworkID: device.VirtualNetworkID,
+		VirtualIP:        device.VirtualIP,
+		Tags:             tags,
+		ApprovalStatus:   device.ApprovalStatus,
+	}
+}
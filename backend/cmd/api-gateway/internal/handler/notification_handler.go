@@ -0,0 +1,394 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/edgelink/backend/internal/domain"
+	"github.com/edgelink/backend/internal/repository"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// NotificationHandler 通知投递记录处理器
+type NotificationHandler struct {
+	deliveryRepo     repository.NotificationDeliveryRepository
+	emailHistoryRepo repository.EmailHistoryRepository
+	alertRepo        repository.AlertRepository
+	endpointRepo     repository.WebhookEndpointRepository
+	logger           *zap.Logger
+}
+
+// NewNotificationHandler 创建NotificationHandler实例
+func NewNotificationHandler(
+	deliveryRepo repository.NotificationDeliveryRepository,
+	emailHistoryRepo repository.EmailHistoryRepository,
+	alertRepo repository.AlertRepository,
+	endpointRepo repository.WebhookEndpointRepository,
+	logger *zap.Logger,
+) *NotificationHandler {
+	return &NotificationHandler{
+		deliveryRepo:     deliveryRepo,
+		emailHistoryRepo: emailHistoryRepo,
+		alertRepo:        alertRepo,
+		endpointRepo:     endpointRepo,
+		logger:           logger,
+	}
+}
+
+// GetAlertNotifications godoc
+// @Summary      获取告警通知历史
+// @Description  获取告警的全部通知投递记录（含每次尝试的状态、耗时和错误）以及邮件发送历史
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        alert_id  path  string  true  "告警ID"
+// @Success      200  {object}  AlertNotificationHistoryResponse
+// @Failure      400  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/v1/admin/alerts/{alert_id}/notifications [get]
+func (h *NotificationHandler) GetAlertNotifications(c *gin.Context) {
+	alertID, ok := parseAlertID(c)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	if _, err := h.alertRepo.FindByID(ctx, alertID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Error:   "alert_not_found",
+				Message: "Alert not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "query_failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	deliveries, err := h.deliveryRepo.FindByAlertID(ctx, alertID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "query_failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	emails, err := h.emailHistoryRepo.GetByAlertID(ctx, alertID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "query_failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	emailItems := make([]EmailHistoryResponse, 0, len(emails))
+	for _, email := range emails {
+		emailItems = append(emailItems, newEmailHistoryResponse(email))
+	}
+
+	c.JSON(http.StatusOK, AlertNotificationHistoryResponse{
+		AlertID:    alertID,
+		Deliveries: deliveries,
+		Emails:     emailItems,
+	})
+}
+
+// GetDeadLetters godoc
+// @Summary      获取通知死信列表
+// @Description  获取重试耗尽的通知投递，可按通道过滤
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        channel  query  string  false  "通知通道 (email/webhook/slack/...)"
+// @Param        limit    query  int     false  "返回数量限制"
+// @Param        offset   query  int     false  "偏移量"
+// @Success      200  {object}  DeadLetterListResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/v1/admin/notifications/dead-letters [get]
+func (h *NotificationHandler) GetDeadLetters(c *gin.Context) {
+	filters := &repository.DeadLetterFilters{
+		Limit:  50,
+		Offset: 0,
+	}
+
+	if channel := c.Query("channel"); channel != "" {
+		filters.Channel = &channel
+	}
+
+	if limitStr := c.Query("limit"); limitStr != "" {
+		filters.Limit, _ = strconv.Atoi(limitStr)
+	}
+
+	if offsetStr := c.Query("offset"); offsetStr != "" {
+		filters.Offset, _ = strconv.Atoi(offsetStr)
+	}
+
+	deliveries, total, err := h.deliveryRepo.FindDeadLetters(c.Request.Context(), filters)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "query_failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, DeadLetterListResponse{
+		Deliveries: deliveries,
+		Total:      int(total),
+		Limit:      filters.Limit,
+		Offset:     filters.Offset,
+	})
+}
+
+// RedriveDelivery godoc
+// @Summary      重新投递死信
+// @Description  将死信通知重新放回投递队列，重置尝试次数
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        delivery_id  path  string  true  "投递ID"
+// @Success      200  {object}  domain.NotificationDelivery
+// @Failure      400  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse
+// @Failure      409  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/v1/admin/notifications/{delivery_id}/redrive [post]
+func (h *NotificationHandler) RedriveDelivery(c *gin.Context) {
+	deliveryID, err := uuid.Parse(c.Param("delivery_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_delivery_id",
+			Message: "delivery_id must be a valid UUID",
+		})
+		return
+	}
+
+	ctx := c.Request.Context()
+	delivery, err := h.deliveryRepo.FindByID(ctx, deliveryID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Error:   "delivery_not_found",
+				Message: "Notification delivery not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "query_failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if delivery.Status != domain.DeliveryStatusDead {
+		c.JSON(http.StatusConflict, ErrorResponse{
+			Error:   "delivery_not_dead",
+			Message: "Only dead-lettered notifications can be redriven",
+		})
+		return
+	}
+
+	if err := h.deliveryRepo.Redrive(ctx, deliveryID, time.Now()); err != nil {
+		// 并发重投时记录可能已不在死信列表中
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusConflict, ErrorResponse{
+				Error:   "delivery_not_dead",
+				Message: "Only dead-lettered notifications can be redriven",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "redrive_failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	h.logger.Info("Notification delivery redriven",
+		zap.String("delivery_id", deliveryID.String()),
+		zap.String("alert_id", delivery.AlertID.String()),
+		zap.String("channel", delivery.Channel),
+	)
+
+	updated, err := h.deliveryRepo.FindByID(ctx, deliveryID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "query_failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, updated)
+}
+
+// GetWebhookEndpoints godoc
+// @Summary      获取Webhook端点状态
+// @Description  获取出站Webhook端点的投递健康状态，持续失败的端点会被自动停用
+// @Tags         admin
+// @Produce      json
+// @Param        status  query  string  false  "端点状态 (active/disabled)"
+// @Success      200  {object}  WebhookEndpointListResponse
+// @Failure      400  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/v1/admin/notifications/webhook-endpoints [get]
+func (h *NotificationHandler) GetWebhookEndpoints(c *gin.Context) {
+	status := domain.WebhookEndpointStatus(c.Query("status"))
+	if status != "" && status != domain.WebhookEndpointStatusActive && status != domain.WebhookEndpointStatusDisabled {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_status",
+			Message: "status must be active or disabled",
+		})
+		return
+	}
+
+	endpoints, err := h.endpointRepo.List(c.Request.Context(), status)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "query_failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, WebhookEndpointListResponse{
+		Endpoints: endpoints,
+		Total:     len(endpoints),
+	})
+}
+
+// EnableWebhookEndpoint godoc
+// @Summary      重新启用Webhook端点
+// @Description  清零失败计数并恢复投递，同时解决端点停用时产生的告警；停用期间进入死信的通知可通过redrive重新投递
+// @Tags         admin
+// @Produce      json
+// @Param        endpoint_id  path  string  true  "端点ID"
+// @Success      200  {object}  domain.WebhookEndpoint
+// @Failure      400  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/v1/admin/notifications/webhook-endpoints/{endpoint_id}/enable [post]
+func (h *NotificationHandler) EnableWebhookEndpoint(c *gin.Context) {
+	endpointID, err := uuid.Parse(c.Param("endpoint_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_endpoint_id",
+			Message: "endpoint_id must be a valid UUID",
+		})
+		return
+	}
+
+	ctx := c.Request.Context()
+	endpoint, err := h.endpointRepo.FindByID(ctx, endpointID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Error:   "endpoint_not_found",
+				Message: "Webhook endpoint not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "query_failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if err := h.endpointRepo.Enable(ctx, endpointID); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "enable_failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	// 端点恢复后停用告警随之解决
+	if endpoint.DisabledAlertID != nil {
+		alert, err := h.alertRepo.FindByID(ctx, *endpoint.DisabledAlertID)
+		if err == nil && alert.Status != domain.AlertStatusResolved {
+			if err := h.alertRepo.Resolve(ctx, alert.ID); err != nil {
+				h.logger.Warn("Failed to resolve webhook disabled alert",
+					zap.String("alert_id", alert.ID.String()),
+					zap.Error(err),
+				)
+			}
+		}
+	}
+
+	h.logger.Info("Webhook endpoint re-enabled",
+		zap.String("endpoint_id", endpointID.String()),
+		zap.String("endpoint", endpoint.Name),
+	)
+
+	updated, err := h.endpointRepo.FindByID(ctx, endpointID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "query_failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, updated)
+}
+
+// newEmailHistoryResponse 构建邮件历史响应
+func newEmailHistoryResponse(history *repository.EmailHistory) EmailHistoryResponse {
+	return EmailHistoryResponse{
+		ID:         history.ID,
+		Provider:   history.Provider,
+		Recipients: history.Recipients,
+		Subject:    history.Subject,
+		Status:     history.Status,
+		Attempts:   history.Attempts,
+		LastError:  history.LastError,
+		SentAt:     history.SentAt,
+		CreatedAt:  history.CreatedAt,
+	}
+}
+
+// AlertNotificationHistoryResponse 告警通知历史响应
+type AlertNotificationHistoryResponse struct {
+	AlertID    uuid.UUID                      `json:"alert_id"`
+	Deliveries []*domain.NotificationDelivery `json:"deliveries"`
+	Emails     []EmailHistoryResponse         `json:"emails"`
+}
+
+// EmailHistoryResponse 邮件发送历史
+type EmailHistoryResponse struct {
+	ID         uuid.UUID  `json:"id"`
+	Provider   string     `json:"provider"`
+	Recipients []string   `json:"recipients"`
+	Subject    string     `json:"subject"`
+	Status     string     `json:"status"`
+	Attempts   int        `json:"attempts"`
+	LastError  *string    `json:"last_error,omitempty"`
+	SentAt     *time.Time `json:"sent_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// DeadLetterListResponse 死信列表响应
+type DeadLetterListResponse struct {
+	Deliveries []*domain.NotificationDelivery `json:"deliveries"`
+	Total      int                            `json:"total"`
+	Limit      int                            `json:"limit"`
+	Offset     int                            `json:"offset"`
+}
+
+// WebhookEndpointListResponse Webhook端点列表响应
+type WebhookEndpointListResponse struct {
+	Endpoints []*domain.WebhookEndpoint `json:"endpoints"`
+	Total     int                       `json:"total"`
+}
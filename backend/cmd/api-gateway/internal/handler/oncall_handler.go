@@ -0,0 +1,945 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/edgelink/backend/internal/domain"
+	"github.com/edgelink/backend/internal/repository"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// OnCallHandler 值班排班与升级策略处理器
+type OnCallHandler struct {
+	scheduleRepo   repository.OnCallScheduleRepository
+	policyRepo     repository.EscalationPolicyRepository
+	escalationRepo repository.AlertEscalationRepository
+	adminUserRepo  repository.AdminUserRepository
+	alertRepo      repository.AlertRepository
+	logger         *zap.Logger
+}
+
+// NewOnCallHandler 创建OnCallHandler实例
+func NewOnCallHandler(
+	scheduleRepo repository.OnCallScheduleRepository,
+	policyRepo repository.EscalationPolicyRepository,
+	escalationRepo repository.AlertEscalationRepository,
+	adminUserRepo repository.AdminUserRepository,
+	alertRepo repository.AlertRepository,
+	logger *zap.Logger,
+) *OnCallHandler {
+	return &OnCallHandler{
+		scheduleRepo:   scheduleRepo,
+		policyRepo:     policyRepo,
+		escalationRepo: escalationRepo,
+		adminUserRepo:  adminUserRepo,
+		alertRepo:      alertRepo,
+		logger:         logger,
+	}
+}
+
+// GetWhoIsOnCall godoc
+// @Summary      查询当前值班人员
+// @Description  计算组织内每个排班表在指定时刻（默认当前）的值班人员，替班优先于值班层
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        organization_id  query  string  true   "组织ID"
+// @Param        at               query  string  false  "查询时刻 (RFC3339)"
+// @Success      200  {object}  WhoIsOnCallResponse
+// @Failure      400  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/v1/admin/oncall/now [get]
+func (h *OnCallHandler) GetWhoIsOnCall(c *gin.Context) {
+	orgID, ok := parseRequiredOrganizationID(c)
+	if !ok {
+		return
+	}
+	at, ok := parseAtQuery(c)
+	if !ok {
+		return
+	}
+
+	schedules, err := h.scheduleRepo.FindByOrganizationID(c.Request.Context(), orgID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "query_failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	items := make([]OnCallEntry, 0, len(schedules))
+	for _, schedule := range schedules {
+		items = append(items, h.onCallEntry(c.Request.Context(), schedule, at))
+	}
+
+	c.JSON(http.StatusOK, WhoIsOnCallResponse{
+		At:        at,
+		Schedules: items,
+	})
+}
+
+// GetSchedules godoc
+// @Summary      获取排班表列表
+// @Description  获取组织的所有值班排班表（包含替班）
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        organization_id  query  string  true  "组织ID"
+// @Success      200  {object}  ScheduleListResponse
+// @Failure      400  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/v1/admin/oncall/schedules [get]
+func (h *OnCallHandler) GetSchedules(c *gin.Context) {
+	orgID, ok := parseRequiredOrganizationID(c)
+	if !ok {
+		return
+	}
+
+	schedules, err := h.scheduleRepo.FindByOrganizationID(c.Request.Context(), orgID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "query_failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, ScheduleListResponse{
+		Schedules: schedules,
+		Total:     len(schedules),
+	})
+}
+
+// GetSchedule godoc
+// @Summary      获取排班表详情
+// @Description  根据ID获取值班排班表及当前值班人员
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        schedule_id  path   string  true   "排班表ID"
+// @Param        at           query  string  false  "查询时刻 (RFC3339)"
+// @Success      200  {object}  ScheduleResponse
+// @Failure      400  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/v1/admin/oncall/schedules/{schedule_id} [get]
+func (h *OnCallHandler) GetSchedule(c *gin.Context) {
+	scheduleID, ok := parseUUIDParam(c, "schedule_id")
+	if !ok {
+		return
+	}
+	at, ok := parseAtQuery(c)
+	if !ok {
+		return
+	}
+
+	schedule, ok := h.findSchedule(c, scheduleID)
+	if !ok {
+		return
+	}
+
+	entry := h.onCallEntry(c.Request.Context(), schedule, at)
+	c.JSON(http.StatusOK, ScheduleResponse{
+		OnCallSchedule: schedule,
+		OnCall:         entry.OnCall,
+	})
+}
+
+// CreateSchedule godoc
+// @Summary      创建排班表
+// @Description  创建值班排班表，值班层按列表顺序叠加，靠后的层优先
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        request  body  ScheduleRequest  true  "排班表定义"
+// @Success      201  {object}  domain.OnCallSchedule
+// @Failure      400  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/v1/admin/oncall/schedules [post]
+func (h *OnCallHandler) CreateSchedule(c *gin.Context) {
+	var req ScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	orgID, err := uuid.Parse(req.OrganizationID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_organization_id",
+			Message: "organization_id must be a valid UUID",
+		})
+		return
+	}
+
+	now := time.Now()
+	schedule := &domain.OnCallSchedule{
+		ID:             uuid.New(),
+		OrganizationID: orgID,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	req.applyTo(schedule)
+
+	if !h.validateSchedule(c, schedule) {
+		return
+	}
+
+	if err := h.scheduleRepo.Create(c.Request.Context(), schedule); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "create_failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	h.logger.Info("On-call schedule created",
+		zap.String("schedule_id", schedule.ID.String()),
+		zap.String("organization_id", orgID.String()),
+	)
+
+	c.JSON(http.StatusCreated, schedule)
+}
+
+// UpdateSchedule godoc
+// @Summary      更新排班表
+// @Description  替换排班表的名称、时区和值班层（替班不受影响）
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        schedule_id  path  string           true  "排班表ID"
+// @Param        request      body  ScheduleRequest  true  "排班表定义"
+// @Success      200  {object}  domain.OnCallSchedule
+// @Failure      400  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/v1/admin/oncall/schedules/{schedule_id} [put]
+func (h *OnCallHandler) UpdateSchedule(c *gin.Context) {
+	scheduleID, ok := parseUUIDParam(c, "schedule_id")
+	if !ok {
+		return
+	}
+
+	var req ScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	schedule, ok := h.findSchedule(c, scheduleID)
+	if !ok {
+		return
+	}
+
+	req.applyTo(schedule)
+	schedule.UpdatedAt = time.Now()
+
+	if !h.validateSchedule(c, schedule) {
+		return
+	}
+
+	if err := h.scheduleRepo.Update(c.Request.Context(), schedule); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "update_failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, schedule)
+}
+
+// DeleteSchedule godoc
+// @Summary      删除排班表
+// @Description  删除排班表及其替班；仍被升级策略引用时拒绝删除
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        schedule_id  path  string  true  "排班表ID"
+// @Success      200  {object}  SuccessResponse
+// @Failure      400  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse
+// @Failure      409  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/v1/admin/oncall/schedules/{schedule_id} [delete]
+func (h *OnCallHandler) DeleteSchedule(c *gin.Context) {
+	scheduleID, ok := parseUUIDParam(c, "schedule_id")
+	if !ok {
+		return
+	}
+
+	if _, ok := h.findSchedule(c, scheduleID); !ok {
+		return
+	}
+
+	policies, err := h.policyRepo.FindReferencingTarget(c.Request.Context(), domain.EscalationTargetSchedule, scheduleID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "query_failed",
+			Message: err.Error(),
+		})
+		return
+	}
+	if len(policies) > 0 {
+		c.JSON(http.StatusConflict, ErrorResponse{
+			Error:   "schedule_in_use",
+			Message: "schedule is referenced by escalation policy " + policies[0].Name,
+		})
+		return
+	}
+
+	if err := h.scheduleRepo.Delete(c.Request.Context(), scheduleID); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "delete_failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Message: "Schedule deleted successfully",
+	})
+}
+
+// CreateOverride godoc
+// @Summary      创建替班
+// @Description  在时间区间内由指定管理员替代排班表的值班人员
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        schedule_id  path  string                 true  "排班表ID"
+// @Param        request      body  CreateOverrideRequest  true  "替班定义"
+// @Success      201  {object}  domain.OnCallOverride
+// @Failure      400  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/v1/admin/oncall/schedules/{schedule_id}/overrides [post]
+func (h *OnCallHandler) CreateOverride(c *gin.Context) {
+	scheduleID, ok := parseUUIDParam(c, "schedule_id")
+	if !ok {
+		return
+	}
+
+	var req CreateOverrideRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	userID, err := uuid.Parse(req.UserID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_user_id",
+			Message: "user_id must be a valid UUID",
+		})
+		return
+	}
+	if !req.EndsAt.After(req.StartsAt) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_override",
+			Message: "ends_at must be after starts_at",
+		})
+		return
+	}
+
+	schedule, ok := h.findSchedule(c, scheduleID)
+	if !ok {
+		return
+	}
+	if !h.validateUsers(c, schedule.OrganizationID, []uuid.UUID{userID}) {
+		return
+	}
+
+	override := &domain.OnCallOverride{
+		ID:         uuid.New(),
+		ScheduleID: scheduleID,
+		UserID:     userID,
+		StartsAt:   req.StartsAt,
+		EndsAt:     req.EndsAt,
+		Reason:     req.Reason,
+		CreatedBy:  actorIDFromHeader(c),
+		CreatedAt:  time.Now(),
+	}
+
+	if err := h.scheduleRepo.CreateOverride(c.Request.Context(), override); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "create_failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, override)
+}
+
+// DeleteOverride godoc
+// @Summary      删除替班
+// @Description  删除排班表下的替班
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        schedule_id  path  string  true  "排班表ID"
+// @Param        override_id  path  string  true  "替班ID"
+// @Success      200  {object}  SuccessResponse
+// @Failure      400  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/v1/admin/oncall/schedules/{schedule_id}/overrides/{override_id} [delete]
+func (h *OnCallHandler) DeleteOverride(c *gin.Context) {
+	scheduleID, ok := parseUUIDParam(c, "schedule_id")
+	if !ok {
+		return
+	}
+	overrideID, ok := parseUUIDParam(c, "override_id")
+	if !ok {
+		return
+	}
+
+	if err := h.scheduleRepo.DeleteOverride(c.Request.Context(), scheduleID, overrideID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Error:   "override_not_found",
+				Message: "Override not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "delete_failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Message: "Override deleted successfully",
+	})
+}
+
+// GetEscalationPolicies godoc
+// @Summary      获取升级策略列表
+// @Description  获取组织的所有升级策略
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        organization_id  query  string  true  "组织ID"
+// @Success      200  {object}  EscalationPolicyListResponse
+// @Failure      400  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/v1/admin/escalation-policies [get]
+func (h *OnCallHandler) GetEscalationPolicies(c *gin.Context) {
+	orgID, ok := parseRequiredOrganizationID(c)
+	if !ok {
+		return
+	}
+
+	policies, err := h.policyRepo.FindByOrganizationID(c.Request.Context(), orgID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "query_failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, EscalationPolicyListResponse{
+		Policies: policies,
+		Total:    len(policies),
+	})
+}
+
+// GetEscalationPolicy godoc
+// @Summary      获取升级策略详情
+// @Description  根据ID获取升级策略
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        policy_id  path  string  true  "升级策略ID"
+// @Success      200  {object}  domain.EscalationPolicy
+// @Failure      400  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/v1/admin/escalation-policies/{policy_id} [get]
+func (h *OnCallHandler) GetEscalationPolicy(c *gin.Context) {
+	policyID, ok := parseUUIDParam(c, "policy_id")
+	if !ok {
+		return
+	}
+
+	policy, ok := h.findPolicy(c, policyID)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, policy)
+}
+
+// CreateEscalationPolicy godoc
+// @Summary      创建升级策略
+// @Description  创建多级升级策略，规则通过escalation.policy_id引用
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        request  body  EscalationPolicyRequest  true  "升级策略定义"
+// @Success      201  {object}  domain.EscalationPolicy
+// @Failure      400  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/v1/admin/escalation-policies [post]
+func (h *OnCallHandler) CreateEscalationPolicy(c *gin.Context) {
+	var req EscalationPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	orgID, err := uuid.Parse(req.OrganizationID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_organization_id",
+			Message: "organization_id must be a valid UUID",
+		})
+		return
+	}
+
+	now := time.Now()
+	policy := &domain.EscalationPolicy{
+		ID:             uuid.New(),
+		OrganizationID: orgID,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	req.applyTo(policy)
+
+	if !h.validatePolicy(c, policy) {
+		return
+	}
+
+	if err := h.policyRepo.Create(c.Request.Context(), policy); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "create_failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	h.logger.Info("Escalation policy created",
+		zap.String("policy_id", policy.ID.String()),
+		zap.String("organization_id", orgID.String()),
+		zap.Int("levels", len(policy.Levels)),
+	)
+
+	c.JSON(http.StatusCreated, policy)
+}
+
+// UpdateEscalationPolicy godoc
+// @Summary      更新升级策略
+// @Description  替换升级策略的级别定义，进行中的升级从当前级别继续
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        policy_id  path  string                   true  "升级策略ID"
+// @Param        request    body  EscalationPolicyRequest  true  "升级策略定义"
+// @Success      200  {object}  domain.EscalationPolicy
+// @Failure      400  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/v1/admin/escalation-policies/{policy_id} [put]
+func (h *OnCallHandler) UpdateEscalationPolicy(c *gin.Context) {
+	policyID, ok := parseUUIDParam(c, "policy_id")
+	if !ok {
+		return
+	}
+
+	var req EscalationPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	policy, ok := h.findPolicy(c, policyID)
+	if !ok {
+		return
+	}
+
+	req.applyTo(policy)
+	policy.UpdatedAt = time.Now()
+
+	if !h.validatePolicy(c, policy) {
+		return
+	}
+
+	if err := h.policyRepo.Update(c.Request.Context(), policy); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "update_failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, policy)
+}
+
+// DeleteEscalationPolicy godoc
+// @Summary      删除升级策略
+// @Description  删除升级策略，引用该策略的进行中升级将停止
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        policy_id  path  string  true  "升级策略ID"
+// @Success      200  {object}  SuccessResponse
+// @Failure      400  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/v1/admin/escalation-policies/{policy_id} [delete]
+func (h *OnCallHandler) DeleteEscalationPolicy(c *gin.Context) {
+	policyID, ok := parseUUIDParam(c, "policy_id")
+	if !ok {
+		return
+	}
+
+	if _, ok := h.findPolicy(c, policyID); !ok {
+		return
+	}
+
+	if err := h.policyRepo.Delete(c.Request.Context(), policyID); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "delete_failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Message: "Escalation policy deleted successfully",
+	})
+}
+
+// GetAlertEscalations godoc
+// @Summary      获取告警升级进度
+// @Description  获取告警的持久化升级进度（当前级别、通知次数、下次升级时间）
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        alert_id  path  string  true  "告警ID"
+// @Success      200  {object}  AlertEscalationsResponse
+// @Failure      400  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/v1/admin/alerts/{alert_id}/escalations [get]
+func (h *OnCallHandler) GetAlertEscalations(c *gin.Context) {
+	alertID, ok := parseAlertID(c)
+	if !ok {
+		return
+	}
+
+	if _, err := h.alertRepo.FindByID(c.Request.Context(), alertID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Error:   "alert_not_found",
+				Message: "Alert not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "query_failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	escalations, err := h.escalationRepo.FindByAlertID(c.Request.Context(), alertID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "query_failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, AlertEscalationsResponse{
+		AlertID:     alertID,
+		Escalations: escalations,
+	})
+}
+
+// onCallEntry 计算排班表在指定时刻的值班人员
+func (h *OnCallHandler) onCallEntry(ctx context.Context, schedule *domain.OnCallSchedule, at time.Time) OnCallEntry {
+	entry := OnCallEntry{
+		ScheduleID:   schedule.ID,
+		ScheduleName: schedule.Name,
+		Timezone:     schedule.Timezone,
+	}
+
+	shift, ok := schedule.OnCallAt(at)
+	if !ok {
+		return entry
+	}
+
+	onCall := &OnCallUser{OnCallShift: *shift}
+	if user, err := h.adminUserRepo.FindByID(ctx, shift.UserID); err == nil {
+		onCall.Name = user.Name
+		onCall.Email = user.Email
+	}
+	entry.OnCall = onCall
+	return entry
+}
+
+// validateSchedule 校验排班表定义及其人员，失败时写入错误响应
+func (h *OnCallHandler) validateSchedule(c *gin.Context, schedule *domain.OnCallSchedule) bool {
+	if err := schedule.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_schedule",
+			Message: err.Error(),
+		})
+		return false
+	}
+	return h.validateUsers(c, schedule.OrganizationID, schedule.UserIDs())
+}
+
+// validatePolicy 校验升级策略定义及其目标归属，失败时写入错误响应
+func (h *OnCallHandler) validatePolicy(c *gin.Context, policy *domain.EscalationPolicy) bool {
+	if err := policy.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_escalation_policy",
+			Message: err.Error(),
+		})
+		return false
+	}
+
+	if !h.validateUsers(c, policy.OrganizationID, policy.TargetIDs(domain.EscalationTargetUser)) {
+		return false
+	}
+
+	scheduleIDs := policy.TargetIDs(domain.EscalationTargetSchedule)
+	schedules, err := h.scheduleRepo.FindByIDs(c.Request.Context(), scheduleIDs)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "query_failed",
+			Message: err.Error(),
+		})
+		return false
+	}
+
+	found := make(map[uuid.UUID]bool, len(schedules))
+	for _, schedule := range schedules {
+		if schedule.OrganizationID == policy.OrganizationID {
+			found[schedule.ID] = true
+		}
+	}
+	for _, id := range scheduleIDs {
+		if !found[id] {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "invalid_escalation_policy",
+				Message: "schedule " + id.String() + " does not exist in the organization",
+			})
+			return false
+		}
+	}
+	return true
+}
+
+// validateUsers 校验人员均为组织内的管理员，失败时写入错误响应
+func (h *OnCallHandler) validateUsers(c *gin.Context, orgID uuid.UUID, userIDs []uuid.UUID) bool {
+	for _, id := range userIDs {
+		user, err := h.adminUserRepo.FindByID(c.Request.Context(), id)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusInternalServerError, ErrorResponse{
+				Error:   "query_failed",
+				Message: err.Error(),
+			})
+			return false
+		}
+		if err != nil || user.OrganizationID != orgID {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "invalid_user",
+				Message: "user " + id.String() + " is not an admin user of the organization",
+			})
+			return false
+		}
+	}
+	return true
+}
+
+// findSchedule 查找排班表，失败时写入错误响应
+func (h *OnCallHandler) findSchedule(c *gin.Context, scheduleID uuid.UUID) (*domain.OnCallSchedule, bool) {
+	schedule, err := h.scheduleRepo.FindByID(c.Request.Context(), scheduleID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Error:   "schedule_not_found",
+				Message: "Schedule not found",
+			})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "query_failed",
+			Message: err.Error(),
+		})
+		return nil, false
+	}
+	return schedule, true
+}
+
+// findPolicy 查找升级策略，失败时写入错误响应
+func (h *OnCallHandler) findPolicy(c *gin.Context, policyID uuid.UUID) (*domain.EscalationPolicy, bool) {
+	policy, err := h.policyRepo.FindByID(c.Request.Context(), policyID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Error:   "escalation_policy_not_found",
+				Message: "Escalation policy not found",
+			})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "query_failed",
+			Message: err.Error(),
+		})
+		return nil, false
+	}
+	return policy, true
+}
+
+// parseRequiredOrganizationID 解析必填的organization_id查询参数，失败时写入错误响应
+func parseRequiredOrganizationID(c *gin.Context) (uuid.UUID, bool) {
+	orgID, err := uuid.Parse(c.Query("organization_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_organization_id",
+			Message: "organization_id is required and must be a valid UUID",
+		})
+		return uuid.Nil, false
+	}
+	return orgID, true
+}
+
+// parseAtQuery 解析可选的at查询参数（RFC3339，默认当前时间），失败时写入错误响应
+func parseAtQuery(c *gin.Context) (time.Time, bool) {
+	atStr := c.Query("at")
+	if atStr == "" {
+		return time.Now(), true
+	}
+	at, err := time.Parse(time.RFC3339, atStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_at",
+			Message: "at must be an RFC3339 timestamp",
+		})
+		return time.Time{}, false
+	}
+	return at, true
+}
+
+// parseUUIDParam 解析路径中的UUID参数，失败时写入错误响应
+func parseUUIDParam(c *gin.Context, name string) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param(name))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_" + name,
+			Message: name + " must be a valid UUID",
+		})
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// applyTo 将请求内容写入排班表
+func (r *ScheduleRequest) applyTo(schedule *domain.OnCallSchedule) {
+	schedule.Name = r.Name
+	schedule.Description = r.Description
+	schedule.Timezone = r.Timezone
+	if schedule.Timezone == "" {
+		schedule.Timezone = "UTC"
+	}
+	schedule.Layers = r.Layers
+}
+
+// applyTo 将请求内容写入升级策略
+func (r *EscalationPolicyRequest) applyTo(policy *domain.EscalationPolicy) {
+	policy.Name = r.Name
+	policy.Description = r.Description
+	policy.Levels = r.Levels
+	policy.RepeatCount = r.RepeatCount
+}
+
+type ScheduleRequest struct {
+	OrganizationID string              `json:"organization_id"`
+	Name           string              `json:"name" binding:"required"`
+	Description    string              `json:"description"`
+	Timezone       string              `json:"timezone"`
+	Layers         domain.OnCallLayers `json:"layers" binding:"required"`
+}
+
+type CreateOverrideRequest struct {
+	UserID   string    `json:"user_id" binding:"required"`
+	StartsAt time.Time `json:"starts_at" binding:"required"`
+	EndsAt   time.Time `json:"ends_at" binding:"required"`
+	Reason   string    `json:"reason"`
+}
+
+type EscalationPolicyRequest struct {
+	OrganizationID string                  `json:"organization_id"`
+	Name           string                  `json:"name" binding:"required"`
+	Description    string                  `json:"description"`
+	Levels         domain.EscalationLevels `json:"levels" binding:"required"`
+	RepeatCount    int                     `json:"repeat_count"`
+}
+
+type ScheduleListResponse struct {
+	Schedules []*domain.OnCallSchedule `json:"schedules"`
+	Total     int                      `json:"total"`
+}
+
+type ScheduleResponse struct {
+	*domain.OnCallSchedule
+	OnCall *OnCallUser `json:"on_call"`
+}
+
+type EscalationPolicyListResponse struct {
+	Policies []*domain.EscalationPolicy `json:"policies"`
+	Total    int                        `json:"total"`
+}
+
+type AlertEscalationsResponse struct {
+	AlertID     uuid.UUID                 `json:"alert_id"`
+	Escalations []*domain.AlertEscalation `json:"escalations"`
+}
+
+type WhoIsOnCallResponse struct {
+	At        time.Time     `json:"at"`
+	Schedules []OnCallEntry `json:"schedules"`
+}
+
+type OnCallEntry struct {
+	ScheduleID   uuid.UUID   `json:"schedule_id"`
+	ScheduleName string      `json:"schedule_name"`
+	Timezone     string      `json:"timezone"`
+	OnCall       *OnCallUser `json:"on_call"`
+}
+
+type OnCallUser struct {
+	domain.OnCallShift
+	Name  string `json:"name,omitempty"`
+	Email string `json:"email,omitempty"`
+}
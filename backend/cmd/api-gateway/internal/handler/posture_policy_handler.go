@@ -0,0 +1,464 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/edgelink/backend/cmd/api-gateway/internal/websocket"
+	"github.com/edgelink/backend/internal/audit"
+	"github.com/edgelink/backend/internal/domain"
+	"github.com/edgelink/backend/internal/repository"
+	"github.com/edgelink/backend/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+)
+
+// PosturePolicyHandler 设备安全状态策略处理器
+// 策略变更后立即重新评估网络内的设备，评估结果变化的设备广播device_posture事件
+type PosturePolicyHandler struct {
+	deviceService *service.DeviceService
+	deviceRepo    repository.DeviceRepository
+	vnRepo        repository.VirtualNetworkRepository
+	policyRepo    repository.PosturePolicyRepository
+	broadcaster   *websocket.Broadcaster
+	logger        *zap.Logger
+}
+
+// NewPosturePolicyHandler 创建PosturePolicyHandler实例
+func NewPosturePolicyHandler(
+	deviceService *service.DeviceService,
+	deviceRepo repository.DeviceRepository,
+	vnRepo repository.VirtualNetworkRepository,
+	policyRepo repository.PosturePolicyRepository,
+	broadcaster *websocket.Broadcaster,
+	logger *zap.Logger,
+) *PosturePolicyHandler {
+	return &PosturePolicyHandler{
+		deviceService: deviceService,
+		deviceRepo:    deviceRepo,
+		vnRepo:        vnRepo,
+		policyRepo:    policyRepo,
+		broadcaster:   broadcaster,
+		logger:        logger,
+	}
+}
+
+// PosturePolicyRequest 创建/更新安全状态策略请求，更新时整体替换
+type PosturePolicyRequest struct {
+	Name                  string            `json:"name" binding:"required"`
+	Tag                   *string           `json:"tag"`
+	Platform              *string           `json:"platform"`
+	Enabled               *bool             `json:"enabled"`
+	MinClientVersion      *string           `json:"min_client_version"`
+	MinOSVersion          *string           `json:"min_os_version"`
+	RequireFirewall       bool              `json:"require_firewall"`
+	RequireDiskEncryption bool              `json:"require_disk_encryption"`
+	RequiredAttributes    map[string]string `json:"required_attributes"`
+	Enforcement           string            `json:"enforcement"`       // quarantine（默认）或restrict
+	AllowedPeerTags       []string          `json:"allowed_peer_tags"` // restrict时必填
+	Description           string            `json:"description"`
+}
+
+// PosturePolicyListResponse 安全状态策略列表响应
+type PosturePolicyListResponse struct {
+	Policies []*domain.PosturePolicy `json:"policies"`
+	Total    int                     `json:"total"`
+}
+
+// DevicePostureResponse 设备安全状态响应
+type DevicePostureResponse struct {
+	DeviceID           uuid.UUID                  `json:"device_id"`
+	Posture            *domain.DevicePosture      `json:"posture,omitempty"`
+	ReportedAt         *time.Time                 `json:"reported_at,omitempty"`
+	Status             domain.DevicePostureStatus `json:"status"`
+	Violations         []string                   `json:"violations,omitempty"`
+	AllowedPeerTags    []string                   `json:"allowed_peer_tags,omitempty"`     // 各组允许标签的并集
+	AllowedPeerTagSets domain.PeerTagSets         `json:"allowed_peer_tag_sets,omitempty"` // 每条违反的restrict策略一组，对端须满足每一组
+	CheckedAt          *time.Time                 `json:"checked_at,omitempty"`
+	ApplicablePolicies []uuid.UUID                `json:"applicable_policies"`
+}
+
+// DevicePostureEvent 通过WebSocket广播的设备安全状态事件
+type DevicePostureEvent struct {
+	DeviceID         uuid.UUID                  `json:"device_id"`
+	VirtualNetworkID uuid.UUID                  `json:"virtual_network_id"`
+	Name             string                     `json:"name"`
+	Status           domain.DevicePostureStatus `json:"status"`
+	Violations       []string                   `json:"violations,omitempty"`
+	AllowedPeerTags  []string                   `json:"allowed_peer_tags,omitempty"`     // 不合规时仍可互通的对端标签（各组的并集），为空表示完全隔离
+	AllowedTagSets   domain.PeerTagSets         `json:"allowed_peer_tag_sets,omitempty"` // 每条违反的restrict策略一组，对端须满足每一组
+	Timestamp        time.Time                  `json:"timestamp"`
+}
+
+// GetPosturePolicies godoc
+// @Summary      获取虚拟网络的安全状态策略
+// @Tags         admin
+// @Produce      json
+// @Param        network_id  path  string  true  "虚拟网络ID"
+// @Success      200  {object}  PosturePolicyListResponse
+// @Failure      400  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/v1/admin/virtual-networks/{network_id}/posture-policies [get]
+func (h *PosturePolicyHandler) GetPosturePolicies(c *gin.Context) {
+	vn, ok := h.findVirtualNetwork(c)
+	if !ok {
+		return
+	}
+
+	policies, err := h.policyRepo.FindByVirtualNetwork(c.Request.Context(), vn.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "query_failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, PosturePolicyListResponse{
+		Policies: policies,
+		Total:    len(policies),
+	})
+}
+
+// CreatePosturePolicy godoc
+// @Summary      创建安全状态策略
+// @Description  作用于网络内全部设备或带有指定标签的设备，不满足要求的设备被隔离（quarantine）或只能与带有允许标签的设备互通（restrict）
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        network_id  path  string                true  "虚拟网络ID"
+// @Param        request     body  PosturePolicyRequest  true  "策略"
+// @Success      201  {object}  domain.PosturePolicy
+// @Failure      400  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/v1/admin/virtual-networks/{network_id}/posture-policies [post]
+func (h *PosturePolicyHandler) CreatePosturePolicy(c *gin.Context) {
+	var req PosturePolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	vn, ok := h.findVirtualNetwork(c)
+	if !ok {
+		return
+	}
+
+	now := time.Now()
+	policy := &domain.PosturePolicy{
+		ID:               uuid.New(),
+		VirtualNetworkID: vn.ID,
+		Enabled:          true,
+		CreatedBy:        actorIDFromHeader(c),
+		CreatedAt:        now,
+		UpdatedAt:        now,
+	}
+	req.applyTo(policy)
+
+	if err := policy.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_policy",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if err := h.policyRepo.Create(c.Request.Context(), policy); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "create_failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	h.logger.Info("Posture policy created",
+		zap.String("policy_id", policy.ID.String()),
+		zap.String("network_id", vn.ID.String()),
+	)
+
+	h.reevaluate(c, vn)
+	c.JSON(http.StatusCreated, policy)
+}
+
+// UpdatePosturePolicy godoc
+// @Summary      更新安全状态策略
+// @Description  整体替换策略内容，随后重新评估网络内的设备
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        network_id  path  string                true  "虚拟网络ID"
+// @Param        policy_id   path  string                true  "策略ID"
+// @Param        request     body  PosturePolicyRequest  true  "策略"
+// @Success      200  {object}  domain.PosturePolicy
+// @Failure      400  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/v1/admin/virtual-networks/{network_id}/posture-policies/{policy_id} [put]
+func (h *PosturePolicyHandler) UpdatePosturePolicy(c *gin.Context) {
+	var req PosturePolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	vn, ok := h.findVirtualNetwork(c)
+	if !ok {
+		return
+	}
+	policy, ok := h.findPolicy(c, vn)
+	if !ok {
+		return
+	}
+
+	req.applyTo(policy)
+	policy.UpdatedAt = time.Now()
+
+	if err := policy.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_policy",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if err := h.policyRepo.Update(c.Request.Context(), policy); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "update_failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	h.logger.Info("Posture policy updated", zap.String("policy_id", policy.ID.String()))
+
+	h.reevaluate(c, vn)
+	c.JSON(http.StatusOK, policy)
+}
+
+// DeletePosturePolicy godoc
+// @Summary      删除安全状态策略
+// @Description  删除后重新评估网络内的设备，只因该策略不合规的设备恢复正常
+// @Tags         admin
+// @Produce      json
+// @Param        network_id  path  string  true  "虚拟网络ID"
+// @Param        policy_id   path  string  true  "策略ID"
+// @Success      200  {object}  SuccessResponse
+// @Failure      400  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/v1/admin/virtual-networks/{network_id}/posture-policies/{policy_id} [delete]
+func (h *PosturePolicyHandler) DeletePosturePolicy(c *gin.Context) {
+	vn, ok := h.findVirtualNetwork(c)
+	if !ok {
+		return
+	}
+	policy, ok := h.findPolicy(c, vn)
+	if !ok {
+		return
+	}
+
+	if err := h.policyRepo.Delete(c.Request.Context(), policy.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "delete_failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	h.logger.Info("Posture policy deleted",
+		zap.String("policy_id", policy.ID.String()),
+		zap.String("network_id", vn.ID.String()),
+	)
+
+	h.reevaluate(c, vn)
+	c.JSON(http.StatusOK, SuccessResponse{
+		Message: "posture policy deleted",
+	})
+}
+
+// GetDevicePosture godoc
+// @Summary      获取设备安全状态
+// @Description  返回设备最近一次上报的安全状态、评估结果和适用的策略
+// @Tags         admin
+// @Produce      json
+// @Param        device_id  path  string  true  "设备ID"
+// @Success      200  {object}  DevicePostureResponse
+// @Failure      400  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/v1/admin/devices/{device_id}/posture [get]
+func (h *PosturePolicyHandler) GetDevicePosture(c *gin.Context) {
+	deviceID, err := uuid.Parse(c.Param("device_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_device_id",
+			Message: "device_id must be a valid UUID",
+		})
+		return
+	}
+
+	device, err := h.deviceRepo.FindByID(c.Request.Context(), deviceID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:   "device_not_found",
+			Message: "device not found",
+		})
+		return
+	}
+
+	policies, err := h.policyRepo.FindByVirtualNetwork(c.Request.Context(), device.VirtualNetworkID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "query_failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	resp := DevicePostureResponse{
+		DeviceID:           device.ID,
+		Posture:            device.Posture,
+		ReportedAt:         device.PostureReportedAt,
+		Status:             device.PostureStatus,
+		Violations:         device.PostureViolations,
+		AllowedPeerTags:    device.PostureAllowedPeerTags,
+		AllowedPeerTagSets: device.PostureAllowedPeerTagSets,
+		CheckedAt:          device.PostureCheckedAt,
+		ApplicablePolicies: []uuid.UUID{},
+	}
+	for _, policy := range policies {
+		if policy.Applies(device) {
+			resp.ApplicablePolicies = append(resp.ApplicablePolicies, policy.ID)
+		}
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// reevaluate 策略变更后重新评估网络内的设备，失败只记录日志（设备下次上报时会再次评估）
+func (h *PosturePolicyHandler) reevaluate(c *gin.Context, vn *domain.VirtualNetwork) {
+	changed, err := h.deviceService.ReevaluateNetworkPosture(c.Request.Context(), vn.ID)
+	if err != nil {
+		h.logger.Error("Failed to re-evaluate device posture",
+			zap.String("network_id", vn.ID.String()),
+			zap.Error(err),
+		)
+	}
+
+	for i := range changed {
+		publishDevicePosture(c.Request.Context(), h.broadcaster, h.logger, &changed[i], vn.OrganizationID)
+	}
+
+	if len(changed) > 0 {
+		h.logger.Info("Device posture re-evaluated",
+			zap.String("network_id", vn.ID.String()),
+			zap.Int("changed", len(changed)),
+		)
+	}
+}
+
+// findVirtualNetwork 解析路径中的network_id并查找虚拟网络，失败时已写入响应
+func (h *PosturePolicyHandler) findVirtualNetwork(c *gin.Context) (*domain.VirtualNetwork, bool) {
+	vnID, err := uuid.Parse(c.Param("network_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_network_id",
+			Message: "network_id must be a valid UUID",
+		})
+		return nil, false
+	}
+
+	vn, err := h.vnRepo.FindByID(c.Request.Context(), vnID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:   "network_not_found",
+			Message: "virtual network not found",
+		})
+		return nil, false
+	}
+
+	c.Set(audit.ContextOrganizationID, vn.OrganizationID)
+	return vn, true
+}
+
+// findPolicy 解析路径中的policy_id并查找属于该网络的策略，失败时已写入响应
+func (h *PosturePolicyHandler) findPolicy(c *gin.Context, vn *domain.VirtualNetwork) (*domain.PosturePolicy, bool) {
+	policyID, err := uuid.Parse(c.Param("policy_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_policy_id",
+			Message: "policy_id must be a valid UUID",
+		})
+		return nil, false
+	}
+
+	policy, err := h.policyRepo.FindByID(c.Request.Context(), policyID)
+	if err != nil || policy.VirtualNetworkID != vn.ID {
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:   "policy_not_found",
+			Message: "posture policy not found",
+		})
+		return nil, false
+	}
+
+	return policy, true
+}
+
+// applyTo 将请求写入策略
+func (req *PosturePolicyRequest) applyTo(policy *domain.PosturePolicy) {
+	policy.Name = req.Name
+	policy.Tag = req.Tag
+	policy.Platform = nil
+	if req.Platform != nil {
+		platform := domain.Platform(*req.Platform)
+		policy.Platform = &platform
+	}
+	if req.Enabled != nil {
+		policy.Enabled = *req.Enabled
+	}
+	policy.MinClientVersion = req.MinClientVersion
+	policy.MinOSVersion = req.MinOSVersion
+	policy.RequireFirewall = req.RequireFirewall
+	policy.RequireDiskEncryption = req.RequireDiskEncryption
+	policy.RequiredAttributes = nil
+	if len(req.RequiredAttributes) > 0 {
+		policy.RequiredAttributes = make(domain.JSONB, len(req.RequiredAttributes))
+		for key, value := range req.RequiredAttributes {
+			policy.RequiredAttributes[key] = value
+		}
+	}
+	policy.Enforcement = domain.PostureEnforcement(req.Enforcement)
+	policy.AllowedPeerTags = pq.StringArray(req.AllowedPeerTags)
+	policy.Description = req.Description
+}
+
+// publishDevicePosture 广播设备安全状态评估结果变化，失败只记录日志
+func publishDevicePosture(ctx context.Context, broadcaster *websocket.Broadcaster, logger *zap.Logger, device *domain.Device, orgID uuid.UUID) {
+	event := DevicePostureEvent{
+		DeviceID:         device.ID,
+		VirtualNetworkID: device.VirtualNetworkID,
+		Name:             device.Name,
+		Status:           device.PostureStatus,
+		Violations:       device.PostureViolations,
+		AllowedPeerTags:  device.PostureAllowedPeerTags,
+		AllowedTagSets:   device.PostureAllowedPeerTagSets,
+		Timestamp:        time.Now(),
+	}
+	if err := broadcaster.PublishDevicePosture(ctx, device.ID.String(), orgID.String(), event); err != nil {
+		logger.Error("Failed to publish device posture event",
+			zap.String("device_id", device.ID.String()),
+			zap.Error(err),
+		)
+	}
+}
@@ -0,0 +1,410 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/edgelink/backend/internal/domain"
+	"github.com/edgelink/backend/internal/repository"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// RetentionHandler 数据保留策略与清理报告处理器
+type RetentionHandler struct {
+	policyRepo repository.RetentionPolicyRepository
+	runRepo    repository.RetentionRunRepository
+	orgRepo    repository.OrganizationRepository
+	logger     *zap.Logger
+}
+
+// NewRetentionHandler 创建RetentionHandler实例
+func NewRetentionHandler(
+	policyRepo repository.RetentionPolicyRepository,
+	runRepo repository.RetentionRunRepository,
+	orgRepo repository.OrganizationRepository,
+	logger *zap.Logger,
+) *RetentionHandler {
+	return &RetentionHandler{
+		policyRepo: policyRepo,
+		runRepo:    runRepo,
+		orgRepo:    orgRepo,
+		logger:     logger,
+	}
+}
+
+// RetentionPolicyRequest 创建保留策略请求
+type RetentionPolicyRequest struct {
+	OrganizationID *string                `json:"organization_id"` // 为空表示全局策略
+	DataClass      domain.DataClass       `json:"data_class" binding:"required"`
+	RetentionDays  int                    `json:"retention_days" binding:"required"`
+	Action         domain.RetentionAction `json:"action"` // 默认delete
+}
+
+// UpdateRetentionPolicyRequest 更新保留策略请求
+type UpdateRetentionPolicyRequest struct {
+	RetentionDays *int                    `json:"retention_days"`
+	Action        *domain.RetentionAction `json:"action"`
+}
+
+// RetentionPolicyListResponse 保留策略列表响应
+type RetentionPolicyListResponse struct {
+	Policies []*domain.RetentionPolicy `json:"policies"`
+}
+
+// RetentionRunListResponse 清理执行记录列表响应
+type RetentionRunListResponse struct {
+	Runs   []*domain.RetentionRun `json:"runs"`
+	Total  int64                  `json:"total"`
+	Limit  int                    `json:"limit"`
+	Offset int                    `json:"offset"`
+}
+
+// GetRetentionPolicies godoc
+// @Summary      获取数据保留策略
+// @Description  列出全局和组织级保留策略，未配置策略的数据类别使用Background Worker的默认保留期（审计日志除外）
+// @Tags         admin
+// @Produce      json
+// @Param        organization_id  query  string  false  "组织ID"
+// @Success      200  {object}  RetentionPolicyListResponse
+// @Failure      400  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/v1/admin/retention-policies [get]
+func (h *RetentionHandler) GetRetentionPolicies(c *gin.Context) {
+	var orgID *uuid.UUID
+	if orgIDStr := c.Query("organization_id"); orgIDStr != "" {
+		id, err := uuid.Parse(orgIDStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "invalid_organization_id",
+				Message: "organization_id must be a valid UUID",
+			})
+			return
+		}
+		orgID = &id
+	}
+
+	policies, err := h.policyRepo.List(c.Request.Context(), orgID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "query_failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, RetentionPolicyListResponse{Policies: policies})
+}
+
+// CreateRetentionPolicy godoc
+// @Summary      创建数据保留策略
+// @Description  每个组织（或全局）的每个数据类别只能有一条策略；审计日志只有配置了策略才会被清理
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        request  body  RetentionPolicyRequest  true  "保留策略"
+// @Success      201  {object}  domain.RetentionPolicy
+// @Failure      400  {object}  ErrorResponse
+// @Failure      409  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/v1/admin/retention-policies [post]
+func (h *RetentionHandler) CreateRetentionPolicy(c *gin.Context) {
+	var req RetentionPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+	if req.Action == "" {
+		req.Action = domain.RetentionActionDelete
+	}
+
+	now := time.Now()
+	policy := &domain.RetentionPolicy{
+		ID:            uuid.New(),
+		DataClass:     req.DataClass,
+		RetentionDays: req.RetentionDays,
+		Action:        req.Action,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+
+	if req.OrganizationID != nil {
+		orgID, err := uuid.Parse(*req.OrganizationID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "invalid_organization_id",
+				Message: "organization_id must be a valid UUID",
+			})
+			return
+		}
+		if _, err := h.orgRepo.FindByID(c.Request.Context(), orgID); err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "invalid_organization_id",
+				Message: "organization not found",
+			})
+			return
+		}
+		policy.OrganizationID = &orgID
+	}
+
+	if !h.validatePolicy(c, policy) {
+		return
+	}
+
+	if err := h.policyRepo.Create(c.Request.Context(), policy); err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) || strings.Contains(err.Error(), "duplicate key") {
+			c.JSON(http.StatusConflict, ErrorResponse{
+				Error:   "policy_exists",
+				Message: "A retention policy for this data class and scope already exists",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "create_failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	h.logPolicyChange("Retention policy created", policy)
+
+	c.JSON(http.StatusCreated, policy)
+}
+
+// UpdateRetentionPolicy godoc
+// @Summary      更新数据保留策略
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        policy_id  path  string                        true  "策略ID"
+// @Param        request    body  UpdateRetentionPolicyRequest  true  "更新内容"
+// @Success      200  {object}  domain.RetentionPolicy
+// @Failure      400  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/v1/admin/retention-policies/{policy_id} [put]
+func (h *RetentionHandler) UpdateRetentionPolicy(c *gin.Context) {
+	var req UpdateRetentionPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	policy, ok := h.findPolicy(c)
+	if !ok {
+		return
+	}
+
+	if req.RetentionDays != nil {
+		policy.RetentionDays = *req.RetentionDays
+	}
+	if req.Action != nil {
+		policy.Action = *req.Action
+	}
+	policy.UpdatedAt = time.Now()
+
+	if !h.validatePolicy(c, policy) {
+		return
+	}
+
+	if err := h.policyRepo.Update(c.Request.Context(), policy); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "update_failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	h.logPolicyChange("Retention policy updated", policy)
+
+	c.JSON(http.StatusOK, policy)
+}
+
+// DeleteRetentionPolicy godoc
+// @Summary      删除数据保留策略
+// @Description  删除后该数据类别回退到全局策略或默认保留期；删除审计日志策略即停止清理审计日志
+// @Tags         admin
+// @Produce      json
+// @Param        policy_id  path  string  true  "策略ID"
+// @Success      204
+// @Failure      400  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/v1/admin/retention-policies/{policy_id} [delete]
+func (h *RetentionHandler) DeleteRetentionPolicy(c *gin.Context) {
+	policy, ok := h.findPolicy(c)
+	if !ok {
+		return
+	}
+
+	if err := h.policyRepo.Delete(c.Request.Context(), policy.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "delete_failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	h.logPolicyChange("Retention policy deleted", policy)
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetRetentionRuns godoc
+// @Summary      获取数据清理执行记录
+// @Description  按开始时间倒序列出清理执行记录及报告
+// @Tags         admin
+// @Produce      json
+// @Param        limit   query  int  false  "返回数量限制"
+// @Param        offset  query  int  false  "偏移量"
+// @Success      200  {object}  RetentionRunListResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/v1/admin/retention-runs [get]
+func (h *RetentionHandler) GetRetentionRuns(c *gin.Context) {
+	limit := 20
+	offset := 0
+	if limitStr := c.Query("limit"); limitStr != "" {
+		limit, _ = strconv.Atoi(limitStr)
+	}
+	if offsetStr := c.Query("offset"); offsetStr != "" {
+		offset, _ = strconv.Atoi(offsetStr)
+	}
+
+	runs, total, err := h.runRepo.List(c.Request.Context(), limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "query_failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, RetentionRunListResponse{
+		Runs:   runs,
+		Total:  total,
+		Limit:  limit,
+		Offset: offset,
+	})
+}
+
+// GetRetentionRun godoc
+// @Summary      获取数据清理报告
+// @Description  返回一次清理中每个数据类别和组织的清理数量、归档文件以及审计日志的链序号范围
+// @Tags         admin
+// @Produce      json
+// @Param        run_id  path  string  true  "执行记录ID"
+// @Success      200  {object}  domain.RetentionRun
+// @Failure      400  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse
+// @Router       /api/v1/admin/retention-runs/{run_id} [get]
+func (h *RetentionHandler) GetRetentionRun(c *gin.Context) {
+	runID, err := uuid.Parse(c.Param("run_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_run_id",
+			Message: "run_id must be a valid UUID",
+		})
+		return
+	}
+
+	run, err := h.runRepo.FindByID(c.Request.Context(), runID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Error:   "retention_run_not_found",
+				Message: "Retention run not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "query_failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, run)
+}
+
+// validatePolicy 校验策略字段，失败时写入400响应
+func (h *RetentionHandler) validatePolicy(c *gin.Context, policy *domain.RetentionPolicy) bool {
+	var message string
+	switch {
+	case !policy.DataClass.IsValid():
+		message = "unknown data_class: " + string(policy.DataClass)
+	case policy.OrganizationID != nil && !policy.DataClass.OrganizationScoped():
+		message = string(policy.DataClass) + " can only have a global policy"
+	case policy.RetentionDays < 1:
+		message = "retention_days must be at least 1"
+	case !policy.Action.IsValid():
+		message = "action must be delete or archive"
+	default:
+		return true
+	}
+
+	c.JSON(http.StatusBadRequest, ErrorResponse{
+		Error:   "invalid_policy",
+		Message: message,
+	})
+	return false
+}
+
+// findPolicy 根据路径参数查找策略，失败时写入错误响应
+func (h *RetentionHandler) findPolicy(c *gin.Context) (*domain.RetentionPolicy, bool) {
+	policyID, err := uuid.Parse(c.Param("policy_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_policy_id",
+			Message: "policy_id must be a valid UUID",
+		})
+		return nil, false
+	}
+
+	policy, err := h.policyRepo.FindByID(c.Request.Context(), policyID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Error:   "policy_not_found",
+				Message: "Retention policy not found",
+			})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "query_failed",
+			Message: err.Error(),
+		})
+		return nil, false
+	}
+
+	return policy, true
+}
+
+// logPolicyChange 记录策略变更，审计日志策略使用Warn级别便于发现
+func (h *RetentionHandler) logPolicyChange(message string, policy *domain.RetentionPolicy) {
+	fields := []zap.Field{
+		zap.String("policy_id", policy.ID.String()),
+		zap.String("data_class", string(policy.DataClass)),
+		zap.Int("retention_days", policy.RetentionDays),
+		zap.String("action", string(policy.Action)),
+	}
+	if policy.OrganizationID != nil {
+		fields = append(fields, zap.String("organization_id", policy.OrganizationID.String()))
+	}
+
+	if policy.DataClass == domain.DataClassAuditLogs {
+		h.logger.Warn(message, fields...)
+		return
+	}
+	h.logger.Info(message, fields...)
+}
@@ -0,0 +1,469 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/edgelink/backend/internal/domain"
+	"github.com/edgelink/backend/internal/repository"
+	"github.com/edgelink/backend/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// SilenceHandler 告警静默处理器
+type SilenceHandler struct {
+	silenceRepo    repository.SilenceRepository
+	adminUserRepo  repository.AdminUserRepository
+	silenceService *service.SilenceService
+	logger         *zap.Logger
+}
+
+// NewSilenceHandler 创建SilenceHandler实例
+func NewSilenceHandler(
+	silenceRepo repository.SilenceRepository,
+	adminUserRepo repository.AdminUserRepository,
+	silenceService *service.SilenceService,
+	logger *zap.Logger,
+) *SilenceHandler {
+	return &SilenceHandler{
+		silenceRepo:    silenceRepo,
+		adminUserRepo:  adminUserRepo,
+		silenceService: silenceService,
+		logger:         logger,
+	}
+}
+
+// GetSilences godoc
+// @Summary      获取静默列表
+// @Description  获取告警静默列表，支持按组织和状态过滤
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        organization_id  query  string  false  "组织ID"
+// @Param        state            query  string  false  "状态 (pending/active/expired)"
+// @Param        limit            query  int     false  "返回数量限制"
+// @Param        offset           query  int     false  "偏移量"
+// @Success      200  {object}  SilenceListResponse
+// @Failure      400  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/v1/admin/silences [get]
+func (h *SilenceHandler) GetSilences(c *gin.Context) {
+	filters := &repository.SilenceFilters{
+		Now:    time.Now(),
+		Limit:  50,
+		Offset: 0,
+	}
+
+	if orgIDStr := c.Query("organization_id"); orgIDStr != "" {
+		orgID, err := uuid.Parse(orgIDStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "invalid_organization_id",
+				Message: "organization_id must be a valid UUID",
+			})
+			return
+		}
+		filters.OrganizationID = &orgID
+	}
+
+	if stateStr := c.Query("state"); stateStr != "" {
+		state := domain.SilenceState(stateStr)
+		switch state {
+		case domain.SilenceStatePending, domain.SilenceStateActive, domain.SilenceStateExpired:
+			filters.State = &state
+		default:
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "invalid_state",
+				Message: "state must be one of pending, active, expired",
+			})
+			return
+		}
+	}
+
+	if limitStr := c.Query("limit"); limitStr != "" {
+		filters.Limit, _ = strconv.Atoi(limitStr)
+	}
+
+	if offsetStr := c.Query("offset"); offsetStr != "" {
+		filters.Offset, _ = strconv.Atoi(offsetStr)
+	}
+
+	silences, total, err := h.silenceRepo.FindByFilters(c.Request.Context(), filters)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "query_failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	items := make([]SilenceResponse, 0, len(silences))
+	for _, silence := range silences {
+		items = append(items, newSilenceResponse(silence, filters.Now))
+	}
+
+	c.JSON(http.StatusOK, SilenceListResponse{
+		Silences: items,
+		Total:    int(total),
+		Limit:    filters.Limit,
+		Offset:   filters.Offset,
+	})
+}
+
+// GetSilence godoc
+// @Summary      获取静默详情
+// @Description  根据ID获取告警静默详情
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        silence_id  path  string  true  "静默ID"
+// @Success      200  {object}  SilenceResponse
+// @Failure      400  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/v1/admin/silences/{silence_id} [get]
+func (h *SilenceHandler) GetSilence(c *gin.Context) {
+	silenceID, ok := parseSilenceID(c)
+	if !ok {
+		return
+	}
+
+	silence, ok := h.findSilence(c, silenceID)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, newSilenceResponse(silence, time.Now()))
+}
+
+// CreateSilence godoc
+// @Summary      创建静默
+// @Description  创建告警静默，命中匹配器的告警在有效期内不会触发通知；已生效时立即标记命中的未解决告警
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        request  body  CreateSilenceRequest  true  "静默定义"
+// @Success      201  {object}  SilenceResponse
+// @Failure      400  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/v1/admin/silences [post]
+func (h *SilenceHandler) CreateSilence(c *gin.Context) {
+	var req CreateSilenceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	orgID, err := uuid.Parse(req.OrganizationID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_organization_id",
+			Message: "organization_id must be a valid UUID",
+		})
+		return
+	}
+
+	createdBy, ok := h.resolveCreator(c, req.CreatedBy)
+	if !ok {
+		return
+	}
+
+	now := time.Now()
+	startsAt := now
+	if req.StartsAt != nil {
+		startsAt = *req.StartsAt
+	}
+
+	silence := &domain.Silence{
+		ID:             uuid.New(),
+		OrganizationID: orgID,
+		Matchers:       req.Matchers,
+		StartsAt:       startsAt,
+		EndsAt:         req.EndsAt,
+		CreatedBy:      createdBy,
+		Comment:        req.Comment,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+
+	if err := silence.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_silence",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if err := h.silenceRepo.Create(c.Request.Context(), silence); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "create_failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	h.logger.Info("Silence created",
+		zap.String("silence_id", silence.ID.String()),
+		zap.String("organization_id", orgID.String()),
+		zap.Time("ends_at", silence.EndsAt),
+	)
+
+	h.reconcileAlerts(c, orgID, now)
+
+	c.JSON(http.StatusCreated, newSilenceResponse(silence, now))
+}
+
+// UpdateSilence godoc
+// @Summary      更新静默
+// @Description  更新未过期静默的匹配器、有效期和说明
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        silence_id  path  string                true  "静默ID"
+// @Param        request     body  UpdateSilenceRequest  true  "静默定义"
+// @Success      200  {object}  SilenceResponse
+// @Failure      400  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse
+// @Failure      409  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/v1/admin/silences/{silence_id} [put]
+func (h *SilenceHandler) UpdateSilence(c *gin.Context) {
+	silenceID, ok := parseSilenceID(c)
+	if !ok {
+		return
+	}
+
+	var req UpdateSilenceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	silence, ok := h.findSilence(c, silenceID)
+	if !ok {
+		return
+	}
+
+	now := time.Now()
+	if silence.State(now) == domain.SilenceStateExpired {
+		c.JSON(http.StatusConflict, ErrorResponse{
+			Error:   "silence_expired",
+			Message: "expired silences cannot be modified",
+		})
+		return
+	}
+
+	if req.Matchers != nil {
+		silence.Matchers = req.Matchers
+	}
+	if req.StartsAt != nil {
+		silence.StartsAt = *req.StartsAt
+	}
+	if req.EndsAt != nil {
+		silence.EndsAt = *req.EndsAt
+	}
+	if req.Comment != nil {
+		silence.Comment = *req.Comment
+	}
+	silence.UpdatedAt = now
+
+	if err := silence.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_silence",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if err := h.silenceRepo.Update(c.Request.Context(), silence); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "update_failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	h.reconcileAlerts(c, silence.OrganizationID, now)
+
+	c.JSON(http.StatusOK, newSilenceResponse(silence, now))
+}
+
+// ExpireSilence godoc
+// @Summary      使静默过期
+// @Description  立即结束静默，之后的告警将恢复正常通知
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        silence_id  path  string  true  "静默ID"
+// @Success      200  {object}  SuccessResponse
+// @Failure      400  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse
+// @Failure      409  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/v1/admin/silences/{silence_id} [delete]
+func (h *SilenceHandler) ExpireSilence(c *gin.Context) {
+	silenceID, ok := parseSilenceID(c)
+	if !ok {
+		return
+	}
+
+	silence, ok := h.findSilence(c, silenceID)
+	if !ok {
+		return
+	}
+
+	now := time.Now()
+	if silence.State(now) == domain.SilenceStateExpired {
+		c.JSON(http.StatusConflict, ErrorResponse{
+			Error:   "silence_expired",
+			Message: "silence has already expired",
+		})
+		return
+	}
+
+	if err := h.silenceRepo.Expire(c.Request.Context(), silenceID, now); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "expire_failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	h.reconcileAlerts(c, silence.OrganizationID, now)
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Message: "Silence expired successfully",
+	})
+}
+
+// reconcileAlerts 静默变更后立即更新组织内已有告警的静默标记，失败只记录日志（告警再次处理时仍会更新）
+func (h *SilenceHandler) reconcileAlerts(c *gin.Context, orgID uuid.UUID, now time.Time) {
+	changed, err := h.silenceService.ReconcileAlerts(c.Request.Context(), orgID, now)
+	if err != nil {
+		h.logger.Error("Failed to update silenced alerts",
+			zap.String("organization_id", orgID.String()),
+			zap.Error(err),
+		)
+		return
+	}
+	if changed > 0 {
+		h.logger.Info("Alert silence state updated",
+			zap.String("organization_id", orgID.String()),
+			zap.Int("alerts", changed),
+		)
+	}
+}
+
+// findSilence 查找静默，失败时写入错误响应
+func (h *SilenceHandler) findSilence(c *gin.Context, silenceID uuid.UUID) (*domain.Silence, bool) {
+	silence, err := h.silenceRepo.FindByID(c.Request.Context(), silenceID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Error:   "silence_not_found",
+				Message: "Silence not found",
+			})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "query_failed",
+			Message: err.Error(),
+		})
+		return nil, false
+	}
+	return silence, true
+}
+
+// resolveCreator 确定静默创建者（请求体优先，其次X-Actor-ID），并校验为有效管理员
+func (h *SilenceHandler) resolveCreator(c *gin.Context, createdBy string) (uuid.UUID, bool) {
+	var creatorID uuid.UUID
+	if createdBy != "" {
+		id, err := uuid.Parse(createdBy)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "invalid_created_by",
+				Message: "created_by must be a valid UUID",
+			})
+			return uuid.Nil, false
+		}
+		creatorID = id
+	} else if actorID := actorIDFromHeader(c); actorID != nil {
+		creatorID = *actorID
+	} else {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "missing_created_by",
+			Message: "created_by or X-Actor-ID header is required",
+		})
+		return uuid.Nil, false
+	}
+
+	user, err := h.adminUserRepo.FindByID(c.Request.Context(), creatorID)
+	if err != nil || !user.IsActive {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_created_by",
+			Message: "created_by must reference an active admin user",
+		})
+		return uuid.Nil, false
+	}
+	return creatorID, true
+}
+
+// parseSilenceID 解析路径中的静默ID，失败时写入错误响应
+func parseSilenceID(c *gin.Context) (uuid.UUID, bool) {
+	silenceID, err := uuid.Parse(c.Param("silence_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_silence_id",
+			Message: "silence_id must be a valid UUID",
+		})
+		return uuid.Nil, false
+	}
+	return silenceID, true
+}
+
+// newSilenceResponse 构建带当前状态的静默响应
+func newSilenceResponse(silence *domain.Silence, now time.Time) SilenceResponse {
+	return SilenceResponse{
+		Silence: silence,
+		State:   silence.State(now),
+	}
+}
+
+type CreateSilenceRequest struct {
+	OrganizationID string                 `json:"organization_id" binding:"required"`
+	Matchers       domain.SilenceMatchers `json:"matchers" binding:"required"`
+	StartsAt       *time.Time             `json:"starts_at"`
+	EndsAt         time.Time              `json:"ends_at" binding:"required"`
+	CreatedBy      string                 `json:"created_by"`
+	Comment        string                 `json:"comment" binding:"required"`
+}
+
+type UpdateSilenceRequest struct {
+	Matchers domain.SilenceMatchers `json:"matchers"`
+	StartsAt *time.Time             `json:"starts_at"`
+	EndsAt   *time.Time             `json:"ends_at"`
+	Comment  *string                `json:"comment"`
+}
+
+type SilenceResponse struct {
+	*domain.Silence
+	State domain.SilenceState `json:"state"`
+}
+
+type SilenceListResponse struct {
+	Silences []SilenceResponse `json:"silences"`
+	Total    int               `json:"total"`
+	Limit    int               `json:"limit"`
+	Offset   int               `json:"offset"`
+}
@@ -0,0 +1,233 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/edgelink/backend/internal/domain"
+	"github.com/edgelink/backend/internal/repository"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// TaskHandler 后台任务执行记录与手动触发处理器
+type TaskHandler struct {
+	taskRunRepo repository.TaskRunRepository
+	logger      *zap.Logger
+}
+
+// NewTaskHandler 创建TaskHandler实例
+func NewTaskHandler(
+	taskRunRepo repository.TaskRunRepository,
+	logger *zap.Logger,
+) *TaskHandler {
+	return &TaskHandler{
+		taskRunRepo: taskRunRepo,
+		logger:      logger,
+	}
+}
+
+// TaskSummary 后台任务及最近一次执行
+type TaskSummary struct {
+	Name    string          `json:"name"`
+	LastRun *domain.TaskRun `json:"last_run,omitempty"`
+}
+
+// TaskListResponse 后台任务列表响应
+type TaskListResponse struct {
+	Tasks []TaskSummary `json:"tasks"`
+}
+
+// TaskRunListResponse 执行记录列表响应
+type TaskRunListResponse struct {
+	Runs   []*domain.TaskRun `json:"runs"`
+	Total  int64             `json:"total"`
+	Limit  int               `json:"limit"`
+	Offset int               `json:"offset"`
+}
+
+// GetTasks godoc
+// @Summary      获取后台任务列表
+// @Description  列出Background Worker的所有任务及各自最近一次执行记录
+// @Tags         admin
+// @Produce      json
+// @Success      200  {object}  TaskListResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/v1/admin/tasks [get]
+func (h *TaskHandler) GetTasks(c *gin.Context) {
+	latest, err := h.taskRunRepo.FindLatest(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "query_failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	tasks := make([]TaskSummary, 0, len(domain.BackgroundTasks))
+	for _, name := range domain.BackgroundTasks {
+		tasks = append(tasks, TaskSummary{Name: name, LastRun: latest[name]})
+	}
+
+	c.JSON(http.StatusOK, TaskListResponse{Tasks: tasks})
+}
+
+// TriggerTask godoc
+// @Summary      手动触发后台任务
+// @Description  创建pending执行记录，由持有leader租约的Background Worker在任务空闲时执行；已有等待中的请求时返回409
+// @Tags         admin
+// @Produce      json
+// @Param        task_name  path  string  true  "任务名称"
+// @Success      202  {object}  domain.TaskRun
+// @Failure      404  {object}  ErrorResponse
+// @Failure      409  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/v1/admin/tasks/{task_name}/runs [post]
+func (h *TaskHandler) TriggerTask(c *gin.Context) {
+	taskName := c.Param("task_name")
+	if !domain.IsBackgroundTask(taskName) {
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:   "task_not_found",
+			Message: "Unknown background task: " + taskName,
+		})
+		return
+	}
+
+	// 同一任务只保留一个等待中的请求，避免重复点击堆积
+	pending := domain.TaskRunStatusPending
+	existing, _, err := h.taskRunRepo.FindByFilters(c.Request.Context(), &repository.TaskRunFilters{
+		TaskName: &taskName,
+		Status:   &pending,
+		Limit:    1,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "query_failed",
+			Message: err.Error(),
+		})
+		return
+	}
+	if len(existing) > 0 {
+		c.JSON(http.StatusConflict, ErrorResponse{
+			Error:   "task_already_pending",
+			Message: "Task " + taskName + " already has a pending run: " + existing[0].ID.String(),
+		})
+		return
+	}
+
+	run := &domain.TaskRun{
+		TaskName:    taskName,
+		Trigger:     domain.TaskRunTriggerManual,
+		Status:      domain.TaskRunStatusPending,
+		RequestedBy: actorIDFromHeader(c),
+	}
+	if err := h.taskRunRepo.Create(c.Request.Context(), run); err != nil {
+		h.logger.Error("Failed to create task run", zap.String("task", taskName), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "trigger_failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	h.logger.Info("Background task triggered manually",
+		zap.String("task", taskName),
+		zap.String("run_id", run.ID.String()),
+	)
+	c.JSON(http.StatusAccepted, run)
+}
+
+// GetTaskRuns godoc
+// @Summary      获取后台任务执行记录
+// @Description  按创建时间倒序列出执行记录，包括定时执行和手动触发
+// @Tags         admin
+// @Produce      json
+// @Param        task_name  query  string  false  "任务名称"
+// @Param        status     query  string  false  "状态：pending/running/success/failure/interrupted"
+// @Param        trigger    query  string  false  "触发方式：schedule/manual"
+// @Param        limit      query  int     false  "返回数量限制"
+// @Param        offset     query  int     false  "偏移量"
+// @Success      200  {object}  TaskRunListResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/v1/admin/task-runs [get]
+func (h *TaskHandler) GetTaskRuns(c *gin.Context) {
+	filters := &repository.TaskRunFilters{
+		Limit:  50,
+		Offset: 0,
+	}
+
+	if taskName := c.Query("task_name"); taskName != "" {
+		filters.TaskName = &taskName
+	}
+	if statusStr := c.Query("status"); statusStr != "" {
+		status := domain.TaskRunStatus(statusStr)
+		filters.Status = &status
+	}
+	if triggerStr := c.Query("trigger"); triggerStr != "" {
+		trigger := domain.TaskRunTrigger(triggerStr)
+		filters.Trigger = &trigger
+	}
+	if limitStr := c.Query("limit"); limitStr != "" {
+		filters.Limit, _ = strconv.Atoi(limitStr)
+	}
+	if offsetStr := c.Query("offset"); offsetStr != "" {
+		filters.Offset, _ = strconv.Atoi(offsetStr)
+	}
+
+	runs, total, err := h.taskRunRepo.FindByFilters(c.Request.Context(), filters)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "query_failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, TaskRunListResponse{
+		Runs:   runs,
+		Total:  total,
+		Limit:  filters.Limit,
+		Offset: filters.Offset,
+	})
+}
+
+// GetTaskRun godoc
+// @Summary      获取执行记录详情
+// @Tags         admin
+// @Produce      json
+// @Param        run_id  path  string  true  "执行记录ID"
+// @Success      200  {object}  domain.TaskRun
+// @Failure      400  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse
+// @Router       /api/v1/admin/task-runs/{run_id} [get]
+func (h *TaskHandler) GetTaskRun(c *gin.Context) {
+	runID, err := uuid.Parse(c.Param("run_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_run_id",
+			Message: "run_id must be a valid UUID",
+		})
+		return
+	}
+
+	run, err := h.taskRunRepo.FindByID(c.Request.Context(), runID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Error:   "task_run_not_found",
+				Message: "Task run not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "query_failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, run)
+}
@@ -1,11 +1,8 @@
 package middleware
 
 import (
-	"fmt"
-	"math/rand"
 	"net/http"
 	"strings"
-	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -240,32 +237,6 @@ func SecurityHeaders() gin.HandlerFunc {
 	}
 }
 
-// RequestID 请求ID中间件
-// 为每个请求生成唯一ID
-func RequestID() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		// 尝试从请求头获取ID
-		requestID := c.GetHeader("X-Request-ID")
-
-		// 如果没有，生成新ID
-		if requestID == "" {
-			requestID = generateRequestID()
-		}
-
-		// 设置到上下文和响应头
-		c.Set("request_id", requestID)
-		c.Header("X-Request-ID", requestID)
-
-		c.Next()
-	}
-}
-
-// generateRequestID 生成请求ID
-func generateRequestID() string {
-	// 简单实现：时间戳 + 随机数
-	return fmt.Sprintf("%d-%d", time.Now().UnixNano(), rand.Int63())
-}
-
 // TrustedProxies 配置可信代理
 func TrustedProxies(trustedProxies []string) gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -1,11 +1,14 @@
 package router
 
 import (
-	"net/http"
-
 	"github.com/edgelink/backend/cmd/api-gateway/internal/handler"
 	"github.com/edgelink/backend/cmd/api-gateway/internal/websocket"
 	"github.com/edgelink/backend/internal/audit"
+	"github.com/edgelink/backend/internal/config"
+	"github.com/edgelink/backend/internal/domain"
+	"github.com/edgelink/backend/internal/metrics"
+	"github.com/edgelink/backend/internal/middleware"
+	"github.com/edgelink/backend/internal/tracing"
 	"github.com/gin-gonic/gin"
 )
 
@@ -13,12 +16,30 @@ import (
 func SetupRouter(
 	deviceHandler *handler.DeviceHandler,
 	adminHandler *handler.AdminHandler,
+	alertHandler *handler.AlertHandler,
+	silenceHandler *handler.SilenceHandler,
+	notificationHandler *handler.NotificationHandler,
+	onCallHandler *handler.OnCallHandler,
+	callbackHandler *handler.CallbackHandler,
+	anomalyThresholdHandler *handler.AnomalyThresholdHandler,
+	auditHandler *handler.AuditHandler,
+	taskHandler *handler.TaskHandler,
+	retentionHandler *handler.RetentionHandler,
+	deviceApprovalHandler *handler.DeviceApprovalHandler,
+	deviceManagementHandler *handler.DeviceManagementHandler,
+	posturePolicyHandler *handler.PosturePolicyHandler,
 	wsHandler *websocket.WebSocketHandler,
 	auditMiddleware *audit.AuditMiddleware,
+	adminAuth *middleware.AdminAuth,
+	m *metrics.Metrics,
+	cfg *config.Config,
 ) *gin.Engine {
 	// 创建Gin引擎
 	r := gin.Default()
 
+	// 请求ID、追踪与指标（追踪span关联请求ID，需在RequestID之后）
+	r.Use(middleware.RequestID(), tracing.GinMiddleware(), m.GinMiddleware())
+
 	// 健康检查端点
 	r.GET("/health", func(c *gin.Context) {
 		c.JSON(200, gin.H{"status": "healthy"})
@@ -45,27 +66,125 @@ func SetupRouter(
 			device.POST("/:device_id/metrics", deviceHandler.SubmitDeviceMetrics)
 		}
 
+		// 外部平台回调端点（由各平台签名/令牌鉴权）
+		callbacks := v1.Group("/callbacks")
+		{
+			callbacks.POST("/slack", callbackHandler.SlackCallback)
+			callbacks.POST("/pagerduty", callbackHandler.PagerDutyCallback)
+			callbacks.POST("/opsgenie", callbackHandler.OpsgenieCallback)
+			callbacks.POST("/teams", callbackHandler.TeamsCallback)
+		}
+
 		// 管理员端点
 		admin := v1.Group("/admin")
+		if cfg.Auth.AdminAuthEnabled {
+			// 只读角色可查询，写操作需要网络运维及以上角色
+			admin.Use(adminAuth.Middleware(domain.RoleReadonly), adminAuth.RequireRoleForWrites(domain.RoleNetworkOperator))
+		}
 		admin.Use(auditMiddleware.Middleware()) // 应用审计日志中间件
 		{
 			// 设备管理
 			admin.GET("/devices", adminHandler.GetDevices)
 			admin.GET("/devices/:device_id", adminHandler.GetDeviceById)
+			admin.PATCH("/devices/:device_id", deviceManagementHandler.UpdateDevice)
 			admin.DELETE("/devices/:device_id", adminHandler.DeleteDevice)
+			admin.POST("/devices/:device_id/move", deviceManagementHandler.MoveDevice)
+			admin.POST("/devices/bulk/tag", deviceManagementHandler.BulkTagDevices)
+			admin.POST("/devices/bulk/approve", deviceManagementHandler.BulkApproveDevices)
+			admin.POST("/devices/bulk/delete", deviceManagementHandler.BulkDeleteDevices)
 			admin.GET("/devices/:device_id/peers", adminHandler.GetDevicePeers)
 			admin.GET("/devices/:device_id/metrics", adminHandler.GetDeviceMetrics)
+			admin.GET("/devices/:device_id/link-baselines", anomalyThresholdHandler.GetDeviceLinkBaselines)
+			admin.POST("/devices/:device_id/approve", deviceApprovalHandler.ApproveDevice)
+			admin.POST("/devices/:device_id/reject", deviceApprovalHandler.RejectDevice)
+			admin.GET("/devices/:device_id/posture", posturePolicyHandler.GetDevicePosture)
 
 			// 虚拟网络管理
 			admin.GET("/virtual-networks", adminHandler.GetVirtualNetworks)
 			admin.POST("/virtual-networks", adminHandler.CreateVirtualNetwork)
+			admin.PUT("/virtual-networks/:network_id/approval", deviceApprovalHandler.UpdateApprovalSetting)
+			admin.GET("/virtual-networks/:network_id/approval-rules", deviceApprovalHandler.GetApprovalRules)
+			admin.POST("/virtual-networks/:network_id/approval-rules", deviceApprovalHandler.CreateApprovalRule)
+			admin.DELETE("/virtual-networks/:network_id/approval-rules/:rule_id", deviceApprovalHandler.DeleteApprovalRule)
+			admin.GET("/virtual-networks/:network_id/posture-policies", posturePolicyHandler.GetPosturePolicies)
+			admin.POST("/virtual-networks/:network_id/posture-policies", posturePolicyHandler.CreatePosturePolicy)
+			admin.PUT("/virtual-networks/:network_id/posture-policies/:policy_id", posturePolicyHandler.UpdatePosturePolicy)
+			admin.DELETE("/virtual-networks/:network_id/posture-policies/:policy_id", posturePolicyHandler.DeletePosturePolicy)
 
 			// 告警管理
-			admin.GET("/alerts", adminHandler.GetAlerts)
-			admin.POST("/alerts/:alert_id/acknowledge", adminHandler.AcknowledgeAlert)
+			admin.GET("/alerts", alertHandler.GetAlerts)
+			admin.POST("/alerts/bulk/acknowledge", alertHandler.BulkAcknowledgeAlerts)
+			admin.POST("/alerts/bulk/resolve", alertHandler.BulkResolveAlerts)
+			admin.POST("/alerts/:alert_id/acknowledge", alertHandler.AcknowledgeAlert)
+			admin.POST("/alerts/:alert_id/resolve", alertHandler.ResolveAlert)
+			admin.POST("/alerts/:alert_id/reopen", alertHandler.ReopenAlert)
+			admin.POST("/alerts/:alert_id/assign", alertHandler.AssignAlert)
+			admin.GET("/alerts/:alert_id/comments", alertHandler.GetAlertComments)
+			admin.POST("/alerts/:alert_id/comments", alertHandler.AddAlertComment)
+			admin.GET("/alerts/:alert_id/notifications", notificationHandler.GetAlertNotifications)
+			admin.GET("/alerts/:alert_id/escalations", onCallHandler.GetAlertEscalations)
+
+			// 通知投递
+			admin.GET("/notifications/dead-letters", notificationHandler.GetDeadLetters)
+			admin.POST("/notifications/:delivery_id/redrive", notificationHandler.RedriveDelivery)
+			admin.GET("/notifications/webhook-endpoints", notificationHandler.GetWebhookEndpoints)
+			admin.POST("/notifications/webhook-endpoints/:endpoint_id/enable", notificationHandler.EnableWebhookEndpoint)
+
+			// 告警静默
+			admin.GET("/silences", silenceHandler.GetSilences)
+			admin.POST("/silences", silenceHandler.CreateSilence)
+			admin.GET("/silences/:silence_id", silenceHandler.GetSilence)
+			admin.PUT("/silences/:silence_id", silenceHandler.UpdateSilence)
+			admin.DELETE("/silences/:silence_id", silenceHandler.ExpireSilence)
+
+			// 值班排班
+			admin.GET("/oncall/now", onCallHandler.GetWhoIsOnCall)
+			admin.GET("/oncall/schedules", onCallHandler.GetSchedules)
+			admin.POST("/oncall/schedules", onCallHandler.CreateSchedule)
+			admin.GET("/oncall/schedules/:schedule_id", onCallHandler.GetSchedule)
+			admin.PUT("/oncall/schedules/:schedule_id", onCallHandler.UpdateSchedule)
+			admin.DELETE("/oncall/schedules/:schedule_id", onCallHandler.DeleteSchedule)
+			admin.POST("/oncall/schedules/:schedule_id/overrides", onCallHandler.CreateOverride)
+			admin.DELETE("/oncall/schedules/:schedule_id/overrides/:override_id", onCallHandler.DeleteOverride)
+
+			// 升级策略
+			admin.GET("/escalation-policies", onCallHandler.GetEscalationPolicies)
+			admin.POST("/escalation-policies", onCallHandler.CreateEscalationPolicy)
+			admin.GET("/escalation-policies/:policy_id", onCallHandler.GetEscalationPolicy)
+			admin.PUT("/escalation-policies/:policy_id", onCallHandler.UpdateEscalationPolicy)
+			admin.DELETE("/escalation-policies/:policy_id", onCallHandler.DeleteEscalationPolicy)
+
+			// 链路异常检测阈值覆盖
+			admin.GET("/anomaly-thresholds", anomalyThresholdHandler.GetAnomalyThresholds)
+			admin.POST("/anomaly-thresholds", anomalyThresholdHandler.CreateAnomalyThreshold)
+			admin.GET("/anomaly-thresholds/:threshold_id", anomalyThresholdHandler.GetAnomalyThreshold)
+			admin.PUT("/anomaly-thresholds/:threshold_id", anomalyThresholdHandler.UpdateAnomalyThreshold)
+			admin.DELETE("/anomaly-thresholds/:threshold_id", anomalyThresholdHandler.DeleteAnomalyThreshold)
+
+			// 外部平台用户映射
+			admin.GET("/external-identities", callbackHandler.ListExternalIdentities)
+			admin.POST("/external-identities", callbackHandler.CreateExternalIdentity)
+			admin.DELETE("/external-identities/:identity_id", callbackHandler.DeleteExternalIdentity)
 
 			// 审计日志
 			admin.GET("/audit-logs", adminHandler.GetAuditLogs)
+			admin.GET("/audit-logs/verify", auditHandler.VerifyAuditChain)
+			admin.GET("/audit-logs/checkpoints", auditHandler.GetAuditCheckpoints)
+			admin.GET("/audit-logs/export", auditHandler.ExportAuditLogs)
+
+			// 后台任务
+			admin.GET("/tasks", taskHandler.GetTasks)
+			admin.POST("/tasks/:task_name/runs", taskHandler.TriggerTask)
+			admin.GET("/task-runs", taskHandler.GetTaskRuns)
+			admin.GET("/task-runs/:run_id", taskHandler.GetTaskRun)
+
+			// 数据保留
+			admin.GET("/retention-policies", retentionHandler.GetRetentionPolicies)
+			admin.POST("/retention-policies", retentionHandler.CreateRetentionPolicy)
+			admin.PUT("/retention-policies/:policy_id", retentionHandler.UpdateRetentionPolicy)
+			admin.DELETE("/retention-policies/:policy_id", retentionHandler.DeleteRetentionPolicy)
+			admin.GET("/retention-runs", retentionHandler.GetRetentionRuns)
+			admin.GET("/retention-runs/:run_id", retentionHandler.GetRetentionRun)
 		}
 
 		// 统计数据API
@@ -5,7 +5,11 @@ import (
 	"encoding/json"
 	"fmt"
 
+	"github.com/edgelink/backend/internal/service"
+	"github.com/edgelink/backend/internal/tracing"
+	"github.com/google/uuid"
 	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel/attribute"
 	"go.uber.org/zap"
 )
 
@@ -15,26 +19,42 @@ type BroadcastMessage struct {
 	DeviceID  *string         `json:"device_id,omitempty"`
 	OrgID     *string         `json:"org_id,omitempty"`
 	Data      json.RawMessage `json:"data"`
+
+	// TraceContext 发布方的追踪上下文（W3C traceparent），订阅方据此延续追踪
+	TraceContext map[string]string `json:"trace_context,omitempty"`
 }
 
 // Broadcaster 事件广播器
 type Broadcaster struct {
-	redisClient *redis.Client
-	wsHandler   *WebSocketHandler
-	logger      *zap.Logger
-	channelName string
+	redisClient     *redis.Client
+	wsHandler       *WebSocketHandler
+	topologyService *service.TopologyService
+	logger          *zap.Logger
+	channelName     string
 }
 
 // NewBroadcaster 创建事件广播器
-func NewBroadcaster(redisClient *redis.Client, wsHandler *WebSocketHandler, logger *zap.Logger) *Broadcaster {
+func NewBroadcaster(
+	redisClient *redis.Client,
+	wsHandler *WebSocketHandler,
+	topologyService *service.TopologyService,
+	logger *zap.Logger,
+) *Broadcaster {
 	return &Broadcaster{
-		redisClient: redisClient,
-		wsHandler:   wsHandler,
-		logger:      logger,
-		channelName: "edgelink:events",
+		redisClient:     redisClient,
+		wsHandler:       wsHandler,
+		topologyService: topologyService,
+		logger:          logger,
+		channelName:     "edgelink:events",
 	}
 }
 
+// deviceRemovedData device_removed事件中释放虚拟IP所需的字段
+type deviceRemovedData struct {
+	VirtualNetworkID uuid.UUID `json:"virtual_network_id"`
+	VirtualIP        string    `json:"virtual_ip"`
+}
+
 // Start 启动广播器（订阅Redis频道）
 func (b *Broadcaster) Start(ctx context.Context) error {
 	// 订阅Redis频道
@@ -60,13 +80,13 @@ func (b *Broadcaster) Start(ctx context.Context) error {
 			return ctx.Err()
 
 		case msg := <-ch:
-			b.handleRedisMessage(msg)
+			b.handleRedisMessage(ctx, msg)
 		}
 	}
 }
 
 // handleRedisMessage 处理从Redis接收的消息
-func (b *Broadcaster) handleRedisMessage(msg *redis.Message) {
+func (b *Broadcaster) handleRedisMessage(ctx context.Context, msg *redis.Message) {
 	var broadcastMsg BroadcastMessage
 	if err := json.Unmarshal([]byte(msg.Payload), &broadcastMsg); err != nil {
 		b.logger.Error("Failed to unmarshal Redis message",
@@ -76,12 +96,43 @@ func (b *Broadcaster) handleRedisMessage(msg *redis.Message) {
 		return
 	}
 
+	ctx, span := tracing.StartReceive(ctx, b.channelName, broadcastMsg.TraceContext)
+	span.SetAttributes(attribute.String("event_type", broadcastMsg.EventType))
+	defer span.End()
+
+	if broadcastMsg.EventType == MessageTypeDeviceRemoved {
+		b.releaseVirtualIP(ctx, broadcastMsg.Data)
+	}
+
 	// 通过WebSocket广播给客户端
 	b.wsHandler.Broadcast(&broadcastMsg)
 }
 
+// releaseVirtualIP 将移出网络的设备的虚拟IP归还本副本的地址池
+// 后台任务撤销临时设备、其他副本删除或迁移设备时，本副本只能通过事件得知IP已释放
+func (b *Broadcaster) releaseVirtualIP(ctx context.Context, payload json.RawMessage) {
+	var data deviceRemovedData
+	if err := json.Unmarshal(payload, &data); err != nil || data.VirtualIP == "" {
+		return
+	}
+
+	if err := b.topologyService.ReleaseVirtualIP(ctx, data.VirtualNetworkID, data.VirtualIP); err != nil {
+		b.logger.Warn("Failed to release virtual IP",
+			zap.Error(err),
+			zap.String("virtual_network_id", data.VirtualNetworkID.String()),
+			zap.String("virtual_ip", data.VirtualIP),
+		)
+	}
+}
+
 // Publish 发布事件到Redis（供其他服务调用）
-func (b *Broadcaster) Publish(ctx context.Context, msg *BroadcastMessage) error {
+func (b *Broadcaster) Publish(ctx context.Context, msg *BroadcastMessage) (err error) {
+	ctx, span := tracing.StartPublish(ctx, b.channelName)
+	span.SetAttributes(attribute.String("event_type", msg.EventType))
+	defer func() { tracing.End(span, err) }()
+
+	msg.TraceContext = tracing.Inject(ctx)
+
 	data, err := json.Marshal(msg)
 	if err != nil {
 		return fmt.Errorf("failed to marshal broadcast message: %w", err)
@@ -169,3 +220,63 @@ func (b *Broadcaster) PublishSessionUpdate(ctx context.Context, orgID string, da
 		Data:      jsonData,
 	})
 }
+
+// PublishDeviceApproval 发布设备审批事件（新设备等待审批、批准或拒绝）
+func (b *Broadcaster) PublishDeviceApproval(ctx context.Context, deviceID, orgID string, data interface{}) error {
+	jsonData, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	return b.Publish(ctx, &BroadcastMessage{
+		EventType: MessageTypeDeviceApproval,
+		DeviceID:  &deviceID,
+		OrgID:     &orgID,
+		Data:      jsonData,
+	})
+}
+
+// PublishDevicePosture 发布设备安全状态评估结果变化事件（被隔离、限制对端或恢复合规）
+func (b *Broadcaster) PublishDevicePosture(ctx context.Context, deviceID, orgID string, data interface{}) error {
+	jsonData, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	return b.Publish(ctx, &BroadcastMessage{
+		EventType: MessageTypeDevicePosture,
+		DeviceID:  &deviceID,
+		OrgID:     &orgID,
+		Data:      jsonData,
+	})
+}
+
+// PublishDeviceRemoved 发布设备移出网络事件，同网络的设备据此移除该peer
+func (b *Broadcaster) PublishDeviceRemoved(ctx context.Context, deviceID, orgID string, data interface{}) error {
+	jsonData, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	return b.Publish(ctx, &BroadcastMessage{
+		EventType: MessageTypeDeviceRemoved,
+		DeviceID:  &deviceID,
+		OrgID:     &orgID,
+		Data:      jsonData,
+	})
+}
+
+// PublishDeviceUpdated 发布设备名称、标签或所属网络变更事件，相关设备据此重新拉取配置
+func (b *Broadcaster) PublishDeviceUpdated(ctx context.Context, deviceID, orgID string, data interface{}) error {
+	jsonData, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	return b.Publish(ctx, &BroadcastMessage{
+		EventType: MessageTypeDeviceUpdated,
+		DeviceID:  &deviceID,
+		OrgID:     &orgID,
+		Data:      jsonData,
+	})
+}
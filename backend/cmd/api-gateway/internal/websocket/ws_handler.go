@@ -8,6 +8,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/edgelink/backend/internal/metrics"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
@@ -28,6 +29,10 @@ const (
 	MessageTypeAlertUpdated    = "alert_updated"
 	MessageTypeMetricsUpdate   = "metrics_update"
 	MessageTypeSessionUpdate   = "session_update"
+	MessageTypeDeviceApproval  = "device_approval"
+	MessageTypeDeviceRemoved   = "device_removed"
+	MessageTypeDevicePosture   = "device_posture"
+	MessageTypeDeviceUpdated   = "device_updated"
 	MessageTypeError           = "error"
 )
 
@@ -72,17 +77,19 @@ type WebSocketHandler struct {
 	unregister chan *Client
 	broadcast  chan *BroadcastMessage
 	mu         sync.RWMutex
+	metrics    *metrics.Metrics
 	logger     *zap.Logger
 	upgrader   websocket.Upgrader
 }
 
 // NewWebSocketHandler 创建WebSocket处理器
-func NewWebSocketHandler(logger *zap.Logger) *WebSocketHandler {
+func NewWebSocketHandler(m *metrics.Metrics, logger *zap.Logger) *WebSocketHandler {
 	return &WebSocketHandler{
 		clients:    make(map[string]*Client),
 		register:   make(chan *Client, 256),
 		unregister: make(chan *Client, 256),
 		broadcast:  make(chan *BroadcastMessage, 1024),
+		metrics:    m,
 		logger:     logger,
 		upgrader: websocket.Upgrader{
 			ReadBufferSize:  1024,
@@ -106,6 +113,7 @@ func (h *WebSocketHandler) Run(ctx context.Context) {
 		case client := <-h.register:
 			h.mu.Lock()
 			h.clients[client.ID] = client
+			h.metrics.UpdateWebSocketClients(len(h.clients))
 			h.mu.Unlock()
 			h.logger.Info("Client registered",
 				zap.String("client_id", client.ID),
@@ -118,6 +126,7 @@ func (h *WebSocketHandler) Run(ctx context.Context) {
 				delete(h.clients, client.ID)
 				close(client.Send)
 			}
+			h.metrics.UpdateWebSocketClients(len(h.clients))
 			h.mu.Unlock()
 			h.logger.Info("Client unregistered",
 				zap.String("client_id", client.ID),
@@ -11,14 +11,19 @@ import (
 
 	"github.com/edgelink/backend/cmd/api-gateway/internal/handler"
 	"github.com/edgelink/backend/cmd/api-gateway/internal/router"
+	"github.com/edgelink/backend/cmd/api-gateway/internal/websocket"
 	"github.com/edgelink/backend/internal/audit"
 	"github.com/edgelink/backend/internal/service"
 	"github.com/edgelink/backend/internal/auth"
 	"github.com/edgelink/backend/internal/config"
 	"github.com/edgelink/backend/internal/database"
 	"github.com/edgelink/backend/internal/logger"
+	"github.com/edgelink/backend/internal/metrics"
+	"github.com/edgelink/backend/internal/middleware"
 	"github.com/edgelink/backend/internal/repository"
+	"github.com/edgelink/backend/internal/tracing"
 	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
 	"go.uber.org/fx"
 	"go.uber.org/zap"
 )
@@ -29,11 +34,13 @@ func main() {
 		fx.Provide(
 			config.LoadConfig,
 			logger.NewLogger,
+			metrics.New,
 		),
 
 		// 数据库模块
 		fx.Provide(
 			database.NewPostgresDB,
+			NewRedisClient,
 		),
 
 		// 仓储层
@@ -45,8 +52,27 @@ func main() {
 			repository.NewPreSharedKeyRepository,
 			repository.NewSessionRepository,
 			repository.NewAlertRepository,
+			repository.NewAlertCommentRepository,
+			repository.NewSilenceRepository,
+			repository.NewNotificationDeliveryRepository,
+			repository.NewEmailHistoryRepository,
 			repository.NewAuditLogRepository,
+			repository.NewAuditCheckpointRepository,
 			repository.NewAdminUserRepository,
+			repository.NewOnCallScheduleRepository,
+			repository.NewEscalationPolicyRepository,
+			repository.NewAlertEscalationRepository,
+			repository.NewAlertGroupRepository,
+			repository.NewExternalIdentityRepository,
+			repository.NewWebhookEndpointRepository,
+			repository.NewLinkMetricRepository,
+			repository.NewAnomalyThresholdRepository,
+			repository.NewDeviceStatusEventRepository,
+			repository.NewTaskRunRepository,
+			repository.NewRetentionPolicyRepository,
+			repository.NewRetentionRunRepository,
+			repository.NewDeviceApprovalRuleRepository,
+			repository.NewPosturePolicyRepository,
 		),
 
 		// 认证模块
@@ -58,22 +84,38 @@ func main() {
 		fx.Provide(
 			service.NewDeviceService,
 			service.NewTopologyService,
+			service.NewSilenceService,
 		),
 
 		// 处理器层
 		fx.Provide(
 			handler.NewDeviceHandler,
 			handler.NewAdminHandler,
-		)
+			handler.NewAlertHandler,
+			handler.NewSilenceHandler,
+			handler.NewNotificationHandler,
+			handler.NewOnCallHandler,
+			handler.NewCallbackHandler,
+			handler.NewAnomalyThresholdHandler,
+			handler.NewAuditHandler,
+			handler.NewTaskHandler,
+			handler.NewRetentionHandler,
+			handler.NewDeviceApprovalHandler,
+			handler.NewDeviceManagementHandler,
+			handler.NewPosturePolicyHandler,
+		),
 
 		// WebSocket处理器
 		fx.Provide(
 			websocket.NewWebSocketHandler,
+			websocket.NewBroadcaster,
 		),
 
-		// 审计中间件
+		// 审计中间件与哈希链校验
 		fx.Provide(
 			audit.NewAuditMiddleware,
+			audit.NewChainVerifier,
+			middleware.NewAdminAuth,
 		),
 
 		// HTTP路由器
@@ -81,9 +123,15 @@ func main() {
 			router.SetupRouter,
 		),
 
+		// 分布式追踪（最先注册，停止时最后刷新span）
+		fx.Invoke(tracing.Setup("api-gateway")),
+
 		// HTTP服务器
 		fx.Invoke(runHTTPServer),
 
+		// 指标服务器
+		fx.Invoke(metrics.RunServer),
+
 		// WebSocket广播器
 		fx.Invoke(startWebSocketBroadcaster),
 	)
@@ -146,21 +194,43 @@ func runHTTPServer(
 	}()
 }
 
+// NewRedisClient 创建Redis客户端
+func NewRedisClient(cfg *config.Config) *redis.Client {
+	return redis.NewClient(&redis.Options{
+		Addr:     cfg.Redis.Addr(),
+		Password: cfg.Redis.Password,
+		DB:       cfg.Redis.DB,
+		PoolSize: cfg.Redis.PoolSize,
+	})
+}
+
 // startWebSocketBroadcaster 启动WebSocket广播器
 func startWebSocketBroadcaster(
 	lifecycle fx.Lifecycle,
 	log *zap.Logger,
 	wsHandler *websocket.WebSocketHandler,
+	broadcaster *websocket.Broadcaster,
 ) {
+	ctx, cancel := context.WithCancel(context.Background())
+
 	lifecycle.Append(fx.Hook{
-		OnStart: func(ctx context.Context) error {
+		OnStart: func(context.Context) error {
 			log.Info("Starting WebSocket broadcaster")
-			// WebSocket广播器已经在NewWebSocketHandler中启动
+
+			// 事件循环负责客户端注册和消息分发
+			go wsHandler.Run(ctx)
+
+			// 订阅Redis事件频道，转发给WebSocket客户端
+			go func() {
+				if err := broadcaster.Start(ctx); err != nil && err != context.Canceled {
+					log.Error("WebSocket broadcaster stopped", zap.Error(err))
+				}
+			}()
 			return nil
 		},
-		OnStop: func(ctx context.Context) error {
+		OnStop: func(context.Context) error {
 			log.Info("Stopping WebSocket broadcaster")
-			wsHandler.Shutdown()
+			cancel()
 			return nil
 		},
 	})
@@ -0,0 +1,243 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/edgelink/backend/internal/audit"
+	"github.com/edgelink/backend/internal/config"
+	"github.com/edgelink/backend/internal/crypto"
+	"github.com/edgelink/backend/internal/database"
+	"github.com/edgelink/backend/internal/logger"
+	"github.com/edgelink/backend/internal/repository"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		printUsage()
+		os.Exit(1)
+	}
+
+	switch command := os.Args[1]; command {
+	case "verify":
+		os.Exit(runVerify(os.Args[2:]))
+	case "checkpoint":
+		os.Exit(runCheckpoint(os.Args[2:]))
+	case "keygen":
+		os.Exit(runKeygen())
+	default:
+		fmt.Printf("Unknown command: %s\n", command)
+		printUsage()
+		os.Exit(1)
+	}
+}
+
+func printUsage() {
+	fmt.Println("Usage: audit <command> [flags]")
+	fmt.Println()
+	fmt.Println("Commands:")
+	fmt.Println("  verify      - Verify audit log hash chains and signed checkpoints")
+	fmt.Println("  checkpoint  - Sign the current chain head of every organization")
+	fmt.Println("  keygen      - Generate an Ed25519 key pair for checkpoint signing")
+	fmt.Println()
+	fmt.Println("Environment Variables:")
+	fmt.Println("  DB_HOST, DB_PORT, DB_USER, DB_PASSWORD, DB_NAME - Database connection")
+	fmt.Println("  AUDIT_SIGNING_PUBLIC_KEY   - Current checkpoint public key (Base64)")
+	fmt.Println("  AUDIT_SIGNING_PRIVATE_KEY  - Checkpoint private key (Base64, checkpoint only)")
+	fmt.Println("  AUDIT_TRUSTED_PUBLIC_KEYS  - Previously used public keys, comma separated")
+}
+
+// runVerify 校验哈希链，发现断裂时返回退出码3，便于在定时任务中告警
+func runVerify(args []string) int {
+	fs := flag.NewFlagSet("verify", flag.ExitOnError)
+	orgIDStr := fs.String("org", "", "only verify this organization ID")
+	output := fs.String("output", "text", "output format: text or json")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: audit verify [flags]")
+		fmt.Fprintln(os.Stderr)
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	var orgID uuid.UUID
+	if *orgIDStr != "" {
+		id, err := uuid.Parse(*orgIDStr)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "org must be a valid UUID")
+			return 2
+		}
+		orgID = id
+	}
+
+	cfg, log, db, code := setup()
+	if code != 0 {
+		return code
+	}
+	defer log.Sync()
+
+	verifier, err := audit.NewChainVerifier(
+		repository.NewAuditLogRepository(db),
+		repository.NewAuditCheckpointRepository(db),
+		cfg,
+		log,
+	)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to create verifier: %v\n", err)
+		return 1
+	}
+
+	var results []*audit.VerificationResult
+	if orgID != uuid.Nil {
+		result, err := verifier.Verify(context.Background(), orgID)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Verification failed: %v\n", err)
+			return 1
+		}
+		results = []*audit.VerificationResult{result}
+	} else {
+		results, err = verifier.VerifyAll(context.Background())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Verification failed: %v\n", err)
+			return 1
+		}
+	}
+
+	switch *output {
+	case "json":
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(results); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to encode results: %v\n", err)
+			return 1
+		}
+	default:
+		printResults(results)
+	}
+
+	for _, result := range results {
+		if !result.Valid {
+			return 3
+		}
+	}
+	return 0
+}
+
+// printResults 以表格形式输出校验结果，断裂的链附带第一个断裂位置
+func printResults(results []*audit.VerificationResult) {
+	if len(results) == 0 {
+		fmt.Println("No chained audit logs found")
+		return
+	}
+	if !results[0].SignaturesVerified {
+		fmt.Println("Warning: no audit public key configured, checkpoint signatures were not verified")
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "ORGANIZATION\tSTATUS\tRECORDS\tSEQUENCES\tCHECKPOINTS\tUNCHAINED")
+	for _, result := range results {
+		status := "ok"
+		if !result.Valid {
+			status = "BROKEN"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%d\t%d-%d\t%d\t%d\n",
+			result.OrganizationID, status, result.RecordsVerified,
+			result.FirstSequence, result.LastSequence, result.CheckpointsVerified, result.UnchainedRecords)
+	}
+	w.Flush()
+
+	for _, result := range results {
+		if result.BrokenLink == nil {
+			continue
+		}
+		brk := result.BrokenLink
+		fmt.Println()
+		fmt.Printf("Organization %s: first broken link at sequence %d (%s)\n", result.OrganizationID, brk.Sequence, brk.Reason)
+		if brk.AuditLogID != nil {
+			fmt.Printf("  audit log: %s\n", brk.AuditLogID)
+		}
+		if brk.Expected != "" || brk.Actual != "" {
+			fmt.Printf("  expected:  %s\n", brk.Expected)
+			fmt.Printf("  actual:    %s\n", brk.Actual)
+		}
+	}
+}
+
+// runCheckpoint 立即为所有组织生成检查点（通常由Background Worker定期执行）
+func runCheckpoint(args []string) int {
+	fs := flag.NewFlagSet("checkpoint", flag.ExitOnError)
+	fs.Parse(args)
+
+	cfg, log, db, code := setup()
+	if code != 0 {
+		return code
+	}
+	defer log.Sync()
+
+	checkpointer, err := audit.NewCheckpointer(
+		repository.NewAuditLogRepository(db),
+		repository.NewAuditCheckpointRepository(db),
+		cfg,
+		log,
+	)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to create checkpointer: %v\n", err)
+		return 1
+	}
+	if !checkpointer.Enabled() {
+		fmt.Fprintln(os.Stderr, "AUDIT_SIGNING_PUBLIC_KEY and AUDIT_SIGNING_PRIVATE_KEY must be set")
+		return 2
+	}
+
+	if err := checkpointer.Run(context.Background()); err != nil {
+		fmt.Fprintf(os.Stderr, "Checkpoint failed: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// runKeygen 生成检查点签名密钥对
+func runKeygen() int {
+	keyPair, err := crypto.GenerateKeyPair()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to generate key pair: %v\n", err)
+		return 1
+	}
+
+	encoded := keyPair.ToBase64()
+	fmt.Printf("AUDIT_SIGNING_PUBLIC_KEY=%s\n", encoded.PublicKey)
+	fmt.Printf("AUDIT_SIGNING_PRIVATE_KEY=%s\n", encoded.PrivateKey)
+	return 0
+}
+
+// setup 加载配置并连接数据库
+func setup() (*config.Config, *zap.Logger, *gorm.DB, int) {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load config: %v\n", err)
+		return nil, nil, nil, 1
+	}
+
+	log, err := logger.NewLogger(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to create logger: %v\n", err)
+		return nil, nil, nil, 1
+	}
+
+	db, err := database.NewPostgresDB(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to connect to database: %v\n", err)
+		return nil, nil, nil, 1
+	}
+	// 关闭SQL日志，避免污染报告输出
+	db = db.Session(&gorm.Session{Logger: gormlogger.Default.LogMode(gormlogger.Silent)})
+
+	return cfg, log, db, 0
+}
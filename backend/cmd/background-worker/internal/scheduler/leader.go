@@ -0,0 +1,134 @@
+package scheduler
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/edgelink/backend/internal/config"
+	"github.com/edgelink/backend/internal/metrics"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// leaderLeaseKey leader租约在Redis中的键，值为持有者的实例标识
+const leaderLeaseKey = "worker:leader"
+
+// renewScript 仅当租约仍由本实例持有时续期
+var renewScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+// releaseScript 仅当租约仍由本实例持有时释放，避免删除其他实例的租约
+var releaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// Elector 基于Redis租约的leader选举
+// 只有持有租约的实例执行任务；leader每ttl/3续期一次，连续续期失败超过2/3个ttl时主动放弃，
+// 保证租约在Redis中过期、其他实例接管之前本实例已经停止执行
+type Elector struct {
+	redis    *redis.Client
+	instance string
+	ttl      time.Duration
+	metrics  *metrics.Metrics
+	logger   *zap.Logger
+}
+
+// NewElector 创建leader选举器
+func NewElector(cfg *config.Config, redisClient *redis.Client, m *metrics.Metrics, logger *zap.Logger) *Elector {
+	return &Elector{
+		redis:    redisClient,
+		instance: cfg.Worker.InstanceID,
+		ttl:      cfg.Worker.LeaseTTL,
+		metrics:  m,
+		logger:   logger,
+	}
+}
+
+// Run 持续参与选举，成为leader时调用lead，失去leader身份时取消传给lead的上下文并等待其返回
+// 阻塞直到ctx取消，退出前释放租约
+func (e *Elector) Run(ctx context.Context, lead func(ctx context.Context)) {
+	interval := e.ttl / 3
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var (
+		leaderCancel context.CancelFunc
+		leaderDone   sync.WaitGroup
+		lastRenewed  time.Time
+	)
+
+	stepDown := func(reason string) {
+		if leaderCancel == nil {
+			return
+		}
+		e.logger.Warn("Stepping down as background worker leader",
+			zap.String("instance", e.instance),
+			zap.String("reason", reason),
+		)
+		leaderCancel()
+		leaderDone.Wait()
+		leaderCancel = nil
+		e.metrics.SetWorkerLeader(false)
+	}
+
+	for {
+		if leaderCancel == nil {
+			acquired, err := e.redis.SetNX(ctx, leaderLeaseKey, e.instance, e.ttl).Result()
+			if err != nil && ctx.Err() == nil {
+				e.logger.Warn("Failed to acquire leader lease", zap.Error(err))
+			}
+			if acquired {
+				e.logger.Info("Became background worker leader", zap.String("instance", e.instance))
+				e.metrics.SetWorkerLeader(true)
+				lastRenewed = time.Now()
+
+				var leaderCtx context.Context
+				leaderCtx, leaderCancel = context.WithCancel(ctx)
+				leaderDone.Add(1)
+				go func() {
+					defer leaderDone.Done()
+					lead(leaderCtx)
+				}()
+			}
+		} else {
+			renewed, err := renewScript.Run(ctx, e.redis, []string{leaderLeaseKey}, e.instance, e.ttl.Milliseconds()).Int()
+			switch {
+			case err == nil && renewed == 1:
+				lastRenewed = time.Now()
+			case err == nil:
+				stepDown("lease taken over by another instance")
+			case ctx.Err() == nil:
+				e.logger.Warn("Failed to renew leader lease", zap.Error(err))
+				if time.Since(lastRenewed) > e.ttl*2/3 {
+					stepDown("lease renewal failed")
+				}
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			if leaderCancel != nil {
+				leaderCancel()
+				leaderDone.Wait()
+				e.metrics.SetWorkerLeader(false)
+
+				// ctx已取消，使用独立的短超时上下文释放租约，让其他实例立即接管
+				releaseCtx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+				if err := releaseScript.Run(releaseCtx, e.redis, []string{leaderLeaseKey}, e.instance).Err(); err != nil {
+					e.logger.Warn("Failed to release leader lease", zap.Error(err))
+				}
+				cancel()
+			}
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,252 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/edgelink/backend/internal/config"
+	"github.com/edgelink/backend/internal/domain"
+	"github.com/edgelink/backend/internal/metrics"
+	"github.com/edgelink/backend/internal/repository"
+	"github.com/robfig/cron/v3"
+	"go.uber.org/zap"
+)
+
+// scheduleOff 调度配置为该值时任务只能手动触发
+const scheduleOff = "off"
+
+// task 已注册的后台任务
+type task struct {
+	name     string
+	schedule string // 为空表示不定时执行
+	run      func(context.Context) error
+}
+
+// Scheduler 后台任务调度器
+// 定时执行与手动触发都经过同一入口：同一任务同时只执行一次，每次执行写入task_runs
+type Scheduler struct {
+	taskRunRepo  repository.TaskRunRepository
+	metrics      *metrics.Metrics
+	logger       *zap.Logger
+	instance     string
+	pollInterval time.Duration
+	schedules    map[string]string
+
+	tasks []*task
+
+	mu      sync.Mutex
+	running map[string]bool
+	wg      sync.WaitGroup
+}
+
+// NewScheduler 创建调度器
+func NewScheduler(
+	cfg *config.Config,
+	taskRunRepo repository.TaskRunRepository,
+	m *metrics.Metrics,
+	logger *zap.Logger,
+) *Scheduler {
+	return &Scheduler{
+		taskRunRepo:  taskRunRepo,
+		metrics:      m,
+		logger:       logger,
+		instance:     cfg.Worker.InstanceID,
+		pollInterval: cfg.Worker.TriggerPollInterval,
+		schedules:    cfg.Worker.Schedules,
+		running:      make(map[string]bool),
+	}
+}
+
+// Register 注册任务，WORKER_SCHEDULE_<TASK>可覆盖默认调度，"off"表示只允许手动触发
+func (s *Scheduler) Register(name, defaultSchedule string, run func(context.Context) error) error {
+	schedule := defaultSchedule
+	if override, ok := s.schedules[name]; ok {
+		schedule = override
+	}
+	if schedule == scheduleOff {
+		schedule = ""
+	}
+	if schedule != "" {
+		if _, err := cron.ParseStandard(schedule); err != nil {
+			return fmt.Errorf("invalid schedule for task %s: %w", name, err)
+		}
+	}
+
+	s.tasks = append(s.tasks, &task{name: name, schedule: schedule, run: run})
+	return nil
+}
+
+// Validate 检查调度覆盖是否都对应已注册的任务
+func (s *Scheduler) Validate() error {
+	for name := range s.schedules {
+		if s.find(name) == nil {
+			return fmt.Errorf("WORKER_SCHEDULE_%s: unknown task %s", strings.ToUpper(name), name)
+		}
+	}
+	return nil
+}
+
+// Run 作为leader执行任务：启动定时调度并轮询手动触发请求，阻塞直到ctx取消并等待执行中的任务退出
+func (s *Scheduler) Run(ctx context.Context) {
+	if n, err := s.taskRunRepo.InterruptStale(ctx, s.instance); err != nil {
+		s.logger.Warn("Failed to mark stale task runs as interrupted", zap.Error(err))
+	} else if n > 0 {
+		s.logger.Info("Marked stale task runs as interrupted", zap.Int64("count", n))
+	}
+
+	c := cron.New()
+	for _, t := range s.tasks {
+		if t.schedule == "" {
+			s.logger.Info("Task has no schedule, manual trigger only", zap.String("task", t.name))
+			continue
+		}
+		t := t
+		c.AddFunc(t.schedule, func() {
+			s.runScheduled(ctx, t)
+		})
+		s.logger.Info("Task scheduled", zap.String("task", t.name), zap.String("schedule", t.schedule))
+	}
+	c.Start()
+
+	ticker := time.NewTicker(s.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			<-c.Stop().Done()
+			s.wg.Wait()
+			return
+		case <-ticker.C:
+			s.claimManualRuns(ctx)
+		}
+	}
+}
+
+// claimManualRuns 为空闲的任务认领手动触发请求
+// 先占用任务再认领，避免认领后与定时执行冲突导致请求停留在running状态
+func (s *Scheduler) claimManualRuns(ctx context.Context) {
+	for _, t := range s.tasks {
+		if !s.tryStart(t.name) {
+			continue
+		}
+
+		run, err := s.taskRunRepo.ClaimPending(ctx, t.name, s.instance)
+		if err != nil && ctx.Err() == nil {
+			s.logger.Warn("Failed to claim manual task run", zap.String("task", t.name), zap.Error(err))
+		}
+		if run == nil {
+			s.finish(t.name)
+			continue
+		}
+
+		s.logger.Info("Running manually triggered task",
+			zap.String("task", t.name),
+			zap.String("run_id", run.ID.String()),
+		)
+		go s.executeAndFinish(ctx, t, run)
+	}
+}
+
+// runScheduled 定时执行任务，任务仍在执行时跳过本次调度
+func (s *Scheduler) runScheduled(ctx context.Context, t *task) {
+	if !s.tryStart(t.name) {
+		s.logger.Warn("Task still running, skipping scheduled run", zap.String("task", t.name))
+		return
+	}
+	s.executeAndFinish(ctx, t, nil)
+}
+
+// executeAndFinish 执行任务并释放占用
+func (s *Scheduler) executeAndFinish(ctx context.Context, t *task, run *domain.TaskRun) {
+	defer s.finish(t.name)
+	s.execute(ctx, t, run)
+}
+
+// tryStart 占用任务，任务正在执行时返回false
+func (s *Scheduler) tryStart(name string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.running[name] {
+		return false
+	}
+	s.running[name] = true
+	s.wg.Add(1)
+	return true
+}
+
+// finish 释放任务占用
+func (s *Scheduler) finish(name string) {
+	s.mu.Lock()
+	delete(s.running, name)
+	s.mu.Unlock()
+	s.wg.Done()
+}
+
+// execute 执行任务并记录耗时与结果
+func (s *Scheduler) execute(ctx context.Context, t *task, run *domain.TaskRun) {
+	start := time.Now()
+	if run == nil {
+		run = &domain.TaskRun{
+			TaskName:  t.name,
+			Trigger:   domain.TaskRunTriggerSchedule,
+			Status:    domain.TaskRunStatusRunning,
+			Instance:  &s.instance,
+			StartedAt: &start,
+		}
+		if err := s.taskRunRepo.Create(ctx, run); err != nil {
+			// 执行记录写入失败不影响任务本身
+			s.logger.Warn("Failed to record task run", zap.String("task", t.name), zap.Error(err))
+			run = nil
+		}
+	}
+
+	err := t.run(ctx)
+	duration := time.Since(start)
+	s.metrics.RecordTask(t.name, duration, err)
+	if err != nil {
+		s.logger.Error("Background task failed", zap.String("task", t.name), zap.Error(err))
+	}
+
+	if run == nil {
+		return
+	}
+
+	finishedAt := time.Now()
+	durationMs := duration.Milliseconds()
+	run.FinishedAt = &finishedAt
+	run.DurationMs = &durationMs
+	switch {
+	case ctx.Err() != nil:
+		run.Status = domain.TaskRunStatusInterrupted
+	case err != nil:
+		run.Status = domain.TaskRunStatusFailure
+	default:
+		run.Status = domain.TaskRunStatusSuccess
+	}
+	if err != nil {
+		message := err.Error()
+		run.Error = &message
+	}
+
+	// 失去leader身份或停止时ctx已取消，仍需写入结果
+	finishCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+	defer cancel()
+	if err := s.taskRunRepo.Finish(finishCtx, run); err != nil {
+		s.logger.Warn("Failed to record task run result", zap.String("task", t.name), zap.Error(err))
+	}
+}
+
+// find 根据名称查找已注册的任务
+func (s *Scheduler) find(name string) *task {
+	for _, t := range s.tasks {
+		if t.name == name {
+			return t
+		}
+	}
+	return nil
+}
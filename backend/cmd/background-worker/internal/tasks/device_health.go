@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/edgelink/backend/internal/domain"
+	"github.com/edgelink/backend/internal/metrics"
 	"github.com/edgelink/backend/internal/repository"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
@@ -14,6 +15,7 @@ import (
 type DeviceHealthTask struct {
 	deviceRepo repository.DeviceRepository
 	alertRepo  repository.AlertRepository
+	metrics    *metrics.Metrics
 	logger     *zap.Logger
 }
 
@@ -21,11 +23,13 @@ type DeviceHealthTask struct {
 func NewDeviceHealthTask(
 	deviceRepo repository.DeviceRepository,
 	alertRepo repository.AlertRepository,
+	m *metrics.Metrics,
 	logger *zap.Logger,
 ) *DeviceHealthTask {
 	return &DeviceHealthTask{
 		deviceRepo: deviceRepo,
 		alertRepo:  alertRepo,
+		metrics:    m,
 		logger:     logger,
 	}
 }
@@ -41,13 +45,21 @@ func (t *DeviceHealthTask) Run(ctx context.Context) error {
 		return err
 	}
 
+	// 更新设备数量指标
+	total, err := t.deviceRepo.CountByOrganization(ctx, nil)
+	if err != nil {
+		t.logger.Warn("Failed to count devices", zap.Error(err))
+	} else {
+		t.metrics.UpdateDeviceCount(total, len(devices))
+	}
+
 	now := time.Now()
 	offlineThreshold := 5 * time.Minute
 	alertsCreated := 0
 
 	for _, device := range devices {
-		// 检查最后上线时间
-		if device.LastSeenAt == nil {
+		// 检查最后上线时间；临时设备离线后会被自动撤销，不产生离线告警
+		if device.LastSeenAt == nil || device.Ephemeral {
 			continue
 		}
 
@@ -0,0 +1,212 @@
+package tasks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/edgelink/backend/internal/config"
+	"github.com/edgelink/backend/internal/domain"
+	"github.com/edgelink/backend/internal/metrics"
+	"github.com/edgelink/backend/internal/repository"
+	"github.com/edgelink/backend/internal/tracing"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+)
+
+const (
+	// eventsChannel API网关订阅的事件频道，消息经WebSocket转发给客户端
+	eventsChannel = "edgelink:events"
+
+	// deviceRemovedEvent 设备被移出网络的事件类型（与websocket.MessageTypeDeviceRemoved一致）
+	deviceRemovedEvent = "device_removed"
+
+	// ephemeralRevokeReason 临时设备离线超时撤销的原因
+	ephemeralRevokeReason = "ephemeral_offline"
+
+	// ephemeralCleanupBatchSize 每次执行最多撤销的设备数，剩余的留到下次执行
+	ephemeralCleanupBatchSize = 500
+)
+
+// deviceEventMessage 发布到事件频道的消息（与websocket.BroadcastMessage一致）
+type deviceEventMessage struct {
+	EventType    string            `json:"event_type"`
+	DeviceID     *string           `json:"device_id,omitempty"`
+	OrgID        *string           `json:"org_id,omitempty"`
+	Data         json.RawMessage   `json:"data"`
+	TraceContext map[string]string `json:"trace_context,omitempty"`
+}
+
+// deviceRemovedData device_removed事件的data部分，对端据此移除该设备的peer，API网关据此归还虚拟IP
+type deviceRemovedData struct {
+	DeviceID         uuid.UUID `json:"device_id"`
+	VirtualNetworkID uuid.UUID `json:"virtual_network_id"`
+	VirtualIP        string    `json:"virtual_ip"`
+	PublicKey        string    `json:"public_key"`
+	Reason           string    `json:"reason"`
+}
+
+// EphemeralCleanupTask 临时设备清理任务
+// 临时设备离线超过EPHEMERAL_DEVICE_OFFLINE_TTL后被删除：会话、密钥和对端配置级联删除，
+// 并广播device_removed，同网络的设备据此移除该peer，API网关据此将虚拟IP归还地址池
+type EphemeralCleanupTask struct {
+	offlineTTL   time.Duration
+	deviceRepo   repository.DeviceRepository
+	auditLogRepo repository.AuditLogRepository
+	redisClient  *redis.Client
+	metrics      *metrics.Metrics
+	logger       *zap.Logger
+}
+
+// NewEphemeralCleanupTask 创建临时设备清理任务
+func NewEphemeralCleanupTask(
+	cfg *config.Config,
+	deviceRepo repository.DeviceRepository,
+	auditLogRepo repository.AuditLogRepository,
+	redisClient *redis.Client,
+	m *metrics.Metrics,
+	logger *zap.Logger,
+) *EphemeralCleanupTask {
+	return &EphemeralCleanupTask{
+		offlineTTL:   cfg.Worker.EphemeralOfflineTTL,
+		deviceRepo:   deviceRepo,
+		auditLogRepo: auditLogRepo,
+		redisClient:  redisClient,
+		metrics:      m,
+		logger:       logger,
+	}
+}
+
+// Run 撤销离线超时的临时设备
+func (t *EphemeralCleanupTask) Run(ctx context.Context) error {
+	if t.offlineTTL <= 0 {
+		return nil
+	}
+
+	before := time.Now().Add(-t.offlineTTL)
+	devices, err := t.deviceRepo.FindStaleEphemeral(ctx, before, ephemeralCleanupBatchSize)
+	if err != nil {
+		return fmt.Errorf("failed to find stale ephemeral devices: %w", err)
+	}
+
+	revoked := 0
+	for i := range devices {
+		device := &devices[i]
+
+		// 条件删除：查询之后重新上报的设备不会被撤销
+		deleted, err := t.deviceRepo.DeleteStaleEphemeral(ctx, device.ID, before)
+		if err != nil {
+			t.logger.Error("Failed to revoke ephemeral device",
+				zap.Error(err),
+				zap.String("device_id", device.ID.String()),
+			)
+			continue
+		}
+		if !deleted {
+			continue
+		}
+
+		revoked++
+		t.metrics.RecordEphemeralRevocation()
+		t.recordAudit(ctx, device)
+		t.publishRemoved(ctx, device)
+
+		t.logger.Info("Ephemeral device revoked",
+			zap.String("device_id", device.ID.String()),
+			zap.String("device_name", device.Name),
+			zap.String("virtual_ip", device.VirtualIP),
+		)
+	}
+
+	if len(devices) > 0 {
+		t.logger.Info("Ephemeral device cleanup completed",
+			zap.Int("candidates", len(devices)),
+			zap.Int("revoked", revoked),
+			zap.Duration("offline_ttl", t.offlineTTL),
+		)
+	}
+
+	return nil
+}
+
+// recordAudit 写入撤销审计记录（系统操作，没有操作人）
+func (t *EphemeralCleanupTask) recordAudit(ctx context.Context, device *domain.Device) {
+	if device.VirtualNetwork == nil {
+		return
+	}
+
+	before := domain.JSONB{
+		"name":               device.Name,
+		"virtual_network_id": device.VirtualNetworkID.String(),
+		"virtual_ip":         device.VirtualIP,
+		"ephemeral":          true,
+	}
+	if device.LastSeenAt != nil {
+		before["last_seen_at"] = device.LastSeenAt.Format(time.RFC3339)
+	}
+	after := domain.JSONB{
+		"reason":      ephemeralRevokeReason,
+		"offline_ttl": t.offlineTTL.String(),
+	}
+
+	auditLog := &domain.AuditLog{
+		ID:             uuid.New(),
+		OrganizationID: device.VirtualNetwork.OrganizationID,
+		Action:         "revoke",
+		ResourceType:   domain.ResourceTypeDevice,
+		ResourceID:     device.ID,
+		BeforeState:    &before,
+		AfterState:     &after,
+		CreatedAt:      time.Now(),
+	}
+	if err := t.auditLogRepo.Create(ctx, auditLog); err != nil {
+		t.logger.Warn("Failed to record ephemeral device revocation",
+			zap.Error(err),
+			zap.String("device_id", device.ID.String()),
+		)
+	}
+}
+
+// publishRemoved 广播device_removed事件，通知同网络的设备移除该peer、API网关归还虚拟IP
+func (t *EphemeralCleanupTask) publishRemoved(ctx context.Context, device *domain.Device) {
+	ctx, span := tracing.StartPublish(ctx, eventsChannel)
+	span.SetAttributes(attribute.String("event_type", deviceRemovedEvent))
+	defer span.End()
+
+	data, err := json.Marshal(deviceRemovedData{
+		DeviceID:         device.ID,
+		VirtualNetworkID: device.VirtualNetworkID,
+		VirtualIP:        device.VirtualIP,
+		PublicKey:        device.PublicKey,
+		Reason:           ephemeralRevokeReason,
+	})
+	if err != nil {
+		return
+	}
+
+	deviceID := device.ID.String()
+	msg := deviceEventMessage{
+		EventType:    deviceRemovedEvent,
+		DeviceID:     &deviceID,
+		Data:         data,
+		TraceContext: tracing.Inject(ctx),
+	}
+	if device.VirtualNetwork != nil {
+		orgID := device.VirtualNetwork.OrganizationID.String()
+		msg.OrgID = &orgID
+	}
+
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return
+	}
+	if err := t.redisClient.Publish(ctx, eventsChannel, payload).Err(); err != nil {
+		t.logger.Warn("Failed to publish device removed event",
+			zap.Error(err),
+			zap.String("device_id", deviceID),
+		)
+	}
+}
@@ -0,0 +1,386 @@
+package tasks
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/edgelink/backend/internal/config"
+	"github.com/edgelink/backend/internal/domain"
+	"github.com/edgelink/backend/internal/metrics"
+	"github.com/edgelink/backend/internal/repository"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// organizationPageSize 分页读取组织列表的页大小
+const organizationPageSize = 500
+
+// RetentionTask 数据保留清理任务
+// 按组织策略 > 全局策略 > 配置默认值确定每个数据类别的保留期，分批删除或归档过期记录，
+// 每次执行写入retention_runs作为清理报告。审计日志只有显式配置策略时才清理
+type RetentionTask struct {
+	cfg              config.RetentionConfig
+	organizationRepo repository.OrganizationRepository
+	policyRepo       repository.RetentionPolicyRepository
+	runRepo          repository.RetentionRunRepository
+	retentionRepo    repository.RetentionRepository
+	metrics          *metrics.Metrics
+	logger           *zap.Logger
+}
+
+// NewRetentionTask 创建数据保留清理任务
+func NewRetentionTask(
+	cfg *config.Config,
+	organizationRepo repository.OrganizationRepository,
+	policyRepo repository.RetentionPolicyRepository,
+	runRepo repository.RetentionRunRepository,
+	retentionRepo repository.RetentionRepository,
+	m *metrics.Metrics,
+	logger *zap.Logger,
+) *RetentionTask {
+	return &RetentionTask{
+		cfg:              cfg.Retention,
+		organizationRepo: organizationRepo,
+		policyRepo:       policyRepo,
+		runRepo:          runRepo,
+		retentionRepo:    retentionRepo,
+		metrics:          m,
+		logger:           logger,
+	}
+}
+
+// retentionRule 生效的保留规则
+type retentionRule struct {
+	policyID *uuid.UUID // 为空表示配置默认值
+	days     int
+	action   domain.RetentionAction
+}
+
+// Run 执行一次数据清理
+func (t *RetentionTask) Run(ctx context.Context) error {
+	t.logger.Info("Running retention task", zap.Bool("dry_run", t.cfg.DryRun))
+
+	run := &domain.RetentionRun{
+		Status:    domain.RetentionRunStatusRunning,
+		DryRun:    t.cfg.DryRun,
+		Report:    domain.RetentionReport{},
+		StartedAt: time.Now(),
+	}
+	if err := t.runRepo.Create(ctx, run); err != nil {
+		return fmt.Errorf("failed to create retention run: %w", err)
+	}
+
+	policies, err := t.policyRepo.List(ctx, nil)
+	if err != nil {
+		return t.finish(ctx, run, fmt.Errorf("failed to load retention policies: %w", err))
+	}
+	global := make(map[domain.DataClass]*domain.RetentionPolicy)
+	perOrg := make(map[uuid.UUID]map[domain.DataClass]*domain.RetentionPolicy)
+	for _, policy := range policies {
+		if policy.OrganizationID == nil {
+			global[policy.DataClass] = policy
+			continue
+		}
+		if perOrg[*policy.OrganizationID] == nil {
+			perOrg[*policy.OrganizationID] = make(map[domain.DataClass]*domain.RetentionPolicy)
+		}
+		perOrg[*policy.OrganizationID][policy.DataClass] = policy
+	}
+
+	orgIDs, err := t.organizationIDs(ctx)
+	if err != nil {
+		return t.finish(ctx, run, fmt.Errorf("failed to list organizations: %w", err))
+	}
+
+	for _, class := range domain.DataClasses {
+		if class.OrganizationScoped() {
+			for _, orgID := range orgIDs {
+				orgID := orgID
+				if rule := t.resolve(class, global, perOrg[orgID]); rule != nil {
+					run.Report = append(run.Report, t.purge(ctx, run.ID, class, &orgID, rule))
+				}
+			}
+		}
+		if class.HasUnscopedRecords() {
+			if rule := t.resolve(class, global, nil); rule != nil {
+				run.Report = append(run.Report, t.purge(ctx, run.ID, class, nil, rule))
+			}
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+
+	failed := 0
+	for _, item := range run.Report {
+		run.TotalPurged += item.Purged
+		if item.Error != "" {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return t.finish(ctx, run, fmt.Errorf("retention failed for %d of %d items, see retention run %s", failed, len(run.Report), run.ID))
+	}
+	return t.finish(ctx, run, ctx.Err())
+}
+
+// resolve 确定数据类别的保留规则，orgPolicies为空表示不属于任何组织的记录；返回nil表示不清理
+func (t *RetentionTask) resolve(
+	class domain.DataClass,
+	global map[domain.DataClass]*domain.RetentionPolicy,
+	orgPolicies map[domain.DataClass]*domain.RetentionPolicy,
+) *retentionRule {
+	policy := orgPolicies[class]
+	if policy == nil {
+		policy = global[class]
+	}
+	if policy != nil {
+		return &retentionRule{policyID: &policy.ID, days: policy.RetentionDays, action: policy.Action}
+	}
+
+	// 审计日志没有默认保留期，必须显式配置策略
+	if class == domain.DataClassAuditLogs {
+		return nil
+	}
+	days := t.cfg.DefaultDays[string(class)]
+	if days <= 0 {
+		return nil
+	}
+	return &retentionRule{days: days, action: domain.RetentionActionDelete}
+}
+
+// purge 分批清理一个数据类别（及组织）的过期记录
+// 每批在独立的短事务中删除，批次之间暂停，避免长时间持有锁
+func (t *RetentionTask) purge(
+	ctx context.Context,
+	runID uuid.UUID,
+	class domain.DataClass,
+	orgID *uuid.UUID,
+	rule *retentionRule,
+) domain.RetentionReportItem {
+	item := domain.RetentionReportItem{
+		DataClass:      class,
+		OrganizationID: orgID,
+		PolicyID:       rule.policyID,
+		Action:         rule.action,
+		RetentionDays:  rule.days,
+		Cutoff:         time.Now().AddDate(0, 0, -rule.days),
+	}
+
+	if t.cfg.DryRun {
+		count, err := t.retentionRepo.CountExpired(ctx, class, orgID, item.Cutoff)
+		if err != nil {
+			item.Error = err.Error()
+		}
+		item.Purged = count
+		return item
+	}
+
+	if rule.action == domain.RetentionActionArchive && t.cfg.ArchiveDir == "" {
+		item.Error = "archive action requires RETENTION_ARCHIVE_DIR"
+		return item
+	}
+
+	if err := t.purgeBatches(ctx, runID, class, orgID, rule, &item); err != nil {
+		item.Error = err.Error()
+	}
+
+	fields := []zap.Field{
+		zap.String("data_class", string(class)),
+		zap.String("action", string(rule.action)),
+		zap.Int("retention_days", rule.days),
+		zap.Int64("purged", item.Purged),
+	}
+	if orgID != nil {
+		fields = append(fields, zap.String("organization_id", orgID.String()))
+	}
+	if item.Error != "" {
+		t.logger.Error("Retention purge failed", append(fields, zap.String("error", item.Error))...)
+	} else if item.Purged > 0 {
+		t.logger.Info("Retention purge completed", fields...)
+	}
+	return item
+}
+
+// purgeBatches 循环查找、归档并删除一批过期记录，直到没有过期记录
+func (t *RetentionTask) purgeBatches(
+	ctx context.Context,
+	runID uuid.UUID,
+	class domain.DataClass,
+	orgID *uuid.UUID,
+	rule *retentionRule,
+	item *domain.RetentionReportItem,
+) (err error) {
+	var archive *retentionArchive
+	defer func() {
+		if archive == nil {
+			return
+		}
+		if closeErr := archive.Close(); closeErr != nil && err == nil {
+			err = fmt.Errorf("failed to close archive: %w", closeErr)
+		}
+	}()
+
+	for {
+		records, err := t.retentionRepo.FindExpired(ctx, class, orgID, item.Cutoff, t.cfg.BatchSize)
+		if err != nil {
+			return err
+		}
+		if len(records) == 0 {
+			return nil
+		}
+
+		ids := make([]uuid.UUID, len(records))
+		for i, record := range records {
+			ids[i] = record.ID
+		}
+
+		// 归档写入并落盘后才删除，删除失败时归档中可能有重复记录，但不会丢失
+		if rule.action == domain.RetentionActionArchive {
+			if archive == nil {
+				if archive, err = openRetentionArchive(t.cfg.ArchiveDir, runID, class, orgID); err != nil {
+					return err
+				}
+				item.ArchiveFile = archive.path
+			}
+			rows, err := t.retentionRepo.ExportRecords(ctx, class, ids)
+			if err == nil {
+				err = archive.Write(rows)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to archive records: %w", err)
+			}
+		}
+
+		deleted, err := t.retentionRepo.DeleteRecords(ctx, class, ids)
+		if err != nil {
+			return err
+		}
+		item.Purged += deleted
+		t.metrics.RecordRetentionPurge(string(class), string(rule.action), deleted)
+
+		for _, record := range records {
+			if record.Sequence == nil {
+				continue
+			}
+			if item.FirstSequence == nil || *record.Sequence < *item.FirstSequence {
+				item.FirstSequence = record.Sequence
+			}
+			if item.LastSequence == nil || *record.Sequence > *item.LastSequence {
+				item.LastSequence = record.Sequence
+			}
+		}
+
+		// 最后一批，或记录已被并发删除时结束，避免空转
+		if len(records) < t.cfg.BatchSize || deleted == 0 {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(t.cfg.BatchPause):
+		}
+	}
+}
+
+// finish 写入执行结果，ctx已取消时仍需保存报告
+func (t *RetentionTask) finish(ctx context.Context, run *domain.RetentionRun, runErr error) error {
+	now := time.Now()
+	run.FinishedAt = &now
+	run.Status = domain.RetentionRunStatusSuccess
+	if runErr != nil {
+		run.Status = domain.RetentionRunStatusFailure
+	}
+
+	saveCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+	defer cancel()
+	if err := t.runRepo.Update(saveCtx, run); err != nil {
+		t.logger.Warn("Failed to save retention run report", zap.String("run_id", run.ID.String()), zap.Error(err))
+	}
+
+	t.logger.Info("Retention task completed",
+		zap.String("run_id", run.ID.String()),
+		zap.String("status", string(run.Status)),
+		zap.Int64("total_purged", run.TotalPurged),
+	)
+	return runErr
+}
+
+// organizationIDs 分页读取所有组织ID
+func (t *RetentionTask) organizationIDs(ctx context.Context) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	for offset := 0; ; offset += organizationPageSize {
+		orgs, total, err := t.organizationRepo.List(ctx, organizationPageSize, offset)
+		if err != nil {
+			return nil, err
+		}
+		for _, org := range orgs {
+			ids = append(ids, org.ID)
+		}
+		if len(orgs) < organizationPageSize || int64(len(ids)) >= total {
+			return ids, nil
+		}
+	}
+}
+
+// retentionArchive gzip压缩的NDJSON归档文件
+type retentionArchive struct {
+	path string
+	file *os.File
+	gz   *gzip.Writer
+}
+
+// openRetentionArchive 创建归档文件：<dir>/<run_id>/<class>[-<organization_id>].ndjson.gz
+func openRetentionArchive(dir string, runID uuid.UUID, class domain.DataClass, orgID *uuid.UUID) (*retentionArchive, error) {
+	runDir := filepath.Join(dir, runID.String())
+	if err := os.MkdirAll(runDir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create archive directory: %w", err)
+	}
+
+	name := string(class)
+	if orgID != nil {
+		name += "-" + orgID.String()
+	}
+	path := filepath.Join(runDir, name+".ndjson.gz")
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o640)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open archive file: %w", err)
+	}
+	return &retentionArchive{path: path, file: file, gz: gzip.NewWriter(file)}, nil
+}
+
+// Write 写入一批记录并落盘
+func (a *retentionArchive) Write(rows []json.RawMessage) error {
+	for _, row := range rows {
+		if _, err := a.gz.Write(row); err != nil {
+			return err
+		}
+		if _, err := a.gz.Write([]byte{'\n'}); err != nil {
+			return err
+		}
+	}
+	if err := a.gz.Flush(); err != nil {
+		return err
+	}
+	return a.file.Sync()
+}
+
+// Close 结束压缩流并关闭文件
+func (a *retentionArchive) Close() error {
+	if err := a.gz.Close(); err != nil {
+		a.file.Close()
+		return err
+	}
+	if err := a.file.Sync(); err != nil {
+		a.file.Close()
+		return err
+	}
+	return a.file.Close()
+}
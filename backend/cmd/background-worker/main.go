@@ -7,13 +7,18 @@ import (
 	"os/signal"
 	"syscall"
 
+	"github.com/edgelink/backend/cmd/background-worker/internal/scheduler"
 	"github.com/edgelink/backend/cmd/background-worker/internal/tasks"
+	"github.com/edgelink/backend/internal/audit"
+	"github.com/edgelink/backend/internal/audit/export"
 	"github.com/edgelink/backend/internal/config"
 	"github.com/edgelink/backend/internal/database"
+	"github.com/edgelink/backend/internal/domain"
 	"github.com/edgelink/backend/internal/logger"
+	"github.com/edgelink/backend/internal/metrics"
 	"github.com/edgelink/backend/internal/repository"
+	"github.com/edgelink/backend/internal/tracing"
 	"github.com/redis/go-redis/v9"
-	"github.com/robfig/cron/v3"
 	"go.uber.org/fx"
 	"go.uber.org/zap"
 )
@@ -24,6 +29,7 @@ func main() {
 		fx.Provide(
 			config.LoadConfig,
 			logger.NewLogger,
+			metrics.New,
 		),
 
 		// 数据库模块
@@ -38,6 +44,14 @@ func main() {
 			repository.NewAlertRepository,
 			repository.NewSessionRepository,
 			repository.NewDeviceKeyRepository,
+			repository.NewAuditLogRepository,
+			repository.NewAuditCheckpointRepository,
+			repository.NewAuditExportCursorRepository,
+			repository.NewTaskRunRepository,
+			repository.NewOrganizationRepository,
+			repository.NewRetentionPolicyRepository,
+			repository.NewRetentionRunRepository,
+			repository.NewRetentionRepository,
 		),
 
 		// 后台任务
@@ -46,10 +60,26 @@ func main() {
 			tasks.NewPerformanceMonitorTask,
 			tasks.NewSecurityMonitorTask,
 			tasks.NewKeyExpiryTask,
+			tasks.NewRetentionTask,
+			tasks.NewEphemeralCleanupTask,
+			audit.NewCheckpointer,
+			export.NewExporter,
 		),
 
+		// 调度与leader选举
+		fx.Provide(
+			scheduler.NewScheduler,
+			scheduler.NewElector,
+		),
+
+		// 分布式追踪（最先注册，停止时最后刷新span）
+		fx.Invoke(tracing.Setup("background-worker")),
+
 		// 启动后台工作器
 		fx.Invoke(runBackgroundWorker),
+
+		// 指标服务器
+		fx.Invoke(metrics.RunServer),
 	)
 
 	app.Run()
@@ -65,64 +95,108 @@ func NewRedisClient(cfg *config.Config) *redis.Client {
 }
 
 // runBackgroundWorker 运行后台工作器
+// 多副本部署时只有持有leader租约的实例执行定时任务、手动触发的任务和审计日志导出
 func runBackgroundWorker(
 	lifecycle fx.Lifecycle,
 	log *zap.Logger,
 	cfg *config.Config,
+	m *metrics.Metrics,
+	sched *scheduler.Scheduler,
+	elector *scheduler.Elector,
 	deviceHealthTask *tasks.DeviceHealthTask,
 	performanceMonitorTask *tasks.PerformanceMonitorTask,
 	securityMonitorTask *tasks.SecurityMonitorTask,
 	keyExpiryTask *tasks.KeyExpiryTask,
-) {
+	retentionTask *tasks.RetentionTask,
+	ephemeralCleanupTask *tasks.EphemeralCleanupTask,
+	auditCheckpointer *audit.Checkpointer,
+	auditExporter *export.Exporter,
+) error {
 	ctx, cancel := context.WithCancel(context.Background())
-
-	// 创建cron调度器
-	c := cron.New()
+	done := make(chan struct{})
+
+	// 注册任务及默认调度，可通过WORKER_SCHEDULE_<TASK>覆盖
+	auditCheckpoint := auditCheckpointer.Run
+	auditCheckpointSchedule := "@every " + cfg.Audit.CheckpointInterval.String()
+	if !auditCheckpointer.Enabled() {
+		log.Warn("Audit signing key not configured, audit checkpoints disabled")
+		auditCheckpointSchedule = "off"
+		auditCheckpoint = func(context.Context) error {
+			return fmt.Errorf("audit signing key not configured")
+		}
+	}
+
+	registrations := []struct {
+		name     string
+		schedule string
+		run      func(context.Context) error
+	}{
+		{domain.TaskDeviceHealth, "@every 1m", deviceHealthTask.Run},             // 设备健康检查 - 每分钟
+		{domain.TaskPerformanceMonitor, "@every 5m", performanceMonitorTask.Run}, // 性能监控 - 每5分钟
+		{domain.TaskSecurityMonitor, "@every 1m", securityMonitorTask.Run},       // 安全监控 - 每分钟
+		{domain.TaskKeyExpiry, "0 2 * * *", keyExpiryTask.Run},                   // 密钥过期检查 - 每天凌晨2点
+		{domain.TaskAuditCheckpoint, auditCheckpointSchedule, auditCheckpoint},   // 审计日志签名检查点 - 默认每小时
+		{domain.TaskRetention, "0 3 * * *", retentionTask.Run},                   // 数据保留清理 - 每天凌晨3点
+		{domain.TaskEphemeralCleanup, "@every 1m", ephemeralCleanupTask.Run},     // 临时设备离线撤销 - 每分钟
+	}
+	for _, r := range registrations {
+		if err := sched.Register(r.name, r.schedule, r.run); err != nil {
+			cancel()
+			return err
+		}
+	}
+	if err := sched.Validate(); err != nil {
+		cancel()
+		return err
+	}
+
+	// lead 成为leader后执行，ctx取消（失去leader身份或停止）时返回
+	lead := func(ctx context.Context) {
+		exportDone := make(chan struct{})
+		go func() {
+			defer close(exportDone)
+			// 审计日志SIEM导出 - 持续轮询，不经过cron调度
+			if auditExporter.Enabled() {
+				auditExporter.Start(ctx)
+			}
+		}()
+
+		sched.Run(ctx)
+		<-exportDone
+	}
 
 	lifecycle.Append(fx.Hook{
 		OnStart: func(context.Context) error {
-			log.Info("Starting Background Worker")
-
-			// 调度任务
-			// 设备健康检查 - 每分钟
-			c.AddFunc("@every 1m", func() {
-				if err := deviceHealthTask.Run(ctx); err != nil {
-					log.Error("Device health task failed", zap.Error(err))
+			log.Info("Starting Background Worker",
+				zap.String("instance", cfg.Worker.InstanceID),
+				zap.Bool("leader_election", cfg.Worker.LeaderElection),
+			)
+
+			go func() {
+				defer close(done)
+				if cfg.Worker.LeaderElection {
+					elector.Run(ctx, lead)
+					return
 				}
-			})
+				// 未开启选举时本实例始终执行任务，只能部署一个副本
+				m.SetWorkerLeader(true)
+				lead(ctx)
+			}()
 
-			// 性能监控 - 每5分钟
-			c.AddFunc("@every 5m", func() {
-				if err := performanceMonitorTask.Run(ctx); err != nil {
-					log.Error("Performance monitor task failed", zap.Error(err))
-				}
-			})
-
-			// 安全监控 - 每分钟
-			c.AddFunc("@every 1m", func() {
-				if err := securityMonitorTask.Run(ctx); err != nil {
-					log.Error("Security monitor task failed", zap.Error(err))
-				}
-			})
-
-			// 密钥过期检查 - 每天凌晨2点
-			c.AddFunc("0 2 * * *", func() {
-				if err := keyExpiryTask.Run(ctx); err != nil {
-					log.Error("Key expiry task failed", zap.Error(err))
-				}
-			})
-
-			// 启动调度器
-			c.Start()
-
-			log.Info("Background worker started with scheduled tasks")
+			log.Info("Background worker started")
 
 			return nil
 		},
-		OnStop: func(context.Context) error {
+		OnStop: func(stopCtx context.Context) error {
 			log.Info("Shutting down Background Worker")
 			cancel()
-			c.Stop()
+
+			// 等待执行中的任务退出并释放leader租约
+			select {
+			case <-done:
+			case <-stopCtx.Done():
+				log.Warn("Timed out waiting for background tasks to stop")
+			}
 			return nil
 		},
 	})
@@ -136,4 +210,6 @@ func runBackgroundWorker(
 		log.Info("Received shutdown signal")
 		cancel()
 	}()
+
+	return nil
 }
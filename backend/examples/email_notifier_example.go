@@ -26,7 +26,7 @@ func main() {
 	defer logger.Sync()
 
 	// 3. 创建邮件通知器
-	emailNotifier, err := notifier.NewEmailNotifier(cfg, logger)
+	emailNotifier, err := notifier.NewEmailNotifier(cfg, nil, logger)
 	if err != nil {
 		logger.Fatal("Failed to create email notifier", zap.Error(err))
 	}
@@ -82,7 +82,7 @@ func main() {
 func exampleBatchSend() {
 	cfg, _ := config.Load()
 	logger, _ := zap.NewProduction()
-	emailNotifier, _ := notifier.NewEmailNotifier(cfg, logger)
+	emailNotifier, _ := notifier.NewEmailNotifier(cfg, nil, logger)
 	defer emailNotifier.Stop()
 
 	ctx := context.Background()
@@ -175,7 +175,7 @@ func exampleProviderSwitch() {
 	}
 
 	cfg := &config.Config{Email: *emailCfg}
-	emailNotifier, _ := notifier.NewEmailNotifier(cfg, logger)
+	emailNotifier, _ := notifier.NewEmailNotifier(cfg, nil, logger)
 	defer emailNotifier.Stop()
 
 	logger.Info("Email notifier initialized with provider",
@@ -0,0 +1,419 @@
+package audit
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/edgelink/backend/internal/config"
+	"github.com/edgelink/backend/internal/crypto"
+	"github.com/edgelink/backend/internal/domain"
+	"github.com/edgelink/backend/internal/repository"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// chainBatchSize 校验时每批读取的记录数
+const chainBatchSize = 1000
+
+// 哈希链断裂原因
+const (
+	BreakSequenceGap         = "sequence_gap"         // 序号不连续，中间的记录被删除
+	BreakPrevHashMismatch    = "prev_hash_mismatch"   // 记录的prev_hash与上一条记录的哈希不一致
+	BreakHashMismatch        = "hash_mismatch"        // 记录内容被修改，重算的哈希与保存的不一致
+	BreakCheckpointMismatch  = "checkpoint_mismatch"  // 记录哈希与已签名检查点不一致（整条链被重算）
+	BreakCheckpointSignature = "checkpoint_signature" // 检查点签名无效
+	BreakCheckpointKey       = "checkpoint_untrusted" // 检查点由不受信任的公钥签名
+	BreakTruncated           = "truncated"            // 检查点之后的记录被删除
+)
+
+// BrokenLink 哈希链中第一个校验失败的位置
+type BrokenLink struct {
+	Sequence   int64      `json:"sequence"`
+	AuditLogID *uuid.UUID `json:"audit_log_id,omitempty"`
+	Reason     string     `json:"reason"`
+	Expected   string     `json:"expected,omitempty"`
+	Actual     string     `json:"actual,omitempty"`
+}
+
+// VerificationResult 组织哈希链的校验结果
+type VerificationResult struct {
+	OrganizationID      uuid.UUID   `json:"organization_id"`
+	Valid               bool        `json:"valid"`
+	RecordsVerified     int64       `json:"records_verified"`
+	FirstSequence       int64       `json:"first_sequence,omitempty"` // 大于1说明链首已按保留策略清理
+	LastSequence        int64       `json:"last_sequence,omitempty"`
+	LastHash            string      `json:"last_hash,omitempty"`
+	CheckpointsVerified int         `json:"checkpoints_verified"`
+	SignaturesVerified  bool        `json:"signatures_verified"` // 未配置公钥时只比对检查点哈希，不校验签名
+	UnchainedRecords    int64       `json:"unchained_records"`   // 启用哈希链之前的记录，不在校验范围内
+	BrokenLink          *BrokenLink `json:"broken_link,omitempty"`
+	VerifiedAt          time.Time   `json:"verified_at"`
+}
+
+// chainCursor 链上最后一条已校验的记录
+type chainCursor struct {
+	sequence int64  // 0表示尚未读取任何记录
+	hash     string // 下一条记录应携带的prev_hash
+}
+
+// checkLink 校验记录与上一条记录的链接以及记录自身的哈希，通过后推进游标
+func checkLink(cursor *chainCursor, log *domain.AuditLog) *BrokenLink {
+	seq := *log.Sequence
+	switch {
+	case cursor.sequence == 0 && seq == 1:
+		cursor.hash = domain.AuditGenesisHash
+	case cursor.sequence == 0:
+		// 链首已被清理，以第一条保留记录的prev_hash为起点
+		cursor.hash = stringValue(log.PrevHash)
+	case seq != cursor.sequence+1:
+		return &BrokenLink{
+			Sequence: cursor.sequence + 1,
+			Reason:   BreakSequenceGap,
+			Expected: strconv.FormatInt(cursor.sequence+1, 10),
+			Actual:   strconv.FormatInt(seq, 10),
+		}
+	}
+
+	id := log.ID
+	if stringValue(log.PrevHash) != cursor.hash {
+		return &BrokenLink{
+			Sequence:   seq,
+			AuditLogID: &id,
+			Reason:     BreakPrevHashMismatch,
+			Expected:   cursor.hash,
+			Actual:     stringValue(log.PrevHash),
+		}
+	}
+
+	computed, err := log.ComputeHash()
+	if err != nil || computed != stringValue(log.Hash) {
+		return &BrokenLink{
+			Sequence:   seq,
+			AuditLogID: &id,
+			Reason:     BreakHashMismatch,
+			Expected:   computed,
+			Actual:     stringValue(log.Hash),
+		}
+	}
+
+	cursor.sequence, cursor.hash = seq, computed
+	return nil
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// ChainVerifier 审计哈希链校验器
+type ChainVerifier struct {
+	auditLogRepo   repository.AuditLogRepository
+	checkpointRepo repository.AuditCheckpointRepository
+	trustedKeys    map[string]ed25519.PublicKey // Base64公钥 -> 公钥
+	logger         *zap.Logger
+}
+
+// NewChainVerifier 创建哈希链校验器，信任当前签名公钥和配置的历史公钥
+func NewChainVerifier(
+	auditLogRepo repository.AuditLogRepository,
+	checkpointRepo repository.AuditCheckpointRepository,
+	cfg *config.Config,
+	logger *zap.Logger,
+) (*ChainVerifier, error) {
+	trustedKeys := make(map[string]ed25519.PublicKey)
+	encodedKeys := append([]string{}, cfg.Audit.TrustedPublicKeys...)
+	if cfg.Audit.SigningPublicKey != "" {
+		encodedKeys = append(encodedKeys, cfg.Audit.SigningPublicKey)
+	}
+	for _, encoded := range encodedKeys {
+		key, err := crypto.PublicKeyFromBase64(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("invalid audit public key: %w", err)
+		}
+		trustedKeys[base64.StdEncoding.EncodeToString(key)] = key
+	}
+
+	return &ChainVerifier{
+		auditLogRepo:   auditLogRepo,
+		checkpointRepo: checkpointRepo,
+		trustedKeys:    trustedKeys,
+		logger:         logger,
+	}, nil
+}
+
+// VerifyAll 校验所有组织的哈希链
+func (v *ChainVerifier) VerifyAll(ctx context.Context) ([]*VerificationResult, error) {
+	chained, err := v.auditLogRepo.ListChainedOrganizations(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list organizations: %w", err)
+	}
+	// 记录被全部删除但留有检查点的组织同样需要校验
+	checkpointed, err := v.checkpointRepo.ListOrganizations(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list organizations: %w", err)
+	}
+
+	seen := make(map[uuid.UUID]bool)
+	results := make([]*VerificationResult, 0, len(chained))
+	for _, orgID := range append(chained, checkpointed...) {
+		if seen[orgID] {
+			continue
+		}
+		seen[orgID] = true
+
+		result, err := v.Verify(ctx, orgID)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// Verify 从链首逐条校验组织的哈希链和检查点，遇到第一个断裂处即停止
+func (v *ChainVerifier) Verify(ctx context.Context, organizationID uuid.UUID) (*VerificationResult, error) {
+	result := &VerificationResult{
+		OrganizationID:     organizationID,
+		SignaturesVerified: len(v.trustedKeys) > 0,
+	}
+
+	unchained, err := v.auditLogRepo.CountUnchained(ctx, organizationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count unchained audit logs: %w", err)
+	}
+	result.UnchainedRecords = unchained
+
+	checkpoints, err := v.checkpointRepo.FindByOrganization(ctx, organizationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load audit checkpoints: %w", err)
+	}
+
+	result.BrokenLink, err = v.walk(ctx, organizationID, checkpoints, result)
+	if err != nil {
+		return nil, err
+	}
+	result.Valid = result.BrokenLink == nil
+	result.VerifiedAt = time.Now()
+
+	if !result.Valid {
+		v.logger.Warn("Audit chain verification failed",
+			zap.String("organization_id", organizationID.String()),
+			zap.Int64("sequence", result.BrokenLink.Sequence),
+			zap.String("reason", result.BrokenLink.Reason),
+		)
+	}
+
+	return result, nil
+}
+
+// walk 逐批读取记录校验链接，并在经过检查点时比对哈希和签名
+func (v *ChainVerifier) walk(ctx context.Context, organizationID uuid.UUID, checkpoints []*domain.AuditCheckpoint, result *VerificationResult) (*BrokenLink, error) {
+	cursor := &chainCursor{}
+	next := 0
+
+	for {
+		logs, err := v.auditLogRepo.FindChain(ctx, organizationID, cursor.sequence, chainBatchSize)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load audit chain: %w", err)
+		}
+
+		for _, log := range logs {
+			if brk := checkLink(cursor, log); brk != nil {
+				return brk, nil
+			}
+			if result.FirstSequence == 0 {
+				result.FirstSequence = cursor.sequence
+			}
+			result.RecordsVerified++
+			result.LastSequence, result.LastHash = cursor.sequence, cursor.hash
+
+			// 序号小于当前记录的检查点只可能落在已清理的链首，此时只能校验签名
+			for next < len(checkpoints) && checkpoints[next].Sequence <= cursor.sequence {
+				checkpoint := checkpoints[next]
+				if brk := v.checkSignature(checkpoint); brk != nil {
+					return brk, nil
+				}
+				if checkpoint.Sequence == cursor.sequence && checkpoint.Hash != cursor.hash {
+					id := log.ID
+					return &BrokenLink{
+						Sequence:   cursor.sequence,
+						AuditLogID: &id,
+						Reason:     BreakCheckpointMismatch,
+						Expected:   checkpoint.Hash,
+						Actual:     cursor.hash,
+					}, nil
+				}
+				result.CheckpointsVerified++
+				next++
+			}
+		}
+
+		if len(logs) < chainBatchSize {
+			break
+		}
+	}
+
+	// 链尾之后仍有检查点，说明已签名的记录被删除
+	if next < len(checkpoints) {
+		checkpoint := checkpoints[next]
+		if brk := v.checkSignature(checkpoint); brk != nil {
+			return brk, nil
+		}
+		return &BrokenLink{
+			Sequence: cursor.sequence + 1,
+			Reason:   BreakTruncated,
+			Expected: strconv.FormatInt(checkpoint.Sequence, 10),
+			Actual:   strconv.FormatInt(cursor.sequence, 10),
+		}, nil
+	}
+
+	return nil, nil
+}
+
+// checkSignature 校验检查点签名，未配置任何公钥时跳过
+func (v *ChainVerifier) checkSignature(checkpoint *domain.AuditCheckpoint) *BrokenLink {
+	if len(v.trustedKeys) == 0 {
+		return nil
+	}
+
+	key, ok := v.trustedKeys[checkpoint.PublicKey]
+	if !ok {
+		return &BrokenLink{
+			Sequence: checkpoint.Sequence,
+			Reason:   BreakCheckpointKey,
+			Actual:   checkpoint.PublicKey,
+		}
+	}
+
+	signature, err := base64.StdEncoding.DecodeString(checkpoint.Signature)
+	if err != nil || !ed25519.Verify(key, checkpoint.SigningPayload(), signature) {
+		return &BrokenLink{
+			Sequence: checkpoint.Sequence,
+			Reason:   BreakCheckpointSignature,
+		}
+	}
+	return nil
+}
+
+// Checkpointer 为各组织的哈希链头生成Ed25519签名检查点
+type Checkpointer struct {
+	auditLogRepo   repository.AuditLogRepository
+	checkpointRepo repository.AuditCheckpointRepository
+	keyPair        *crypto.KeyPair // 未配置签名密钥时为nil
+	logger         *zap.Logger
+}
+
+// NewCheckpointer 创建检查点生成器，签名密钥需同时配置公钥和私钥
+func NewCheckpointer(
+	auditLogRepo repository.AuditLogRepository,
+	checkpointRepo repository.AuditCheckpointRepository,
+	cfg *config.Config,
+	logger *zap.Logger,
+) (*Checkpointer, error) {
+	c := &Checkpointer{
+		auditLogRepo:   auditLogRepo,
+		checkpointRepo: checkpointRepo,
+		logger:         logger,
+	}
+
+	if cfg.Audit.SigningPrivateKey == "" {
+		return c, nil
+	}
+	keyPair, err := crypto.ParseKeyPairBase64(cfg.Audit.SigningPublicKey, cfg.Audit.SigningPrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid audit signing key: %w", err)
+	}
+	c.keyPair = keyPair
+	return c, nil
+}
+
+// Enabled 是否配置了签名密钥
+func (c *Checkpointer) Enabled() bool {
+	return c.keyPair != nil
+}
+
+// Run 为自上个检查点以来有新记录的组织签名链头
+func (c *Checkpointer) Run(ctx context.Context) error {
+	if !c.Enabled() {
+		return nil
+	}
+
+	orgIDs, err := c.auditLogRepo.ListChainedOrganizations(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list organizations: %w", err)
+	}
+
+	var errs []error
+	for _, orgID := range orgIDs {
+		if err := c.checkpoint(ctx, orgID); err != nil {
+			c.logger.Error("Failed to create audit checkpoint",
+				zap.String("organization_id", orgID.String()),
+				zap.Error(err),
+			)
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// checkpoint 校验上个检查点之后的新记录，链接完整时签名当前链头
+// 链已断裂时拒绝签名，避免为被篡改的记录背书
+func (c *Checkpointer) checkpoint(ctx context.Context, organizationID uuid.UUID) error {
+	head, err := c.auditLogRepo.FindChainHead(ctx, organizationID)
+	if err != nil {
+		return fmt.Errorf("failed to load chain head: %w", err)
+	}
+
+	cursor := &chainCursor{}
+	latest, err := c.checkpointRepo.FindLatest(ctx, organizationID)
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+	case err != nil:
+		return fmt.Errorf("failed to load latest checkpoint: %w", err)
+	case latest.Sequence >= *head.Sequence:
+		return nil
+	default:
+		cursor.sequence, cursor.hash = latest.Sequence, latest.Hash
+	}
+
+	for cursor.sequence < *head.Sequence {
+		logs, err := c.auditLogRepo.FindChain(ctx, organizationID, cursor.sequence, chainBatchSize)
+		if err != nil {
+			return fmt.Errorf("failed to load audit chain: %w", err)
+		}
+		if len(logs) == 0 {
+			break
+		}
+		for _, log := range logs {
+			if brk := checkLink(cursor, log); brk != nil {
+				return fmt.Errorf("audit chain broken at sequence %d: %s", brk.Sequence, brk.Reason)
+			}
+		}
+	}
+
+	checkpoint := &domain.AuditCheckpoint{
+		OrganizationID: organizationID,
+		Sequence:       cursor.sequence,
+		Hash:           cursor.hash,
+		PublicKey:      base64.StdEncoding.EncodeToString(c.keyPair.PublicKey),
+	}
+	checkpoint.Signature = base64.StdEncoding.EncodeToString(c.keyPair.Sign(checkpoint.SigningPayload()))
+
+	if err := c.checkpointRepo.Create(ctx, checkpoint); err != nil {
+		return fmt.Errorf("failed to save checkpoint: %w", err)
+	}
+
+	c.logger.Info("Audit checkpoint created",
+		zap.String("organization_id", organizationID.String()),
+		zap.Int64("sequence", checkpoint.Sequence),
+	)
+	return nil
+}
@@ -0,0 +1,90 @@
+package export
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/edgelink/backend/internal/domain"
+)
+
+// cefHeaderEscaper CEF头部字段只需转义反斜杠和竖线
+var cefHeaderEscaper = strings.NewReplacer(`\`, `\\`, `|`, `\|`, "\r", " ", "\n", " ")
+
+// cefExtensionEscaper CEF扩展字段值需转义反斜杠、等号和换行
+var cefExtensionEscaper = strings.NewReplacer(`\`, `\\`, `=`, `\=`, "\r", `\r`, "\n", `\n`)
+
+// FormatCEF 将审计记录格式化为CEF（ArcSight Common Event Format）消息
+func FormatCEF(log *domain.AuditLog) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "CEF:0|EdgeLink|EdgeLink|1.0|%s|%s|%d|",
+		cefHeaderEscaper.Replace("audit:"+log.Action),
+		cefHeaderEscaper.Replace(string(log.ResourceType)+" "+log.Action),
+		cefSeverity(log.Action),
+	)
+
+	ext := []string{
+		"rt=" + strconv.FormatInt(log.CreatedAt.UnixMilli(), 10),
+		"externalId=" + log.ID.String(),
+		"act=" + cefExtensionEscaper.Replace(log.Action),
+		"cs1Label=organizationId",
+		"cs1=" + log.OrganizationID.String(),
+		"cs2Label=resourceType",
+		"cs2=" + cefExtensionEscaper.Replace(string(log.ResourceType)),
+		"cs3Label=resourceId",
+		"cs3=" + log.ResourceID.String(),
+	}
+	if log.ActorID != nil {
+		ext = append(ext, "suid="+log.ActorID.String())
+	}
+	if log.IPAddress != nil {
+		ext = append(ext, "src="+cefExtensionEscaper.Replace(*log.IPAddress))
+	}
+	if log.UserAgent != nil {
+		ext = append(ext, "requestClientApplication="+cefExtensionEscaper.Replace(*log.UserAgent))
+	}
+	if log.Sequence != nil {
+		ext = append(ext, "cn1Label=sequence", "cn1="+strconv.FormatInt(*log.Sequence, 10))
+	}
+	if log.Hash != nil {
+		ext = append(ext, "cs4Label=hash", "cs4="+*log.Hash)
+	}
+	if outcome := cefOutcome(log); outcome != "" {
+		ext = append(ext, "outcome="+outcome)
+	}
+
+	b.WriteString(strings.Join(ext, " "))
+	return b.String()
+}
+
+// cefSeverity 按操作类型映射CEF严重级别（0-10）
+func cefSeverity(action string) int {
+	switch {
+	case strings.Contains(action, "delete"):
+		return 7
+	case strings.Contains(action, "create"), strings.Contains(action, "update"):
+		return 5
+	default:
+		return 3
+	}
+}
+
+// cefOutcome 根据after_state中记录的HTTP状态码判断操作结果
+func cefOutcome(log *domain.AuditLog) string {
+	if log.AfterState == nil {
+		return ""
+	}
+	var status float64
+	switch v := (*log.AfterState)["http_status"].(type) {
+	case float64: // 从数据库读回
+		status = v
+	case int: // 写入前的内存对象
+		status = float64(v)
+	default:
+		return ""
+	}
+	if status >= 200 && status < 400 {
+		return "success"
+	}
+	return "failure"
+}
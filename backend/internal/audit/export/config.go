@@ -0,0 +1,135 @@
+package export
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// defaultBatchSize 每批导出的记录数，也是进程崩溃后最多重复发送的记录数
+	defaultBatchSize = 500
+	// defaultPollInterval 轮询新记录的间隔
+	defaultPollInterval = 10 * time.Second
+	// maxRetryDelay 导出目标连续失败时的最大退避时间
+	maxRetryDelay = 5 * time.Minute
+)
+
+// Config 审计日志导出配置
+type Config struct {
+	BatchSize    int           `yaml:"batch_size"`
+	PollInterval time.Duration `yaml:"poll_interval"`
+	Sinks        []*SinkConfig `yaml:"sinks"`
+}
+
+// SinkConfig 单个导出目标配置，type决定读取哪个子配置
+type SinkConfig struct {
+	Name    string        `yaml:"name"` // 游标按名称保存，改名等同于新目标（从头导出）
+	Type    string        `yaml:"type"` // "syslog"、"http" 或 "file"
+	Enabled bool          `yaml:"enabled"`
+	Syslog  *SyslogConfig `yaml:"syslog"`
+	HTTP    *HTTPConfig   `yaml:"http"`
+	File    *FileConfig   `yaml:"file"`
+}
+
+// SyslogConfig RFC 5424 syslog导出配置（TCP，按RFC 6587八位组计数分帧）
+type SyslogConfig struct {
+	Address            string        `yaml:"address"` // host:port
+	TLS                bool          `yaml:"tls"`
+	CAFile             string        `yaml:"ca_file"`
+	CertFile           string        `yaml:"cert_file"` // 双向TLS客户端证书（可选）
+	KeyFile            string        `yaml:"key_file"`
+	InsecureSkipVerify bool          `yaml:"insecure_skip_verify"` // 跳过证书验证(仅测试环境)
+	Format             string        `yaml:"format"`               // 消息体格式："cef"（默认）或 "json"
+	Facility           int           `yaml:"facility"`             // 默认13（log audit）
+	AppName            string        `yaml:"app_name"`
+	Hostname           string        `yaml:"hostname"` // 默认使用本机主机名
+	Timeout            time.Duration `yaml:"timeout"`
+}
+
+// HTTPConfig HTTP JSON Lines导出配置，每批记录作为一个application/x-ndjson请求体POST
+type HTTPConfig struct {
+	URL     string            `yaml:"url"`
+	Headers map[string]string `yaml:"headers"` // 如Authorization
+	Timeout time.Duration     `yaml:"timeout"`
+}
+
+// FileConfig 本地滚动文件导出配置（JSON Lines）
+type FileConfig struct {
+	Path       string `yaml:"path"`
+	MaxSizeMB  int    `yaml:"max_size_mb"` // 超过后滚动，默认100
+	MaxBackups int    `yaml:"max_backups"` // 保留的历史文件数，0表示全部保留
+}
+
+// auditExportFile 配置文件结构
+type auditExportFile struct {
+	AuditExport Config `yaml:"audit_export"`
+}
+
+// LoadConfig 从YAML文件加载导出配置，支持${ENV}引用环境变量
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read audit export config: %w", err)
+	}
+
+	var file auditExportFile
+	if err := yaml.Unmarshal([]byte(os.ExpandEnv(string(data))), &file); err != nil {
+		return nil, fmt.Errorf("failed to parse audit export config: %w", err)
+	}
+
+	cfg := &file.AuditExport
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = defaultBatchSize
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = defaultPollInterval
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// Validate 验证配置
+func (c *Config) Validate() error {
+	names := make(map[string]bool)
+	for _, sink := range c.Sinks {
+		if sink.Name == "" {
+			return fmt.Errorf("audit export sink name is required")
+		}
+		if len(sink.Name) > 100 {
+			return fmt.Errorf("audit export sink name too long: %s", sink.Name)
+		}
+		if names[sink.Name] {
+			return fmt.Errorf("duplicate audit export sink: %s", sink.Name)
+		}
+		names[sink.Name] = true
+
+		if !sink.Enabled {
+			continue
+		}
+		switch sink.Type {
+		case "syslog":
+			if sink.Syslog == nil || sink.Syslog.Address == "" {
+				return fmt.Errorf("sink %s: syslog.address is required", sink.Name)
+			}
+			if f := sink.Syslog.Format; f != "" && f != "cef" && f != "json" {
+				return fmt.Errorf("sink %s: unsupported syslog format: %s", sink.Name, f)
+			}
+		case "http":
+			if sink.HTTP == nil || sink.HTTP.URL == "" {
+				return fmt.Errorf("sink %s: http.url is required", sink.Name)
+			}
+		case "file":
+			if sink.File == nil || sink.File.Path == "" {
+				return fmt.Errorf("sink %s: file.path is required", sink.Name)
+			}
+		default:
+			return fmt.Errorf("sink %s: unsupported type: %s", sink.Name, sink.Type)
+		}
+	}
+	return nil
+}
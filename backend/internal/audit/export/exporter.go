@@ -0,0 +1,178 @@
+package export
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/edgelink/backend/internal/config"
+	"github.com/edgelink/backend/internal/metrics"
+	"github.com/edgelink/backend/internal/repository"
+	"go.uber.org/zap"
+)
+
+// Exporter 将审计日志哈希链持续导出到SIEM
+// 每个目标按组织保存已送达的链序号作为游标：先写入目标，成功后再推进游标（至少一次投递）。
+// 同一组织的链序号按提交顺序分配，游标之后不会再出现更小的序号，重启后从游标继续，
+// 最多重发进程退出前正在发送的一批记录
+type Exporter struct {
+	auditLogRepo repository.AuditLogRepository
+	cursorRepo   repository.AuditExportCursorRepository
+	metrics      *metrics.Metrics
+	logger       *zap.Logger
+
+	batchSize    int
+	pollInterval time.Duration
+	sinks        []Sink
+}
+
+// NewExporter 创建导出器，未配置AUDIT_EXPORT_FILE时不启用任何目标
+func NewExporter(
+	cfg *config.Config,
+	auditLogRepo repository.AuditLogRepository,
+	cursorRepo repository.AuditExportCursorRepository,
+	m *metrics.Metrics,
+	logger *zap.Logger,
+) (*Exporter, error) {
+	e := &Exporter{
+		auditLogRepo: auditLogRepo,
+		cursorRepo:   cursorRepo,
+		metrics:      m,
+		logger:       logger,
+		batchSize:    defaultBatchSize,
+		pollInterval: defaultPollInterval,
+	}
+	if cfg.Audit.ExportFile == "" {
+		return e, nil
+	}
+
+	exportCfg, err := LoadConfig(cfg.Audit.ExportFile)
+	if err != nil {
+		return nil, err
+	}
+	e.batchSize = exportCfg.BatchSize
+	e.pollInterval = exportCfg.PollInterval
+
+	for _, sinkCfg := range exportCfg.Sinks {
+		if !sinkCfg.Enabled {
+			continue
+		}
+		sink, err := NewSink(sinkCfg)
+		if err != nil {
+			e.Close()
+			return nil, err
+		}
+		e.sinks = append(e.sinks, sink)
+	}
+	return e, nil
+}
+
+// Enabled 是否配置了至少一个启用的导出目标
+func (e *Exporter) Enabled() bool {
+	return len(e.sinks) > 0
+}
+
+// Start 为每个目标启动导出循环，阻塞直到ctx取消，退出前关闭所有目标
+func (e *Exporter) Start(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, sink := range e.sinks {
+		wg.Add(1)
+		go func(sink Sink) {
+			defer wg.Done()
+			e.run(ctx, sink)
+		}(sink)
+	}
+	wg.Wait()
+	e.Close()
+}
+
+// run 单个目标的导出循环，目标失败时按指数退避重试，不影响其他目标
+func (e *Exporter) run(ctx context.Context, sink Sink) {
+	e.logger.Info("Audit export started",
+		zap.String("sink", sink.Name()),
+		zap.Duration("poll_interval", e.pollInterval),
+	)
+
+	delay := e.pollInterval
+	for {
+		if err := e.ExportOnce(ctx, sink); err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			delay *= 2
+			if delay > maxRetryDelay {
+				delay = maxRetryDelay
+			}
+			e.logger.Error("Audit export failed",
+				zap.String("sink", sink.Name()),
+				zap.Duration("retry_in", delay),
+				zap.Error(err),
+			)
+		} else {
+			delay = e.pollInterval
+		}
+
+		select {
+		case <-ctx.Done():
+			e.logger.Info("Audit export stopped", zap.String("sink", sink.Name()))
+			return
+		case <-time.After(delay):
+		}
+	}
+}
+
+// ExportOnce 将各组织游标之后的全部记录导出到目标，遇到第一个错误即返回
+func (e *Exporter) ExportOnce(ctx context.Context, sink Sink) error {
+	cursors, err := e.cursorRepo.FindBySink(ctx, sink.Name())
+	if err != nil {
+		return fmt.Errorf("failed to load export cursors: %w", err)
+	}
+
+	organizations, err := e.auditLogRepo.ListChainedOrganizations(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list organizations: %w", err)
+	}
+
+	for _, orgID := range organizations {
+		after := cursors[orgID]
+		for {
+			logs, err := e.auditLogRepo.FindChain(ctx, orgID, after, e.batchSize)
+			if err != nil {
+				return fmt.Errorf("failed to load audit logs for organization %s: %w", orgID, err)
+			}
+			if len(logs) == 0 {
+				break
+			}
+
+			err = sink.Write(ctx, logs)
+			e.metrics.RecordAuditExport(sink.Name(), len(logs), err)
+			if err != nil {
+				return fmt.Errorf("organization %s after sequence %d: %w", orgID, after, err)
+			}
+
+			last := *logs[len(logs)-1].Sequence
+			if err := e.cursorRepo.Save(ctx, sink.Name(), orgID, last); err != nil {
+				return fmt.Errorf("failed to save export cursor: %w", err)
+			}
+			after = last
+
+			if len(logs) < e.batchSize {
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// Close 关闭所有目标
+func (e *Exporter) Close() {
+	for _, sink := range e.sinks {
+		if err := sink.Close(); err != nil {
+			e.logger.Warn("Failed to close audit export sink",
+				zap.String("sink", sink.Name()),
+				zap.Error(err),
+			)
+		}
+	}
+}
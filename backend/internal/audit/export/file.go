@@ -0,0 +1,155 @@
+package export
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/edgelink/backend/internal/domain"
+)
+
+const defaultFileMaxSizeMB = 100
+
+// fileSink 以JSON Lines格式追加写入本地文件，超过大小上限时滚动
+type fileSink struct {
+	name    string
+	cfg     *FileConfig
+	maxSize int64
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+func newFileSink(name string, cfg *FileConfig) *fileSink {
+	maxSizeMB := cfg.MaxSizeMB
+	if maxSizeMB <= 0 {
+		maxSizeMB = defaultFileMaxSizeMB
+	}
+	return &fileSink{
+		name:    name,
+		cfg:     cfg,
+		maxSize: int64(maxSizeMB) * 1024 * 1024,
+	}
+}
+
+// Name 返回目标名称
+func (s *fileSink) Name() string {
+	return s.name
+}
+
+// Write 追加一批记录并fsync，返回前数据已落盘
+func (s *fileSink) Write(ctx context.Context, logs []*domain.AuditLog) error {
+	data, err := encodeJSONLines(logs)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.file != nil && s.size > 0 && s.size+int64(len(data)) > s.maxSize {
+		if err := s.rotate(); err != nil {
+			return err
+		}
+	}
+	if err := s.open(); err != nil {
+		return err
+	}
+
+	n, err := s.file.Write(data)
+	s.size += int64(n)
+	if err != nil {
+		return fmt.Errorf("failed to write audit export file: %w", err)
+	}
+	if err := s.file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync audit export file: %w", err)
+	}
+	return nil
+}
+
+// open 打开（或创建）当前文件（调用方需持有锁）
+func (s *fileSink) open() error {
+	if s.file != nil {
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.cfg.Path), 0o750); err != nil {
+		return fmt.Errorf("failed to create audit export directory: %w", err)
+	}
+	file, err := os.OpenFile(s.cfg.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o640)
+	if err != nil {
+		return fmt.Errorf("failed to open audit export file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to stat audit export file: %w", err)
+	}
+
+	s.file = file
+	s.size = info.Size()
+	return nil
+}
+
+// rotate 将当前文件重命名为 <path>.<UTC时间戳> 并清理超出数量的历史文件（调用方需持有锁）
+func (s *fileSink) rotate() error {
+	if err := s.file.Close(); err != nil {
+		return fmt.Errorf("failed to close audit export file: %w", err)
+	}
+	s.file = nil
+	s.size = 0
+
+	backup := s.cfg.Path + "." + time.Now().UTC().Format("20060102T150405.000000000")
+	if err := os.Rename(s.cfg.Path, backup); err != nil {
+		return fmt.Errorf("failed to rotate audit export file: %w", err)
+	}
+	return s.pruneBackups()
+}
+
+// pruneBackups 只保留最新的MaxBackups个历史文件
+func (s *fileSink) pruneBackups() error {
+	if s.cfg.MaxBackups <= 0 {
+		return nil
+	}
+
+	backups, err := filepath.Glob(s.cfg.Path + ".*")
+	if err != nil {
+		return err
+	}
+	// 时间戳格式按字典序即时间序
+	sort.Strings(backups)
+	prefix := s.cfg.Path + "."
+	var matched []string
+	for _, backup := range backups {
+		if len(strings.TrimPrefix(backup, prefix)) == len("20060102T150405.000000000") {
+			matched = append(matched, backup)
+		}
+	}
+
+	for len(matched) > s.cfg.MaxBackups {
+		if err := os.Remove(matched[0]); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove audit export backup: %w", err)
+		}
+		matched = matched[1:]
+	}
+	return nil
+}
+
+// Close 关闭当前文件
+func (s *fileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.file == nil {
+		return nil
+	}
+	err := s.file.Close()
+	s.file = nil
+	return err
+}
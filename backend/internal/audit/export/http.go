@@ -0,0 +1,74 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/edgelink/backend/internal/domain"
+)
+
+const defaultHTTPTimeout = 10 * time.Second
+
+// httpSink 将每批记录以JSON Lines格式POST到HTTP端点（如Splunk HEC raw、Logstash http input）
+type httpSink struct {
+	name   string
+	cfg    *HTTPConfig
+	client *http.Client
+}
+
+func newHTTPSink(name string, cfg *HTTPConfig) *httpSink {
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = defaultHTTPTimeout
+	}
+	return &httpSink{
+		name:   name,
+		cfg:    cfg,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+// Name 返回目标名称
+func (s *httpSink) Name() string {
+	return s.name
+}
+
+// Write 发送一批记录，非2xx响应视为失败
+func (s *httpSink) Write(ctx context.Context, logs []*domain.AuditLog) error {
+	body, err := encodeJSONLines(logs)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	for key, value := range s.cfg.Headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send audit logs: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("audit export endpoint returned status %d: %s", resp.StatusCode, string(respBody))
+	}
+	io.Copy(io.Discard, resp.Body)
+	return nil
+}
+
+// Close 关闭空闲连接
+func (s *httpSink) Close() error {
+	s.client.CloseIdleConnections()
+	return nil
+}
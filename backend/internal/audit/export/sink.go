@@ -0,0 +1,44 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/edgelink/backend/internal/domain"
+)
+
+// Sink 审计日志导出目标
+// Write 返回nil表示整批记录已被目标接收，导出器随后才推进游标；返回错误时整批会在退避后重发
+type Sink interface {
+	Name() string
+	Write(ctx context.Context, logs []*domain.AuditLog) error
+	Close() error
+}
+
+// NewSink 根据配置创建导出目标
+func NewSink(cfg *SinkConfig) (Sink, error) {
+	switch cfg.Type {
+	case "syslog":
+		return newSyslogSink(cfg.Name, cfg.Syslog)
+	case "http":
+		return newHTTPSink(cfg.Name, cfg.HTTP), nil
+	case "file":
+		return newFileSink(cfg.Name, cfg.File), nil
+	default:
+		return nil, fmt.Errorf("unsupported audit export sink type: %s", cfg.Type)
+	}
+}
+
+// encodeJSONLines 将记录编码为JSON Lines，每条记录一行
+func encodeJSONLines(logs []*domain.AuditLog) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	for _, log := range logs {
+		if err := encoder.Encode(log); err != nil {
+			return nil, fmt.Errorf("failed to encode audit log %s: %w", log.ID, err)
+		}
+	}
+	return buf.Bytes(), nil
+}
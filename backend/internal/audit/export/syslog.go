@@ -0,0 +1,202 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/edgelink/backend/internal/domain"
+)
+
+const (
+	defaultSyslogFacility = 13 // log audit
+	defaultSyslogAppName  = "edgelink"
+	defaultSyslogTimeout  = 10 * time.Second
+	// syslogSeverityNotice RFC 5424 severity 5
+	syslogSeverityNotice = 5
+)
+
+// syslogSink 通过TCP/TLS发送RFC 5424 syslog消息，使用RFC 6587八位组计数分帧
+type syslogSink struct {
+	name      string
+	cfg       *SyslogConfig
+	tlsConfig *tls.Config
+	hostname  string
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+func newSyslogSink(name string, cfg *SyslogConfig) (*syslogSink, error) {
+	s := &syslogSink{
+		name:     name,
+		cfg:      cfg,
+		hostname: cfg.Hostname,
+	}
+	if s.cfg.AppName == "" {
+		s.cfg.AppName = defaultSyslogAppName
+	}
+	if s.cfg.Facility <= 0 {
+		s.cfg.Facility = defaultSyslogFacility
+	}
+	if s.cfg.Timeout <= 0 {
+		s.cfg.Timeout = defaultSyslogTimeout
+	}
+	if s.hostname == "" {
+		if hostname, err := os.Hostname(); err == nil {
+			s.hostname = hostname
+		} else {
+			s.hostname = "-"
+		}
+	}
+
+	if cfg.TLS {
+		tlsConfig, err := buildTLSConfig(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("sink %s: %w", name, err)
+		}
+		s.tlsConfig = tlsConfig
+	}
+	return s, nil
+}
+
+// buildTLSConfig 构建TLS配置
+func buildTLSConfig(cfg *SyslogConfig) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: cfg.InsecureSkipVerify,
+	}
+	if host, _, err := net.SplitHostPort(cfg.Address); err == nil {
+		tlsConfig.ServerName = host
+	}
+
+	if cfg.CAFile != "" {
+		pem, err := os.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in CA file %s", cfg.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if cfg.CertFile != "" || cfg.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
+
+// Name 返回目标名称
+func (s *syslogSink) Name() string {
+	return s.name
+}
+
+// Write 将整批记录写入连接，写入失败时关闭连接，下一次重试时重新建立
+func (s *syslogSink) Write(ctx context.Context, logs []*domain.AuditLog) error {
+	var buf bytes.Buffer
+	for _, log := range logs {
+		msg, err := s.formatMessage(log)
+		if err != nil {
+			return err
+		}
+		buf.WriteString(strconv.Itoa(len(msg)))
+		buf.WriteByte(' ')
+		buf.Write(msg)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	conn, err := s.connect(ctx)
+	if err != nil {
+		return err
+	}
+
+	deadline := time.Now().Add(s.cfg.Timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	conn.SetWriteDeadline(deadline)
+
+	if _, err := conn.Write(buf.Bytes()); err != nil {
+		conn.Close()
+		s.conn = nil
+		return fmt.Errorf("failed to write to syslog %s: %w", s.cfg.Address, err)
+	}
+	return nil
+}
+
+// connect 返回当前连接，不存在时建立新连接（调用方需持有锁）
+func (s *syslogSink) connect(ctx context.Context) (net.Conn, error) {
+	if s.conn != nil {
+		return s.conn, nil
+	}
+
+	dialer := &net.Dialer{Timeout: s.cfg.Timeout, KeepAlive: 30 * time.Second}
+	var (
+		conn net.Conn
+		err  error
+	)
+	if s.tlsConfig != nil {
+		tlsDialer := &tls.Dialer{NetDialer: dialer, Config: s.tlsConfig}
+		conn, err = tlsDialer.DialContext(ctx, "tcp", s.cfg.Address)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", s.cfg.Address)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to syslog %s: %w", s.cfg.Address, err)
+	}
+
+	s.conn = conn
+	return conn, nil
+}
+
+// formatMessage 生成RFC 5424消息：<PRI>1 TIMESTAMP HOSTNAME APP-NAME PROCID MSGID SD MSG
+func (s *syslogSink) formatMessage(log *domain.AuditLog) ([]byte, error) {
+	var body string
+	if s.cfg.Format == "json" {
+		data, err := json.Marshal(log)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode audit log %s: %w", log.ID, err)
+		}
+		body = string(data)
+	} else {
+		body = FormatCEF(log)
+	}
+
+	pri := s.cfg.Facility*8 + syslogSeverityNotice
+	return []byte(fmt.Sprintf("<%d>1 %s %s %s - audit - %s",
+		pri,
+		log.CreatedAt.UTC().Format("2006-01-02T15:04:05.000000Z"),
+		s.hostname,
+		s.cfg.AppName,
+		body,
+	)), nil
+}
+
+// Close 关闭连接
+func (s *syslogSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn = nil
+	return err
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"io"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/edgelink/backend/internal/domain"
@@ -14,6 +15,18 @@ import (
 	"go.uber.org/zap"
 )
 
+const (
+	// ContextOrganizationID 处理器已查得资源所属组织时通过c.Set写入，审计日志优先使用
+	ContextOrganizationID = "audit_organization_id"
+
+	// ContextBeforeState、ContextAfterState 处理器写入的资源变更前后快照，记录在before_state/after_state的resource字段
+	ContextBeforeState = "audit_before_state"
+	ContextAfterState  = "audit_after_state"
+
+	// ContextSkipAudit 处理器确认请求未改变任何状态（如dry-run预览）时写入true，不记录审计日志
+	ContextSkipAudit = "audit_skip"
+)
+
 // AuditMiddleware 审计日志中间件
 type AuditMiddleware struct {
 	auditLogRepo repository.AuditLogRepository
@@ -53,9 +66,21 @@ func (am *AuditMiddleware) Middleware() gin.HandlerFunc {
 		// 执行请求
 		c.Next()
 
+		if c.GetBool(ContextSkipAudit) {
+			return
+		}
+
 		// 记录操作后状态
 		afterState := am.captureAfterState(c, responseWriter)
 
+		// 合并处理器提供的资源快照
+		if resource, ok := c.Get(ContextBeforeState); ok {
+			beforeState["resource"] = resource
+		}
+		if resource, ok := c.Get(ContextAfterState); ok {
+			afterState["resource"] = resource
+		}
+
 		// 创建审计日志
 		auditLog := am.createAuditLog(c, beforeState, afterState, startTime)
 		if auditLog != nil {
@@ -236,17 +261,27 @@ func (am *AuditMiddleware) extractResourceInfo(c *gin.Context) (domain.ResourceT
 		}
 	}
 
+	// /api/v1/admin/silences/:silence_id
+	if silenceID := c.Param("silence_id"); silenceID != "" {
+		if id, err := uuid.Parse(silenceID); err == nil {
+			return domain.ResourceTypeSilence, id
+		}
+	}
+
 	// 对于创建操作,可能没有ID参数,从path推断类型
 	if c.Request.Method == http.MethodPost {
-		if len(path) >= 26 && path[:26] == "/api/v1/admin/virtual-networks" {
+		if strings.HasPrefix(path, "/api/v1/admin/virtual-networks") {
 			return domain.ResourceTypeVirtualNetwork, uuid.Nil
 		}
-		if len(path) >= 20 && path[:20] == "/api/v1/admin/devices" {
+		if strings.HasPrefix(path, "/api/v1/admin/devices") {
 			return domain.ResourceTypeDevice, uuid.Nil
 		}
-		if len(path) >= 19 && path[:19] == "/api/v1/admin/alerts" {
+		if strings.HasPrefix(path, "/api/v1/admin/alerts") {
 			return domain.ResourceTypeAlert, uuid.Nil
 		}
+		if strings.HasPrefix(path, "/api/v1/admin/silences") {
+			return domain.ResourceTypeSilence, uuid.Nil
+		}
 	}
 
 	return "", uuid.Nil
@@ -269,7 +304,13 @@ func (am *AuditMiddleware) extractActorID(c *gin.Context) uuid.UUID {
 // extractOrganizationID 提取组织ID
 func (am *AuditMiddleware) extractOrganizationID(c *gin.Context) uuid.UUID {
 	// TODO: 从认证上下文中获取组织ID
-	// 目前从查询参数或请求体中提取
+	// 目前从处理器设置的上下文、查询参数或请求体中提取
+	if value, ok := c.Get(ContextOrganizationID); ok {
+		if orgID, ok := value.(uuid.UUID); ok {
+			return orgID
+		}
+	}
+
 	if orgIDStr := c.Query("organization_id"); orgIDStr != "" {
 		if orgID, err := uuid.Parse(orgIDStr); err == nil {
 			return orgID
@@ -303,10 +344,27 @@ func (am *AuditMiddleware) determineAction(c *gin.Context) string {
 	// 基于HTTP方法和路径的组合判断操作
 	switch method {
 	case http.MethodPost:
-		if len(path) >= 14 {
-			if path[len(path)-12:] == "/acknowledge" {
-				return "acknowledge"
-			}
+		switch {
+		case strings.HasSuffix(path, "/acknowledge"):
+			return "acknowledge"
+		case strings.HasSuffix(path, "/resolve"):
+			return "resolve"
+		case strings.HasSuffix(path, "/reopen"):
+			return "reopen"
+		case strings.HasSuffix(path, "/assign"):
+			return "assign"
+		case strings.HasSuffix(path, "/comments"):
+			return "comment"
+		case strings.HasSuffix(path, "/approve"):
+			return "approve"
+		case strings.HasSuffix(path, "/reject"):
+			return "reject"
+		case strings.HasSuffix(path, "/move"):
+			return "move"
+		case strings.HasSuffix(path, "/bulk/tag"):
+			return "tag"
+		case strings.HasSuffix(path, "/bulk/delete"):
+			return "delete"
 		}
 		return "create"
 	case http.MethodPut:
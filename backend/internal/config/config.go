@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -14,8 +15,14 @@ type Config struct {
 	Redis    RedisConfig
 	Logging  LoggingConfig
 	Metrics  MetricsConfig
-	Email    EmailConfig
-	Alert    AlertConfig
+	Tracing  TracingConfig
+	Email     EmailConfig
+	Alert     AlertConfig
+	Callbacks CallbackConfig
+	Auth      AuthConfig
+	Audit     AuditConfig
+	Worker    WorkerConfig
+	Retention RetentionConfig
 }
 
 // ServerConfig HTTP服务器配置
@@ -61,11 +68,24 @@ type MetricsConfig struct {
 	Port    int
 }
 
+// TracingConfig 分布式追踪配置
+type TracingConfig struct {
+	Exporter     string            // "none"（默认，不导出）或 "otlp"
+	OTLPEndpoint string            // OTLP接收端地址（host:port）
+	OTLPProtocol string            // "grpc" 或 "http"
+	OTLPInsecure bool              // 不使用TLS连接接收端
+	OTLPHeaders  map[string]string // 附加请求头（如认证令牌）
+	SampleRatio  float64           // 根span采样比例（0-1），有上游上下文时跟随上游决定
+}
+
 // EmailConfig 邮件配置
 type EmailConfig struct {
 	// 邮件提供商类型: "smtp", "sendgrid", "mailgun", "ses"
 	Provider string
 
+	// 备用提供商，主提供商失败时按顺序切换
+	FallbackProviders []string
+
 	// SMTP通用配置
 	SMTP SMTPConfig
 
@@ -109,13 +129,15 @@ type SMTPConfig struct {
 type SendGridConfig struct {
 	APIKey      string
 	SandboxMode bool
+	BaseURL     string // API地址，默认https://api.sendgrid.com
 }
 
 // MailgunConfig Mailgun API配置
 type MailgunConfig struct {
 	Domain      string
 	APIKey      string
-	BaseURL     string // eu域名可自定义
+	BaseURL     string // eu域名可自定义，为空时按Region选择
+	Region      string // "us"或"eu"
 }
 
 // SESConfig Amazon SES配置
@@ -123,7 +145,55 @@ type SESConfig struct {
 	Region          string
 	AccessKeyID     string
 	SecretAccessKey string
+	SessionToken    string // 临时凭证的会话令牌(可选)
 	ConfigSet       string // SES配置集(可选)
+	Endpoint        string // API地址(可选)，默认https://email.<region>.amazonaws.com
+}
+
+// CallbackConfig 第三方平台回调（确认/解决告警）配置，未配置密钥的平台拒绝回调
+type CallbackConfig struct {
+	SlackSigningSecret      string        // Slack应用的Signing Secret
+	PagerDutyWebhookSecrets []string      // PagerDuty v3 Webhook订阅密钥（支持轮换时配置多个）
+	OpsgenieWebhookToken    string        // Opsgenie Webhook集成中自定义X-EdgeLink-Token头的值
+	TeamsAudience           string        // 可操作消息令牌的aud（网关对外URL）
+	TeamsIssuer             string        // 可操作消息令牌的签发者
+	TeamsJWKSURL            string        // 可操作消息令牌签名公钥地址
+	MaxClockSkew            time.Duration // 签名时间戳允许的最大偏差
+}
+
+// AuthConfig 管理API认证配置
+type AuthConfig struct {
+	JWTSecret        string        // 管理员JWT签名密钥，网关与告警服务共用
+	TokenDuration    time.Duration // 令牌有效期
+	AdminAuthEnabled bool          // 网关管理端点是否要求JWT（告警服务始终要求）
+}
+
+// AuditConfig 审计日志配置
+type AuditConfig struct {
+	SigningPublicKey   string        // 检查点签名Ed25519公钥（Base64）
+	SigningPrivateKey  string        // 检查点签名Ed25519私钥（Base64），只有生成检查点的后台任务需要
+	TrustedPublicKeys  []string      // 轮换前使用过的公钥，校验历史检查点时仍然信任
+	CheckpointInterval time.Duration // 生成检查点的间隔
+	ExportFile         string        // SIEM导出目标配置文件，为空时不导出
+}
+
+// WorkerConfig 后台工作器配置
+type WorkerConfig struct {
+	LeaderElection      bool              // 通过Redis租约选出一个实例执行任务，多副本部署时必须开启
+	InstanceID          string            // 实例标识，默认为主机名
+	LeaseTTL            time.Duration     // leader租约时长，leader失联后最多经过该时长由其他实例接管
+	TriggerPollInterval time.Duration     // 检查手动触发请求的间隔
+	Schedules           map[string]string // 任务调度覆盖（WORKER_SCHEDULE_<TASK>），值为cron表达式或"off"
+	EphemeralOfflineTTL time.Duration     // 临时设备离线超过该时长后自动撤销
+}
+
+// RetentionConfig 数据保留与清理配置
+type RetentionConfig struct {
+	BatchSize   int            // 每批删除的记录数，控制单个事务的锁持有时间
+	BatchPause  time.Duration  // 批次之间的间隔，降低对数据库的持续压力
+	ArchiveDir  string         // 归档文件目录，策略为archive时必须配置
+	DryRun      bool           // 只统计将被清理的记录数，不删除
+	DefaultDays map[string]int // 没有保留策略时各数据类别的默认保留天数，0表示不清理；审计日志没有默认值
 }
 
 // AlertConfig 告警配置
@@ -137,7 +207,31 @@ type AlertConfig struct {
 	// 检查配置
 	CheckInterval       time.Duration // 检查间隔
 	DeviceOfflineThreshold time.Duration // 设备离线阈值
-	HighLatencyThreshold   int           // 高延迟阈值（毫秒）
+	HighLatencyThreshold   int           // 高延迟阈值（毫秒），链路基线建立前使用
+	PacketLossThreshold    float64       // 丢包率阈值（0-1），链路基线建立前使用
+
+	// 链路基线异常检测（可按虚拟网络/标签在数据库中覆盖）
+	AnomalySigma              float64       // 偏离基线的标准差倍数
+	AnomalySustain            time.Duration // 持续偏离多久才告警
+	AnomalyEWMAAlpha          float64       // EWMA平滑系数，越小基线越稳定
+	AnomalyMinSamples         int           // 基线生效所需的最少样本数
+	AnomalyMinLatencyDeltaMs  int           // 延迟高出基线不足该值时忽略
+	AnomalyMinPacketLossDelta float64       // 丢包率高出基线不足该值时忽略
+	LinkMetricsRetention      time.Duration // 链路指标样本保留时长
+
+	// 运行状态检测
+	FlapWindow               time.Duration // 统计设备上下线次数的时间窗口
+	FlapThreshold            int           // 窗口内上下线次数达到该值视为抖动
+	RelayFallbackWindow      time.Duration // 统计中继回落比例的时间窗口
+	RelayFallbackThreshold   float64       // 网络内经TURN中继建立的会话比例阈值（0-1）
+	RelayFallbackMinSessions int           // 窗口内会话数不足该值时不判定
+	IPPoolThreshold          float64       // 虚拟网络地址池使用率阈值（0-1）
+	PSKUsageThreshold        float64       // 预共享密钥使用次数达到MaxUses的比例阈值（0-1）
+	PSKExpiryWindow          time.Duration // 预共享密钥在该时长内过期时告警
+	ClockSkewThreshold       time.Duration // 设备请求时间戳与服务器时间偏差阈值
+
+	// 第三方集成配置文件（PagerDuty/Opsgenie/Slack等）
+	IntegrationsFile string
 }
 
 // LoadConfig 从环境变量加载配置（Fx兼容）
@@ -181,8 +275,17 @@ func Load() (*Config, error) {
 			Enabled: getEnvAsBool("METRICS_ENABLED", true),
 			Port:    getEnvAsInt("METRICS_PORT", 9090),
 		},
+		Tracing: TracingConfig{
+			Exporter:     getEnv("TRACING_EXPORTER", "none"),
+			OTLPEndpoint: getEnv("TRACING_OTLP_ENDPOINT", "localhost:4317"),
+			OTLPProtocol: getEnv("TRACING_OTLP_PROTOCOL", "grpc"),
+			OTLPInsecure: getEnvAsBool("TRACING_OTLP_INSECURE", true),
+			OTLPHeaders:  getEnvAsMap("TRACING_OTLP_HEADERS"),
+			SampleRatio:  getEnvAsFloat("TRACING_SAMPLE_RATIO", 1.0),
+		},
 		Email: EmailConfig{
-			Provider: getEnv("EMAIL_PROVIDER", "smtp"),
+			Provider:          getEnv("EMAIL_PROVIDER", "smtp"),
+			FallbackProviders: getEnvAsSlice("EMAIL_FALLBACK_PROVIDERS", nil),
 
 			SMTP: SMTPConfig{
 				Host:        getEnv("SMTP_HOST", "smtp.gmail.com"),
@@ -198,19 +301,23 @@ func Load() (*Config, error) {
 			SendGrid: SendGridConfig{
 				APIKey:      getEnv("SENDGRID_API_KEY", ""),
 				SandboxMode: getEnvAsBool("SENDGRID_SANDBOX_MODE", false),
+				BaseURL:     getEnv("SENDGRID_BASE_URL", "https://api.sendgrid.com"),
 			},
 
 			Mailgun: MailgunConfig{
 				Domain:  getEnv("MAILGUN_DOMAIN", ""),
 				APIKey:  getEnv("MAILGUN_API_KEY", ""),
-				BaseURL: getEnv("MAILGUN_BASE_URL", "https://api.mailgun.net"),
+				BaseURL: getEnv("MAILGUN_BASE_URL", ""),
+				Region:  getEnv("MAILGUN_REGION", "us"),
 			},
 
 			SES: SESConfig{
 				Region:          getEnv("AWS_SES_REGION", "us-east-1"),
 				AccessKeyID:     getEnv("AWS_ACCESS_KEY_ID", ""),
 				SecretAccessKey: getEnv("AWS_SECRET_ACCESS_KEY", ""),
+				SessionToken:    getEnv("AWS_SESSION_TOKEN", ""),
 				ConfigSet:       getEnv("AWS_SES_CONFIG_SET", ""),
+				Endpoint:        getEnv("AWS_SES_ENDPOINT", ""),
 			},
 
 			FromAddress:  getEnv("EMAIL_FROM_ADDRESS", "noreply@edgelink.com"),
@@ -238,6 +345,69 @@ func Load() (*Config, error) {
 			CheckInterval:          getEnvAsDuration("ALERT_CHECK_INTERVAL", 1*time.Minute),
 			DeviceOfflineThreshold: getEnvAsDuration("ALERT_DEVICE_OFFLINE_THRESHOLD", 5*time.Minute),
 			HighLatencyThreshold:   getEnvAsInt("ALERT_HIGH_LATENCY_THRESHOLD", 200),
+			PacketLossThreshold:    getEnvAsFloat("ALERT_PACKET_LOSS_THRESHOLD", 0.1),
+
+			AnomalySigma:              getEnvAsFloat("ALERT_ANOMALY_SIGMA", 3),
+			AnomalySustain:            getEnvAsDuration("ALERT_ANOMALY_SUSTAIN", 10*time.Minute),
+			AnomalyEWMAAlpha:          getEnvAsFloat("ALERT_ANOMALY_EWMA_ALPHA", 0.05),
+			AnomalyMinSamples:         getEnvAsInt("ALERT_ANOMALY_MIN_SAMPLES", 30),
+			AnomalyMinLatencyDeltaMs:  getEnvAsInt("ALERT_ANOMALY_MIN_LATENCY_DELTA_MS", 20),
+			AnomalyMinPacketLossDelta: getEnvAsFloat("ALERT_ANOMALY_MIN_PACKET_LOSS_DELTA", 0.02),
+			LinkMetricsRetention:      getEnvAsDuration("ALERT_LINK_METRICS_RETENTION", 7*24*time.Hour),
+
+			FlapWindow:               getEnvAsDuration("ALERT_FLAP_WINDOW", 30*time.Minute),
+			FlapThreshold:            getEnvAsInt("ALERT_FLAP_THRESHOLD", 6),
+			RelayFallbackWindow:      getEnvAsDuration("ALERT_RELAY_FALLBACK_WINDOW", 1*time.Hour),
+			RelayFallbackThreshold:   getEnvAsFloat("ALERT_RELAY_FALLBACK_THRESHOLD", 0.5),
+			RelayFallbackMinSessions: getEnvAsInt("ALERT_RELAY_FALLBACK_MIN_SESSIONS", 10),
+			IPPoolThreshold:          getEnvAsFloat("ALERT_IP_POOL_THRESHOLD", 0.85),
+			PSKUsageThreshold:        getEnvAsFloat("ALERT_PSK_USAGE_THRESHOLD", 0.9),
+			PSKExpiryWindow:          getEnvAsDuration("ALERT_PSK_EXPIRY_WINDOW", 7*24*time.Hour),
+			ClockSkewThreshold:       getEnvAsDuration("ALERT_CLOCK_SKEW_THRESHOLD", 30*time.Second),
+
+			IntegrationsFile: getEnv("ALERT_INTEGRATIONS_FILE", "config/integrations.yaml"),
+		},
+		Callbacks: CallbackConfig{
+			SlackSigningSecret:      getEnv("SLACK_SIGNING_SECRET", ""),
+			PagerDutyWebhookSecrets: getEnvAsSlice("PAGERDUTY_WEBHOOK_SECRETS", nil),
+			OpsgenieWebhookToken:    getEnv("OPSGENIE_WEBHOOK_TOKEN", ""),
+			TeamsAudience:           getEnv("TEAMS_ACTION_AUDIENCE", ""),
+			TeamsIssuer:             getEnv("TEAMS_ACTION_ISSUER", "https://substrate.office.com/sts/"),
+			TeamsJWKSURL:            getEnv("TEAMS_ACTION_JWKS_URL", "https://substrate.office.com/sts/common/discovery/keys"),
+			MaxClockSkew:            getEnvAsDuration("CALLBACK_MAX_CLOCK_SKEW", 5*time.Minute),
+		},
+		Auth: AuthConfig{
+			JWTSecret:        getEnv("JWT_SECRET", ""),
+			TokenDuration:    getEnvAsDuration("JWT_TOKEN_DURATION", 24*time.Hour),
+			AdminAuthEnabled: getEnvAsBool("ADMIN_AUTH_ENABLED", false),
+		},
+		Audit: AuditConfig{
+			SigningPublicKey:   getEnv("AUDIT_SIGNING_PUBLIC_KEY", ""),
+			SigningPrivateKey:  getEnv("AUDIT_SIGNING_PRIVATE_KEY", ""),
+			TrustedPublicKeys:  getEnvAsSlice("AUDIT_TRUSTED_PUBLIC_KEYS", nil),
+			CheckpointInterval: getEnvAsDuration("AUDIT_CHECKPOINT_INTERVAL", time.Hour),
+			ExportFile:         getEnv("AUDIT_EXPORT_FILE", ""),
+		},
+		Worker: WorkerConfig{
+			LeaderElection:      getEnvAsBool("WORKER_LEADER_ELECTION", true),
+			InstanceID:          getEnv("WORKER_INSTANCE_ID", hostname()),
+			LeaseTTL:            getEnvAsDuration("WORKER_LEASE_TTL", 15*time.Second),
+			TriggerPollInterval: getEnvAsDuration("WORKER_TRIGGER_POLL_INTERVAL", 5*time.Second),
+			Schedules:           getEnvWithPrefix("WORKER_SCHEDULE_"),
+			EphemeralOfflineTTL: getEnvAsDuration("EPHEMERAL_DEVICE_OFFLINE_TTL", 10*time.Minute),
+		},
+		Retention: RetentionConfig{
+			BatchSize:  getEnvAsInt("RETENTION_BATCH_SIZE", 1000),
+			BatchPause: getEnvAsDuration("RETENTION_BATCH_PAUSE", 100*time.Millisecond),
+			ArchiveDir: getEnv("RETENTION_ARCHIVE_DIR", ""),
+			DryRun:     getEnvAsBool("RETENTION_DRY_RUN", false),
+			DefaultDays: map[string]int{
+				"sessions":           getEnvAsInt("RETENTION_SESSIONS_DAYS", 90),
+				"alerts":             getEnvAsInt("RETENTION_ALERTS_DAYS", 365),
+				"email_history":      getEnvAsInt("RETENTION_EMAIL_HISTORY_DAYS", 90),
+				"diagnostic_bundles": getEnvAsInt("RETENTION_DIAGNOSTIC_BUNDLES_DAYS", 30),
+				"task_runs":          getEnvAsInt("RETENTION_TASK_RUNS_DAYS", 30),
+			},
 		},
 	}, nil
 }
@@ -281,6 +451,15 @@ func getEnvAsBool(key string, defaultValue bool) bool {
 	return defaultValue
 }
 
+func getEnvAsFloat(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if floatVal, err := strconv.ParseFloat(value, 64); err == nil {
+			return floatVal
+		}
+	}
+	return defaultValue
+}
+
 func getEnvAsDuration(key string, defaultValue time.Duration) time.Duration {
 	if value := os.Getenv(key); value != "" {
 		if duration, err := time.ParseDuration(value); err == nil {
@@ -289,3 +468,56 @@ func getEnvAsDuration(key string, defaultValue time.Duration) time.Duration {
 	}
 	return defaultValue
 }
+
+// getEnvAsMap 解析 "key1=value1,key2=value2" 格式的环境变量
+func getEnvAsMap(key string) map[string]string {
+	items := getEnvAsSlice(key, nil)
+	if len(items) == 0 {
+		return nil
+	}
+
+	result := make(map[string]string, len(items))
+	for _, item := range items {
+		k, v, ok := strings.Cut(item, "=")
+		if !ok {
+			continue
+		}
+		result[strings.TrimSpace(k)] = strings.TrimSpace(v)
+	}
+	return result
+}
+
+// getEnvWithPrefix 读取指定前缀的所有环境变量，键为去掉前缀后的小写名称
+func getEnvWithPrefix(prefix string) map[string]string {
+	result := make(map[string]string)
+	for _, item := range os.Environ() {
+		key, value, ok := strings.Cut(item, "=")
+		if !ok || !strings.HasPrefix(key, prefix) || value == "" {
+			continue
+		}
+		result[strings.ToLower(strings.TrimPrefix(key, prefix))] = strings.TrimSpace(value)
+	}
+	return result
+}
+
+// hostname 返回主机名，获取失败时返回空字符串
+func hostname() string {
+	name, _ := os.Hostname()
+	return name
+}
+
+// getEnvAsSlice 读取逗号分隔的列表
+func getEnvAsSlice(key string, defaultValue []string) []string {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	items := make([]string, 0)
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
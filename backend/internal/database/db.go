@@ -8,6 +8,7 @@ import (
 
 	"github.com/edgelink/backend/internal/config"
 	"github.com/edgelink/backend/internal/domain"
+	"github.com/edgelink/backend/internal/repository"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
@@ -117,11 +118,11 @@ func createEnumTypes(gormDB *gorm.DB) error {
 		{"key_status_enum", "'active', 'pending_rotation', 'revoked', 'expired'"},
 		{"connection_type_enum", "'p2p_direct', 'turn_relay'"},
 		{"severity_enum", "'critical', 'high', 'medium', 'low'"},
-		{"alert_type_enum", "'device_offline', 'high_latency', 'failed_auth', 'key_expiration', 'tunnel_failure'"},
+		{"alert_type_enum", "'device_offline', 'high_latency', 'failed_auth', 'key_expiration', 'tunnel_failure', 'webhook_disabled', 'packet_loss', 'device_flapping', 'relay_fallback', 'ip_pool_exhaustion', 'enrollment_key_exhaustion', 'clock_skew'"},
 		{"alert_status_enum", "'active', 'acknowledged', 'resolved'"},
 		{"role_enum", "'super_admin', 'admin', 'network_operator', 'auditor', 'readonly'"},
 		{"diagnostic_status_enum", "'requested', 'collecting', 'uploaded', 'failed', 'expired'"},
-		{"resource_type_enum", "'device', 'virtual_network', 'pre_shared_key', 'alert', 'organization'"},
+		{"resource_type_enum", "'device', 'virtual_network', 'pre_shared_key', 'alert', 'organization', 'silence'"},
 	}
 	
 	// 使用DO块创建ENUM类型（如果不存在）
@@ -152,12 +153,37 @@ func AutoMigrate(db *gorm.DB) error {
 		&domain.Device{},
 		&domain.DeviceKey{},
 		&domain.PreSharedKey{},
+		&domain.DeviceApprovalRule{},
+		&domain.PosturePolicy{},
 		&domain.PeerConfiguration{},
 		&domain.Session{},
 		&domain.Alert{},
 		&domain.AuditLog{},
+		&domain.AuditCheckpoint{},
+		&domain.AuditExportCursor{},
+		&domain.TaskRun{},
+		&domain.RetentionPolicy{},
+		&domain.RetentionRun{},
 		&domain.DiagnosticBundle{},
 		&domain.AdminUser{},
+		&domain.AlertComment{},
+		&domain.Silence{},
+		&domain.NotificationRule{},
+		&domain.NotificationRuleVersion{},
+		&domain.NotificationDelivery{},
+		&domain.NotificationAttempt{},
+		&domain.OnCallSchedule{},
+		&domain.OnCallOverride{},
+		&domain.EscalationPolicy{},
+		&domain.AlertEscalation{},
+		&domain.AlertGroup{},
+		&domain.ExternalIdentity{},
+		&domain.WebhookEndpoint{},
+		&domain.LinkMetric{},
+		&domain.LinkBaseline{},
+		&domain.AnomalyThreshold{},
+		&domain.DeviceStatusEvent{},
+		&repository.EmailHistory{},
 	)
 }
 
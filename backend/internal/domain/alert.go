@@ -22,11 +22,20 @@ const (
 type AlertType string
 
 const (
-	AlertTypeDeviceOffline  AlertType = "device_offline"
-	AlertTypeHighLatency    AlertType = "high_latency"
-	AlertTypeFailedAuth     AlertType = "failed_auth"
-	AlertTypeKeyExpiration  AlertType = "key_expiration"
-	AlertTypeTunnelFailure  AlertType = "tunnel_failure"
+	AlertTypeDeviceOffline           AlertType = "device_offline"
+	AlertTypeHighLatency             AlertType = "high_latency"
+	AlertTypeFailedAuth              AlertType = "failed_auth"
+	AlertTypeKeyExpiration           AlertType = "key_expiration"
+	AlertTypeTunnelFailure           AlertType = "tunnel_failure"
+	AlertTypeWebhookDisabled         AlertType = "webhook_disabled"          // 出站Webhook端点持续失败被自动停用
+	AlertTypePacketLoss              AlertType = "packet_loss"               // 链路丢包率持续偏离基线
+	AlertTypeDeviceFlapping          AlertType = "device_flapping"           // 设备在短时间内频繁上下线
+	AlertTypeRelayFallback           AlertType = "relay_fallback"            // 网络内大量会话回落到TURN中继
+	AlertTypeIPPoolExhaustion        AlertType = "ip_pool_exhaustion"        // 虚拟网络地址池即将耗尽
+	AlertTypeEnrollmentKeyExhaustion AlertType = "enrollment_key_exhaustion" // 预共享密钥接近使用上限或过期
+	AlertTypeClockSkew               AlertType = "clock_skew"                // 设备时钟与服务器偏差过大
+	AlertTypeDevicePendingApproval   AlertType = "device_pending_approval"   // 新设备等待管理员审批
+	AlertTypePostureViolation        AlertType = "posture_violation"         // 设备不满足安全状态策略，已被隔离或限制对端
 )
 
 // AlertStatus 告警状态枚举
@@ -68,6 +77,10 @@ type Alert struct {
 	AcknowledgedBy  *uuid.UUID   `json:"acknowledged_by,omitempty"`
 	AcknowledgedAt  *time.Time   `json:"acknowledged_at,omitempty"`
 	ResolvedAt      *time.Time   `json:"resolved_at,omitempty"`
+	AssignedTo      *uuid.UUID   `gorm:"type:uuid;index" json:"assigned_to,omitempty"`
+	AssignedAt      *time.Time   `json:"assigned_at,omitempty"`
+	SilencedBy      *uuid.UUID   `gorm:"type:uuid;index" json:"silenced_by,omitempty"`
+	InhibitedBy     *uuid.UUID   `gorm:"type:uuid;index" json:"inhibited_by,omitempty"` // 抑制该告警的根因告警
 
 	// 去重相关字段
 	OccurrenceCount int        `gorm:"default:1;not null" json:"occurrence_count"`
@@ -78,7 +91,8 @@ type Alert struct {
 	UpdatedAt       time.Time  `gorm:"not null;default:now()" json:"updated_at"`
 
 	// 关联
-	Device *Device `gorm:"foreignKey:DeviceID" json:"device,omitempty"`
+	Device   *Device    `gorm:"foreignKey:DeviceID" json:"device,omitempty"`
+	Assignee *AdminUser `gorm:"foreignKey:AssignedTo" json:"assignee,omitempty"`
 }
 
 // TableName 指定表名
@@ -0,0 +1,25 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AlertComment 告警评论实体
+type AlertComment struct {
+	ID        uuid.UUID `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	AlertID   uuid.UUID `gorm:"type:uuid;not null;index" json:"alert_id"`
+	AuthorID  uuid.UUID `gorm:"type:uuid;not null" json:"author_id"`
+	Body      string    `gorm:"type:text;not null" json:"body"`
+	CreatedAt time.Time `gorm:"not null;default:now();index" json:"created_at"`
+
+	// 关联
+	Alert  *Alert     `gorm:"foreignKey:AlertID" json:"alert,omitempty"`
+	Author *AdminUser `gorm:"foreignKey:AuthorID" json:"author,omitempty"`
+}
+
+// TableName 指定表名
+func (AlertComment) TableName() string {
+	return "alert_comments"
+}
@@ -0,0 +1,106 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AlertGroupStatus 告警分组状态枚举
+type AlertGroupStatus string
+
+const (
+	AlertGroupStatusOpen   AlertGroupStatus = "open"
+	AlertGroupStatusClosed AlertGroupStatus = "closed" // 成员全部恢复，新告警到达时重新打开
+)
+
+// AlertIDList 告警ID列表（以JSONB存储）
+type AlertIDList []uuid.UUID
+
+// Scan 实现sql.Scanner接口
+func (l *AlertIDList) Scan(value interface{}) error {
+	bytes, ok := value.([]byte)
+	if !ok {
+		return nil
+	}
+	return json.Unmarshal(bytes, l)
+}
+
+// Value 实现driver.Valuer接口
+func (l AlertIDList) Value() (driver.Value, error) {
+	if l == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(l)
+}
+
+// Contains 判断列表是否包含指定告警
+func (l AlertIDList) Contains(id uuid.UUID) bool {
+	for _, existing := range l {
+		if existing == id {
+			return true
+		}
+	}
+	return false
+}
+
+// Fingerprint 返回与顺序无关的成员指纹，用于判断分组是否发生变化
+func (l AlertIDList) Fingerprint() string {
+	ids := make([]string, len(l))
+	for i, id := range l {
+		ids[i] = id.String()
+	}
+	sort.Strings(ids)
+	return strings.Join(ids, ",")
+}
+
+// GroupLabels 分组标签（group_by键及其取值）
+type GroupLabels map[string]string
+
+// Scan 实现sql.Scanner接口
+func (g *GroupLabels) Scan(value interface{}) error {
+	bytes, ok := value.([]byte)
+	if !ok {
+		return nil
+	}
+	return json.Unmarshal(bytes, g)
+}
+
+// Value 实现driver.Valuer接口
+func (g GroupLabels) Value() (driver.Value, error) {
+	if g == nil {
+		return []byte("{}"), nil
+	}
+	return json.Marshal(g)
+}
+
+// AlertGroup 告警分组（同一规则下group_by取值相同的告警合并为一条通知）
+type AlertGroup struct {
+	ID               uuid.UUID        `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	RuleID           string           `gorm:"type:varchar(255);not null;uniqueIndex:idx_alert_groups_rule_key" json:"rule_id"`
+	GroupKey         string           `gorm:"type:text;not null;uniqueIndex:idx_alert_groups_rule_key" json:"group_key"`
+	Labels           GroupLabels      `gorm:"type:jsonb;not null;default:'{}'" json:"labels"`
+	Status           AlertGroupStatus `gorm:"type:varchar(20);not null;default:'open';index" json:"status"`
+	AlertIDs         AlertIDList      `gorm:"type:jsonb;not null;default:'[]'" json:"alert_ids"`
+	NotifiedAlertIDs AlertIDList      `gorm:"type:jsonb;not null;default:'[]'" json:"notified_alert_ids"` // 最近一次通知时的成员
+	NotifyCount      int              `gorm:"not null;default:0" json:"notify_count"`
+	NextFlushAt      time.Time        `gorm:"not null;index" json:"next_flush_at"`
+	LockedUntil      *time.Time       `json:"-"`
+	LastNotifiedAt   *time.Time       `json:"last_notified_at,omitempty"`
+	CreatedAt        time.Time        `gorm:"not null;default:now()" json:"created_at"`
+	UpdatedAt        time.Time        `gorm:"not null;default:now()" json:"updated_at"`
+}
+
+// TableName 指定表名
+func (AlertGroup) TableName() string {
+	return "alert_groups"
+}
+
+// Changed 判断当前成员与上次通知的成员是否不同
+func (g *AlertGroup) Changed(members AlertIDList) bool {
+	return members.Fingerprint() != g.NotifiedAlertIDs.Fingerprint()
+}
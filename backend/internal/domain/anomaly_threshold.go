@@ -0,0 +1,89 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AnomalyThreshold 异常检测阈值覆盖
+// 作用于单个虚拟网络或带有指定标签的设备（二选一），未设置的字段沿用全局默认值
+type AnomalyThreshold struct {
+	ID                 uuid.UUID  `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	OrganizationID     uuid.UUID  `gorm:"type:uuid;not null;index" json:"organization_id"`
+	VirtualNetworkID   *uuid.UUID `gorm:"type:uuid;index" json:"virtual_network_id,omitempty"`
+	Tag                *string    `gorm:"type:varchar(100)" json:"tag,omitempty"`
+	Priority           int        `gorm:"not null;default:100" json:"priority"` // 多个标签覆盖同时匹配时数字小者优先
+	Enabled            bool       `gorm:"not null;default:true" json:"enabled"` // false时该范围内不做基线异常检测
+	Sigma              *float64   `json:"sigma,omitempty"`                      // 偏离基线的标准差倍数
+	SustainSeconds     *int       `json:"sustain_seconds,omitempty"`            // 持续偏离多久才告警
+	MinLatencyDeltaMs  *int       `json:"min_latency_delta_ms,omitempty"`       // 延迟高出基线不足该值时忽略
+	MinPacketLossDelta *float64   `json:"min_packet_loss_delta,omitempty"`      // 丢包率高出基线不足该值时忽略
+	LatencyMaxMs       *int       `json:"latency_max_ms,omitempty"`             // 延迟绝对上限，超过即视为异常
+	PacketLossMax      *float64   `json:"packet_loss_max,omitempty"`            // 丢包率绝对上限
+	OfflineAfterSecs   *int       `gorm:"column:offline_after_seconds" json:"offline_after_seconds,omitempty"`
+	Comment            string     `gorm:"type:text" json:"comment,omitempty"`
+	CreatedBy          *uuid.UUID `gorm:"type:uuid" json:"created_by,omitempty"`
+	CreatedAt          time.Time  `gorm:"not null;default:now()" json:"created_at"`
+	UpdatedAt          time.Time  `gorm:"not null;default:now()" json:"updated_at"`
+}
+
+// TableName 指定表名
+func (AnomalyThreshold) TableName() string {
+	return "anomaly_thresholds"
+}
+
+// Matches 检查覆盖是否作用于该设备
+func (t *AnomalyThreshold) Matches(device *Device) bool {
+	if t.VirtualNetworkID != nil {
+		return *t.VirtualNetworkID == device.VirtualNetworkID
+	}
+	if t.Tag != nil {
+		for _, tag := range device.Tags {
+			if tag == *t.Tag {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Validate 校验作用范围与阈值取值
+func (t *AnomalyThreshold) Validate() error {
+	if t.Tag != nil {
+		tag := strings.TrimSpace(*t.Tag)
+		if tag == "" {
+			return errors.New("tag must not be empty")
+		}
+		t.Tag = &tag
+	}
+	if (t.VirtualNetworkID == nil) == (t.Tag == nil) {
+		return errors.New("exactly one of virtual_network_id or tag is required")
+	}
+	if t.Sigma != nil && *t.Sigma <= 0 {
+		return errors.New("sigma must be positive")
+	}
+	if t.SustainSeconds != nil && *t.SustainSeconds < 0 {
+		return errors.New("sustain_seconds must be non-negative")
+	}
+	if t.MinLatencyDeltaMs != nil && *t.MinLatencyDeltaMs < 0 {
+		return errors.New("min_latency_delta_ms must be non-negative")
+	}
+	if t.LatencyMaxMs != nil && *t.LatencyMaxMs <= 0 {
+		return errors.New("latency_max_ms must be positive")
+	}
+	for name, value := range map[string]*float64{
+		"min_packet_loss_delta": t.MinPacketLossDelta,
+		"packet_loss_max":       t.PacketLossMax,
+	} {
+		if value != nil && (*value < 0 || *value > 1) {
+			return errors.New(name + " must be between 0 and 1")
+		}
+	}
+	if t.OfflineAfterSecs != nil && *t.OfflineAfterSecs <= 0 {
+		return errors.New("offline_after_seconds must be positive")
+	}
+	return nil
+}
@@ -0,0 +1,20 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AuditExportCursor 审计日志导出游标，记录每个导出目标在各组织哈希链上已送达的位置
+type AuditExportCursor struct {
+	Sink           string    `gorm:"type:varchar(100);primaryKey" json:"sink"`
+	OrganizationID uuid.UUID `gorm:"type:uuid;primaryKey" json:"organization_id"`
+	LastSequence   int64     `gorm:"not null" json:"last_sequence"`
+	UpdatedAt      time.Time `gorm:"not null;default:now()" json:"updated_at"`
+}
+
+// TableName 指定表名
+func (AuditExportCursor) TableName() string {
+	return "audit_export_cursors"
+}
@@ -1,6 +1,11 @@
 package domain
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
 	"time"
 
 	"github.com/google/uuid"
@@ -15,12 +20,20 @@ const (
 	ResourceTypePreSharedKey   ResourceType = "pre_shared_key"
 	ResourceTypeAlert          ResourceType = "alert"
 	ResourceTypeOrganization   ResourceType = "organization"
+	ResourceTypeSilence        ResourceType = "silence"
 )
 
+// AuditGenesisHash 组织哈希链第一条记录的前序哈希
+const AuditGenesisHash = "0000000000000000000000000000000000000000000000000000000000000000"
+
+// auditHashVersion 哈希计算格式版本，调整参与哈希的字段时递增
+const auditHashVersion = 1
+
 // AuditLog 审计日志实体（不可变）
+// 每个组织的记录按Sequence组成哈希链：Hash覆盖记录内容和PrevHash，修改或删除任一记录都会使后续链接校验失败
 type AuditLog struct {
 	ID             uuid.UUID    `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
-	OrganizationID uuid.UUID    `gorm:"type:uuid;not null;index" json:"organization_id"`
+	OrganizationID uuid.UUID    `gorm:"type:uuid;not null;index;uniqueIndex:idx_audit_logs_org_sequence,priority:1" json:"organization_id"`
 	ActorID        *uuid.UUID   `gorm:"type:uuid;index" json:"actor_id,omitempty"`
 	Action         string       `gorm:"type:varchar(100);not null;index" json:"action"`
 	ResourceType   ResourceType `gorm:"type:resource_type_enum;not null;index" json:"resource_type"`
@@ -30,6 +43,9 @@ type AuditLog struct {
 	IPAddress      *string      `gorm:"type:inet" json:"ip_address,omitempty"`
 	UserAgent      *string      `gorm:"type:text" json:"user_agent,omitempty"`
 	CreatedAt      time.Time    `gorm:"not null;default:now();index:,sort:desc" json:"created_at"`
+	Sequence       *int64       `gorm:"uniqueIndex:idx_audit_logs_org_sequence,priority:2" json:"sequence,omitempty"` // 组织内的链序号，启用哈希链之前的记录为空
+	PrevHash       *string      `gorm:"type:varchar(64)" json:"prev_hash,omitempty"`                                  // 前一条记录的哈希
+	Hash           *string      `gorm:"type:varchar(64)" json:"hash,omitempty"`                                       // SHA-256(记录内容 + PrevHash)
 
 	// 关联
 	Organization *Organization `gorm:"foreignKey:OrganizationID" json:"organization,omitempty"`
@@ -39,3 +55,119 @@ type AuditLog struct {
 func (AuditLog) TableName() string {
 	return "audit_logs"
 }
+
+// auditHashPayload 参与哈希计算的字段，JSON字段顺序即序列化顺序
+type auditHashPayload struct {
+	Version        int             `json:"v"`
+	ID             string          `json:"id"`
+	OrganizationID string          `json:"organization_id"`
+	Sequence       int64           `json:"sequence"`
+	ActorID        *string         `json:"actor_id"`
+	Action         string          `json:"action"`
+	ResourceType   string          `json:"resource_type"`
+	ResourceID     string          `json:"resource_id"`
+	BeforeState    json.RawMessage `json:"before_state"`
+	AfterState     json.RawMessage `json:"after_state"`
+	IPAddress      *string         `json:"ip_address"`
+	UserAgent      *string         `json:"user_agent"`
+	CreatedAt      string          `json:"created_at"`
+	PrevHash       string          `json:"prev_hash"`
+}
+
+// ComputeHash 计算记录的链哈希（十六进制SHA-256）
+// 各字段先规范化为数据库读回后的形式（时间精度为微秒、JSONB重新序列化、IP地址标准写法），
+// 写入前与校验时对同一条记录得到相同结果
+func (a *AuditLog) ComputeHash() (string, error) {
+	if a.Sequence == nil || a.PrevHash == nil {
+		return "", fmt.Errorf("audit log %s is not chained", a.ID)
+	}
+
+	beforeState, err := canonicalState(a.BeforeState)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode before_state: %w", err)
+	}
+	afterState, err := canonicalState(a.AfterState)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode after_state: %w", err)
+	}
+
+	payload := auditHashPayload{
+		Version:        auditHashVersion,
+		ID:             a.ID.String(),
+		OrganizationID: a.OrganizationID.String(),
+		Sequence:       *a.Sequence,
+		Action:         a.Action,
+		ResourceType:   string(a.ResourceType),
+		ResourceID:     a.ResourceID.String(),
+		BeforeState:    beforeState,
+		AfterState:     afterState,
+		UserAgent:      a.UserAgent,
+		CreatedAt:      a.CreatedAt.UTC().Truncate(time.Microsecond).Format(time.RFC3339Nano),
+		PrevHash:       *a.PrevHash,
+	}
+	if a.ActorID != nil {
+		actorID := a.ActorID.String()
+		payload.ActorID = &actorID
+	}
+	if a.IPAddress != nil {
+		ip := canonicalIP(*a.IPAddress)
+		payload.IPAddress = &ip
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// canonicalState 将状态快照序列化为与JSONB读回后一致的形式
+func canonicalState(state *JSONB) (json.RawMessage, error) {
+	if state == nil {
+		return json.RawMessage("null"), nil
+	}
+	data, err := json.Marshal(state)
+	if err != nil {
+		return nil, err
+	}
+	// 写入前的值可能包含int、[]string等具体类型，经一次解码后与从数据库读回的值一致
+	var normalized interface{}
+	if err := json.Unmarshal(data, &normalized); err != nil {
+		return nil, err
+	}
+	return json.Marshal(normalized)
+}
+
+// canonicalIP 返回IP地址的标准写法（inet列读回时可能带掩码或改变大小写）
+func canonicalIP(addr string) string {
+	if ip := net.ParseIP(addr); ip != nil {
+		return ip.String()
+	}
+	if ip, _, err := net.ParseCIDR(addr); err == nil {
+		return ip.String()
+	}
+	return addr
+}
+
+// AuditCheckpoint 审计哈希链的签名检查点
+// 定期对每个组织的链头签名，校验时可发现检查点之后被截断或整条链被重算的情况
+type AuditCheckpoint struct {
+	ID             uuid.UUID `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	OrganizationID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_audit_checkpoints_org_sequence" json:"organization_id"`
+	Sequence       int64     `gorm:"not null;uniqueIndex:idx_audit_checkpoints_org_sequence" json:"sequence"`
+	Hash           string    `gorm:"type:varchar(64);not null" json:"hash"`
+	PublicKey      string    `gorm:"type:varchar(64);not null" json:"public_key"` // 签名公钥（Base64），用于密钥轮换后识别
+	Signature      string    `gorm:"type:text;not null" json:"signature"`         // Ed25519签名（Base64）
+	CreatedAt      time.Time `gorm:"not null;default:now()" json:"created_at"`
+}
+
+// TableName 指定表名
+func (AuditCheckpoint) TableName() string {
+	return "audit_checkpoints"
+}
+
+// SigningPayload 返回检查点签名覆盖的内容
+func (c *AuditCheckpoint) SigningPayload() []byte {
+	return []byte(fmt.Sprintf("edgelink-audit-checkpoint/v1\n%s\n%d\n%s", c.OrganizationID, c.Sequence, c.Hash))
+}
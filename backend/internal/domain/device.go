@@ -1,12 +1,23 @@
 package domain
 
 import (
+	"errors"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/lib/pq"
 )
 
+const (
+	// maxDeviceTags 单台设备的标签数上限
+	maxDeviceTags = 32
+
+	// maxDeviceTagLength 标签最大长度（与策略、规则中tag列的长度一致）
+	maxDeviceTagLength = 100
+)
+
 // NATType NAT类型枚举
 type NATType string
 
@@ -32,6 +43,15 @@ const (
 	PlatformContainer      Platform = "container"
 )
 
+// DeviceApprovalStatus 设备审批状态
+type DeviceApprovalStatus string
+
+const (
+	DeviceApprovalPending  DeviceApprovalStatus = "pending"  // 等待管理员审批，不出现在任何对端列表中
+	DeviceApprovalApproved DeviceApprovalStatus = "approved" // 已批准（未开启审批的网络中注册即批准）
+	DeviceApprovalRejected DeviceApprovalStatus = "rejected" // 已拒绝
+)
+
 // Device 设备实体
 type Device struct {
 	ID               uuid.UUID  `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
@@ -44,7 +64,22 @@ type Device struct {
 	PublicEndpoint   string          `gorm:"type:varchar(255)" json:"public_endpoint,omitempty"`
 	Tags             pq.StringArray  `gorm:"type:text[];default:'{}'" json:"tags,omitempty"`
 	Online           bool            `gorm:"not null;default:false;index" json:"online"`
+	Ephemeral        bool            `gorm:"not null;default:false" json:"ephemeral"` // 离线超过EPHEMERAL_DEVICE_OFFLINE_TTL后自动撤销，不产生离线告警
 	LastSeenAt       *time.Time `gorm:"index" json:"last_seen_at,omitempty"`
+	ClockSkewMs      *int64     `json:"clock_skew_ms,omitempty"` // 最近一次请求时间戳减服务器时间
+	ClockSkewAt      *time.Time `json:"clock_skew_at,omitempty"` // 时钟偏差测量时间
+	ApprovalStatus    DeviceApprovalStatus `gorm:"type:varchar(20);not null;default:'approved'" json:"approval_status"`
+	ApprovalDecidedAt *time.Time           `json:"approval_decided_at,omitempty"`
+	ApprovalDecidedBy *uuid.UUID           `gorm:"type:uuid" json:"approval_decided_by,omitempty"` // 为空且有规则ID时为自动批准
+	ApprovalRuleID    *uuid.UUID           `gorm:"type:uuid" json:"approval_rule_id,omitempty"`    // 命中的自动审批规则
+	ApprovalNote      string               `gorm:"type:text" json:"approval_note,omitempty"`
+	Posture                   *DevicePosture      `gorm:"type:jsonb" json:"posture,omitempty"` // 最近一次上报的安全状态
+	PostureReportedAt         *time.Time          `json:"posture_reported_at,omitempty"`
+	PostureStatus             DevicePostureStatus `gorm:"type:varchar(20);not null;default:'compliant'" json:"posture_status"`
+	PostureViolations         pq.StringArray      `gorm:"type:text[];default:'{}'" json:"posture_violations,omitempty"`
+	PostureAllowedPeerTags    pq.StringArray      `gorm:"type:text[];default:'{}'" json:"posture_allowed_peer_tags,omitempty"` // 不合规时仍可互通的对端标签（各组的并集），为空表示完全隔离
+	PostureAllowedPeerTagSets PeerTagSets         `gorm:"type:jsonb;default:'[]'" json:"posture_allowed_peer_tag_sets,omitempty"` // 每条违反的restrict策略一组，对端须满足每一组
+	PostureCheckedAt          *time.Time          `json:"posture_checked_at,omitempty"`
 	CreatedAt        time.Time  `gorm:"not null;default:now()" json:"created_at"`
 	UpdatedAt        time.Time  `gorm:"not null;default:now()" json:"updated_at"`
 
@@ -61,3 +96,73 @@ type Device struct {
 func (Device) TableName() string {
 	return "devices"
 }
+
+// IsApproved 设备是否已获批加入网络
+func (d *Device) IsApproved() bool {
+	return d.ApprovalStatus == "" || d.ApprovalStatus == DeviceApprovalApproved
+}
+
+// IsPostureCompliant 设备是否满足全部适用的安全状态策略
+func (d *Device) IsPostureCompliant() bool {
+	return d.PostureStatus == "" || d.PostureStatus == DevicePostureCompliant
+}
+
+// CanPeerWith 两台设备能否出现在彼此的对端列表中
+// 双方都须已获批准；不合规的一方只能与满足其每组允许标签、且自身合规的设备互通
+func (d *Device) CanPeerWith(peer *Device) bool {
+	if d.ID == peer.ID || !d.IsApproved() || !peer.IsApproved() {
+		return false
+	}
+	return d.postureAllows(peer) && peer.postureAllows(d)
+}
+
+// postureAllows 按本设备的安全状态判断是否允许与peer互通
+func (d *Device) postureAllows(peer *Device) bool {
+	if d.IsPostureCompliant() {
+		return true
+	}
+	if !peer.IsPostureCompliant() {
+		return false
+	}
+	return d.PostureAllowedPeerTagSets.Allows(peer.Tags)
+}
+
+// AuditState 审计记录中的设备状态快照
+func (d *Device) AuditState() JSONB {
+	tags := []string(d.Tags)
+	if tags == nil {
+		tags = []string{}
+	}
+	return JSONB{
+		"name":               d.Name,
+		"tags":               tags,
+		"virtual_network_id": d.VirtualNetworkID.String(),
+		"virtual_ip":         d.VirtualIP,
+		"approval_status":    string(d.ApprovalStatus),
+		"posture_status":     string(d.PostureStatus),
+	}
+}
+
+// NormalizeDeviceTags 去除标签首尾空白并去重（保持原有顺序）
+func NormalizeDeviceTags(tags []string) (pq.StringArray, error) {
+	normalized := make(pq.StringArray, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" {
+			return nil, errors.New("tags must not be empty")
+		}
+		if len(tag) > maxDeviceTagLength {
+			return nil, fmt.Errorf("tag %q exceeds %d characters", tag, maxDeviceTagLength)
+		}
+		if seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	if len(normalized) > maxDeviceTags {
+		return nil, fmt.Errorf("a device can have at most %d tags", maxDeviceTags)
+	}
+	return normalized, nil
+}
@@ -0,0 +1,61 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DeviceApprovalRule 设备自动审批规则
+// 在要求审批的虚拟网络中，注册所用的预共享密钥和/或设备标签匹配时直接批准；
+// 两者都设置时需同时匹配
+type DeviceApprovalRule struct {
+	ID               uuid.UUID  `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	VirtualNetworkID uuid.UUID  `gorm:"type:uuid;not null;index" json:"virtual_network_id"`
+	PreSharedKeyID   *uuid.UUID `gorm:"type:uuid" json:"pre_shared_key_id,omitempty"`
+	Tag              *string    `gorm:"type:varchar(100)" json:"tag,omitempty"`
+	Description      string     `gorm:"type:text" json:"description,omitempty"`
+	CreatedBy        *uuid.UUID `gorm:"type:uuid" json:"created_by,omitempty"`
+	CreatedAt        time.Time  `gorm:"not null;default:now()" json:"created_at"`
+}
+
+// TableName 指定表名
+func (DeviceApprovalRule) TableName() string {
+	return "device_approval_rules"
+}
+
+// Matches 检查规则是否批准使用该密钥、带有这些标签注册的设备
+func (r *DeviceApprovalRule) Matches(pskID uuid.UUID, tags []string) bool {
+	if r.PreSharedKeyID == nil && r.Tag == nil {
+		return false
+	}
+	if r.PreSharedKeyID != nil && *r.PreSharedKeyID != pskID {
+		return false
+	}
+	if r.Tag != nil {
+		for _, tag := range tags {
+			if tag == *r.Tag {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// Validate 校验匹配条件
+func (r *DeviceApprovalRule) Validate() error {
+	if r.Tag != nil {
+		tag := strings.TrimSpace(*r.Tag)
+		if tag == "" {
+			return errors.New("tag must not be empty")
+		}
+		r.Tag = &tag
+	}
+	if r.PreSharedKeyID == nil && r.Tag == nil {
+		return errors.New("at least one of pre_shared_key_id or tag is required")
+	}
+	return nil
+}
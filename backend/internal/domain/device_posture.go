@@ -0,0 +1,348 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// DevicePosture 设备上报的安全状态
+// 布尔项为nil表示客户端无法检测，要求该项的策略视为不满足
+type DevicePosture struct {
+	OSVersion       string            `json:"os_version,omitempty"`
+	ClientVersion   string            `json:"client_version,omitempty"`
+	FirewallEnabled *bool             `json:"firewall_enabled,omitempty"`
+	DiskEncrypted   *bool             `json:"disk_encrypted,omitempty"`
+	Attributes      map[string]string `json:"attributes,omitempty"` // 自定义键值，如内核版本、MDM注册状态
+}
+
+// Scan 实现sql.Scanner接口
+func (p *DevicePosture) Scan(value interface{}) error {
+	bytes, ok := value.([]byte)
+	if !ok {
+		return nil
+	}
+	return json.Unmarshal(bytes, p)
+}
+
+// Value 实现driver.Valuer接口
+func (p DevicePosture) Value() (driver.Value, error) {
+	return json.Marshal(p)
+}
+
+// DevicePostureStatus 设备安全状态评估结果
+type DevicePostureStatus string
+
+const (
+	DevicePostureCompliant    DevicePostureStatus = "compliant"     // 满足全部适用策略（或没有适用策略）
+	DevicePostureNonCompliant DevicePostureStatus = "non_compliant" // 不满足任一适用策略，按策略隔离或限制对端
+)
+
+// PostureEnforcement 不合规时的处置方式
+type PostureEnforcement string
+
+const (
+	PostureEnforcementQuarantine PostureEnforcement = "quarantine" // 从所有对端列表中移除
+	PostureEnforcementRestrict   PostureEnforcement = "restrict"   // 只能与带有允许标签的设备互通
+)
+
+// PosturePolicy 设备安全状态策略
+// 作用于虚拟网络内的全部设备，或其中带有指定标签的设备；可再按平台限定。
+// 设备需同时满足全部适用策略，未设置的要求项不检查
+type PosturePolicy struct {
+	ID                    uuid.UUID          `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	VirtualNetworkID      uuid.UUID          `gorm:"type:uuid;not null;index" json:"virtual_network_id"`
+	Name                  string             `gorm:"type:varchar(255);not null" json:"name"`
+	Tag                   *string            `gorm:"type:varchar(100)" json:"tag,omitempty"`     // 为空时作用于网络内全部设备
+	Platform              *Platform          `gorm:"type:varchar(50)" json:"platform,omitempty"` // 为空时作用于全部平台
+	Enabled               bool               `gorm:"not null;default:true" json:"enabled"`
+	MinClientVersion      *string            `gorm:"type:varchar(50)" json:"min_client_version,omitempty"`
+	MinOSVersion          *string            `gorm:"type:varchar(50)" json:"min_os_version,omitempty"` // 不同平台版本号不可比，建议与platform一起使用
+	RequireFirewall       bool               `gorm:"not null;default:false" json:"require_firewall"`
+	RequireDiskEncryption bool               `gorm:"not null;default:false" json:"require_disk_encryption"`
+	RequiredAttributes    JSONB              `gorm:"type:jsonb" json:"required_attributes,omitempty"` // 自定义属性须等于指定值
+	Enforcement           PostureEnforcement `gorm:"type:varchar(20);not null;default:'quarantine'" json:"enforcement"`
+	AllowedPeerTags       pq.StringArray     `gorm:"type:text[];default:'{}'" json:"allowed_peer_tags,omitempty"` // restrict时仍可互通的对端标签
+	Description           string             `gorm:"type:text" json:"description,omitempty"`
+	CreatedBy             *uuid.UUID         `gorm:"type:uuid" json:"created_by,omitempty"`
+	CreatedAt             time.Time          `gorm:"not null;default:now()" json:"created_at"`
+	UpdatedAt             time.Time          `gorm:"not null;default:now()" json:"updated_at"`
+}
+
+// TableName 指定表名
+func (PosturePolicy) TableName() string {
+	return "posture_policies"
+}
+
+// Applies 检查策略是否作用于该设备
+func (p *PosturePolicy) Applies(device *Device) bool {
+	if !p.Enabled || p.VirtualNetworkID != device.VirtualNetworkID {
+		return false
+	}
+	if p.Platform != nil && *p.Platform != device.Platform {
+		return false
+	}
+	return p.Tag == nil || hasTag(device.Tags, *p.Tag)
+}
+
+// Check 返回设备上报状态不满足的要求，全部满足时为空
+func (p *PosturePolicy) Check(posture *DevicePosture) []string {
+	if posture == nil {
+		posture = &DevicePosture{}
+	}
+
+	var violations []string
+	if p.MinClientVersion != nil && !versionAtLeast(posture.ClientVersion, *p.MinClientVersion) {
+		violations = append(violations, fmt.Sprintf("client_version %s is below %s", orUnknown(posture.ClientVersion), *p.MinClientVersion))
+	}
+	if p.MinOSVersion != nil && !versionAtLeast(posture.OSVersion, *p.MinOSVersion) {
+		violations = append(violations, fmt.Sprintf("os_version %s is below %s", orUnknown(posture.OSVersion), *p.MinOSVersion))
+	}
+	if p.RequireFirewall && (posture.FirewallEnabled == nil || !*posture.FirewallEnabled) {
+		violations = append(violations, "firewall is not enabled")
+	}
+	if p.RequireDiskEncryption && (posture.DiskEncrypted == nil || !*posture.DiskEncrypted) {
+		violations = append(violations, "disk encryption is not enabled")
+	}
+
+	keys := make([]string, 0, len(p.RequiredAttributes))
+	for key := range p.RequiredAttributes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		want := fmt.Sprint(p.RequiredAttributes[key])
+		if got, ok := posture.Attributes[key]; !ok || got != want {
+			violations = append(violations, fmt.Sprintf("attribute %s is %s, want %s", key, orUnknown(posture.Attributes[key]), want))
+		}
+	}
+
+	return violations
+}
+
+// Validate 校验策略
+func (p *PosturePolicy) Validate() error {
+	p.Name = strings.TrimSpace(p.Name)
+	if p.Name == "" {
+		return errors.New("name is required")
+	}
+	if p.Tag != nil {
+		tag := strings.TrimSpace(*p.Tag)
+		if tag == "" {
+			return errors.New("tag must not be empty")
+		}
+		p.Tag = &tag
+	}
+	if p.Platform != nil {
+		switch *p.Platform {
+		case PlatformDesktopLinux, PlatformDesktopWindows, PlatformDesktopMacOS,
+			PlatformMobileIOS, PlatformMobileAndroid, PlatformIoT, PlatformContainer:
+		default:
+			return fmt.Errorf("unsupported platform: %s", *p.Platform)
+		}
+	}
+	for field, version := range map[string]*string{
+		"min_client_version": p.MinClientVersion,
+		"min_os_version":     p.MinOSVersion,
+	} {
+		if version != nil && parseVersion(*version) == nil {
+			return fmt.Errorf("%s must be a dotted numeric version", field)
+		}
+	}
+	if p.MinClientVersion == nil && p.MinOSVersion == nil && !p.RequireFirewall &&
+		!p.RequireDiskEncryption && len(p.RequiredAttributes) == 0 {
+		return errors.New("at least one requirement is required")
+	}
+
+	switch p.Enforcement {
+	case "":
+		p.Enforcement = PostureEnforcementQuarantine
+	case PostureEnforcementQuarantine, PostureEnforcementRestrict:
+	default:
+		return fmt.Errorf("unsupported enforcement: %s", p.Enforcement)
+	}
+	if p.Enforcement == PostureEnforcementRestrict && len(p.AllowedPeerTags) == 0 {
+		return errors.New("allowed_peer_tags is required for restrict enforcement")
+	}
+	if p.Enforcement == PostureEnforcementQuarantine {
+		p.AllowedPeerTags = nil
+	}
+	return nil
+}
+
+// PeerTagSets 不合规设备仍可互通的对端标签组，每条违反的restrict策略一组
+// 对端须与每一组都至少有一个相同标签
+type PeerTagSets [][]string
+
+// Scan 实现sql.Scanner接口
+func (s *PeerTagSets) Scan(value interface{}) error {
+	bytes, ok := value.([]byte)
+	if !ok {
+		return nil
+	}
+	return json.Unmarshal(bytes, s)
+}
+
+// Value 实现driver.Valuer接口
+func (s PeerTagSets) Value() (driver.Value, error) {
+	if s == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(s)
+}
+
+// Allows 对端标签是否满足每一组，没有任何组时表示完全隔离
+func (s PeerTagSets) Allows(tags []string) bool {
+	if len(s) == 0 {
+		return false
+	}
+	for _, set := range s {
+		matched := false
+		for _, tag := range set {
+			if hasTag(tags, tag) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
+
+// Equal 两组标签是否相同（组的顺序按策略顺序，视为有意义）
+func (s PeerTagSets) Equal(other PeerTagSets) bool {
+	if len(s) != len(other) {
+		return false
+	}
+	for i := range s {
+		if strings.Join(s[i], ",") != strings.Join(other[i], ",") {
+			return false
+		}
+	}
+	return true
+}
+
+// PostureEvaluation 设备安全状态评估结果
+type PostureEvaluation struct {
+	Status             DevicePostureStatus
+	Violations         []string    // 前缀为违反的策略名称
+	AllowedPeerTags    []string    // 各组允许标签的并集，仅用于展示，为空表示完全隔离
+	AllowedPeerTagSets PeerTagSets // 每条违反的restrict策略允许的对端标签，对端须满足每一组
+}
+
+// EvaluatePosture 按适用策略评估设备上报的安全状态
+// 违反任一quarantine策略时完全隔离；只违反restrict策略时，对端须带有每条违反策略允许的标签之一
+func EvaluatePosture(device *Device, policies []*PosturePolicy) PostureEvaluation {
+	result := PostureEvaluation{Status: DevicePostureCompliant}
+
+	quarantined := false
+	var sets PeerTagSets
+	for _, policy := range policies {
+		if !policy.Applies(device) {
+			continue
+		}
+		violations := policy.Check(device.Posture)
+		if len(violations) == 0 {
+			continue
+		}
+
+		result.Status = DevicePostureNonCompliant
+		for _, v := range violations {
+			result.Violations = append(result.Violations, policy.Name+": "+v)
+		}
+
+		if policy.Enforcement != PostureEnforcementRestrict {
+			quarantined = true
+			continue
+		}
+		set := append([]string(nil), policy.AllowedPeerTags...)
+		sort.Strings(set)
+		sets = append(sets, set)
+	}
+
+	if !quarantined && len(sets) > 0 {
+		union := make(map[string]bool)
+		for _, set := range sets {
+			for _, tag := range set {
+				union[tag] = true
+			}
+		}
+		for tag := range union {
+			result.AllowedPeerTags = append(result.AllowedPeerTags, tag)
+		}
+		sort.Strings(result.AllowedPeerTags)
+		result.AllowedPeerTagSets = sets
+	}
+	return result
+}
+
+// versionAtLeast 比较点分数字版本号，无法解析的版本视为不满足
+func versionAtLeast(version, minimum string) bool {
+	have, want := parseVersion(version), parseVersion(minimum)
+	if have == nil || want == nil {
+		return false
+	}
+	for i := 0; i < len(have) || i < len(want); i++ {
+		var a, b int
+		if i < len(have) {
+			a = have[i]
+		}
+		if i < len(want) {
+			b = want[i]
+		}
+		if a != b {
+			return a > b
+		}
+	}
+	return true
+}
+
+// parseVersion 解析"v1.4.2"、"22.04"、"10.0.19045-rc1"等版本号的数字部分
+func parseVersion(version string) []int {
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	if i := strings.IndexAny(version, "-+ "); i >= 0 {
+		version = version[:i]
+	}
+	if version == "" {
+		return nil
+	}
+
+	parts := strings.Split(version, ".")
+	numbers := make([]int, 0, len(parts))
+	for _, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return nil
+		}
+		numbers = append(numbers, n)
+	}
+	return numbers
+}
+
+// hasTag 检查标签列表中是否包含指定标签
+func hasTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// orUnknown 空值显示为unknown
+func orUnknown(value string) string {
+	if value == "" {
+		return "unknown"
+	}
+	return value
+}
@@ -0,0 +1,69 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+func newRestrictPolicy(vnID uuid.UUID, name string, allowed ...string) *PosturePolicy {
+	return &PosturePolicy{
+		VirtualNetworkID: vnID,
+		Name:             name,
+		Enabled:          true,
+		RequireFirewall:  true,
+		Enforcement:      PostureEnforcementRestrict,
+		AllowedPeerTags:  pq.StringArray(allowed),
+	}
+}
+
+// TestRestrictPoliciesRequireEveryTagSet 违反多条restrict策略时，对端须满足每条策略的允许标签
+func TestRestrictPoliciesRequireEveryTagSet(t *testing.T) {
+	vnID := uuid.New()
+	device := &Device{ID: uuid.New(), VirtualNetworkID: vnID, Posture: &DevicePosture{}}
+	policies := []*PosturePolicy{
+		newRestrictPolicy(vnID, "ci-only", "ci"),
+		newRestrictPolicy(vnID, "admin-only", "admin"),
+	}
+
+	result := EvaluatePosture(device, policies)
+	if result.Status != DevicePostureNonCompliant {
+		t.Fatalf("status = %s, want %s", result.Status, DevicePostureNonCompliant)
+	}
+	if len(result.AllowedPeerTagSets) != 2 {
+		t.Fatalf("allowed tag sets = %v, want one per violated policy", result.AllowedPeerTagSets)
+	}
+
+	device.PostureStatus = result.Status
+	device.PostureAllowedPeerTagSets = result.AllowedPeerTagSets
+
+	cases := []struct {
+		tags []string
+		want bool
+	}{
+		{[]string{"ci", "admin"}, true},
+		{[]string{"ci"}, false},
+		{[]string{"admin"}, false},
+		{nil, false},
+	}
+	for _, tc := range cases {
+		peer := &Device{ID: uuid.New(), VirtualNetworkID: vnID, Tags: pq.StringArray(tc.tags)}
+		if got := device.CanPeerWith(peer); got != tc.want {
+			t.Errorf("CanPeerWith(tags=%v) = %v, want %v", tc.tags, got, tc.want)
+		}
+	}
+}
+
+// TestQuarantineClearsAllowedTagSets 同时违反quarantine策略时完全隔离
+func TestQuarantineClearsAllowedTagSets(t *testing.T) {
+	vnID := uuid.New()
+	device := &Device{ID: uuid.New(), VirtualNetworkID: vnID, Posture: &DevicePosture{}}
+	quarantine := newRestrictPolicy(vnID, "strict")
+	quarantine.Enforcement = PostureEnforcementQuarantine
+
+	result := EvaluatePosture(device, []*PosturePolicy{newRestrictPolicy(vnID, "ci-only", "ci"), quarantine})
+	if len(result.AllowedPeerTagSets) != 0 || len(result.AllowedPeerTags) != 0 {
+		t.Errorf("allowed tags = %v / %v, want none", result.AllowedPeerTags, result.AllowedPeerTagSets)
+	}
+}
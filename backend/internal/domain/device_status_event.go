@@ -0,0 +1,20 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DeviceStatusEvent 设备上下线状态变化记录，用于检测隧道抖动
+type DeviceStatusEvent struct {
+	ID         int64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	DeviceID   uuid.UUID `gorm:"type:uuid;not null;index:idx_device_status_events_device,priority:1" json:"device_id"`
+	Online     bool      `gorm:"not null" json:"online"`
+	OccurredAt time.Time `gorm:"not null;default:now();index;index:idx_device_status_events_device,priority:2" json:"occurred_at"`
+}
+
+// TableName 指定表名
+func (DeviceStatusEvent) TableName() string {
+	return "device_status_events"
+}
@@ -0,0 +1,150 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EscalationTargetType 升级目标类型
+type EscalationTargetType string
+
+const (
+	EscalationTargetSchedule EscalationTargetType = "schedule" // 通知排班表当前值班人员
+	EscalationTargetUser     EscalationTargetType = "user"     // 直接通知指定管理员
+)
+
+// EscalationTarget 升级目标
+type EscalationTarget struct {
+	Type EscalationTargetType `json:"type"`
+	ID   uuid.UUID            `json:"id"`
+}
+
+// EscalationLevel 升级级别
+type EscalationLevel struct {
+	DelayMinutes int                `json:"delay_minutes"` // 通知本级后等待多久升级到下一级
+	Targets      []EscalationTarget `json:"targets"`
+}
+
+// EscalationLevels 升级级别列表（以JSONB存储，按顺序逐级升级）
+type EscalationLevels []EscalationLevel
+
+// Scan 实现sql.Scanner接口
+func (l *EscalationLevels) Scan(value interface{}) error {
+	bytes, ok := value.([]byte)
+	if !ok {
+		return nil
+	}
+	return json.Unmarshal(bytes, l)
+}
+
+// Value 实现driver.Valuer接口
+func (l EscalationLevels) Value() (driver.Value, error) {
+	return json.Marshal(l)
+}
+
+// EscalationPolicy 升级策略实体
+type EscalationPolicy struct {
+	ID             uuid.UUID        `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	OrganizationID uuid.UUID        `gorm:"type:uuid;not null;index" json:"organization_id"`
+	Name           string           `gorm:"type:varchar(255);not null" json:"name"`
+	Description    string           `gorm:"type:text" json:"description,omitempty"`
+	Levels         EscalationLevels `gorm:"type:jsonb;not null" json:"levels"`
+	RepeatCount    int              `gorm:"not null;default:0" json:"repeat_count"` // 最后一级后从第一级重新开始的次数
+	CreatedAt      time.Time        `gorm:"not null;default:now()" json:"created_at"`
+	UpdatedAt      time.Time        `gorm:"not null;default:now()" json:"updated_at"`
+}
+
+// TableName 指定表名
+func (EscalationPolicy) TableName() string {
+	return "escalation_policies"
+}
+
+// Validate 校验升级策略定义
+func (p *EscalationPolicy) Validate() error {
+	if strings.TrimSpace(p.Name) == "" {
+		return errors.New("name is required")
+	}
+	if len(p.Levels) == 0 {
+		return errors.New("at least one level is required")
+	}
+	if p.RepeatCount < 0 {
+		return errors.New("repeat_count must be non-negative")
+	}
+
+	for i, level := range p.Levels {
+		if len(level.Targets) == 0 {
+			return fmt.Errorf("level %d: at least one target is required", i)
+		}
+		if level.DelayMinutes < 1 {
+			return fmt.Errorf("level %d: delay_minutes must be at least 1", i)
+		}
+		for j, target := range level.Targets {
+			switch target.Type {
+			case EscalationTargetSchedule, EscalationTargetUser:
+			default:
+				return fmt.Errorf("level %d target %d: unsupported type %q", i, j, target.Type)
+			}
+			if target.ID == uuid.Nil {
+				return fmt.Errorf("level %d target %d: id is required", i, j)
+			}
+		}
+	}
+	return nil
+}
+
+// TargetIDs 按类型收集策略引用的所有目标ID
+func (p *EscalationPolicy) TargetIDs(targetType EscalationTargetType) []uuid.UUID {
+	ids := make([]uuid.UUID, 0)
+	for _, level := range p.Levels {
+		for _, target := range level.Targets {
+			if target.Type == targetType {
+				ids = append(ids, target.ID)
+			}
+		}
+	}
+	return ids
+}
+
+// EscalationStatus 告警升级状态
+type EscalationStatus string
+
+const (
+	EscalationStatusActive    EscalationStatus = "active"
+	EscalationStatusCompleted EscalationStatus = "completed" // 所有级别与重复次数已用完
+	EscalationStatusStopped   EscalationStatus = "stopped"   // 告警被确认/解决或规则被移除
+)
+
+// AlertEscalation 告警升级进度（持久化，服务重启后继续而不重复通知）
+type AlertEscalation struct {
+	ID             uuid.UUID        `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	AlertID        uuid.UUID        `gorm:"type:uuid;not null;uniqueIndex:idx_alert_escalations_alert_rule" json:"alert_id"`
+	RuleID         string           `gorm:"type:varchar(255);not null;uniqueIndex:idx_alert_escalations_alert_rule" json:"rule_id"`
+	PolicyID       *uuid.UUID       `gorm:"type:uuid;index" json:"policy_id,omitempty"`
+	Status         EscalationStatus `gorm:"type:varchar(20);not null;default:'active';index" json:"status"`
+	Level          int              `gorm:"not null;default:0" json:"level"` // 下一次通知的级别
+	Cycle          int              `gorm:"not null;default:0" json:"cycle"` // 已完成的完整轮次
+	NotifyCount    int              `gorm:"not null;default:0" json:"notify_count"`
+	NextRunAt      time.Time        `gorm:"not null;index" json:"next_run_at"`
+	LockedUntil    *time.Time       `json:"-"`
+	LastNotifiedAt *time.Time       `json:"last_notified_at,omitempty"`
+	StopReason     string           `gorm:"type:varchar(50)" json:"stop_reason,omitempty"`
+	CreatedAt      time.Time        `gorm:"not null;default:now()" json:"created_at"`
+	UpdatedAt      time.Time        `gorm:"not null;default:now()" json:"updated_at"`
+}
+
+// TableName 指定表名
+func (AlertEscalation) TableName() string {
+	return "alert_escalations"
+}
+
+// Stop 结束升级
+func (e *AlertEscalation) Stop(status EscalationStatus, reason string) {
+	e.Status = status
+	e.StopReason = reason
+}
@@ -0,0 +1,44 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ExternalProvider 外部平台枚举
+type ExternalProvider string
+
+const (
+	ExternalProviderSlack     ExternalProvider = "slack"
+	ExternalProviderPagerDuty ExternalProvider = "pagerduty"
+	ExternalProviderOpsgenie  ExternalProvider = "opsgenie"
+	ExternalProviderTeams     ExternalProvider = "teams"
+)
+
+// IsValid 检查外部平台是否受支持
+func (p ExternalProvider) IsValid() bool {
+	switch p {
+	case ExternalProviderSlack, ExternalProviderPagerDuty, ExternalProviderOpsgenie, ExternalProviderTeams:
+		return true
+	}
+	return false
+}
+
+// ExternalIdentity 外部平台用户与管理员的映射（用于聊天/值班工具回调时识别操作者）
+type ExternalIdentity struct {
+	ID          uuid.UUID        `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	AdminUserID uuid.UUID        `gorm:"type:uuid;not null;index" json:"admin_user_id"`
+	Provider    ExternalProvider `gorm:"type:varchar(50);not null;uniqueIndex:idx_external_identities_provider_external_id" json:"provider"`
+	ExternalID  string           `gorm:"type:varchar(255);not null;uniqueIndex:idx_external_identities_provider_external_id" json:"external_id"` // Slack用户ID、PagerDuty用户ID等
+	DisplayName string           `gorm:"type:varchar(255)" json:"display_name,omitempty"`
+	CreatedAt   time.Time        `gorm:"not null;default:now()" json:"created_at"`
+
+	// 关联
+	AdminUser *AdminUser `gorm:"foreignKey:AdminUserID" json:"admin_user,omitempty"`
+}
+
+// TableName 指定表名
+func (ExternalIdentity) TableName() string {
+	return "external_identities"
+}
@@ -0,0 +1,74 @@
+package domain
+
+import (
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LinkMetricType 链路指标类型枚举
+type LinkMetricType string
+
+const (
+	LinkMetricLatency    LinkMetricType = "latency"     // 延迟（毫秒）
+	LinkMetricPacketLoss LinkMetricType = "packet_loss" // 丢包率（0-1）
+)
+
+// LinkMetric 设备上报的单条链路指标样本（设备 -> 对端）
+type LinkMetric struct {
+	ID           int64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	DeviceID     uuid.UUID `gorm:"type:uuid;not null;index:idx_link_metrics_pair,priority:1" json:"device_id"`
+	PeerDeviceID uuid.UUID `gorm:"type:uuid;not null;index:idx_link_metrics_pair,priority:2" json:"peer_device_id"`
+	LatencyMs    *int      `json:"latency_ms,omitempty"`
+	PacketLoss   *float64  `json:"packet_loss,omitempty"`
+	RecordedAt   time.Time `gorm:"not null;default:now();index;index:idx_link_metrics_pair,priority:3" json:"recorded_at"`
+}
+
+// TableName 指定表名
+func (LinkMetric) TableName() string {
+	return "link_metrics"
+}
+
+// Value 返回指定指标的取值
+func (m *LinkMetric) Value(metric LinkMetricType) (float64, bool) {
+	switch metric {
+	case LinkMetricLatency:
+		if m.LatencyMs != nil {
+			return float64(*m.LatencyMs), true
+		}
+	case LinkMetricPacketLoss:
+		if m.PacketLoss != nil {
+			return *m.PacketLoss, true
+		}
+	}
+	return 0, false
+}
+
+// LinkBaseline 设备对单项指标的自适应基线（指数加权均值与方差）
+type LinkBaseline struct {
+	DeviceID       uuid.UUID      `gorm:"type:uuid;primaryKey" json:"device_id"`
+	PeerDeviceID   uuid.UUID      `gorm:"type:uuid;primaryKey" json:"peer_device_id"`
+	Metric         LinkMetricType `gorm:"type:varchar(20);primaryKey" json:"metric"`
+	Mean           float64        `gorm:"not null;default:0" json:"mean"`
+	Variance       float64        `gorm:"not null;default:0" json:"variance"`
+	SampleCount    int            `gorm:"not null;default:0" json:"sample_count"`
+	LastValue      *float64       `json:"last_value,omitempty"`
+	LastSampleAt   *time.Time     `json:"last_sample_at,omitempty"`
+	AnomalousSince *time.Time     `json:"anomalous_since,omitempty"` // 持续偏离基线的起始时间
+	Alerting       bool           `gorm:"not null;default:false" json:"alerting"`
+	UpdatedAt      time.Time      `gorm:"not null;default:now()" json:"updated_at"`
+}
+
+// TableName 指定表名
+func (LinkBaseline) TableName() string {
+	return "link_baselines"
+}
+
+// StdDev 基线标准差
+func (b *LinkBaseline) StdDev() float64 {
+	if b.Variance <= 0 {
+		return 0
+	}
+	return math.Sqrt(b.Variance)
+}
@@ -0,0 +1,67 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DeliveryStatus 通知投递状态枚举
+type DeliveryStatus string
+
+const (
+	DeliveryStatusPending  DeliveryStatus = "pending"
+	DeliveryStatusRetrying DeliveryStatus = "retrying"
+	DeliveryStatusSent     DeliveryStatus = "sent"
+	DeliveryStatusDead     DeliveryStatus = "dead" // 重试耗尽，进入死信列表
+)
+
+// NotificationDelivery 通知投递任务（持久化发件箱，服务重启后继续投递）
+type NotificationDelivery struct {
+	ID            uuid.UUID      `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	AlertID       uuid.UUID      `gorm:"type:uuid;not null;index" json:"alert_id"`
+	RuleID        string         `gorm:"type:varchar(255);not null" json:"rule_id"`
+	Channel       string         `gorm:"type:varchar(50);not null;index" json:"channel"`
+	Target        string         `gorm:"type:text" json:"target,omitempty"`
+	Escalation    bool           `gorm:"not null;default:false" json:"escalation"`
+	GroupID       *uuid.UUID     `gorm:"type:uuid;index" json:"group_id,omitempty"` // 分组摘要通知所属的告警分组
+	Action        JSONB          `gorm:"type:jsonb;not null" json:"-"`              // 动作定义（含凭据，不对外暴露）
+	Status        DeliveryStatus `gorm:"type:varchar(20);not null;default:'pending';index" json:"status"`
+	Attempts      int            `gorm:"not null;default:0" json:"attempts"`
+	MaxAttempts   int            `gorm:"not null;default:1" json:"max_attempts"`
+	RedriveCount  int            `gorm:"not null;default:0" json:"redrive_count"`
+	NextAttemptAt time.Time      `gorm:"not null;default:now();index" json:"next_attempt_at"`
+	LockedUntil   *time.Time     `json:"-"`
+	LastError     *string        `gorm:"type:text" json:"last_error,omitempty"`
+	SentAt        *time.Time     `json:"sent_at,omitempty"`
+	DeadAt        *time.Time     `json:"dead_at,omitempty"`
+	TraceContext  JSONB          `gorm:"type:jsonb" json:"-"` // 入队时的W3C追踪上下文
+	CreatedAt     time.Time      `gorm:"not null;default:now();index" json:"created_at"`
+	UpdatedAt     time.Time      `gorm:"not null;default:now()" json:"updated_at"`
+
+	// 关联
+	DeliveryAttempts []NotificationAttempt `gorm:"foreignKey:DeliveryID" json:"attempt_log,omitempty"`
+}
+
+// TableName 指定表名
+func (NotificationDelivery) TableName() string {
+	return "notification_deliveries"
+}
+
+// NotificationAttempt 单次投递尝试记录（不可变）
+type NotificationAttempt struct {
+	ID         uuid.UUID `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	DeliveryID uuid.UUID `gorm:"type:uuid;not null;index" json:"delivery_id"`
+	AlertID    uuid.UUID `gorm:"type:uuid;not null;index" json:"alert_id"`
+	Channel    string    `gorm:"type:varchar(50);not null" json:"channel"`
+	Attempt    int       `gorm:"not null" json:"attempt"`
+	Success    bool      `gorm:"not null" json:"success"`
+	LatencyMs  int64     `gorm:"not null" json:"latency_ms"`
+	Error      *string   `gorm:"type:text" json:"error,omitempty"`
+	CreatedAt  time.Time `gorm:"not null;default:now();index" json:"created_at"`
+}
+
+// TableName 指定表名
+func (NotificationAttempt) TableName() string {
+	return "notification_attempts"
+}
@@ -0,0 +1,66 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RuleChangeType 通知规则变更类型
+type RuleChangeType string
+
+const (
+	RuleChangeCreate   RuleChangeType = "create"
+	RuleChangeUpdate   RuleChangeType = "update"
+	RuleChangeEnable   RuleChangeType = "enable"
+	RuleChangeDisable  RuleChangeType = "disable"
+	RuleChangeDelete   RuleChangeType = "delete"
+	RuleChangeRollback RuleChangeType = "rollback"
+	RuleChangeImport   RuleChangeType = "import"
+)
+
+// NotificationRule 组织级通知规则（规则定义以JSONB存储，结构与YAML规则一致）
+type NotificationRule struct {
+	ID             uuid.UUID  `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	OrganizationID uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:idx_notification_rules_org_key" json:"organization_id"`
+	RuleKey        string     `gorm:"type:varchar(100);not null;uniqueIndex:idx_notification_rules_org_key" json:"rule_id"`
+	Name           string     `gorm:"type:varchar(255);not null" json:"name"`
+	Enabled        bool       `gorm:"not null;default:true;index" json:"enabled"`
+	Priority       int        `gorm:"not null;default:100" json:"priority"`
+	Definition     JSONB      `gorm:"type:jsonb;not null" json:"definition"`
+	Version        int        `gorm:"not null;default:1" json:"version"`
+	CreatedBy      *uuid.UUID `gorm:"type:uuid" json:"created_by,omitempty"`
+	UpdatedBy      *uuid.UUID `gorm:"type:uuid" json:"updated_by,omitempty"`
+	CreatedAt      time.Time  `gorm:"not null;default:now()" json:"created_at"`
+	UpdatedAt      time.Time  `gorm:"not null;default:now()" json:"updated_at"`
+	DeletedAt      *time.Time `gorm:"index" json:"deleted_at,omitempty"`
+}
+
+// TableName 指定表名
+func (NotificationRule) TableName() string {
+	return "notification_rules"
+}
+
+// IsDeleted 检查规则是否已删除
+func (r *NotificationRule) IsDeleted() bool {
+	return r.DeletedAt != nil
+}
+
+// NotificationRuleVersion 通知规则版本快照（不可变）
+type NotificationRuleVersion struct {
+	ID                 uuid.UUID      `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	NotificationRuleID uuid.UUID      `gorm:"type:uuid;not null;uniqueIndex:idx_notification_rule_versions_rule_version" json:"notification_rule_id"`
+	OrganizationID     uuid.UUID      `gorm:"type:uuid;not null;index" json:"organization_id"`
+	Version            int            `gorm:"not null;uniqueIndex:idx_notification_rule_versions_rule_version" json:"version"`
+	ChangeType         RuleChangeType `gorm:"type:varchar(20);not null" json:"change_type"`
+	Enabled            bool           `gorm:"not null" json:"enabled"`
+	Definition         JSONB          `gorm:"type:jsonb;not null" json:"definition"`
+	ChangedBy          *uuid.UUID     `gorm:"type:uuid" json:"changed_by,omitempty"`
+	Comment            string         `gorm:"type:text" json:"comment,omitempty"`
+	CreatedAt          time.Time      `gorm:"not null;default:now()" json:"created_at"`
+}
+
+// TableName 指定表名
+func (NotificationRuleVersion) TableName() string {
+	return "notification_rule_versions"
+}
@@ -0,0 +1,307 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RotationType 轮换周期类型
+type RotationType string
+
+const (
+	RotationDaily  RotationType = "daily"
+	RotationWeekly RotationType = "weekly"
+	RotationCustom RotationType = "custom" // 按ShiftLengthHours轮换
+)
+
+// OnCallRestriction 值班层的生效时间窗口（窗口外该层无人值班）
+type OnCallRestriction struct {
+	Weekdays  []string `json:"weekdays,omitempty"` // Monday, Tuesday, etc.，为空表示每天
+	StartTime string   `json:"start"`              // HH:MM格式，排班时区
+	EndTime   string   `json:"end"`                // HH:MM格式，早于start表示跨午夜
+}
+
+// OnCallLayer 值班层：一组人员按固定周期轮换
+type OnCallLayer struct {
+	Name             string             `json:"name"`
+	Users            []uuid.UUID        `json:"users"` // 轮换顺序
+	RotationType     RotationType       `json:"rotation_type"`
+	ShiftLengthHours int                `json:"shift_length_hours,omitempty"` // 仅custom使用
+	StartDate        string             `json:"start_date"`                   // YYYY-MM-DD，第一班开始日期
+	HandoffTime      string             `json:"handoff_time"`                 // HH:MM，交接时间
+	Restriction      *OnCallRestriction `json:"restriction,omitempty"`
+}
+
+// OnCallLayers 值班层列表（以JSONB存储，列表中靠后的层优先级更高）
+type OnCallLayers []OnCallLayer
+
+// Scan 实现sql.Scanner接口
+func (l *OnCallLayers) Scan(value interface{}) error {
+	bytes, ok := value.([]byte)
+	if !ok {
+		return nil
+	}
+	return json.Unmarshal(bytes, l)
+}
+
+// Value 实现driver.Valuer接口
+func (l OnCallLayers) Value() (driver.Value, error) {
+	return json.Marshal(l)
+}
+
+// OnCallSchedule 值班排班表实体
+type OnCallSchedule struct {
+	ID             uuid.UUID    `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	OrganizationID uuid.UUID    `gorm:"type:uuid;not null;index" json:"organization_id"`
+	Name           string       `gorm:"type:varchar(255);not null" json:"name"`
+	Description    string       `gorm:"type:text" json:"description,omitempty"`
+	Timezone       string       `gorm:"type:varchar(64);not null;default:'UTC'" json:"timezone"`
+	Layers         OnCallLayers `gorm:"type:jsonb;not null" json:"layers"`
+	CreatedAt      time.Time    `gorm:"not null;default:now()" json:"created_at"`
+	UpdatedAt      time.Time    `gorm:"not null;default:now()" json:"updated_at"`
+
+	// 关联
+	Overrides []OnCallOverride `gorm:"foreignKey:ScheduleID" json:"overrides,omitempty"`
+}
+
+// TableName 指定表名
+func (OnCallSchedule) TableName() string {
+	return "oncall_schedules"
+}
+
+// OnCallOverride 临时替班：时间区间内由指定人员值班，优先于所有值班层
+type OnCallOverride struct {
+	ID         uuid.UUID  `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	ScheduleID uuid.UUID  `gorm:"type:uuid;not null;index" json:"schedule_id"`
+	UserID     uuid.UUID  `gorm:"type:uuid;not null" json:"user_id"`
+	StartsAt   time.Time  `gorm:"not null;index" json:"starts_at"`
+	EndsAt     time.Time  `gorm:"not null;index" json:"ends_at"`
+	Reason     string     `gorm:"type:text" json:"reason,omitempty"`
+	CreatedBy  *uuid.UUID `gorm:"type:uuid" json:"created_by,omitempty"`
+	CreatedAt  time.Time  `gorm:"not null;default:now()" json:"created_at"`
+}
+
+// TableName 指定表名
+func (OnCallOverride) TableName() string {
+	return "oncall_overrides"
+}
+
+// OnCallShift 某一时刻的值班结果
+type OnCallShift struct {
+	UserID   uuid.UUID `json:"user_id"`
+	Layer    string    `json:"layer,omitempty"`
+	Override bool      `json:"override"`
+	StartsAt time.Time `json:"starts_at"`
+	EndsAt   time.Time `json:"ends_at"`
+}
+
+// Validate 校验排班定义
+func (s *OnCallSchedule) Validate() error {
+	if strings.TrimSpace(s.Name) == "" {
+		return errors.New("name is required")
+	}
+	if _, err := time.LoadLocation(s.Timezone); err != nil {
+		return fmt.Errorf("invalid timezone: %w", err)
+	}
+	if len(s.Layers) == 0 {
+		return errors.New("at least one layer is required")
+	}
+
+	for i, layer := range s.Layers {
+		if err := layer.validate(); err != nil {
+			return fmt.Errorf("layer %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// UserIDs 返回排班中出现的所有人员（去重）
+func (s *OnCallSchedule) UserIDs() []uuid.UUID {
+	seen := make(map[uuid.UUID]bool)
+	ids := make([]uuid.UUID, 0)
+	for _, layer := range s.Layers {
+		for _, id := range layer.Users {
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+	}
+	return ids
+}
+
+// OnCallAt 计算指定时刻的值班人员：替班优先，其次从最后一层向前查找
+func (s *OnCallSchedule) OnCallAt(at time.Time) (*OnCallShift, bool) {
+	for _, o := range s.Overrides {
+		if !at.Before(o.StartsAt) && at.Before(o.EndsAt) {
+			return &OnCallShift{
+				UserID:   o.UserID,
+				Override: true,
+				StartsAt: o.StartsAt,
+				EndsAt:   o.EndsAt,
+			}, true
+		}
+	}
+
+	loc, err := time.LoadLocation(s.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+
+	for i := len(s.Layers) - 1; i >= 0; i-- {
+		if shift, ok := s.Layers[i].shiftAt(at.In(loc)); ok {
+			return shift, true
+		}
+	}
+	return nil, false
+}
+
+// validate 校验值班层定义
+func (l *OnCallLayer) validate() error {
+	if len(l.Users) == 0 {
+		return errors.New("at least one user is required")
+	}
+	if _, err := time.Parse("2006-01-02", l.StartDate); err != nil {
+		return fmt.Errorf("invalid start_date (expected YYYY-MM-DD): %w", err)
+	}
+	if _, err := time.Parse("15:04", l.HandoffTime); err != nil {
+		return fmt.Errorf("invalid handoff_time (expected HH:MM): %w", err)
+	}
+
+	switch l.RotationType {
+	case RotationDaily, RotationWeekly:
+	case RotationCustom:
+		if l.ShiftLengthHours <= 0 {
+			return errors.New("shift_length_hours must be positive for custom rotations")
+		}
+	default:
+		return fmt.Errorf("unsupported rotation_type %q", l.RotationType)
+	}
+
+	if r := l.Restriction; r != nil {
+		if _, err := time.Parse("15:04", r.StartTime); err != nil {
+			return fmt.Errorf("invalid restriction start (expected HH:MM): %w", err)
+		}
+		if _, err := time.Parse("15:04", r.EndTime); err != nil {
+			return fmt.Errorf("invalid restriction end (expected HH:MM): %w", err)
+		}
+		for _, wd := range r.Weekdays {
+			if _, ok := parseWeekday(wd); !ok {
+				return fmt.Errorf("invalid restriction weekday: %s", wd)
+			}
+		}
+	}
+	return nil
+}
+
+// shiftAt 计算值班层在指定时刻（已转换到排班时区）的班次
+func (l *OnCallLayer) shiftAt(at time.Time) (*OnCallShift, bool) {
+	if len(l.Users) == 0 {
+		return nil, false
+	}
+
+	date, err := time.ParseInLocation("2006-01-02", l.StartDate, at.Location())
+	if err != nil {
+		return nil, false
+	}
+	handoff, err := time.Parse("15:04", l.HandoffTime)
+	if err != nil {
+		return nil, false
+	}
+	anchor := time.Date(date.Year(), date.Month(), date.Day(), handoff.Hour(), handoff.Minute(), 0, 0, at.Location())
+	if at.Before(anchor) {
+		return nil, false
+	}
+
+	// 按日历日推进班次，保证夏令时切换时交接时间不漂移
+	var shiftStart, shiftEnd time.Time
+	var index int
+	switch l.RotationType {
+	case RotationCustom:
+		length := time.Duration(l.ShiftLengthHours) * time.Hour
+		index = int(at.Sub(anchor) / length)
+		shiftStart = anchor.Add(time.Duration(index) * length)
+		shiftEnd = shiftStart.Add(length)
+	default:
+		days := 1
+		if l.RotationType == RotationWeekly {
+			days = 7
+		}
+		index = int(at.Sub(anchor).Hours()/24) / days
+		shiftStart = anchor.AddDate(0, 0, index*days)
+		for shiftStart.After(at) {
+			index--
+			shiftStart = anchor.AddDate(0, 0, index*days)
+		}
+		for !anchor.AddDate(0, 0, (index+1)*days).After(at) {
+			index++
+		}
+		shiftStart = anchor.AddDate(0, 0, index*days)
+		shiftEnd = anchor.AddDate(0, 0, (index+1)*days)
+	}
+
+	if l.Restriction != nil && !l.Restriction.contains(at) {
+		return nil, false
+	}
+
+	return &OnCallShift{
+		UserID:   l.Users[index%len(l.Users)],
+		Layer:    l.Name,
+		StartsAt: shiftStart,
+		EndsAt:   shiftEnd,
+	}, true
+}
+
+// contains 检查时刻是否在生效窗口内
+func (r *OnCallRestriction) contains(at time.Time) bool {
+	start, err1 := time.Parse("15:04", r.StartTime)
+	end, err2 := time.Parse("15:04", r.EndTime)
+	if err1 != nil || err2 != nil {
+		return false
+	}
+
+	minutes := at.Hour()*60 + at.Minute()
+	startMin := start.Hour()*60 + start.Minute()
+	endMin := end.Hour()*60 + end.Minute()
+
+	day := at.Weekday()
+	var inWindow bool
+	if startMin <= endMin {
+		inWindow = minutes >= startMin && minutes < endMin
+	} else {
+		// 跨午夜窗口：凌晨部分归属前一天
+		inWindow = minutes >= startMin || minutes < endMin
+		if minutes < endMin {
+			day = (day + 6) % 7
+		}
+	}
+	if !inWindow {
+		return false
+	}
+
+	if len(r.Weekdays) == 0 {
+		return true
+	}
+	for _, wd := range r.Weekdays {
+		if d, ok := parseWeekday(wd); ok && d == day {
+			return true
+		}
+	}
+	return false
+}
+
+// parseWeekday 解析星期名称（Monday, Tuesday, etc.）
+func parseWeekday(name string) (time.Weekday, bool) {
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		if d.String() == name {
+			return d, true
+		}
+	}
+	return 0, false
+}
@@ -14,6 +14,7 @@ type PreSharedKey struct {
 	Name           *string    `gorm:"type:varchar(255)" json:"name,omitempty"`
 	MaxUses        *int       `json:"max_uses,omitempty"`
 	UsedCount      int        `gorm:"not null;default:0" json:"used_count"`
+	Ephemeral      bool       `gorm:"not null;default:false" json:"ephemeral"` // 使用该密钥注册的设备均为临时设备
 	ExpiresAt      *time.Time `gorm:"index" json:"expires_at,omitempty"`
 	CreatedAt      time.Time  `gorm:"not null;default:now()" json:"created_at"`
 	UpdatedAt      time.Time  `gorm:"not null;default:now()" json:"updated_at"`
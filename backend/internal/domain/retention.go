@@ -0,0 +1,144 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DataClass 可按保留策略清理的数据类别
+type DataClass string
+
+const (
+	DataClassSessions          DataClass = "sessions"           // 已结束的会话
+	DataClassAlerts            DataClass = "alerts"             // 已解决的告警（评论、投递记录、升级记录级联删除）
+	DataClassAuditLogs         DataClass = "audit_logs"         // 审计日志，只有显式配置策略时才清理
+	DataClassEmailHistory      DataClass = "email_history"      // 邮件发送历史
+	DataClassDiagnosticBundles DataClass = "diagnostic_bundles" // 已结束的诊断包记录
+	DataClassTaskRuns          DataClass = "task_runs"          // 已结束的后台任务执行记录（不区分组织）
+)
+
+// DataClasses 所有数据类别，按清理顺序排列（告警先于邮件历史）
+var DataClasses = []DataClass{
+	DataClassSessions,
+	DataClassAlerts,
+	DataClassEmailHistory,
+	DataClassDiagnosticBundles,
+	DataClassTaskRuns,
+	DataClassAuditLogs,
+}
+
+// IsValid 检查数据类别是否有效
+func (c DataClass) IsValid() bool {
+	for _, class := range DataClasses {
+		if class == c {
+			return true
+		}
+	}
+	return false
+}
+
+// OrganizationScoped 该类别的记录是否可以归属到组织（按组织策略清理）
+func (c DataClass) OrganizationScoped() bool {
+	return c != DataClassTaskRuns
+}
+
+// HasUnscopedRecords 该类别是否存在不属于任何组织的记录（只适用全局策略），
+// 如既未关联设备也未记录organization_id的告警、无法确定组织的邮件历史
+func (c DataClass) HasUnscopedRecords() bool {
+	switch c {
+	case DataClassAlerts, DataClassEmailHistory, DataClassTaskRuns:
+		return true
+	default:
+		return false
+	}
+}
+
+// RetentionAction 过期数据的处理方式
+type RetentionAction string
+
+const (
+	RetentionActionDelete  RetentionAction = "delete"  // 直接删除
+	RetentionActionArchive RetentionAction = "archive" // 写入归档文件后删除
+)
+
+// IsValid 检查处理方式是否有效
+func (a RetentionAction) IsValid() bool {
+	return a == RetentionActionDelete || a == RetentionActionArchive
+}
+
+// RetentionPolicy 数据保留策略
+// OrganizationID为空表示全局默认策略；组织策略优先于全局策略，全局策略优先于配置文件中的默认值
+type RetentionPolicy struct {
+	ID             uuid.UUID       `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	OrganizationID *uuid.UUID      `gorm:"type:uuid;index" json:"organization_id,omitempty"`
+	DataClass      DataClass       `gorm:"type:varchar(50);not null" json:"data_class"`
+	RetentionDays  int             `gorm:"not null" json:"retention_days"`
+	Action         RetentionAction `gorm:"type:varchar(20);not null;default:'delete'" json:"action"`
+	CreatedAt      time.Time       `gorm:"not null;default:now()" json:"created_at"`
+	UpdatedAt      time.Time       `gorm:"not null;default:now()" json:"updated_at"`
+}
+
+// TableName 指定表名
+func (RetentionPolicy) TableName() string {
+	return "retention_policies"
+}
+
+// RetentionReportItem 一次清理中单个数据类别（及组织）的结果
+type RetentionReportItem struct {
+	DataClass      DataClass       `json:"data_class"`
+	OrganizationID *uuid.UUID      `json:"organization_id,omitempty"` // 为空表示不属于任何组织的记录
+	PolicyID       *uuid.UUID      `json:"policy_id,omitempty"`       // 为空表示使用配置默认值
+	Action         RetentionAction `json:"action"`
+	RetentionDays  int             `json:"retention_days"`
+	Cutoff         time.Time       `json:"cutoff"`
+	Purged         int64           `json:"purged"`                   // 删除（或试运行时将删除）的记录数
+	ArchiveFile    string          `json:"archive_file,omitempty"`   // 归档文件路径
+	FirstSequence  *int64          `json:"first_sequence,omitempty"` // 审计日志：清理的链序号范围
+	LastSequence   *int64          `json:"last_sequence,omitempty"`
+	Error          string          `json:"error,omitempty"`
+}
+
+// RetentionReport 清理报告
+type RetentionReport []RetentionReportItem
+
+// Scan 实现sql.Scanner接口
+func (r *RetentionReport) Scan(value interface{}) error {
+	bytes, ok := value.([]byte)
+	if !ok {
+		return nil
+	}
+	return json.Unmarshal(bytes, r)
+}
+
+// Value 实现driver.Valuer接口
+func (r RetentionReport) Value() (driver.Value, error) {
+	return json.Marshal(r)
+}
+
+// RetentionRunStatus 清理执行状态
+type RetentionRunStatus string
+
+const (
+	RetentionRunStatusRunning RetentionRunStatus = "running"
+	RetentionRunStatusSuccess RetentionRunStatus = "success"
+	RetentionRunStatusFailure RetentionRunStatus = "failure" // 至少一个类别清理失败，其余类别照常执行
+)
+
+// RetentionRun 一次数据清理的执行记录与报告
+type RetentionRun struct {
+	ID          uuid.UUID          `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	Status      RetentionRunStatus `gorm:"type:varchar(20);not null" json:"status"`
+	DryRun      bool               `gorm:"not null;default:false" json:"dry_run"`
+	TotalPurged int64              `gorm:"not null;default:0" json:"total_purged"`
+	Report      RetentionReport    `gorm:"type:jsonb;not null;default:'[]'" json:"report"`
+	StartedAt   time.Time          `gorm:"not null;default:now();index:,sort:desc" json:"started_at"`
+	FinishedAt  *time.Time         `json:"finished_at,omitempty"`
+}
+
+// TableName 指定表名
+func (RetentionRun) TableName() string {
+	return "retention_runs"
+}
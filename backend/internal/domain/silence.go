@@ -0,0 +1,242 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MatchOperator 静默匹配运算符
+type MatchOperator string
+
+const (
+	MatchEqual    MatchOperator = "="
+	MatchNotEqual MatchOperator = "!="
+	MatchRegex    MatchOperator = "=~"
+	MatchNotRegex MatchOperator = "!~"
+)
+
+// 静默匹配器支持的标签名，metadata.<key> 匹配告警元数据
+const (
+	SilenceLabelSeverity   = "severity"
+	SilenceLabelType       = "type"
+	SilenceLabelDeviceID   = "device_id"
+	SilenceLabelDeviceName = "device_name"
+	SilenceLabelTag        = "tag"
+	SilenceLabelMetadata   = "metadata."
+)
+
+// SilenceState 静默状态枚举
+type SilenceState string
+
+const (
+	SilenceStatePending SilenceState = "pending"
+	SilenceStateActive  SilenceState = "active"
+	SilenceStateExpired SilenceState = "expired"
+)
+
+// SilenceMatcher 静默匹配器（标签式匹配）
+type SilenceMatcher struct {
+	Name     string        `json:"name"`
+	Value    string        `json:"value"`
+	Operator MatchOperator `json:"operator"`
+
+	re *regexp.Regexp // 正则运算符编译后的表达式，加载或校验时编译一次
+}
+
+// SilenceMatchers 匹配器列表（以JSONB存储）
+type SilenceMatchers []SilenceMatcher
+
+// Scan 实现sql.Scanner接口
+func (m *SilenceMatchers) Scan(value interface{}) error {
+	bytes, ok := value.([]byte)
+	if !ok {
+		return nil
+	}
+	if err := json.Unmarshal(bytes, m); err != nil {
+		return err
+	}
+	// 已保存的静默均通过校验，编译失败的匹配器在匹配时视为不命中
+	for i := range *m {
+		(*m)[i].compile()
+	}
+	return nil
+}
+
+// Value 实现driver.Valuer接口
+func (m SilenceMatchers) Value() (driver.Value, error) {
+	return json.Marshal(m)
+}
+
+// Silence 告警静默实体
+type Silence struct {
+	ID             uuid.UUID       `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	OrganizationID uuid.UUID       `gorm:"type:uuid;not null;index" json:"organization_id"`
+	Matchers       SilenceMatchers `gorm:"type:jsonb;not null" json:"matchers"`
+	StartsAt       time.Time       `gorm:"not null;index" json:"starts_at"`
+	EndsAt         time.Time       `gorm:"not null;index" json:"ends_at"`
+	CreatedBy      uuid.UUID       `gorm:"type:uuid;not null" json:"created_by"`
+	Comment        string          `gorm:"type:text;not null" json:"comment"`
+	CreatedAt      time.Time       `gorm:"not null;default:now()" json:"created_at"`
+	UpdatedAt      time.Time       `gorm:"not null;default:now()" json:"updated_at"`
+}
+
+// TableName 指定表名
+func (Silence) TableName() string {
+	return "silences"
+}
+
+// State 获取静默在指定时间的状态
+func (s *Silence) State(now time.Time) SilenceState {
+	if now.Before(s.StartsAt) {
+		return SilenceStatePending
+	}
+	if !now.Before(s.EndsAt) {
+		return SilenceStateExpired
+	}
+	return SilenceStateActive
+}
+
+// Validate 校验静默定义
+func (s *Silence) Validate() error {
+	if len(s.Matchers) == 0 {
+		return errors.New("at least one matcher is required")
+	}
+	if !s.EndsAt.After(s.StartsAt) {
+		return errors.New("ends_at must be after starts_at")
+	}
+	if strings.TrimSpace(s.Comment) == "" {
+		return errors.New("comment is required")
+	}
+
+	for i := range s.Matchers {
+		m := &s.Matchers[i]
+		if !isKnownSilenceLabel(m.Name) {
+			return fmt.Errorf("matcher %d: unsupported name %q", i, m.Name)
+		}
+		switch m.Operator {
+		case MatchEqual, MatchNotEqual:
+		case MatchRegex, MatchNotRegex:
+			if err := m.compile(); err != nil {
+				return fmt.Errorf("matcher %d: invalid regex: %w", i, err)
+			}
+		default:
+			return fmt.Errorf("matcher %d: unsupported operator %q", i, m.Operator)
+		}
+	}
+
+	return nil
+}
+
+// Matches 检查告警是否命中静默（所有匹配器均需满足）
+func (s *Silence) Matches(alert *Alert, device *Device) bool {
+	for _, m := range s.Matchers {
+		if !m.matches(silenceLabelValues(m.Name, alert, device)) {
+			return false
+		}
+	}
+	return true
+}
+
+// FindMatchingSilence 在生效的静默中查找命中告警的第一个同组织静默，未命中时返回nil
+func FindMatchingSilence(silences []*Silence, orgID uuid.UUID, alert *Alert, device *Device) *Silence {
+	for _, silence := range silences {
+		if silence.OrganizationID == orgID && silence.Matches(alert, device) {
+			return silence
+		}
+	}
+	return nil
+}
+
+// matches 对标签值应用匹配器
+// 多值标签（如tag）在正向运算符下任一值命中即可，在反向运算符下要求全部不命中
+func (m SilenceMatcher) matches(values []string) bool {
+	if len(values) == 0 {
+		// 缺失的标签视为空字符串
+		values = []string{""}
+	}
+
+	re := m.re
+	if re == nil && (m.Operator == MatchRegex || m.Operator == MatchNotRegex) {
+		// 未经加载或校验的匹配器才在这里编译
+		var err error
+		if re, err = compileSilenceRegex(m.Value); err != nil {
+			return false
+		}
+	}
+
+	hit := false
+	for _, v := range values {
+		if re != nil && re.MatchString(v) || re == nil && v == m.Value {
+			hit = true
+			break
+		}
+	}
+
+	if m.Operator == MatchNotEqual || m.Operator == MatchNotRegex {
+		return !hit
+	}
+	return hit
+}
+
+// compile 编译正则运算符的表达式并缓存，其他运算符无需编译
+func (m *SilenceMatcher) compile() error {
+	m.re = nil
+	if m.Operator != MatchRegex && m.Operator != MatchNotRegex {
+		return nil
+	}
+	re, err := compileSilenceRegex(m.Value)
+	if err != nil {
+		return err
+	}
+	m.re = re
+	return nil
+}
+
+// silenceLabelValues 提取告警在指定标签上的取值
+func silenceLabelValues(name string, alert *Alert, device *Device) []string {
+	switch name {
+	case SilenceLabelSeverity:
+		return []string{string(alert.Severity)}
+	case SilenceLabelType:
+		return []string{string(alert.Type)}
+	case SilenceLabelDeviceID:
+		if alert.DeviceID != nil {
+			return []string{alert.DeviceID.String()}
+		}
+	case SilenceLabelDeviceName:
+		if device != nil {
+			return []string{device.Name}
+		}
+	case SilenceLabelTag:
+		if device != nil {
+			return device.Tags
+		}
+	default:
+		key := strings.TrimPrefix(name, SilenceLabelMetadata)
+		if value, ok := alert.Metadata[key]; ok && value != nil {
+			return []string{fmt.Sprint(value)}
+		}
+	}
+	return nil
+}
+
+// isKnownSilenceLabel 检查标签名是否受支持
+func isKnownSilenceLabel(name string) bool {
+	switch name {
+	case SilenceLabelSeverity, SilenceLabelType, SilenceLabelDeviceID, SilenceLabelDeviceName, SilenceLabelTag:
+		return true
+	}
+	return strings.HasPrefix(name, SilenceLabelMetadata) && len(name) > len(SilenceLabelMetadata)
+}
+
+// compileSilenceRegex 编译完整匹配的正则（与Alertmanager一致，自动锚定）
+func compileSilenceRegex(pattern string) (*regexp.Regexp, error) {
+	return regexp.Compile("^(?:" + pattern + ")$")
+}
@@ -0,0 +1,28 @@
+package domain
+
+import "testing"
+
+// TestSilenceMatchersCompileOnScan 从数据库加载的正则匹配器已编译，匹配时无需再编译
+func TestSilenceMatchersCompileOnScan(t *testing.T) {
+	var matchers SilenceMatchers
+	raw := []byte(`[{"name":"type","value":"device_.*","operator":"=~"},{"name":"severity","value":"low","operator":"!="}]`)
+	if err := matchers.Scan(raw); err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+	if matchers[0].re == nil {
+		t.Fatal("regex matcher was not compiled on scan")
+	}
+	if matchers[1].re != nil {
+		t.Error("equality matcher should not carry a regex")
+	}
+
+	silence := &Silence{Matchers: matchers}
+	alert := &Alert{Type: AlertTypeDeviceOffline, Severity: SeverityCritical}
+	if !silence.Matches(alert, nil) {
+		t.Error("silence should match device_offline critical alert")
+	}
+	alert.Severity = SeverityLow
+	if silence.Matches(alert, nil) {
+		t.Error("silence should not match low alert")
+	}
+}
@@ -0,0 +1,83 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// 后台任务名称，Background Worker调度与手动触发共用
+const (
+	TaskDeviceHealth       = "device_health"
+	TaskPerformanceMonitor = "performance_monitor"
+	TaskSecurityMonitor    = "security_monitor"
+	TaskKeyExpiry          = "key_expiry"
+	TaskAuditCheckpoint    = "audit_checkpoint"
+	TaskRetention          = "retention"
+	TaskEphemeralCleanup   = "ephemeral_cleanup"
+)
+
+// BackgroundTasks 所有可调度的后台任务
+var BackgroundTasks = []string{
+	TaskDeviceHealth,
+	TaskPerformanceMonitor,
+	TaskSecurityMonitor,
+	TaskKeyExpiry,
+	TaskAuditCheckpoint,
+	TaskRetention,
+	TaskEphemeralCleanup,
+}
+
+// IsBackgroundTask 检查任务名称是否有效
+func IsBackgroundTask(name string) bool {
+	for _, task := range BackgroundTasks {
+		if task == name {
+			return true
+		}
+	}
+	return false
+}
+
+// TaskRunStatus 任务执行状态
+type TaskRunStatus string
+
+const (
+	TaskRunStatusPending     TaskRunStatus = "pending"     // 手动触发，等待leader执行
+	TaskRunStatusRunning     TaskRunStatus = "running"     // 执行中
+	TaskRunStatusSuccess     TaskRunStatus = "success"     // 执行成功
+	TaskRunStatusFailure     TaskRunStatus = "failure"     // 执行失败
+	TaskRunStatusInterrupted TaskRunStatus = "interrupted" // 执行实例退出或失去leader身份，未执行完
+)
+
+// TaskRunTrigger 任务触发方式
+type TaskRunTrigger string
+
+const (
+	TaskRunTriggerSchedule TaskRunTrigger = "schedule"
+	TaskRunTriggerManual   TaskRunTrigger = "manual"
+)
+
+// TaskRun 后台任务执行记录
+type TaskRun struct {
+	ID          uuid.UUID      `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	TaskName    string         `gorm:"type:varchar(100);not null;index:idx_task_runs_task_created,priority:1" json:"task_name"`
+	Trigger     TaskRunTrigger `gorm:"type:varchar(20);not null" json:"trigger"`
+	Status      TaskRunStatus  `gorm:"type:varchar(20);not null;index" json:"status"`
+	Instance    *string        `gorm:"type:varchar(255)" json:"instance,omitempty"` // 执行任务的Worker实例
+	RequestedBy *uuid.UUID     `gorm:"type:uuid" json:"requested_by,omitempty"`     // 手动触发的管理员
+	Error       *string        `gorm:"type:text" json:"error,omitempty"`
+	StartedAt   *time.Time     `json:"started_at,omitempty"`
+	FinishedAt  *time.Time     `json:"finished_at,omitempty"`
+	DurationMs  *int64         `json:"duration_ms,omitempty"`
+	CreatedAt   time.Time      `gorm:"not null;default:now();index:idx_task_runs_task_created,priority:2,sort:desc" json:"created_at"`
+}
+
+// TableName 指定表名
+func (TaskRun) TableName() string {
+	return "task_runs"
+}
+
+// IsFinished 检查任务是否已结束
+func (r *TaskRun) IsFinished() bool {
+	return r.Status == TaskRunStatusSuccess || r.Status == TaskRunStatusFailure || r.Status == TaskRunStatusInterrupted
+}
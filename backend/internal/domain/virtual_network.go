@@ -10,14 +10,15 @@ import (
 
 // VirtualNetwork 虚拟网络实体
 type VirtualNetwork struct {
-	ID             uuid.UUID      `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
-	OrganizationID uuid.UUID      `gorm:"type:uuid;not null;index" json:"organization_id"`
-	Name           string         `gorm:"type:varchar(255);not null" json:"name"`
-	CIDR           string         `gorm:"type:cidr;not null" json:"cidr"`
-	GatewayIP      string         `gorm:"type:inet;not null" json:"gateway_ip"`
-	DNSServers     pq.StringArray `gorm:"type:inet[]" json:"dns_servers"`
-	CreatedAt      time.Time      `gorm:"not null;default:now()" json:"created_at"`
-	UpdatedAt      time.Time      `gorm:"not null;default:now()" json:"updated_at"`
+	ID              uuid.UUID      `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	OrganizationID  uuid.UUID      `gorm:"type:uuid;not null;index" json:"organization_id"`
+	Name            string         `gorm:"type:varchar(255);not null" json:"name"`
+	CIDR            string         `gorm:"type:cidr;not null" json:"cidr"`
+	GatewayIP       string         `gorm:"type:inet;not null" json:"gateway_ip"`
+	DNSServers      pq.StringArray `gorm:"type:inet[]" json:"dns_servers"`
+	RequireApproval bool           `gorm:"not null;default:false" json:"require_approval"` // 新设备需经管理员审批才加入网络
+	CreatedAt       time.Time      `gorm:"not null;default:now()" json:"created_at"`
+	UpdatedAt       time.Time      `gorm:"not null;default:now()" json:"updated_at"`
 
 	// 关联
 	Organization *Organization `gorm:"foreignKey:OrganizationID" json:"organization,omitempty"`
@@ -40,4 +41,25 @@ func (vn *VirtualNetwork) Gateway() net.IP {
 	return net.ParseIP(vn.GatewayIP)
 }
 
+// UsableAddresses 可分配给设备的地址数量（扣除网络地址、广播地址和网关）
+// 地址空间超过2^30时按2^30计算，不会成为容量瓶颈
+func (vn *VirtualNetwork) UsableAddresses() (int, error) {
+	ipnet, err := vn.CIDRIP()
+	if err != nil {
+		return 0, err
+	}
+
+	ones, bits := ipnet.Mask.Size()
+	hostBits := bits - ones
+	if hostBits > 30 {
+		hostBits = 30
+	}
+
+	usable := 1<<hostBits - 3
+	if usable < 0 {
+		usable = 0
+	}
+	return usable, nil
+}
+
 // pq.StringArray already implements sql.Scanner and driver.Valuer interfaces
@@ -0,0 +1,45 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WebhookEndpointStatus Webhook端点状态枚举
+type WebhookEndpointStatus string
+
+const (
+	WebhookEndpointStatusActive   WebhookEndpointStatus = "active"
+	WebhookEndpointStatusDisabled WebhookEndpointStatus = "disabled" // 持续失败被自动停用，需管理员重新启用
+)
+
+// WebhookEndpoint 出站Webhook端点的投递健康状态
+// 端点本身在规则动作中配置，此处按URL哈希记录连续失败次数与停用状态
+type WebhookEndpoint struct {
+	ID                  uuid.UUID             `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	EndpointKey         string                `gorm:"type:varchar(64);not null;uniqueIndex:idx_webhook_endpoints_endpoint_key" json:"-"` // URL的SHA-256
+	Name                string                `gorm:"type:varchar(255);not null" json:"name"`
+	URL                 string                `gorm:"type:text;not null" json:"url"` // 去除查询参数与凭据后的URL
+	Status              WebhookEndpointStatus `gorm:"type:varchar(20);not null;default:'active';index" json:"status"`
+	ConsecutiveFailures int                   `gorm:"not null;default:0" json:"consecutive_failures"`
+	FailingSince        *time.Time            `json:"failing_since,omitempty"`
+	LastError           *string               `gorm:"type:text" json:"last_error,omitempty"`
+	LastStatusCode      *int                  `json:"last_status_code,omitempty"`
+	LastSuccessAt       *time.Time            `json:"last_success_at,omitempty"`
+	LastFailureAt       *time.Time            `json:"last_failure_at,omitempty"`
+	DisabledAt          *time.Time            `json:"disabled_at,omitempty"`
+	DisabledAlertID     *uuid.UUID            `gorm:"type:uuid" json:"disabled_alert_id,omitempty"` // 停用时产生的告警
+	CreatedAt           time.Time             `gorm:"not null;default:now()" json:"created_at"`
+	UpdatedAt           time.Time             `gorm:"not null;default:now()" json:"updated_at"`
+}
+
+// TableName 指定表名
+func (WebhookEndpoint) TableName() string {
+	return "webhook_endpoints"
+}
+
+// IsDisabled 端点是否已停用
+func (e *WebhookEndpoint) IsDisabled() bool {
+	return e.Status == WebhookEndpointStatusDisabled
+}
@@ -0,0 +1,37 @@
+package metrics
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// unmatchedRoute 未匹配任何路由的请求使用的path标签，避免扫描请求产生大量标签
+const unmatchedRoute = "unmatched"
+
+// GinMiddleware 按路由模板（如/api/v1/device/:device_id/config）记录HTTP请求数量和耗时
+func (m *Metrics) GinMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// WebSocket连接的持续时间没有参考意义，由连接数指标覆盖
+		if c.IsWebsocket() {
+			c.Next()
+			return
+		}
+
+		start := time.Now()
+		c.Next()
+
+		path := c.FullPath()
+		if path == "" {
+			path = unmatchedRoute
+		}
+
+		m.RecordHTTPRequest(
+			c.Request.Method,
+			path,
+			strconv.Itoa(c.Writer.Status()),
+			time.Since(start).Seconds(),
+		)
+	}
+}
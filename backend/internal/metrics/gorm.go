@@ -0,0 +1,93 @@
+package metrics
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"gorm.io/gorm"
+)
+
+// gormStartKey 查询开始时间在GORM语句实例中的存储键
+const gormStartKey = "metrics:start_time"
+
+// InstrumentGORM 注册GORM回调记录查询数量、耗时和错误，并导出连接池统计
+func (m *Metrics) InstrumentGORM(db *gorm.DB, dbName string) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return fmt.Errorf("failed to get sql.DB: %w", err)
+	}
+
+	// 连接池统计（go_sql_*），抓取时读取sql.DB.Stats()
+	if err := register(collectors.NewDBStatsCollector(sqlDB, dbName)); err != nil {
+		return fmt.Errorf("failed to register db stats collector: %w", err)
+	}
+
+	cb := db.Callback()
+	err = errors.Join(
+		cb.Create().Before("gorm:create").Register("metrics:before_create", m.gormBefore),
+		cb.Create().After("gorm:create").Register("metrics:after_create", m.gormAfter("create")),
+		cb.Query().Before("gorm:query").Register("metrics:before_query", m.gormBefore),
+		cb.Query().After("gorm:query").Register("metrics:after_query", m.gormAfter("query")),
+		cb.Update().Before("gorm:update").Register("metrics:before_update", m.gormBefore),
+		cb.Update().After("gorm:update").Register("metrics:after_update", m.gormAfter("update")),
+		cb.Delete().Before("gorm:delete").Register("metrics:before_delete", m.gormBefore),
+		cb.Delete().After("gorm:delete").Register("metrics:after_delete", m.gormAfter("delete")),
+		cb.Row().Before("gorm:row").Register("metrics:before_row", m.gormBefore),
+		cb.Row().After("gorm:row").Register("metrics:after_row", m.gormAfter("row")),
+		cb.Raw().Before("gorm:raw").Register("metrics:before_raw", m.gormBefore),
+		cb.Raw().After("gorm:raw").Register("metrics:after_raw", m.gormAfter("raw")),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to register gorm callbacks: %w", err)
+	}
+
+	return nil
+}
+
+// gormBefore 记录语句开始时间
+func (m *Metrics) gormBefore(db *gorm.DB) {
+	db.InstanceSet(gormStartKey, time.Now())
+}
+
+// gormAfter 按操作类型和表名记录查询结果
+func (m *Metrics) gormAfter(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		value, ok := db.InstanceGet(gormStartKey)
+		if !ok {
+			return
+		}
+		start, ok := value.(time.Time)
+		if !ok {
+			return
+		}
+
+		// 原生SQL没有表名
+		table := db.Statement.Table
+		if table == "" {
+			table = "unknown"
+		}
+
+		m.DBQueriesTotal.WithLabelValues(operation, table).Inc()
+		m.DBQueryDuration.WithLabelValues(operation, table).Observe(time.Since(start).Seconds())
+
+		// 未找到记录属于正常查询结果
+		if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+			m.DBQueryErrors.WithLabelValues(operation, table).Inc()
+		}
+	}
+}
+
+// register 注册采集器，重复注册（如同一进程内多次初始化）视为成功
+func register(collector prometheus.Collector) error {
+	if err := prometheus.Register(collector); err != nil {
+		var already prometheus.AlreadyRegisteredError
+		if errors.As(err, &already) {
+			return nil
+		}
+		return err
+	}
+	return nil
+}
@@ -1,6 +1,8 @@
 package metrics
 
 import (
+	"time"
+
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
 )
@@ -24,6 +26,36 @@ type Metrics struct {
 	DBQueriesTotal    *prometheus.CounterVec
 	DBQueryDuration   *prometheus.HistogramVec
 	DBConnectionsPool *prometheus.GaugeVec
+	DBQueryErrors     *prometheus.CounterVec
+
+	// Redis指标
+	RedisCommandsTotal   *prometheus.CounterVec
+	RedisCommandDuration *prometheus.HistogramVec
+
+	// WebSocket指标
+	WebSocketClients prometheus.Gauge
+
+	// 通知指标
+	NotificationDeliveries       *prometheus.CounterVec
+	NotificationDeliveryDuration *prometheus.HistogramVec
+	IntegrationSends             *prometheus.CounterVec
+	IntegrationSendDuration      *prometheus.HistogramVec
+
+	// 后台任务指标
+	TaskRunsTotal   *prometheus.CounterVec
+	TaskDuration    *prometheus.HistogramVec
+	TaskLastSuccess *prometheus.GaugeVec
+	WorkerLeader    prometheus.Gauge
+
+	// 审计日志导出指标
+	AuditExportRecords     *prometheus.CounterVec
+	AuditExportLastSuccess *prometheus.GaugeVec
+
+	// 数据保留清理指标
+	RetentionPurgedRecords *prometheus.CounterVec
+
+	// 临时设备撤销指标
+	EphemeralDevicesRevoked prometheus.Counter
 }
 
 // New 创建指标收集器
@@ -108,6 +140,135 @@ func New() *Metrics {
 			},
 			[]string{"state"}, // "idle", "in_use", "total"
 		),
+
+		DBQueryErrors: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "edgelink_db_query_errors_total",
+				Help: "Total number of failed database queries",
+			},
+			[]string{"operation", "table"},
+		),
+
+		RedisCommandsTotal: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "edgelink_redis_commands_total",
+				Help: "Total number of Redis commands",
+			},
+			[]string{"command", "status"},
+		),
+
+		RedisCommandDuration: promauto.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "edgelink_redis_command_duration_seconds",
+				Help:    "Redis command duration in seconds",
+				Buckets: []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1},
+			},
+			[]string{"command"},
+		),
+
+		WebSocketClients: promauto.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "edgelink_websocket_clients",
+				Help: "Number of connected WebSocket clients",
+			},
+		),
+
+		NotificationDeliveries: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "edgelink_notification_deliveries_total",
+				Help: "Total number of notification delivery attempts",
+			},
+			[]string{"channel", "status"}, // "sent", "retrying", "dead"
+		),
+
+		NotificationDeliveryDuration: promauto.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "edgelink_notification_delivery_duration_seconds",
+				Help:    "Notification delivery attempt duration in seconds",
+				Buckets: prometheus.DefBuckets,
+			},
+			[]string{"channel"},
+		),
+
+		IntegrationSends: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "edgelink_integration_sends_total",
+				Help: "Total number of alerts sent to third-party integrations",
+			},
+			[]string{"integration", "status"},
+		),
+
+		IntegrationSendDuration: promauto.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "edgelink_integration_send_duration_seconds",
+				Help:    "Third-party integration request duration in seconds",
+				Buckets: prometheus.DefBuckets,
+			},
+			[]string{"integration"},
+		),
+
+		TaskRunsTotal: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "edgelink_task_runs_total",
+				Help: "Total number of background task runs",
+			},
+			[]string{"task", "status"},
+		),
+
+		TaskDuration: promauto.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "edgelink_task_duration_seconds",
+				Help:    "Background task run duration in seconds",
+				Buckets: []float64{.01, .05, .1, .5, 1, 5, 10, 30, 60, 300},
+			},
+			[]string{"task"},
+		),
+
+		TaskLastSuccess: promauto.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "edgelink_task_last_success_timestamp_seconds",
+				Help: "Unix timestamp of the last successful background task run",
+			},
+			[]string{"task"},
+		),
+
+		WorkerLeader: promauto.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "edgelink_worker_leader",
+				Help: "Whether this background worker instance currently holds the leader lease (1) or not (0)",
+			},
+		),
+
+		AuditExportRecords: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "edgelink_audit_export_records_total",
+				Help: "Total number of audit log records sent to export sinks",
+			},
+			[]string{"sink", "status"},
+		),
+
+		AuditExportLastSuccess: promauto.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "edgelink_audit_export_last_success_timestamp_seconds",
+				Help: "Unix timestamp of the last batch delivered to an audit export sink",
+			},
+			[]string{"sink"},
+		),
+
+		RetentionPurgedRecords: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "edgelink_retention_purged_records_total",
+				Help: "Total number of records purged by retention policies",
+			},
+			[]string{"data_class", "action"},
+		),
+
+		EphemeralDevicesRevoked: promauto.NewCounter(
+			prometheus.CounterOpts{
+				Name: "edgelink_ephemeral_devices_revoked_total",
+				Help: "Total number of ephemeral devices revoked after going offline",
+			},
+		),
 	}
 }
 
@@ -137,3 +298,64 @@ func (m *Metrics) UpdateTunnelCount(connType string, count int) {
 func (m *Metrics) RecordTunnelFailure(reason string) {
 	m.TunnelFailures.WithLabelValues(reason).Inc()
 }
+
+// RecordNotificationDelivery 记录一次通知投递尝试
+func (m *Metrics) RecordNotificationDelivery(channel, status string, duration time.Duration) {
+	m.NotificationDeliveries.WithLabelValues(channel, status).Inc()
+	m.NotificationDeliveryDuration.WithLabelValues(channel).Observe(duration.Seconds())
+}
+
+// RecordIntegrationSend 记录一次第三方集成发送
+func (m *Metrics) RecordIntegrationSend(integration string, err error, duration time.Duration) {
+	m.IntegrationSends.WithLabelValues(integration, resultStatus(err)).Inc()
+	m.IntegrationSendDuration.WithLabelValues(integration).Observe(duration.Seconds())
+}
+
+// RecordTask 记录一次后台任务执行
+func (m *Metrics) RecordTask(task string, duration time.Duration, err error) {
+	m.TaskRunsTotal.WithLabelValues(task, resultStatus(err)).Inc()
+	m.TaskDuration.WithLabelValues(task).Observe(duration.Seconds())
+	if err == nil {
+		m.TaskLastSuccess.WithLabelValues(task).SetToCurrentTime()
+	}
+}
+
+// SetWorkerLeader 更新当前实例的leader状态
+func (m *Metrics) SetWorkerLeader(leader bool) {
+	if leader {
+		m.WorkerLeader.Set(1)
+	} else {
+		m.WorkerLeader.Set(0)
+	}
+}
+
+// RecordAuditExport 记录一批审计日志的导出结果
+func (m *Metrics) RecordAuditExport(sink string, records int, err error) {
+	m.AuditExportRecords.WithLabelValues(sink, resultStatus(err)).Add(float64(records))
+	if err == nil {
+		m.AuditExportLastSuccess.WithLabelValues(sink).SetToCurrentTime()
+	}
+}
+
+// RecordRetentionPurge 记录按保留策略清理的记录数
+func (m *Metrics) RecordRetentionPurge(dataClass, action string, records int64) {
+	m.RetentionPurgedRecords.WithLabelValues(dataClass, action).Add(float64(records))
+}
+
+// RecordEphemeralRevocation 记录一台离线超时被撤销的临时设备
+func (m *Metrics) RecordEphemeralRevocation() {
+	m.EphemeralDevicesRevoked.Inc()
+}
+
+// UpdateWebSocketClients 更新WebSocket客户端数量
+func (m *Metrics) UpdateWebSocketClients(count int) {
+	m.WebSocketClients.Set(float64(count))
+}
+
+// resultStatus 将错误转换为status标签值
+func resultStatus(err error) string {
+	if err != nil {
+		return "failure"
+	}
+	return "success"
+}
@@ -0,0 +1,105 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/redis/go-redis/v9"
+)
+
+// InstrumentRedis 为Redis客户端添加命令耗时钩子，并导出连接池统计
+func (m *Metrics) InstrumentRedis(client *redis.Client) error {
+	client.AddHook(&redisHook{metrics: m})
+	return register(newRedisPoolCollector(client))
+}
+
+// redisHook 记录Redis命令数量、耗时和错误
+type redisHook struct {
+	metrics *Metrics
+}
+
+// DialHook 不记录建连
+func (h *redisHook) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return next(ctx, network, addr)
+	}
+}
+
+// ProcessHook 记录单条命令
+func (h *redisHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		start := time.Now()
+		err := next(ctx, cmd)
+		h.observe(strings.ToLower(cmd.Name()), err, time.Since(start))
+		return err
+	}
+}
+
+// ProcessPipelineHook 将整个管道记录为一次pipeline命令
+func (h *redisHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		start := time.Now()
+		err := next(ctx, cmds)
+		h.observe("pipeline", err, time.Since(start))
+		return err
+	}
+}
+
+func (h *redisHook) observe(command string, err error, duration time.Duration) {
+	// redis.Nil表示键不存在，属于正常结果
+	status := "success"
+	if err != nil && !errors.Is(err, redis.Nil) {
+		status = "failure"
+	}
+	h.metrics.RedisCommandsTotal.WithLabelValues(command, status).Inc()
+	h.metrics.RedisCommandDuration.WithLabelValues(command).Observe(duration.Seconds())
+}
+
+// redisPoolCollector 抓取时读取Redis连接池统计
+type redisPoolCollector struct {
+	client *redis.Client
+
+	hits       *prometheus.Desc
+	misses     *prometheus.Desc
+	timeouts   *prometheus.Desc
+	totalConns *prometheus.Desc
+	idleConns  *prometheus.Desc
+	staleConns *prometheus.Desc
+}
+
+func newRedisPoolCollector(client *redis.Client) *redisPoolCollector {
+	return &redisPoolCollector{
+		client:     client,
+		hits:       prometheus.NewDesc("edgelink_redis_pool_hits_total", "Number of times a free connection was found in the pool", nil, nil),
+		misses:     prometheus.NewDesc("edgelink_redis_pool_misses_total", "Number of times a free connection was not found in the pool", nil, nil),
+		timeouts:   prometheus.NewDesc("edgelink_redis_pool_timeouts_total", "Number of times a wait for a connection timed out", nil, nil),
+		totalConns: prometheus.NewDesc("edgelink_redis_pool_connections", "Number of connections in the pool", nil, nil),
+		idleConns:  prometheus.NewDesc("edgelink_redis_pool_idle_connections", "Number of idle connections in the pool", nil, nil),
+		staleConns: prometheus.NewDesc("edgelink_redis_pool_stale_connections_total", "Number of stale connections removed from the pool", nil, nil),
+	}
+}
+
+// Describe 实现prometheus.Collector
+func (c *redisPoolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.hits
+	ch <- c.misses
+	ch <- c.timeouts
+	ch <- c.totalConns
+	ch <- c.idleConns
+	ch <- c.staleConns
+}
+
+// Collect 实现prometheus.Collector
+func (c *redisPoolCollector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.client.PoolStats()
+	ch <- prometheus.MustNewConstMetric(c.hits, prometheus.CounterValue, float64(stats.Hits))
+	ch <- prometheus.MustNewConstMetric(c.misses, prometheus.CounterValue, float64(stats.Misses))
+	ch <- prometheus.MustNewConstMetric(c.timeouts, prometheus.CounterValue, float64(stats.Timeouts))
+	ch <- prometheus.MustNewConstMetric(c.totalConns, prometheus.GaugeValue, float64(stats.TotalConns))
+	ch <- prometheus.MustNewConstMetric(c.idleConns, prometheus.GaugeValue, float64(stats.IdleConns))
+	ch <- prometheus.MustNewConstMetric(c.staleConns, prometheus.CounterValue, float64(stats.StaleConns))
+}
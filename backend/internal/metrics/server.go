@@ -0,0 +1,68 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/edgelink/backend/internal/config"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// RunServer 为数据库和Redis注册采集器，并在METRICS_PORT上单独暴露/metrics
+// 指标端口与业务端口分开，避免通过公网入口暴露内部指标
+func RunServer(
+	lifecycle fx.Lifecycle,
+	log *zap.Logger,
+	cfg *config.Config,
+	m *Metrics,
+	db *gorm.DB,
+	redisClient *redis.Client,
+) error {
+	if !cfg.Metrics.Enabled {
+		log.Info("Metrics disabled")
+		return nil
+	}
+
+	if err := m.InstrumentGORM(db, cfg.Database.DBName); err != nil {
+		return err
+	}
+	if err := m.InstrumentRedis(redisClient); err != nil {
+		return fmt.Errorf("failed to instrument redis: %w", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+
+	server := &http.Server{
+		Addr:         fmt.Sprintf(":%d", cfg.Metrics.Port),
+		Handler:      mux,
+		ReadTimeout:  10 * time.Second,
+		WriteTimeout: 30 * time.Second,
+	}
+
+	lifecycle.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			log.Info("Starting metrics server", zap.Int("port", cfg.Metrics.Port))
+
+			go func() {
+				if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+					log.Error("Metrics server stopped", zap.Error(err))
+				}
+			}()
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			shutdownCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+			defer cancel()
+			return server.Shutdown(shutdownCtx)
+		},
+	})
+
+	return nil
+}
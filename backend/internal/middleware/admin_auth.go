@@ -0,0 +1,174 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/edgelink/backend/internal/auth"
+	"github.com/edgelink/backend/internal/config"
+	"github.com/edgelink/backend/internal/domain"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// 认证通过后写入gin上下文的键
+const (
+	ContextKeyUserID         = "user_id"
+	ContextKeyOrganizationID = "organization_id"
+	ContextKeyEmail          = "email"
+	ContextKeyRole           = "role"
+)
+
+// AdminAuth 管理员JWT认证中间件
+type AdminAuth struct {
+	jwtManager *auth.JWTManager
+	logger     *zap.Logger
+}
+
+// NewAdminAuth 创建管理员认证中间件
+// 未配置JWT_SECRET时所有受保护的请求都会被拒绝
+func NewAdminAuth(cfg *config.Config, logger *zap.Logger) *AdminAuth {
+	var jwtManager *auth.JWTManager
+	if cfg.Auth.JWTSecret != "" {
+		jwtManager = auth.NewJWTManager(cfg.Auth.JWTSecret, cfg.Auth.TokenDuration)
+	} else {
+		logger.Warn("JWT_SECRET not configured, admin API requests will be rejected")
+	}
+
+	return &AdminAuth{
+		jwtManager: jwtManager,
+		logger:     logger,
+	}
+}
+
+// Middleware 校验Bearer令牌并要求不低于minRole的角色
+// 路径中带organization_id参数时，非超级管理员只能访问自己的组织
+func (a *AdminAuth) Middleware(minRole domain.Role) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if a.jwtManager == nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{
+				"error":   "auth_not_configured",
+				"message": "Admin authentication is not configured",
+			})
+			c.Abort()
+			return
+		}
+
+		token, err := a.jwtManager.ExtractTokenFromHeader(c.GetHeader("Authorization"))
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error":   "unauthorized",
+				"message": "Missing or malformed bearer token",
+			})
+			c.Abort()
+			return
+		}
+
+		claims, err := a.jwtManager.ValidateToken(token)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error":   "unauthorized",
+				"message": "Invalid or expired token",
+			})
+			c.Abort()
+			return
+		}
+
+		user := &domain.AdminUser{ID: claims.UserID, Role: domain.Role(claims.Role)}
+		if !user.HasPermission(minRole) {
+			a.logger.Warn("Admin request rejected: insufficient role",
+				zap.String("user_id", claims.UserID.String()),
+				zap.String("role", claims.Role),
+				zap.String("required_role", string(minRole)),
+				zap.String("path", c.FullPath()),
+			)
+			c.JSON(http.StatusForbidden, gin.H{
+				"error":   "forbidden",
+				"message": "Insufficient permissions",
+			})
+			c.Abort()
+			return
+		}
+
+		if orgParam := c.Param("organization_id"); orgParam != "" && user.Role != domain.RoleSuperAdmin {
+			if orgID, err := uuid.Parse(orgParam); err == nil && orgID != claims.OrganizationID {
+				c.JSON(http.StatusForbidden, gin.H{
+					"error":   "forbidden",
+					"message": "Access to this organization is not allowed",
+				})
+				c.Abort()
+				return
+			}
+		}
+
+		c.Set(ContextKeyUserID, claims.UserID)
+		c.Set(ContextKeyOrganizationID, claims.OrganizationID)
+		c.Set(ContextKeyEmail, claims.Email)
+		c.Set(ContextKeyRole, user.Role)
+
+		// 下游处理器与审计中间件从X-Actor-ID读取操作者，以令牌为准防止伪造
+		c.Request.Header.Set("X-Actor-ID", claims.UserID.String())
+
+		c.Next()
+	}
+}
+
+// RequireRole 要求不低于minRole的角色，需在Middleware之后使用
+func (a *AdminAuth) RequireRole(minRole domain.Role) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !contextHasRole(c, minRole) {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error":   "forbidden",
+				"message": "Insufficient permissions",
+			})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RequireRoleForWrites 写操作（非GET/HEAD）要求不低于minRole的角色，需在Middleware之后使用
+func (a *AdminAuth) RequireRoleForWrites(minRole domain.Role) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead {
+			c.Next()
+			return
+		}
+
+		if !contextHasRole(c, minRole) {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error":   "forbidden",
+				"message": "Insufficient permissions",
+			})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// contextHasRole 判断Middleware写入上下文的角色是否不低于minRole
+func contextHasRole(c *gin.Context, minRole domain.Role) bool {
+	role, _ := c.Get(ContextKeyRole)
+	user := &domain.AdminUser{}
+	if r, ok := role.(domain.Role); ok {
+		user.Role = r
+	}
+	return user.HasPermission(minRole)
+}
+
+// ScopedOrganizationID 返回请求可访问的组织：超级管理员返回nil（不限组织），其他角色返回令牌所属组织
+func ScopedOrganizationID(c *gin.Context) *uuid.UUID {
+	if role, _ := c.Get(ContextKeyRole); role == domain.RoleSuperAdmin {
+		return nil
+	}
+	orgID, _ := c.Get(ContextKeyOrganizationID)
+	if id, ok := orgID.(uuid.UUID); ok {
+		return &id
+	}
+	// 未经Middleware认证的请求不允许访问任何组织
+	return &uuid.Nil
+}
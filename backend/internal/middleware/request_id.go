@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// ContextKeyRequestID 请求ID在gin上下文中的键
+const ContextKeyRequestID = "request_id"
+
+// RequestIDHeader 请求ID请求/响应头
+const RequestIDHeader = "X-Request-ID"
+
+// RequestID 请求ID中间件
+// 沿用客户端或上游代理传入的X-Request-ID，没有时生成新ID，并写入上下文和响应头
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if requestID == "" {
+			requestID = uuid.New().String()
+		}
+
+		c.Set(ContextKeyRequestID, requestID)
+		c.Header(RequestIDHeader, requestID)
+
+		c.Next()
+	}
+}
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_alert_comments_created_at;
+DROP INDEX IF EXISTS idx_alert_comments_alert_id;
+DROP TABLE IF EXISTS alert_comments;
+DROP INDEX IF EXISTS idx_alerts_assigned_to;
+ALTER TABLE alerts DROP COLUMN IF EXISTS assigned_at;
+ALTER TABLE alerts DROP COLUMN IF EXISTS assigned_to;
@@ -0,0 +1,17 @@
+-- 告警指派字段
+ALTER TABLE alerts ADD COLUMN IF NOT EXISTS assigned_to UUID REFERENCES admin_users(id) ON DELETE SET NULL;
+ALTER TABLE alerts ADD COLUMN IF NOT EXISTS assigned_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_alerts_assigned_to ON alerts(assigned_to);
+
+-- 创建 alert_comments 表
+CREATE TABLE IF NOT EXISTS alert_comments (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    alert_id UUID NOT NULL REFERENCES alerts(id) ON DELETE CASCADE,
+    author_id UUID NOT NULL REFERENCES admin_users(id) ON DELETE CASCADE,
+    body TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_alert_comments_alert_id ON alert_comments(alert_id);
+CREATE INDEX idx_alert_comments_created_at ON alert_comments(created_at);
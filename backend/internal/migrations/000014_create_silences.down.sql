@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_alerts_silenced_by;
+ALTER TABLE alerts DROP COLUMN IF EXISTS silenced_by;
+DROP INDEX IF EXISTS idx_silences_ends_at;
+DROP INDEX IF EXISTS idx_silences_starts_at;
+DROP INDEX IF EXISTS idx_silences_organization_id;
+DROP TABLE IF EXISTS silences;
+-- 注意: PostgreSQL 不支持从枚举类型中删除值，resource_type_enum 中的 'silence' 保留
@@ -0,0 +1,24 @@
+-- 创建 silences 表
+CREATE TABLE IF NOT EXISTS silences (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    matchers JSONB NOT NULL,
+    starts_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    ends_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_by UUID NOT NULL REFERENCES admin_users(id),
+    comment TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    CONSTRAINT chk_silences_time_range CHECK (ends_at > starts_at)
+);
+
+CREATE INDEX idx_silences_organization_id ON silences(organization_id);
+CREATE INDEX idx_silences_starts_at ON silences(starts_at);
+CREATE INDEX idx_silences_ends_at ON silences(ends_at);
+
+-- 告警静默引用
+ALTER TABLE alerts ADD COLUMN IF NOT EXISTS silenced_by UUID REFERENCES silences(id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS idx_alerts_silenced_by ON alerts(silenced_by);
+
+-- 审计资源类型
+ALTER TYPE resource_type_enum ADD VALUE IF NOT EXISTS 'silence';
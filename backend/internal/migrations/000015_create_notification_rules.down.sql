@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_notification_rule_versions_organization_id;
+DROP INDEX IF EXISTS idx_notification_rule_versions_rule_version;
+DROP TABLE IF EXISTS notification_rule_versions;
+DROP INDEX IF EXISTS idx_notification_rules_deleted_at;
+DROP INDEX IF EXISTS idx_notification_rules_enabled;
+DROP INDEX IF EXISTS idx_notification_rules_org_key;
+DROP TABLE IF EXISTS notification_rules;
@@ -0,0 +1,37 @@
+-- 创建 notification_rules 表（组织级通知规则）
+CREATE TABLE IF NOT EXISTS notification_rules (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    rule_key VARCHAR(100) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    priority INTEGER NOT NULL DEFAULT 100,
+    definition JSONB NOT NULL,
+    version INTEGER NOT NULL DEFAULT 1,
+    created_by UUID REFERENCES admin_users(id) ON DELETE SET NULL,
+    updated_by UUID REFERENCES admin_users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE UNIQUE INDEX idx_notification_rules_org_key ON notification_rules(organization_id, rule_key);
+CREATE INDEX idx_notification_rules_enabled ON notification_rules(enabled);
+CREATE INDEX idx_notification_rules_deleted_at ON notification_rules(deleted_at);
+
+-- 创建 notification_rule_versions 表（规则版本快照）
+CREATE TABLE IF NOT EXISTS notification_rule_versions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    notification_rule_id UUID NOT NULL REFERENCES notification_rules(id) ON DELETE CASCADE,
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    version INTEGER NOT NULL,
+    change_type VARCHAR(20) NOT NULL,
+    enabled BOOLEAN NOT NULL,
+    definition JSONB NOT NULL,
+    changed_by UUID REFERENCES admin_users(id) ON DELETE SET NULL,
+    comment TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX idx_notification_rule_versions_rule_version ON notification_rule_versions(notification_rule_id, version);
+CREATE INDEX idx_notification_rule_versions_organization_id ON notification_rule_versions(organization_id);
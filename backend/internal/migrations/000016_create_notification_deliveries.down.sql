@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS idx_notification_attempts_created_at;
+DROP INDEX IF EXISTS idx_notification_attempts_alert_id;
+DROP INDEX IF EXISTS idx_notification_attempts_delivery_id;
+DROP TABLE IF EXISTS notification_attempts;
+DROP INDEX IF EXISTS idx_notification_deliveries_due;
+DROP INDEX IF EXISTS idx_notification_deliveries_created_at;
+DROP INDEX IF EXISTS idx_notification_deliveries_status;
+DROP INDEX IF EXISTS idx_notification_deliveries_channel;
+DROP INDEX IF EXISTS idx_notification_deliveries_alert_id;
+DROP TABLE IF EXISTS notification_deliveries;
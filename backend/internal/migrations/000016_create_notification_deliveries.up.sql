@@ -0,0 +1,46 @@
+-- 创建 notification_deliveries 表（通知发件箱）
+CREATE TABLE IF NOT EXISTS notification_deliveries (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    alert_id UUID NOT NULL REFERENCES alerts(id) ON DELETE CASCADE,
+    rule_id VARCHAR(255) NOT NULL,
+    channel VARCHAR(50) NOT NULL,
+    target TEXT,
+    escalation BOOLEAN NOT NULL DEFAULT FALSE,
+    action JSONB NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    max_attempts INTEGER NOT NULL DEFAULT 1,
+    redrive_count INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    locked_until TIMESTAMP WITH TIME ZONE,
+    last_error TEXT,
+    sent_at TIMESTAMP WITH TIME ZONE,
+    dead_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_notification_deliveries_alert_id ON notification_deliveries(alert_id);
+CREATE INDEX idx_notification_deliveries_channel ON notification_deliveries(channel);
+CREATE INDEX idx_notification_deliveries_status ON notification_deliveries(status);
+CREATE INDEX idx_notification_deliveries_created_at ON notification_deliveries(created_at);
+-- 投递轮询只扫描待发送的任务
+CREATE INDEX idx_notification_deliveries_due ON notification_deliveries(next_attempt_at)
+    WHERE status IN ('pending', 'retrying');
+
+-- 创建 notification_attempts 表（投递尝试日志）
+CREATE TABLE IF NOT EXISTS notification_attempts (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    delivery_id UUID NOT NULL REFERENCES notification_deliveries(id) ON DELETE CASCADE,
+    alert_id UUID NOT NULL REFERENCES alerts(id) ON DELETE CASCADE,
+    channel VARCHAR(50) NOT NULL,
+    attempt INTEGER NOT NULL,
+    success BOOLEAN NOT NULL,
+    latency_ms BIGINT NOT NULL,
+    error TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_notification_attempts_delivery_id ON notification_attempts(delivery_id);
+CREATE INDEX idx_notification_attempts_alert_id ON notification_attempts(alert_id);
+CREATE INDEX idx_notification_attempts_created_at ON notification_attempts(created_at);
@@ -0,0 +1,13 @@
+DROP INDEX IF EXISTS idx_alert_escalations_next_run_at;
+DROP INDEX IF EXISTS idx_alert_escalations_status;
+DROP INDEX IF EXISTS idx_alert_escalations_policy_id;
+DROP INDEX IF EXISTS idx_alert_escalations_alert_rule;
+DROP TABLE IF EXISTS alert_escalations;
+DROP INDEX IF EXISTS idx_escalation_policies_organization_id;
+DROP TABLE IF EXISTS escalation_policies;
+DROP INDEX IF EXISTS idx_oncall_overrides_ends_at;
+DROP INDEX IF EXISTS idx_oncall_overrides_starts_at;
+DROP INDEX IF EXISTS idx_oncall_overrides_schedule_id;
+DROP TABLE IF EXISTS oncall_overrides;
+DROP INDEX IF EXISTS idx_oncall_schedules_organization_id;
+DROP TABLE IF EXISTS oncall_schedules;
@@ -0,0 +1,69 @@
+-- 创建 oncall_schedules 表（值班排班表，值班层以JSONB存储）
+CREATE TABLE IF NOT EXISTS oncall_schedules (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    description TEXT,
+    timezone VARCHAR(64) NOT NULL DEFAULT 'UTC',
+    layers JSONB NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_oncall_schedules_organization_id ON oncall_schedules(organization_id);
+
+-- 创建 oncall_overrides 表（临时替班）
+CREATE TABLE IF NOT EXISTS oncall_overrides (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    schedule_id UUID NOT NULL REFERENCES oncall_schedules(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES admin_users(id) ON DELETE CASCADE,
+    starts_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    ends_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    reason TEXT,
+    created_by UUID REFERENCES admin_users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    CONSTRAINT chk_oncall_overrides_time_range CHECK (ends_at > starts_at)
+);
+
+CREATE INDEX idx_oncall_overrides_schedule_id ON oncall_overrides(schedule_id);
+CREATE INDEX idx_oncall_overrides_starts_at ON oncall_overrides(starts_at);
+CREATE INDEX idx_oncall_overrides_ends_at ON oncall_overrides(ends_at);
+
+-- 创建 escalation_policies 表（多级升级策略）
+CREATE TABLE IF NOT EXISTS escalation_policies (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    description TEXT,
+    levels JSONB NOT NULL,
+    repeat_count INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_escalation_policies_organization_id ON escalation_policies(organization_id);
+
+-- 创建 alert_escalations 表（告警升级进度）
+CREATE TABLE IF NOT EXISTS alert_escalations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    alert_id UUID NOT NULL REFERENCES alerts(id) ON DELETE CASCADE,
+    rule_id VARCHAR(255) NOT NULL,
+    policy_id UUID REFERENCES escalation_policies(id) ON DELETE SET NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'active',
+    level INTEGER NOT NULL DEFAULT 0,
+    cycle INTEGER NOT NULL DEFAULT 0,
+    notify_count INTEGER NOT NULL DEFAULT 0,
+    next_run_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    locked_until TIMESTAMP WITH TIME ZONE,
+    last_notified_at TIMESTAMP WITH TIME ZONE,
+    stop_reason VARCHAR(50),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX idx_alert_escalations_alert_rule ON alert_escalations(alert_id, rule_id);
+CREATE INDEX idx_alert_escalations_policy_id ON alert_escalations(policy_id);
+CREATE INDEX idx_alert_escalations_status ON alert_escalations(status);
+-- 升级轮询只扫描进行中的升级
+CREATE INDEX idx_alert_escalations_next_run_at ON alert_escalations(next_run_at)
+    WHERE status = 'active';
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_notification_deliveries_group_id;
+ALTER TABLE notification_deliveries DROP COLUMN IF EXISTS group_id;
+DROP INDEX IF EXISTS idx_alert_groups_next_flush_at;
+DROP INDEX IF EXISTS idx_alert_groups_status;
+DROP INDEX IF EXISTS idx_alert_groups_rule_key;
+DROP TABLE IF EXISTS alert_groups;
@@ -0,0 +1,28 @@
+-- 创建 alert_groups 表（告警分组与摘要通知）
+CREATE TABLE IF NOT EXISTS alert_groups (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    rule_id VARCHAR(255) NOT NULL,
+    group_key TEXT NOT NULL,
+    labels JSONB NOT NULL DEFAULT '{}',
+    status VARCHAR(20) NOT NULL DEFAULT 'open',
+    alert_ids JSONB NOT NULL DEFAULT '[]',
+    notified_alert_ids JSONB NOT NULL DEFAULT '[]',
+    notify_count INTEGER NOT NULL DEFAULT 0,
+    next_flush_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    locked_until TIMESTAMP WITH TIME ZONE,
+    last_notified_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX idx_alert_groups_rule_key ON alert_groups(rule_id, group_key);
+CREATE INDEX idx_alert_groups_status ON alert_groups(status);
+-- 分组刷新循环只扫描未关闭的分组
+CREATE INDEX idx_alert_groups_next_flush_at ON alert_groups(next_flush_at)
+    WHERE status = 'open';
+
+-- 分组通知的投递记录引用所属分组
+ALTER TABLE notification_deliveries
+    ADD COLUMN IF NOT EXISTS group_id UUID REFERENCES alert_groups(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_notification_deliveries_group_id ON notification_deliveries(group_id);
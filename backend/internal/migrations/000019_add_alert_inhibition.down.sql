@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_alerts_inhibited_by;
+ALTER TABLE alerts DROP COLUMN IF EXISTS inhibited_by;
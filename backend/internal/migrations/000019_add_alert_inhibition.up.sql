@@ -0,0 +1,3 @@
+-- 告警抑制引用（被抑制的告警仍然入库，并指向根因告警）
+ALTER TABLE alerts ADD COLUMN IF NOT EXISTS inhibited_by UUID REFERENCES alerts(id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS idx_alerts_inhibited_by ON alerts(inhibited_by);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_external_identities_admin_user_id;
+DROP INDEX IF EXISTS idx_external_identities_provider_external_id;
+DROP TABLE IF EXISTS external_identities;
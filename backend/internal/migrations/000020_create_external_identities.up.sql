@@ -0,0 +1,12 @@
+-- 创建 external_identities 表（外部平台用户与管理员映射，用于回调确认告警）
+CREATE TABLE IF NOT EXISTS external_identities (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    admin_user_id UUID NOT NULL REFERENCES admin_users(id) ON DELETE CASCADE,
+    provider VARCHAR(50) NOT NULL,
+    external_id VARCHAR(255) NOT NULL,
+    display_name VARCHAR(255),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX idx_external_identities_provider_external_id ON external_identities(provider, external_id);
+CREATE INDEX idx_external_identities_admin_user_id ON external_identities(admin_user_id);
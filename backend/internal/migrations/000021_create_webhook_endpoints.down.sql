@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_webhook_endpoints_status;
+DROP INDEX IF EXISTS idx_webhook_endpoints_endpoint_key;
+DROP TABLE IF EXISTS webhook_endpoints;
+-- 注意: PostgreSQL 不支持从枚举类型中删除值，alert_type_enum 中的 'webhook_disabled' 保留
@@ -0,0 +1,24 @@
+-- 创建 webhook_endpoints 表（出站Webhook端点的健康状态，持续失败时自动停用）
+CREATE TABLE IF NOT EXISTS webhook_endpoints (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    endpoint_key VARCHAR(64) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    url TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'active',
+    consecutive_failures INTEGER NOT NULL DEFAULT 0,
+    failing_since TIMESTAMP WITH TIME ZONE,
+    last_error TEXT,
+    last_status_code INTEGER,
+    last_success_at TIMESTAMP WITH TIME ZONE,
+    last_failure_at TIMESTAMP WITH TIME ZONE,
+    disabled_at TIMESTAMP WITH TIME ZONE,
+    disabled_alert_id UUID REFERENCES alerts(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX idx_webhook_endpoints_endpoint_key ON webhook_endpoints(endpoint_key);
+CREATE INDEX idx_webhook_endpoints_status ON webhook_endpoints(status);
+
+-- 端点被停用时产生的告警类型
+ALTER TYPE alert_type_enum ADD VALUE IF NOT EXISTS 'webhook_disabled';
@@ -0,0 +1,11 @@
+DROP INDEX IF EXISTS idx_anomaly_thresholds_tag;
+DROP INDEX IF EXISTS idx_anomaly_thresholds_network;
+DROP INDEX IF EXISTS idx_anomaly_thresholds_organization_id;
+DROP TABLE IF EXISTS anomaly_thresholds;
+
+DROP TABLE IF EXISTS link_baselines;
+
+DROP INDEX IF EXISTS idx_link_metrics_pair;
+DROP INDEX IF EXISTS idx_link_metrics_recorded_at;
+DROP TABLE IF EXISTS link_metrics;
+-- 注意: PostgreSQL 不支持从枚举类型中删除值，alert_type_enum 中的 'packet_loss' 保留
@@ -0,0 +1,57 @@
+-- 设备上报的链路指标样本（设备 -> 对端）
+CREATE TABLE link_metrics (
+    id BIGSERIAL PRIMARY KEY,
+    device_id UUID NOT NULL REFERENCES devices(id) ON DELETE CASCADE,
+    peer_device_id UUID NOT NULL REFERENCES devices(id) ON DELETE CASCADE,
+    latency_ms INTEGER,
+    packet_loss DOUBLE PRECISION CHECK (packet_loss IS NULL OR (packet_loss >= 0 AND packet_loss <= 1)),
+    recorded_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_link_metrics_recorded_at ON link_metrics(recorded_at);
+CREATE INDEX idx_link_metrics_pair ON link_metrics(device_id, peer_device_id, recorded_at);
+
+-- 每个设备对、每项指标的自适应基线（EWMA均值与方差）
+CREATE TABLE link_baselines (
+    device_id UUID NOT NULL REFERENCES devices(id) ON DELETE CASCADE,
+    peer_device_id UUID NOT NULL REFERENCES devices(id) ON DELETE CASCADE,
+    metric VARCHAR(20) NOT NULL,
+    mean DOUBLE PRECISION NOT NULL DEFAULT 0,
+    variance DOUBLE PRECISION NOT NULL DEFAULT 0,
+    sample_count INTEGER NOT NULL DEFAULT 0,
+    last_value DOUBLE PRECISION,
+    last_sample_at TIMESTAMPTZ,
+    anomalous_since TIMESTAMPTZ,
+    alerting BOOLEAN NOT NULL DEFAULT FALSE,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (device_id, peer_device_id, metric)
+);
+
+-- 按虚拟网络或设备标签覆盖异常检测阈值
+CREATE TABLE anomaly_thresholds (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    virtual_network_id UUID REFERENCES virtual_networks(id) ON DELETE CASCADE,
+    tag VARCHAR(100),
+    priority INTEGER NOT NULL DEFAULT 100,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    sigma DOUBLE PRECISION CHECK (sigma IS NULL OR sigma > 0),
+    sustain_seconds INTEGER CHECK (sustain_seconds IS NULL OR sustain_seconds >= 0),
+    min_latency_delta_ms INTEGER,
+    min_packet_loss_delta DOUBLE PRECISION,
+    latency_max_ms INTEGER,
+    packet_loss_max DOUBLE PRECISION,
+    offline_after_seconds INTEGER CHECK (offline_after_seconds IS NULL OR offline_after_seconds > 0),
+    comment TEXT,
+    created_by UUID REFERENCES admin_users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT chk_anomaly_thresholds_scope CHECK ((virtual_network_id IS NULL) <> (tag IS NULL))
+);
+
+CREATE INDEX idx_anomaly_thresholds_organization_id ON anomaly_thresholds(organization_id);
+CREATE UNIQUE INDEX idx_anomaly_thresholds_network ON anomaly_thresholds(virtual_network_id) WHERE virtual_network_id IS NOT NULL;
+CREATE UNIQUE INDEX idx_anomaly_thresholds_tag ON anomaly_thresholds(organization_id, tag) WHERE tag IS NOT NULL;
+
+-- 丢包率异常告警类型
+ALTER TYPE alert_type_enum ADD VALUE IF NOT EXISTS 'packet_loss';
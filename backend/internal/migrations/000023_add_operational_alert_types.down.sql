@@ -0,0 +1,7 @@
+ALTER TABLE devices DROP COLUMN IF EXISTS clock_skew_at;
+ALTER TABLE devices DROP COLUMN IF EXISTS clock_skew_ms;
+
+DROP INDEX IF EXISTS idx_device_status_events_device;
+DROP INDEX IF EXISTS idx_device_status_events_occurred_at;
+DROP TABLE IF EXISTS device_status_events;
+-- 注意: PostgreSQL 不支持从枚举类型中删除值，alert_type_enum 中新增的告警类型保留
@@ -0,0 +1,21 @@
+-- 设备上下线状态变化记录（用于检测隧道抖动）
+CREATE TABLE device_status_events (
+    id BIGSERIAL PRIMARY KEY,
+    device_id UUID NOT NULL REFERENCES devices(id) ON DELETE CASCADE,
+    online BOOLEAN NOT NULL,
+    occurred_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_device_status_events_occurred_at ON device_status_events(occurred_at);
+CREATE INDEX idx_device_status_events_device ON device_status_events(device_id, occurred_at);
+
+-- 设备时钟偏差（由设备请求中的签名时间戳计算）
+ALTER TABLE devices ADD COLUMN IF NOT EXISTS clock_skew_ms BIGINT;
+ALTER TABLE devices ADD COLUMN IF NOT EXISTS clock_skew_at TIMESTAMPTZ;
+
+-- 运行状态类告警
+ALTER TYPE alert_type_enum ADD VALUE IF NOT EXISTS 'device_flapping';
+ALTER TYPE alert_type_enum ADD VALUE IF NOT EXISTS 'relay_fallback';
+ALTER TYPE alert_type_enum ADD VALUE IF NOT EXISTS 'ip_pool_exhaustion';
+ALTER TYPE alert_type_enum ADD VALUE IF NOT EXISTS 'enrollment_key_exhaustion';
+ALTER TYPE alert_type_enum ADD VALUE IF NOT EXISTS 'clock_skew';
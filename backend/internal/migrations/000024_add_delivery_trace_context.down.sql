@@ -0,0 +1 @@
+ALTER TABLE notification_deliveries DROP COLUMN IF EXISTS trace_context;
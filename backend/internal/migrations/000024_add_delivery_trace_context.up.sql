@@ -0,0 +1,2 @@
+-- 投递记录保存写入发件箱时的追踪上下文，异步投递与规则引擎处于同一条追踪
+ALTER TABLE notification_deliveries ADD COLUMN IF NOT EXISTS trace_context JSONB;
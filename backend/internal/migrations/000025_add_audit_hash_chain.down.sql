@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_audit_checkpoints_org_sequence;
+DROP TABLE IF EXISTS audit_checkpoints;
+
+DROP INDEX IF EXISTS idx_audit_logs_org_sequence;
+ALTER TABLE audit_logs DROP COLUMN IF EXISTS hash;
+ALTER TABLE audit_logs DROP COLUMN IF EXISTS prev_hash;
+ALTER TABLE audit_logs DROP COLUMN IF EXISTS sequence;
@@ -0,0 +1,20 @@
+-- 审计日志哈希链：每个组织的记录按序号链接，hash = SHA-256(记录内容 + prev_hash)
+-- 启用前的历史记录不参与链，sequence 为空
+ALTER TABLE audit_logs ADD COLUMN IF NOT EXISTS sequence BIGINT;
+ALTER TABLE audit_logs ADD COLUMN IF NOT EXISTS prev_hash VARCHAR(64);
+ALTER TABLE audit_logs ADD COLUMN IF NOT EXISTS hash VARCHAR(64);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_audit_logs_org_sequence ON audit_logs(organization_id, sequence);
+
+-- 链头的签名检查点（Ed25519）
+CREATE TABLE IF NOT EXISTS audit_checkpoints (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    sequence BIGINT NOT NULL,
+    hash VARCHAR(64) NOT NULL,
+    public_key VARCHAR(64) NOT NULL,
+    signature TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_audit_checkpoints_org_sequence ON audit_checkpoints(organization_id, sequence);
@@ -0,0 +1,8 @@
+-- 审计日志导出游标（每个导出目标 × 组织一行，记录已送达的链序号）
+CREATE TABLE IF NOT EXISTS audit_export_cursors (
+    sink VARCHAR(100) NOT NULL,
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    last_sequence BIGINT NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (sink, organization_id)
+);
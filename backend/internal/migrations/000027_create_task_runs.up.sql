@@ -0,0 +1,17 @@
+-- 后台任务执行记录（定时执行与手动触发）
+CREATE TABLE IF NOT EXISTS task_runs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    task_name VARCHAR(100) NOT NULL,
+    trigger VARCHAR(20) NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    instance VARCHAR(255),
+    requested_by UUID,
+    error TEXT,
+    started_at TIMESTAMP WITH TIME ZONE,
+    finished_at TIMESTAMP WITH TIME ZONE,
+    duration_ms BIGINT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_runs_task_created ON task_runs(task_name, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_task_runs_status ON task_runs(status);
@@ -0,0 +1,10 @@
+CREATE OR REPLACE FUNCTION prevent_audit_log_modification()
+RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'Audit logs are immutable and cannot be modified or deleted';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TABLE IF EXISTS retention_runs;
+DROP TRIGGER IF EXISTS update_retention_policies_updated_at ON retention_policies;
+DROP TABLE IF EXISTS retention_policies;
@@ -0,0 +1,44 @@
+-- 数据保留策略：organization_id 为空表示全局默认策略
+CREATE TABLE IF NOT EXISTS retention_policies (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID REFERENCES organizations(id) ON DELETE CASCADE,
+    data_class VARCHAR(50) NOT NULL,
+    retention_days INTEGER NOT NULL CHECK (retention_days > 0),
+    action VARCHAR(20) NOT NULL DEFAULT 'delete',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_retention_policies_org_class
+    ON retention_policies(organization_id, data_class) WHERE organization_id IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_retention_policies_global_class
+    ON retention_policies(data_class) WHERE organization_id IS NULL;
+
+CREATE TRIGGER update_retention_policies_updated_at
+    BEFORE UPDATE ON retention_policies
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- 数据清理执行记录，report 为每个数据类别/组织的清理结果
+CREATE TABLE IF NOT EXISTS retention_runs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    status VARCHAR(20) NOT NULL,
+    dry_run BOOLEAN NOT NULL DEFAULT FALSE,
+    total_purged BIGINT NOT NULL DEFAULT 0,
+    report JSONB NOT NULL DEFAULT '[]',
+    started_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    finished_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_retention_runs_started_at ON retention_runs(started_at DESC);
+
+-- 审计日志仍禁止更新；删除只允许在设置了 edgelink.audit_retention 的事务内进行（保留策略清理）
+CREATE OR REPLACE FUNCTION prevent_audit_log_modification()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'DELETE' AND current_setting('edgelink.audit_retention', true) = 'on' THEN
+        RETURN OLD;
+    END IF;
+    RAISE EXCEPTION 'Audit logs are immutable and cannot be modified or deleted';
+END;
+$$ LANGUAGE plpgsql;
@@ -0,0 +1,12 @@
+DROP INDEX IF EXISTS idx_device_approval_rules_network;
+DROP TABLE IF EXISTS device_approval_rules;
+
+DROP INDEX IF EXISTS idx_devices_pending_approval;
+ALTER TABLE devices DROP COLUMN IF EXISTS approval_note;
+ALTER TABLE devices DROP COLUMN IF EXISTS approval_rule_id;
+ALTER TABLE devices DROP COLUMN IF EXISTS approval_decided_by;
+ALTER TABLE devices DROP COLUMN IF EXISTS approval_decided_at;
+ALTER TABLE devices DROP COLUMN IF EXISTS approval_status;
+
+ALTER TABLE virtual_networks DROP COLUMN IF EXISTS require_approval;
+-- 注意: PostgreSQL 不支持从枚举类型中删除值，alert_type_enum 中的 device_pending_approval 保留
@@ -0,0 +1,29 @@
+-- 虚拟网络可要求新注册的设备经管理员审批后才加入网络
+ALTER TABLE virtual_networks ADD COLUMN IF NOT EXISTS require_approval BOOLEAN NOT NULL DEFAULT false;
+
+-- 设备审批状态，已有设备视为已批准
+ALTER TABLE devices ADD COLUMN IF NOT EXISTS approval_status VARCHAR(20) NOT NULL DEFAULT 'approved'
+    CHECK (approval_status IN ('pending', 'approved', 'rejected'));
+ALTER TABLE devices ADD COLUMN IF NOT EXISTS approval_decided_at TIMESTAMPTZ;
+ALTER TABLE devices ADD COLUMN IF NOT EXISTS approval_decided_by UUID;
+ALTER TABLE devices ADD COLUMN IF NOT EXISTS approval_rule_id UUID;
+ALTER TABLE devices ADD COLUMN IF NOT EXISTS approval_note TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_devices_pending_approval ON devices(virtual_network_id) WHERE approval_status = 'pending';
+
+-- 自动审批规则：注册使用的预共享密钥和/或设备标签匹配时直接批准
+CREATE TABLE IF NOT EXISTS device_approval_rules (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    virtual_network_id UUID NOT NULL REFERENCES virtual_networks(id) ON DELETE CASCADE,
+    pre_shared_key_id UUID REFERENCES pre_shared_keys(id) ON DELETE CASCADE,
+    tag VARCHAR(100),
+    description TEXT,
+    created_by UUID,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (pre_shared_key_id IS NOT NULL OR tag IS NOT NULL)
+);
+
+CREATE INDEX IF NOT EXISTS idx_device_approval_rules_network ON device_approval_rules(virtual_network_id);
+
+-- 等待审批的设备告警，经通知规则引擎通知管理员
+ALTER TYPE alert_type_enum ADD VALUE IF NOT EXISTS 'device_pending_approval';
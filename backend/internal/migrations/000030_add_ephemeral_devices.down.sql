@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_devices_ephemeral_last_seen;
+ALTER TABLE devices DROP COLUMN IF EXISTS ephemeral;
+ALTER TABLE pre_shared_keys DROP COLUMN IF EXISTS ephemeral;
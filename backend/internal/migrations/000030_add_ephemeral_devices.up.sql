@@ -0,0 +1,6 @@
+-- 临时设备：离线超过配置时长后由后台任务自动撤销
+-- 使用标记为ephemeral的预共享密钥注册的设备，或注册时声明ephemeral的设备
+ALTER TABLE pre_shared_keys ADD COLUMN IF NOT EXISTS ephemeral BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE devices ADD COLUMN IF NOT EXISTS ephemeral BOOLEAN NOT NULL DEFAULT false;
+
+CREATE INDEX IF NOT EXISTS idx_devices_ephemeral_last_seen ON devices(COALESCE(last_seen_at, created_at)) WHERE ephemeral;
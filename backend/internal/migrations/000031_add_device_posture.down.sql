@@ -0,0 +1,11 @@
+DROP INDEX IF EXISTS idx_posture_policies_network;
+DROP TABLE IF EXISTS posture_policies;
+
+DROP INDEX IF EXISTS idx_devices_posture_non_compliant;
+ALTER TABLE devices DROP COLUMN IF EXISTS posture_checked_at;
+ALTER TABLE devices DROP COLUMN IF EXISTS posture_allowed_peer_tags;
+ALTER TABLE devices DROP COLUMN IF EXISTS posture_violations;
+ALTER TABLE devices DROP COLUMN IF EXISTS posture_status;
+ALTER TABLE devices DROP COLUMN IF EXISTS posture_reported_at;
+ALTER TABLE devices DROP COLUMN IF EXISTS posture;
+-- 注意: PostgreSQL 不支持从枚举类型中删除值，alert_type_enum 中的 posture_violation 保留
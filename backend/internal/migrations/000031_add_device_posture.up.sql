@@ -0,0 +1,36 @@
+-- 设备上报的安全状态及评估结果，已有设备视为合规
+ALTER TABLE devices ADD COLUMN IF NOT EXISTS posture JSONB;
+ALTER TABLE devices ADD COLUMN IF NOT EXISTS posture_reported_at TIMESTAMPTZ;
+ALTER TABLE devices ADD COLUMN IF NOT EXISTS posture_status VARCHAR(20) NOT NULL DEFAULT 'compliant'
+    CHECK (posture_status IN ('compliant', 'non_compliant'));
+ALTER TABLE devices ADD COLUMN IF NOT EXISTS posture_violations TEXT[] DEFAULT '{}';
+ALTER TABLE devices ADD COLUMN IF NOT EXISTS posture_allowed_peer_tags TEXT[] DEFAULT '{}';
+ALTER TABLE devices ADD COLUMN IF NOT EXISTS posture_checked_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_devices_posture_non_compliant ON devices(virtual_network_id) WHERE posture_status = 'non_compliant';
+
+-- 安全状态策略：作用于网络内全部设备或带有指定标签的设备，不满足时隔离或限制对端
+CREATE TABLE IF NOT EXISTS posture_policies (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    virtual_network_id UUID NOT NULL REFERENCES virtual_networks(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    tag VARCHAR(100),
+    platform VARCHAR(50),
+    enabled BOOLEAN NOT NULL DEFAULT true,
+    min_client_version VARCHAR(50),
+    min_os_version VARCHAR(50),
+    require_firewall BOOLEAN NOT NULL DEFAULT false,
+    require_disk_encryption BOOLEAN NOT NULL DEFAULT false,
+    required_attributes JSONB,
+    enforcement VARCHAR(20) NOT NULL DEFAULT 'quarantine' CHECK (enforcement IN ('quarantine', 'restrict')),
+    allowed_peer_tags TEXT[] DEFAULT '{}',
+    description TEXT,
+    created_by UUID,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_posture_policies_network ON posture_policies(virtual_network_id);
+
+-- 设备不满足安全状态策略的告警
+ALTER TYPE alert_type_enum ADD VALUE IF NOT EXISTS 'posture_violation';
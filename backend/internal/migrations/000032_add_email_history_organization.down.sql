@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_email_history_organization_id;
+ALTER TABLE email_history DROP COLUMN IF EXISTS organization_id;
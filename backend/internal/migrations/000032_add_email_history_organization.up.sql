@@ -0,0 +1,6 @@
+-- 邮件历史所属组织：告警被数据保留任务删除后 alert_id 置空，删除前记下组织，之后仍按组织策略清理
+ALTER TABLE email_history ADD COLUMN IF NOT EXISTS organization_id UUID;
+
+CREATE INDEX IF NOT EXISTS idx_email_history_organization_id ON email_history(organization_id) WHERE organization_id IS NOT NULL;
+
+COMMENT ON COLUMN email_history.organization_id IS '所属组织（关联告警删除前写入）';
@@ -0,0 +1 @@
+ALTER TABLE devices DROP COLUMN IF EXISTS posture_allowed_peer_tag_sets;
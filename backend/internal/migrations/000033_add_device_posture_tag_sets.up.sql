@@ -0,0 +1,9 @@
+-- 不合规设备仍可互通的对端标签按违反的restrict策略分组，对端须满足每一组
+ALTER TABLE devices ADD COLUMN IF NOT EXISTS posture_allowed_peer_tag_sets JSONB NOT NULL DEFAULT '[]';
+
+-- 已有的评估结果是各策略标签的交集，作为单独一组保留，下次评估时按策略重新分组
+UPDATE devices
+SET posture_allowed_peer_tag_sets = jsonb_build_array(to_jsonb(posture_allowed_peer_tags))
+WHERE posture_status = 'non_compliant' AND cardinality(posture_allowed_peer_tags) > 0;
+
+COMMENT ON COLUMN devices.posture_allowed_peer_tag_sets IS '每条违反的restrict策略允许的对端标签，对端须与每一组都有相同标签';
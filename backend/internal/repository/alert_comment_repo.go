@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/edgelink/backend/internal/domain"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// AlertCommentRepository 告警评论仓储接口
+type AlertCommentRepository interface {
+	// Create 创建告警评论
+	Create(ctx context.Context, comment *domain.AlertComment) error
+
+	// FindByAlertID 按时间顺序查找告警的所有评论
+	FindByAlertID(ctx context.Context, alertID uuid.UUID) ([]*domain.AlertComment, error)
+}
+
+// alertCommentRepository AlertComment仓储的GORM实现
+type alertCommentRepository struct {
+	db *gorm.DB
+}
+
+// NewAlertCommentRepository 创建AlertComment仓储实例
+func NewAlertCommentRepository(db *gorm.DB) AlertCommentRepository {
+	return &alertCommentRepository{db: db}
+}
+
+// Create 创建告警评论
+func (r *alertCommentRepository) Create(ctx context.Context, comment *domain.AlertComment) error {
+	return r.db.WithContext(ctx).Create(comment).Error
+}
+
+// FindByAlertID 按时间顺序查找告警的所有评论
+func (r *alertCommentRepository) FindByAlertID(ctx context.Context, alertID uuid.UUID) ([]*domain.AlertComment, error) {
+	var comments []*domain.AlertComment
+	err := r.db.WithContext(ctx).
+		Preload("Author").
+		Where("alert_id = ?", alertID).
+		Order("created_at ASC").
+		Find(&comments).Error
+	return comments, err
+}
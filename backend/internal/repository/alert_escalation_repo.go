@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/edgelink/backend/internal/domain"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// AlertEscalationRepository 告警升级进度仓储接口
+type AlertEscalationRepository interface {
+	// Start 登记告警升级；同一告警和规则已有升级时不重复创建，返回是否新建
+	Start(ctx context.Context, escalation *domain.AlertEscalation) (bool, error)
+
+	// ClaimDue 认领到期的升级，并在lease时长内锁定，防止多个实例重复通知
+	ClaimDue(ctx context.Context, now time.Time, limit int, lease time.Duration) ([]*domain.AlertEscalation, error)
+
+	// Save 保存升级进度并释放锁
+	Save(ctx context.Context, escalation *domain.AlertEscalation) error
+
+	// FindByAlertID 查找告警的所有升级进度
+	FindByAlertID(ctx context.Context, alertID uuid.UUID) ([]*domain.AlertEscalation, error)
+}
+
+// alertEscalationRepository AlertEscalation仓储的GORM实现
+type alertEscalationRepository struct {
+	db *gorm.DB
+}
+
+// NewAlertEscalationRepository 创建AlertEscalation仓储实例
+func NewAlertEscalationRepository(db *gorm.DB) AlertEscalationRepository {
+	return &alertEscalationRepository{db: db}
+}
+
+// Start 登记告警升级
+func (r *alertEscalationRepository) Start(ctx context.Context, escalation *domain.AlertEscalation) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "alert_id"}, {Name: "rule_id"}},
+			DoNothing: true,
+		}).
+		Create(escalation)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// ClaimDue 认领到期的升级
+func (r *alertEscalationRepository) ClaimDue(ctx context.Context, now time.Time, limit int, lease time.Duration) ([]*domain.AlertEscalation, error) {
+	var escalations []*domain.AlertEscalation
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.
+			Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ?", domain.EscalationStatusActive).
+			Where("next_run_at <= ?", now).
+			Where("locked_until IS NULL OR locked_until < ?", now).
+			Order("next_run_at ASC").
+			Limit(limit).
+			Find(&escalations).Error; err != nil {
+			return err
+		}
+
+		if len(escalations) == 0 {
+			return nil
+		}
+
+		ids := make([]uuid.UUID, 0, len(escalations))
+		lockedUntil := now.Add(lease)
+		for _, escalation := range escalations {
+			ids = append(ids, escalation.ID)
+			escalation.LockedUntil = &lockedUntil
+		}
+
+		return tx.Model(&domain.AlertEscalation{}).
+			Where("id IN ?", ids).
+			Update("locked_until", lockedUntil).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return escalations, nil
+}
+
+// Save 保存升级进度并释放锁
+func (r *alertEscalationRepository) Save(ctx context.Context, escalation *domain.AlertEscalation) error {
+	escalation.LockedUntil = nil
+	return r.db.WithContext(ctx).Model(&domain.AlertEscalation{}).
+		Where("id = ?", escalation.ID).
+		Updates(map[string]interface{}{
+			"status":           escalation.Status,
+			"level":            escalation.Level,
+			"cycle":            escalation.Cycle,
+			"notify_count":     escalation.NotifyCount,
+			"next_run_at":      escalation.NextRunAt,
+			"locked_until":     nil,
+			"last_notified_at": escalation.LastNotifiedAt,
+			"stop_reason":      escalation.StopReason,
+			"updated_at":       time.Now(),
+		}).Error
+}
+
+// FindByAlertID 查找告警的所有升级进度
+func (r *alertEscalationRepository) FindByAlertID(ctx context.Context, alertID uuid.UUID) ([]*domain.AlertEscalation, error) {
+	var escalations []*domain.AlertEscalation
+	err := r.db.WithContext(ctx).
+		Where("alert_id = ?", alertID).
+		Order("created_at ASC").
+		Find(&escalations).Error
+	return escalations, err
+}
@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/edgelink/backend/internal/domain"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// AlertGroupRepository 告警分组仓储接口
+type AlertGroupRepository interface {
+	// AddAlert 将告警加入分组；分组不存在时创建，已关闭的分组会重新打开并在flushAt刷新
+	AddAlert(ctx context.Context, group *domain.AlertGroup, alertID uuid.UUID) error
+
+	// FindByID 根据ID查找分组
+	FindByID(ctx context.Context, id uuid.UUID) (*domain.AlertGroup, error)
+
+	// FindByRuleID 查找规则下的分组
+	FindByRuleID(ctx context.Context, ruleID string, openOnly bool) ([]*domain.AlertGroup, error)
+
+	// ClaimDue 认领到期需要刷新的分组，并在lease时长内锁定
+	ClaimDue(ctx context.Context, now time.Time, limit int, lease time.Duration) ([]*domain.AlertGroup, error)
+
+	// Save 保存通知进度、移除已恢复的成员并释放锁；成员为空时关闭分组
+	Save(ctx context.Context, group *domain.AlertGroup, removed domain.AlertIDList) error
+}
+
+// alertGroupRepository AlertGroup仓储的GORM实现
+type alertGroupRepository struct {
+	db *gorm.DB
+}
+
+// NewAlertGroupRepository 创建AlertGroup仓储实例
+func NewAlertGroupRepository(db *gorm.DB) AlertGroupRepository {
+	return &alertGroupRepository{db: db}
+}
+
+// AddAlert 将告警加入分组
+// 使用单条upsert追加成员，多个实例并发写入同一分组时不会丢失告警
+func (r *alertGroupRepository) AddAlert(ctx context.Context, group *domain.AlertGroup, alertID uuid.UUID) error {
+	members := domain.AlertIDList{alertID}
+	return r.db.WithContext(ctx).Exec(`
+		INSERT INTO alert_groups (rule_id, group_key, labels, status, alert_ids, next_flush_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT (rule_id, group_key) DO UPDATE SET
+			alert_ids = CASE
+				WHEN alert_groups.status = 'closed' THEN EXCLUDED.alert_ids
+				WHEN alert_groups.alert_ids @> EXCLUDED.alert_ids THEN alert_groups.alert_ids
+				ELSE alert_groups.alert_ids || EXCLUDED.alert_ids
+			END,
+			notified_alert_ids = CASE
+				WHEN alert_groups.status = 'closed' THEN '[]'::jsonb
+				ELSE alert_groups.notified_alert_ids
+			END,
+			notify_count = CASE
+				WHEN alert_groups.status = 'closed' THEN 0
+				ELSE alert_groups.notify_count
+			END,
+			next_flush_at = CASE
+				WHEN alert_groups.status = 'closed' THEN EXCLUDED.next_flush_at
+				ELSE alert_groups.next_flush_at
+			END,
+			labels = EXCLUDED.labels,
+			status = 'open',
+			updated_at = NOW()`,
+		group.RuleID, group.GroupKey, group.Labels, domain.AlertGroupStatusOpen, members, group.NextFlushAt,
+	).Error
+}
+
+// FindByID 根据ID查找分组
+func (r *alertGroupRepository) FindByID(ctx context.Context, id uuid.UUID) (*domain.AlertGroup, error) {
+	var group domain.AlertGroup
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&group).Error
+	if err != nil {
+		return nil, err
+	}
+	return &group, nil
+}
+
+// FindByRuleID 查找规则下的分组
+func (r *alertGroupRepository) FindByRuleID(ctx context.Context, ruleID string, openOnly bool) ([]*domain.AlertGroup, error) {
+	var groups []*domain.AlertGroup
+	query := r.db.WithContext(ctx).Where("rule_id = ?", ruleID)
+	if openOnly {
+		query = query.Where("status = ?", domain.AlertGroupStatusOpen)
+	}
+	err := query.Order("updated_at DESC").Find(&groups).Error
+	return groups, err
+}
+
+// ClaimDue 认领到期需要刷新的分组
+func (r *alertGroupRepository) ClaimDue(ctx context.Context, now time.Time, limit int, lease time.Duration) ([]*domain.AlertGroup, error) {
+	var groups []*domain.AlertGroup
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.
+			Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ?", domain.AlertGroupStatusOpen).
+			Where("next_flush_at <= ?", now).
+			Where("locked_until IS NULL OR locked_until < ?", now).
+			Order("next_flush_at ASC").
+			Limit(limit).
+			Find(&groups).Error; err != nil {
+			return err
+		}
+
+		if len(groups) == 0 {
+			return nil
+		}
+
+		ids := make([]uuid.UUID, 0, len(groups))
+		lockedUntil := now.Add(lease)
+		for _, group := range groups {
+			ids = append(ids, group.ID)
+			group.LockedUntil = &lockedUntil
+		}
+
+		return tx.Model(&domain.AlertGroup{}).
+			Where("id IN ?", ids).
+			Update("locked_until", lockedUntil).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return groups, nil
+}
+
+// Save 保存通知进度并释放锁
+// 成员列表只做差量移除，认领期间新加入的告警会保留到下一次刷新
+func (r *alertGroupRepository) Save(ctx context.Context, group *domain.AlertGroup, removed domain.AlertIDList) error {
+	if removed == nil {
+		removed = domain.AlertIDList{}
+	}
+	group.LockedUntil = nil
+
+	remaining := gorm.Expr(`COALESCE((
+		SELECT jsonb_agg(member) FROM jsonb_array_elements(alert_ids) AS member
+		WHERE NOT (?::jsonb @> member)
+	), '[]'::jsonb)`, removed)
+
+	return r.db.WithContext(ctx).Model(&domain.AlertGroup{}).
+		Where("id = ?", group.ID).
+		Updates(map[string]interface{}{
+			"alert_ids":          remaining,
+			"status":             gorm.Expr("CASE WHEN ? = '[]'::jsonb THEN ? ELSE ? END", remaining, domain.AlertGroupStatusClosed, domain.AlertGroupStatusOpen),
+			"notified_alert_ids": group.NotifiedAlertIDs,
+			"notify_count":       group.NotifyCount,
+			"next_flush_at":      group.NextFlushAt,
+			"locked_until":       nil,
+			"last_notified_at":   group.LastNotifiedAt,
+			"updated_at":         time.Now(),
+		}).Error
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/edgelink/backend/internal/domain"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // AlertRepository 告警仓储接口
@@ -17,6 +18,9 @@ type AlertRepository interface {
 	// FindByID 根据ID查找告警
 	FindByID(ctx context.Context, id uuid.UUID) (*domain.Alert, error)
 
+	// FindByIDs 根据ID列表批量查找告警（含设备信息）
+	FindByIDs(ctx context.Context, ids []uuid.UUID) ([]*domain.Alert, error)
+
 	// FindByFilters 根据过滤条件查找告警
 	FindByFilters(ctx context.Context, filters *AlertFilters) ([]*domain.Alert, int64, error)
 
@@ -38,6 +42,33 @@ type AlertRepository interface {
 	// Resolve 解决告警
 	Resolve(ctx context.Context, id uuid.UUID) error
 
+	// Reopen 重新打开已确认或已解决的告警
+	Reopen(ctx context.Context, id uuid.UUID) error
+
+	// Assign 指派告警给管理员（assignee为nil时取消指派）
+	Assign(ctx context.Context, id uuid.UUID, assignee *uuid.UUID) error
+
+	// MarkSilenced 记录告警被静默（silenceID为nil时清除静默标记）
+	MarkSilenced(ctx context.Context, id uuid.UUID, silenceID *uuid.UUID) error
+
+	// MarkInhibited 记录告警被根因告警抑制（sourceID为nil时清除抑制标记）
+	MarkInhibited(ctx context.Context, id uuid.UUID, sourceID *uuid.UUID) error
+
+	// ClearInhibition 仅在告警仍被指定根因抑制时清除标记，返回是否清除（多实例下只有一个实例会重新处理）
+	ClearInhibition(ctx context.Context, id uuid.UUID, sourceID uuid.UUID) (bool, error)
+
+	// FindUnresolvedByTypes 查找指定类型的未解决告警（orgID为nil时不限组织，types为空时不限类型，含设备及虚拟网络信息）
+	FindUnresolvedByTypes(ctx context.Context, orgID *uuid.UUID, types []domain.AlertType, limit int) ([]*domain.Alert, error)
+
+	// FindReleasableInhibited 查找根因告警已解决的被抑制告警
+	FindReleasableInhibited(ctx context.Context, limit int) ([]*domain.Alert, error)
+
+	// AcknowledgeByFilters 批量确认符合过滤条件的活跃告警，返回受影响的告警ID
+	AcknowledgeByFilters(ctx context.Context, filters *AlertFilters, acknowledgedBy uuid.UUID) ([]uuid.UUID, error)
+
+	// ResolveByFilters 批量解决符合过滤条件的未解决告警，返回受影响的告警ID
+	ResolveByFilters(ctx context.Context, filters *AlertFilters) ([]uuid.UUID, error)
+
 	// UpdateOccurrence 更新告警出现次数和最后出现时间
 	UpdateOccurrence(ctx context.Context, id uuid.UUID, occurrenceCount int) error
 
@@ -53,8 +84,8 @@ type AlertRepository interface {
 	// CountBySeverity 根据严重程度统计告警数量
 	CountBySeverity(ctx context.Context, severity domain.Severity) (int, error)
 
-	// ResolveByDeviceAndType 解决设备的特定类型告警
-	ResolveByDeviceAndType(ctx context.Context, deviceID uuid.UUID, alertType domain.AlertType) error
+	// ResolveByDeviceAndType 解决设备的特定类型告警，返回被解决的告警ID
+	ResolveByDeviceAndType(ctx context.Context, deviceID uuid.UUID, alertType domain.AlertType) ([]uuid.UUID, error)
 
 	// GetAlertStats 获取告警统计
 	GetAlertStats(ctx context.Context, startTime, endTime time.Time) (*AlertStats, error)
@@ -62,14 +93,18 @@ type AlertRepository interface {
 
 // AlertFilters 告警查询过滤条件
 type AlertFilters struct {
-	DeviceID   *uuid.UUID
-	Severity   *domain.Severity
-	AlertType  *domain.AlertType
-	Status     *domain.AlertStatus
-	StartTime  *time.Time
-	EndTime    *time.Time
-	Limit      int
-	Offset     int
+	DeviceID    *uuid.UUID
+	Severity    *domain.Severity
+	AlertType   *domain.AlertType
+	Status      *domain.AlertStatus
+	AssignedTo  *uuid.UUID
+	Silenced    *bool
+	Inhibited   *bool
+	InhibitedBy *uuid.UUID
+	StartTime   *time.Time
+	EndTime     *time.Time
+	Limit       int
+	Offset      int
 }
 
 // AlertStats 告警统计信息
@@ -111,6 +146,20 @@ func (r *alertRepository) FindByID(ctx context.Context, id uuid.UUID) (*domain.A
 	return &alert, nil
 }
 
+// FindByIDs 根据ID列表批量查找告警
+func (r *alertRepository) FindByIDs(ctx context.Context, ids []uuid.UUID) ([]*domain.Alert, error) {
+	var alerts []*domain.Alert
+	if len(ids) == 0 {
+		return alerts, nil
+	}
+	err := r.db.WithContext(ctx).
+		Preload("Device").
+		Where("id IN ?", ids).
+		Order("created_at ASC").
+		Find(&alerts).Error
+	return alerts, err
+}
+
 // FindByFilters 根据过滤条件查找告警
 func (r *alertRepository) FindByFilters(ctx context.Context, filters *AlertFilters) ([]*domain.Alert, int64, error) {
 	var alerts []*domain.Alert
@@ -119,6 +168,28 @@ func (r *alertRepository) FindByFilters(ctx context.Context, filters *AlertFilte
 	query := r.db.WithContext(ctx).Model(&domain.Alert{}).Preload("Device")
 
 	// 应用过滤条件
+	query = applyAlertFilters(query, filters)
+
+	// 获取总数
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// 应用分页和排序
+	query = query.Order("created_at DESC")
+	if filters.Limit > 0 {
+		query = query.Limit(filters.Limit)
+	}
+	if filters.Offset > 0 {
+		query = query.Offset(filters.Offset)
+	}
+
+	err := query.Find(&alerts).Error
+	return alerts, total, err
+}
+
+// applyAlertFilters 应用告警过滤条件（不含分页）
+func applyAlertFilters(query *gorm.DB, filters *AlertFilters) *gorm.DB {
 	if filters.DeviceID != nil {
 		query = query.Where("device_id = ?", *filters.DeviceID)
 	}
@@ -131,29 +202,33 @@ func (r *alertRepository) FindByFilters(ctx context.Context, filters *AlertFilte
 	if filters.Status != nil {
 		query = query.Where("status = ?", *filters.Status)
 	}
+	if filters.AssignedTo != nil {
+		query = query.Where("assigned_to = ?", *filters.AssignedTo)
+	}
+	if filters.Silenced != nil {
+		if *filters.Silenced {
+			query = query.Where("silenced_by IS NOT NULL")
+		} else {
+			query = query.Where("silenced_by IS NULL")
+		}
+	}
+	if filters.Inhibited != nil {
+		if *filters.Inhibited {
+			query = query.Where("inhibited_by IS NOT NULL")
+		} else {
+			query = query.Where("inhibited_by IS NULL")
+		}
+	}
+	if filters.InhibitedBy != nil {
+		query = query.Where("inhibited_by = ?", *filters.InhibitedBy)
+	}
 	if filters.StartTime != nil {
 		query = query.Where("created_at >= ?", *filters.StartTime)
 	}
 	if filters.EndTime != nil {
 		query = query.Where("created_at <= ?", *filters.EndTime)
 	}
-
-	// 获取总数
-	if err := query.Count(&total).Error; err != nil {
-		return nil, 0, err
-	}
-
-	// 应用分页和排序
-	query = query.Order("created_at DESC")
-	if filters.Limit > 0 {
-		query = query.Limit(filters.Limit)
-	}
-	if filters.Offset > 0 {
-		query = query.Offset(filters.Offset)
-	}
-
-	err := query.Find(&alerts).Error
-	return alerts, total, err
+	return query
 }
 
 // FindActiveAlerts 查找所有活跃告警
@@ -240,6 +315,155 @@ func (r *alertRepository) Resolve(ctx context.Context, id uuid.UUID) error {
 		}).Error
 }
 
+// Reopen 重新打开已确认或已解决的告警
+func (r *alertRepository) Reopen(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).
+		Model(&domain.Alert{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":          domain.AlertStatusActive,
+			"acknowledged_by": nil,
+			"acknowledged_at": nil,
+			"resolved_at":     nil,
+			"updated_at":      time.Now(),
+		}).Error
+}
+
+// Assign 指派告警给管理员（assignee为nil时取消指派）
+func (r *alertRepository) Assign(ctx context.Context, id uuid.UUID, assignee *uuid.UUID) error {
+	now := time.Now()
+	var assignedAt *time.Time
+	if assignee != nil {
+		assignedAt = &now
+	}
+	return r.db.WithContext(ctx).
+		Model(&domain.Alert{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"assigned_to": assignee,
+			"assigned_at": assignedAt,
+			"updated_at":  now,
+		}).Error
+}
+
+// MarkSilenced 记录告警被静默（silenceID为nil时清除静默标记）
+func (r *alertRepository) MarkSilenced(ctx context.Context, id uuid.UUID, silenceID *uuid.UUID) error {
+	return r.db.WithContext(ctx).
+		Model(&domain.Alert{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"silenced_by": silenceID,
+			"updated_at":  time.Now(),
+		}).Error
+}
+
+// MarkInhibited 记录告警被根因告警抑制
+func (r *alertRepository) MarkInhibited(ctx context.Context, id uuid.UUID, sourceID *uuid.UUID) error {
+	return r.db.WithContext(ctx).
+		Model(&domain.Alert{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"inhibited_by": sourceID,
+			"updated_at":   time.Now(),
+		}).Error
+}
+
+// ClearInhibition 仅在告警仍被指定根因抑制时清除标记
+func (r *alertRepository) ClearInhibition(ctx context.Context, id uuid.UUID, sourceID uuid.UUID) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&domain.Alert{}).
+		Where("id = ? AND inhibited_by = ?", id, sourceID).
+		Updates(map[string]interface{}{
+			"inhibited_by": nil,
+			"updated_at":   time.Now(),
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// FindUnresolvedByTypes 查找指定类型的未解决告警
+// 按组织查询时只返回关联设备属于该组织的告警，limit在组织过滤之后生效
+func (r *alertRepository) FindUnresolvedByTypes(ctx context.Context, orgID *uuid.UUID, types []domain.AlertType, limit int) ([]*domain.Alert, error) {
+	var alerts []*domain.Alert
+	query := r.db.WithContext(ctx).
+		Preload("Device.VirtualNetwork").
+		Where("status <> ?", domain.AlertStatusResolved)
+
+	if orgID != nil {
+		// 非设备告警（网络、注册密钥等）按metadata中的organization_id归属组织
+		query = query.Where("(device_id IN (SELECT d.id FROM devices d JOIN virtual_networks vn ON vn.id = d.virtual_network_id WHERE vn.organization_id = ?) "+
+			"OR (device_id IS NULL AND metadata->>'organization_id' = ?))", *orgID, orgID.String())
+	}
+	if len(types) > 0 {
+		query = query.Where("type IN ?", types)
+	}
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+
+	err := query.Order("created_at ASC").Find(&alerts).Error
+	return alerts, err
+}
+
+// FindReleasableInhibited 查找根因告警已解决的被抑制告警
+func (r *alertRepository) FindReleasableInhibited(ctx context.Context, limit int) ([]*domain.Alert, error) {
+	var alerts []*domain.Alert
+	query := r.db.WithContext(ctx).
+		Where("status <> ?", domain.AlertStatusResolved).
+		Where("inhibited_by IS NOT NULL").
+		Where("EXISTS (SELECT 1 FROM alerts AS source WHERE source.id = alerts.inhibited_by AND source.status = ?)", domain.AlertStatusResolved).
+		Order("created_at ASC")
+
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+
+	err := query.Find(&alerts).Error
+	return alerts, err
+}
+
+// AcknowledgeByFilters 批量确认符合过滤条件的活跃告警，返回受影响的告警ID
+func (r *alertRepository) AcknowledgeByFilters(ctx context.Context, filters *AlertFilters, acknowledgedBy uuid.UUID) ([]uuid.UUID, error) {
+	now := time.Now()
+	return r.updateByFilters(ctx, filters, "status = ?", domain.AlertStatusActive, map[string]interface{}{
+		"status":          domain.AlertStatusAcknowledged,
+		"acknowledged_by": acknowledgedBy,
+		"acknowledged_at": now,
+		"updated_at":      now,
+	})
+}
+
+// ResolveByFilters 批量解决符合过滤条件的未解决告警，返回受影响的告警ID
+func (r *alertRepository) ResolveByFilters(ctx context.Context, filters *AlertFilters) ([]uuid.UUID, error) {
+	now := time.Now()
+	return r.updateByFilters(ctx, filters, "status <> ?", domain.AlertStatusResolved, map[string]interface{}{
+		"status":      domain.AlertStatusResolved,
+		"resolved_at": now,
+		"updated_at":  now,
+	})
+}
+
+// updateByFilters 以单条UPDATE ... RETURNING更新符合过滤条件和状态条件的告警，只返回实际更新的告警ID
+// 并发修改了状态的告警不满足状态条件，不会被计入
+func (r *alertRepository) updateByFilters(ctx context.Context, filters *AlertFilters, statusCond string, status domain.AlertStatus, updates map[string]interface{}) ([]uuid.UUID, error) {
+	var updated []domain.Alert
+	err := applyAlertFilters(r.db.WithContext(ctx).Model(&updated), filters).
+		Clauses(clause.Returning{Columns: []clause.Column{{Name: "id"}}}).
+		Where(statusCond, status).
+		Updates(updates).Error
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]uuid.UUID, len(updated))
+	for i := range updated {
+		ids[i] = updated[i].ID
+	}
+	return ids, nil
+}
+
 // UpdateOccurrence 更新告警出现次数和最后出现时间
 func (r *alertRepository) UpdateOccurrence(ctx context.Context, id uuid.UUID, occurrenceCount int) error {
 	now := time.Now()
@@ -278,17 +502,36 @@ func (r *alertRepository) FindActiveByDeviceAndType(ctx context.Context, deviceI
 	return &alert, nil
 }
 
-// ResolveByDeviceAndType 解决设备的特定类型告警
-func (r *alertRepository) ResolveByDeviceAndType(ctx context.Context, deviceID uuid.UUID, alertType domain.AlertType) error {
+// ResolveByDeviceAndType 解决设备的特定类型告警，返回被解决的告警ID
+func (r *alertRepository) ResolveByDeviceAndType(ctx context.Context, deviceID uuid.UUID, alertType domain.AlertType) ([]uuid.UUID, error) {
+	var alertIDs []uuid.UUID
 	now := time.Now()
-	return r.db.WithContext(ctx).
-		Model(&domain.Alert{}).
-		Where("device_id = ? AND type = ? AND status = ?", deviceID, alertType, domain.AlertStatusActive).
-		Updates(map[string]interface{}{
-			"status":      domain.AlertStatusResolved,
-			"resolved_at": now,
-			"updated_at":  now,
-		}).Error
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&domain.Alert{}).
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("device_id = ? AND type = ? AND status = ?", deviceID, alertType, domain.AlertStatusActive).
+			Pluck("id", &alertIDs).Error; err != nil {
+			return err
+		}
+
+		if len(alertIDs) == 0 {
+			return nil
+		}
+
+		return tx.Model(&domain.Alert{}).
+			Where("id IN ?", alertIDs).
+			Updates(map[string]interface{}{
+				"status":      domain.AlertStatusResolved,
+				"resolved_at": now,
+				"updated_at":  now,
+			}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return alertIDs, nil
 }
 
 // GetAlertStats 获取告警统计
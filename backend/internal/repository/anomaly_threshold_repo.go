@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/edgelink/backend/internal/domain"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// AnomalyThresholdRepository 异常检测阈值覆盖仓储接口
+type AnomalyThresholdRepository interface {
+	// Create 创建阈值覆盖
+	Create(ctx context.Context, threshold *domain.AnomalyThreshold) error
+
+	// FindByID 根据ID查找
+	FindByID(ctx context.Context, id uuid.UUID) (*domain.AnomalyThreshold, error)
+
+	// List 列出阈值覆盖，orgID为nil时返回全部
+	List(ctx context.Context, orgID *uuid.UUID) ([]*domain.AnomalyThreshold, error)
+
+	// Update 更新阈值覆盖
+	Update(ctx context.Context, threshold *domain.AnomalyThreshold) error
+
+	// Delete 删除阈值覆盖
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+// anomalyThresholdRepository AnomalyThreshold仓储的GORM实现
+type anomalyThresholdRepository struct {
+	db *gorm.DB
+}
+
+// NewAnomalyThresholdRepository 创建AnomalyThreshold仓储实例
+func NewAnomalyThresholdRepository(db *gorm.DB) AnomalyThresholdRepository {
+	return &anomalyThresholdRepository{db: db}
+}
+
+// Create 创建阈值覆盖
+func (r *anomalyThresholdRepository) Create(ctx context.Context, threshold *domain.AnomalyThreshold) error {
+	return r.db.WithContext(ctx).Create(threshold).Error
+}
+
+// FindByID 根据ID查找
+func (r *anomalyThresholdRepository) FindByID(ctx context.Context, id uuid.UUID) (*domain.AnomalyThreshold, error) {
+	var threshold domain.AnomalyThreshold
+	err := r.db.WithContext(ctx).
+		Where("id = ?", id).
+		First(&threshold).Error
+	if err != nil {
+		return nil, err
+	}
+	return &threshold, nil
+}
+
+// List 列出阈值覆盖
+func (r *anomalyThresholdRepository) List(ctx context.Context, orgID *uuid.UUID) ([]*domain.AnomalyThreshold, error) {
+	query := r.db.WithContext(ctx).Model(&domain.AnomalyThreshold{})
+	if orgID != nil {
+		query = query.Where("organization_id = ?", *orgID)
+	}
+
+	var thresholds []*domain.AnomalyThreshold
+	err := query.Order("priority ASC, created_at ASC").Find(&thresholds).Error
+	return thresholds, err
+}
+
+// Update 更新阈值覆盖
+func (r *anomalyThresholdRepository) Update(ctx context.Context, threshold *domain.AnomalyThreshold) error {
+	return r.db.WithContext(ctx).Save(threshold).Error
+}
+
+// Delete 删除阈值覆盖
+func (r *anomalyThresholdRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Delete(&domain.AnomalyThreshold{}, "id = ?", id).Error
+}
@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/edgelink/backend/internal/domain"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// AuditCheckpointRepository 审计哈希链检查点仓储接口（仅追加）
+type AuditCheckpointRepository interface {
+	// Create 创建检查点
+	Create(ctx context.Context, checkpoint *domain.AuditCheckpoint) error
+
+	// FindByOrganization 按序号升序查找组织的所有检查点
+	FindByOrganization(ctx context.Context, organizationID uuid.UUID) ([]*domain.AuditCheckpoint, error)
+
+	// FindLatest 查找组织最新的检查点
+	FindLatest(ctx context.Context, organizationID uuid.UUID) (*domain.AuditCheckpoint, error)
+
+	// ListOrganizations 列出存在检查点的组织
+	ListOrganizations(ctx context.Context) ([]uuid.UUID, error)
+}
+
+// auditCheckpointRepository AuditCheckpoint仓储的GORM实现
+type auditCheckpointRepository struct {
+	db *gorm.DB
+}
+
+// NewAuditCheckpointRepository 创建AuditCheckpoint仓储实例
+func NewAuditCheckpointRepository(db *gorm.DB) AuditCheckpointRepository {
+	return &auditCheckpointRepository{db: db}
+}
+
+// Create 创建检查点
+func (r *auditCheckpointRepository) Create(ctx context.Context, checkpoint *domain.AuditCheckpoint) error {
+	return r.db.WithContext(ctx).Create(checkpoint).Error
+}
+
+// FindByOrganization 按序号升序查找组织的所有检查点
+func (r *auditCheckpointRepository) FindByOrganization(ctx context.Context, organizationID uuid.UUID) ([]*domain.AuditCheckpoint, error) {
+	var checkpoints []*domain.AuditCheckpoint
+	err := r.db.WithContext(ctx).
+		Where("organization_id = ?", organizationID).
+		Order("sequence ASC").
+		Find(&checkpoints).Error
+	return checkpoints, err
+}
+
+// FindLatest 查找组织最新的检查点
+func (r *auditCheckpointRepository) FindLatest(ctx context.Context, organizationID uuid.UUID) (*domain.AuditCheckpoint, error) {
+	var checkpoint domain.AuditCheckpoint
+	err := r.db.WithContext(ctx).
+		Where("organization_id = ?", organizationID).
+		Order("sequence DESC").
+		First(&checkpoint).Error
+	if err != nil {
+		return nil, err
+	}
+	return &checkpoint, nil
+}
+
+// ListOrganizations 列出存在检查点的组织
+func (r *auditCheckpointRepository) ListOrganizations(ctx context.Context) ([]uuid.UUID, error) {
+	var orgIDs []uuid.UUID
+	err := r.db.WithContext(ctx).Model(&domain.AuditCheckpoint{}).
+		Distinct().
+		Order("organization_id").
+		Pluck("organization_id", &orgIDs).Error
+	return orgIDs, err
+}
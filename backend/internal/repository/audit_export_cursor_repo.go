@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/edgelink/backend/internal/domain"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// AuditExportCursorRepository 审计日志导出游标仓储接口
+type AuditExportCursorRepository interface {
+	// FindBySink 查找导出目标在各组织上的游标（组织ID -> 已送达的链序号）
+	FindBySink(ctx context.Context, sink string) (map[uuid.UUID]int64, error)
+
+	// Save 保存导出目标在组织上的游标
+	Save(ctx context.Context, sink string, organizationID uuid.UUID, lastSequence int64) error
+}
+
+// auditExportCursorRepository AuditExportCursor仓储的GORM实现
+type auditExportCursorRepository struct {
+	db *gorm.DB
+}
+
+// NewAuditExportCursorRepository 创建AuditExportCursor仓储实例
+func NewAuditExportCursorRepository(db *gorm.DB) AuditExportCursorRepository {
+	return &auditExportCursorRepository{db: db}
+}
+
+// FindBySink 查找导出目标在各组织上的游标
+func (r *auditExportCursorRepository) FindBySink(ctx context.Context, sink string) (map[uuid.UUID]int64, error) {
+	var cursors []*domain.AuditExportCursor
+	if err := r.db.WithContext(ctx).
+		Where("sink = ?", sink).
+		Find(&cursors).Error; err != nil {
+		return nil, err
+	}
+
+	result := make(map[uuid.UUID]int64, len(cursors))
+	for _, cursor := range cursors {
+		result[cursor.OrganizationID] = cursor.LastSequence
+	}
+	return result, nil
+}
+
+// Save 保存导出目标在组织上的游标
+func (r *auditExportCursorRepository) Save(ctx context.Context, sink string, organizationID uuid.UUID, lastSequence int64) error {
+	cursor := &domain.AuditExportCursor{
+		Sink:           sink,
+		OrganizationID: organizationID,
+		LastSequence:   lastSequence,
+		UpdatedAt:      time.Now(),
+	}
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "sink"}, {Name: "organization_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"last_sequence", "updated_at"}),
+		}).
+		Create(cursor).Error
+}
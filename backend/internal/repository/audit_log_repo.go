@@ -2,6 +2,8 @@ package repository
 
 import (
 	"context"
+	"fmt"
+	"sort"
 	"time"
 
 	"github.com/edgelink/backend/internal/domain"
@@ -11,10 +13,10 @@ import (
 
 // AuditLogRepository 审计日志仓储接口（只读仓储，不可变）
 type AuditLogRepository interface {
-	// Create 创建新审计日志（唯一的写操作）
+	// Create 创建新审计日志（唯一的写操作），追加到所属组织哈希链的末尾
 	Create(ctx context.Context, log *domain.AuditLog) error
 
-	// CreateBatch 批量创建审计日志
+	// CreateBatch 批量创建审计日志，按传入顺序追加到各组织的哈希链
 	CreateBatch(ctx context.Context, logs []*domain.AuditLog) error
 
 	// FindByID 根据ID查找审计日志
@@ -37,6 +39,21 @@ type AuditLogRepository interface {
 
 	// GetAuditStats 获取审计统计信息
 	GetAuditStats(ctx context.Context, organizationID uuid.UUID, startTime, endTime time.Time) (*AuditStats, error)
+
+	// FindChain 按序号升序查找组织哈希链中序号大于afterSequence的记录
+	FindChain(ctx context.Context, organizationID uuid.UUID, afterSequence int64, limit int) ([]*domain.AuditLog, error)
+
+	// FindChainHead 查找组织哈希链的最后一条记录
+	FindChainHead(ctx context.Context, organizationID uuid.UUID) (*domain.AuditLog, error)
+
+	// ListChainedOrganizations 列出存在哈希链记录的组织
+	ListChainedOrganizations(ctx context.Context) ([]uuid.UUID, error)
+
+	// CountUnchained 统计组织中启用哈希链之前的记录数
+	CountUnchained(ctx context.Context, organizationID uuid.UUID) (int64, error)
+
+	// ExportByFilters 按创建时间升序分批读取符合条件的全部审计日志（忽略Limit/Offset），逐批交给fn处理
+	ExportByFilters(ctx context.Context, filters *AuditLogFilters, batchSize int, fn func([]*domain.AuditLog) error) error
 }
 
 // AuditLogFilters 审计日志查询过滤条件
@@ -75,12 +92,85 @@ func NewAuditLogRepository(db *gorm.DB) AuditLogRepository {
 // Create 创建新审计日志
 func (r *auditLogRepository) Create(ctx context.Context, log *domain.AuditLog) error {
 	// 审计日志是不可变的，只能插入
-	return r.db.WithContext(ctx).Create(log).Error
+	return r.CreateBatch(ctx, []*domain.AuditLog{log})
 }
 
 // CreateBatch 批量创建审计日志
 func (r *auditLogRepository) CreateBatch(ctx context.Context, logs []*domain.AuditLog) error {
-	return r.db.WithContext(ctx).Create(logs).Error
+	if len(logs) == 0 {
+		return nil
+	}
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		byOrg := make(map[uuid.UUID][]*domain.AuditLog)
+		orgIDs := make([]uuid.UUID, 0, 1)
+		for _, log := range logs {
+			if _, ok := byOrg[log.OrganizationID]; !ok {
+				orgIDs = append(orgIDs, log.OrganizationID)
+			}
+			byOrg[log.OrganizationID] = append(byOrg[log.OrganizationID], log)
+		}
+
+		// 按固定顺序加锁，避免并发批量写入互相等待
+		sort.Slice(orgIDs, func(i, j int) bool {
+			return orgIDs[i].String() < orgIDs[j].String()
+		})
+		for _, orgID := range orgIDs {
+			if err := chainAuditLogs(tx, orgID, byOrg[orgID]); err != nil {
+				return err
+			}
+		}
+
+		return tx.Create(logs).Error
+	})
+}
+
+// chainAuditLogs 为同一组织的新记录分配序号并计算链哈希
+// 事务级advisory lock串行化同一组织的追加，事务结束时自动释放
+func chainAuditLogs(tx *gorm.DB, organizationID uuid.UUID, logs []*domain.AuditLog) error {
+	if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtextextended(?, 0))", "audit_logs:"+organizationID.String()).Error; err != nil {
+		return fmt.Errorf("failed to lock audit chain: %w", err)
+	}
+
+	var heads []*domain.AuditLog
+	if err := tx.Select("sequence", "hash").
+		Where("organization_id = ? AND sequence IS NOT NULL", organizationID).
+		Order("sequence DESC").
+		Limit(1).
+		Find(&heads).Error; err != nil {
+		return fmt.Errorf("failed to load audit chain head: %w", err)
+	}
+
+	sequence, prevHash := int64(0), domain.AuditGenesisHash
+	if len(heads) > 0 && heads[0].Sequence != nil && heads[0].Hash != nil {
+		sequence, prevHash = *heads[0].Sequence, *heads[0].Hash
+	}
+
+	now := time.Now()
+	for _, log := range logs {
+		// ID和时间参与哈希，不能交给数据库默认值生成
+		if log.ID == uuid.Nil {
+			log.ID = uuid.New()
+		}
+		if log.CreatedAt.IsZero() {
+			log.CreatedAt = now
+		}
+		log.CreatedAt = log.CreatedAt.Truncate(time.Microsecond)
+
+		sequence++
+		seq, prev := sequence, prevHash
+		log.Sequence = &seq
+		log.PrevHash = &prev
+
+		hash, err := log.ComputeHash()
+		if err != nil {
+			return fmt.Errorf("failed to hash audit log: %w", err)
+		}
+		log.Hash = &hash
+		prevHash = hash
+	}
+
+	return nil
 }
 
 // FindByID 根据ID查找审计日志
@@ -101,9 +191,28 @@ func (r *auditLogRepository) FindByFilters(ctx context.Context, filters *AuditLo
 	var logs []*domain.AuditLog
 	var total int64
 
-	query := r.db.WithContext(ctx).Model(&domain.AuditLog{}).Preload("Organization")
+	query := applyAuditLogFilters(r.db.WithContext(ctx).Model(&domain.AuditLog{}).Preload("Organization"), filters)
+
+	// 获取总数
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// 应用分页和排序（按时间倒序）
+	query = query.Order("created_at DESC")
+	if filters.Limit > 0 {
+		query = query.Limit(filters.Limit)
+	}
+	if filters.Offset > 0 {
+		query = query.Offset(filters.Offset)
+	}
+
+	err := query.Find(&logs).Error
+	return logs, total, err
+}
 
-	// 应用过滤条件
+// applyAuditLogFilters 应用过滤条件
+func applyAuditLogFilters(query *gorm.DB, filters *AuditLogFilters) *gorm.DB {
 	if filters.OrganizationID != nil {
 		query = query.Where("organization_id = ?", *filters.OrganizationID)
 	}
@@ -128,23 +237,7 @@ func (r *auditLogRepository) FindByFilters(ctx context.Context, filters *AuditLo
 	if filters.EndTime != nil {
 		query = query.Where("created_at <= ?", *filters.EndTime)
 	}
-
-	// 获取总数
-	if err := query.Count(&total).Error; err != nil {
-		return nil, 0, err
-	}
-
-	// 应用分页和排序（按时间倒序）
-	query = query.Order("created_at DESC")
-	if filters.Limit > 0 {
-		query = query.Limit(filters.Limit)
-	}
-	if filters.Offset > 0 {
-		query = query.Offset(filters.Offset)
-	}
-
-	err := query.Find(&logs).Error
-	return logs, total, err
+	return query
 }
 
 // FindByOrganizationID 查找组织的所有审计日志
@@ -281,3 +374,81 @@ func (r *auditLogRepository) GetAuditStats(ctx context.Context, organizationID u
 
 	return &stats, nil
 }
+
+// FindChain 按序号升序查找组织哈希链中的记录
+func (r *auditLogRepository) FindChain(ctx context.Context, organizationID uuid.UUID, afterSequence int64, limit int) ([]*domain.AuditLog, error) {
+	var logs []*domain.AuditLog
+	query := r.db.WithContext(ctx).
+		Where("organization_id = ? AND sequence > ?", organizationID, afterSequence).
+		Order("sequence ASC")
+
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+
+	err := query.Find(&logs).Error
+	return logs, err
+}
+
+// FindChainHead 查找组织哈希链的最后一条记录
+func (r *auditLogRepository) FindChainHead(ctx context.Context, organizationID uuid.UUID) (*domain.AuditLog, error) {
+	var log domain.AuditLog
+	err := r.db.WithContext(ctx).
+		Where("organization_id = ? AND sequence IS NOT NULL", organizationID).
+		Order("sequence DESC").
+		First(&log).Error
+	if err != nil {
+		return nil, err
+	}
+	return &log, nil
+}
+
+// ListChainedOrganizations 列出存在哈希链记录的组织
+func (r *auditLogRepository) ListChainedOrganizations(ctx context.Context) ([]uuid.UUID, error) {
+	var orgIDs []uuid.UUID
+	err := r.db.WithContext(ctx).Model(&domain.AuditLog{}).
+		Where("sequence IS NOT NULL").
+		Distinct().
+		Order("organization_id").
+		Pluck("organization_id", &orgIDs).Error
+	return orgIDs, err
+}
+
+// CountUnchained 统计组织中启用哈希链之前的记录数
+func (r *auditLogRepository) CountUnchained(ctx context.Context, organizationID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&domain.AuditLog{}).
+		Where("organization_id = ? AND sequence IS NULL", organizationID).
+		Count(&count).Error
+	return count, err
+}
+
+// ExportByFilters 按(created_at, id)键集分页读取，导出期间有新记录写入也不会跳过或重复
+func (r *auditLogRepository) ExportByFilters(ctx context.Context, filters *AuditLogFilters, batchSize int, fn func([]*domain.AuditLog) error) error {
+	var lastCreatedAt time.Time
+	var lastID uuid.UUID
+
+	for first := true; ; first = false {
+		query := applyAuditLogFilters(r.db.WithContext(ctx).Model(&domain.AuditLog{}), filters)
+		if !first {
+			query = query.Where("(created_at, id) > (?, ?)", lastCreatedAt, lastID)
+		}
+
+		var logs []*domain.AuditLog
+		if err := query.Order("created_at ASC, id ASC").Limit(batchSize).Find(&logs).Error; err != nil {
+			return err
+		}
+		if len(logs) == 0 {
+			return nil
+		}
+		if err := fn(logs); err != nil {
+			return err
+		}
+		if len(logs) < batchSize {
+			return nil
+		}
+
+		last := logs[len(logs)-1]
+		lastCreatedAt, lastID = last.CreatedAt, last.ID
+	}
+}
@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/edgelink/backend/internal/domain"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// DeviceApprovalRuleRepository 设备自动审批规则仓储接口
+type DeviceApprovalRuleRepository interface {
+	// Create 创建规则
+	Create(ctx context.Context, rule *domain.DeviceApprovalRule) error
+
+	// FindByID 根据ID查找
+	FindByID(ctx context.Context, id uuid.UUID) (*domain.DeviceApprovalRule, error)
+
+	// FindByVirtualNetwork 列出虚拟网络的全部规则
+	FindByVirtualNetwork(ctx context.Context, vnID uuid.UUID) ([]*domain.DeviceApprovalRule, error)
+
+	// Delete 删除规则
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+// deviceApprovalRuleRepository DeviceApprovalRule仓储的GORM实现
+type deviceApprovalRuleRepository struct {
+	db *gorm.DB
+}
+
+// NewDeviceApprovalRuleRepository 创建DeviceApprovalRule仓储实例
+func NewDeviceApprovalRuleRepository(db *gorm.DB) DeviceApprovalRuleRepository {
+	return &deviceApprovalRuleRepository{db: db}
+}
+
+// Create 创建规则
+func (r *deviceApprovalRuleRepository) Create(ctx context.Context, rule *domain.DeviceApprovalRule) error {
+	return r.db.WithContext(ctx).Create(rule).Error
+}
+
+// FindByID 根据ID查找
+func (r *deviceApprovalRuleRepository) FindByID(ctx context.Context, id uuid.UUID) (*domain.DeviceApprovalRule, error) {
+	var rule domain.DeviceApprovalRule
+	err := r.db.WithContext(ctx).
+		Where("id = ?", id).
+		First(&rule).Error
+	if err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// FindByVirtualNetwork 列出虚拟网络的全部规则
+func (r *deviceApprovalRuleRepository) FindByVirtualNetwork(ctx context.Context, vnID uuid.UUID) ([]*domain.DeviceApprovalRule, error) {
+	var rules []*domain.DeviceApprovalRule
+	err := r.db.WithContext(ctx).
+		Where("virtual_network_id = ?", vnID).
+		Order("created_at ASC").
+		Find(&rules).Error
+	return rules, err
+}
+
+// Delete 删除规则
+func (r *deviceApprovalRuleRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Delete(&domain.DeviceApprovalRule{}, "id = ?", id).Error
+}
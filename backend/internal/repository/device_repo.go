@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/edgelink/backend/internal/domain"
 	"github.com/google/uuid"
@@ -12,12 +13,37 @@ import (
 type DeviceRepository interface {
 	Create(ctx context.Context, device *domain.Device) error
 	FindByID(ctx context.Context, id uuid.UUID) (*domain.Device, error)
+	FindByIDs(ctx context.Context, ids []uuid.UUID) ([]domain.Device, error)
 	FindByPublicKey(ctx context.Context, publicKey string) (*domain.Device, error)
 	FindByVirtualNetwork(ctx context.Context, vnID uuid.UUID, online *bool) ([]domain.Device, error)
+	FindVirtualIPs(ctx context.Context, vnID uuid.UUID) ([]string, error)
 	Update(ctx context.Context, device *domain.Device) error
 	UpdateOnlineStatus(ctx context.Context, id uuid.UUID, online bool) error
+	ChangeOnlineStatus(ctx context.Context, id uuid.UUID, online bool) (bool, error)
+	UpdateClockSkew(ctx context.Context, id uuid.UUID, skewMs int64, measuredAt time.Time) error
+	FindClockSkewed(ctx context.Context, since time.Time, minSkewMs int64) ([]domain.Device, error)
+	CountByVirtualNetworks(ctx context.Context) (map[uuid.UUID]int, error)
 	Delete(ctx context.Context, id uuid.UUID) error
 	CountByOrganization(ctx context.Context, orgID *uuid.UUID) (int, error)
+	SetApprovalStatus(ctx context.Context, id uuid.UUID, from []domain.DeviceApprovalStatus, to domain.DeviceApprovalStatus, decidedBy *uuid.UUID, note string, decidedAt time.Time) (bool, error)
+	FindPendingApproval(ctx context.Context) ([]domain.Device, error)
+	FindStaleEphemeral(ctx context.Context, before time.Time, limit int) ([]domain.Device, error)
+	DeleteStaleEphemeral(ctx context.Context, id uuid.UUID, before time.Time) (bool, error)
+	UpdatePosture(ctx context.Context, device *domain.Device) error
+	FindPostureNonCompliant(ctx context.Context) ([]domain.Device, error)
+	FindByFilters(ctx context.Context, filters *DeviceFilters, limit int) ([]domain.Device, error)
+	UpdateNameAndTags(ctx context.Context, device *domain.Device) error
+	MoveToNetwork(ctx context.Context, device *domain.Device, fromVNID uuid.UUID) (bool, error)
+}
+
+// DeviceFilters 设备批量操作的过滤条件，各条件同时满足
+type DeviceFilters struct {
+	DeviceIDs        []uuid.UUID
+	VirtualNetworkID *uuid.UUID
+	Tag              *string
+	Platform         *domain.Platform
+	Online           *bool
+	ApprovalStatus   *domain.DeviceApprovalStatus
 }
 
 type deviceRepository struct {
@@ -44,6 +70,19 @@ func (r *deviceRepository) FindByID(ctx context.Context, id uuid.UUID) (*domain.
 	return &device, nil
 }
 
+// FindByIDs 批量查询设备（预加载虚拟网络以获取组织）
+func (r *deviceRepository) FindByIDs(ctx context.Context, ids []uuid.UUID) ([]domain.Device, error) {
+	var devices []domain.Device
+	if len(ids) == 0 {
+		return devices, nil
+	}
+	err := r.db.WithContext(ctx).
+		Preload("VirtualNetwork").
+		Where("id IN ?", ids).
+		Find(&devices).Error
+	return devices, err
+}
+
 func (r *deviceRepository) FindByPublicKey(ctx context.Context, publicKey string) (*domain.Device, error) {
 	var device domain.Device
 	err := r.db.WithContext(ctx).
@@ -72,6 +111,16 @@ func (r *deviceRepository) FindByVirtualNetwork(ctx context.Context, vnID uuid.U
 	return devices, err
 }
 
+// FindVirtualIPs 查询虚拟网络中已被设备占用的虚拟IP
+func (r *deviceRepository) FindVirtualIPs(ctx context.Context, vnID uuid.UUID) ([]string, error) {
+	var ips []string
+	err := r.db.WithContext(ctx).
+		Model(&domain.Device{}).
+		Where("virtual_network_id = ?", vnID).
+		Pluck("host(virtual_ip)", &ips).Error
+	return ips, err
+}
+
 func (r *deviceRepository) Update(ctx context.Context, device *domain.Device) error {
 	return r.db.WithContext(ctx).Save(device).Error
 }
@@ -86,6 +135,58 @@ func (r *deviceRepository) UpdateOnlineStatus(ctx context.Context, id uuid.UUID,
 		}).Error
 }
 
+// ChangeOnlineStatus 仅在在线状态发生变化时更新，返回是否发生了变化
+func (r *deviceRepository) ChangeOnlineStatus(ctx context.Context, id uuid.UUID, online bool) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&domain.Device{}).
+		Where("id = ? AND online <> ?", id, online).
+		Update("online", online)
+	return result.RowsAffected > 0, result.Error
+}
+
+// UpdateClockSkew 记录设备时钟偏差
+func (r *deviceRepository) UpdateClockSkew(ctx context.Context, id uuid.UUID, skewMs int64, measuredAt time.Time) error {
+	return r.db.WithContext(ctx).
+		Model(&domain.Device{}).
+		Where("id = ?", id).
+		UpdateColumns(map[string]interface{}{
+			"clock_skew_ms": skewMs,
+			"clock_skew_at": measuredAt,
+		}).Error
+}
+
+// FindClockSkewed 查询since之后测得的时钟偏差绝对值不小于minSkewMs的设备
+func (r *deviceRepository) FindClockSkewed(ctx context.Context, since time.Time, minSkewMs int64) ([]domain.Device, error) {
+	var devices []domain.Device
+	err := r.db.WithContext(ctx).
+		Preload("VirtualNetwork").
+		Where("clock_skew_at >= ? AND ABS(clock_skew_ms) >= ?", since, minSkewMs).
+		Find(&devices).Error
+	return devices, err
+}
+
+// CountByVirtualNetworks 统计每个虚拟网络中的设备数量
+func (r *deviceRepository) CountByVirtualNetworks(ctx context.Context) (map[uuid.UUID]int, error) {
+	var rows []struct {
+		VirtualNetworkID uuid.UUID
+		Count            int
+	}
+	err := r.db.WithContext(ctx).
+		Model(&domain.Device{}).
+		Select("virtual_network_id, COUNT(*) AS count").
+		Group("virtual_network_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[uuid.UUID]int, len(rows))
+	for _, row := range rows {
+		counts[row.VirtualNetworkID] = row.Count
+	}
+	return counts, nil
+}
+
 func (r *deviceRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	return r.db.WithContext(ctx).Delete(&domain.Device{}, "id = ?", id).Error
 }
@@ -102,3 +203,153 @@ func (r *deviceRepository) CountByOrganization(ctx context.Context, orgID *uuid.
 	err := query.Count(&count).Error
 	return int(count), err
 }
+
+// SetApprovalStatus 仅在设备当前处于from中的某个状态时更新审批状态，返回是否更新成功
+// 并发的批准和拒绝只有一个会生效
+func (r *deviceRepository) SetApprovalStatus(ctx context.Context, id uuid.UUID, from []domain.DeviceApprovalStatus, to domain.DeviceApprovalStatus, decidedBy *uuid.UUID, note string, decidedAt time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&domain.Device{}).
+		Where("id = ? AND approval_status IN ?", id, from).
+		UpdateColumns(map[string]interface{}{
+			"approval_status":     to,
+			"approval_decided_at": decidedAt,
+			"approval_decided_by": decidedBy,
+			"approval_rule_id":    nil,
+			"approval_note":       note,
+			"updated_at":          decidedAt,
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// FindPendingApproval 查询等待审批的设备（预加载虚拟网络以获取组织）
+func (r *deviceRepository) FindPendingApproval(ctx context.Context) ([]domain.Device, error) {
+	var devices []domain.Device
+	err := r.db.WithContext(ctx).
+		Preload("VirtualNetwork").
+		Where("approval_status = ?", domain.DeviceApprovalPending).
+		Order("created_at ASC").
+		Find(&devices).Error
+	return devices, err
+}
+
+// FindStaleEphemeral 查询before之后没有再上报的临时设备（从未上报的按注册时间计算）
+func (r *deviceRepository) FindStaleEphemeral(ctx context.Context, before time.Time, limit int) ([]domain.Device, error) {
+	var devices []domain.Device
+	err := r.db.WithContext(ctx).
+		Preload("VirtualNetwork").
+		Where("ephemeral AND COALESCE(last_seen_at, created_at) < ?", before).
+		Order("COALESCE(last_seen_at, created_at) ASC").
+		Limit(limit).
+		Find(&devices).Error
+	return devices, err
+}
+
+// DeleteStaleEphemeral 仅在临时设备仍未重新上报时删除，返回是否删除
+// 查询与删除之间设备恢复上报时不会被误删
+func (r *deviceRepository) DeleteStaleEphemeral(ctx context.Context, id uuid.UUID, before time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Where("id = ? AND ephemeral AND COALESCE(last_seen_at, created_at) < ?", id, before).
+		Delete(&domain.Device{})
+	return result.RowsAffected > 0, result.Error
+}
+
+// UpdatePosture 保存设备上报的安全状态及评估结果，不影响其他字段
+func (r *deviceRepository) UpdatePosture(ctx context.Context, device *domain.Device) error {
+	return r.db.WithContext(ctx).
+		Model(&domain.Device{}).
+		Where("id = ?", device.ID).
+		UpdateColumns(map[string]interface{}{
+			"posture":                       device.Posture,
+			"posture_reported_at":           device.PostureReportedAt,
+			"posture_status":                device.PostureStatus,
+			"posture_violations":            device.PostureViolations,
+			"posture_allowed_peer_tags":     device.PostureAllowedPeerTags,
+			"posture_allowed_peer_tag_sets": device.PostureAllowedPeerTagSets,
+			"posture_checked_at":            device.PostureCheckedAt,
+		}).Error
+}
+
+// FindPostureNonCompliant 查询不满足安全状态策略的设备（预加载虚拟网络以获取组织）
+func (r *deviceRepository) FindPostureNonCompliant(ctx context.Context) ([]domain.Device, error) {
+	var devices []domain.Device
+	err := r.db.WithContext(ctx).
+		Preload("VirtualNetwork").
+		Where("posture_status = ?", domain.DevicePostureNonCompliant).
+		Order("posture_checked_at ASC").
+		Find(&devices).Error
+	return devices, err
+}
+
+// FindByFilters 按过滤条件查询设备（预加载虚拟网络以获取组织），limit<=0时不限制数量
+func (r *deviceRepository) FindByFilters(ctx context.Context, filters *DeviceFilters, limit int) ([]domain.Device, error) {
+	query := r.db.WithContext(ctx).Preload("VirtualNetwork")
+	if len(filters.DeviceIDs) > 0 {
+		query = query.Where("id IN ?", filters.DeviceIDs)
+	}
+	if filters.VirtualNetworkID != nil {
+		query = query.Where("virtual_network_id = ?", *filters.VirtualNetworkID)
+	}
+	if filters.Tag != nil {
+		query = query.Where("? = ANY(tags)", *filters.Tag)
+	}
+	if filters.Platform != nil {
+		query = query.Where("platform = ?", *filters.Platform)
+	}
+	if filters.Online != nil {
+		query = query.Where("online = ?", *filters.Online)
+	}
+	if filters.ApprovalStatus != nil {
+		query = query.Where("approval_status = ?", *filters.ApprovalStatus)
+	}
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+
+	var devices []domain.Device
+	err := query.Order("created_at ASC").Find(&devices).Error
+	return devices, err
+}
+
+// UpdateNameAndTags 保存设备名称和标签，不影响其他字段
+func (r *deviceRepository) UpdateNameAndTags(ctx context.Context, device *domain.Device) error {
+	return r.db.WithContext(ctx).
+		Model(&domain.Device{}).
+		Where("id = ?", device.ID).
+		UpdateColumns(map[string]interface{}{
+			"name":       device.Name,
+			"tags":       device.Tags,
+			"updated_at": device.UpdatedAt,
+		}).Error
+}
+
+// MoveToNetwork 将设备迁移到device.VirtualNetworkID，同时保存新的虚拟IP、审批规则和安全状态评估结果
+// 条件更新：设备已不在fromVNID时返回false；迁移后删除该设备的旧对等配置
+func (r *deviceRepository) MoveToNetwork(ctx context.Context, device *domain.Device, fromVNID uuid.UUID) (bool, error) {
+	moved := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&domain.Device{}).
+			Where("id = ? AND virtual_network_id = ?", device.ID, fromVNID).
+			UpdateColumns(map[string]interface{}{
+				"virtual_network_id":            device.VirtualNetworkID,
+				"virtual_ip":                    device.VirtualIP,
+				"approval_rule_id":              device.ApprovalRuleID,
+				"posture_status":                device.PostureStatus,
+				"posture_violations":            device.PostureViolations,
+				"posture_allowed_peer_tags":     device.PostureAllowedPeerTags,
+				"posture_allowed_peer_tag_sets": device.PostureAllowedPeerTagSets,
+				"posture_checked_at":            device.PostureCheckedAt,
+				"updated_at":                    device.UpdatedAt,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		moved = true
+
+		return tx.Where("device_id = ? OR peer_device_id = ?", device.ID, device.ID).
+			Delete(&domain.PeerConfiguration{}).Error
+	})
+	return moved, err
+}
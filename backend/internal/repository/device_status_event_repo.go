@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/edgelink/backend/internal/domain"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// DeviceTransitionCount 设备在时间窗口内的上下线次数
+type DeviceTransitionCount struct {
+	DeviceID    uuid.UUID
+	Transitions int
+	LastOnline  bool      // 窗口内最后一次变化后的状态
+	LastChange  time.Time // 窗口内最后一次变化时间
+}
+
+// DeviceStatusEventRepository 设备状态变化记录仓储接口
+type DeviceStatusEventRepository interface {
+	// Create 记录状态变化
+	Create(ctx context.Context, event *domain.DeviceStatusEvent) error
+
+	// CreateBatch 批量记录状态变化
+	CreateBatch(ctx context.Context, events []*domain.DeviceStatusEvent) error
+
+	// CountTransitionsSince 统计since之后每台设备的状态变化次数，只返回次数不少于minCount的设备
+	CountTransitionsSince(ctx context.Context, since time.Time, minCount int) ([]DeviceTransitionCount, error)
+
+	// DeleteOlderThan 分批删除过期记录
+	DeleteOlderThan(ctx context.Context, before time.Time, batchSize int) (int64, error)
+}
+
+// deviceStatusEventRepository DeviceStatusEvent仓储的GORM实现
+type deviceStatusEventRepository struct {
+	db *gorm.DB
+}
+
+// NewDeviceStatusEventRepository 创建DeviceStatusEvent仓储实例
+func NewDeviceStatusEventRepository(db *gorm.DB) DeviceStatusEventRepository {
+	return &deviceStatusEventRepository{db: db}
+}
+
+// Create 记录状态变化
+func (r *deviceStatusEventRepository) Create(ctx context.Context, event *domain.DeviceStatusEvent) error {
+	return r.db.WithContext(ctx).Create(event).Error
+}
+
+// CreateBatch 批量记录状态变化
+func (r *deviceStatusEventRepository) CreateBatch(ctx context.Context, events []*domain.DeviceStatusEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).Create(&events).Error
+}
+
+// CountTransitionsSince 统计since之后每台设备的状态变化次数
+func (r *deviceStatusEventRepository) CountTransitionsSince(ctx context.Context, since time.Time, minCount int) ([]DeviceTransitionCount, error) {
+	var rows []struct {
+		DeviceID    uuid.UUID
+		Transitions int
+		LastOnline  bool
+		LastChange  time.Time
+	}
+
+	err := r.db.WithContext(ctx).Raw(`
+		SELECT device_id,
+			COUNT(*) AS transitions,
+			(ARRAY_AGG(online ORDER BY occurred_at DESC, id DESC))[1] AS last_online,
+			MAX(occurred_at) AS last_change
+		FROM device_status_events
+		WHERE occurred_at >= ?
+		GROUP BY device_id
+		HAVING COUNT(*) >= ?`, since, minCount).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make([]DeviceTransitionCount, 0, len(rows))
+	for _, row := range rows {
+		counts = append(counts, DeviceTransitionCount{
+			DeviceID:    row.DeviceID,
+			Transitions: row.Transitions,
+			LastOnline:  row.LastOnline,
+			LastChange:  row.LastChange,
+		})
+	}
+	return counts, nil
+}
+
+// DeleteOlderThan 分批删除过期记录，避免长事务锁表
+func (r *deviceStatusEventRepository) DeleteOlderThan(ctx context.Context, before time.Time, batchSize int) (int64, error) {
+	result := r.db.WithContext(ctx).Exec(`
+		DELETE FROM device_status_events
+		WHERE id IN (
+			SELECT id FROM device_status_events WHERE occurred_at < ? ORDER BY id LIMIT ?
+		)`, before, batchSize)
+	return result.RowsAffected, result.Error
+}
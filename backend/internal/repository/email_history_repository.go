@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 	"gorm.io/gorm"
 )
 
@@ -12,8 +13,9 @@ import (
 type EmailHistory struct {
 	ID         uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
 	AlertID    *uuid.UUID `gorm:"type:uuid;index"`
+	OrganizationID *uuid.UUID `gorm:"type:uuid"` // 关联告警被数据保留任务删除前写入
 	Provider   string    `gorm:"type:varchar(50);not null"`
-	Recipients []string  `gorm:"type:text[];not null"`
+	Recipients pq.StringArray `gorm:"type:text[];not null"`
 	Subject    string    `gorm:"type:text;not null"`
 	Status     string    `gorm:"type:varchar(20);not null;index"` // queued, sent, failed, retrying
 	Attempts   int       `gorm:"default:1"`
@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/edgelink/backend/internal/domain"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// EscalationPolicyRepository 升级策略仓储接口
+type EscalationPolicyRepository interface {
+	// Create 创建升级策略
+	Create(ctx context.Context, policy *domain.EscalationPolicy) error
+
+	// FindByID 根据ID查找升级策略
+	FindByID(ctx context.Context, id uuid.UUID) (*domain.EscalationPolicy, error)
+
+	// FindByOrganizationID 查找组织的所有升级策略
+	FindByOrganizationID(ctx context.Context, organizationID uuid.UUID) ([]*domain.EscalationPolicy, error)
+
+	// FindReferencingTarget 查找引用了指定目标（排班表或用户）的升级策略
+	FindReferencingTarget(ctx context.Context, targetType domain.EscalationTargetType, targetID uuid.UUID) ([]*domain.EscalationPolicy, error)
+
+	// Update 更新升级策略
+	Update(ctx context.Context, policy *domain.EscalationPolicy) error
+
+	// Delete 删除升级策略
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+// escalationPolicyRepository EscalationPolicy仓储的GORM实现
+type escalationPolicyRepository struct {
+	db *gorm.DB
+}
+
+// NewEscalationPolicyRepository 创建EscalationPolicy仓储实例
+func NewEscalationPolicyRepository(db *gorm.DB) EscalationPolicyRepository {
+	return &escalationPolicyRepository{db: db}
+}
+
+// Create 创建升级策略
+func (r *escalationPolicyRepository) Create(ctx context.Context, policy *domain.EscalationPolicy) error {
+	return r.db.WithContext(ctx).Create(policy).Error
+}
+
+// FindByID 根据ID查找升级策略
+func (r *escalationPolicyRepository) FindByID(ctx context.Context, id uuid.UUID) (*domain.EscalationPolicy, error) {
+	var policy domain.EscalationPolicy
+	err := r.db.WithContext(ctx).
+		Where("id = ?", id).
+		First(&policy).Error
+	if err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+// FindByOrganizationID 查找组织的所有升级策略
+func (r *escalationPolicyRepository) FindByOrganizationID(ctx context.Context, organizationID uuid.UUID) ([]*domain.EscalationPolicy, error) {
+	var policies []*domain.EscalationPolicy
+	err := r.db.WithContext(ctx).
+		Where("organization_id = ?", organizationID).
+		Order("name ASC").
+		Find(&policies).Error
+	return policies, err
+}
+
+// FindReferencingTarget 查找引用了指定目标的升级策略（JSONB包含查询）
+func (r *escalationPolicyRepository) FindReferencingTarget(ctx context.Context, targetType domain.EscalationTargetType, targetID uuid.UUID) ([]*domain.EscalationPolicy, error) {
+	// 包含查询只比较模式中出现的键，因此只构造targets部分
+	pattern, err := json.Marshal([]map[string]interface{}{
+		{"targets": []domain.EscalationTarget{{Type: targetType, ID: targetID}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var policies []*domain.EscalationPolicy
+	err = r.db.WithContext(ctx).
+		Where("levels @> ?::jsonb", string(pattern)).
+		Order("name ASC").
+		Find(&policies).Error
+	return policies, err
+}
+
+// Update 更新升级策略
+func (r *escalationPolicyRepository) Update(ctx context.Context, policy *domain.EscalationPolicy) error {
+	return r.db.WithContext(ctx).Save(policy).Error
+}
+
+// Delete 删除升级策略
+func (r *escalationPolicyRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Delete(&domain.EscalationPolicy{}, "id = ?", id).Error
+}
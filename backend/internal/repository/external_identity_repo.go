@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/edgelink/backend/internal/domain"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ExternalIdentityRepository 外部平台用户映射仓储接口
+type ExternalIdentityRepository interface {
+	// Create 创建映射
+	Create(ctx context.Context, identity *domain.ExternalIdentity) error
+
+	// FindByID 根据ID查找映射
+	FindByID(ctx context.Context, id uuid.UUID) (*domain.ExternalIdentity, error)
+
+	// FindByExternalID 根据平台与外部用户ID查找映射（含管理员信息）
+	FindByExternalID(ctx context.Context, provider domain.ExternalProvider, externalID string) (*domain.ExternalIdentity, error)
+
+	// FindByAdminUserID 查找管理员的全部映射
+	FindByAdminUserID(ctx context.Context, adminUserID uuid.UUID) ([]*domain.ExternalIdentity, error)
+
+	// Delete 删除映射
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+// externalIdentityRepository ExternalIdentity仓储的GORM实现
+type externalIdentityRepository struct {
+	db *gorm.DB
+}
+
+// NewExternalIdentityRepository 创建ExternalIdentity仓储实例
+func NewExternalIdentityRepository(db *gorm.DB) ExternalIdentityRepository {
+	return &externalIdentityRepository{db: db}
+}
+
+// Create 创建映射
+func (r *externalIdentityRepository) Create(ctx context.Context, identity *domain.ExternalIdentity) error {
+	return r.db.WithContext(ctx).Create(identity).Error
+}
+
+// FindByID 根据ID查找映射
+func (r *externalIdentityRepository) FindByID(ctx context.Context, id uuid.UUID) (*domain.ExternalIdentity, error) {
+	var identity domain.ExternalIdentity
+	err := r.db.WithContext(ctx).
+		Where("id = ?", id).
+		First(&identity).Error
+	if err != nil {
+		return nil, err
+	}
+	return &identity, nil
+}
+
+// FindByExternalID 根据平台与外部用户ID查找映射
+func (r *externalIdentityRepository) FindByExternalID(ctx context.Context, provider domain.ExternalProvider, externalID string) (*domain.ExternalIdentity, error) {
+	var identity domain.ExternalIdentity
+	err := r.db.WithContext(ctx).
+		Preload("AdminUser").
+		Where("provider = ? AND external_id = ?", provider, externalID).
+		First(&identity).Error
+	if err != nil {
+		return nil, err
+	}
+	return &identity, nil
+}
+
+// FindByAdminUserID 查找管理员的全部映射
+func (r *externalIdentityRepository) FindByAdminUserID(ctx context.Context, adminUserID uuid.UUID) ([]*domain.ExternalIdentity, error) {
+	var identities []*domain.ExternalIdentity
+	err := r.db.WithContext(ctx).
+		Where("admin_user_id = ?", adminUserID).
+		Order("provider ASC, created_at ASC").
+		Find(&identities).Error
+	return identities, err
+}
+
+// Delete 删除映射
+func (r *externalIdentityRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).
+		Where("id = ?", id).
+		Delete(&domain.ExternalIdentity{}).Error
+}
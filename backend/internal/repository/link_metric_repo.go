@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/edgelink/backend/internal/domain"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// LinkMetricRepository 链路指标样本与基线仓储接口
+type LinkMetricRepository interface {
+	// CreateBatch 批量写入指标样本
+	CreateBatch(ctx context.Context, metrics []*domain.LinkMetric) error
+
+	// ListAfter 按ID顺序读取afterID之后、不早于since的样本
+	ListAfter(ctx context.Context, afterID int64, since time.Time, limit int) ([]*domain.LinkMetric, error)
+
+	// ListByDevice 查询设备在时间范围内上报的样本
+	ListByDevice(ctx context.Context, deviceID uuid.UUID, start, end time.Time, limit int) ([]*domain.LinkMetric, error)
+
+	// DeleteOlderThan 分批删除早于指定时间的样本，返回删除条数
+	DeleteOlderThan(ctx context.Context, before time.Time, batchSize int) (int64, error)
+
+	// FindBaselinesByDevices 查询设备作为上报方的全部基线
+	FindBaselinesByDevices(ctx context.Context, deviceIDs []uuid.UUID) ([]*domain.LinkBaseline, error)
+
+	// SaveBaseline 保存基线，prevSampleAt与数据库中的last_sample_at不一致时放弃保存并返回false
+	SaveBaseline(ctx context.Context, baseline *domain.LinkBaseline, prevSampleAt *time.Time) (bool, error)
+
+	// CountAlertingBaselines 统计设备某项指标处于告警状态的链路数
+	CountAlertingBaselines(ctx context.Context, deviceID uuid.UUID, metric domain.LinkMetricType) (int64, error)
+}
+
+// linkMetricRepository LinkMetric仓储的GORM实现
+type linkMetricRepository struct {
+	db *gorm.DB
+}
+
+// NewLinkMetricRepository 创建LinkMetric仓储实例
+func NewLinkMetricRepository(db *gorm.DB) LinkMetricRepository {
+	return &linkMetricRepository{db: db}
+}
+
+// CreateBatch 批量写入指标样本
+func (r *linkMetricRepository) CreateBatch(ctx context.Context, metrics []*domain.LinkMetric) error {
+	if len(metrics) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).Create(&metrics).Error
+}
+
+// ListAfter 按ID顺序读取样本
+func (r *linkMetricRepository) ListAfter(ctx context.Context, afterID int64, since time.Time, limit int) ([]*domain.LinkMetric, error) {
+	var metrics []*domain.LinkMetric
+	err := r.db.WithContext(ctx).
+		Where("id > ? AND recorded_at >= ?", afterID, since).
+		Order("id ASC").
+		Limit(limit).
+		Find(&metrics).Error
+	return metrics, err
+}
+
+// ListByDevice 查询设备在时间范围内上报的样本
+func (r *linkMetricRepository) ListByDevice(ctx context.Context, deviceID uuid.UUID, start, end time.Time, limit int) ([]*domain.LinkMetric, error) {
+	var metrics []*domain.LinkMetric
+	err := r.db.WithContext(ctx).
+		Where("device_id = ? AND recorded_at BETWEEN ? AND ?", deviceID, start, end).
+		Order("recorded_at ASC").
+		Limit(limit).
+		Find(&metrics).Error
+	return metrics, err
+}
+
+// DeleteOlderThan 分批删除过期样本，避免长事务锁表
+func (r *linkMetricRepository) DeleteOlderThan(ctx context.Context, before time.Time, batchSize int) (int64, error) {
+	result := r.db.WithContext(ctx).Exec(`
+		DELETE FROM link_metrics
+		WHERE id IN (
+			SELECT id FROM link_metrics WHERE recorded_at < ? ORDER BY id LIMIT ?
+		)`, before, batchSize)
+	return result.RowsAffected, result.Error
+}
+
+// FindBaselinesByDevices 查询设备作为上报方的全部基线
+func (r *linkMetricRepository) FindBaselinesByDevices(ctx context.Context, deviceIDs []uuid.UUID) ([]*domain.LinkBaseline, error) {
+	var baselines []*domain.LinkBaseline
+	if len(deviceIDs) == 0 {
+		return baselines, nil
+	}
+	err := r.db.WithContext(ctx).
+		Where("device_id IN ?", deviceIDs).
+		Find(&baselines).Error
+	return baselines, err
+}
+
+// SaveBaseline 保存基线
+// 以last_sample_at做乐观并发控制，多个告警服务副本处理同一批样本时只有一个写入生效
+func (r *linkMetricRepository) SaveBaseline(ctx context.Context, baseline *domain.LinkBaseline, prevSampleAt *time.Time) (bool, error) {
+	baseline.UpdatedAt = time.Now()
+
+	if prevSampleAt == nil {
+		result := r.db.WithContext(ctx).
+			Clauses(clause.OnConflict{DoNothing: true}).
+			Create(baseline)
+		return result.RowsAffected == 1, result.Error
+	}
+
+	result := r.db.WithContext(ctx).
+		Model(&domain.LinkBaseline{}).
+		Where("device_id = ? AND peer_device_id = ? AND metric = ? AND last_sample_at = ?",
+			baseline.DeviceID, baseline.PeerDeviceID, baseline.Metric, *prevSampleAt).
+		Updates(map[string]interface{}{
+			"mean":            baseline.Mean,
+			"variance":        baseline.Variance,
+			"sample_count":    baseline.SampleCount,
+			"last_value":      baseline.LastValue,
+			"last_sample_at":  baseline.LastSampleAt,
+			"anomalous_since": baseline.AnomalousSince,
+			"alerting":        baseline.Alerting,
+			"updated_at":      baseline.UpdatedAt,
+		})
+	return result.RowsAffected == 1, result.Error
+}
+
+// CountAlertingBaselines 统计设备某项指标处于告警状态的链路数
+func (r *linkMetricRepository) CountAlertingBaselines(ctx context.Context, deviceID uuid.UUID, metric domain.LinkMetricType) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&domain.LinkBaseline{}).
+		Where("device_id = ? AND metric = ? AND alerting = ?", deviceID, metric, true).
+		Count(&count).Error
+	return count, err
+}
@@ -0,0 +1,207 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/edgelink/backend/internal/domain"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// NotificationDeliveryRepository 通知投递发件箱仓储接口
+type NotificationDeliveryRepository interface {
+	// Enqueue 写入一条待投递的通知
+	Enqueue(ctx context.Context, delivery *domain.NotificationDelivery) error
+
+	// ClaimDue 认领到期的投递任务，并在lease时长内锁定，防止多个实例重复投递
+	ClaimDue(ctx context.Context, now time.Time, limit int, lease time.Duration) ([]*domain.NotificationDelivery, error)
+
+	// RecordAttempt 记录一次投递尝试并保存投递状态
+	RecordAttempt(ctx context.Context, delivery *domain.NotificationDelivery, attempt *domain.NotificationAttempt) error
+
+	// FindByID 根据ID查找投递任务
+	FindByID(ctx context.Context, id uuid.UUID) (*domain.NotificationDelivery, error)
+
+	// FindByAlertID 查找告警的全部通知投递及尝试记录
+	FindByAlertID(ctx context.Context, alertID uuid.UUID) ([]*domain.NotificationDelivery, error)
+
+	// FindDeliveredTargets 查找告警已成功投递的目标（用于同步解决/确认状态）
+	FindDeliveredTargets(ctx context.Context, alertID uuid.UUID, channel string) ([]string, error)
+
+	// FindDeadLetters 查找死信列表
+	FindDeadLetters(ctx context.Context, filters *DeadLetterFilters) ([]*domain.NotificationDelivery, int64, error)
+
+	// Redrive 将死信重新放回投递队列
+	Redrive(ctx context.Context, id uuid.UUID, now time.Time) error
+}
+
+// DeadLetterFilters 死信查询过滤条件
+type DeadLetterFilters struct {
+	Channel *string
+	Limit   int
+	Offset  int
+}
+
+// notificationDeliveryRepository NotificationDelivery仓储的GORM实现
+type notificationDeliveryRepository struct {
+	db *gorm.DB
+}
+
+// NewNotificationDeliveryRepository 创建NotificationDelivery仓储实例
+func NewNotificationDeliveryRepository(db *gorm.DB) NotificationDeliveryRepository {
+	return &notificationDeliveryRepository{db: db}
+}
+
+// Enqueue 写入一条待投递的通知
+func (r *notificationDeliveryRepository) Enqueue(ctx context.Context, delivery *domain.NotificationDelivery) error {
+	return r.db.WithContext(ctx).Create(delivery).Error
+}
+
+// ClaimDue 认领到期的投递任务
+func (r *notificationDeliveryRepository) ClaimDue(ctx context.Context, now time.Time, limit int, lease time.Duration) ([]*domain.NotificationDelivery, error) {
+	var deliveries []*domain.NotificationDelivery
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.
+			Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status IN ?", []domain.DeliveryStatus{domain.DeliveryStatusPending, domain.DeliveryStatusRetrying}).
+			Where("next_attempt_at <= ?", now).
+			Where("locked_until IS NULL OR locked_until < ?", now).
+			Order("next_attempt_at ASC").
+			Limit(limit).
+			Find(&deliveries).Error; err != nil {
+			return err
+		}
+
+		if len(deliveries) == 0 {
+			return nil
+		}
+
+		ids := make([]uuid.UUID, 0, len(deliveries))
+		lockedUntil := now.Add(lease)
+		for _, delivery := range deliveries {
+			ids = append(ids, delivery.ID)
+			delivery.LockedUntil = &lockedUntil
+		}
+
+		return tx.Model(&domain.NotificationDelivery{}).
+			Where("id IN ?", ids).
+			Update("locked_until", lockedUntil).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+// RecordAttempt 记录一次投递尝试并保存投递状态
+func (r *notificationDeliveryRepository) RecordAttempt(ctx context.Context, delivery *domain.NotificationDelivery, attempt *domain.NotificationAttempt) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(attempt).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(&domain.NotificationDelivery{}).
+			Where("id = ?", delivery.ID).
+			Updates(map[string]interface{}{
+				"status":          delivery.Status,
+				"attempts":        delivery.Attempts,
+				"next_attempt_at": delivery.NextAttemptAt,
+				"locked_until":    nil,
+				"last_error":      delivery.LastError,
+				"sent_at":         delivery.SentAt,
+				"dead_at":         delivery.DeadAt,
+				"updated_at":      time.Now(),
+			}).Error
+	})
+}
+
+// FindByID 根据ID查找投递任务
+func (r *notificationDeliveryRepository) FindByID(ctx context.Context, id uuid.UUID) (*domain.NotificationDelivery, error) {
+	var delivery domain.NotificationDelivery
+	err := r.db.WithContext(ctx).
+		Preload("DeliveryAttempts", func(db *gorm.DB) *gorm.DB {
+			return db.Order("attempt ASC")
+		}).
+		Where("id = ?", id).
+		First(&delivery).Error
+	if err != nil {
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+// FindByAlertID 查找告警的全部通知投递及尝试记录
+func (r *notificationDeliveryRepository) FindByAlertID(ctx context.Context, alertID uuid.UUID) ([]*domain.NotificationDelivery, error) {
+	var deliveries []*domain.NotificationDelivery
+	err := r.db.WithContext(ctx).
+		Preload("DeliveryAttempts", func(db *gorm.DB) *gorm.DB {
+			return db.Order("created_at ASC")
+		}).
+		Where("alert_id = ?", alertID).
+		Order("created_at ASC").
+		Find(&deliveries).Error
+	return deliveries, err
+}
+
+// FindDeliveredTargets 查找告警已成功投递的目标
+func (r *notificationDeliveryRepository) FindDeliveredTargets(ctx context.Context, alertID uuid.UUID, channel string) ([]string, error) {
+	var targets []string
+	err := r.db.WithContext(ctx).Model(&domain.NotificationDelivery{}).
+		Where("alert_id = ? AND channel = ? AND status = ?", alertID, channel, domain.DeliveryStatusSent).
+		Distinct("target").
+		Pluck("target", &targets).Error
+	return targets, err
+}
+
+// FindDeadLetters 查找死信列表
+func (r *notificationDeliveryRepository) FindDeadLetters(ctx context.Context, filters *DeadLetterFilters) ([]*domain.NotificationDelivery, int64, error) {
+	var deliveries []*domain.NotificationDelivery
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&domain.NotificationDelivery{}).
+		Where("status = ?", domain.DeliveryStatusDead)
+
+	if filters.Channel != nil {
+		query = query.Where("channel = ?", *filters.Channel)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if filters.Limit > 0 {
+		query = query.Limit(filters.Limit)
+	}
+	if filters.Offset > 0 {
+		query = query.Offset(filters.Offset)
+	}
+
+	err := query.Order("dead_at DESC").Find(&deliveries).Error
+	return deliveries, total, err
+}
+
+// Redrive 将死信重新放回投递队列（仅限dead状态）
+func (r *notificationDeliveryRepository) Redrive(ctx context.Context, id uuid.UUID, now time.Time) error {
+	result := r.db.WithContext(ctx).Model(&domain.NotificationDelivery{}).
+		Where("id = ? AND status = ?", id, domain.DeliveryStatusDead).
+		Updates(map[string]interface{}{
+			"status":          domain.DeliveryStatusPending,
+			"attempts":        0,
+			"redrive_count":   gorm.Expr("redrive_count + 1"),
+			"next_attempt_at": now,
+			"locked_until":    nil,
+			"dead_at":         nil,
+			"updated_at":      now,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/edgelink/backend/internal/domain"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// NotificationRuleRepository 通知规则仓储接口
+type NotificationRuleRepository interface {
+	// Create 创建规则及其首个版本
+	Create(ctx context.Context, rule *domain.NotificationRule, version *domain.NotificationRuleVersion) error
+
+	// SaveWithVersion 保存规则并追加新版本（版本号在事务内递增）
+	SaveWithVersion(ctx context.Context, rule *domain.NotificationRule, version *domain.NotificationRuleVersion) error
+
+	// FindByKey 根据组织和规则标识查找规则（包含已删除的规则）
+	FindByKey(ctx context.Context, orgID uuid.UUID, ruleKey string) (*domain.NotificationRule, error)
+
+	// FindByOrganization 查找组织的所有未删除规则
+	FindByOrganization(ctx context.Context, orgID uuid.UUID) ([]*domain.NotificationRule, error)
+
+	// FindEnabledByOrganization 查找组织的所有启用规则
+	FindEnabledByOrganization(ctx context.Context, orgID uuid.UUID) ([]*domain.NotificationRule, error)
+
+	// FindAllEnabled 查找所有组织的启用规则
+	FindAllEnabled(ctx context.Context) ([]*domain.NotificationRule, error)
+
+	// FindVersions 查找规则的所有版本（按版本号倒序）
+	FindVersions(ctx context.Context, ruleID uuid.UUID) ([]*domain.NotificationRuleVersion, error)
+
+	// FindVersion 查找规则的指定版本
+	FindVersion(ctx context.Context, ruleID uuid.UUID, version int) (*domain.NotificationRuleVersion, error)
+}
+
+// notificationRuleRepository NotificationRule仓储的GORM实现
+type notificationRuleRepository struct {
+	db *gorm.DB
+}
+
+// NewNotificationRuleRepository 创建NotificationRule仓储实例
+func NewNotificationRuleRepository(db *gorm.DB) NotificationRuleRepository {
+	return &notificationRuleRepository{db: db}
+}
+
+// Create 创建规则及其首个版本
+func (r *notificationRuleRepository) Create(ctx context.Context, rule *domain.NotificationRule, version *domain.NotificationRuleVersion) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		rule.Version = 1
+		if err := tx.Create(rule).Error; err != nil {
+			return err
+		}
+
+		version.NotificationRuleID = rule.ID
+		version.OrganizationID = rule.OrganizationID
+		version.Version = rule.Version
+		return tx.Create(version).Error
+	})
+}
+
+// SaveWithVersion 保存规则并追加新版本（版本号在事务内递增）
+func (r *notificationRuleRepository) SaveWithVersion(ctx context.Context, rule *domain.NotificationRule, version *domain.NotificationRuleVersion) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 锁定规则行，避免并发保存产生重复版本号
+		var current domain.NotificationRule
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id", "version").
+			First(&current, "id = ?", rule.ID).Error; err != nil {
+			return err
+		}
+
+		rule.Version = current.Version + 1
+		if err := tx.Save(rule).Error; err != nil {
+			return err
+		}
+
+		version.NotificationRuleID = rule.ID
+		version.OrganizationID = rule.OrganizationID
+		version.Version = rule.Version
+		return tx.Create(version).Error
+	})
+}
+
+// FindByKey 根据组织和规则标识查找规则（包含已删除的规则）
+func (r *notificationRuleRepository) FindByKey(ctx context.Context, orgID uuid.UUID, ruleKey string) (*domain.NotificationRule, error) {
+	var rule domain.NotificationRule
+	err := r.db.WithContext(ctx).
+		Where("organization_id = ? AND rule_key = ?", orgID, ruleKey).
+		First(&rule).Error
+	if err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// FindByOrganization 查找组织的所有未删除规则
+func (r *notificationRuleRepository) FindByOrganization(ctx context.Context, orgID uuid.UUID) ([]*domain.NotificationRule, error) {
+	var rules []*domain.NotificationRule
+	err := r.db.WithContext(ctx).
+		Where("organization_id = ? AND deleted_at IS NULL", orgID).
+		Order("priority ASC, rule_key ASC").
+		Find(&rules).Error
+	return rules, err
+}
+
+// FindEnabledByOrganization 查找组织的所有启用规则
+func (r *notificationRuleRepository) FindEnabledByOrganization(ctx context.Context, orgID uuid.UUID) ([]*domain.NotificationRule, error) {
+	var rules []*domain.NotificationRule
+	err := r.db.WithContext(ctx).
+		Where("organization_id = ? AND enabled = ? AND deleted_at IS NULL", orgID, true).
+		Order("priority ASC, rule_key ASC").
+		Find(&rules).Error
+	return rules, err
+}
+
+// FindAllEnabled 查找所有组织的启用规则
+func (r *notificationRuleRepository) FindAllEnabled(ctx context.Context) ([]*domain.NotificationRule, error) {
+	var rules []*domain.NotificationRule
+	err := r.db.WithContext(ctx).
+		Where("enabled = ? AND deleted_at IS NULL", true).
+		Order("organization_id ASC, priority ASC").
+		Find(&rules).Error
+	return rules, err
+}
+
+// FindVersions 查找规则的所有版本（按版本号倒序）
+func (r *notificationRuleRepository) FindVersions(ctx context.Context, ruleID uuid.UUID) ([]*domain.NotificationRuleVersion, error) {
+	var versions []*domain.NotificationRuleVersion
+	err := r.db.WithContext(ctx).
+		Where("notification_rule_id = ?", ruleID).
+		Order("version DESC").
+		Find(&versions).Error
+	return versions, err
+}
+
+// FindVersion 查找规则的指定版本
+func (r *notificationRuleRepository) FindVersion(ctx context.Context, ruleID uuid.UUID, version int) (*domain.NotificationRuleVersion, error) {
+	var v domain.NotificationRuleVersion
+	err := r.db.WithContext(ctx).
+		Where("notification_rule_id = ? AND version = ?", ruleID, version).
+		First(&v).Error
+	if err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
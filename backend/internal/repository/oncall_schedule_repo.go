@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/edgelink/backend/internal/domain"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// OnCallScheduleRepository 值班排班仓储接口
+type OnCallScheduleRepository interface {
+	// Create 创建排班表
+	Create(ctx context.Context, schedule *domain.OnCallSchedule) error
+
+	// FindByID 根据ID查找排班表（包含替班）
+	FindByID(ctx context.Context, id uuid.UUID) (*domain.OnCallSchedule, error)
+
+	// FindByIDs 批量查找排班表（包含替班）
+	FindByIDs(ctx context.Context, ids []uuid.UUID) ([]*domain.OnCallSchedule, error)
+
+	// FindByOrganizationID 查找组织的所有排班表（包含替班）
+	FindByOrganizationID(ctx context.Context, organizationID uuid.UUID) ([]*domain.OnCallSchedule, error)
+
+	// Update 更新排班表（不修改替班）
+	Update(ctx context.Context, schedule *domain.OnCallSchedule) error
+
+	// Delete 删除排班表及其替班
+	Delete(ctx context.Context, id uuid.UUID) error
+
+	// CreateOverride 创建替班
+	CreateOverride(ctx context.Context, override *domain.OnCallOverride) error
+
+	// DeleteOverride 删除排班表下的替班
+	DeleteOverride(ctx context.Context, scheduleID, overrideID uuid.UUID) error
+}
+
+// onCallScheduleRepository OnCallSchedule仓储的GORM实现
+type onCallScheduleRepository struct {
+	db *gorm.DB
+}
+
+// NewOnCallScheduleRepository 创建OnCallSchedule仓储实例
+func NewOnCallScheduleRepository(db *gorm.DB) OnCallScheduleRepository {
+	return &onCallScheduleRepository{db: db}
+}
+
+// Create 创建排班表
+func (r *onCallScheduleRepository) Create(ctx context.Context, schedule *domain.OnCallSchedule) error {
+	return r.db.WithContext(ctx).Omit("Overrides").Create(schedule).Error
+}
+
+// FindByID 根据ID查找排班表
+func (r *onCallScheduleRepository) FindByID(ctx context.Context, id uuid.UUID) (*domain.OnCallSchedule, error) {
+	var schedule domain.OnCallSchedule
+	err := r.withOverrides(ctx).
+		Where("id = ?", id).
+		First(&schedule).Error
+	if err != nil {
+		return nil, err
+	}
+	return &schedule, nil
+}
+
+// FindByIDs 批量查找排班表
+func (r *onCallScheduleRepository) FindByIDs(ctx context.Context, ids []uuid.UUID) ([]*domain.OnCallSchedule, error) {
+	var schedules []*domain.OnCallSchedule
+	if len(ids) == 0 {
+		return schedules, nil
+	}
+	err := r.withOverrides(ctx).
+		Where("id IN ?", ids).
+		Find(&schedules).Error
+	return schedules, err
+}
+
+// FindByOrganizationID 查找组织的所有排班表
+func (r *onCallScheduleRepository) FindByOrganizationID(ctx context.Context, organizationID uuid.UUID) ([]*domain.OnCallSchedule, error) {
+	var schedules []*domain.OnCallSchedule
+	err := r.withOverrides(ctx).
+		Where("organization_id = ?", organizationID).
+		Order("name ASC").
+		Find(&schedules).Error
+	return schedules, err
+}
+
+// Update 更新排班表
+func (r *onCallScheduleRepository) Update(ctx context.Context, schedule *domain.OnCallSchedule) error {
+	return r.db.WithContext(ctx).Omit("Overrides").Save(schedule).Error
+}
+
+// Delete 删除排班表（替班由外键级联删除）
+func (r *onCallScheduleRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Delete(&domain.OnCallSchedule{}, "id = ?", id).Error
+}
+
+// CreateOverride 创建替班
+func (r *onCallScheduleRepository) CreateOverride(ctx context.Context, override *domain.OnCallOverride) error {
+	return r.db.WithContext(ctx).Create(override).Error
+}
+
+// DeleteOverride 删除排班表下的替班
+func (r *onCallScheduleRepository) DeleteOverride(ctx context.Context, scheduleID, overrideID uuid.UUID) error {
+	result := r.db.WithContext(ctx).
+		Where("id = ? AND schedule_id = ?", overrideID, scheduleID).
+		Delete(&domain.OnCallOverride{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// withOverrides 预加载按开始时间排序的替班
+func (r *onCallScheduleRepository) withOverrides(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx).
+		Preload("Overrides", func(db *gorm.DB) *gorm.DB {
+			return db.Order("starts_at ASC")
+		})
+}
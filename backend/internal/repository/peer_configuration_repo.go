@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/edgelink/backend/internal/domain"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// PeerConfigurationRepository 对等配置仓储接口
+type PeerConfigurationRepository interface {
+	// FindByDeviceID 查找设备的所有对等配置
+	FindByDeviceID(ctx context.Context, deviceID uuid.UUID) ([]*domain.PeerConfiguration, error)
+}
+
+// peerConfigurationRepository PeerConfiguration仓储的GORM实现
+type peerConfigurationRepository struct {
+	db *gorm.DB
+}
+
+// NewPeerConfigurationRepository 创建PeerConfiguration仓储实例
+func NewPeerConfigurationRepository(db *gorm.DB) PeerConfigurationRepository {
+	return &peerConfigurationRepository{db: db}
+}
+
+// FindByDeviceID 查找设备的所有对等配置
+func (r *peerConfigurationRepository) FindByDeviceID(ctx context.Context, deviceID uuid.UUID) ([]*domain.PeerConfiguration, error) {
+	var configs []*domain.PeerConfiguration
+	err := r.db.WithContext(ctx).
+		Where("device_id = ?", deviceID).
+		Find(&configs).Error
+	return configs, err
+}
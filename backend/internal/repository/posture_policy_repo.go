@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/edgelink/backend/internal/domain"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// PosturePolicyRepository 设备安全状态策略仓储接口
+type PosturePolicyRepository interface {
+	// Create 创建策略
+	Create(ctx context.Context, policy *domain.PosturePolicy) error
+
+	// FindByID 根据ID查找
+	FindByID(ctx context.Context, id uuid.UUID) (*domain.PosturePolicy, error)
+
+	// FindByVirtualNetwork 列出虚拟网络的全部策略
+	FindByVirtualNetwork(ctx context.Context, vnID uuid.UUID) ([]*domain.PosturePolicy, error)
+
+	// Update 更新策略
+	Update(ctx context.Context, policy *domain.PosturePolicy) error
+
+	// Delete 删除策略
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+// posturePolicyRepository PosturePolicy仓储的GORM实现
+type posturePolicyRepository struct {
+	db *gorm.DB
+}
+
+// NewPosturePolicyRepository 创建PosturePolicy仓储实例
+func NewPosturePolicyRepository(db *gorm.DB) PosturePolicyRepository {
+	return &posturePolicyRepository{db: db}
+}
+
+// Create 创建策略
+func (r *posturePolicyRepository) Create(ctx context.Context, policy *domain.PosturePolicy) error {
+	return r.db.WithContext(ctx).Create(policy).Error
+}
+
+// FindByID 根据ID查找
+func (r *posturePolicyRepository) FindByID(ctx context.Context, id uuid.UUID) (*domain.PosturePolicy, error) {
+	var policy domain.PosturePolicy
+	err := r.db.WithContext(ctx).
+		Where("id = ?", id).
+		First(&policy).Error
+	if err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+// FindByVirtualNetwork 列出虚拟网络的全部策略
+func (r *posturePolicyRepository) FindByVirtualNetwork(ctx context.Context, vnID uuid.UUID) ([]*domain.PosturePolicy, error) {
+	var policies []*domain.PosturePolicy
+	err := r.db.WithContext(ctx).
+		Where("virtual_network_id = ?", vnID).
+		Order("created_at ASC").
+		Find(&policies).Error
+	return policies, err
+}
+
+// Update 更新策略
+func (r *posturePolicyRepository) Update(ctx context.Context, policy *domain.PosturePolicy) error {
+	return r.db.WithContext(ctx).Save(policy).Error
+}
+
+// Delete 删除策略
+func (r *posturePolicyRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Delete(&domain.PosturePolicy{}, "id = ?", id).Error
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/edgelink/backend/internal/domain"
 	"github.com/google/uuid"
@@ -17,6 +18,7 @@ type PreSharedKeyRepository interface {
 	Update(ctx context.Context, psk *domain.PreSharedKey) error
 	IncrementUsedCount(ctx context.Context, id uuid.UUID) error
 	Delete(ctx context.Context, id uuid.UUID) error
+	FindWithLimits(ctx context.Context, expiredSince time.Time) ([]domain.PreSharedKey, error)
 }
 
 type preSharedKeyRepository struct {
@@ -75,3 +77,14 @@ func (r *preSharedKeyRepository) IncrementUsedCount(ctx context.Context, id uuid
 func (r *preSharedKeyRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	return r.db.WithContext(ctx).Delete(&domain.PreSharedKey{}, "id = ?", id).Error
 }
+
+// FindWithLimits 查询设置了使用次数上限或过期时间的密钥，忽略在expiredSince之前就已过期的密钥
+func (r *preSharedKeyRepository) FindWithLimits(ctx context.Context, expiredSince time.Time) ([]domain.PreSharedKey, error) {
+	var psks []domain.PreSharedKey
+	err := r.db.WithContext(ctx).
+		Where("max_uses IS NOT NULL OR expires_at IS NOT NULL").
+		Where("expires_at IS NULL OR expires_at >= ?", expiredSince).
+		Order("created_at ASC").
+		Find(&psks).Error
+	return psks, err
+}
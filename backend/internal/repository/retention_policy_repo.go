@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/edgelink/backend/internal/domain"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// RetentionPolicyRepository 数据保留策略仓储接口
+type RetentionPolicyRepository interface {
+	// Create 创建保留策略
+	Create(ctx context.Context, policy *domain.RetentionPolicy) error
+
+	// Update 更新保留策略
+	Update(ctx context.Context, policy *domain.RetentionPolicy) error
+
+	// Delete 删除保留策略
+	Delete(ctx context.Context, id uuid.UUID) error
+
+	// FindByID 根据ID查找保留策略
+	FindByID(ctx context.Context, id uuid.UUID) (*domain.RetentionPolicy, error)
+
+	// List 列出保留策略，organizationID为空时列出全部
+	List(ctx context.Context, organizationID *uuid.UUID) ([]*domain.RetentionPolicy, error)
+}
+
+// retentionPolicyRepository RetentionPolicy仓储的GORM实现
+type retentionPolicyRepository struct {
+	db *gorm.DB
+}
+
+// NewRetentionPolicyRepository 创建RetentionPolicy仓储实例
+func NewRetentionPolicyRepository(db *gorm.DB) RetentionPolicyRepository {
+	return &retentionPolicyRepository{db: db}
+}
+
+// Create 创建保留策略
+func (r *retentionPolicyRepository) Create(ctx context.Context, policy *domain.RetentionPolicy) error {
+	return r.db.WithContext(ctx).Create(policy).Error
+}
+
+// Update 更新保留策略
+func (r *retentionPolicyRepository) Update(ctx context.Context, policy *domain.RetentionPolicy) error {
+	return r.db.WithContext(ctx).Save(policy).Error
+}
+
+// Delete 删除保留策略
+func (r *retentionPolicyRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Delete(&domain.RetentionPolicy{}, "id = ?", id).Error
+}
+
+// FindByID 根据ID查找保留策略
+func (r *retentionPolicyRepository) FindByID(ctx context.Context, id uuid.UUID) (*domain.RetentionPolicy, error) {
+	var policy domain.RetentionPolicy
+	if err := r.db.WithContext(ctx).First(&policy, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+// List 列出保留策略
+func (r *retentionPolicyRepository) List(ctx context.Context, organizationID *uuid.UUID) ([]*domain.RetentionPolicy, error) {
+	query := r.db.WithContext(ctx)
+	if organizationID != nil {
+		query = query.Where("organization_id = ?", *organizationID)
+	}
+
+	var policies []*domain.RetentionPolicy
+	if err := query.Order("organization_id NULLS FIRST, data_class").Find(&policies).Error; err != nil {
+		return nil, err
+	}
+	return policies, nil
+}
@@ -0,0 +1,248 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/edgelink/backend/internal/domain"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// RetentionRepository 按数据类别查找和清理过期记录的仓储接口
+// organizationID为空时作用于不属于任何组织的记录（见DataClass.HasUnscopedRecords）
+type RetentionRepository interface {
+	// CountExpired 统计过期记录数，用于试运行
+	CountExpired(ctx context.Context, class domain.DataClass, organizationID *uuid.UUID, before time.Time) (int64, error)
+
+	// FindExpired 查找一批过期记录；审计日志按链序号升序返回，保证中断后剩余的链仍然连续
+	FindExpired(ctx context.Context, class domain.DataClass, organizationID *uuid.UUID, before time.Time, limit int) ([]ExpiredRecord, error)
+
+	// ExportRecords 以JSON读取记录的完整内容，用于归档
+	ExportRecords(ctx context.Context, class domain.DataClass, ids []uuid.UUID) ([]json.RawMessage, error)
+
+	// DeleteRecords 删除记录，返回删除数量
+	DeleteRecords(ctx context.Context, class domain.DataClass, ids []uuid.UUID) (int64, error)
+}
+
+// ExpiredRecord 过期记录
+type ExpiredRecord struct {
+	ID       uuid.UUID
+	Sequence *int64 // 仅审计日志
+}
+
+const (
+	// orgDevicesQuery 组织下所有设备的ID，参数@org为组织ID
+	orgDevicesQuery = "SELECT d.id FROM devices d JOIN virtual_networks vn ON vn.id = d.virtual_network_id WHERE vn.organization_id = @org"
+
+	// orgAlertsQuery 组织下所有告警的ID：设备告警按设备所在网络，网络、注册密钥等非设备告警按metadata中的organization_id
+	orgAlertsQuery = "SELECT a.id FROM alerts a WHERE a.device_id IN (" + orgDevicesQuery + ") " +
+		"OR (a.device_id IS NULL AND a.metadata->>'organization_id' = @org)"
+
+	// unscopedAlertsQuery 不属于任何组织的告警的ID
+	unscopedAlertsQuery = "SELECT a.id FROM alerts a WHERE a.device_id IS NULL AND COALESCE(a.metadata->>'organization_id', '') = ''"
+)
+
+// retentionTarget 数据类别对应的表和过滤条件，条件中表别名为t
+type retentionTarget struct {
+	table   string
+	expired string // 过期条件，参数为截止时间
+	org     string // 属于组织的条件，参数@org为组织ID
+	noOrg   string // 不属于任何组织的条件
+}
+
+var retentionTargets = map[domain.DataClass]retentionTarget{
+	domain.DataClassSessions: {
+		table:   "sessions",
+		expired: "t.ended_at IS NOT NULL AND t.ended_at < ?",
+		org:     "t.device_a_id IN (" + orgDevicesQuery + ")",
+	},
+	domain.DataClassAlerts: {
+		table:   "alerts",
+		expired: "t.status = 'resolved' AND t.resolved_at < ?",
+		org:     "t.id IN (" + orgAlertsQuery + ")",
+		noOrg:   "t.id IN (" + unscopedAlertsQuery + ")",
+	},
+	// 告警先于邮件历史清理，alert_id随之置空；删除告警前写入的organization_id保证之后仍按组织策略清理
+	domain.DataClassEmailHistory: {
+		table:   "email_history",
+		expired: "t.created_at < ?",
+		org:     "(t.organization_id = @org OR (t.organization_id IS NULL AND t.alert_id IN (" + orgAlertsQuery + ")))",
+		noOrg:   "t.organization_id IS NULL AND (t.alert_id IS NULL OR t.alert_id IN (" + unscopedAlertsQuery + "))",
+	},
+	domain.DataClassDiagnosticBundles: {
+		table:   "diagnostic_bundles",
+		expired: "t.requested_at < ? AND t.status IN ('uploaded', 'failed', 'expired')",
+		org:     "t.device_id IN (" + orgDevicesQuery + ")",
+	},
+	domain.DataClassTaskRuns: {
+		table:   "task_runs",
+		expired: "t.created_at < ? AND t.status IN ('success', 'failure', 'interrupted')",
+		noOrg:   "TRUE",
+	},
+	domain.DataClassAuditLogs: {
+		table: "audit_logs",
+		org:   "t.organization_id = @org",
+	},
+}
+
+// retentionRepository Retention仓储的GORM实现
+type retentionRepository struct {
+	db *gorm.DB
+}
+
+// NewRetentionRepository 创建Retention仓储实例
+func NewRetentionRepository(db *gorm.DB) RetentionRepository {
+	return &retentionRepository{db: db}
+}
+
+// CountExpired 统计过期记录数
+func (r *retentionRepository) CountExpired(ctx context.Context, class domain.DataClass, organizationID *uuid.UUID, before time.Time) (int64, error) {
+	query, err := r.expiredQuery(ctx, class, organizationID, before)
+	if err != nil {
+		return 0, err
+	}
+
+	var count int64
+	if err := query.Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// FindExpired 查找一批过期记录
+func (r *retentionRepository) FindExpired(ctx context.Context, class domain.DataClass, organizationID *uuid.UUID, before time.Time, limit int) ([]ExpiredRecord, error) {
+	query, err := r.expiredQuery(ctx, class, organizationID, before)
+	if err != nil {
+		return nil, err
+	}
+
+	var records []ExpiredRecord
+	if class == domain.DataClassAuditLogs {
+		query = query.Select("t.id, t.sequence").Order("t.sequence ASC NULLS FIRST")
+	} else {
+		query = query.Select("t.id")
+	}
+	if err := query.Limit(limit).Scan(&records).Error; err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// ExportRecords 以JSON读取记录的完整内容
+func (r *retentionRepository) ExportRecords(ctx context.Context, class domain.DataClass, ids []uuid.UUID) ([]json.RawMessage, error) {
+	target, ok := retentionTargets[class]
+	if !ok {
+		return nil, fmt.Errorf("unknown data class: %s", class)
+	}
+
+	var rows []string
+	if err := r.db.WithContext(ctx).
+		Raw(fmt.Sprintf("SELECT row_to_json(t)::text FROM %s t WHERE t.id IN ?", target.table), ids).
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	records := make([]json.RawMessage, len(rows))
+	for i, row := range rows {
+		records[i] = json.RawMessage(row)
+	}
+	return records, nil
+}
+
+// DeleteRecords 删除记录
+// 审计日志受触发器保护，只在设置了edgelink.audit_retention的事务内允许删除
+func (r *retentionRepository) DeleteRecords(ctx context.Context, class domain.DataClass, ids []uuid.UUID) (int64, error) {
+	target, ok := retentionTargets[class]
+	if !ok {
+		return 0, fmt.Errorf("unknown data class: %s", class)
+	}
+	statement := fmt.Sprintf("DELETE FROM %s WHERE id IN ?", target.table)
+
+	if class == domain.DataClassAlerts {
+		return r.deleteAlerts(ctx, statement, ids)
+	}
+	if class != domain.DataClassAuditLogs {
+		result := r.db.WithContext(ctx).Exec(statement, ids)
+		return result.RowsAffected, result.Error
+	}
+
+	var deleted int64
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SET LOCAL edgelink.audit_retention = 'on'").Error; err != nil {
+			return err
+		}
+		result := tx.Exec(statement, ids)
+		deleted = result.RowsAffected
+		return result.Error
+	})
+	return deleted, err
+}
+
+// deleteAlerts 删除告警，同一事务内先为关联的邮件历史写入所属组织
+// email_history.alert_id为ON DELETE SET NULL，否则告警删除后这些记录会落入不属于任何组织的范围
+func (r *retentionRepository) deleteAlerts(ctx context.Context, statement string, ids []uuid.UUID) (int64, error) {
+	var deleted int64
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(`
+			UPDATE email_history e SET organization_id = o.organization_id
+			FROM (
+				SELECT a.id, COALESCE(vn.organization_id, NULLIF(a.metadata->>'organization_id', '')::uuid) AS organization_id
+				FROM alerts a
+				LEFT JOIN devices d ON d.id = a.device_id
+				LEFT JOIN virtual_networks vn ON vn.id = d.virtual_network_id
+				WHERE a.id IN ?
+			) o
+			WHERE e.alert_id = o.id AND e.organization_id IS NULL AND o.organization_id IS NOT NULL`, ids).Error; err != nil {
+			return err
+		}
+		result := tx.Exec(statement, ids)
+		deleted = result.RowsAffected
+		return result.Error
+	})
+	return deleted, err
+}
+
+// expiredQuery 构造某类别过期记录的查询
+func (r *retentionRepository) expiredQuery(ctx context.Context, class domain.DataClass, organizationID *uuid.UUID, before time.Time) (*gorm.DB, error) {
+	target, ok := retentionTargets[class]
+	if !ok {
+		return nil, fmt.Errorf("unknown data class: %s", class)
+	}
+
+	query := r.db.WithContext(ctx).Table(target.table + " AS t")
+	switch {
+	case organizationID != nil && target.org != "":
+		query = query.Where(target.org, sql.Named("org", *organizationID))
+	case organizationID == nil && target.noOrg != "":
+		query = query.Where(target.noOrg)
+	case organizationID != nil:
+		return nil, fmt.Errorf("data class %s is not scoped to organizations", class)
+	default:
+		return nil, fmt.Errorf("data class %s has no records outside organizations", class)
+	}
+
+	if class != domain.DataClassAuditLogs {
+		return query.Where(target.expired, before), nil
+	}
+
+	// 审计日志只能从链头部开始删除：清理范围为第一条未过期记录之前的连续前缀，
+	// 且始终保留最新一条链记录，之后的记录仍能接续哈希链
+	var boundary *int64
+	if err := r.db.WithContext(ctx).Raw(`
+		SELECT MIN(sequence) FROM audit_logs
+		WHERE organization_id = ? AND sequence IS NOT NULL
+		  AND (created_at >= ? OR sequence = (SELECT MAX(sequence) FROM audit_logs WHERE organization_id = ?))`,
+		*organizationID, before, *organizationID,
+	).Scan(&boundary).Error; err != nil {
+		return nil, err
+	}
+
+	if boundary == nil {
+		return query.Where("t.sequence IS NULL AND t.created_at < ?", before), nil
+	}
+	return query.Where("(t.sequence IS NULL AND t.created_at < ?) OR t.sequence < ?", before, *boundary), nil
+}
@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/edgelink/backend/internal/domain"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// RetentionRunRepository 数据清理执行记录仓储接口
+type RetentionRunRepository interface {
+	// Create 创建执行记录
+	Create(ctx context.Context, run *domain.RetentionRun) error
+
+	// Update 保存执行结果与报告
+	Update(ctx context.Context, run *domain.RetentionRun) error
+
+	// FindByID 根据ID查找执行记录
+	FindByID(ctx context.Context, id uuid.UUID) (*domain.RetentionRun, error)
+
+	// List 按开始时间倒序列出执行记录
+	List(ctx context.Context, limit, offset int) ([]*domain.RetentionRun, int64, error)
+}
+
+// retentionRunRepository RetentionRun仓储的GORM实现
+type retentionRunRepository struct {
+	db *gorm.DB
+}
+
+// NewRetentionRunRepository 创建RetentionRun仓储实例
+func NewRetentionRunRepository(db *gorm.DB) RetentionRunRepository {
+	return &retentionRunRepository{db: db}
+}
+
+// Create 创建执行记录
+func (r *retentionRunRepository) Create(ctx context.Context, run *domain.RetentionRun) error {
+	return r.db.WithContext(ctx).Create(run).Error
+}
+
+// Update 保存执行结果与报告
+func (r *retentionRunRepository) Update(ctx context.Context, run *domain.RetentionRun) error {
+	return r.db.WithContext(ctx).Save(run).Error
+}
+
+// FindByID 根据ID查找执行记录
+func (r *retentionRunRepository) FindByID(ctx context.Context, id uuid.UUID) (*domain.RetentionRun, error) {
+	var run domain.RetentionRun
+	if err := r.db.WithContext(ctx).First(&run, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
+
+// List 按开始时间倒序列出执行记录
+func (r *retentionRunRepository) List(ctx context.Context, limit, offset int) ([]*domain.RetentionRun, int64, error) {
+	var runs []*domain.RetentionRun
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&domain.RetentionRun{})
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	query = query.Order("started_at DESC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if offset > 0 {
+		query = query.Offset(offset)
+	}
+
+	if err := query.Find(&runs).Error; err != nil {
+		return nil, 0, err
+	}
+	return runs, total, nil
+}
@@ -52,6 +52,9 @@ type SessionRepository interface {
 
 	// GetSessionStats 获取会话统计信息
 	GetSessionStats(ctx context.Context, startTime, endTime time.Time) (*SessionStats, error)
+
+	// GetRelayStatsByNetwork 按虚拟网络统计since之后建立的会话中经TURN中继的数量
+	GetRelayStatsByNetwork(ctx context.Context, since time.Time) ([]NetworkRelayStats, error)
 }
 
 // SessionStats 会话统计信息
@@ -64,6 +67,21 @@ type SessionStats struct {
 	TotalBytesTransferred int64 `json:"total_bytes_transferred"`
 }
 
+// NetworkRelayStats 虚拟网络的中继回落统计
+type NetworkRelayStats struct {
+	VirtualNetworkID uuid.UUID `json:"virtual_network_id"`
+	TotalSessions    int64     `json:"total_sessions"`
+	RelaySessions    int64     `json:"relay_sessions"`
+}
+
+// RelayRate 经TURN中继建立的会话比例
+func (s NetworkRelayStats) RelayRate() float64 {
+	if s.TotalSessions == 0 {
+		return 0
+	}
+	return float64(s.RelaySessions) / float64(s.TotalSessions)
+}
+
 // sessionRepository Session仓储的GORM实现
 type sessionRepository struct {
 	db *gorm.DB
@@ -305,3 +323,18 @@ func (r *sessionRepository) CountActive(ctx context.Context) (int, error) {
 		Count(&count).Error
 	return int(count), err
 }
+
+// GetRelayStatsByNetwork 按虚拟网络统计会话中继回落情况（以发起方设备所在网络归属）
+func (r *sessionRepository) GetRelayStatsByNetwork(ctx context.Context, since time.Time) ([]NetworkRelayStats, error) {
+	var stats []NetworkRelayStats
+	err := r.db.WithContext(ctx).
+		Table("sessions").
+		Select("devices.virtual_network_id AS virtual_network_id, "+
+			"COUNT(*) AS total_sessions, "+
+			"COUNT(*) FILTER (WHERE sessions.connection_type = ?) AS relay_sessions", domain.ConnectionTypeTURNRelay).
+		Joins("JOIN devices ON devices.id = sessions.device_a_id").
+		Where("sessions.started_at >= ?", since).
+		Group("devices.virtual_network_id").
+		Scan(&stats).Error
+	return stats, err
+}
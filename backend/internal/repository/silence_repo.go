@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/edgelink/backend/internal/domain"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// SilenceRepository 告警静默仓储接口
+type SilenceRepository interface {
+	// Create 创建静默
+	Create(ctx context.Context, silence *domain.Silence) error
+
+	// FindByID 根据ID查找静默
+	FindByID(ctx context.Context, id uuid.UUID) (*domain.Silence, error)
+
+	// FindByFilters 根据过滤条件查找静默
+	FindByFilters(ctx context.Context, filters *SilenceFilters) ([]*domain.Silence, int64, error)
+
+	// FindActive 查找在指定时间生效的所有静默
+	FindActive(ctx context.Context, now time.Time) ([]*domain.Silence, error)
+
+	// FindOverlapping 查找与指定时间区间有重叠的所有静默
+	FindOverlapping(ctx context.Context, from, to time.Time) ([]*domain.Silence, error)
+
+	// Update 更新静默
+	Update(ctx context.Context, silence *domain.Silence) error
+
+	// Expire 提前使静默过期
+	Expire(ctx context.Context, id uuid.UUID, at time.Time) error
+}
+
+// SilenceFilters 静默查询过滤条件
+type SilenceFilters struct {
+	OrganizationID *uuid.UUID
+	State          *domain.SilenceState
+	Now            time.Time
+	Limit          int
+	Offset         int
+}
+
+// silenceRepository Silence仓储的GORM实现
+type silenceRepository struct {
+	db *gorm.DB
+}
+
+// NewSilenceRepository 创建Silence仓储实例
+func NewSilenceRepository(db *gorm.DB) SilenceRepository {
+	return &silenceRepository{db: db}
+}
+
+// Create 创建静默
+func (r *silenceRepository) Create(ctx context.Context, silence *domain.Silence) error {
+	return r.db.WithContext(ctx).Create(silence).Error
+}
+
+// FindByID 根据ID查找静默
+func (r *silenceRepository) FindByID(ctx context.Context, id uuid.UUID) (*domain.Silence, error) {
+	var silence domain.Silence
+	err := r.db.WithContext(ctx).
+		Where("id = ?", id).
+		First(&silence).Error
+	if err != nil {
+		return nil, err
+	}
+	return &silence, nil
+}
+
+// FindByFilters 根据过滤条件查找静默
+func (r *silenceRepository) FindByFilters(ctx context.Context, filters *SilenceFilters) ([]*domain.Silence, int64, error) {
+	var silences []*domain.Silence
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&domain.Silence{})
+
+	if filters.OrganizationID != nil {
+		query = query.Where("organization_id = ?", *filters.OrganizationID)
+	}
+	if filters.State != nil {
+		now := filters.Now
+		if now.IsZero() {
+			now = time.Now()
+		}
+		switch *filters.State {
+		case domain.SilenceStatePending:
+			query = query.Where("starts_at > ?", now)
+		case domain.SilenceStateActive:
+			query = query.Where("starts_at <= ? AND ends_at > ?", now, now)
+		case domain.SilenceStateExpired:
+			query = query.Where("ends_at <= ?", now)
+		}
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if filters.Limit > 0 {
+		query = query.Limit(filters.Limit)
+	}
+	if filters.Offset > 0 {
+		query = query.Offset(filters.Offset)
+	}
+
+	err := query.Order("ends_at DESC").Find(&silences).Error
+	return silences, total, err
+}
+
+// FindActive 查找在指定时间生效的所有静默
+func (r *silenceRepository) FindActive(ctx context.Context, now time.Time) ([]*domain.Silence, error) {
+	var silences []*domain.Silence
+	err := r.db.WithContext(ctx).
+		Where("starts_at <= ? AND ends_at > ?", now, now).
+		Order("created_at ASC").
+		Find(&silences).Error
+	return silences, err
+}
+
+// FindOverlapping 查找与指定时间区间有重叠的所有静默
+func (r *silenceRepository) FindOverlapping(ctx context.Context, from, to time.Time) ([]*domain.Silence, error) {
+	var silences []*domain.Silence
+	err := r.db.WithContext(ctx).
+		Where("starts_at < ? AND ends_at > ?", to, from).
+		Order("starts_at ASC").
+		Find(&silences).Error
+	return silences, err
+}
+
+// Update 更新静默
+func (r *silenceRepository) Update(ctx context.Context, silence *domain.Silence) error {
+	return r.db.WithContext(ctx).Save(silence).Error
+}
+
+// Expire 提前使静默过期（尚未开始的静默同时将开始时间前移，保证时间区间有效）
+func (r *silenceRepository) Expire(ctx context.Context, id uuid.UUID, at time.Time) error {
+	return r.db.WithContext(ctx).
+		Model(&domain.Silence{}).
+		Where("id = ? AND ends_at > ?", id, at).
+		Updates(map[string]interface{}{
+			"starts_at":  gorm.Expr("LEAST(starts_at, ?)", at),
+			"ends_at":    at,
+			"updated_at": at,
+		}).Error
+}
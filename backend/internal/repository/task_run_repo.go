@@ -0,0 +1,178 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/edgelink/backend/internal/domain"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TaskRunRepository 后台任务执行记录仓储接口
+type TaskRunRepository interface {
+	// Create 创建执行记录（定时执行时状态为running，手动触发时为pending）
+	Create(ctx context.Context, run *domain.TaskRun) error
+
+	// FindByID 根据ID查找执行记录
+	FindByID(ctx context.Context, id uuid.UUID) (*domain.TaskRun, error)
+
+	// FindByFilters 根据过滤条件查找执行记录（按创建时间倒序）
+	FindByFilters(ctx context.Context, filters *TaskRunFilters) ([]*domain.TaskRun, int64, error)
+
+	// FindLatest 查找每个任务最近一次执行记录
+	FindLatest(ctx context.Context) (map[string]*domain.TaskRun, error)
+
+	// ClaimPending 认领任务最早的一个手动触发请求并标记为running，没有待执行请求时返回nil
+	ClaimPending(ctx context.Context, taskName, instance string) (*domain.TaskRun, error)
+
+	// Finish 记录执行结果
+	Finish(ctx context.Context, run *domain.TaskRun) error
+
+	// InterruptStale 将其他实例遗留的running记录标记为interrupted（这些实例已退出或失去leader身份）
+	InterruptStale(ctx context.Context, instance string) (int64, error)
+}
+
+// TaskRunFilters 执行记录查询过滤条件
+type TaskRunFilters struct {
+	TaskName *string
+	Status   *domain.TaskRunStatus
+	Trigger  *domain.TaskRunTrigger
+	Limit    int
+	Offset   int
+}
+
+// taskRunRepository TaskRun仓储的GORM实现
+type taskRunRepository struct {
+	db *gorm.DB
+}
+
+// NewTaskRunRepository 创建TaskRun仓储实例
+func NewTaskRunRepository(db *gorm.DB) TaskRunRepository {
+	return &taskRunRepository{db: db}
+}
+
+// Create 创建执行记录
+func (r *taskRunRepository) Create(ctx context.Context, run *domain.TaskRun) error {
+	return r.db.WithContext(ctx).Create(run).Error
+}
+
+// FindByID 根据ID查找执行记录
+func (r *taskRunRepository) FindByID(ctx context.Context, id uuid.UUID) (*domain.TaskRun, error) {
+	var run domain.TaskRun
+	if err := r.db.WithContext(ctx).First(&run, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
+
+// FindByFilters 根据过滤条件查找执行记录
+func (r *taskRunRepository) FindByFilters(ctx context.Context, filters *TaskRunFilters) ([]*domain.TaskRun, int64, error) {
+	var runs []*domain.TaskRun
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&domain.TaskRun{})
+	if filters.TaskName != nil {
+		query = query.Where("task_name = ?", *filters.TaskName)
+	}
+	if filters.Status != nil {
+		query = query.Where("status = ?", *filters.Status)
+	}
+	if filters.Trigger != nil {
+		query = query.Where("trigger = ?", *filters.Trigger)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	query = query.Order("created_at DESC")
+	if filters.Limit > 0 {
+		query = query.Limit(filters.Limit)
+	}
+	if filters.Offset > 0 {
+		query = query.Offset(filters.Offset)
+	}
+
+	if err := query.Find(&runs).Error; err != nil {
+		return nil, 0, err
+	}
+	return runs, total, nil
+}
+
+// FindLatest 查找每个任务最近一次执行记录
+func (r *taskRunRepository) FindLatest(ctx context.Context) (map[string]*domain.TaskRun, error) {
+	var runs []*domain.TaskRun
+	if err := r.db.WithContext(ctx).
+		Raw("SELECT DISTINCT ON (task_name) * FROM task_runs ORDER BY task_name, created_at DESC").
+		Scan(&runs).Error; err != nil {
+		return nil, err
+	}
+
+	latest := make(map[string]*domain.TaskRun, len(runs))
+	for _, run := range runs {
+		latest[run.TaskName] = run
+	}
+	return latest, nil
+}
+
+// ClaimPending 认领任务最早的一个手动触发请求
+func (r *taskRunRepository) ClaimPending(ctx context.Context, taskName, instance string) (*domain.TaskRun, error) {
+	var run domain.TaskRun
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.
+			Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("task_name = ? AND status = ?", taskName, domain.TaskRunStatusPending).
+			Order("created_at ASC").
+			First(&run).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+		run.Status = domain.TaskRunStatusRunning
+		run.Instance = &instance
+		run.StartedAt = &now
+
+		return tx.Model(&domain.TaskRun{}).
+			Where("id = ?", run.ID).
+			Updates(map[string]interface{}{
+				"status":     run.Status,
+				"instance":   instance,
+				"started_at": now,
+			}).Error
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
+
+// Finish 记录执行结果
+func (r *taskRunRepository) Finish(ctx context.Context, run *domain.TaskRun) error {
+	return r.db.WithContext(ctx).Model(&domain.TaskRun{}).
+		Where("id = ?", run.ID).
+		Updates(map[string]interface{}{
+			"status":      run.Status,
+			"error":       run.Error,
+			"finished_at": run.FinishedAt,
+			"duration_ms": run.DurationMs,
+		}).Error
+}
+
+// InterruptStale 将其他实例遗留的running记录标记为interrupted
+func (r *taskRunRepository) InterruptStale(ctx context.Context, instance string) (int64, error) {
+	result := r.db.WithContext(ctx).Model(&domain.TaskRun{}).
+		Where("status = ?", domain.TaskRunStatusRunning).
+		Where("instance IS NULL OR instance <> ?", instance).
+		Updates(map[string]interface{}{
+			"status":      domain.TaskRunStatusInterrupted,
+			"finished_at": time.Now(),
+		})
+	return result.RowsAffected, result.Error
+}
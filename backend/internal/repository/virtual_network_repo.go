@@ -13,6 +13,7 @@ type VirtualNetworkRepository interface {
 	Create(ctx context.Context, vn *domain.VirtualNetwork) error
 	FindByID(ctx context.Context, id uuid.UUID) (*domain.VirtualNetwork, error)
 	FindByOrganization(ctx context.Context, orgID uuid.UUID) ([]domain.VirtualNetwork, error)
+	FindAll(ctx context.Context) ([]domain.VirtualNetwork, error)
 	Update(ctx context.Context, vn *domain.VirtualNetwork) error
 	Delete(ctx context.Context, id uuid.UUID) error
 }
@@ -50,6 +51,14 @@ func (r *virtualNetworkRepository) FindByOrganization(ctx context.Context, orgID
 	return vns, err
 }
 
+func (r *virtualNetworkRepository) FindAll(ctx context.Context) ([]domain.VirtualNetwork, error) {
+	var vns []domain.VirtualNetwork
+	err := r.db.WithContext(ctx).
+		Order("created_at ASC").
+		Find(&vns).Error
+	return vns, err
+}
+
 func (r *virtualNetworkRepository) Update(ctx context.Context, vn *domain.VirtualNetwork) error {
 	return r.db.WithContext(ctx).Save(vn).Error
 }
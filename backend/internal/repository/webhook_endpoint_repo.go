@@ -0,0 +1,172 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/edgelink/backend/internal/domain"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// WebhookEndpointRepository Webhook端点健康状态仓储接口
+type WebhookEndpointRepository interface {
+	// FindByKey 根据端点键查找
+	FindByKey(ctx context.Context, endpointKey string) (*domain.WebhookEndpoint, error)
+
+	// FindByID 根据ID查找
+	FindByID(ctx context.Context, id uuid.UUID) (*domain.WebhookEndpoint, error)
+
+	// List 列出端点，status为空时返回全部
+	List(ctx context.Context, status domain.WebhookEndpointStatus) ([]*domain.WebhookEndpoint, error)
+
+	// RecordSuccess 记录投递成功并清零连续失败次数
+	RecordSuccess(ctx context.Context, endpoint *domain.WebhookEndpoint, at time.Time) error
+
+	// RecordFailure 记录投递失败，返回累加后的端点状态
+	RecordFailure(ctx context.Context, endpoint *domain.WebhookEndpoint, errMsg string, statusCode int, at time.Time) (*domain.WebhookEndpoint, error)
+
+	// Disable 停用端点，端点已停用时返回false
+	Disable(ctx context.Context, id uuid.UUID, at time.Time) (bool, error)
+
+	// SetDisabledAlert 记录停用时产生的告警
+	SetDisabledAlert(ctx context.Context, id uuid.UUID, alertID uuid.UUID) error
+
+	// Enable 重新启用端点并清零失败计数
+	Enable(ctx context.Context, id uuid.UUID) error
+}
+
+// webhookEndpointRepository WebhookEndpoint仓储的GORM实现
+type webhookEndpointRepository struct {
+	db *gorm.DB
+}
+
+// NewWebhookEndpointRepository 创建WebhookEndpoint仓储实例
+func NewWebhookEndpointRepository(db *gorm.DB) WebhookEndpointRepository {
+	return &webhookEndpointRepository{db: db}
+}
+
+// FindByKey 根据端点键查找
+func (r *webhookEndpointRepository) FindByKey(ctx context.Context, endpointKey string) (*domain.WebhookEndpoint, error) {
+	var endpoint domain.WebhookEndpoint
+	err := r.db.WithContext(ctx).
+		Where("endpoint_key = ?", endpointKey).
+		First(&endpoint).Error
+	if err != nil {
+		return nil, err
+	}
+	return &endpoint, nil
+}
+
+// FindByID 根据ID查找
+func (r *webhookEndpointRepository) FindByID(ctx context.Context, id uuid.UUID) (*domain.WebhookEndpoint, error) {
+	var endpoint domain.WebhookEndpoint
+	err := r.db.WithContext(ctx).
+		Where("id = ?", id).
+		First(&endpoint).Error
+	if err != nil {
+		return nil, err
+	}
+	return &endpoint, nil
+}
+
+// List 列出端点
+func (r *webhookEndpointRepository) List(ctx context.Context, status domain.WebhookEndpointStatus) ([]*domain.WebhookEndpoint, error) {
+	query := r.db.WithContext(ctx).Model(&domain.WebhookEndpoint{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var endpoints []*domain.WebhookEndpoint
+	err := query.Order("name ASC").Find(&endpoints).Error
+	return endpoints, err
+}
+
+// RecordSuccess 记录投递成功
+// 使用upsert，首次投递的端点同时创建记录
+func (r *webhookEndpointRepository) RecordSuccess(ctx context.Context, endpoint *domain.WebhookEndpoint, at time.Time) error {
+	return r.db.WithContext(ctx).Exec(`
+		INSERT INTO webhook_endpoints (endpoint_key, name, url, last_success_at, last_status_code)
+		VALUES (?, ?, ?, ?, NULL)
+		ON CONFLICT (endpoint_key) DO UPDATE SET
+			name = EXCLUDED.name,
+			url = EXCLUDED.url,
+			consecutive_failures = 0,
+			failing_since = NULL,
+			last_error = NULL,
+			last_status_code = NULL,
+			last_success_at = EXCLUDED.last_success_at,
+			updated_at = NOW()`,
+		endpoint.EndpointKey, endpoint.Name, endpoint.URL, at,
+	).Error
+}
+
+// RecordFailure 记录投递失败
+// 单条upsert原子累加，多个实例并发投递同一端点时计数不会丢失
+func (r *webhookEndpointRepository) RecordFailure(ctx context.Context, endpoint *domain.WebhookEndpoint, errMsg string, statusCode int, at time.Time) (*domain.WebhookEndpoint, error) {
+	var code *int
+	if statusCode > 0 {
+		code = &statusCode
+	}
+
+	var updated domain.WebhookEndpoint
+	err := r.db.WithContext(ctx).Raw(`
+		INSERT INTO webhook_endpoints (endpoint_key, name, url, consecutive_failures, failing_since, last_error, last_status_code, last_failure_at)
+		VALUES (?, ?, ?, 1, ?, ?, ?, ?)
+		ON CONFLICT (endpoint_key) DO UPDATE SET
+			name = EXCLUDED.name,
+			url = EXCLUDED.url,
+			consecutive_failures = webhook_endpoints.consecutive_failures + 1,
+			failing_since = COALESCE(webhook_endpoints.failing_since, EXCLUDED.failing_since),
+			last_error = EXCLUDED.last_error,
+			last_status_code = EXCLUDED.last_status_code,
+			last_failure_at = EXCLUDED.last_failure_at,
+			updated_at = NOW()
+		RETURNING *`,
+		endpoint.EndpointKey, endpoint.Name, endpoint.URL, at, errMsg, code, at,
+	).Scan(&updated).Error
+	if err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// Disable 停用端点
+// 条件更新保证多个实例同时达到阈值时只有一个实例产生停用告警
+func (r *webhookEndpointRepository) Disable(ctx context.Context, id uuid.UUID, at time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&domain.WebhookEndpoint{}).
+		Where("id = ? AND status = ?", id, domain.WebhookEndpointStatusActive).
+		Updates(map[string]interface{}{
+			"status":      domain.WebhookEndpointStatusDisabled,
+			"disabled_at": at,
+			"updated_at":  time.Now(),
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// SetDisabledAlert 记录停用时产生的告警
+func (r *webhookEndpointRepository) SetDisabledAlert(ctx context.Context, id uuid.UUID, alertID uuid.UUID) error {
+	return r.db.WithContext(ctx).
+		Model(&domain.WebhookEndpoint{}).
+		Where("id = ?", id).
+		Update("disabled_alert_id", alertID).Error
+}
+
+// Enable 重新启用端点
+func (r *webhookEndpointRepository) Enable(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).
+		Model(&domain.WebhookEndpoint{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":               domain.WebhookEndpointStatusActive,
+			"consecutive_failures": 0,
+			"failing_since":        nil,
+			"disabled_at":          nil,
+			"disabled_alert_id":    nil,
+			"updated_at":           time.Now(),
+		}).Error
+}
@@ -3,12 +3,15 @@ package service
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/edgelink/backend/internal/auth"
+	"github.com/edgelink/backend/internal/config"
 	"github.com/edgelink/backend/internal/domain"
 	"github.com/edgelink/backend/internal/repository"
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 // DeviceService 设备服务
@@ -17,6 +20,13 @@ type DeviceService struct {
 	virtualNetworkRepo repository.VirtualNetworkRepository
 	pskRepo           repository.PreSharedKeyRepository
 	pskAuth           *auth.PSKAuthenticator
+	linkMetricRepo    repository.LinkMetricRepository
+	statusEventRepo   repository.DeviceStatusEventRepository
+	approvalRuleRepo  repository.DeviceApprovalRuleRepository
+	posturePolicyRepo repository.PosturePolicyRepository
+	auditLogRepo      repository.AuditLogRepository
+	topologyService   *TopologyService
+	offlineThreshold  time.Duration
 }
 
 // NewDeviceService 创建设备服务实例
@@ -25,31 +35,55 @@ func NewDeviceService(
 	vnRepo repository.VirtualNetworkRepository,
 	pskRepo repository.PreSharedKeyRepository,
 	pskAuth *auth.PSKAuthenticator,
+	linkMetricRepo repository.LinkMetricRepository,
+	statusEventRepo repository.DeviceStatusEventRepository,
+	approvalRuleRepo repository.DeviceApprovalRuleRepository,
+	posturePolicyRepo repository.PosturePolicyRepository,
+	auditLogRepo repository.AuditLogRepository,
+	topologyService *TopologyService,
+	cfg *config.Config,
 ) *DeviceService {
 	return &DeviceService{
 		deviceRepo:        deviceRepo,
 		virtualNetworkRepo: vnRepo,
 		pskRepo:           pskRepo,
 		pskAuth:           pskAuth,
+		linkMetricRepo:    linkMetricRepo,
+		statusEventRepo:   statusEventRepo,
+		approvalRuleRepo:  approvalRuleRepo,
+		posturePolicyRepo: posturePolicyRepo,
+		auditLogRepo:      auditLogRepo,
+		topologyService:   topologyService,
+		offlineThreshold:  cfg.Alert.DeviceOfflineThreshold,
 	}
 }
 
 // RegisterDeviceRequest 设备注册请求
 type RegisterDeviceRequest struct {
-	PublicKey        string `json:"public_key"`
-	Platform         string `json:"platform"`
-	DeviceName       string `json:"device_name"`
-	OrganizationSlug string `json:"organization_slug"`
-	VirtualNetworkID string `json:"virtual_network_id"`
-	PreSharedKey     string `json:"-"` // 从Header提取，不在JSON body中
+	PublicKey        string                `json:"public_key"`
+	Platform         string                `json:"platform"`
+	DeviceName       string                `json:"device_name"`
+	OrganizationSlug string                `json:"organization_slug"`
+	VirtualNetworkID string                `json:"virtual_network_id"`
+	Tags             []string              `json:"tags,omitempty"`
+	Ephemeral        bool                  `json:"ephemeral,omitempty"` // 临时设备，离线一段时间后自动撤销；使用临时密钥注册时忽略该字段
+	Posture          *domain.DevicePosture `json:"posture,omitempty"`   // 注册时的安全状态，网络有安全状态策略时未上报视为不合规
+	PreSharedKey     string                `json:"-"`                   // 从Header提取，不在JSON body中
 }
 
 // RegisterDeviceResponse 设备注册响应
 type RegisterDeviceResponse struct {
-	DeviceID         uuid.UUID              `json:"device_id"`
-	VirtualIP        string                 `json:"virtual_ip"`
-	VirtualNetworkID uuid.UUID              `json:"virtual_network_id"`
-	CreatedAt        time.Time              `json:"created_at"`
+	DeviceID               uuid.UUID                   `json:"device_id"`
+	VirtualIP              string                      `json:"virtual_ip"`
+	VirtualNetworkID       uuid.UUID                   `json:"virtual_network_id"`
+	OrganizationID         uuid.UUID                   `json:"-"`
+	ApprovalStatus         domain.DeviceApprovalStatus `json:"approval_status"` // pending时需等待管理员审批才会出现在对端列表中
+	Ephemeral              bool                        `json:"ephemeral"`
+	PostureStatus          domain.DevicePostureStatus  `json:"posture_status"`                          // non_compliant时按策略被隔离或限制对端
+	PostureViolations      []string                    `json:"posture_violations,omitempty"`            // 不满足的策略要求
+	PostureAllowedPeerTags []string                    `json:"posture_allowed_peer_tags,omitempty"`     // 不合规时仍可互通的对端标签（各组的并集），为空表示完全隔离
+	PostureAllowedTagSets  domain.PeerTagSets          `json:"posture_allowed_peer_tag_sets,omitempty"` // 每条违反的restrict策略一组，对端须满足每一组
+	CreatedAt              time.Time                   `json:"created_at"`
 }
 
 // RegisterDevice 注册新设备
@@ -83,42 +117,128 @@ func (s *DeviceService) RegisterDevice(ctx context.Context, req *RegisterDeviceR
 		return nil, fmt.Errorf("virtual network not found: %w", err)
 	}
 
-	// 5. 分配虚拟IP（简化版本 - 生产环境需要IP池管理）
-	// TODO: 实现完整的IP分配逻辑
-	virtualIP := s.allocateVirtualIP(vn)
-
-	// 6. 创建设备记录
+	// 5. 构建设备记录（虚拟IP在写入前分配）
 	device := &domain.Device{
 		ID:               uuid.New(),
 		VirtualNetworkID: vnID,
 		Name:             req.DeviceName,
-		VirtualIP:        virtualIP,
 		PublicKey:        req.PublicKey,
 		Platform:         domain.Platform(req.Platform),
 		NATType:          domain.NATTypeUnknown,
+		Tags:             req.Tags,
+		Ephemeral:        psk.Ephemeral || req.Ephemeral,
+		Posture:          req.Posture,
 		Online:           false,
+		ApprovalStatus:   domain.DeviceApprovalApproved,
 		CreatedAt:        time.Now(),
 		UpdatedAt:        time.Now(),
 	}
 
+	// 6. 要求审批的网络中，未命中自动审批规则的设备进入待审批状态
+	var approvalRule *domain.DeviceApprovalRule
+	if vn.RequireApproval {
+		approvalRule, err = s.matchApprovalRule(ctx, vnID, psk.ID, req.Tags)
+		if err != nil {
+			return nil, err
+		}
+		if approvalRule != nil {
+			device.ApprovalRuleID = &approvalRule.ID
+			device.ApprovalDecidedAt = &device.CreatedAt
+		} else {
+			device.ApprovalStatus = domain.DeviceApprovalPending
+		}
+	}
+
+	// 7. 按网络的安全状态策略评估，不合规的设备注册后即被隔离或限制对端
+	policies, err := s.posturePolicyRepo.FindByVirtualNetwork(ctx, vnID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load posture policies: %w", err)
+	}
+	if device.Posture != nil {
+		device.PostureReportedAt = &device.CreatedAt
+	}
+	applyPosture(device, policies, device.CreatedAt)
+
+	// 8. 从网络地址池分配虚拟IP，写入失败时归还
+	device.VirtualIP, err = s.topologyService.AllocateVirtualIP(ctx, vnID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to allocate virtual IP: %w", err)
+	}
 	if err := s.deviceRepo.Create(ctx, device); err != nil {
+		s.topologyService.ReleaseVirtualIP(ctx, vnID, device.VirtualIP)
 		return nil, fmt.Errorf("failed to create device: %w", err)
 	}
 
-	// 7. 更新PSK使用次数
+	if approvalRule != nil {
+		s.recordAutoApproval(ctx, vn, device, approvalRule)
+	}
+
+	// 9. 更新PSK使用次数
 	if err := s.pskRepo.IncrementUsedCount(ctx, psk.ID); err != nil {
 		// 记录日志但不失败
 		fmt.Printf("warning: failed to increment PSK used count: %v\n", err)
 	}
 
+
 	return &RegisterDeviceResponse{
-		DeviceID:         device.ID,
-		VirtualIP:        device.VirtualIP,
-		VirtualNetworkID: device.VirtualNetworkID,
-		CreatedAt:        device.CreatedAt,
+		DeviceID:               device.ID,
+		VirtualIP:              device.VirtualIP,
+		VirtualNetworkID:       device.VirtualNetworkID,
+		OrganizationID:         vn.OrganizationID,
+		ApprovalStatus:         device.ApprovalStatus,
+		Ephemeral:              device.Ephemeral,
+		PostureStatus:          device.PostureStatus,
+		PostureViolations:      device.PostureViolations,
+		PostureAllowedPeerTags: device.PostureAllowedPeerTags,
+		PostureAllowedTagSets:  device.PostureAllowedPeerTagSets,
+		CreatedAt:              device.CreatedAt,
 	}, nil
 }
 
+// matchApprovalRule 查找批准该注册的自动审批规则，没有则返回nil
+func (s *DeviceService) matchApprovalRule(ctx context.Context, vnID, pskID uuid.UUID, tags []string) (*domain.DeviceApprovalRule, error) {
+	rules, err := s.approvalRuleRepo.FindByVirtualNetwork(ctx, vnID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load approval rules: %w", err)
+	}
+	for _, rule := range rules {
+		if rule.Matches(pskID, tags) {
+			return rule, nil
+		}
+	}
+	return nil, nil
+}
+
+// recordAutoApproval 为自动批准写入审计日志（没有操作者，规则即批准依据）
+func (s *DeviceService) recordAutoApproval(ctx context.Context, vn *domain.VirtualNetwork, device *domain.Device, rule *domain.DeviceApprovalRule) {
+	after := domain.JSONB{
+		"approval_status":    string(device.ApprovalStatus),
+		"approval_rule_id":   rule.ID.String(),
+		"virtual_network_id": vn.ID.String(),
+		"name":               device.Name,
+	}
+	if rule.PreSharedKeyID != nil {
+		after["rule_pre_shared_key_id"] = rule.PreSharedKeyID.String()
+	}
+	if rule.Tag != nil {
+		after["rule_tag"] = *rule.Tag
+	}
+
+	auditLog := &domain.AuditLog{
+		ID:             uuid.New(),
+		OrganizationID: vn.OrganizationID,
+		Action:         "auto_approve",
+		ResourceType:   domain.ResourceTypeDevice,
+		ResourceID:     device.ID,
+		AfterState:     &after,
+		CreatedAt:      time.Now(),
+	}
+	if err := s.auditLogRepo.Create(ctx, auditLog); err != nil {
+		// 记录日志但不失败
+		fmt.Printf("warning: failed to record device auto-approval: %v\n", err)
+	}
+}
+
 // GetDeviceConfig 获取设备配置
 func (s *DeviceService) GetDeviceConfig(ctx context.Context, deviceID uuid.UUID) (*domain.Device, error) {
 	device, err := s.deviceRepo.FindByID(ctx, deviceID)
@@ -129,10 +249,252 @@ func (s *DeviceService) GetDeviceConfig(ctx context.Context, deviceID uuid.UUID)
 }
 
 // UpdateDeviceStatus 更新设备在线状态
+// 在线状态发生变化、或设备在离线阈值之后重新上报时记录状态变化，供告警服务检测隧道抖动
 func (s *DeviceService) UpdateDeviceStatus(ctx context.Context, deviceID uuid.UUID, online bool) error {
+	device, err := s.deviceRepo.FindByID(ctx, deviceID)
+	if err != nil {
+		return fmt.Errorf("device not found: %w", err)
+	}
+
+	changed, err := s.deviceRepo.ChangeOnlineStatus(ctx, deviceID, online)
+	if err != nil {
+		return fmt.Errorf("failed to update online status: %w", err)
+	}
+
+	now := time.Now()
+	var events []*domain.DeviceStatusEvent
+	switch {
+	case changed:
+		events = append(events, &domain.DeviceStatusEvent{DeviceID: deviceID, Online: online, OccurredAt: now})
+	case online && device.LastSeenAt != nil && s.offlineThreshold > 0 && now.Sub(*device.LastSeenAt) > s.offlineThreshold:
+		// 设备停止上报后又恢复，期间数据库中的状态始终为在线，补记一次离线和一次上线
+		events = append(events,
+			&domain.DeviceStatusEvent{DeviceID: deviceID, Online: false, OccurredAt: device.LastSeenAt.Add(s.offlineThreshold)},
+			&domain.DeviceStatusEvent{DeviceID: deviceID, Online: true, OccurredAt: now},
+		)
+	}
+	if err := s.statusEventRepo.CreateBatch(ctx, events); err != nil {
+		return fmt.Errorf("failed to record status change: %w", err)
+	}
+
 	return s.deviceRepo.UpdateOnlineStatus(ctx, deviceID, online)
 }
 
+// RecordClockSkew 根据设备请求携带的时间戳记录时钟偏差（设备时间减服务器时间）
+func (s *DeviceService) RecordClockSkew(ctx context.Context, deviceID uuid.UUID, deviceTime, serverTime time.Time) error {
+	skew := deviceTime.Sub(serverTime)
+	return s.deviceRepo.UpdateClockSkew(ctx, deviceID, skew.Milliseconds(), serverTime)
+}
+
+// RecordLinkMetrics 保存设备上报的链路延迟与丢包率样本，返回写入条数
+// 键为对端设备ID，只接受同一虚拟网络内已批准的对端；丢包率大于1时按百分比换算
+func (s *DeviceService) RecordLinkMetrics(ctx context.Context, deviceID uuid.UUID, latencyMs map[string]int, packetLoss map[string]float64, recordedAt time.Time) (int, error) {
+	if len(latencyMs) == 0 && len(packetLoss) == 0 {
+		return 0, nil
+	}
+
+	device, err := s.deviceRepo.FindByID(ctx, deviceID)
+	if err != nil {
+		return 0, fmt.Errorf("device not found: %w", err)
+	}
+	if !device.IsApproved() {
+		return 0, nil
+	}
+
+	peers, err := s.deviceRepo.FindByVirtualNetwork(ctx, device.VirtualNetworkID, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query peers: %w", err)
+	}
+	peerIDs := make(map[uuid.UUID]bool, len(peers))
+	for _, peer := range peers {
+		if peer.ID != deviceID && peer.IsApproved() {
+			peerIDs[peer.ID] = true
+		}
+	}
+
+	samples := make(map[uuid.UUID]*domain.LinkMetric)
+	sampleFor := func(key string) *domain.LinkMetric {
+		peerID, err := uuid.Parse(key)
+		if err != nil || !peerIDs[peerID] {
+			return nil
+		}
+		sample, ok := samples[peerID]
+		if !ok {
+			sample = &domain.LinkMetric{
+				DeviceID:     deviceID,
+				PeerDeviceID: peerID,
+				RecordedAt:   recordedAt,
+			}
+			samples[peerID] = sample
+		}
+		return sample
+	}
+
+	for key, latency := range latencyMs {
+		if latency < 0 {
+			continue
+		}
+		if sample := sampleFor(key); sample != nil {
+			value := latency
+			sample.LatencyMs = &value
+		}
+	}
+	for key, loss := range packetLoss {
+		if loss < 0 {
+			continue
+		}
+		if loss > 1 {
+			loss = loss / 100
+		}
+		if loss > 1 {
+			continue
+		}
+		if sample := sampleFor(key); sample != nil {
+			value := loss
+			sample.PacketLoss = &value
+		}
+	}
+
+	batch := make([]*domain.LinkMetric, 0, len(samples))
+	for _, sample := range samples {
+		batch = append(batch, sample)
+	}
+	if err := s.linkMetricRepo.CreateBatch(ctx, batch); err != nil {
+		return 0, fmt.Errorf("failed to store link metrics: %w", err)
+	}
+	return len(batch), nil
+}
+
+// ReportPosture 保存设备上报的安全状态并按适用策略重新评估
+// 返回更新后的设备，以及评估结果（合规状态或允许的对端）是否发生变化
+func (s *DeviceService) ReportPosture(ctx context.Context, deviceID uuid.UUID, posture *domain.DevicePosture, reportedAt time.Time) (*domain.Device, bool, error) {
+	device, err := s.deviceRepo.FindByID(ctx, deviceID)
+	if err != nil {
+		return nil, false, fmt.Errorf("device not found: %w", err)
+	}
+
+	policies, err := s.posturePolicyRepo.FindByVirtualNetwork(ctx, device.VirtualNetworkID)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to load posture policies: %w", err)
+	}
+
+	device.Posture = posture
+	device.PostureReportedAt = &reportedAt
+	changed := applyPosture(device, policies, reportedAt)
+
+	if err := s.deviceRepo.UpdatePosture(ctx, device); err != nil {
+		return nil, false, fmt.Errorf("failed to update device posture: %w", err)
+	}
+	return device, changed, nil
+}
+
+// ReevaluateNetworkPosture 按当前策略重新评估虚拟网络内的全部设备（策略变更后调用）
+// 返回评估结果发生变化的设备
+func (s *DeviceService) ReevaluateNetworkPosture(ctx context.Context, vnID uuid.UUID) ([]domain.Device, error) {
+	policies, err := s.posturePolicyRepo.FindByVirtualNetwork(ctx, vnID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load posture policies: %w", err)
+	}
+	devices, err := s.deviceRepo.FindByVirtualNetwork(ctx, vnID, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch devices: %w", err)
+	}
+
+	now := time.Now()
+	var changed []domain.Device
+	for i := range devices {
+		device := &devices[i]
+		previousViolations := strings.Join(device.PostureViolations, "\n")
+		enforcementChanged := applyPosture(device, policies, now)
+		if !enforcementChanged && previousViolations == strings.Join(device.PostureViolations, "\n") {
+			continue
+		}
+		if err := s.deviceRepo.UpdatePosture(ctx, device); err != nil {
+			return changed, fmt.Errorf("failed to update device posture: %w", err)
+		}
+		if enforcementChanged {
+			changed = append(changed, *device)
+		}
+	}
+	return changed, nil
+}
+
+// applyPosture 将策略评估结果写入设备，返回合规状态或允许的对端标签是否变化
+func applyPosture(device *domain.Device, policies []*domain.PosturePolicy, checkedAt time.Time) bool {
+	result := domain.EvaluatePosture(device, policies)
+
+	previousStatus := device.PostureStatus
+	if previousStatus == "" {
+		previousStatus = domain.DevicePostureCompliant
+	}
+	changed := previousStatus != result.Status ||
+		!device.PostureAllowedPeerTagSets.Equal(result.AllowedPeerTagSets)
+
+	device.PostureStatus = result.Status
+	device.PostureViolations = pq.StringArray(result.Violations)
+	device.PostureAllowedPeerTags = pq.StringArray(result.AllowedPeerTags)
+	device.PostureAllowedPeerTagSets = result.AllowedPeerTagSets
+	if device.PostureViolations == nil {
+		device.PostureViolations = pq.StringArray{}
+	}
+	if device.PostureAllowedPeerTags == nil {
+		device.PostureAllowedPeerTags = pq.StringArray{}
+	}
+	if device.PostureAllowedPeerTagSets == nil {
+		device.PostureAllowedPeerTagSets = domain.PeerTagSets{}
+	}
+	device.PostureCheckedAt = &checkedAt
+	return changed
+}
+
+// UpdateDevice 保存设备名称和标签，并按网络的安全状态策略重新评估（策略可按标签限定作用范围）
+// 返回隔离/限制状态是否变化
+func (s *DeviceService) UpdateDevice(ctx context.Context, device *domain.Device) (bool, error) {
+	device.UpdatedAt = time.Now()
+	if err := s.deviceRepo.UpdateNameAndTags(ctx, device); err != nil {
+		return false, fmt.Errorf("failed to update device: %w", err)
+	}
+
+	policies, err := s.posturePolicyRepo.FindByVirtualNetwork(ctx, device.VirtualNetworkID)
+	if err != nil {
+		return false, fmt.Errorf("failed to load posture policies: %w", err)
+	}
+
+	previousViolations := strings.Join(device.PostureViolations, "\n")
+	changed := applyPosture(device, policies, device.UpdatedAt)
+	if !changed && previousViolations == strings.Join(device.PostureViolations, "\n") {
+		return false, nil
+	}
+	if err := s.deviceRepo.UpdatePosture(ctx, device); err != nil {
+		return false, fmt.Errorf("failed to update device posture: %w", err)
+	}
+	return changed, nil
+}
+
+// MoveDevice 将设备迁移到目标虚拟网络，使用调用方分配的虚拟IP
+// 审批状态保持不变，旧网络的自动审批规则不再关联；安全状态按目标网络的策略重新评估
+// 设备已被并发迁移或删除时返回false
+func (s *DeviceService) MoveDevice(ctx context.Context, device *domain.Device, target *domain.VirtualNetwork, virtualIP string) (bool, error) {
+	policies, err := s.posturePolicyRepo.FindByVirtualNetwork(ctx, target.ID)
+	if err != nil {
+		return false, fmt.Errorf("failed to load posture policies: %w", err)
+	}
+
+	fromVNID := device.VirtualNetworkID
+	device.VirtualNetworkID = target.ID
+	device.VirtualNetwork = target
+	device.VirtualIP = virtualIP
+	device.ApprovalRuleID = nil
+	device.UpdatedAt = time.Now()
+	applyPosture(device, policies, device.UpdatedAt)
+
+	moved, err := s.deviceRepo.MoveToNetwork(ctx, device, fromVNID)
+	if err != nil {
+		return false, fmt.Errorf("failed to move device: %w", err)
+	}
+	return moved, nil
+}
+
 // RevokeDevice 撤销设备
 func (s *DeviceService) RevokeDevice(ctx context.Context, deviceID uuid.UUID) error {
 	// 1. 标记设备为离线
@@ -147,15 +509,3 @@ func (s *DeviceService) RevokeDevice(ctx context.Context, deviceID uuid.UUID) er
 
 	return nil
 }
-
-// allocateVirtualIP 分配虚拟IP（简化实现）
-// TODO: 实现完整的IP池管理，支持CIDR范围内的动态分配
-func (s *DeviceService) allocateVirtualIP(vn *domain.VirtualNetwork) string {
-	// 临时实现：生成随机IP（仅用于MVP演示）
-	// 生产环境需要：
-	// 1. 解析CIDR范围
-	// 2. 查询已分配IP
-	// 3. 从可用池中选择未使用IP
-	// 4. 处理IP回收和重用
-	return "10.100.1.100" // 占位符
-}
@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/edgelink/backend/internal/domain"
+	"github.com/edgelink/backend/internal/repository"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// silenceReconcileScanLimit 每个组织重新计算静默标记时加载的未解决告警上限
+const silenceReconcileScanLimit = 5000
+
+// SilenceService 静默服务
+// 告警的silenced_by标记原本只在告警经过规则引擎时更新，静默创建、修改、到期后由该服务重新计算已有告警的标记
+type SilenceService struct {
+	silenceRepo repository.SilenceRepository
+	alertRepo   repository.AlertRepository
+	logger      *zap.Logger
+}
+
+// NewSilenceService 创建静默服务实例
+func NewSilenceService(
+	silenceRepo repository.SilenceRepository,
+	alertRepo repository.AlertRepository,
+	logger *zap.Logger,
+) *SilenceService {
+	return &SilenceService{
+		silenceRepo: silenceRepo,
+		alertRepo:   alertRepo,
+		logger:      logger,
+	}
+}
+
+// ReconcileAlerts 按当前生效的静默重新计算组织内未解决告警的静默标记，返回标记发生变化的告警数
+// 命中规则与规则引擎一致：取第一个命中的生效静默
+func (s *SilenceService) ReconcileAlerts(ctx context.Context, orgID uuid.UUID, now time.Time) (int, error) {
+	silences, err := s.silenceRepo.FindActive(ctx, now)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load active silences: %w", err)
+	}
+
+	alerts, err := s.alertRepo.FindUnresolvedByTypes(ctx, &orgID, nil, silenceReconcileScanLimit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load unresolved alerts: %w", err)
+	}
+	if len(alerts) == silenceReconcileScanLimit {
+		s.logger.Warn("Silence reconciliation hit scan limit, newer alerts are updated when processed",
+			zap.String("organization_id", orgID.String()),
+			zap.Int("limit", silenceReconcileScanLimit),
+		)
+	}
+
+	changed := 0
+	for _, alert := range alerts {
+		var silenceID *uuid.UUID
+		if silence := domain.FindMatchingSilence(silences, orgID, alert, alert.Device); silence != nil {
+			silenceID = &silence.ID
+		}
+		if sameSilence(alert.SilencedBy, silenceID) {
+			continue
+		}
+
+		if err := s.alertRepo.MarkSilenced(ctx, alert.ID, silenceID); err != nil {
+			return changed, fmt.Errorf("failed to update alert %s: %w", alert.ID, err)
+		}
+		changed++
+	}
+
+	return changed, nil
+}
+
+// ReconcileTransitions 为在(from, to]内开始或结束的静默所属组织重新计算告警静默标记
+func (s *SilenceService) ReconcileTransitions(ctx context.Context, from, to time.Time) (int, error) {
+	silences, err := s.silenceRepo.FindOverlapping(ctx, from, to)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load silences: %w", err)
+	}
+
+	orgs := make(map[uuid.UUID]bool)
+	for _, silence := range silences {
+		if silence.StartsAt.After(from) || !silence.EndsAt.After(to) {
+			orgs[silence.OrganizationID] = true
+		}
+	}
+
+	changed := 0
+	for orgID := range orgs {
+		n, err := s.ReconcileAlerts(ctx, orgID, to)
+		changed += n
+		if err != nil {
+			return changed, err
+		}
+	}
+	return changed, nil
+}
+
+// sameSilence 比较两个可空的静默ID
+func sameSilence(a, b *uuid.UUID) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
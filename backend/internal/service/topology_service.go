@@ -57,8 +57,15 @@ func (s *TopologyService) AllocateVirtualIP(ctx context.Context, virtualNetworkI
 		return "", fmt.Errorf("failed to initialize IP pool: %w", err)
 	}
 
-	// 3. 从池中分配IP
-	ip, err := pool.Allocate()
+	// 3. 以设备表为准排除已占用的IP：其他网关副本分配的IP不在本地池中，
+	// 本地池中已释放的IP可能仍被设备占用
+	used, err := s.deviceRepo.FindVirtualIPs(ctx, vn.ID)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch allocated IPs: %w", err)
+	}
+
+	// 4. 从池中分配IP
+	ip, err := pool.Allocate(used)
 	if err != nil {
 		return "", fmt.Errorf("failed to allocate IP: %w", err)
 	}
@@ -74,6 +81,11 @@ func (s *TopologyService) GetPeerConfigurations(ctx context.Context, deviceID uu
 		return nil, fmt.Errorf("device not found: %w", err)
 	}
 
+	// 未获批准的设备只能拿到自身信息
+	if !device.IsApproved() {
+		return []crypto.WireGuardPeerConfig{}, nil
+	}
+
 	// 2. 获取同一虚拟网络下的所有其他设备
 	onlineFilter := true
 	peers, err := s.deviceRepo.FindByVirtualNetwork(ctx, device.VirtualNetworkID, &onlineFilter)
@@ -94,6 +106,11 @@ func (s *TopologyService) GetPeerConfigurations(ctx context.Context, deviceID uu
 			continue
 		}
 
+		// 待审批、已拒绝的设备不出现在任何对端列表中；不合规的设备按安全状态策略隔离或限制对端
+		if !device.CanPeerWith(&peer) {
+			continue
+		}
+
 		peerConfig := crypto.WireGuardPeerConfig{
 			PublicKey:  peer.PublicKey,
 			AllowedIPs: []string{fmt.Sprintf("%s/32", peer.VirtualIP)},
@@ -229,25 +246,23 @@ func (s *TopologyService) getOrInitIPPool(ctx context.Context, vn *domain.Virtua
 		AllocatedIPs: make(map[string]bool),
 	}
 
-	// 查询已分配的IP
-	devices, err := s.deviceRepo.FindByVirtualNetwork(ctx, vn.ID, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch existing devices: %w", err)
-	}
-
-	for _, device := range devices {
-		pool.AllocatedIPs[device.VirtualIP] = true
-	}
+	// 网关地址不分配给设备
+	pool.AllocatedIPs[vn.GatewayIP] = true
 
 	s.ipPools[vn.ID] = pool
 	return pool, nil
 }
 
-// Allocate 从IP池中分配一个可用IP
-func (p *IPPool) Allocate() (string, error) {
+// Allocate 从IP池中分配一个可用IP，跳过池中已分配和used中已被设备占用的IP
+func (p *IPPool) Allocate(used []string) (string, error) {
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
+	inUse := make(map[string]bool, len(used))
+	for _, ip := range used {
+		inUse[ip] = true
+	}
+
 	// 遍历网络范围内的所有IP
 	for ip := incrementIP(p.Network.IP); p.Network.Contains(ip); ip = incrementIP(ip) {
 		ipStr := ip.String()
@@ -258,7 +273,7 @@ func (p *IPPool) Allocate() (string, error) {
 		}
 
 		// 检查是否已分配
-		if !p.AllocatedIPs[ipStr] {
+		if !p.AllocatedIPs[ipStr] && !inUse[ipStr] {
 			p.AllocatedIPs[ipStr] = true
 			return ipStr, nil
 		}
@@ -0,0 +1,82 @@
+package tracing
+
+import (
+	"errors"
+	"fmt"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+// gormSpanKey span在GORM语句实例中的存储键
+const gormSpanKey = "tracing:span"
+
+// InstrumentGORM 注册GORM回调，为已有追踪内的每条语句创建client span
+func InstrumentGORM(db *gorm.DB) error {
+	cb := db.Callback()
+	err := errors.Join(
+		cb.Create().Before("gorm:create").Register("tracing:before_create", gormBefore("create")),
+		cb.Create().After("gorm:create").Register("tracing:after_create", gormAfter),
+		cb.Query().Before("gorm:query").Register("tracing:before_query", gormBefore("query")),
+		cb.Query().After("gorm:query").Register("tracing:after_query", gormAfter),
+		cb.Update().Before("gorm:update").Register("tracing:before_update", gormBefore("update")),
+		cb.Update().After("gorm:update").Register("tracing:after_update", gormAfter),
+		cb.Delete().Before("gorm:delete").Register("tracing:before_delete", gormBefore("delete")),
+		cb.Delete().After("gorm:delete").Register("tracing:after_delete", gormAfter),
+		cb.Row().Before("gorm:row").Register("tracing:before_row", gormBefore("row")),
+		cb.Row().After("gorm:row").Register("tracing:after_row", gormAfter),
+		cb.Raw().Before("gorm:raw").Register("tracing:before_raw", gormBefore("raw")),
+		cb.Raw().After("gorm:raw").Register("tracing:after_raw", gormAfter),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to register gorm tracing callbacks: %w", err)
+	}
+	return nil
+}
+
+// gormBefore 开始语句span，并将子ctx写回语句供后续回调使用
+func gormBefore(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		ctx := db.Statement.Context
+		if !hasParent(ctx) {
+			return
+		}
+
+		ctx, span := Tracer().Start(ctx, "gorm."+operation,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				attribute.String("db.system", "postgresql"),
+				attribute.String("db.operation.name", operation),
+			),
+		)
+		db.Statement.Context = ctx
+		db.InstanceSet(gormSpanKey, span)
+	}
+}
+
+// gormAfter 记录表名、SQL（参数为占位符）、影响行数和错误后结束span
+func gormAfter(db *gorm.DB) {
+	value, ok := db.InstanceGet(gormSpanKey)
+	if !ok {
+		return
+	}
+	span, ok := value.(trace.Span)
+	if !ok {
+		return
+	}
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("db.collection.name", db.Statement.Table),
+		attribute.String("db.query.text", db.Statement.SQL.String()),
+		attribute.Int64("db.rows_affected", db.Statement.RowsAffected),
+	)
+
+	// 未找到记录属于正常查询结果
+	if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+		span.RecordError(db.Error)
+		span.SetStatus(codes.Error, db.Error.Error())
+	}
+}
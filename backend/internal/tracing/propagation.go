@@ -0,0 +1,51 @@
+package tracing
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Inject 将ctx中的追踪上下文序列化为map，随Redis消息或发件箱记录一起保存
+// ctx中没有追踪时返回nil
+func Inject(ctx context.Context) map[string]string {
+	carrier := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+	if len(carrier) == 0 {
+		return nil
+	}
+	return carrier
+}
+
+// Extract 从消息携带的map还原追踪上下文
+func Extract(ctx context.Context, carrier map[string]string) context.Context {
+	if len(carrier) == 0 {
+		return ctx
+	}
+	return otel.GetTextMapPropagator().Extract(ctx, propagation.MapCarrier(carrier))
+}
+
+// StartPublish 为发布Redis消息创建producer span，返回的ctx用于Inject
+func StartPublish(ctx context.Context, channel string) (context.Context, trace.Span) {
+	return Tracer().Start(ctx, channel+" publish",
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			attribute.String("messaging.system", "redis"),
+			attribute.String("messaging.destination.name", channel),
+		),
+	)
+}
+
+// StartReceive 从消息携带的追踪上下文创建consumer span，处理逻辑与发布方处于同一条追踪
+func StartReceive(ctx context.Context, channel string, carrier map[string]string) (context.Context, trace.Span) {
+	return Tracer().Start(Extract(ctx, carrier), channel+" receive",
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			attribute.String("messaging.system", "redis"),
+			attribute.String("messaging.destination.name", channel),
+		),
+	)
+}
@@ -0,0 +1,77 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// InstrumentRedis 为Redis客户端添加钩子，为已有追踪内的命令创建client span
+func InstrumentRedis(client *redis.Client) {
+	client.AddHook(redisHook{})
+}
+
+// redisHook 记录Redis命令span
+type redisHook struct{}
+
+// DialHook 不追踪建连
+func (redisHook) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return next(ctx, network, addr)
+	}
+}
+
+// ProcessHook 追踪单条命令
+func (redisHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		if !hasParent(ctx) {
+			return next(ctx, cmd)
+		}
+
+		name := strings.ToLower(cmd.Name())
+		ctx, span := startRedisSpan(ctx, "redis."+name, name)
+		err := next(ctx, cmd)
+		endRedisSpan(span, err)
+		return err
+	}
+}
+
+// ProcessPipelineHook 将整个管道记录为一个span
+func (redisHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		if !hasParent(ctx) {
+			return next(ctx, cmds)
+		}
+
+		ctx, span := startRedisSpan(ctx, "redis.pipeline", "pipeline")
+		span.SetAttributes(attribute.Int("db.operation.batch.size", len(cmds)))
+		err := next(ctx, cmds)
+		endRedisSpan(span, err)
+		return err
+	}
+}
+
+func startRedisSpan(ctx context.Context, spanName, operation string) (context.Context, trace.Span) {
+	return Tracer().Start(ctx, spanName,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("db.system", "redis"),
+			attribute.String("db.operation.name", operation),
+		),
+	)
+}
+
+func endRedisSpan(span trace.Span, err error) {
+	// redis.Nil表示键不存在，属于正常结果
+	if err != nil && !errors.Is(err, redis.Nil) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,146 @@
+// Package tracing 提供OpenTelemetry分布式追踪的初始化与公共埋点
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/edgelink/backend/internal/config"
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// instrumentationName 本项目埋点使用的Tracer名称
+const instrumentationName = "github.com/edgelink/backend"
+
+// Tracer 返回项目统一使用的Tracer（未启用导出时为no-op实现）
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// Start 开始一个内部span
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return Tracer().Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// End 按错误设置span状态并结束span
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// hasParent ctx中是否已有span，数据库和Redis埋点只在已有追踪内创建子span，
+// 避免投递队列轮询等后台查询产生大量孤立的根span
+func hasParent(ctx context.Context) bool {
+	return ctx != nil && trace.SpanContextFromContext(ctx).IsValid()
+}
+
+// Setup 返回初始化追踪的fx.Invoke函数
+// 始终注册W3C TraceContext传播器和数据库/Redis埋点，未导出追踪的服务也会透传上游的追踪上下文；
+// TRACING_EXPORTER=otlp 时才注册SDK并导出span
+func Setup(serviceName string) func(fx.Lifecycle, *config.Config, *zap.Logger, *gorm.DB, *redis.Client) error {
+	return func(lifecycle fx.Lifecycle, cfg *config.Config, log *zap.Logger, db *gorm.DB, redisClient *redis.Client) error {
+		otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+			propagation.TraceContext{},
+			propagation.Baggage{},
+		))
+
+		if err := InstrumentGORM(db); err != nil {
+			return err
+		}
+		InstrumentRedis(redisClient)
+
+		switch strings.ToLower(cfg.Tracing.Exporter) {
+		case "", "none":
+			return nil
+		case "otlp":
+		default:
+			return fmt.Errorf("unsupported tracing exporter: %s", cfg.Tracing.Exporter)
+		}
+
+		exporter, err := newOTLPExporter(context.Background(), cfg.Tracing)
+		if err != nil {
+			return fmt.Errorf("failed to create OTLP exporter: %w", err)
+		}
+
+		res, err := resource.Merge(
+			resource.Default(),
+			resource.NewSchemaless(attribute.String("service.name", serviceName)),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to build tracing resource: %w", err)
+		}
+
+		provider := sdktrace.NewTracerProvider(
+			sdktrace.WithBatcher(exporter),
+			sdktrace.WithResource(res),
+			sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.Tracing.SampleRatio))),
+		)
+		otel.SetTracerProvider(provider)
+		otel.SetErrorHandler(otel.ErrorHandlerFunc(func(err error) {
+			log.Warn("OpenTelemetry error", zap.Error(err))
+		}))
+
+		log.Info("Tracing enabled",
+			zap.String("service", serviceName),
+			zap.String("endpoint", cfg.Tracing.OTLPEndpoint),
+			zap.String("protocol", cfg.Tracing.OTLPProtocol),
+			zap.Float64("sample_ratio", cfg.Tracing.SampleRatio),
+		)
+
+		lifecycle.Append(fx.Hook{
+			OnStop: func(ctx context.Context) error {
+				// 刷新尚未导出的span
+				shutdownCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+				defer cancel()
+				return provider.Shutdown(shutdownCtx)
+			},
+		})
+
+		return nil
+	}
+}
+
+// newOTLPExporter 按协议创建OTLP导出器
+func newOTLPExporter(ctx context.Context, cfg config.TracingConfig) (sdktrace.SpanExporter, error) {
+	switch strings.ToLower(cfg.OTLPProtocol) {
+	case "http", "http/protobuf":
+		opts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(cfg.OTLPEndpoint)}
+		if cfg.OTLPInsecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		if len(cfg.OTLPHeaders) > 0 {
+			opts = append(opts, otlptracehttp.WithHeaders(cfg.OTLPHeaders))
+		}
+		return otlptracehttp.New(ctx, opts...)
+
+	case "", "grpc":
+		opts := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(cfg.OTLPEndpoint)}
+		if cfg.OTLPInsecure {
+			opts = append(opts, otlptracegrpc.WithInsecure())
+		}
+		if len(cfg.OTLPHeaders) > 0 {
+			opts = append(opts, otlptracegrpc.WithHeaders(cfg.OTLPHeaders))
+		}
+		return otlptracegrpc.New(ctx, opts...)
+
+	default:
+		return nil, fmt.Errorf("unsupported OTLP protocol: %s", cfg.OTLPProtocol)
+	}
+}
@@ -35,6 +35,7 @@ func main() {
 	)
 
 	flag.Parse()
+	platform.ClientVersion = version
 
 	if *showVersion {
 		fmt.Printf("EdgeLink Lite Client v%s\n", version)
@@ -139,6 +140,7 @@ func registerDevice(apiClient *api.Client, cfg *config.ClientConfig, plat *platf
 		Name:         cfg.DeviceName,
 		Platform:     plat.GetName(),
 		PublicKey:    publicKey,
+		Posture:      platform.CollectPosture(),
 	}
 
 	resp, err := apiClient.RegisterDevice(req)
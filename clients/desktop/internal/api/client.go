@@ -7,6 +7,8 @@ import (
 	"io"
 	"net/http"
 	"time"
+
+	"github.com/edgelink/client/internal/platform"
 )
 
 // Client API客户端
@@ -27,10 +29,11 @@ func NewClient(baseURL string) *Client {
 
 // RegisterDeviceRequest 设备注册请求
 type RegisterDeviceRequest struct {
-	PreSharedKey string `json:"pre_shared_key"`
-	Name         string `json:"name"`
-	Platform     string `json:"platform"`
-	PublicKey    string `json:"public_key"`
+	PreSharedKey string                  `json:"pre_shared_key"`
+	Name         string                  `json:"name"`
+	Platform     string                  `json:"platform"`
+	PublicKey    string                  `json:"public_key"`
+	Posture      *platform.DevicePosture `json:"posture,omitempty"`
 }
 
 // RegisterDeviceResponse 设备注册响应
@@ -6,18 +6,24 @@ import (
 	"fmt"
 	"net/http"
 	"time"
+
+	"github.com/edgelink/client/internal/platform"
 )
 
+// postureInterval 安全状态采集间隔（采集需要执行系统命令，不随每次指标上报）
+const postureInterval = 10 * time.Minute
+
 // DeviceMetrics 设备指标
 type DeviceMetrics struct {
-	DeviceID       string             `json:"device_id"`
-	Online         bool               `json:"online"`
-	BytesSent      int64              `json:"bytes_sent"`
-	BytesReceived  int64              `json:"bytes_received"`
-	LatencyMs      map[string]int     `json:"latency_ms"`      // peerID -> latency
-	PacketLoss     map[string]float64 `json:"packet_loss"`     // peerID -> loss rate
-	PublicEndpoint string             `json:"public_endpoint,omitempty"`
-	Timestamp      time.Time          `json:"timestamp"`
+	DeviceID       string                  `json:"device_id"`
+	Online         bool                    `json:"online"`
+	BytesSent      int64                   `json:"bytes_sent"`
+	BytesReceived  int64                   `json:"bytes_received"`
+	LatencyMs      map[string]int          `json:"latency_ms"`  // peerID -> latency
+	PacketLoss     map[string]float64      `json:"packet_loss"` // peerID -> loss rate
+	PublicEndpoint string                  `json:"public_endpoint,omitempty"`
+	Posture        *platform.DevicePosture `json:"posture,omitempty"` // 省略时控制平面保留上次上报的状态
+	Timestamp      time.Time               `json:"timestamp"`
 }
 
 // Reporter 指标报告器
@@ -26,6 +32,7 @@ type Reporter struct {
 	controlPlaneURL string
 	httpClient      *http.Client
 	interval        time.Duration
+	lastPostureAt   time.Time
 	stopCh          chan struct{}
 }
 
@@ -100,6 +107,12 @@ func (r *Reporter) collectMetrics() (*DeviceMetrics, error) {
 		Timestamp:     time.Now(),
 	}
 
+	// 按采集间隔附带安全状态
+	if time.Since(r.lastPostureAt) >= postureInterval {
+		metrics.Posture = platform.CollectPosture()
+		r.lastPostureAt = time.Now()
+	}
+
 	return metrics, nil
 }
 
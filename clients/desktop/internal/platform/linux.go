@@ -4,9 +4,11 @@
 package platform
 
 import (
+	"bufio"
 	"fmt"
 	"os"
 	"os/exec"
+	"strings"
 	"syscall"
 )
 
@@ -140,6 +142,62 @@ func GetSystemInfo() (map[string]string, error) {
 	return info, nil
 }
 
+// CollectPosture 收集设备安全状态
+// 发行版版本取自/etc/os-release；防火墙依次检查ufw、firewalld和nftables；磁盘加密检查是否存在dm-crypt设备
+func CollectPosture() *DevicePosture {
+	posture := &DevicePosture{
+		OSVersion:     osReleaseValue("VERSION_ID"),
+		ClientVersion: ClientVersion,
+		Attributes:    make(map[string]string),
+	}
+
+	if id := osReleaseValue("ID"); id != "" {
+		posture.Attributes["distribution"] = id
+	}
+	if release, err := os.ReadFile("/proc/sys/kernel/osrelease"); err == nil {
+		posture.Attributes["kernel"] = strings.TrimSpace(string(release))
+	}
+
+	if output, err := exec.Command("ufw", "status").Output(); err == nil {
+		posture.FirewallEnabled = boolPtr(strings.Contains(string(output), "Status: active"))
+	} else if output, err := exec.Command("firewall-cmd", "--state").Output(); err == nil {
+		posture.FirewallEnabled = boolPtr(strings.TrimSpace(string(output)) == "running")
+	} else if output, err := exec.Command("nft", "list", "ruleset").Output(); err == nil {
+		posture.FirewallEnabled = boolPtr(strings.Contains(string(output), "hook input"))
+	}
+
+	if output, err := exec.Command("lsblk", "-n", "-o", "TYPE").Output(); err == nil {
+		encrypted := false
+		for _, line := range strings.Fields(string(output)) {
+			if line == "crypt" {
+				encrypted = true
+				break
+			}
+		}
+		posture.DiskEncrypted = boolPtr(encrypted)
+	}
+
+	return posture
+}
+
+// osReleaseValue 读取/etc/os-release中的字段
+func osReleaseValue(key string) string {
+	file, err := os.Open("/etc/os-release")
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if value, ok := strings.CutPrefix(line, key+"="); ok {
+			return strings.Trim(value, `"'`)
+		}
+	}
+	return ""
+}
+
 // CheckRootPrivilege 检查是否具有root权限
 func CheckRootPrivilege() bool {
 	return os.Geteuid() == 0
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os/exec"
 	"runtime"
+	"strings"
 )
 
 // Platform macOS平台实现
@@ -143,3 +144,29 @@ func (p *Platform) GetInterfaceStats(interfaceName string) (bytesSent, bytesRece
 	_ = output
 	return 0, 0, nil
 }
+
+// CollectPosture 收集设备安全状态
+// 系统版本取自sw_vers；防火墙检查应用防火墙全局状态；磁盘加密检查FileVault
+func CollectPosture() *DevicePosture {
+	posture := &DevicePosture{
+		ClientVersion: ClientVersion,
+		Attributes:    make(map[string]string),
+	}
+
+	if output, err := exec.Command("sw_vers", "-productVersion").Output(); err == nil {
+		posture.OSVersion = strings.TrimSpace(string(output))
+	}
+	if output, err := exec.Command("sw_vers", "-buildVersion").Output(); err == nil {
+		posture.Attributes["build"] = strings.TrimSpace(string(output))
+	}
+
+	if output, err := exec.Command("/usr/libexec/ApplicationFirewall/socketfilterfw", "--getglobalstate").Output(); err == nil {
+		posture.FirewallEnabled = boolPtr(strings.Contains(string(output), "enabled"))
+	}
+
+	if output, err := exec.Command("fdesetup", "status").Output(); err == nil {
+		posture.DiskEncrypted = boolPtr(strings.Contains(string(output), "FileVault is On"))
+	}
+
+	return posture
+}
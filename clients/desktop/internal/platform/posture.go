@@ -0,0 +1,19 @@
+package platform
+
+// ClientVersion 客户端版本，构建时通过 -ldflags "-X github.com/edgelink/client/internal/platform.ClientVersion=..." 注入
+var ClientVersion = "dev"
+
+// DevicePosture 设备安全状态，随注册和指标一起上报给控制平面
+// 布尔项为nil表示无法检测（控制平面按不满足处理）
+type DevicePosture struct {
+	OSVersion       string            `json:"os_version,omitempty"`
+	ClientVersion   string            `json:"client_version,omitempty"`
+	FirewallEnabled *bool             `json:"firewall_enabled,omitempty"`
+	DiskEncrypted   *bool             `json:"disk_encrypted,omitempty"`
+	Attributes      map[string]string `json:"attributes,omitempty"`
+}
+
+// boolPtr 返回检测结果的指针
+func boolPtr(v bool) *bool {
+	return &v
+}
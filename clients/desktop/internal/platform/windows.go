@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os/exec"
 	"runtime"
+	"strings"
 )
 
 // Platform Windows平台实现
@@ -144,3 +145,39 @@ func (p *Platform) GetInterfaceStats(interfaceName string) (bytesSent, bytesRece
 	// 简化实现返回示例数据
 	return 0, 0, nil
 }
+
+// CollectPosture 收集设备安全状态
+// 系统版本取自Win32_OperatingSystem；防火墙要求所有配置文件均已开启；磁盘加密检查系统盘BitLocker保护状态
+func CollectPosture() *DevicePosture {
+	posture := &DevicePosture{
+		ClientVersion: ClientVersion,
+		Attributes:    make(map[string]string),
+	}
+
+	if output, err := exec.Command("powershell", "-NoProfile", "-Command",
+		"(Get-CimInstance Win32_OperatingSystem).Version").Output(); err == nil {
+		posture.OSVersion = strings.TrimSpace(string(output))
+	}
+
+	if output, err := exec.Command("netsh", "advfirewall", "show", "allprofiles", "state").Output(); err == nil {
+		// 每个配置文件输出一行"State ON/OFF"
+		states := 0
+		enabled := true
+		for _, line := range strings.Split(string(output), "\n") {
+			fields := strings.Fields(line)
+			if len(fields) == 2 && strings.EqualFold(fields[0], "State") {
+				states++
+				enabled = enabled && strings.EqualFold(fields[1], "ON")
+			}
+		}
+		if states > 0 {
+			posture.FirewallEnabled = boolPtr(enabled)
+		}
+	}
+
+	if output, err := exec.Command("manage-bde", "-status", "C:").Output(); err == nil {
+		posture.DiskEncrypted = boolPtr(strings.Contains(string(output), "Protection On"))
+	}
+
+	return posture
+}
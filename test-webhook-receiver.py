@@ -1,10 +1,59 @@
 #!/usr/bin/env python3
 """
 简单的Webhook接收器用于测试Edge-Link告警通知
+
+设置环境变量 WEBHOOK_SECRET 后会校验 X-EdgeLink-Signature 签名
 """
 from http.server import HTTPServer, BaseHTTPRequestHandler
+import hashlib
+import hmac
 import json
+import os
 import sys
+import time
+
+WEBHOOK_SECRET = os.environ.get('WEBHOOK_SECRET', '')
+SIGNATURE_TOLERANCE = 300  # 允许的时间戳偏差（秒）
+
+
+def verify_signature(headers, body):
+    """校验签名，返回 (是否通过, 原因)"""
+    timestamp = headers.get('X-EdgeLink-Timestamp', '')
+    signature = headers.get('X-EdgeLink-Signature', '')
+    if not timestamp or not signature:
+        return False, '缺少签名请求头'
+
+    try:
+        if abs(time.time() - int(timestamp)) > SIGNATURE_TOLERANCE:
+            return False, '时间戳超出允许范围'
+    except ValueError:
+        return False, '时间戳格式错误'
+
+    mac = hmac.new(WEBHOOK_SECRET.encode(), timestamp.encode() + b'.' + body, hashlib.sha256)
+    expected = 'v1=' + mac.hexdigest()
+    if not hmac.compare_digest(expected, signature):
+        return False, '签名不匹配'
+    return True, ''
+
+
+def print_alert(alert):
+    print(f"\n告警ID: {alert.get('alert_id') or alert.get('id')}")
+    print(f"标题: {alert.get('title')}")
+    print(f"消息: {alert.get('message')}")
+    print(f"严重程度: {alert.get('severity')}")
+    print(f"告警类型: {alert.get('alert_type') or alert.get('type')}")
+    if alert.get('status'):
+        print(f"状态: {alert.get('status')}")
+    print(f"设备ID: {alert.get('device_id', 'N/A')}")
+    print(f"创建时间: {alert.get('created_at')}")
+    if alert.get('timestamp'):
+        print(f"时间戳: {alert.get('timestamp')}")
+
+    if alert.get('metadata'):
+        print("\n元数据:")
+        for key, value in alert['metadata'].items():
+            print(f"  {key}: {value}")
+
 
 class WebhookHandler(BaseHTTPRequestHandler):
     def do_POST(self):
@@ -15,21 +64,37 @@ class WebhookHandler(BaseHTTPRequestHandler):
         print("✅ 收到Webhook通知！")
         print("="*80)
 
+        print(f"事件ID: {self.headers.get('X-EdgeLink-Event-ID', 'N/A')}")
+        print(f"事件类型: {self.headers.get('X-EdgeLink-Event-Type', 'N/A')}")
+        print(f"负载版本: {self.headers.get('X-EdgeLink-Schema-Version', 'N/A')}")
+
+        if WEBHOOK_SECRET:
+            ok, reason = verify_signature(self.headers, post_data)
+            if not ok:
+                print(f"❌ 签名校验失败: {reason}")
+                self.send_response(401)
+                self.send_header('Content-type', 'application/json')
+                self.end_headers()
+                self.wfile.write(b'{"status": "invalid_signature"}')
+                return
+            print("🔐 签名校验通过")
+
         try:
             data = json.loads(post_data.decode('utf-8'))
-            print(f"\n告警ID: {data.get('alert_id')}")
-            print(f"标题: {data.get('title')}")
-            print(f"消息: {data.get('message')}")
-            print(f"严重程度: {data.get('severity')}")
-            print(f"告警类型: {data.get('alert_type')}")
-            print(f"设备ID: {data.get('device_id', 'N/A')}")
-            print(f"创建时间: {data.get('created_at')}")
-            print(f"时间戳: {data.get('timestamp')}")
-
-            if data.get('metadata'):
-                print("\n元数据:")
-                for key, value in data['metadata'].items():
-                    print(f"  {key}: {value}")
+
+            # CloudEvents 结构化模式，负载位于 data 字段
+            if 'specversion' in data:
+                print(f"CloudEvent: {data.get('type')} ({data.get('source')})")
+                data = data.get('data') or {}
+
+            if 'alerts' in data:
+                print(f"批量告警: {data.get('count')} 条")
+                for alert in data['alerts']:
+                    print_alert(alert)
+            elif 'alert' in data:
+                print_alert(data['alert'])
+            else:
+                print_alert(data)
 
             print("\n" + "="*80 + "\n")
 
@@ -52,6 +117,8 @@ def run_server(port=8888):
     httpd = HTTPServer(server_address, WebhookHandler)
     print(f"🚀 Webhook接收器启动在端口 {port}")
     print(f"📡 Webhook URL: http://localhost:{port}/webhook")
+    if WEBHOOK_SECRET:
+        print("🔐 已启用签名校验")
     print("⏳ 等待告警通知...\n")
     httpd.serve_forever()
 